@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"math/big"
+	"sort"
 	"strconv"
 
 	rCommon "github.com/ethereum/go-ethereum/common"
@@ -120,3 +121,100 @@ func findExternalTokenID(tokenID *common.Hash, db database.DatabaseInterface) ([
 	}
 	return nil, errors.New("invalid tokenID")
 }
+
+// issuingModule builds instructions for issuing requests of centralized and decentralized bridge tokens,
+// its state only lives during the building of a beacon block
+type issuingModule struct{}
+
+func init() {
+	if err := RegisterStatefulModule(&issuingModule{}); err != nil {
+		panic("failed to register issuing stateful module")
+	}
+}
+
+func (module *issuingModule) Name() string {
+	return "issuing"
+}
+
+func (module *issuingModule) Priority() int {
+	return issuingModulePriority
+}
+
+func (module *issuingModule) ActionMetaTypes() []int {
+	return []int{metadata.IssuingRequestMeta, metadata.IssuingETHRequestMeta}
+}
+
+// InstructionMetaTypes returns nothing since issuing instructions are processed along with other bridge instructions
+func (module *issuingModule) InstructionMetaTypes() []int {
+	return []int{}
+}
+
+func (module *issuingModule) InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error) {
+	return &metadata.AccumulatedValues{
+		UniqETHTxsUsed:   [][]byte{},
+		DBridgeTokenPair: map[string][]byte{},
+		CBridgeTokens:    []*common.Hash{},
+	}, nil
+}
+
+func (module *issuingModule) BuildInstructions(
+	blockchain *BlockChain,
+	beaconHeight uint64,
+	state interface{},
+	actionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	accumulatedValues, ok := state.(*metadata.AccumulatedValues)
+	if !ok {
+		return [][]string{}, errors.New("invalid state of issuing module")
+	}
+	instructions := [][]string{}
+	var keys []int
+	for k := range actionsByShardID {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+	for _, value := range keys {
+		shardID := byte(value)
+		for _, action := range actionsByShardID[shardID] {
+			metaType, err := strconv.Atoi(action[0])
+			if err != nil {
+				continue
+			}
+			contentStr := action[1]
+			newInst := [][]string{}
+			switch metaType {
+			case metadata.IssuingRequestMeta:
+				newInst, err = blockchain.buildInstructionsForIssuingReq(contentStr, shardID, metaType, accumulatedValues)
+			case metadata.IssuingETHRequestMeta:
+				newInst, err = blockchain.buildInstructionsForIssuingETHReq(contentStr, shardID, metaType, accumulatedValues)
+			default:
+				continue
+			}
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			if len(newInst) > 0 {
+				instructions = append(instructions, newInst...)
+			}
+		}
+	}
+	return instructions, nil
+}
+
+func (module *issuingModule) ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error {
+	return nil
+}
+
+func (module *issuingModule) StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error {
+	return nil
+}
+
+func (module *issuingModule) BackupState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
+
+func (module *issuingModule) RestoreState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
@@ -102,6 +102,10 @@ func (chain *BeaconChain) ValidatePreSignBlock(block common.BlockInterface) erro
 	return chain.Blockchain.VerifyPreSignBeaconBlock(block.(*BeaconBlock), true)
 }
 
+func (chain *BeaconChain) AddDoubleSignEvidence(evidence *DoubleSignEvidence) error {
+	return chain.Blockchain.AddDoubleSignEvidence(evidence)
+}
+
 // func (chain *BeaconChain) ValidateAndInsertBlock(block common.BlockInterface) error {
 // 	var beaconBestState BeaconBestState
 // 	beaconBlock := block.(*BeaconBlock)
@@ -3,52 +3,12 @@ package blockchain
 import (
 	"encoding/base64"
 	"encoding/json"
-	"strconv"
 
 	"github.com/incognitochain/incognito-chain/common"
-	"github.com/incognitochain/incognito-chain/database"
 	"github.com/incognitochain/incognito-chain/database/lvdb"
 	"github.com/incognitochain/incognito-chain/metadata"
 )
 
-func (blockchain *BlockChain) processPDEInstructions(block *BeaconBlock, bd *[]database.BatchData) error {
-	beaconHeight := block.Header.Height - 1
-	db := blockchain.GetDatabase()
-	currentPDEState, err := InitCurrentPDEStateFromDB(db, beaconHeight)
-	if err != nil {
-		Logger.log.Error(err)
-		return nil
-	}
-	for _, inst := range block.Body.Instructions {
-		if len(inst) < 2 {
-			continue // Not error, just not PDE instruction
-		}
-		var err error
-		switch inst[0] {
-		case strconv.Itoa(metadata.PDEContributionMeta):
-			err = blockchain.processPDEContributionV2(beaconHeight, inst, currentPDEState)
-		case strconv.Itoa(metadata.PDETradeRequestMeta):
-			err = blockchain.processPDETrade(beaconHeight, inst, currentPDEState)
-		case strconv.Itoa(metadata.PDEWithdrawalRequestMeta):
-			err = blockchain.processPDEWithdrawal(beaconHeight, inst, currentPDEState)
-		}
-		if err != nil {
-			Logger.log.Error(err)
-			return nil
-		}
-	}
-	// store updated currentPDEState to leveldb with new beacon height
-	err = storePDEStateToDB(
-		db,
-		beaconHeight+1,
-		currentPDEState,
-	)
-	if err != nil {
-		Logger.log.Error(err)
-	}
-	return nil
-}
-
 func storePDEPoolForPair(
 	pdePoolForPairKey string,
 	token1IDStr string,
@@ -318,6 +278,13 @@ func (blockchain *BlockChain) processPDETrade(
 		pdePoolForPair.Token1PoolValue -= pdeTradeAcceptedContent.Token1PoolValueOperation.Value
 		pdePoolForPair.Token2PoolValue += pdeTradeAcceptedContent.Token2PoolValueOperation.Value
 	}
+	creditPDETradingFee(
+		beaconHeight,
+		pdePoolForPair,
+		pdeSellingTokenIDStr(pdeTradeAcceptedContent.Token1IDStr, pdeTradeAcceptedContent.Token2IDStr, pdeTradeAcceptedContent.Token1PoolValueOperation),
+		pdeTradeAcceptedContent.ProtocolFee,
+		currentPDEState,
+	)
 	err = db.TrackPDEStatus(
 		lvdb.PDETradeStatusPrefix,
 		pdeTradeAcceptedContent.RequestedTxID[:],
@@ -329,6 +296,71 @@ func (blockchain *BlockChain) processPDETrade(
 	return nil
 }
 
+func (blockchain *BlockChain) processPDEMultiHopTrade(
+	beaconHeight uint64,
+	instruction []string,
+	currentPDEState *CurrentPDEState,
+) error {
+	if len(instruction) != 4 {
+		return nil // skip the instruction
+	}
+	db := blockchain.GetDatabase()
+	if instruction[2] == common.PDETradeRefundChainStatus {
+		pdeMultiHopTradeReqAction, err := metadata.ParsePDEMultiHopTradeRequestAction(instruction[3])
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while parsing pde multi-hop trade request action: %+v", err)
+			return nil
+		}
+		err = db.TrackPDEStatus(
+			lvdb.PDETradeStatusPrefix,
+			pdeMultiHopTradeReqAction.TxReqID[:],
+			byte(common.PDETradeRefundStatus),
+		)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while tracking pde refund multi-hop trade status: %+v", err)
+		}
+		return nil
+	}
+	var pdeMultiHopTradeAcceptedContent metadata.PDEMultiHopTradeAcceptedContent
+	err := json.Unmarshal([]byte(instruction[3]), &pdeMultiHopTradeAcceptedContent)
+	if err != nil {
+		Logger.log.Errorf("WARNING: an error occured while unmarshaling PDEMultiHopTradeAcceptedContent: %+v", err)
+		return nil
+	}
+	for _, hop := range pdeMultiHopTradeAcceptedContent.Hops {
+		pdePoolForPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, hop.Token1IDStr, hop.Token2IDStr))
+		pdePoolForPair, found := currentPDEState.PDEPoolPairs[pdePoolForPairKey]
+		if !found || pdePoolForPair == nil {
+			Logger.log.Errorf("WARNING: could not find out pdePoolForPair with token ids: %s & %s", hop.Token1IDStr, hop.Token2IDStr)
+			return nil
+		}
+	}
+	for _, hop := range pdeMultiHopTradeAcceptedContent.Hops {
+		pdePoolForPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, hop.Token1IDStr, hop.Token2IDStr))
+		pdePoolForPair := currentPDEState.PDEPoolPairs[pdePoolForPairKey]
+		if hop.Token1PoolValueOperation.Operator == "+" {
+			pdePoolForPair.Token1PoolValue += hop.Token1PoolValueOperation.Value
+			pdePoolForPair.Token2PoolValue -= hop.Token2PoolValueOperation.Value
+		} else {
+			pdePoolForPair.Token1PoolValue -= hop.Token1PoolValueOperation.Value
+			pdePoolForPair.Token2PoolValue += hop.Token2PoolValueOperation.Value
+		}
+	}
+	for _, hop := range pdeMultiHopTradeAcceptedContent.Hops {
+		pdePoolForPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, hop.Token1IDStr, hop.Token2IDStr))
+		creditPDETradingFee(beaconHeight, currentPDEState.PDEPoolPairs[pdePoolForPairKey], hop.TokenIDToSellStr, hop.ProtocolFee, currentPDEState)
+	}
+	err = db.TrackPDEStatus(
+		lvdb.PDETradeStatusPrefix,
+		pdeMultiHopTradeAcceptedContent.RequestedTxID[:],
+		byte(common.PDETradeAcceptedStatus),
+	)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while tracking pde accepted multi-hop trade status: %+v", err)
+	}
+	return nil
+}
+
 func deductSharesForWithdrawal(
 	beaconHeight uint64,
 	token1IDStr string,
@@ -343,7 +375,7 @@ func deductSharesForWithdrawal(
 	if found && amt <= currentAmt {
 		adjustingAmt = currentAmt - amt
 	}
-	currentPDEState.PDEShares[pdeShareKey] = adjustingAmt
+	setPDEShare(currentPDEState, pdeShareKey, adjustingAmt)
 }
 
 func (blockchain *BlockChain) processPDEWithdrawal(
@@ -411,6 +443,14 @@ func (blockchain *BlockChain) processPDEWithdrawal(
 		currentPDEState,
 	)
 
+	// accrued trading fees in the withdrawal token are paid by the response tx
+	claimPDETradingFee(
+		beaconHeight,
+		wdAcceptedContent.PairToken1IDStr, wdAcceptedContent.PairToken2IDStr,
+		wdAcceptedContent.WithdrawalTokenIDStr, wdAcceptedContent.WithdrawerAddressStr,
+		currentPDEState,
+	)
+
 	err = db.TrackPDEStatus(
 		lvdb.PDEWithdrawalStatusPrefix,
 		wdAcceptedContent.TxReqID[:],
@@ -421,3 +461,105 @@ func (blockchain *BlockChain) processPDEWithdrawal(
 	}
 	return nil
 }
+
+func (blockchain *BlockChain) processPDELimitOrder(
+	beaconHeight uint64,
+	instruction []string,
+	currentPDEState *CurrentPDEState,
+) error {
+	if len(instruction) != 4 {
+		return nil // skip the instruction
+	}
+	db := blockchain.GetDatabase()
+	switch instruction[2] {
+	case common.PDELimitOrderPlacedChainStatus:
+		pdeLimitOrderReqAction, err := metadata.ParsePDELimitOrderRequestAction(instruction[3])
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while parsing pde limit order action: %+v", err)
+			return nil
+		}
+		order := newPDELimitOrder(pdeLimitOrderReqAction)
+		orderKey := string(lvdb.BuildPDELimitOrderKey(beaconHeight, order.OrderID))
+		currentPDEState.PDELimitOrders[orderKey] = order
+		err = db.TrackPDEStatus(
+			lvdb.PDELimitOrderStatusPrefix,
+			order.TxReqID[:],
+			byte(common.PDELimitOrderPlacedStatus),
+		)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while tracking pde placed limit order status: %+v", err)
+		}
+
+	case common.PDELimitOrderRefundChainStatus:
+		var refundContent metadata.PDELimitOrderRefundContent
+		err := json.Unmarshal([]byte(instruction[3]), &refundContent)
+		if err != nil {
+			Logger.log.Errorf("WARNING: an error occured while unmarshaling PDELimitOrderRefundContent: %+v", err)
+			return nil
+		}
+		orderKey := string(lvdb.BuildPDELimitOrderKey(beaconHeight, refundContent.OrderID))
+		delete(currentPDEState.PDELimitOrders, orderKey)
+		err = db.TrackPDEStatus(
+			lvdb.PDELimitOrderStatusPrefix,
+			refundContent.RequestedTxID[:],
+			byte(common.PDELimitOrderRefundStatus),
+		)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while tracking pde refund limit order status: %+v", err)
+		}
+
+	case common.PDELimitOrderFilledChainStatus:
+		var filledContent metadata.PDELimitOrderFilledContent
+		err := json.Unmarshal([]byte(instruction[3]), &filledContent)
+		if err != nil {
+			Logger.log.Errorf("WARNING: an error occured while unmarshaling PDELimitOrderFilledContent: %+v", err)
+			return nil
+		}
+		pdePoolForPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, filledContent.Token1IDStr, filledContent.Token2IDStr))
+		pdePoolForPair, found := currentPDEState.PDEPoolPairs[pdePoolForPairKey]
+		if !found || pdePoolForPair == nil {
+			Logger.log.Errorf("WARNING: could not find out pdePoolForPair with token ids: %s & %s", filledContent.Token1IDStr, filledContent.Token2IDStr)
+			return nil
+		}
+		if filledContent.Token1PoolValueOperation.Operator == "+" {
+			pdePoolForPair.Token1PoolValue += filledContent.Token1PoolValueOperation.Value
+			pdePoolForPair.Token2PoolValue -= filledContent.Token2PoolValueOperation.Value
+		} else {
+			pdePoolForPair.Token1PoolValue -= filledContent.Token1PoolValueOperation.Value
+			pdePoolForPair.Token2PoolValue += filledContent.Token2PoolValueOperation.Value
+		}
+		creditPDETradingFee(
+			beaconHeight,
+			pdePoolForPair,
+			pdeSellingTokenIDStr(filledContent.Token1IDStr, filledContent.Token2IDStr, filledContent.Token1PoolValueOperation),
+			filledContent.ProtocolFee,
+			currentPDEState,
+		)
+		orderKey := string(lvdb.BuildPDELimitOrderKey(beaconHeight, filledContent.OrderID))
+		delete(currentPDEState.PDELimitOrders, orderKey)
+		err = db.TrackPDEStatus(
+			lvdb.PDELimitOrderStatusPrefix,
+			filledContent.RequestedTxID[:],
+			byte(common.PDELimitOrderFilledStatus),
+		)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while tracking pde filled limit order status: %+v", err)
+		}
+	}
+	return nil
+}
+
+// processPDECancelLimitOrder does nothing on pde state since the order is removed by its refund instruction
+func (blockchain *BlockChain) processPDECancelLimitOrder(
+	beaconHeight uint64,
+	instruction []string,
+	currentPDEState *CurrentPDEState,
+) error {
+	if len(instruction) != 4 {
+		return nil // skip the instruction
+	}
+	if instruction[2] == common.PDECancelLimitOrderRejectedChainStatus {
+		Logger.log.Infof("PDE limit order cancellation is rejected: %s", instruction[3])
+	}
+	return nil
+}
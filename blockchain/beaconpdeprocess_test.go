@@ -27,6 +27,8 @@ func (suite *PDEProcessSuite) SetupTest() {
 		WaitingPDEContributions: make(map[string]*lvdb.PDEContribution),
 		PDEPoolPairs:            make(map[string]*lvdb.PDEPoolForPair),
 		PDEShares:               make(map[string]uint64),
+		PDELimitOrders:          make(map[string]*lvdb.PDELimitOrder),
+		PDETradingFees:          make(map[string]uint64),
 	}
 }
 
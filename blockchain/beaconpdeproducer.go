@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -453,8 +454,11 @@ func (blockchain *BlockChain) buildInstructionsForPDETrade(
 	invariant := big.NewInt(0)
 	invariant.Mul(big.NewInt(int64(tokenPoolValueToSell)), big.NewInt(int64(tokenPoolValueToBuy)))
 	fee := pdeTradeReqAction.Meta.TradingFee
+	// the protocol fee is taken from the selling amount before swapping and goes to liquidity providers
+	protocolFee := blockchain.computePDEProtocolFee(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, pdeTradeReqAction.Meta.SellAmount)
+	sellAmount := pdeTradeReqAction.Meta.SellAmount - protocolFee
 	newTokenPoolValueToSell := big.NewInt(0)
-	newTokenPoolValueToSell.Add(big.NewInt(int64(tokenPoolValueToSell)), big.NewInt(int64(pdeTradeReqAction.Meta.SellAmount)))
+	newTokenPoolValueToSell.Add(big.NewInt(int64(tokenPoolValueToSell)), big.NewInt(int64(sellAmount)))
 
 	newTokenPoolValueToBuy := big.NewInt(0).Div(invariant, newTokenPoolValueToSell).Uint64()
 	modValue := big.NewInt(0).Mod(invariant, newTokenPoolValueToSell)
@@ -490,6 +494,7 @@ func (blockchain *BlockChain) buildInstructionsForPDETrade(
 		pdePoolPair.Token1PoolValue = newTokenPoolValueToSell.Uint64()
 		pdePoolPair.Token2PoolValue = newTokenPoolValueToBuy
 	}
+	creditPDETradingFee(beaconHeight, pdePoolPair, pdeTradeReqAction.Meta.TokenIDToSellStr, protocolFee, currentPDEState)
 
 	pdeTradeAcceptedContent := metadata.PDETradeAcceptedContent{
 		TraderAddressStr: pdeTradeReqAction.Meta.TraderAddressStr,
@@ -497,6 +502,7 @@ func (blockchain *BlockChain) buildInstructionsForPDETrade(
 		ReceiveAmount:    receiveAmt,
 		Token1IDStr:      pdePoolPair.Token1IDStr,
 		Token2IDStr:      pdePoolPair.Token2IDStr,
+		ProtocolFee:      protocolFee,
 		ShardID:          shardID,
 		RequestedTxID:    pdeTradeReqAction.TxReqID,
 	}
@@ -506,12 +512,12 @@ func (blockchain *BlockChain) buildInstructionsForPDETrade(
 	}
 	pdeTradeAcceptedContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{
 		Operator: "+",
-		Value:    pdeTradeReqAction.Meta.SellAmount + fee,
+		Value:    sellAmount + fee,
 	}
 	if pdePoolPair.Token1IDStr == pdeTradeReqAction.Meta.TokenIDToSellStr {
 		pdeTradeAcceptedContent.Token1PoolValueOperation = metadata.TokenPoolValueOperation{
 			Operator: "+",
-			Value:    pdeTradeReqAction.Meta.SellAmount + fee,
+			Value:    sellAmount + fee,
 		}
 		pdeTradeAcceptedContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{
 			Operator: "-",
@@ -532,6 +538,158 @@ func (blockchain *BlockChain) buildInstructionsForPDETrade(
 	return [][]string{inst}, nil
 }
 
+func buildPDEMultiHopTradeRefundInst(
+	contentStr string,
+	shardID byte,
+	metaType int,
+) [][]string {
+	inst := []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		common.PDETradeRefundChainStatus,
+		contentStr,
+	}
+	return [][]string{inst}
+}
+
+// computePDETradeHop computes the amount received by selling an amount of token on a pool pair,
+// it returns false if the pool pair can not afford the trade
+func computePDETradeHop(
+	pdePoolPair *lvdb.PDEPoolForPair,
+	tokenIDToSellStr string,
+	sellAmount uint64,
+) (uint64, uint64, bool) {
+	tokenPoolValueToBuy := pdePoolPair.Token1PoolValue
+	tokenPoolValueToSell := pdePoolPair.Token2PoolValue
+	if pdePoolPair.Token1IDStr == tokenIDToSellStr {
+		tokenPoolValueToSell = pdePoolPair.Token1PoolValue
+		tokenPoolValueToBuy = pdePoolPair.Token2PoolValue
+	}
+	if tokenPoolValueToBuy == 0 || tokenPoolValueToSell == 0 || sellAmount == 0 {
+		return 0, 0, false
+	}
+	invariant := big.NewInt(0)
+	invariant.Mul(new(big.Int).SetUint64(tokenPoolValueToSell), new(big.Int).SetUint64(tokenPoolValueToBuy))
+	newTokenPoolValueToSell := big.NewInt(0)
+	newTokenPoolValueToSell.Add(new(big.Int).SetUint64(tokenPoolValueToSell), new(big.Int).SetUint64(sellAmount))
+
+	newTokenPoolValueToBuy := big.NewInt(0).Div(invariant, newTokenPoolValueToSell).Uint64()
+	modValue := big.NewInt(0).Mod(invariant, newTokenPoolValueToSell)
+	if modValue.Cmp(big.NewInt(0)) != 0 {
+		newTokenPoolValueToBuy++
+	}
+	if tokenPoolValueToBuy <= newTokenPoolValueToBuy {
+		return 0, 0, false
+	}
+	return tokenPoolValueToBuy - newTokenPoolValueToBuy, newTokenPoolValueToBuy, true
+}
+
+func (blockchain *BlockChain) buildInstructionsForPDEMultiHopTrade(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) ([][]string, error) {
+	if currentPDEState == nil ||
+		(currentPDEState.PDEPoolPairs == nil || len(currentPDEState.PDEPoolPairs) == 0) {
+		return buildPDEMultiHopTradeRefundInst(contentStr, shardID, metaType), nil
+	}
+	pdeMultiHopTradeReqAction, err := metadata.ParsePDEMultiHopTradeRequestAction(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while parsing pde multi-hop trade instruction: %+v", err)
+		return [][]string{}, nil
+	}
+	tradeMeta := pdeMultiHopTradeReqAction.Meta
+	if len(tradeMeta.TradePath) < 2 || len(tradeMeta.TradePath) > metadata.MaxPDETradePathLength {
+		return buildPDEMultiHopTradeRefundInst(contentStr, shardID, metaType), nil
+	}
+
+	// hops are computed on copies of pool pairs so that nothing changes if any of them fails
+	updatedPoolPairs := make(map[string]*lvdb.PDEPoolForPair)
+	hops := []metadata.PDETradeHop{}
+	sellAmount := tradeMeta.SellAmount
+	for i := 0; i < len(tradeMeta.TradePath)-1; i++ {
+		tokenIDToSellStr := tradeMeta.TradePath[i]
+		tokenIDToBuyStr := tradeMeta.TradePath[i+1]
+		pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, tokenIDToBuyStr, tokenIDToSellStr))
+		pdePoolPair, found := updatedPoolPairs[pairKey]
+		if !found {
+			currentPoolPair, found := currentPDEState.PDEPoolPairs[pairKey]
+			if !found || currentPoolPair == nil {
+				return buildPDEMultiHopTradeRefundInst(contentStr, shardID, metaType), nil
+			}
+			poolPairCopy := *currentPoolPair
+			pdePoolPair = &poolPairCopy
+			updatedPoolPairs[pairKey] = pdePoolPair
+		}
+		protocolFee := blockchain.computePDEProtocolFee(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, sellAmount)
+		receiveAmt, newTokenPoolValueToBuy, ok := computePDETradeHop(pdePoolPair, tokenIDToSellStr, sellAmount-protocolFee)
+		if !ok {
+			return buildPDEMultiHopTradeRefundInst(contentStr, shardID, metaType), nil
+		}
+		// the trading fee is paid in the selling token so it goes to the first pool pair
+		addingAmt := sellAmount - protocolFee
+		if i == 0 {
+			addingAmt += tradeMeta.TradingFee
+		}
+		hop := metadata.PDETradeHop{
+			TokenIDToSellStr: tokenIDToSellStr,
+			TokenIDToBuyStr:  tokenIDToBuyStr,
+			SellAmount:       sellAmount,
+			ReceiveAmount:    receiveAmt,
+			Token1IDStr:      pdePoolPair.Token1IDStr,
+			Token2IDStr:      pdePoolPair.Token2IDStr,
+			ProtocolFee:      protocolFee,
+		}
+		if pdePoolPair.Token1IDStr == tokenIDToSellStr {
+			pdePoolPair.Token1PoolValue += addingAmt
+			pdePoolPair.Token2PoolValue = newTokenPoolValueToBuy
+			hop.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: addingAmt}
+			hop.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: receiveAmt}
+		} else {
+			pdePoolPair.Token1PoolValue = newTokenPoolValueToBuy
+			pdePoolPair.Token2PoolValue += addingAmt
+			hop.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: receiveAmt}
+			hop.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: addingAmt}
+		}
+		hops = append(hops, hop)
+		sellAmount = receiveAmt
+	}
+	if tradeMeta.MinAcceptableAmount > sellAmount {
+		return buildPDEMultiHopTradeRefundInst(contentStr, shardID, metaType), nil
+	}
+
+	// all hops succeeded, update current pde state on mem
+	for pairKey, pdePoolPair := range updatedPoolPairs {
+		currentPDEState.PDEPoolPairs[pairKey] = pdePoolPair
+	}
+	for _, hop := range hops {
+		pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, hop.Token1IDStr, hop.Token2IDStr))
+		creditPDETradingFee(beaconHeight, currentPDEState.PDEPoolPairs[pairKey], hop.TokenIDToSellStr, hop.ProtocolFee, currentPDEState)
+	}
+	pdeMultiHopTradeAcceptedContent := metadata.PDEMultiHopTradeAcceptedContent{
+		TraderAddressStr: tradeMeta.TraderAddressStr,
+		TokenIDToBuyStr:  tradeMeta.TradePath[len(tradeMeta.TradePath)-1],
+		ReceiveAmount:    sellAmount,
+		Hops:             hops,
+		ShardID:          shardID,
+		RequestedTxID:    pdeMultiHopTradeReqAction.TxReqID,
+	}
+	pdeMultiHopTradeAcceptedContentBytes, err := json.Marshal(pdeMultiHopTradeAcceptedContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while marshaling pdeMultiHopTradeAcceptedContent: %+v", err)
+		return [][]string{}, nil
+	}
+	inst := []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		common.PDETradeAcceptedChainStatus,
+		string(pdeMultiHopTradeAcceptedContentBytes),
+	}
+	return [][]string{inst}, nil
+}
+
 func buildPDEWithdrawalAcceptedInst(
 	wdMeta metadata.PDEWithdrawalRequest,
 	shardID byte,
@@ -539,6 +697,7 @@ func buildPDEWithdrawalAcceptedInst(
 	withdrawalTokenIDStr string,
 	deductingPoolValue uint64,
 	deductingShares uint64,
+	tradingFeeAmt uint64,
 	txReqID common.Hash,
 ) ([]string, error) {
 	wdAcceptedContent := metadata.PDEWithdrawalAcceptedContent{
@@ -546,6 +705,7 @@ func buildPDEWithdrawalAcceptedInst(
 		WithdrawerAddressStr: wdMeta.WithdrawerAddressStr,
 		DeductingPoolValue:   deductingPoolValue,
 		DeductingShares:      deductingShares,
+		TradingFeeAmt:        tradingFeeAmt,
 		PairToken1IDStr:      wdMeta.WithdrawalToken1IDStr,
 		PairToken2IDStr:      wdMeta.WithdrawalToken2IDStr,
 		TxReqID:              txReqID,
@@ -637,6 +797,20 @@ func deductPDEAmountsV2(
 		currentPDEState.PDEShares[shareForWithdrawerKey] -= wdSharesForWithdrawer
 	}
 	deductingAmounts.Shares = wdSharesForWithdrawer
+
+	// trading fees accrued to the withdrawer are paid out along with the withdrawal
+	deductingAmounts.TradingFee1 = claimPDETradingFee(
+		beaconHeight,
+		wdMeta.WithdrawalToken1IDStr, wdMeta.WithdrawalToken2IDStr,
+		deductingAmounts.Token1IDStr, wdMeta.WithdrawerAddressStr,
+		currentPDEState,
+	)
+	deductingAmounts.TradingFee2 = claimPDETradingFee(
+		beaconHeight,
+		wdMeta.WithdrawalToken1IDStr, wdMeta.WithdrawalToken2IDStr,
+		deductingAmounts.Token2IDStr, wdMeta.WithdrawerAddressStr,
+		currentPDEState,
+	)
 	return deductingAmounts
 }
 
@@ -687,6 +861,7 @@ func (blockchain *BlockChain) buildInstructionsForPDEWithdrawal(
 		deductingAmounts.Token1IDStr,
 		deductingAmounts.PoolValue1,
 		deductingAmounts.Shares,
+		deductingAmounts.TradingFee1,
 		pdeWithdrawalRequestAction.TxReqID,
 	)
 	if err != nil {
@@ -700,6 +875,7 @@ func (blockchain *BlockChain) buildInstructionsForPDEWithdrawal(
 		deductingAmounts.Token2IDStr,
 		deductingAmounts.PoolValue2,
 		0,
+		deductingAmounts.TradingFee2,
 		pdeWithdrawalRequestAction.TxReqID,
 	)
 	if err != nil {
@@ -708,3 +884,374 @@ func (blockchain *BlockChain) buildInstructionsForPDEWithdrawal(
 	insts = append(insts, inst2)
 	return insts, nil
 }
+
+func newPDELimitOrder(pdeLimitOrderReqAction *metadata.PDELimitOrderRequestAction) *lvdb.PDELimitOrder {
+	meta := pdeLimitOrderReqAction.Meta
+	return &lvdb.PDELimitOrder{
+		OrderID:             pdeLimitOrderReqAction.TxReqID.String(),
+		TraderAddressStr:    meta.TraderAddressStr,
+		TokenIDToBuyStr:     meta.TokenIDToBuyStr,
+		TokenIDToSellStr:    meta.TokenIDToSellStr,
+		SellAmount:          meta.SellAmount,
+		MinAcceptableAmount: meta.MinAcceptableAmount,
+		TradingFee:          meta.TradingFee,
+		ShardID:             pdeLimitOrderReqAction.ShardID,
+		TxReqID:             pdeLimitOrderReqAction.TxReqID,
+	}
+}
+
+func buildPDELimitOrderRefundInst(
+	order *lvdb.PDELimitOrder,
+	metaType int,
+) ([]string, error) {
+	refundContent := metadata.PDELimitOrderRefundContent{
+		OrderID:          order.OrderID,
+		TraderAddressStr: order.TraderAddressStr,
+		TokenIDStr:       order.TokenIDToSellStr,
+		Amount:           order.SellAmount + order.TradingFee,
+		ShardID:          order.ShardID,
+		RequestedTxID:    order.TxReqID,
+	}
+	refundContentBytes, err := json.Marshal(refundContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while marshaling PDELimitOrderRefundContent: %+v", err)
+		return []string{}, err
+	}
+	return []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(order.ShardID)),
+		common.PDELimitOrderRefundChainStatus,
+		string(refundContentBytes),
+	}, nil
+}
+
+// buildPDELimitOrderFilledInst builds an instruction paying receiveAmt to the owner of a limit order,
+// addingAmt and deductingAmt are added to the selling side and deducted from the buying side of the pool pair
+func buildPDELimitOrderFilledInst(
+	order *lvdb.PDELimitOrder,
+	receiveAmt uint64,
+	pdePoolPair *lvdb.PDEPoolForPair,
+	addingAmt uint64,
+	deductingAmt uint64,
+	protocolFee uint64,
+	metaType int,
+) ([]string, error) {
+	filledContent := metadata.PDELimitOrderFilledContent{
+		OrderID:          order.OrderID,
+		TraderAddressStr: order.TraderAddressStr,
+		TokenIDToBuyStr:  order.TokenIDToBuyStr,
+		ReceiveAmount:    receiveAmt,
+		Token1IDStr:      pdePoolPair.Token1IDStr,
+		Token2IDStr:      pdePoolPair.Token2IDStr,
+		ProtocolFee:      protocolFee,
+		ShardID:          order.ShardID,
+		RequestedTxID:    order.TxReqID,
+	}
+	filledContent.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: deductingAmt}
+	filledContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: addingAmt}
+	if pdePoolPair.Token1IDStr == order.TokenIDToSellStr {
+		filledContent.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: addingAmt}
+		filledContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: deductingAmt}
+	}
+	filledContentBytes, err := json.Marshal(filledContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while marshaling PDELimitOrderFilledContent: %+v", err)
+		return []string{}, err
+	}
+	return []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(order.ShardID)),
+		common.PDELimitOrderFilledChainStatus,
+		string(filledContentBytes),
+	}, nil
+}
+
+// addPDEPoolValue adds an amount to the pool value of a token of a pool pair
+func addPDEPoolValue(pdePoolPair *lvdb.PDEPoolForPair, tokenIDStr string, amt uint64) {
+	if pdePoolPair.Token1IDStr == tokenIDStr {
+		pdePoolPair.Token1PoolValue += amt
+		return
+	}
+	pdePoolPair.Token2PoolValue += amt
+}
+
+// findPDELimitOrderToMatch looks for the resting limit order selling tokenIDToBuyStr for tokenIDToSellStr
+// that is entirely filled by sellAmount and pays at least minReceiveAmt, the order paying the most wins
+func findPDELimitOrderToMatch(
+	currentPDEState *CurrentPDEState,
+	tokenIDToBuyStr string,
+	tokenIDToSellStr string,
+	sellAmount uint64,
+	minReceiveAmt uint64,
+) (string, *lvdb.PDELimitOrder) {
+	var orderKeys []string
+	for orderKey := range currentPDEState.PDELimitOrders {
+		orderKeys = append(orderKeys, orderKey)
+	}
+	sort.Strings(orderKeys)
+	matchedOrderKey := ""
+	var matchedOrder *lvdb.PDELimitOrder
+	for _, orderKey := range orderKeys {
+		order := currentPDEState.PDELimitOrders[orderKey]
+		if order == nil ||
+			order.TokenIDToSellStr != tokenIDToBuyStr ||
+			order.TokenIDToBuyStr != tokenIDToSellStr ||
+			order.MinAcceptableAmount > sellAmount ||
+			order.SellAmount < minReceiveAmt {
+			continue
+		}
+		if matchedOrder == nil || order.SellAmount > matchedOrder.SellAmount {
+			matchedOrderKey = orderKey
+			matchedOrder = order
+		}
+	}
+	return matchedOrderKey, matchedOrder
+}
+
+// buildInstructionsForPDETradeOnLimitOrders fills a trade with a resting limit order of the opposite side
+// if the order pays the trader at least as much as the pool pair does,
+// it returns false if no order matches so that the trade goes to the pool pair
+func (blockchain *BlockChain) buildInstructionsForPDETradeOnLimitOrders(
+	pdeTradeReqAction metadata.PDETradeRequestAction,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) ([][]string, bool) {
+	if currentPDEState == nil || len(currentPDEState.PDELimitOrders) == 0 {
+		return [][]string{}, false
+	}
+	tradeMeta := pdeTradeReqAction.Meta
+	pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, tradeMeta.TokenIDToBuyStr, tradeMeta.TokenIDToSellStr))
+	pdePoolPair, found := currentPDEState.PDEPoolPairs[pairKey]
+	if !found || pdePoolPair == nil {
+		return [][]string{}, false
+	}
+	minReceiveAmt := tradeMeta.MinAcceptableAmount
+	protocolFee := blockchain.computePDEProtocolFee(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, tradeMeta.SellAmount)
+	poolReceiveAmt, _, ok := computePDETradeHop(pdePoolPair, tradeMeta.TokenIDToSellStr, tradeMeta.SellAmount-protocolFee)
+	if ok && poolReceiveAmt > minReceiveAmt {
+		minReceiveAmt = poolReceiveAmt
+	}
+	orderKey, order := findPDELimitOrderToMatch(
+		currentPDEState,
+		tradeMeta.TokenIDToBuyStr,
+		tradeMeta.TokenIDToSellStr,
+		tradeMeta.SellAmount,
+		minReceiveAmt,
+	)
+	if order == nil {
+		return [][]string{}, false
+	}
+
+	// tokens are swapped between the trader and the order owner without protocol fee
+	// since no liquidity of the pool pair is used, trading fees go to the pool pair
+	pdeTradeAcceptedContent := metadata.PDETradeAcceptedContent{
+		TraderAddressStr: tradeMeta.TraderAddressStr,
+		TokenIDToBuyStr:  tradeMeta.TokenIDToBuyStr,
+		ReceiveAmount:    order.SellAmount,
+		Token1IDStr:      pdePoolPair.Token1IDStr,
+		Token2IDStr:      pdePoolPair.Token2IDStr,
+		ShardID:          pdeTradeReqAction.ShardID,
+		RequestedTxID:    pdeTradeReqAction.TxReqID,
+	}
+	pdeTradeAcceptedContent.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: 0}
+	pdeTradeAcceptedContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: tradeMeta.TradingFee}
+	if pdePoolPair.Token1IDStr == tradeMeta.TokenIDToSellStr {
+		pdeTradeAcceptedContent.Token1PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "+", Value: tradeMeta.TradingFee}
+		pdeTradeAcceptedContent.Token2PoolValueOperation = metadata.TokenPoolValueOperation{Operator: "-", Value: 0}
+	}
+	pdeTradeAcceptedContentBytes, err := json.Marshal(pdeTradeAcceptedContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while marshaling pdeTradeAcceptedContent: %+v", err)
+		return [][]string{}, false
+	}
+	tradeInst := []string{
+		strconv.Itoa(metadata.PDETradeRequestMeta),
+		strconv.Itoa(int(pdeTradeReqAction.ShardID)),
+		common.PDETradeAcceptedChainStatus,
+		string(pdeTradeAcceptedContentBytes),
+	}
+	filledInst, err := buildPDELimitOrderFilledInst(order, tradeMeta.SellAmount, pdePoolPair, order.TradingFee, 0, 0, metadata.PDELimitOrderRequestMeta)
+	if err != nil {
+		return [][]string{}, false
+	}
+
+	// update current pde state on mem
+	addPDEPoolValue(pdePoolPair, tradeMeta.TokenIDToSellStr, tradeMeta.TradingFee)
+	addPDEPoolValue(pdePoolPair, order.TokenIDToSellStr, order.TradingFee)
+	delete(currentPDEState.PDELimitOrders, orderKey)
+	return [][]string{tradeInst, filledInst}, true
+}
+
+// fillPDELimitOrderOnPool fills a limit order with the pool pair if the pool price reaches the order's limit
+func (blockchain *BlockChain) fillPDELimitOrderOnPool(
+	order *lvdb.PDELimitOrder,
+	pdePoolPair *lvdb.PDEPoolForPair,
+	metaType int,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) ([]string, bool) {
+	protocolFee := blockchain.computePDEProtocolFee(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, order.SellAmount)
+	receiveAmt, newTokenPoolValueToBuy, ok := computePDETradeHop(pdePoolPair, order.TokenIDToSellStr, order.SellAmount-protocolFee)
+	if !ok || receiveAmt < order.MinAcceptableAmount {
+		return []string{}, false
+	}
+	addingAmt := order.SellAmount - protocolFee + order.TradingFee
+	inst, err := buildPDELimitOrderFilledInst(order, receiveAmt, pdePoolPair, addingAmt, receiveAmt, protocolFee, metaType)
+	if err != nil {
+		return []string{}, false
+	}
+	if pdePoolPair.Token1IDStr == order.TokenIDToSellStr {
+		pdePoolPair.Token1PoolValue += addingAmt
+		pdePoolPair.Token2PoolValue = newTokenPoolValueToBuy
+	} else {
+		pdePoolPair.Token1PoolValue = newTokenPoolValueToBuy
+		pdePoolPair.Token2PoolValue += addingAmt
+	}
+	creditPDETradingFee(beaconHeight, pdePoolPair, order.TokenIDToSellStr, protocolFee, currentPDEState)
+	return inst, true
+}
+
+func (blockchain *BlockChain) buildInstructionsForPDELimitOrder(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) ([][]string, error) {
+	pdeLimitOrderReqAction, err := metadata.ParsePDELimitOrderRequestAction(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while parsing pde limit order action: %+v", err)
+		return [][]string{}, nil
+	}
+	order := newPDELimitOrder(pdeLimitOrderReqAction)
+	if currentPDEState == nil {
+		inst, err := buildPDELimitOrderRefundInst(order, metaType)
+		if err != nil {
+			return [][]string{}, nil
+		}
+		return [][]string{inst}, nil
+	}
+	pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, order.TokenIDToBuyStr, order.TokenIDToSellStr))
+	pdePoolPair, found := currentPDEState.PDEPoolPairs[pairKey]
+	if !found || pdePoolPair == nil || pdePoolPair.Token1PoolValue == 0 || pdePoolPair.Token2PoolValue == 0 {
+		inst, err := buildPDELimitOrderRefundInst(order, metaType)
+		if err != nil {
+			return [][]string{}, nil
+		}
+		return [][]string{inst}, nil
+	}
+
+	// cross with a resting order of the opposite side
+	restingOrderKey, restingOrder := findPDELimitOrderToMatch(
+		currentPDEState,
+		order.TokenIDToBuyStr,
+		order.TokenIDToSellStr,
+		order.SellAmount,
+		order.MinAcceptableAmount,
+	)
+	if restingOrder != nil {
+		filledInst1, err := buildPDELimitOrderFilledInst(order, restingOrder.SellAmount, pdePoolPair, order.TradingFee, 0, 0, metaType)
+		if err != nil {
+			return [][]string{}, nil
+		}
+		filledInst2, err := buildPDELimitOrderFilledInst(restingOrder, order.SellAmount, pdePoolPair, restingOrder.TradingFee, 0, 0, metaType)
+		if err != nil {
+			return [][]string{}, nil
+		}
+		addPDEPoolValue(pdePoolPair, order.TokenIDToSellStr, order.TradingFee)
+		addPDEPoolValue(pdePoolPair, restingOrder.TokenIDToSellStr, restingOrder.TradingFee)
+		delete(currentPDEState.PDELimitOrders, restingOrderKey)
+		return [][]string{filledInst1, filledInst2}, nil
+	}
+
+	// fill immediately if the pool price already reaches the limit
+	if filledInst, ok := blockchain.fillPDELimitOrderOnPool(order, pdePoolPair, metaType, currentPDEState, beaconHeight); ok {
+		return [][]string{filledInst}, nil
+	}
+
+	orderKey := string(lvdb.BuildPDELimitOrderKey(beaconHeight, order.OrderID))
+	currentPDEState.PDELimitOrders[orderKey] = order
+	inst := []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		common.PDELimitOrderPlacedChainStatus,
+		contentStr,
+	}
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForPDECancelLimitOrder(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) ([][]string, error) {
+	pdeCancelLimitOrderReqAction, err := metadata.ParsePDECancelLimitOrderRequestAction(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while parsing pde cancel limit order action: %+v", err)
+		return [][]string{}, nil
+	}
+	rejectedInst := []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		common.PDECancelLimitOrderRejectedChainStatus,
+		contentStr,
+	}
+	if currentPDEState == nil {
+		return [][]string{rejectedInst}, nil
+	}
+	cancelMeta := pdeCancelLimitOrderReqAction.Meta
+	orderKey := string(lvdb.BuildPDELimitOrderKey(beaconHeight, cancelMeta.OrderID))
+	order, found := currentPDEState.PDELimitOrders[orderKey]
+	if !found || order == nil || order.TraderAddressStr != cancelMeta.TraderAddressStr {
+		return [][]string{rejectedInst}, nil
+	}
+	refundInst, err := buildPDELimitOrderRefundInst(order, metadata.PDELimitOrderRequestMeta)
+	if err != nil {
+		return [][]string{}, nil
+	}
+	delete(currentPDEState.PDELimitOrders, orderKey)
+	acceptedInst := []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		common.PDECancelLimitOrderAcceptedChainStatus,
+		contentStr,
+	}
+	return [][]string{acceptedInst, refundInst}, nil
+}
+
+// buildInstructionsForPDELimitOrdersOnPoolPairs fills resting limit orders whose limit is reached
+// by pool prices at the end of the block, orders are visited in the order of their keys
+func (blockchain *BlockChain) buildInstructionsForPDELimitOrdersOnPoolPairs(
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+) [][]string {
+	insts := [][]string{}
+	if currentPDEState == nil || len(currentPDEState.PDELimitOrders) == 0 {
+		return insts
+	}
+	var orderKeys []string
+	for orderKey := range currentPDEState.PDELimitOrders {
+		orderKeys = append(orderKeys, orderKey)
+	}
+	sort.Strings(orderKeys)
+	for _, orderKey := range orderKeys {
+		order := currentPDEState.PDELimitOrders[orderKey]
+		if order == nil {
+			continue
+		}
+		pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, order.TokenIDToBuyStr, order.TokenIDToSellStr))
+		pdePoolPair, found := currentPDEState.PDEPoolPairs[pairKey]
+		if !found || pdePoolPair == nil {
+			continue
+		}
+		filledInst, ok := blockchain.fillPDELimitOrderOnPool(order, pdePoolPair, metadata.PDELimitOrderRequestMeta, currentPDEState, beaconHeight)
+		if !ok {
+			continue
+		}
+		delete(currentPDEState.PDELimitOrders, orderKey)
+		insts = append(insts, filledInst)
+	}
+	return insts
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/incognitochain/incognito-chain/common"
@@ -27,6 +28,8 @@ func (suite *PDEProducerSuite) SetupTest() {
 		WaitingPDEContributions: make(map[string]*lvdb.PDEContribution),
 		PDEPoolPairs:            make(map[string]*lvdb.PDEPoolForPair),
 		PDEShares:               make(map[string]uint64),
+		PDELimitOrders:          make(map[string]*lvdb.PDELimitOrder),
+		PDETradingFees:          make(map[string]uint64),
 	}
 }
 
@@ -637,6 +640,487 @@ func (suite *PDEProducerSuite) TestWithdrawExceededSharesOnToken2OfExistedPair()
 }
 
 // In order for 'go test' to run this suite, we need to create
+func buildPDEMultiHopTradeReqAction(
+	tradePath []string,
+	sellAmount uint64,
+	minAcceptableAmount uint64,
+	tradingFee uint64,
+	traderAddressStr string,
+) []string {
+	pdeMultiHopTradeRequest, _ := metadata.NewPDEMultiHopTradeRequest(
+		tradePath,
+		sellAmount,
+		minAcceptableAmount,
+		tradingFee,
+		traderAddressStr,
+		metadata.PDEMultiHopTradeRequestMeta,
+	)
+	actionContent := metadata.PDEMultiHopTradeRequestAction{
+		Meta:    *pdeMultiHopTradeRequest,
+		TxReqID: common.Hash{},
+		ShardID: 1,
+	}
+	actionContentBytes, _ := json.Marshal(actionContent)
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	return []string{strconv.Itoa(metadata.PDEMultiHopTradeRequestMeta), actionContentBase64Str}
+}
+
+func (suite *PDEProducerSuite) setupMultiHopPoolPairs(beaconHeight uint64) {
+	suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000005"))] = &lvdb.PDEPoolForPair{
+		Token1IDStr:     "0000000000000000000000000000000000000000000000000000000000000004",
+		Token1PoolValue: 10000000000000,
+		Token2IDStr:     "0000000000000000000000000000000000000000000000000000000000000005",
+		Token2PoolValue: 2000000000,
+	}
+	suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000006"))] = &lvdb.PDEPoolForPair{
+		Token1IDStr:     "0000000000000000000000000000000000000000000000000000000000000004",
+		Token1PoolValue: 20000000000000,
+		Token2IDStr:     "0000000000000000000000000000000000000000000000000000000000000006",
+		Token2PoolValue: 500000000000,
+	}
+}
+
+func (suite *PDEProducerSuite) TestMultiHopTradeThroughPRV() {
+	fmt.Println("Running testcase: TestMultiHopTradeThroughPRV")
+	beaconHeight := uint64(1000)
+	suite.setupMultiHopPoolPairs(beaconHeight)
+	reqAction := buildPDEMultiHopTradeReqAction(
+		[]string{
+			"0000000000000000000000000000000000000000000000000000000000000005",
+			"0000000000000000000000000000000000000000000000000000000000000004",
+			"0000000000000000000000000000000000000000000000000000000000000006",
+		},
+		1000000,
+		1,
+		100,
+		"12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj",
+	)
+	bc := &BlockChain{}
+	newInsts, err := bc.buildInstructionsForPDEMultiHopTrade(reqAction[1], 1, metadata.PDEMultiHopTradeRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	suite.Equal(common.PDETradeAcceptedChainStatus, newInsts[0][2])
+
+	var acceptedContent metadata.PDEMultiHopTradeAcceptedContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[0][3]), &acceptedContent))
+	suite.Equal(2, len(acceptedContent.Hops))
+	// 1000000 token 5 -> 4997501249 PRV -> 124906320 token 6
+	suite.Equal(uint64(124906320), acceptedContent.ReceiveAmount)
+	suite.Equal(uint64(4997501249), acceptedContent.Hops[0].ReceiveAmount)
+	suite.Equal(uint64(4997501249), acceptedContent.Hops[1].SellAmount)
+	suite.Equal(acceptedContent.Hops[1].ReceiveAmount, acceptedContent.ReceiveAmount)
+	suite.Equal("0000000000000000000000000000000000000000000000000000000000000006", acceptedContent.TokenIDToBuyStr)
+
+	pool1 := suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000005"))]
+	suite.Equal(uint64(10000000000000-4997501249), pool1.Token1PoolValue)
+	suite.Equal(uint64(2000000000+1000000+100), pool1.Token2PoolValue)
+	pool2 := suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000006"))]
+	suite.Equal(uint64(20000000000000+4997501249), pool2.Token1PoolValue)
+	suite.Equal(uint64(500000000000)-acceptedContent.ReceiveAmount, pool2.Token2PoolValue)
+}
+
+func (suite *PDEProducerSuite) TestMultiHopTradeRefundedAtomically() {
+	fmt.Println("Running testcase: TestMultiHopTradeRefundedAtomically")
+	beaconHeight := uint64(1000)
+	suite.setupMultiHopPoolPairs(beaconHeight)
+	pool1 := suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000005"))]
+	bc := &BlockChain{}
+
+	// the second hop has no pool pair
+	reqAction := buildPDEMultiHopTradeReqAction(
+		[]string{
+			"0000000000000000000000000000000000000000000000000000000000000005",
+			"0000000000000000000000000000000000000000000000000000000000000004",
+			"0000000000000000000000000000000000000000000000000000000000000007",
+		},
+		1000000,
+		1,
+		100,
+		"12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj",
+	)
+	newInsts, err := bc.buildInstructionsForPDEMultiHopTrade(reqAction[1], 1, metadata.PDEMultiHopTradeRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(common.PDETradeRefundChainStatus, newInsts[0][2])
+	suite.Equal(reqAction[1], newInsts[0][3])
+	suite.Equal(uint64(10000000000000), pool1.Token1PoolValue)
+	suite.Equal(uint64(2000000000), pool1.Token2PoolValue)
+
+	// the final amount is less than the min acceptable amount
+	reqAction = buildPDEMultiHopTradeReqAction(
+		[]string{
+			"0000000000000000000000000000000000000000000000000000000000000005",
+			"0000000000000000000000000000000000000000000000000000000000000004",
+			"0000000000000000000000000000000000000000000000000000000000000006",
+		},
+		1000000,
+		200000000,
+		100,
+		"12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj",
+	)
+	newInsts, err = bc.buildInstructionsForPDEMultiHopTrade(reqAction[1], 1, metadata.PDEMultiHopTradeRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(common.PDETradeRefundChainStatus, newInsts[0][2])
+	suite.Equal(uint64(10000000000000), pool1.Token1PoolValue)
+	suite.Equal(uint64(2000000000), pool1.Token2PoolValue)
+}
+
+func buildPDELimitOrderReqAction(
+	tokenIDToBuyStr string,
+	tokenIDToSellStr string,
+	sellAmount uint64,
+	minAcceptableAmount uint64,
+	tradingFee uint64,
+	traderAddressStr string,
+	txReqID common.Hash,
+) []string {
+	pdeLimitOrderRequest, _ := metadata.NewPDELimitOrderRequest(
+		tokenIDToBuyStr,
+		tokenIDToSellStr,
+		sellAmount,
+		minAcceptableAmount,
+		tradingFee,
+		traderAddressStr,
+		metadata.PDELimitOrderRequestMeta,
+	)
+	actionContent := metadata.PDELimitOrderRequestAction{
+		Meta:    *pdeLimitOrderRequest,
+		TxReqID: txReqID,
+		ShardID: 1,
+	}
+	actionContentBytes, _ := json.Marshal(actionContent)
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	return []string{strconv.Itoa(metadata.PDELimitOrderRequestMeta), actionContentBase64Str}
+}
+
+func buildPDECancelLimitOrderReqAction(orderID string, traderAddressStr string) []string {
+	pdeCancelLimitOrderRequest, _ := metadata.NewPDECancelLimitOrderRequest(
+		orderID,
+		traderAddressStr,
+		metadata.PDECancelLimitOrderRequestMeta,
+	)
+	actionContent := metadata.PDECancelLimitOrderRequestAction{
+		Meta:    *pdeCancelLimitOrderRequest,
+		TxReqID: common.Hash{9},
+		ShardID: 1,
+	}
+	actionContentBytes, _ := json.Marshal(actionContent)
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	return []string{strconv.Itoa(metadata.PDECancelLimitOrderRequestMeta), actionContentBase64Str}
+}
+
+func (suite *PDEProducerSuite) TestLimitOrderFilledByPoolPriceMove() {
+	fmt.Println("Running testcase: TestLimitOrderFilledByPoolPriceMove")
+	beaconHeight := uint64(1000)
+	suite.setupMultiHopPoolPairs(beaconHeight)
+	bc := &BlockChain{}
+	traderAddr := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+
+	// the pool pays 4997501249 PRV for 1000000 token 5, the order asks for more
+	orderAction := buildPDELimitOrderReqAction(
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		1000000,
+		6000000000,
+		100,
+		traderAddr,
+		common.Hash{1},
+	)
+	newInsts, err := bc.handlePDEInsts(
+		beaconHeight, suite.currentPDEState,
+		map[byte][][]string{}, map[byte][][]string{}, map[byte][][]string{}, map[byte][][]string{},
+		map[byte][][]string{1: {orderAction}}, map[byte][][]string{},
+	)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	suite.Equal(common.PDELimitOrderPlacedChainStatus, newInsts[0][2])
+	suite.Equal(1, len(suite.currentPDEState.PDELimitOrders))
+
+	// a trade buying token 5 raises its price over the limit of the order
+	tradeAction := buildPDETradeReqAction(
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		3000000000000,
+		traderAddr,
+	)
+	newInsts, err = bc.handlePDEInsts(
+		beaconHeight, suite.currentPDEState,
+		map[byte][][]string{}, map[byte][][]string{1: {tradeAction}}, map[byte][][]string{}, map[byte][][]string{},
+		map[byte][][]string{}, map[byte][][]string{},
+	)
+	suite.Equal(nil, err)
+	suite.Equal(2, len(newInsts))
+	suite.Equal(strconv.Itoa(metadata.PDETradeRequestMeta), newInsts[0][0])
+	suite.Equal(strconv.Itoa(metadata.PDELimitOrderRequestMeta), newInsts[1][0])
+	suite.Equal(common.PDELimitOrderFilledChainStatus, newInsts[1][2])
+	var filledContent metadata.PDELimitOrderFilledContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[1][3]), &filledContent))
+	suite.Equal(common.Hash{1}.String(), filledContent.OrderID)
+	suite.Equal("0000000000000000000000000000000000000000000000000000000000000004", filledContent.TokenIDToBuyStr)
+	suite.True(filledContent.ReceiveAmount >= 6000000000)
+	suite.Equal(uint64(1000100), filledContent.Token2PoolValueOperation.Value)
+	suite.Equal(0, len(suite.currentPDEState.PDELimitOrders))
+}
+
+func (suite *PDEProducerSuite) TestTradeMatchedWithLimitOrder() {
+	fmt.Println("Running testcase: TestTradeMatchedWithLimitOrder")
+	beaconHeight := uint64(1000)
+	suite.setupMultiHopPoolPairs(beaconHeight)
+	bc := &BlockChain{}
+	traderAddr := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+
+	// the pool pays 999500 token 5 for 5000000000 PRV, the order asks for 1000000
+	orderAction := buildPDELimitOrderReqAction(
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		5000000000,
+		1000000,
+		10,
+		traderAddr,
+		common.Hash{1},
+	)
+	newInsts, err := bc.buildInstructionsForPDELimitOrder(orderAction[1], 1, metadata.PDELimitOrderRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(common.PDELimitOrderPlacedChainStatus, newInsts[0][2])
+
+	// the pool pays 4997501249 PRV for 1000000 token 5, the order pays more
+	tradeAction := buildPDETradeReqAction(
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		1000000,
+		traderAddr,
+	)
+	newInsts, err = bc.handlePDEInsts(
+		beaconHeight, suite.currentPDEState,
+		map[byte][][]string{}, map[byte][][]string{1: {tradeAction}}, map[byte][][]string{}, map[byte][][]string{},
+		map[byte][][]string{}, map[byte][][]string{},
+	)
+	suite.Equal(nil, err)
+	suite.Equal(2, len(newInsts))
+	var tradeAcceptedContent metadata.PDETradeAcceptedContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[0][3]), &tradeAcceptedContent))
+	suite.Equal(uint64(5000000000), tradeAcceptedContent.ReceiveAmount)
+	var filledContent metadata.PDELimitOrderFilledContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[1][3]), &filledContent))
+	suite.Equal(uint64(1000000), filledContent.ReceiveAmount)
+	suite.Equal(0, len(suite.currentPDEState.PDELimitOrders))
+
+	// pool values only get trading fees
+	pdePoolPair := suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(beaconHeight, "0000000000000000000000000000000000000000000000000000000000000004", "0000000000000000000000000000000000000000000000000000000000000005"))]
+	suite.Equal(uint64(10000000000010), pdePoolPair.Token1PoolValue)
+	suite.Equal(uint64(2000000000), pdePoolPair.Token2PoolValue)
+}
+
+func (suite *PDEProducerSuite) TestCancelLimitOrder() {
+	fmt.Println("Running testcase: TestCancelLimitOrder")
+	beaconHeight := uint64(1000)
+	suite.setupMultiHopPoolPairs(beaconHeight)
+	bc := &BlockChain{}
+	traderAddr := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	orderAction := buildPDELimitOrderReqAction(
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		1000000,
+		6000000000,
+		100,
+		traderAddr,
+		common.Hash{1},
+	)
+	_, err := bc.buildInstructionsForPDELimitOrder(orderAction[1], 1, metadata.PDELimitOrderRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(suite.currentPDEState.PDELimitOrders))
+
+	// only the owner can cancel the order
+	cancelAction := buildPDECancelLimitOrderReqAction(common.Hash{1}.String(), "another address")
+	newInsts, err := bc.buildInstructionsForPDECancelLimitOrder(cancelAction[1], 1, metadata.PDECancelLimitOrderRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	suite.Equal(common.PDECancelLimitOrderRejectedChainStatus, newInsts[0][2])
+	suite.Equal(1, len(suite.currentPDEState.PDELimitOrders))
+
+	cancelAction = buildPDECancelLimitOrderReqAction(common.Hash{1}.String(), traderAddr)
+	newInsts, err = bc.buildInstructionsForPDECancelLimitOrder(cancelAction[1], 1, metadata.PDECancelLimitOrderRequestMeta, suite.currentPDEState, beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(2, len(newInsts))
+	suite.Equal(common.PDECancelLimitOrderAcceptedChainStatus, newInsts[0][2])
+	suite.Equal(strconv.Itoa(metadata.PDELimitOrderRequestMeta), newInsts[1][0])
+	suite.Equal(common.PDELimitOrderRefundChainStatus, newInsts[1][2])
+	var refundContent metadata.PDELimitOrderRefundContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[1][3]), &refundContent))
+	suite.Equal(uint64(1000100), refundContent.Amount)
+	suite.Equal("0000000000000000000000000000000000000000000000000000000000000005", refundContent.TokenIDStr)
+	suite.Equal(0, len(suite.currentPDEState.PDELimitOrders))
+}
+
+func (suite *PDEProducerSuite) TestTradeCreditsProtocolFeeToShareHolders() {
+	fmt.Println("Running testcase: TestTradeCreditsProtocolFeeToShareHolders")
+	beaconHeight := uint64(1001)
+	token5IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token7IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	contributor1 := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	contributor2 := "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci"
+	pair := lvdb.PDEPoolForPair{
+		Token1IDStr:     token5IDStr,
+		Token1PoolValue: 1000000000000,
+		Token2IDStr:     token7IDStr,
+		Token2PoolValue: 1000000000000,
+	}
+	pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight-1, token5IDStr, token7IDStr))
+	suite.currentPDEState.PDEPoolPairs[pairKey] = &pair
+	shareKey1 := string(lvdb.BuildPDESharesKeyV2(beaconHeight-1, token5IDStr, token7IDStr, contributor1))
+	shareKey2 := string(lvdb.BuildPDESharesKeyV2(beaconHeight-1, token5IDStr, token7IDStr, contributor2))
+	suite.currentPDEState.PDEShares[shareKey1] = 300000000000
+	suite.currentPDEState.PDEShares[shareKey2] = 100000000000
+
+	// the pair overrides the default fee rate
+	bc := &BlockChain{
+		config: Config{
+			ChainParams: &Params{
+				PDEParams: PDEParams{
+					TradingFeeBPS: 30,
+					TradingFeeBPSByPairs: map[string]uint64{
+						BuildPDEPoolPairID(token7IDStr, token5IDStr): 50,
+					},
+				},
+			},
+		},
+	}
+	reqAction := buildPDETradeReqAction(token7IDStr, token5IDStr, 1000000000, contributor1)
+	metaType, _ := strconv.Atoi(reqAction[0])
+	newInsts, err := bc.buildInstructionsForPDETrade(reqAction[1], 1, metaType, suite.currentPDEState, beaconHeight-1)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	suite.Equal(common.PDETradeAcceptedChainStatus, newInsts[0][2])
+	var tradeAcceptedContent metadata.PDETradeAcceptedContent
+	suite.Equal(nil, json.Unmarshal([]byte(newInsts[0][3]), &tradeAcceptedContent))
+	suite.Equal(uint64(5000000), tradeAcceptedContent.ProtocolFee)
+	suite.Equal(uint64(994010959), tradeAcceptedContent.ReceiveAmount)
+	suite.Equal(uint64(995000000), tradeAcceptedContent.Token1PoolValueOperation.Value)
+	suite.Equal(uint64(1000995000000), pair.Token1PoolValue)
+
+	feeKey1 := string(lvdb.BuildPDETradingFeeKey(beaconHeight-1, token5IDStr, token7IDStr, token5IDStr, contributor1))
+	feeKey2 := string(lvdb.BuildPDETradingFeeKey(beaconHeight-1, token5IDStr, token7IDStr, token5IDStr, contributor2))
+	suite.Equal(uint64(3750000), suite.currentPDEState.PDETradingFees[feeKey1])
+	suite.Equal(uint64(1250000), suite.currentPDEState.PDETradingFees[feeKey2])
+
+	// accrued fees are paid out on withdrawal
+	wdReqAction := buildPDEWithdrawReqAction(contributor1, token5IDStr, 150000000000, token7IDStr, 0)
+	wdMetaType, _ := strconv.Atoi(wdReqAction[0])
+	wdInsts, err := bc.buildInstructionsForPDEWithdrawal(wdReqAction[1], 1, wdMetaType, suite.currentPDEState, beaconHeight-1)
+	suite.Equal(nil, err)
+	suite.Equal(2, len(wdInsts))
+	var wdAcceptedContent1, wdAcceptedContent2 metadata.PDEWithdrawalAcceptedContent
+	suite.Equal(nil, json.Unmarshal([]byte(wdInsts[0][3]), &wdAcceptedContent1))
+	suite.Equal(nil, json.Unmarshal([]byte(wdInsts[1][3]), &wdAcceptedContent2))
+	suite.Equal(token5IDStr, wdAcceptedContent1.WithdrawalTokenIDStr)
+	suite.Equal(uint64(3750000), wdAcceptedContent1.TradingFeeAmt)
+	suite.Equal(uint64(0), wdAcceptedContent2.TradingFeeAmt)
+	_, found := suite.currentPDEState.PDETradingFees[feeKey1]
+	suite.Equal(false, found)
+	suite.Equal(uint64(1250000), suite.currentPDEState.PDETradingFees[feeKey2])
+}
+
+func (suite *PDEProducerSuite) TestProtocolFeeCreditedToSharesOfPairOnly() {
+	fmt.Println("Running testcase: TestProtocolFeeCreditedToSharesOfPairOnly")
+	beaconHeight := uint64(1000)
+	token5IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token7IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	token9IDStr := "0000000000000000000000000000000000000000000000000000000000000009"
+	contributor1 := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	contributor2 := "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci"
+	pair := &lvdb.PDEPoolForPair{
+		Token1IDStr:     token5IDStr,
+		Token1PoolValue: 1000000,
+		Token2IDStr:     token7IDStr,
+		Token2PoolValue: 1000000,
+	}
+	suite.currentPDEState.PDEShares[string(lvdb.BuildPDESharesKeyV2(beaconHeight, token5IDStr, token7IDStr, contributor1))] = 500
+	suite.currentPDEState.PDEShares[string(lvdb.BuildPDESharesKeyV2(beaconHeight, token5IDStr, token9IDStr, contributor2))] = 500
+	feeKey1 := string(lvdb.BuildPDETradingFeeKey(beaconHeight, token5IDStr, token7IDStr, token5IDStr, contributor1))
+	feeKey2 := string(lvdb.BuildPDETradingFeeKey(beaconHeight, token5IDStr, token7IDStr, token5IDStr, contributor2))
+
+	// shares of other pairs get nothing
+	creditPDETradingFee(beaconHeight, pair, token5IDStr, 1000, suite.currentPDEState)
+	suite.Equal(uint64(1000), suite.currentPDEState.PDETradingFees[feeKey1])
+	suite.Equal(1, len(suite.currentPDEState.PDETradingFees))
+
+	// a share added after the index was built is credited
+	setPDEShare(suite.currentPDEState, string(lvdb.BuildPDESharesKeyV2(beaconHeight, token7IDStr, token5IDStr, contributor2)), 500)
+	creditPDETradingFee(beaconHeight, pair, token5IDStr, 1000, suite.currentPDEState)
+	suite.Equal(uint64(1500), suite.currentPDEState.PDETradingFees[feeKey1])
+	suite.Equal(uint64(500), suite.currentPDEState.PDETradingFees[feeKey2])
+	suite.Equal(uint64(1000000), pair.Token1PoolValue)
+}
+
+func (suite *PDEProducerSuite) TestProtocolFeeGoesToPoolWithoutShareHolders() {
+	fmt.Println("Running testcase: TestProtocolFeeGoesToPoolWithoutShareHolders")
+	beaconHeight := uint64(1001)
+	token5IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token7IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	pair := lvdb.PDEPoolForPair{
+		Token1IDStr:     token5IDStr,
+		Token1PoolValue: 1000000000000,
+		Token2IDStr:     token7IDStr,
+		Token2PoolValue: 1000000000000,
+	}
+	pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight-1, token5IDStr, token7IDStr))
+	suite.currentPDEState.PDEPoolPairs[pairKey] = &pair
+	bc := &BlockChain{
+		config: Config{
+			ChainParams: &Params{
+				PDEParams: PDEParams{TradingFeeBPS: 50},
+			},
+		},
+	}
+	reqAction := buildPDETradeReqAction(token7IDStr, token5IDStr, 1000000000, "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj")
+	metaType, _ := strconv.Atoi(reqAction[0])
+	newInsts, err := bc.buildInstructionsForPDETrade(reqAction[1], 1, metaType, suite.currentPDEState, beaconHeight-1)
+	suite.Equal(nil, err)
+	suite.Equal(common.PDETradeAcceptedChainStatus, newInsts[0][2])
+	suite.Equal(uint64(1001000000000), pair.Token1PoolValue)
+	suite.Equal(0, len(suite.currentPDEState.PDETradingFees))
+}
+
+func (suite *PDEProducerSuite) TestProtocolFeeActivationHeight() {
+	fmt.Println("Running testcase: TestProtocolFeeActivationHeight")
+	beaconHeight := uint64(1001)
+	token5IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token7IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	traderAddressStr := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	bc := &BlockChain{
+		config: Config{
+			ChainParams: &Params{
+				PDEParams: PDEParams{TradingFeeBPS: 50, ProtocolFeeActivationHeight: beaconHeight + 1},
+			},
+		},
+	}
+	for _, height := range []uint64{beaconHeight - 1, beaconHeight} {
+		pair := lvdb.PDEPoolForPair{
+			Token1IDStr:     token5IDStr,
+			Token1PoolValue: 1000000000000,
+			Token2IDStr:     token7IDStr,
+			Token2PoolValue: 1000000000000,
+		}
+		suite.currentPDEState.PDEPoolPairs[string(lvdb.BuildPDEPoolForPairKey(height, token5IDStr, token7IDStr))] = &pair
+		reqAction := buildPDETradeReqAction(token7IDStr, token5IDStr, 1000000000, traderAddressStr)
+		metaType, _ := strconv.Atoi(reqAction[0])
+		newInsts, err := bc.buildInstructionsForPDETrade(reqAction[1], 1, metaType, suite.currentPDEState, height)
+		suite.Equal(nil, err)
+		suite.Equal(common.PDETradeAcceptedChainStatus, newInsts[0][2])
+		var tradeAcceptedContent metadata.PDETradeAcceptedContent
+		suite.Equal(nil, json.Unmarshal([]byte(newInsts[0][3]), &tradeAcceptedContent))
+		if height+1 < bc.config.ChainParams.PDEParams.ProtocolFeeActivationHeight {
+			// instructions before the activation height are the ones built without protocol fee
+			suite.Equal(false, strings.Contains(newInsts[0][3], "ProtocolFee"))
+			suite.Equal(uint64(0), tradeAcceptedContent.ProtocolFee)
+			suite.Equal(uint64(1001000000000), pair.Token1PoolValue)
+		} else {
+			suite.Equal(uint64(5000000), tradeAcceptedContent.ProtocolFee)
+			suite.Equal(uint64(1001000000000), pair.Token1PoolValue)
+			suite.Equal(uint64(995000000), tradeAcceptedContent.Token1PoolValueOperation.Value)
+		}
+	}
+}
+
 // a normal test function and pass our suite to suite.Run
 func TestPDEProducerSuite(t *testing.T) {
 	fmt.Println("Initialized...")
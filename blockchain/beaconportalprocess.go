@@ -0,0 +1,512 @@
+package blockchain
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+)
+
+func trackPortalStatus(
+	db database.DatabaseInterface,
+	prefix []byte,
+	suffix string,
+	status interface{},
+) {
+	statusBytes, _ := json.Marshal(status)
+	err := db.TrackPortalStatus(prefix, []byte(suffix), statusBytes)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while tracking portal status: %+v", err)
+	}
+}
+
+// isPortalStatusTracked is used to avoid rejected requests with duplicated ids overriding status of the original ones
+func isPortalStatusTracked(
+	db database.DatabaseInterface,
+	prefix []byte,
+	suffix string,
+) bool {
+	statusBytes, err := db.GetPortalStatus(prefix, []byte(suffix))
+	return err == nil && len(statusBytes) > 0
+}
+
+func (blockchain *BlockChain) processPortalCustodianDeposit(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var depositContent metadata.PortalCustodianDepositContent
+	err := json.Unmarshal([]byte(instruction[3]), &depositContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal custodian deposit instruction: %+v", err)
+		return nil
+	}
+	if instruction[2] != common.PortalCustodianDepositAcceptedChainStatus {
+		return nil
+	}
+	updateCustodianStateAfterDeposit(currentPortalState, beaconHeight, depositContent)
+	trackPortalStatus(
+		blockchain.GetDatabase(),
+		lvdb.PortalCustodianDepositStatusPrefix,
+		depositContent.TxReqID.String(),
+		metadata.PortalCustodianDepositStatus{
+			Status:          common.PortalCustodianDepositAcceptedStatus,
+			IncogAddressStr: depositContent.IncogAddressStr,
+			RemoteAddresses: depositContent.RemoteAddresses,
+			DepositedAmount: depositContent.DepositedAmount,
+		},
+	)
+	return nil
+}
+
+func (blockchain *BlockChain) processPortalPortingRequest(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var portingReqContent metadata.PortalPortingRequestContent
+	err := json.Unmarshal([]byte(instruction[3]), &portingReqContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal porting request instruction: %+v", err)
+		return nil
+	}
+	var status byte
+	switch instruction[2] {
+	case common.PortalPortingRequestAcceptedChainStatus:
+		updateStateAfterPortingRequestAccepted(currentPortalState, beaconHeight, portingReqContent)
+		status = common.PortalPortingReqWaitingStatus
+	case common.PortalPortingRequestRejectedChainStatus:
+		if isPortalStatusTracked(blockchain.GetDatabase(), lvdb.PortalPortingRequestStatusPrefix, portingReqContent.UniqueRegisterId) {
+			return nil
+		}
+		status = common.PortalPortingReqRejectedStatus
+	case common.PortalPortingRequestExpiredChainStatus:
+		updateStateAfterPortingRequestExpired(currentPortalState, beaconHeight, portingReqContent)
+		status = common.PortalPortingReqExpiredStatus
+	default:
+		return nil
+	}
+	trackPortalStatus(
+		blockchain.GetDatabase(),
+		lvdb.PortalPortingRequestStatusPrefix,
+		portingReqContent.UniqueRegisterId,
+		metadata.PortalPortingRequestStatus{
+			Status:          status,
+			UniquePortingID: portingReqContent.UniqueRegisterId,
+			TxReqID:         portingReqContent.TxReqID,
+			TokenID:         portingReqContent.PTokenId,
+			PorterAddress:   portingReqContent.IncogAddressStr,
+			Amount:          portingReqContent.RegisterAmount,
+			Custodians:      portingReqContent.Custodian,
+			PortingFee:      portingReqContent.PortingFee,
+			BeaconHeight:    beaconHeight + 1,
+		},
+	)
+	return nil
+}
+
+func (blockchain *BlockChain) processPortalReqPTokens(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var reqPTokensContent metadata.PortalRequestPTokensContent
+	err := json.Unmarshal([]byte(instruction[3]), &reqPTokensContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal request ptokens instruction: %+v", err)
+		return nil
+	}
+	db := blockchain.GetDatabase()
+	var status byte
+	switch instruction[2] {
+	case common.PortalReqPTokensAcceptedChainStatus:
+		updateStateAfterReqPTokensAccepted(currentPortalState, beaconHeight, reqPTokensContent)
+		err = db.Put(lvdb.BuildPortalExternalTxKey(reqPTokensContent.TokenID, reqPTokensContent.ExternalTxID), []byte{1})
+		if err != nil {
+			return database.NewDatabaseError(database.StorePortalStateError, err)
+		}
+		status = common.PortalReqPTokenAcceptedStatus
+		// porting request is done
+		trackPortalStatus(
+			db,
+			lvdb.PortalPortingRequestStatusPrefix,
+			reqPTokensContent.UniquePortingID,
+			metadata.PortalPortingRequestStatus{
+				Status:          common.PortalPortingReqSuccessStatus,
+				UniquePortingID: reqPTokensContent.UniquePortingID,
+				TokenID:         reqPTokensContent.TokenID,
+				PorterAddress:   reqPTokensContent.IncogAddressStr,
+				Amount:          reqPTokensContent.PortingAmount,
+				BeaconHeight:    beaconHeight + 1,
+			},
+		)
+	case common.PortalReqPTokensRejectedChainStatus:
+		status = common.PortalReqPTokenRejectedStatus
+	default:
+		return nil
+	}
+	trackPortalStatus(
+		db,
+		lvdb.PortalReqPTokenStatusPrefix,
+		reqPTokensContent.TxReqID.String(),
+		metadata.PortalRequestPTokensStatus{
+			Status:          status,
+			UniquePortingID: reqPTokensContent.UniquePortingID,
+			TokenID:         reqPTokensContent.TokenID,
+			IncogAddressStr: reqPTokensContent.IncogAddressStr,
+			PortingAmount:   reqPTokensContent.PortingAmount,
+			PortingProof:    reqPTokensContent.PortingProof,
+		},
+	)
+	return nil
+}
+
+func (blockchain *BlockChain) processPortalRedeemRequest(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var redeemReqContent metadata.PortalRedeemRequestContent
+	err := json.Unmarshal([]byte(instruction[3]), &redeemReqContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal redeem request instruction: %+v", err)
+		return nil
+	}
+	var status byte
+	switch instruction[2] {
+	case common.PortalRedeemRequestAcceptedChainStatus:
+		updateStateAfterRedeemRequestAccepted(currentPortalState, beaconHeight, redeemReqContent)
+		status = common.PortalRedeemReqWaitingStatus
+	case common.PortalRedeemRequestRejectedChainStatus:
+		if isPortalStatusTracked(blockchain.GetDatabase(), lvdb.PortalRedeemRequestStatusPrefix, redeemReqContent.UniqueRedeemID) {
+			return nil
+		}
+		status = common.PortalRedeemReqRejectedStatus
+	default:
+		return nil
+	}
+	trackPortalStatus(
+		blockchain.GetDatabase(),
+		lvdb.PortalRedeemRequestStatusPrefix,
+		redeemReqContent.UniqueRedeemID,
+		metadata.PortalRedeemRequestStatus{
+			Status:                  status,
+			UniqueRedeemID:          redeemReqContent.UniqueRedeemID,
+			TokenID:                 redeemReqContent.TokenID,
+			RedeemAmount:            redeemReqContent.RedeemAmount,
+			RedeemerIncAddressStr:   redeemReqContent.RedeemerIncAddressStr,
+			RemoteAddress:           redeemReqContent.RemoteAddress,
+			MatchingCustodianDetail: redeemReqContent.MatchingCustodianDetail,
+			TxReqID:                 redeemReqContent.TxReqID,
+		},
+	)
+	return nil
+}
+
+func (blockchain *BlockChain) processPortalReqUnlockCollateral(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var unlockContent metadata.PortalRequestUnlockCollateralContent
+	err := json.Unmarshal([]byte(instruction[3]), &unlockContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal request unlock collateral instruction: %+v", err)
+		return nil
+	}
+	db := blockchain.GetDatabase()
+	var status byte
+	switch instruction[2] {
+	case common.PortalReqUnlockCollateralAcceptedChainStatus:
+		isRedeemDone := updateStateAfterUnlockCollateral(currentPortalState, beaconHeight, unlockContent)
+		err = db.Put(lvdb.BuildPortalExternalTxKey(unlockContent.TokenID, unlockContent.ExternalTxID), []byte{1})
+		if err != nil {
+			return database.NewDatabaseError(database.StorePortalStateError, err)
+		}
+		if isRedeemDone {
+			updatePortalRedeemRequestStatus(db, unlockContent.UniqueRedeemID, common.PortalRedeemReqSuccessStatus)
+		}
+		status = common.PortalReqUnlockCollateralAcceptedStatus
+	case common.PortalReqUnlockCollateralRejectedChainStatus:
+		status = common.PortalReqUnlockCollateralRejectedStatus
+	default:
+		return nil
+	}
+	trackPortalStatus(
+		db,
+		lvdb.PortalReqUnlockCollateralStatusPrefix,
+		unlockContent.TxReqID.String(),
+		metadata.PortalRequestUnlockCollateralStatus{
+			Status:              status,
+			UniqueRedeemID:      unlockContent.UniqueRedeemID,
+			TokenID:             unlockContent.TokenID,
+			CustodianAddressStr: unlockContent.CustodianAddressStr,
+			RedeemAmount:        unlockContent.RedeemAmount,
+			UnlockAmount:        unlockContent.UnlockAmount,
+			RedeemProof:         unlockContent.RedeemProof,
+		},
+	)
+	return nil
+}
+
+func (blockchain *BlockChain) processPortalLiquidateCustodian(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	if instruction[2] != common.PortalLiquidateCustodianSuccessChainStatus {
+		return nil
+	}
+	var liquidationContent metadata.PortalLiquidateCustodianContent
+	err := json.Unmarshal([]byte(instruction[3]), &liquidationContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal liquidate custodian instruction: %+v", err)
+		return nil
+	}
+	isRedeemDone := updateStateAfterLiquidateCustodian(currentPortalState, beaconHeight, liquidationContent)
+	if isRedeemDone {
+		updatePortalRedeemRequestStatus(blockchain.GetDatabase(), liquidationContent.UniqueRedeemID, common.PortalRedeemReqLiquidatedStatus)
+	}
+	return nil
+}
+
+// processPortalRelayingBTCHeader connects accepted relayed headers to the btc header chain and tracks the request status
+func (blockchain *BlockChain) processPortalRelayingBTCHeader(
+	beaconHeight uint64,
+	instruction []string,
+	currentPortalState *CurrentPortalState,
+) error {
+	var relayingHeaderContent metadata.PortalRelayingBTCHeaderContent
+	err := json.Unmarshal([]byte(instruction[3]), &relayingHeaderContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling content string of portal relaying btc header instruction: %+v", err)
+		return nil
+	}
+	db := blockchain.GetDatabase()
+	var status byte
+	switch instruction[2] {
+	case common.PortalRelayingBTCHeaderAcceptedChainStatus:
+		err = blockchain.connectBTCHeaders(currentPortalState, db, beaconHeight, relayingHeaderContent.Headers)
+		if err != nil {
+			return err
+		}
+		status = common.PortalRelayingBTCHeaderAcceptedStatus
+	case common.PortalRelayingBTCHeaderRejectedChainStatus:
+		status = common.PortalRelayingBTCHeaderRejectedStatus
+	default:
+		return nil
+	}
+	trackPortalStatus(
+		db,
+		lvdb.PortalRelayingBTCHeaderStatusPrefix,
+		relayingHeaderContent.TxReqID.String(),
+		metadata.PortalRelayingBTCHeaderStatus{
+			Status:  status,
+			Headers: relayingHeaderContent.Headers,
+		},
+	)
+	return nil
+}
+
+// updatePortalRedeemRequestStatus updates the status of a tracked redeem request, keeping its details
+func updatePortalRedeemRequestStatus(db database.DatabaseInterface, uniqueRedeemID string, status byte) {
+	redeemStatusBytes, err := db.GetPortalStatus(lvdb.PortalRedeemRequestStatusPrefix, []byte(uniqueRedeemID))
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while getting portal redeem request status: %+v", err)
+		return
+	}
+	var redeemStatus metadata.PortalRedeemRequestStatus
+	if len(redeemStatusBytes) > 0 {
+		err = json.Unmarshal(redeemStatusBytes, &redeemStatus)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while unmarshaling portal redeem request status: %+v", err)
+		}
+	}
+	redeemStatus.Status = status
+	redeemStatus.UniqueRedeemID = uniqueRedeemID
+	trackPortalStatus(db, lvdb.PortalRedeemRequestStatusPrefix, uniqueRedeemID, redeemStatus)
+}
+
+func getOrNewCustodianState(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	custodianAddrStr string,
+) *lvdb.CustodianState {
+	custodianKey := string(lvdb.BuildCustodianStateKey(beaconHeight, custodianAddrStr))
+	custodianState, found := currentPortalState.CustodianPoolState[custodianKey]
+	if !found || custodianState == nil {
+		custodianState = &lvdb.CustodianState{
+			IncognitoAddress:       custodianAddrStr,
+			HoldingPubTokens:       map[string]uint64{},
+			LockedAmountCollateral: map[string]uint64{},
+			RemoteAddresses:        map[string]string{},
+		}
+		currentPortalState.CustodianPoolState[custodianKey] = custodianState
+	}
+	if custodianState.HoldingPubTokens == nil {
+		custodianState.HoldingPubTokens = map[string]uint64{}
+	}
+	if custodianState.LockedAmountCollateral == nil {
+		custodianState.LockedAmountCollateral = map[string]uint64{}
+	}
+	if custodianState.RemoteAddresses == nil {
+		custodianState.RemoteAddresses = map[string]string{}
+	}
+	return custodianState
+}
+
+func updateCustodianStateAfterDeposit(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	depositContent metadata.PortalCustodianDepositContent,
+) {
+	custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, depositContent.IncogAddressStr)
+	custodianState.TotalCollateral += depositContent.DepositedAmount
+	custodianState.FreeCollateral += depositContent.DepositedAmount
+	for tokenID, remoteAddr := range depositContent.RemoteAddresses {
+		custodianState.RemoteAddresses[tokenID] = remoteAddr
+	}
+}
+
+func updateStateAfterPortingRequestAccepted(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	portingReqContent metadata.PortalPortingRequestContent,
+) {
+	for _, custodian := range portingReqContent.Custodian {
+		custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, custodian.IncAddress)
+		custodianState.FreeCollateral -= custodian.LockedAmountCollateral
+		custodianState.LockedAmountCollateral[portingReqContent.PTokenId] += custodian.LockedAmountCollateral
+	}
+	waitingPortingReqKey := string(lvdb.BuildWaitingPortingRequestKey(beaconHeight, portingReqContent.UniqueRegisterId))
+	currentPortalState.WaitingPortingRequests[waitingPortingReqKey] = &lvdb.PortingRequest{
+		UniquePortingID: portingReqContent.UniqueRegisterId,
+		TxReqID:         portingReqContent.TxReqID,
+		TokenID:         portingReqContent.PTokenId,
+		PorterAddress:   portingReqContent.IncogAddressStr,
+		Amount:          portingReqContent.RegisterAmount,
+		Custodians:      portingReqContent.Custodian,
+		PortingFee:      portingReqContent.PortingFee,
+		ShardID:         portingReqContent.ShardID,
+		BeaconHeight:    beaconHeight + 1,
+	}
+}
+
+func updateStateAfterPortingRequestExpired(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	portingReqContent metadata.PortalPortingRequestContent,
+) {
+	waitingPortingReqKey := string(lvdb.BuildWaitingPortingRequestKey(beaconHeight, portingReqContent.UniqueRegisterId))
+	if _, found := currentPortalState.WaitingPortingRequests[waitingPortingReqKey]; !found {
+		return
+	}
+	for _, custodian := range portingReqContent.Custodian {
+		custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, custodian.IncAddress)
+		custodianState.FreeCollateral += custodian.LockedAmountCollateral
+		custodianState.LockedAmountCollateral[portingReqContent.PTokenId] -= custodian.LockedAmountCollateral
+	}
+	delete(currentPortalState.WaitingPortingRequests, waitingPortingReqKey)
+}
+
+func updateStateAfterReqPTokensAccepted(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	reqPTokensContent metadata.PortalRequestPTokensContent,
+) {
+	waitingPortingReqKey := string(lvdb.BuildWaitingPortingRequestKey(beaconHeight, reqPTokensContent.UniquePortingID))
+	portingReq, found := currentPortalState.WaitingPortingRequests[waitingPortingReqKey]
+	if !found || portingReq == nil {
+		return
+	}
+	for _, custodian := range portingReq.Custodians {
+		custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, custodian.IncAddress)
+		custodianState.HoldingPubTokens[portingReq.TokenID] += custodian.Amount
+	}
+	delete(currentPortalState.WaitingPortingRequests, waitingPortingReqKey)
+}
+
+func updateStateAfterRedeemRequestAccepted(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	redeemReqContent metadata.PortalRedeemRequestContent,
+) {
+	// collateral backing the redeemed tokens is reserved in the redeem request until custodians return public tokens
+	for _, custodian := range redeemReqContent.MatchingCustodianDetail {
+		custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, custodian.IncAddress)
+		custodianState.HoldingPubTokens[redeemReqContent.TokenID] -= custodian.Amount
+		custodianState.LockedAmountCollateral[redeemReqContent.TokenID] -= custodian.UnlockAmount
+	}
+	waitingRedeemReqKey := string(lvdb.BuildWaitingRedeemRequestKey(beaconHeight, redeemReqContent.UniqueRedeemID))
+	currentPortalState.WaitingRedeemRequests[waitingRedeemReqKey] = &lvdb.RedeemRequest{
+		UniqueRedeemID:        redeemReqContent.UniqueRedeemID,
+		TxReqID:               redeemReqContent.TxReqID,
+		TokenID:               redeemReqContent.TokenID,
+		RedeemerAddress:       redeemReqContent.RedeemerIncAddressStr,
+		RedeemerRemoteAddress: redeemReqContent.RemoteAddress,
+		RedeemAmount:          redeemReqContent.RedeemAmount,
+		Custodians:            redeemReqContent.MatchingCustodianDetail,
+		ShardID:               redeemReqContent.ShardID,
+		BeaconHeight:          beaconHeight + 1,
+	}
+}
+
+// removeCustodianFromRedeemRequest returns the removed custodian detail and whether the redeem request is done
+func removeCustodianFromRedeemRequest(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	uniqueRedeemID string,
+	custodianAddrStr string,
+) (*lvdb.MatchingRedeemCustodianDetail, bool) {
+	waitingRedeemReqKey := string(lvdb.BuildWaitingRedeemRequestKey(beaconHeight, uniqueRedeemID))
+	redeemReq, found := currentPortalState.WaitingRedeemRequests[waitingRedeemReqKey]
+	if !found || redeemReq == nil {
+		return nil, false
+	}
+	var removedCustodian *lvdb.MatchingRedeemCustodianDetail
+	remainingCustodians := []*lvdb.MatchingRedeemCustodianDetail{}
+	for _, custodian := range redeemReq.Custodians {
+		if removedCustodian == nil && custodian.IncAddress == custodianAddrStr {
+			removedCustodian = custodian
+			continue
+		}
+		remainingCustodians = append(remainingCustodians, custodian)
+	}
+	redeemReq.Custodians = remainingCustodians
+	if len(remainingCustodians) == 0 {
+		delete(currentPortalState.WaitingRedeemRequests, waitingRedeemReqKey)
+		return removedCustodian, removedCustodian != nil
+	}
+	return removedCustodian, false
+}
+
+func updateStateAfterUnlockCollateral(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	unlockContent metadata.PortalRequestUnlockCollateralContent,
+) bool {
+	removedCustodian, isRedeemDone := removeCustodianFromRedeemRequest(currentPortalState, beaconHeight, unlockContent.UniqueRedeemID, unlockContent.CustodianAddressStr)
+	if removedCustodian == nil {
+		return false
+	}
+	custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, unlockContent.CustodianAddressStr)
+	custodianState.FreeCollateral += removedCustodian.UnlockAmount
+	return isRedeemDone
+}
+
+func updateStateAfterLiquidateCustodian(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	liquidationContent metadata.PortalLiquidateCustodianContent,
+) bool {
+	removedCustodian, isRedeemDone := removeCustodianFromRedeemRequest(currentPortalState, beaconHeight, liquidationContent.UniqueRedeemID, liquidationContent.CustodianIncAddressStr)
+	if removedCustodian == nil {
+		return false
+	}
+	// the collateral is paid to the redeemer
+	custodianState := getOrNewCustodianState(currentPortalState, beaconHeight, liquidationContent.CustodianIncAddressStr)
+	custodianState.TotalCollateral -= removedCustodian.UnlockAmount
+	return isRedeemDone
+}
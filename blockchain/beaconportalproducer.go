@@ -0,0 +1,522 @@
+package blockchain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+)
+
+func buildPortalInst(
+	metaType int,
+	shardID byte,
+	status string,
+	content interface{},
+) []string {
+	contentBytes, _ := json.Marshal(content)
+	return []string{
+		strconv.Itoa(metaType),
+		strconv.Itoa(int(shardID)),
+		status,
+		string(contentBytes),
+	}
+}
+
+func (blockchain *BlockChain) handlePortalInsts(
+	beaconHeight uint64,
+	currentPortalState *CurrentPortalState,
+	currentPDEState *CurrentPDEState,
+	portalActionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	instructions := [][]string{}
+	usedExternalTxKeys := map[string]bool{}
+
+	var keys []int
+	for k := range portalActionsByShardID {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+	// btc headers are relayed first so proofs in the same beacon block can be verified against them
+	for _, value := range keys {
+		shardID := byte(value)
+		for _, action := range portalActionsByShardID[shardID] {
+			if action[0] != strconv.Itoa(metadata.PortalRelayingBTCHeaderMeta) {
+				continue
+			}
+			newInst, err := blockchain.buildInstructionsForRelayingBTCHeader(action[1], shardID, metadata.PortalRelayingBTCHeaderMeta, currentPortalState, beaconHeight, db)
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			instructions = append(instructions, newInst...)
+		}
+	}
+	for _, value := range keys {
+		shardID := byte(value)
+		actions := portalActionsByShardID[shardID]
+		for _, action := range actions {
+			metaType, err := strconv.Atoi(action[0])
+			if err != nil {
+				continue
+			}
+			contentStr := action[1]
+			newInst := [][]string{}
+			switch metaType {
+			case metadata.PortalCustodianDepositMeta:
+				newInst, err = blockchain.buildInstructionsForCustodianDeposit(contentStr, shardID, metaType, currentPortalState, beaconHeight)
+			case metadata.PortalUserRegisterMeta:
+				newInst, err = blockchain.buildInstructionsForPortingRequest(contentStr, shardID, metaType, currentPortalState, currentPDEState, beaconHeight, db)
+			case metadata.PortalUserRequestPTokenMeta:
+				newInst, err = blockchain.buildInstructionsForReqPTokens(contentStr, shardID, metaType, currentPortalState, beaconHeight, db, usedExternalTxKeys)
+			case metadata.PortalRedeemRequestMeta:
+				newInst, err = blockchain.buildInstructionsForRedeemRequest(contentStr, shardID, metaType, currentPortalState, beaconHeight, db)
+			case metadata.PortalRequestUnlockCollateralMeta:
+				newInst, err = blockchain.buildInstructionsForReqUnlockCollateral(contentStr, shardID, metaType, currentPortalState, beaconHeight, db, usedExternalTxKeys)
+			default:
+				continue
+			}
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			if len(newInst) > 0 {
+				instructions = append(instructions, newInst...)
+			}
+		}
+	}
+
+	// requests that timed out are handled at the end of every beacon block
+	instructions = append(instructions, blockchain.buildInstsForExpiredPortingRequests(currentPortalState, beaconHeight)...)
+	instructions = append(instructions, blockchain.buildInstsForLiquidatedCustodians(currentPortalState, beaconHeight)...)
+	return instructions, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForCustodianDeposit(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForCustodianDeposit]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal custodian deposit action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalCustodianDepositAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal custodian deposit action: %+v", err)
+		return [][]string{}, nil
+	}
+	meta := actionData.Meta
+	// deposits are always accepted, collateral is topped up for existing custodians
+	depositContent := metadata.PortalCustodianDepositContent{
+		IncogAddressStr: meta.IncogAddressStr,
+		RemoteAddresses: meta.RemoteAddresses,
+		DepositedAmount: meta.DepositedAmount,
+		TxReqID:         actionData.TxReqID,
+		ShardID:         shardID,
+	}
+	updateCustodianStateAfterDeposit(currentPortalState, beaconHeight, depositContent)
+	inst := buildPortalInst(metaType, shardID, common.PortalCustodianDepositAcceptedChainStatus, depositContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForPortingRequest(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	currentPDEState *CurrentPDEState,
+	beaconHeight uint64,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForPortingRequest]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal porting request action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalUserRegisterAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal porting request action: %+v", err)
+		return [][]string{}, nil
+	}
+	meta := actionData.Meta
+	portingReqContent := metadata.PortalPortingRequestContent{
+		UniqueRegisterId: meta.UniqueRegisterId,
+		IncogAddressStr:  meta.IncogAddressStr,
+		PTokenId:         meta.PTokenId,
+		RegisterAmount:   meta.RegisterAmount,
+		PortingFee:       meta.PortingFee,
+		TxReqID:          actionData.TxReqID,
+		ShardID:          shardID,
+	}
+	rejectedInst := buildPortalInst(metaType, shardID, common.PortalPortingRequestRejectedChainStatus, portingReqContent)
+
+	waitingPortingReqKey := string(lvdb.BuildWaitingPortingRequestKey(beaconHeight, meta.UniqueRegisterId))
+	if _, found := currentPortalState.WaitingPortingRequests[waitingPortingReqKey]; found {
+		Logger.log.Warnf("WARN - [buildInstructionsForPortingRequest]: porting request id %s is duplicated", meta.UniqueRegisterId)
+		return [][]string{rejectedInst}, nil
+	}
+	portingStatusBytes, err := db.GetPortalStatus(lvdb.PortalPortingRequestStatusPrefix, []byte(meta.UniqueRegisterId))
+	if err != nil || len(portingStatusBytes) > 0 {
+		Logger.log.Warnf("WARN - [buildInstructionsForPortingRequest]: porting request id %s was used before", meta.UniqueRegisterId)
+		return [][]string{rejectedInst}, nil
+	}
+
+	prvAmount, err := convertPTokenToPRV(beaconHeight, currentPDEState, meta.PTokenId, meta.RegisterAmount)
+	if err != nil {
+		Logger.log.Warnf("WARN - [buildInstructionsForPortingRequest]: could not convert porting amount to PRV: %+v", err)
+		return [][]string{rejectedInst}, nil
+	}
+	lockedCollateral := calculateLockedCollateral(prvAmount, blockchain.config.ChainParams.PortalParams.MinPercentLockedCollateral)
+	if lockedCollateral == 0 {
+		return [][]string{rejectedInst}, nil
+	}
+	matchedCustodians := pickUpCustodiansForPorting(currentPortalState.CustodianPoolState, meta.PTokenId, meta.RegisterAmount, lockedCollateral)
+	if len(matchedCustodians) == 0 {
+		Logger.log.Warnf("WARN - [buildInstructionsForPortingRequest]: there are not enough custodians for porting request %s", meta.UniqueRegisterId)
+		return [][]string{rejectedInst}, nil
+	}
+
+	portingReqContent.Custodian = matchedCustodians
+	updateStateAfterPortingRequestAccepted(currentPortalState, beaconHeight, portingReqContent)
+	inst := buildPortalInst(metaType, shardID, common.PortalPortingRequestAcceptedChainStatus, portingReqContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForReqPTokens(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	db database.DatabaseInterface,
+	usedExternalTxKeys map[string]bool,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForReqPTokens]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal request ptokens action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalRequestPTokensAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal request ptokens action: %+v", err)
+		return [][]string{}, nil
+	}
+	meta := actionData.Meta
+	reqPTokensContent := metadata.PortalRequestPTokensContent{
+		UniquePortingID: meta.UniquePortingID,
+		TokenID:         meta.TokenID,
+		IncogAddressStr: meta.IncogAddressStr,
+		PortingAmount:   meta.PortingAmount,
+		PortingProof:    meta.PortingProof,
+		TxReqID:         actionData.TxReqID,
+		ShardID:         shardID,
+	}
+	rejectedInst := buildPortalInst(metaType, shardID, common.PortalReqPTokensRejectedChainStatus, reqPTokensContent)
+
+	waitingPortingReqKey := string(lvdb.BuildWaitingPortingRequestKey(beaconHeight, meta.UniquePortingID))
+	portingReq, found := currentPortalState.WaitingPortingRequests[waitingPortingReqKey]
+	if !found || portingReq == nil {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqPTokens]: there is no waiting porting request with id %s", meta.UniquePortingID)
+		return [][]string{rejectedInst}, nil
+	}
+	if portingReq.TokenID != meta.TokenID ||
+		portingReq.PorterAddress != meta.IncogAddressStr ||
+		portingReq.Amount != meta.PortingAmount {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqPTokens]: request ptokens does not match porting request %s", meta.UniquePortingID)
+		return [][]string{rejectedInst}, nil
+	}
+	externalTxID, externalTxOutputs, err := blockchain.verifyPortalExternalTx(currentPortalState, db, meta.TokenID, meta.PortingProof)
+	if err != nil {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqPTokens]: porting proof of request %s is invalid: %+v", meta.UniquePortingID, err)
+		return [][]string{rejectedInst}, nil
+	}
+	externalTxKey := string(lvdb.BuildPortalExternalTxKey(meta.TokenID, externalTxID))
+	isUsed, err := db.HasValue([]byte(externalTxKey))
+	if err != nil || isUsed || usedExternalTxKeys[externalTxKey] {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqPTokens]: external tx %s was used before", externalTxID)
+		return [][]string{rejectedInst}, nil
+	}
+	for _, custodian := range portingReq.Custodians {
+		if externalTxOutputs[custodian.RemoteAddress] < custodian.Amount {
+			Logger.log.Warnf("WARN - [buildInstructionsForReqPTokens]: custodian %s did not receive enough public tokens", custodian.IncAddress)
+			return [][]string{rejectedInst}, nil
+		}
+	}
+
+	usedExternalTxKeys[externalTxKey] = true
+	reqPTokensContent.ExternalTxID = externalTxID
+	updateStateAfterReqPTokensAccepted(currentPortalState, beaconHeight, reqPTokensContent)
+	inst := buildPortalInst(metaType, shardID, common.PortalReqPTokensAcceptedChainStatus, reqPTokensContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForRedeemRequest(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForRedeemRequest]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal redeem request action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalRedeemRequestAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal redeem request action: %+v", err)
+		return [][]string{}, nil
+	}
+	meta := actionData.Meta
+	redeemReqContent := metadata.PortalRedeemRequestContent{
+		UniqueRedeemID:        meta.UniqueRedeemID,
+		TokenID:               meta.TokenID,
+		RedeemAmount:          meta.RedeemAmount,
+		RedeemerIncAddressStr: meta.RedeemerIncAddressStr,
+		RemoteAddress:         meta.RemoteAddress,
+		TxReqID:               actionData.TxReqID,
+		ShardID:               shardID,
+	}
+	rejectedInst := buildPortalInst(metaType, shardID, common.PortalRedeemRequestRejectedChainStatus, redeemReqContent)
+
+	waitingRedeemReqKey := string(lvdb.BuildWaitingRedeemRequestKey(beaconHeight, meta.UniqueRedeemID))
+	if _, found := currentPortalState.WaitingRedeemRequests[waitingRedeemReqKey]; found {
+		Logger.log.Warnf("WARN - [buildInstructionsForRedeemRequest]: redeem request id %s is duplicated", meta.UniqueRedeemID)
+		return [][]string{rejectedInst}, nil
+	}
+	redeemStatusBytes, err := db.GetPortalStatus(lvdb.PortalRedeemRequestStatusPrefix, []byte(meta.UniqueRedeemID))
+	if err != nil || len(redeemStatusBytes) > 0 {
+		Logger.log.Warnf("WARN - [buildInstructionsForRedeemRequest]: redeem request id %s was used before", meta.UniqueRedeemID)
+		return [][]string{rejectedInst}, nil
+	}
+	matchedCustodians := pickUpCustodiansForRedeem(currentPortalState.CustodianPoolState, meta.TokenID, meta.RedeemAmount)
+	if len(matchedCustodians) == 0 {
+		Logger.log.Warnf("WARN - [buildInstructionsForRedeemRequest]: custodians do not hold enough public tokens for redeem request %s", meta.UniqueRedeemID)
+		return [][]string{rejectedInst}, nil
+	}
+
+	redeemReqContent.MatchingCustodianDetail = matchedCustodians
+	updateStateAfterRedeemRequestAccepted(currentPortalState, beaconHeight, redeemReqContent)
+	inst := buildPortalInst(metaType, shardID, common.PortalRedeemRequestAcceptedChainStatus, redeemReqContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForReqUnlockCollateral(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	db database.DatabaseInterface,
+	usedExternalTxKeys map[string]bool,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForReqUnlockCollateral]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal request unlock collateral action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalRequestUnlockCollateralAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal request unlock collateral action: %+v", err)
+		return [][]string{}, nil
+	}
+	meta := actionData.Meta
+	unlockContent := metadata.PortalRequestUnlockCollateralContent{
+		UniqueRedeemID:      meta.UniqueRedeemID,
+		TokenID:             meta.TokenID,
+		CustodianAddressStr: meta.CustodianAddressStr,
+		RedeemAmount:        meta.RedeemAmount,
+		RedeemProof:         meta.RedeemProof,
+		TxReqID:             actionData.TxReqID,
+		ShardID:             shardID,
+	}
+	rejectedInst := buildPortalInst(metaType, shardID, common.PortalReqUnlockCollateralRejectedChainStatus, unlockContent)
+
+	waitingRedeemReqKey := string(lvdb.BuildWaitingRedeemRequestKey(beaconHeight, meta.UniqueRedeemID))
+	redeemReq, found := currentPortalState.WaitingRedeemRequests[waitingRedeemReqKey]
+	if !found || redeemReq == nil || redeemReq.TokenID != meta.TokenID {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqUnlockCollateral]: there is no waiting redeem request with id %s", meta.UniqueRedeemID)
+		return [][]string{rejectedInst}, nil
+	}
+	var matchedCustodian *lvdb.MatchingRedeemCustodianDetail
+	for _, custodian := range redeemReq.Custodians {
+		if custodian.IncAddress == meta.CustodianAddressStr {
+			matchedCustodian = custodian
+			break
+		}
+	}
+	if matchedCustodian == nil || matchedCustodian.Amount != meta.RedeemAmount {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqUnlockCollateral]: custodian %s is not matched to redeem request %s", meta.CustodianAddressStr, meta.UniqueRedeemID)
+		return [][]string{rejectedInst}, nil
+	}
+	externalTxID, externalTxOutputs, err := blockchain.verifyPortalExternalTx(currentPortalState, db, meta.TokenID, meta.RedeemProof)
+	if err != nil {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqUnlockCollateral]: redeem proof of custodian %s is invalid: %+v", meta.CustodianAddressStr, err)
+		return [][]string{rejectedInst}, nil
+	}
+	externalTxKey := string(lvdb.BuildPortalExternalTxKey(meta.TokenID, externalTxID))
+	isUsed, err := db.HasValue([]byte(externalTxKey))
+	if err != nil || isUsed || usedExternalTxKeys[externalTxKey] {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqUnlockCollateral]: external tx %s was used before", externalTxID)
+		return [][]string{rejectedInst}, nil
+	}
+	if externalTxOutputs[redeemReq.RedeemerRemoteAddress] < matchedCustodian.Amount {
+		Logger.log.Warnf("WARN - [buildInstructionsForReqUnlockCollateral]: redeemer did not receive enough public tokens from custodian %s", meta.CustodianAddressStr)
+		return [][]string{rejectedInst}, nil
+	}
+
+	usedExternalTxKeys[externalTxKey] = true
+	unlockContent.ExternalTxID = externalTxID
+	unlockContent.UnlockAmount = matchedCustodian.UnlockAmount
+	updateStateAfterUnlockCollateral(currentPortalState, beaconHeight, unlockContent)
+	inst := buildPortalInst(metaType, shardID, common.PortalReqUnlockCollateralAcceptedChainStatus, unlockContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstructionsForRelayingBTCHeader(
+	contentStr string,
+	shardID byte,
+	metaType int,
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	if currentPortalState == nil {
+		Logger.log.Warn("WARN - [buildInstructionsForRelayingBTCHeader]: Current Portal state is null.")
+		return [][]string{}, nil
+	}
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while decoding content string of portal relaying btc header action: %+v", err)
+		return [][]string{}, nil
+	}
+	var actionData metadata.PortalRelayingBTCHeaderAction
+	err = json.Unmarshal(contentBytes, &actionData)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal relaying btc header action: %+v", err)
+		return [][]string{}, nil
+	}
+	relayingHeaderContent := metadata.PortalRelayingBTCHeaderContent{
+		Headers: actionData.Meta.Headers,
+		TxReqID: actionData.TxReqID,
+		ShardID: shardID,
+	}
+	err = blockchain.connectBTCHeaders(currentPortalState, db, beaconHeight, relayingHeaderContent.Headers)
+	if err != nil {
+		Logger.log.Warnf("WARN - [buildInstructionsForRelayingBTCHeader]: btc headers of tx %s are invalid: %+v", actionData.TxReqID.String(), err)
+		inst := buildPortalInst(metaType, shardID, common.PortalRelayingBTCHeaderRejectedChainStatus, relayingHeaderContent)
+		return [][]string{inst}, nil
+	}
+	inst := buildPortalInst(metaType, shardID, common.PortalRelayingBTCHeaderAcceptedChainStatus, relayingHeaderContent)
+	return [][]string{inst}, nil
+}
+
+func (blockchain *BlockChain) buildInstsForExpiredPortingRequests(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+) [][]string {
+	if currentPortalState == nil {
+		return [][]string{}
+	}
+	timeOut := blockchain.config.ChainParams.PortalParams.TimeOutWaitingPortingRequest
+	insts := [][]string{}
+	sortedKeys := make([]string, 0, len(currentPortalState.WaitingPortingRequests))
+	for key := range currentPortalState.WaitingPortingRequests {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		portingReq := currentPortalState.WaitingPortingRequests[key]
+		// beaconHeight is the height of the previous beacon block
+		if beaconHeight+1 < portingReq.BeaconHeight+timeOut {
+			continue
+		}
+		expiredContent := metadata.PortalPortingRequestContent{
+			UniqueRegisterId: portingReq.UniquePortingID,
+			IncogAddressStr:  portingReq.PorterAddress,
+			PTokenId:         portingReq.TokenID,
+			RegisterAmount:   portingReq.Amount,
+			PortingFee:       portingReq.PortingFee,
+			Custodian:        portingReq.Custodians,
+			TxReqID:          portingReq.TxReqID,
+			ShardID:          portingReq.ShardID,
+		}
+		updateStateAfterPortingRequestExpired(currentPortalState, beaconHeight, expiredContent)
+		insts = append(insts, buildPortalInst(metadata.PortalUserRegisterMeta, portingReq.ShardID, common.PortalPortingRequestExpiredChainStatus, expiredContent))
+	}
+	return insts
+}
+
+func (blockchain *BlockChain) buildInstsForLiquidatedCustodians(
+	currentPortalState *CurrentPortalState,
+	beaconHeight uint64,
+) [][]string {
+	if currentPortalState == nil {
+		return [][]string{}
+	}
+	timeOut := blockchain.config.ChainParams.PortalParams.TimeOutCustodianReturnPubToken
+	insts := [][]string{}
+	sortedKeys := make([]string, 0, len(currentPortalState.WaitingRedeemRequests))
+	for key := range currentPortalState.WaitingRedeemRequests {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		redeemReq := currentPortalState.WaitingRedeemRequests[key]
+		if beaconHeight+1 < redeemReq.BeaconHeight+timeOut {
+			continue
+		}
+		// custodians that have not returned public tokens yet are still in the request
+		custodians := make([]*lvdb.MatchingRedeemCustodianDetail, len(redeemReq.Custodians))
+		copy(custodians, redeemReq.Custodians)
+		for _, custodian := range custodians {
+			liquidationContent := metadata.PortalLiquidateCustodianContent{
+				UniqueRedeemID:         redeemReq.UniqueRedeemID,
+				TokenID:                redeemReq.TokenID,
+				RedeemAmount:           custodian.Amount,
+				MintedCollateralAmount: custodian.UnlockAmount,
+				RedeemerIncAddressStr:  redeemReq.RedeemerAddress,
+				CustodianIncAddressStr: custodian.IncAddress,
+				ShardID:                redeemReq.ShardID,
+			}
+			updateStateAfterLiquidateCustodian(currentPortalState, beaconHeight, liquidationContent)
+			insts = append(insts, buildPortalInst(metadata.PortalLiquidateCustodianMeta, redeemReq.ShardID, common.PortalLiquidateCustodianSuccessChainStatus, liquidationContent))
+		}
+	}
+	return insts
+}
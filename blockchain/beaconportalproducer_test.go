@@ -0,0 +1,532 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	portalTestCustodian1Addr = "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	portalTestCustodian2Addr = "12RuEdPjq4yxivzm8xPxRVHmkL74t4eAdUKPdKKhMEnpxPH3k8GEyULbwq4hjwHWmHQr7MmGBJsMpdCHsYAqNE18jipWQwciBf9yqvQ"
+	portalTestUserAddr       = "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci"
+)
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type PortalProducerSuite struct {
+	suite.Suite
+	currentPortalState *CurrentPortalState
+	currentPDEState    *CurrentPDEState
+	db                 *mocks.DatabaseInterface
+	bc                 *BlockChain
+	beaconHeight       uint64
+	btcTip             *btc.BlockHeader // last mined btc header
+}
+
+func (suite *PortalProducerSuite) SetupTest() {
+	suite.beaconHeight = uint64(1000)
+	suite.currentPortalState = &CurrentPortalState{
+		CustodianPoolState:     make(map[string]*lvdb.CustodianState),
+		WaitingPortingRequests: make(map[string]*lvdb.PortingRequest),
+		WaitingRedeemRequests:  make(map[string]*lvdb.RedeemRequest),
+		RelayedBTCHeaders:      make(map[string]*lvdb.BTCHeaderState),
+	}
+	// 1 BTC (10^8 satoshi) = 10000 PRV (10^13 nano PRV)
+	pair := &lvdb.PDEPoolForPair{
+		Token1IDStr:     common.PRVCoinID.String(),
+		Token1PoolValue: 1000000000000000,
+		Token2IDStr:     common.PortalBTCIDStr,
+		Token2PoolValue: 10000000000,
+	}
+	suite.currentPDEState = &CurrentPDEState{
+		WaitingPDEContributions: make(map[string]*lvdb.PDEContribution),
+		PDEPoolPairs: map[string]*lvdb.PDEPoolForPair{
+			string(lvdb.BuildPDEPoolForPairKey(suite.beaconHeight, pair.Token1IDStr, pair.Token2IDStr)): pair,
+		},
+		PDEShares: make(map[string]uint64),
+	}
+	suite.db = &mocks.DatabaseInterface{}
+	suite.db.On("GetPortalStatus", mock.Anything, mock.Anything).Return([]byte{}, nil)
+	suite.db.On("HasValue", mock.Anything).Return(false, nil)
+	suite.btcTip = minePortalTestBTCHeader(common.Hash{}, common.Hash{}, 1500000000)
+	suite.bc = &BlockChain{
+		config: Config{
+			ChainParams: &Params{
+				PortalParams: PortalParams{
+					TimeOutWaitingPortingRequest:   10,
+					TimeOutCustodianReturnPubToken: 10,
+					MinPercentLockedCollateral:     150,
+					BTCParams:                      &btc.RegressionNetParams,
+					BTCCheckpoint:                  btc.Checkpoint{Header: suite.btcTip.Hex(), Height: 0},
+				},
+			},
+		},
+	}
+}
+
+func portalTestDoubleHash(b []byte) common.Hash {
+	first := sha256.Sum256(b)
+	return common.Hash(sha256.Sum256(first[:]))
+}
+
+// portalTestBTCAddress returns a regtest segwit address and the output script paying to it
+func portalTestBTCAddress(seed byte) (string, []byte) {
+	program := bytes.Repeat([]byte{seed}, 20)
+	address, _ := btc.EncodeSegwitAddress(btc.RegressionNetParams.Bech32HRPSegwit, 0, program)
+	return address, append([]byte{0x00, 0x14}, program...)
+}
+
+// buildPortalTestBTCTx serializes a btc tx with one input
+func buildPortalTestBTCTx(pkScripts [][]byte, values []uint64) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, int32(2))
+	buf.WriteByte(1)
+	buf.Write(bytes.Repeat([]byte{0x22}, common.HashSize+4))
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.WriteByte(byte(len(pkScripts)))
+	for i, pkScript := range pkScripts {
+		_ = binary.Write(&buf, binary.LittleEndian, values[i])
+		buf.WriteByte(byte(len(pkScript)))
+		buf.Write(pkScript)
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+func minePortalTestBTCHeader(prevBlock common.Hash, merkleRoot common.Hash, timestamp uint32) *btc.BlockHeader {
+	header := &btc.BlockHeader{
+		Version:    4,
+		PrevBlock:  prevBlock,
+		MerkleRoot: merkleRoot,
+		Timestamp:  timestamp,
+		Bits:       btc.RegressionNetParams.PowLimitBits,
+	}
+	for header.CheckProofOfWork(&btc.RegressionNetParams) != nil {
+		header.Nonce++
+	}
+	return header
+}
+
+// mineBTCBlocks mines empty blocks on top of prev
+func (suite *PortalProducerSuite) mineBTCBlocks(prev *btc.BlockHeader, numBlocks int) []*btc.BlockHeader {
+	headers := []*btc.BlockHeader{}
+	for i := 0; i < numBlocks; i++ {
+		merkleRoot := portalTestDoubleHash([]byte{byte(i)})
+		prev = minePortalTestBTCHeader(prev.BlockHash(), merkleRoot, prev.Timestamp+600)
+		headers = append(headers, prev)
+	}
+	return headers
+}
+
+func (suite *PortalProducerSuite) relayBTCHeaders(headers []*btc.BlockHeader) []string {
+	headerStrs := []string{}
+	for _, header := range headers {
+		headerStrs = append(headerStrs, header.Hex())
+	}
+	action := buildPortalAction(metadata.PortalRelayingBTCHeaderMeta, metadata.PortalRelayingBTCHeaderAction{
+		Meta: metadata.PortalRelayingBTCHeader{
+			MetadataBase: metadata.MetadataBase{Type: metadata.PortalRelayingBTCHeaderMeta},
+			Headers:      headerStrs,
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForRelayingBTCHeader(action[1], 1, metadata.PortalRelayingBTCHeaderMeta, suite.currentPortalState, suite.beaconHeight, suite.db)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	return newInsts[0]
+}
+
+// sendBTC mines a btc block with a tx paying to the output scripts on top of the last mined block,
+// relays the block with the blocks confirming it, returns the proof of the tx
+func (suite *PortalProducerSuite) sendBTC(pkScripts [][]byte, values []uint64, confirmations int) string {
+	rawTx := buildPortalTestBTCTx(pkScripts, values)
+	txHash := portalTestDoubleHash(rawTx)
+	// the tx is the second one in the block
+	otherTxHash := portalTestDoubleHash([]byte("coinbase"))
+	merkleRoot := portalTestDoubleHash(append(otherTxHash[:], txHash[:]...))
+	block := minePortalTestBTCHeader(suite.btcTip.BlockHash(), merkleRoot, suite.btcTip.Timestamp+600)
+	headers := append([]*btc.BlockHeader{block}, suite.mineBTCBlocks(block, confirmations-1)...)
+	inst := suite.relayBTCHeaders(headers)
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.btcTip = headers[len(headers)-1]
+
+	proof := &btc.MerkleProof{
+		BlockHash:  block.BlockHash().String(),
+		TxIndex:    1,
+		MerklePath: []string{otherTxHash.String()},
+		RawTx:      hex.EncodeToString(rawTx),
+	}
+	proofStr, err := proof.Encode()
+	suite.Equal(nil, err)
+	return proofStr
+}
+
+func (suite *PortalProducerSuite) buildReqPTokensInst(uniqueID string, amount uint64, proof string, usedExternalTxKeys map[string]bool) []string {
+	reqPTokenAction := buildPortalAction(metadata.PortalUserRequestPTokenMeta, metadata.PortalRequestPTokensAction{
+		Meta: metadata.PortalUserRequestPToken{
+			MetadataBase:    metadata.MetadataBase{Type: metadata.PortalUserRequestPTokenMeta},
+			UniquePortingID: uniqueID,
+			TokenID:         common.PortalBTCIDStr,
+			IncogAddressStr: portalTestUserAddr,
+			PortingAmount:   amount,
+			PortingProof:    proof,
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForReqPTokens(reqPTokenAction[1], 1, metadata.PortalUserRequestPTokenMeta, suite.currentPortalState, suite.beaconHeight, suite.db, usedExternalTxKeys)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	return newInsts[0]
+}
+
+func buildPortalAction(metaType int, actionContent interface{}) []string {
+	actionContentBytes, _ := json.Marshal(actionContent)
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	return []string{strconv.Itoa(metaType), actionContentBase64Str}
+}
+
+func (suite *PortalProducerSuite) depositCollateral(custodianAddr string, remoteAddr string, amount uint64) {
+	action := buildPortalAction(metadata.PortalCustodianDepositMeta, metadata.PortalCustodianDepositAction{
+		Meta: metadata.PortalCustodianDeposit{
+			MetadataBase:    metadata.MetadataBase{Type: metadata.PortalCustodianDepositMeta},
+			IncogAddressStr: custodianAddr,
+			RemoteAddresses: map[string]string{common.PortalBTCIDStr: remoteAddr},
+			DepositedAmount: amount,
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForCustodianDeposit(action[1], 1, metadata.PortalCustodianDepositMeta, suite.currentPortalState, suite.beaconHeight)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	suite.Equal(common.PortalCustodianDepositAcceptedChainStatus, newInsts[0][2])
+}
+
+func (suite *PortalProducerSuite) requestPorting(uniqueID string, amount uint64) []string {
+	action := buildPortalAction(metadata.PortalUserRegisterMeta, metadata.PortalUserRegisterAction{
+		Meta: metadata.PortalUserRegister{
+			MetadataBase:     metadata.MetadataBase{Type: metadata.PortalUserRegisterMeta},
+			UniqueRegisterId: uniqueID,
+			IncogAddressStr:  portalTestUserAddr,
+			PTokenId:         common.PortalBTCIDStr,
+			RegisterAmount:   amount,
+			PortingFee:       100,
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForPortingRequest(action[1], 1, metadata.PortalUserRegisterMeta, suite.currentPortalState, suite.currentPDEState, suite.beaconHeight, suite.db)
+	suite.Equal(nil, err)
+	suite.Equal(1, len(newInsts))
+	return newInsts[0]
+}
+
+func (suite *PortalProducerSuite) TestCustodianDeposit() {
+	fmt.Println("Running testcase: TestCustodianDeposit")
+	suite.depositCollateral(portalTestCustodian1Addr, "btcAddress1", 1000)
+	suite.depositCollateral(portalTestCustodian1Addr, "btcAddress1", 500)
+
+	custodianKey := string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian1Addr))
+	custodian := suite.currentPortalState.CustodianPoolState[custodianKey]
+	suite.Equal(uint64(1500), custodian.TotalCollateral)
+	suite.Equal(uint64(1500), custodian.FreeCollateral)
+	suite.Equal("btcAddress1", custodian.RemoteAddresses[common.PortalBTCIDStr])
+}
+
+func (suite *PortalProducerSuite) TestPortingRequestMatchedToCustodians() {
+	fmt.Println("Running testcase: TestPortingRequestMatchedToCustodians")
+	// 0.1 BTC = 1000 PRV, 150% collateral = 1500 PRV
+	suite.depositCollateral(portalTestCustodian1Addr, "btcAddress1", 1000000000000)
+	suite.depositCollateral(portalTestCustodian2Addr, "btcAddress2", 600000000000)
+
+	inst := suite.requestPorting("porting-1", 10000000)
+	suite.Equal(common.PortalPortingRequestAcceptedChainStatus, inst[2])
+	var content metadata.PortalPortingRequestContent
+	suite.Equal(nil, json.Unmarshal([]byte(inst[3]), &content))
+	suite.Equal(2, len(content.Custodian))
+	suite.Equal(portalTestCustodian1Addr, content.Custodian[0].IncAddress)
+	suite.Equal(uint64(1000000000000), content.Custodian[0].LockedAmountCollateral)
+	suite.Equal(uint64(500000000000), content.Custodian[1].LockedAmountCollateral)
+	suite.Equal(uint64(10000000), content.Custodian[0].Amount+content.Custodian[1].Amount)
+
+	custodian2 := suite.currentPortalState.CustodianPoolState[string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian2Addr))]
+	suite.Equal(uint64(100000000000), custodian2.FreeCollateral)
+	suite.Equal(uint64(500000000000), custodian2.LockedAmountCollateral[common.PortalBTCIDStr])
+	suite.Equal(1, len(suite.currentPortalState.WaitingPortingRequests))
+
+	// the same porting id is rejected
+	inst = suite.requestPorting("porting-1", 1000)
+	suite.Equal(common.PortalPortingRequestRejectedChainStatus, inst[2])
+}
+
+func (suite *PortalProducerSuite) TestPortingRequestRejectedByNotEnoughCollateral() {
+	fmt.Println("Running testcase: TestPortingRequestRejectedByNotEnoughCollateral")
+	suite.depositCollateral(portalTestCustodian1Addr, "btcAddress1", 1000)
+	inst := suite.requestPorting("porting-2", 10000000)
+	suite.Equal(common.PortalPortingRequestRejectedChainStatus, inst[2])
+	custodian := suite.currentPortalState.CustodianPoolState[string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian1Addr))]
+	suite.Equal(uint64(1000), custodian.FreeCollateral)
+	suite.Equal(0, len(suite.currentPortalState.WaitingPortingRequests))
+}
+
+func (suite *PortalProducerSuite) TestPortingThenRedeemAndLiquidate() {
+	fmt.Println("Running testcase: TestPortingThenRedeemAndLiquidate")
+	btcAddress1, _ := portalTestBTCAddress(1)
+	suite.depositCollateral(portalTestCustodian1Addr, btcAddress1, 2000000000000)
+	inst := suite.requestPorting("porting-3", 10000000)
+	suite.Equal(common.PortalPortingRequestAcceptedChainStatus, inst[2])
+
+	// porter sends BTC to the custodian
+	_, custodianPkScript := portalTestBTCAddress(1)
+	proof := suite.sendBTC([][]byte{custodianPkScript}, []uint64{10000000}, metadata.PortalBTCMinConfirmations)
+	usedExternalTxKeys := map[string]bool{}
+	inst = suite.buildReqPTokensInst("porting-3", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensAcceptedChainStatus, inst[2])
+	var reqPTokensContent metadata.PortalRequestPTokensContent
+	suite.Equal(nil, json.Unmarshal([]byte(inst[3]), &reqPTokensContent))
+	suite.NotEqual("", reqPTokensContent.ExternalTxID)
+	custodianKey := string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian1Addr))
+	suite.Equal(uint64(10000000), suite.currentPortalState.CustodianPoolState[custodianKey].HoldingPubTokens[common.PortalBTCIDStr])
+	suite.Equal(0, len(suite.currentPortalState.WaitingPortingRequests))
+
+	// the same proof can not be used twice
+	inst = suite.buildReqPTokensInst("porting-3", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	// redeem a half
+	redeemAction := buildPortalAction(metadata.PortalRedeemRequestMeta, metadata.PortalRedeemRequestAction{
+		Meta: metadata.PortalRedeemRequest{
+			MetadataBase:          metadata.MetadataBase{Type: metadata.PortalRedeemRequestMeta},
+			UniqueRedeemID:        "redeem-1",
+			TokenID:               common.PortalBTCIDStr,
+			RedeemAmount:          5000000,
+			RedeemerIncAddressStr: portalTestUserAddr,
+			RemoteAddress:         "btcUserAddress",
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForRedeemRequest(redeemAction[1], 1, metadata.PortalRedeemRequestMeta, suite.currentPortalState, suite.beaconHeight, suite.db)
+	suite.Equal(nil, err)
+	suite.Equal(common.PortalRedeemRequestAcceptedChainStatus, newInsts[0][2])
+	custodian := suite.currentPortalState.CustodianPoolState[custodianKey]
+	suite.Equal(uint64(5000000), custodian.HoldingPubTokens[common.PortalBTCIDStr])
+	suite.Equal(uint64(750000000000), custodian.LockedAmountCollateral[common.PortalBTCIDStr])
+
+	// custodian does not return BTC in time
+	suite.Equal(0, len(suite.bc.buildInstsForLiquidatedCustodians(suite.currentPortalState, suite.beaconHeight)))
+	for _, redeemReq := range suite.currentPortalState.WaitingRedeemRequests {
+		redeemReq.BeaconHeight -= 10
+	}
+	liquidationInsts := suite.bc.buildInstsForLiquidatedCustodians(suite.currentPortalState, suite.beaconHeight)
+	suite.Equal(1, len(liquidationInsts))
+	suite.Equal(strconv.Itoa(metadata.PortalLiquidateCustodianMeta), liquidationInsts[0][0])
+	var liquidationContent metadata.PortalLiquidateCustodianContent
+	suite.Equal(nil, json.Unmarshal([]byte(liquidationInsts[0][3]), &liquidationContent))
+	suite.Equal(uint64(750000000000), liquidationContent.MintedCollateralAmount)
+	suite.Equal(portalTestUserAddr, liquidationContent.RedeemerIncAddressStr)
+	suite.Equal(uint64(1250000000000), custodian.TotalCollateral)
+	suite.Equal(0, len(suite.currentPortalState.WaitingRedeemRequests))
+}
+
+func (suite *PortalProducerSuite) TestExpiredPortingRequest() {
+	fmt.Println("Running testcase: TestExpiredPortingRequest")
+	suite.depositCollateral(portalTestCustodian1Addr, "btcAddress1", 2000000000000)
+	inst := suite.requestPorting("porting-4", 10000000)
+	suite.Equal(common.PortalPortingRequestAcceptedChainStatus, inst[2])
+
+	suite.Equal(0, len(suite.bc.buildInstsForExpiredPortingRequests(suite.currentPortalState, suite.beaconHeight)))
+	for _, portingReq := range suite.currentPortalState.WaitingPortingRequests {
+		portingReq.BeaconHeight -= 10
+	}
+	expiredInsts := suite.bc.buildInstsForExpiredPortingRequests(suite.currentPortalState, suite.beaconHeight)
+	suite.Equal(1, len(expiredInsts))
+	suite.Equal(common.PortalPortingRequestExpiredChainStatus, expiredInsts[0][2])
+	custodian := suite.currentPortalState.CustodianPoolState[string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian1Addr))]
+	suite.Equal(uint64(2000000000000), custodian.FreeCollateral)
+	suite.Equal(uint64(0), custodian.LockedAmountCollateral[common.PortalBTCIDStr])
+}
+
+func (suite *PortalProducerSuite) TestRelayingBTCHeader() {
+	fmt.Println("Running testcase: TestRelayingBTCHeader")
+	checkpoint := suite.btcTip
+	headers := suite.mineBTCBlocks(checkpoint, 3)
+	inst := suite.relayBTCHeaders(headers)
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.Equal(3, len(suite.currentPortalState.RelayedBTCHeaders))
+	suite.Equal(headers[2].Hex(), suite.currentPortalState.BTCHeaderChainTip.Header)
+	suite.Equal(uint64(3), suite.currentPortalState.BTCHeaderChainTip.Height)
+	suite.Equal(suite.beaconHeight+1, suite.currentPortalState.BTCHeaderChainTip.BeaconHeight)
+
+	// relaying known headers again is fine
+	inst = suite.relayBTCHeaders(headers[1:])
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.Equal(3, len(suite.currentPortalState.RelayedBTCHeaders))
+
+	// a shorter fork does not move the tip
+	fork := suite.mineBTCBlocks(headers[0], 1)
+	fork[0].Timestamp++
+	fork[0] = minePortalTestBTCHeader(fork[0].PrevBlock, fork[0].MerkleRoot, fork[0].Timestamp)
+	inst = suite.relayBTCHeaders(fork)
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.Equal(headers[2].Hex(), suite.currentPortalState.BTCHeaderChainTip.Header)
+	// a longer fork does
+	fork = append(fork, suite.mineBTCBlocks(fork[0], 2)...)
+	inst = suite.relayBTCHeaders(fork[1:])
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.Equal(fork[2].Hex(), suite.currentPortalState.BTCHeaderChainTip.Header)
+	suite.Equal(uint64(4), suite.currentPortalState.BTCHeaderChainTip.Height)
+	numRelayedHeaders := len(suite.currentPortalState.RelayedBTCHeaders)
+
+	// headers not extending the chain
+	orphans := suite.mineBTCBlocks(minePortalTestBTCHeader(common.Hash{1}, common.Hash{}, 1500000000), 2)
+	inst = suite.relayBTCHeaders(orphans)
+	suite.Equal(common.PortalRelayingBTCHeaderRejectedChainStatus, inst[2])
+	// nothing is added if a header is invalid
+	invalid := suite.mineBTCBlocks(fork[2], 2)
+	invalid[1].Bits = 0x1d00ffff
+	inst = suite.relayBTCHeaders(invalid)
+	suite.Equal(common.PortalRelayingBTCHeaderRejectedChainStatus, inst[2])
+	suite.Equal(numRelayedHeaders, len(suite.currentPortalState.RelayedBTCHeaders))
+	suite.Equal(fork[2].Hex(), suite.currentPortalState.BTCHeaderChainTip.Header)
+}
+
+func (suite *PortalProducerSuite) TestReqPTokensRejectedByInvalidProof() {
+	fmt.Println("Running testcase: TestReqPTokensRejectedByInvalidProof")
+	btcAddress1, custodianPkScript := portalTestBTCAddress(1)
+	_, otherPkScript := portalTestBTCAddress(2)
+	suite.depositCollateral(portalTestCustodian1Addr, btcAddress1, 2000000000000)
+	inst := suite.requestPorting("porting-5", 10000000)
+	suite.Equal(common.PortalPortingRequestAcceptedChainStatus, inst[2])
+	usedExternalTxKeys := map[string]bool{}
+
+	// not enough confirmations
+	proof := suite.sendBTC([][]byte{custodianPkScript}, []uint64{10000000}, metadata.PortalBTCMinConfirmations-1)
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	// paid to another address or not enough
+	proof = suite.sendBTC([][]byte{otherPkScript}, []uint64{10000000}, metadata.PortalBTCMinConfirmations)
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+	proof = suite.sendBTC([][]byte{custodianPkScript, otherPkScript}, []uint64{9999999, 1}, metadata.PortalBTCMinConfirmations)
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	// the tx is not in the block
+	proof = suite.sendBTC([][]byte{custodianPkScript}, []uint64{10000000}, metadata.PortalBTCMinConfirmations)
+	merkleProof, err := btc.ParseMerkleProof(proof)
+	suite.Equal(nil, err)
+	merkleProof.TxIndex = 0
+	tamperedProof, _ := merkleProof.Encode()
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, tamperedProof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	// the block was not relayed
+	merkleProof.TxIndex = 1
+	merkleProof.BlockHash = common.Hash{1}.String()
+	tamperedProof, _ = merkleProof.Encode()
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, tamperedProof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	// the block is not in the best chain any more
+	// rewind from the tip to the block before the one including the tx
+	forkBase := suite.currentPortalState.RelayedBTCHeaders[suite.btcTip.BlockHash().String()]
+	suite.NotNil(forkBase)
+	for i := 0; i < metadata.PortalBTCMinConfirmations; i++ {
+		header, _ := btc.ParseBlockHeaderHex(forkBase.Header)
+		forkBase = suite.currentPortalState.RelayedBTCHeaders[header.PrevBlock.String()]
+	}
+	forkHeader, _ := btc.ParseBlockHeaderHex(forkBase.Header)
+	fork := suite.mineBTCBlocks(forkHeader, metadata.PortalBTCMinConfirmations+1)
+	inst = suite.relayBTCHeaders(fork)
+	suite.Equal(common.PortalRelayingBTCHeaderAcceptedChainStatus, inst[2])
+	suite.Equal(fork[len(fork)-1].Hex(), suite.currentPortalState.BTCHeaderChainTip.Header)
+	inst = suite.buildReqPTokensInst("porting-5", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensRejectedChainStatus, inst[2])
+
+	suite.Equal(1, len(suite.currentPortalState.WaitingPortingRequests))
+	suite.Equal(0, len(usedExternalTxKeys))
+}
+
+func (suite *PortalProducerSuite) TestReqUnlockCollateral() {
+	fmt.Println("Running testcase: TestReqUnlockCollateral")
+	btcAddress1, custodianPkScript := portalTestBTCAddress(1)
+	redeemerAddress, redeemerPkScript := portalTestBTCAddress(3)
+	suite.depositCollateral(portalTestCustodian1Addr, btcAddress1, 2000000000000)
+	inst := suite.requestPorting("porting-6", 10000000)
+	suite.Equal(common.PortalPortingRequestAcceptedChainStatus, inst[2])
+	usedExternalTxKeys := map[string]bool{}
+	proof := suite.sendBTC([][]byte{custodianPkScript}, []uint64{10000000}, metadata.PortalBTCMinConfirmations)
+	inst = suite.buildReqPTokensInst("porting-6", 10000000, proof, usedExternalTxKeys)
+	suite.Equal(common.PortalReqPTokensAcceptedChainStatus, inst[2])
+
+	redeemAction := buildPortalAction(metadata.PortalRedeemRequestMeta, metadata.PortalRedeemRequestAction{
+		Meta: metadata.PortalRedeemRequest{
+			MetadataBase:          metadata.MetadataBase{Type: metadata.PortalRedeemRequestMeta},
+			UniqueRedeemID:        "redeem-2",
+			TokenID:               common.PortalBTCIDStr,
+			RedeemAmount:          4000000,
+			RedeemerIncAddressStr: portalTestUserAddr,
+			RemoteAddress:         redeemerAddress,
+		},
+		ShardID: 1,
+	})
+	newInsts, err := suite.bc.buildInstructionsForRedeemRequest(redeemAction[1], 1, metadata.PortalRedeemRequestMeta, suite.currentPortalState, suite.beaconHeight, suite.db)
+	suite.Equal(nil, err)
+	suite.Equal(common.PortalRedeemRequestAcceptedChainStatus, newInsts[0][2])
+
+	buildUnlockInst := func(redeemProof string) []string {
+		unlockAction := buildPortalAction(metadata.PortalRequestUnlockCollateralMeta, metadata.PortalRequestUnlockCollateralAction{
+			Meta: metadata.PortalRequestUnlockCollateral{
+				MetadataBase:        metadata.MetadataBase{Type: metadata.PortalRequestUnlockCollateralMeta},
+				UniqueRedeemID:      "redeem-2",
+				TokenID:             common.PortalBTCIDStr,
+				CustodianAddressStr: portalTestCustodian1Addr,
+				RedeemAmount:        4000000,
+				RedeemProof:         redeemProof,
+			},
+			ShardID: 1,
+		})
+		newInsts, err := suite.bc.buildInstructionsForReqUnlockCollateral(unlockAction[1], 1, metadata.PortalRequestUnlockCollateralMeta, suite.currentPortalState, suite.beaconHeight, suite.db, usedExternalTxKeys)
+		suite.Equal(nil, err)
+		suite.Equal(1, len(newInsts))
+		return newInsts[0]
+	}
+	// the porting tx can not be used to unlock collateral
+	inst = buildUnlockInst(proof)
+	suite.Equal(common.PortalReqUnlockCollateralRejectedChainStatus, inst[2])
+	// not enough is paid to the redeemer
+	inst = buildUnlockInst(suite.sendBTC([][]byte{redeemerPkScript}, []uint64{3999999}, metadata.PortalBTCMinConfirmations))
+	suite.Equal(common.PortalReqUnlockCollateralRejectedChainStatus, inst[2])
+
+	inst = buildUnlockInst(suite.sendBTC([][]byte{redeemerPkScript}, []uint64{4000000}, metadata.PortalBTCMinConfirmations))
+	suite.Equal(common.PortalReqUnlockCollateralAcceptedChainStatus, inst[2])
+	var unlockContent metadata.PortalRequestUnlockCollateralContent
+	suite.Equal(nil, json.Unmarshal([]byte(inst[3]), &unlockContent))
+	suite.Equal(uint64(600000000000), unlockContent.UnlockAmount)
+	suite.True(usedExternalTxKeys[string(lvdb.BuildPortalExternalTxKey(common.PortalBTCIDStr, unlockContent.ExternalTxID))])
+	custodian := suite.currentPortalState.CustodianPoolState[string(lvdb.BuildCustodianStateKey(suite.beaconHeight, portalTestCustodian1Addr))]
+	suite.Equal(uint64(6000000), custodian.HoldingPubTokens[common.PortalBTCIDStr])
+	suite.Equal(0, len(suite.currentPortalState.WaitingRedeemRequests))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestPortalProducerSuite(t *testing.T) {
+	suite.Run(t, new(PortalProducerSuite))
+}
@@ -70,7 +70,14 @@ func (blockchain *BlockChain) VerifyPreSignBeaconBlock(beaconBlock *BeaconBlock,
 func (blockchain *BlockChain) InsertBeaconBlock(beaconBlock *BeaconBlock, isValidated bool) error {
 	blockchain.chainLock.Lock()
 	defer blockchain.chainLock.Unlock()
+	return blockchain.insertBeaconBlock(beaconBlock, isValidated)
+}
 
+func (blockchain *BlockChain) insertBeaconBlock(beaconBlock *BeaconBlock, isValidated bool) error {
+	// the branch of the parent of beacon block wins over the best block
+	if err := blockchain.switchBeaconView(beaconBlock); err != nil {
+		return err
+	}
 	currentBeaconBestState := GetBeaconBestState()
 	if currentBeaconBestState.BeaconHeight == beaconBlock.Header.Height && currentBeaconBestState.BestBlock.Header.Timestamp < beaconBlock.Header.Timestamp && currentBeaconBestState.BestBlock.Header.Round < beaconBlock.Header.Round {
 		currentBeaconHeight, currentBeaconHash := currentBeaconBestState.BeaconHeight, currentBeaconBestState.BestBlockHash
@@ -233,6 +240,9 @@ func (blockchain *BlockChain) InsertBeaconBlock(beaconBlock *BeaconBlock, isVali
 		return err
 	}
 	blockchain.removeOldDataAfterProcessingBeaconBlock()
+	blockchain.removeDoubleSignEvidences(getDoubleSigners(beaconBlock))
+	blockchain.addBeaconView(beaconBlock)
+	blockchain.pruneChain(true, 0)
 	// go metrics.AnalyzeTimeSeriesMetricDataWithTime(map[string]interface{}{
 	// 	metrics.Measurement:      metrics.NumOfBlockInsertToChain,
 	// 	metrics.MeasurementValue: float64(1),
@@ -385,6 +395,10 @@ func (blockchain *BlockChain) verifyPreProcessingBeaconBlock(beaconBlock *Beacon
 	if !bytes.Equal(root, beaconBlock.Header.InstructionMerkleRoot[:]) {
 		return NewBlockChainError(FlattenAndConvertStringInstError, fmt.Errorf("Expect Instruction Merkle Root in Beacon Block Header to be %+v but get %+v", string(beaconBlock.Header.InstructionMerkleRoot[:]), string(root)))
 	}
+	// Double sign evidence in block must be valid and not expired
+	if _, err := blockchain.verifyDoubleSignInstructions(blockchain.BestState.Beacon, beaconBlock); err != nil {
+		return err
+	}
 	// if pool does not have one of needed block, fail to verify
 	if isPreSign {
 		if err := blockchain.verifyPreProcessingBeaconBlockForSigning(beaconBlock); err != nil {
@@ -513,6 +527,12 @@ func (blockchain *BlockChain) verifyPreProcessingBeaconBlockForSigning(beaconBlo
 	if len(rewardByEpochInstruction) != 0 {
 		tempInstruction = append(tempInstruction, rewardByEpochInstruction...)
 	}
+	// double sign instructions are chosen by producer from its evidence pool, they are verified one by one
+	doubleSignInstructions, err := blockchain.verifyDoubleSignInstructions(blockchain.BestState.Beacon, beaconBlock)
+	if err != nil {
+		return err
+	}
+	tempInstruction = append(tempInstruction, doubleSignInstructions...)
 	tempInstructionArr := []string{}
 	for _, strs := range tempInstruction {
 		tempInstructionArr = append(tempInstructionArr, strs...)
@@ -933,6 +953,17 @@ func (beaconBestState *BeaconBestState) processInstruction(instruction []string)
 			}
 		}
 	}
+	// ["doublesign" "{evidence}"]: double signer does not restake, it is swapped out as a black listed producer
+	if instruction[0] == DoubleSignAction {
+		evidence, err := ParseDoubleSignInstruction(instruction)
+		if err != nil {
+			return err, false, []incognitokey.CommitteePublicKey{}, []incognitokey.CommitteePublicKey{}
+		}
+		if _, ok := beaconBestState.AutoStaking[evidence.Signer]; ok {
+			beaconBestState.AutoStaking[evidence.Signer] = false
+		}
+		return nil, false, []incognitokey.CommitteePublicKey{}, []incognitokey.CommitteePublicKey{}
+	}
 	if instruction[0] == SwapAction {
 		Logger.log.Info("Swap Instruction", instruction)
 		inPublickeys := strings.Split(instruction[1], ",")
@@ -1220,9 +1251,9 @@ func (blockchain *BlockChain) processStoreBeaconBlock(
 	}
 
 	// execute, store
-	err = blockchain.processPDEInstructions(beaconBlock, &batchPutData)
+	err = blockchain.processStatefulInstructions(beaconBlock, &batchPutData)
 	if err != nil {
-		return NewBlockChainError(ProcessPDEInstructionError, err)
+		return NewBlockChainError(ProcessStatefulInstructionError, err)
 	}
 
 	return blockchain.config.DataBase.PutBatch(batchPutData)
@@ -120,6 +120,7 @@ func (blockGenerator *BlockGenerator) NewBlockBeacon(round int, shardsToBeaconLi
 	if len(rewardByEpochInstruction) != 0 {
 		tempInstruction = append(tempInstruction, rewardByEpochInstruction...)
 	}
+	tempInstruction = append(tempInstruction, blockGenerator.chain.buildDoubleSignInstructions(beaconBestState)...)
 	beaconBlock.Body.Instructions = tempInstruction
 	beaconBlock.Body.ShardState = tempShardState
 	if len(beaconBlock.Body.Instructions) != 0 {
@@ -233,7 +234,7 @@ func (blockGenerator *BlockGenerator) NewBlockBeacon(round int, shardsToBeaconLi
 	beaconBlock.Header.InstructionHash = tempInstructionHash
 	beaconBlock.Header.AutoStakingRoot = tempAutoStakingRoot
 	copy(beaconBlock.Header.InstructionMerkleRoot[:], GetKeccak256MerkleRoot(flattenInsts))
-	beaconBlock.Header.Timestamp = time.Now().Unix()
+	beaconBlock.Header.Timestamp = blockGenerator.chain.now().Unix()
 	//============END Build Header Hash=========
 	return beaconBlock, nil
 }
@@ -7,13 +7,12 @@ import (
 	"sort"
 	"strconv"
 
-	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/incognitochain/incognito-chain/database/lvdb"
 	"github.com/incognitochain/incognito-chain/metadata"
 )
 
-// build instructions at beacon chain before syncing to shards
+// collectStatefulActions picks actions of shard blocks that are consumed by registered stateful modules
 func (blockchain *BlockChain) collectStatefulActions(
 	shardBlockInstructions [][]string,
 ) [][]string {
@@ -32,14 +31,8 @@ func (blockchain *BlockChain) collectStatefulActions(
 			Logger.log.Error(err)
 			continue
 		}
-		switch metaType {
-		case metadata.IssuingRequestMeta, metadata.IssuingETHRequestMeta,
-			metadata.PDEContributionMeta, metadata.PDETradeRequestMeta,
-			metadata.PDEWithdrawalRequestMeta:
+		if _, found := statefulModulesByActionType[metaType]; found {
 			statefulInsts = append(statefulInsts, inst)
-
-		default:
-			continue
 		}
 	}
 	return statefulInsts
@@ -59,25 +52,13 @@ func groupPDEActionsByShardID(
 	return pdeActionsByShardID
 }
 
+// build instructions at beacon chain before syncing to shards
 func (blockchain *BlockChain) buildStatefulInstructions(
 	statefulActionsByShardID map[byte][][]string,
 	beaconHeight uint64,
 	db database.DatabaseInterface,
 ) [][]string {
-	currentPDEState, err := InitCurrentPDEStateFromDB(db, beaconHeight-1)
-	if err != nil {
-		Logger.log.Error(err)
-	}
-	accumulatedValues := &metadata.AccumulatedValues{
-		UniqETHTxsUsed:   [][]byte{},
-		DBridgeTokenPair: map[string][]byte{},
-		CBridgeTokens:    []*common.Hash{},
-	}
-	instructions := [][]string{}
-	pdeContributionActionsByShardID := map[byte][][]string{}
-	pdeTradeActionsByShardID := map[byte][][]string{}
-	pdeWithdrawalActionsByShardID := map[byte][][]string{}
-
+	actionsByModule := map[string]map[byte][][]string{}
 	var keys []int
 	for k := range statefulActionsByShardID {
 		keys = append(keys, int(k))
@@ -91,59 +72,126 @@ func (blockchain *BlockChain) buildStatefulInstructions(
 			if err != nil {
 				continue
 			}
-			contentStr := action[1]
-			newInst := [][]string{}
-			switch metaType {
-			case metadata.IssuingRequestMeta:
-				newInst, err = blockchain.buildInstructionsForIssuingReq(contentStr, shardID, metaType, accumulatedValues)
+			module, found := statefulModulesByActionType[metaType]
+			if !found {
+				continue
+			}
+			_, found = actionsByModule[module.Name()]
+			if !found {
+				actionsByModule[module.Name()] = map[byte][][]string{}
+			}
+			actionsByModule[module.Name()] = groupPDEActionsByShardID(
+				actionsByModule[module.Name()],
+				action,
+				shardID,
+			)
+		}
+	}
 
-			case metadata.IssuingETHRequestMeta:
-				newInst, err = blockchain.buildInstructionsForIssuingETHReq(contentStr, shardID, metaType, accumulatedValues)
+	instructions := [][]string{}
+	for _, module := range statefulModules {
+		state, err := module.InitState(db, beaconHeight-1)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while initializing state of stateful module %s: %+v", module.Name(), err)
+			continue
+		}
+		actionsByShardID, found := actionsByModule[module.Name()]
+		if !found {
+			actionsByShardID = map[byte][][]string{}
+		}
+		newInsts, err := module.BuildInstructions(blockchain, beaconHeight-1, state, actionsByShardID, db)
+		if err != nil {
+			Logger.log.Error(err)
+			continue
+		}
+		if len(newInsts) > 0 {
+			instructions = append(instructions, newInsts...)
+		}
+	}
+	return instructions
+}
 
-			case metadata.PDEContributionMeta:
-				pdeContributionActionsByShardID = groupPDEActionsByShardID(
-					pdeContributionActionsByShardID,
-					action,
-					shardID,
-				)
-			case metadata.PDETradeRequestMeta:
-				pdeTradeActionsByShardID = groupPDEActionsByShardID(
-					pdeTradeActionsByShardID,
-					action,
-					shardID,
-				)
-			case metadata.PDEWithdrawalRequestMeta:
-				pdeWithdrawalActionsByShardID = groupPDEActionsByShardID(
-					pdeWithdrawalActionsByShardID,
-					action,
-					shardID,
-				)
-			default:
+// processStatefulInstructions applies instructions of a beacon block to states of stateful modules
+func (blockchain *BlockChain) processStatefulInstructions(block *BeaconBlock, bd *[]database.BatchData) error {
+	beaconHeight := block.Header.Height - 1
+	db := blockchain.GetDatabase()
+	for _, module := range statefulModules {
+		state, err := module.InitState(db, beaconHeight)
+		if err != nil {
+			Logger.log.Error(err)
+			continue
+		}
+		instMetaTypes := map[string]bool{}
+		for _, metaType := range module.InstructionMetaTypes() {
+			instMetaTypes[strconv.Itoa(metaType)] = true
+		}
+		isProcessed := true
+		for _, inst := range block.Body.Instructions {
+			if len(inst) < 2 || !instMetaTypes[inst[0]] {
 				continue
 			}
+			err = module.ProcessInstruction(blockchain, beaconHeight, inst, state)
 			if err != nil {
 				Logger.log.Error(err)
-				continue
-			}
-			if len(newInst) > 0 {
-				instructions = append(instructions, newInst...)
+				isProcessed = false
+				break
 			}
 		}
+		if !isProcessed {
+			continue
+		}
+		// store updated state to leveldb with new beacon height
+		err = module.StoreState(db, beaconHeight+1, state)
+		if err != nil {
+			Logger.log.Error(err)
+		}
 	}
-	pdeInsts, err := blockchain.handlePDEInsts(
-		beaconHeight-1, currentPDEState,
-		pdeContributionActionsByShardID,
-		pdeTradeActionsByShardID,
-		pdeWithdrawalActionsByShardID,
-	)
-	if err != nil {
-		Logger.log.Error(err)
-		return instructions
+	return nil
+}
+
+// backupStatefulStates lets stateful modules back up data that can not be recovered from previous beacon heights
+func (blockchain *BlockChain) backupStatefulStates(block *BeaconBlock) error {
+	db := blockchain.GetDatabase()
+	for _, module := range statefulModules {
+		err := module.BackupState(db, block)
+		if err != nil {
+			return NewBlockChainError(BackupStatefulStateError, err)
+		}
 	}
-	if len(pdeInsts) > 0 {
-		instructions = append(instructions, pdeInsts...)
+	return nil
+}
+
+// restoreStatefulStates removes data stored by stateful modules while inserting a reverted beacon block
+func (blockchain *BlockChain) restoreStatefulStates(block *BeaconBlock) error {
+	db := blockchain.GetDatabase()
+	for _, module := range statefulModules {
+		err := module.RestoreState(db, block)
+		if err != nil {
+			return NewBlockChainError(RestoreStatefulStateError, err)
+		}
 	}
-	return instructions
+	return nil
+}
+
+// deleteRecordsByPrefixes deletes records stored at a beacon height under the given prefixes
+func deleteRecordsByPrefixes(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	prefixes [][]byte,
+) error {
+	for _, prefix := range prefixes {
+		keys, _, err := db.GetAllRecordsByPrefix(beaconHeight, prefix)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			err = db.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func sortPDETradeInstsByFee(
@@ -230,7 +278,10 @@ func (blockchain *BlockChain) handlePDEInsts(
 	currentPDEState *CurrentPDEState,
 	pdeContributionActionsByShardID map[byte][][]string,
 	pdeTradeActionsByShardID map[byte][][]string,
+	pdeMultiHopTradeActionsByShardID map[byte][][]string,
 	pdeWithdrawalActionsByShardID map[byte][][]string,
+	pdeLimitOrderActionsByShardID map[byte][][]string,
+	pdeCancelLimitOrderActionsByShardID map[byte][][]string,
 ) ([][]string, error) {
 	instructions := [][]string{}
 	sortedTradesActions := sortPDETradeInstsByFee(
@@ -239,6 +290,12 @@ func (blockchain *BlockChain) handlePDEInsts(
 		pdeTradeActionsByShardID,
 	)
 	for _, tradeAction := range sortedTradesActions {
+		// resting limit orders have priority over the pool pair if they pay better
+		newInsts, matched := blockchain.buildInstructionsForPDETradeOnLimitOrders(tradeAction, currentPDEState, beaconHeight)
+		if matched {
+			instructions = append(instructions, newInsts...)
+			continue
+		}
 		actionContentBytes, _ := json.Marshal(tradeAction)
 		actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
 		newInst, err := blockchain.buildInstructionsForPDETrade(actionContentBase64Str, tradeAction.ShardID, metadata.PDETradeRequestMeta, currentPDEState, beaconHeight)
@@ -251,6 +308,72 @@ func (blockchain *BlockChain) handlePDEInsts(
 		}
 	}
 
+	// handle multi-hop trades after direct ones
+	var mtKeys []int
+	for k := range pdeMultiHopTradeActionsByShardID {
+		mtKeys = append(mtKeys, int(k))
+	}
+	sort.Ints(mtKeys)
+	for _, value := range mtKeys {
+		shardID := byte(value)
+		actions := pdeMultiHopTradeActionsByShardID[shardID]
+		for _, action := range actions {
+			contentStr := action[1]
+			newInst, err := blockchain.buildInstructionsForPDEMultiHopTrade(contentStr, shardID, metadata.PDEMultiHopTradeRequestMeta, currentPDEState, beaconHeight)
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			if len(newInst) > 0 {
+				instructions = append(instructions, newInst...)
+			}
+		}
+	}
+
+	// handle limit order cancellations before new limit orders
+	var clKeys []int
+	for k := range pdeCancelLimitOrderActionsByShardID {
+		clKeys = append(clKeys, int(k))
+	}
+	sort.Ints(clKeys)
+	for _, value := range clKeys {
+		shardID := byte(value)
+		actions := pdeCancelLimitOrderActionsByShardID[shardID]
+		for _, action := range actions {
+			contentStr := action[1]
+			newInst, err := blockchain.buildInstructionsForPDECancelLimitOrder(contentStr, shardID, metadata.PDECancelLimitOrderRequestMeta, currentPDEState, beaconHeight)
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			if len(newInst) > 0 {
+				instructions = append(instructions, newInst...)
+			}
+		}
+	}
+
+	// handle limit orders
+	var loKeys []int
+	for k := range pdeLimitOrderActionsByShardID {
+		loKeys = append(loKeys, int(k))
+	}
+	sort.Ints(loKeys)
+	for _, value := range loKeys {
+		shardID := byte(value)
+		actions := pdeLimitOrderActionsByShardID[shardID]
+		for _, action := range actions {
+			contentStr := action[1]
+			newInst, err := blockchain.buildInstructionsForPDELimitOrder(contentStr, shardID, metadata.PDELimitOrderRequestMeta, currentPDEState, beaconHeight)
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			if len(newInst) > 0 {
+				instructions = append(instructions, newInst...)
+			}
+		}
+	}
+
 	// handle withdrawal
 	var wrKeys []int
 	for k := range pdeWithdrawalActionsByShardID {
@@ -294,5 +417,8 @@ func (blockchain *BlockChain) handlePDEInsts(
 			}
 		}
 	}
+
+	// fill resting limit orders reached by pool prices of this block
+	instructions = append(instructions, blockchain.buildInstructionsForPDELimitOrdersOnPoolPairs(currentPDEState, beaconHeight)...)
 	return instructions, nil
 }
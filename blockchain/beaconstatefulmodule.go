@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"sort"
+
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/pkg/errors"
+)
+
+// StatefulModule defines a beacon-level feature whose instructions are built from actions
+// collected in shard blocks and depend on a state carried over beacon blocks.
+// A module registers itself with RegisterStatefulModule, usually in an init function,
+// and is then driven by the beacon producer and processor without any change in them.
+type StatefulModule interface {
+	// Name returns the unique name of the module
+	Name() string
+	// Priority returns the rank of the module, modules build and process instructions by increasing priority
+	Priority() int
+	// ActionMetaTypes returns metadata types of the shard actions consumed by the module
+	ActionMetaTypes() []int
+	// InstructionMetaTypes returns metadata types of the beacon instructions processed by the module
+	InstructionMetaTypes() []int
+	// InitState loads the state of the module at a beacon height
+	InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error)
+	// BuildInstructions builds instructions from actions grouped by shard ID,
+	// beaconHeight is the height of the previous beacon block
+	BuildInstructions(
+		blockchain *BlockChain,
+		beaconHeight uint64,
+		state interface{},
+		actionsByShardID map[byte][][]string,
+		db database.DatabaseInterface,
+	) ([][]string, error)
+	// ProcessInstruction updates the state with an instruction of the beacon block at beaconHeight+1
+	ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error
+	// StoreState stores the state at a beacon height
+	StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error
+	// BackupState is called before a beacon block is inserted
+	BackupState(db database.DatabaseInterface, block *BeaconBlock) error
+	// RestoreState is called when an inserted beacon block is reverted
+	RestoreState(db database.DatabaseInterface, block *BeaconBlock) error
+}
+
+// priorities of stateful modules, they do not depend on the order init functions of their files run in:
+// - portal instructions are built before PDE ones since they use PDE pool prices
+// - pde analytics follows the instructions of the pde module and reads the state it stored
+const (
+	issuingModulePriority      = 100
+	portalModulePriority       = 200
+	pdeModulePriority          = 300
+	pdeAnalyticsModulePriority = 400
+)
+
+// statefulModules is sorted by priority, which is the order instructions are built and processed,
+// modules of the same priority keep their registration order
+var statefulModules = []StatefulModule{}
+var statefulModulesByActionType = make(map[int]StatefulModule)
+
+// RegisterStatefulModule adds a module to the beacon stateful module registry
+func RegisterStatefulModule(module StatefulModule) error {
+	for _, registeredModule := range statefulModules {
+		if registeredModule.Name() == module.Name() {
+			return NewBlockChainError(RegisterStatefulModuleError, errors.Errorf("Stateful module %s is already registered", module.Name()))
+		}
+	}
+	for _, metaType := range module.ActionMetaTypes() {
+		if registeredModule, exists := statefulModulesByActionType[metaType]; exists {
+			return NewBlockChainError(RegisterStatefulModuleError, errors.Errorf("Action type %d is already consumed by stateful module %s", metaType, registeredModule.Name()))
+		}
+	}
+	for _, metaType := range module.ActionMetaTypes() {
+		statefulModulesByActionType[metaType] = module
+	}
+	statefulModules = append(statefulModules, module)
+	sort.SliceStable(statefulModules, func(i, j int) bool {
+		return statefulModules[i].Priority() < statefulModules[j].Priority()
+	})
+	return nil
+}
@@ -0,0 +1,176 @@
+package blockchain
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeStatefulMetaType = 9999
+
+type fakeStatefulModule struct {
+	name             string
+	priority         int
+	metaTypeOffset   int // shifts the action type of the module so several fake modules can be registered
+	builtActions     map[byte][][]string
+	builtHeight      uint64
+	processedInsts   [][]string
+	storedHeight     uint64
+	restoredBlockHgt uint64
+}
+
+func (module *fakeStatefulModule) Name() string { return module.name }
+
+func (module *fakeStatefulModule) Priority() int { return module.priority }
+
+func (module *fakeStatefulModule) ActionMetaTypes() []int {
+	return []int{fakeStatefulMetaType + module.metaTypeOffset}
+}
+
+func (module *fakeStatefulModule) InstructionMetaTypes() []int { return []int{fakeStatefulMetaType} }
+
+func (module *fakeStatefulModule) InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error) {
+	return map[string]uint64{}, nil
+}
+
+func (module *fakeStatefulModule) BuildInstructions(
+	blockchain *BlockChain,
+	beaconHeight uint64,
+	state interface{},
+	actionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	module.builtActions = actionsByShardID
+	module.builtHeight = beaconHeight
+	insts := [][]string{}
+	for shardID, actions := range actionsByShardID {
+		for range actions {
+			insts = append(insts, []string{strconv.Itoa(fakeStatefulMetaType), strconv.Itoa(int(shardID)), "accepted"})
+		}
+	}
+	return insts, nil
+}
+
+func (module *fakeStatefulModule) ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error {
+	module.processedInsts = append(module.processedInsts, inst)
+	return nil
+}
+
+func (module *fakeStatefulModule) StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error {
+	module.storedHeight = beaconHeight
+	return nil
+}
+
+func (module *fakeStatefulModule) BackupState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
+
+func (module *fakeStatefulModule) RestoreState(db database.DatabaseInterface, block *BeaconBlock) error {
+	module.restoredBlockHgt = block.Header.Height
+	return nil
+}
+
+// registerFakeStatefulModule replaces registered modules by a fake one, the returned function restores them
+func registerFakeStatefulModule(t *testing.T, module StatefulModule) func() {
+	oldModules := statefulModules
+	oldModulesByActionType := statefulModulesByActionType
+	statefulModules = []StatefulModule{}
+	statefulModulesByActionType = make(map[int]StatefulModule)
+	assert.Nil(t, RegisterStatefulModule(module))
+	return func() {
+		statefulModules = oldModules
+		statefulModulesByActionType = oldModulesByActionType
+	}
+}
+
+func TestRegisterStatefulModule(t *testing.T) {
+	restore := registerFakeStatefulModule(t, &fakeStatefulModule{name: "fake"})
+	defer restore()
+
+	// duplicate name
+	assert.NotNil(t, RegisterStatefulModule(&fakeStatefulModule{name: "fake"}))
+	// action type is already consumed by another module
+	assert.NotNil(t, RegisterStatefulModule(&fakeStatefulModule{name: "other"}))
+	assert.Equal(t, 1, len(statefulModules))
+}
+
+func TestRegisterStatefulModulePriority(t *testing.T) {
+	restore := registerFakeStatefulModule(t, &fakeStatefulModule{name: "second", priority: 20})
+	defer restore()
+
+	assert.Nil(t, RegisterStatefulModule(&fakeStatefulModule{name: "third", priority: 30, metaTypeOffset: 1}))
+	assert.Nil(t, RegisterStatefulModule(&fakeStatefulModule{name: "first", priority: 10, metaTypeOffset: 2}))
+	assert.Nil(t, RegisterStatefulModule(&fakeStatefulModule{name: "second-bis", priority: 20, metaTypeOffset: 3}))
+	names := []string{}
+	for _, module := range statefulModules {
+		names = append(names, module.Name())
+	}
+	assert.Equal(t, []string{"first", "second", "second-bis", "third"}, names)
+}
+
+func TestBuiltinStatefulModules(t *testing.T) {
+	names := []string{}
+	for _, module := range statefulModules {
+		names = append(names, module.Name())
+	}
+	assert.Equal(t, []string{"issuing", "portal", "pde", "pdeanalytics"}, names)
+
+	for _, metaType := range []int{
+		metadata.IssuingRequestMeta,
+		metadata.IssuingETHRequestMeta,
+		metadata.PDEContributionMeta,
+		metadata.PDETradeRequestMeta,
+		metadata.PDEMultiHopTradeRequestMeta,
+		metadata.PDELimitOrderRequestMeta,
+		metadata.PDECancelLimitOrderRequestMeta,
+		metadata.PDEWithdrawalRequestMeta,
+		metadata.PortalCustodianDepositMeta,
+		metadata.PortalUserRegisterMeta,
+		metadata.PortalUserRequestPTokenMeta,
+		metadata.PortalRedeemRequestMeta,
+		metadata.PortalRequestUnlockCollateralMeta,
+	} {
+		_, found := statefulModulesByActionType[metaType]
+		assert.True(t, found, "action type %d is not consumed by any stateful module", metaType)
+	}
+}
+
+func TestStatefulModuleFlow(t *testing.T) {
+	module := &fakeStatefulModule{name: "fake"}
+	restore := registerFakeStatefulModule(t, module)
+	defer restore()
+
+	bc := &BlockChain{}
+	actions := bc.collectStatefulActions([][]string{
+		{strconv.Itoa(fakeStatefulMetaType), "content1"},
+		{strconv.Itoa(metadata.PDETradeRequestMeta), "content2"},
+		{StakeAction, "pubkey"},
+		{strconv.Itoa(fakeStatefulMetaType), "content3"},
+	})
+	assert.Equal(t, 2, len(actions))
+
+	db := &mocks.DatabaseInterface{}
+	insts := bc.buildStatefulInstructions(map[byte][][]string{1: actions, 0: actions[:1]}, 11, db)
+	assert.Equal(t, 3, len(insts))
+	assert.Equal(t, uint64(10), module.builtHeight)
+	assert.Equal(t, 2, len(module.builtActions[1]))
+	assert.Equal(t, 1, len(module.builtActions[0]))
+
+	bc.config.DataBase = db
+	block := &BeaconBlock{
+		Header: BeaconHeader{Height: 11},
+		Body: BeaconBody{Instructions: append(insts, []string{
+			strconv.Itoa(metadata.PDETradeRequestMeta), "0", "accepted", "",
+		})},
+	}
+	assert.Nil(t, bc.processStatefulInstructions(block, &[]database.BatchData{}))
+	assert.Equal(t, 3, len(module.processedInsts))
+	assert.Equal(t, uint64(11), module.storedHeight)
+
+	assert.Nil(t, bc.restoreStatefulStates(block))
+	assert.Equal(t, uint64(11), module.restoredBlockHgt)
+}
@@ -37,6 +37,11 @@ type BlockChain struct {
 	cQuitSync        chan struct{}
 	Synker           Synker
 	ConsensusOngoing bool
+	beaconTree       *BlockTree          // fork-aware tree of beacon blocks, see blocktree.go
+	shardTrees       map[byte]*BlockTree // fork-aware tree of blocks of each shard
+	shardTreesLock   sync.RWMutex        // shard blocks of different shards are inserted concurrently
+	// double sign evidence waiting for beacon block, see slashevidence.go
+	doubleSignEvidences doubleSignEvidencePool
 	//RPCClient        *rpccaller.RPCClient
 	IsTest bool
 }
@@ -66,6 +71,9 @@ type Config struct {
 	IsBlockGenStarted bool
 	PubSubManager     *pubsub.PubSubManager
 	RandomClient      btc.RandomClient
+	PruneBlockEpochs  uint64       // keep block bodies and transaction indexes of the last N epochs only, 0 disables pruning
+	TxHistoryIndex    bool         // index the transactions of every public key, see GetTxHistory
+	Clock             common.Clock // timestamps new blocks, the system clock when nil
 	Server            interface {
 		BoardcastNodeState() error
 		PublishNodeState(userLayer string, shardID int) error
@@ -149,11 +157,20 @@ func (blockchain *BlockChain) Init(config *Config) error {
 	if err := blockchain.initChainState(); err != nil {
 		return err
 	}
+	blockchain.initBlockTrees()
 	blockchain.cQuitSync = make(chan struct{})
 	blockchain.Synker = newSyncker(blockchain.cQuitSync, blockchain, blockchain.config.PubSubManager)
 	return nil
 }
 
+// now returns the time of the configured clock, new blocks are timestamped with it
+func (blockchain *BlockChain) now() time.Time {
+	if blockchain.config.Clock == nil {
+		return time.Now()
+	}
+	return blockchain.config.Clock.Now()
+}
+
 func (blockchain *BlockChain) SetIsBlockGenStarted(value bool) {
 	blockchain.config.IsBlockGenStarted = value
 }
@@ -396,6 +413,9 @@ func (blockchain *BlockChain) GetBeaconBlockByHeight(height uint64) (*BeaconBloc
 	}
 	beaconBlockHash, err := blockchain.config.DataBase.GetBeaconBlockHashByIndex(height)
 	if err != nil {
+		if prunedErr := blockchain.getBlockPrunedError(true, 0, height, nil); prunedErr != nil {
+			return nil, prunedErr
+		}
 		return nil, err
 	}
 	beaconBlock, _, err := blockchain.GetBeaconBlockByHash(beaconBlockHash)
@@ -414,6 +434,11 @@ func (blockchain *BlockChain) GetBeaconBlockByHash(beaconBlockHash common.Hash)
 	}
 	beaconBlockBytes, err := blockchain.config.DataBase.FetchBeaconBlock(beaconBlockHash)
 	if err != nil {
+		if height, errIdx := blockchain.config.DataBase.GetIndexOfBeaconBlock(beaconBlockHash); errIdx == nil {
+			if prunedErr := blockchain.getBlockPrunedError(true, 0, height, &beaconBlockHash); prunedErr != nil {
+				return nil, 0, prunedErr
+			}
+		}
 		return nil, 0, err
 	}
 	beaconBlock := NewBeaconBlock()
@@ -444,6 +469,9 @@ Fetch DatabaseInterface and get block by index(height) of block
 func (blockchain *BlockChain) GetShardBlockByHeight(height uint64, shardID byte) (*ShardBlock, error) {
 	hashBlock, err := blockchain.config.DataBase.GetBlockByIndex(height, shardID)
 	if err != nil {
+		if prunedErr := blockchain.getBlockPrunedError(false, shardID, height, nil); prunedErr != nil {
+			return nil, prunedErr
+		}
 		return nil, err
 	}
 	block, _, err := blockchain.GetShardBlockByHash(hashBlock)
@@ -460,6 +488,11 @@ func (blockchain *BlockChain) GetShardBlockByHash(hash common.Hash) (*ShardBlock
 	}
 	blockBytes, err := blockchain.config.DataBase.FetchBlock(hash)
 	if err != nil {
+		if height, shardID, errIdx := blockchain.config.DataBase.GetIndexOfBlock(hash); errIdx == nil {
+			if prunedErr := blockchain.getBlockPrunedError(false, shardID, height, &hash); prunedErr != nil {
+				return nil, 0, prunedErr
+			}
+		}
 		return nil, 0, err
 	}
 
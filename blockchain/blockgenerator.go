@@ -70,12 +70,28 @@ func (blockGenerator *BlockGenerator) RemoveTransactionV2Worker(cRemoveTx <-chan
 		time.Sleep(time.Nanosecond)
 	}
 }
+// GetPendingTxsV2 returns pending txs in priority order of tx pool (highest fee rate first),
+// pending txs which are no longer in tx pool come last
 func (blockGenerator *BlockGenerator) GetPendingTxsV2() []metadata.Transaction {
+	miningDescs := []*metadata.TxDesc{}
+	if blockGenerator.txPool != nil {
+		miningDescs = blockGenerator.txPool.MiningDescs()
+	}
 	blockGenerator.mtx.Lock()
 	defer blockGenerator.mtx.Unlock()
 	pendingTxs := []metadata.Transaction{}
-	for _, tx := range blockGenerator.PendingTxs {
-		pendingTxs = append(pendingTxs, tx)
+	addedTxs := make(map[common.Hash]struct{})
+	for _, desc := range miningDescs {
+		txHash := *desc.Tx.Hash()
+		if tx, ok := blockGenerator.PendingTxs[txHash]; ok {
+			pendingTxs = append(pendingTxs, tx)
+			addedTxs[txHash] = struct{}{}
+		}
+	}
+	for txHash, tx := range blockGenerator.PendingTxs {
+		if _, ok := addedTxs[txHash]; !ok {
+			pendingTxs = append(pendingTxs, tx)
+		}
 	}
 	return pendingTxs
 }
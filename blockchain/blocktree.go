@@ -0,0 +1,421 @@
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+/*
+Block tree of a chain (beacon or a shard), see specs/finality_pbft.md:
+- Every inserted block is a node of the tree, a fork (two blocks at the same height) makes a new branch
+- Each tip of the tree keeps its view: the block and the best state after inserting it
+- A block extending a tip which is a sibling of the best block makes the branch of the tip win: the block is checked
+against the view of the tip, chain reverts its best block (revert.go) and inserts the tip block again, then the block
+- Finality rule: a block is finalized when its child is proposed by the next rotated proposer in round 1,
+i.e. two consecutive-round blocks by different proposers. Then the majority has committed the block (Claim 6)
+and no other block at its height can be committed, so the block and its ancestors are irreversible
+- Root of the tree is the last finalized block, branches which do not descend from it are pruned
+- A branch which is 2 blocks shorter than the best branch is obsoleted (Claim 6) and pruned as well
+*/
+
+const obsoletedBranchDistance = 2
+
+type blockTreeNode struct {
+	hash     common.Hash
+	height   uint64
+	round    int
+	producer string
+	parent   *blockTreeNode
+	children []*blockTreeNode
+	// only kept by tips
+	block common.BlockInterface
+	view  interface{} // best state after block
+}
+
+type BlockTree struct {
+	lock      sync.RWMutex
+	nodes     map[common.Hash]*blockTreeNode
+	finalized *blockTreeNode
+	best      *blockTreeNode
+}
+
+func newBlockTreeNode(block common.BlockInterface, view interface{}) *blockTreeNode {
+	return &blockTreeNode{
+		hash:     *block.Hash(),
+		height:   block.GetHeight(),
+		round:    block.GetRound(),
+		producer: block.GetProducer(),
+		block:    block,
+		view:     view,
+	}
+}
+
+// NewBlockTree - new tree with block and its view as its root, the root is treated as finalized
+func NewBlockTree(block common.BlockInterface, view interface{}) *BlockTree {
+	tree := &BlockTree{}
+	tree.reset(newBlockTreeNode(block, view))
+	return tree
+}
+
+func (tree *BlockTree) reset(root *blockTreeNode) {
+	root.parent = nil
+	tree.nodes = map[common.Hash]*blockTreeNode{root.hash: root}
+	tree.finalized = root
+	tree.best = root
+}
+
+// AddBlock - add a new block inserted into chain with its view, the block becomes the best block of tree.
+// Block whose parent is not in tree (e.g. parent is pruned) becomes the new root
+func (tree *BlockTree) AddBlock(block common.BlockInterface, prevHash common.Hash, view interface{}) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	node := newBlockTreeNode(block, view)
+	if existed, ok := tree.nodes[node.hash]; ok {
+		// the block is inserted again after its branch wins
+		if len(existed.children) == 0 {
+			existed.block = block
+			existed.view = view
+		}
+		tree.best = existed
+		return
+	}
+	parent, ok := tree.nodes[prevHash]
+	if !ok {
+		Logger.log.Errorf("Block tree: parent %+v of block %+v is not found, reset tree", prevHash.String(), node.hash.String())
+		tree.reset(node)
+		return
+	}
+	node.parent = parent
+	parent.children = append(parent.children, node)
+	// parent is no longer a tip
+	parent.block = nil
+	parent.view = nil
+	tree.nodes[node.hash] = node
+	tree.best = node
+	if node.round == 1 && node.producer != parent.producer && parent.height > tree.finalized.height {
+		tree.finalize(parent)
+	}
+	tree.pruneObsoletedBranches()
+}
+
+// SetBest - switch best block to a block in tree, e.g. after reverting chain, view is kept if block is a tip
+func (tree *BlockTree) SetBest(hash common.Hash, view interface{}) error {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	node, ok := tree.nodes[hash]
+	if !ok {
+		return fmt.Errorf("block %+v is not in block tree", hash.String())
+	}
+	if len(node.children) == 0 && node.view == nil {
+		node.view = view
+	}
+	tree.best = node
+	return nil
+}
+
+// GetSiblingTipView - return block and view of tip hash if it is a sibling of best block,
+// i.e. a block extending it makes its branch longer than the best one
+func (tree *BlockTree) GetSiblingTipView(hash common.Hash) (common.BlockInterface, interface{}, bool) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	node, ok := tree.nodes[hash]
+	if !ok || node == tree.best || node.parent == nil || node.parent != tree.best.parent || len(node.children) != 0 {
+		return nil, nil, false
+	}
+	if node.block == nil || node.view == nil {
+		return nil, nil, false
+	}
+	return node.block, node.view, true
+}
+
+// finalize - make node the root of tree, prune all branches which do not descend from it
+func (tree *BlockTree) finalize(node *blockTreeNode) {
+	Logger.log.Infof("Block tree: finalize block %+v at height %+v", node.hash.String(), node.height)
+	for n := node; n.parent != nil; n = n.parent {
+		for _, sibling := range n.parent.children {
+			if sibling != n {
+				tree.removeSubtree(sibling)
+			}
+		}
+		delete(tree.nodes, n.parent.hash)
+	}
+	node.parent = nil
+	tree.finalized = node
+}
+
+// pruneObsoletedBranches - remove branches whose tip is far behind best block
+func (tree *BlockTree) pruneObsoletedBranches() {
+	for _, tip := range tree.tips() {
+		if tip == tree.best || tip.height+obsoletedBranchDistance > tree.best.height {
+			continue
+		}
+		// remove the branch up to its fork point
+		node := tip
+		for node.parent != nil && len(node.parent.children) == 1 && node.parent != tree.finalized && !tree.isAncestorOfBest(node.parent) {
+			node = node.parent
+		}
+		if node == tree.finalized || tree.isAncestorOfBest(node) {
+			continue
+		}
+		Logger.log.Infof("Block tree: prune obsoleted branch from block %+v at height %+v", node.hash.String(), node.height)
+		tree.removeSubtree(node)
+	}
+}
+
+func (tree *BlockTree) isAncestorOfBest(node *blockTreeNode) bool {
+	for n := tree.best; n != nil; n = n.parent {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSubtree - remove node and its descendants out of tree
+func (tree *BlockTree) removeSubtree(node *blockTreeNode) {
+	if node.parent != nil {
+		children := []*blockTreeNode{}
+		for _, child := range node.parent.children {
+			if child != node {
+				children = append(children, child)
+			}
+		}
+		node.parent.children = children
+	}
+	queue := []*blockTreeNode{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		delete(tree.nodes, current.hash)
+		queue = append(queue, current.children...)
+	}
+}
+
+// tips - return leaves of tree, the higher first
+func (tree *BlockTree) tips() []*blockTreeNode {
+	result := []*blockTreeNode{}
+	for _, node := range tree.nodes {
+		if len(node.children) == 0 {
+			result = append(result, node)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].height != result[j].height {
+			return result[i].height > result[j].height
+		}
+		return result[i].hash.String() < result[j].hash.String()
+	})
+	return result
+}
+
+// GetBestBlock - return hash and height of best block
+func (tree *BlockTree) GetBestBlock() (common.Hash, uint64) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	return tree.best.hash, tree.best.height
+}
+
+// GetFinalizedBlock - return hash and height of the last finalized block
+func (tree *BlockTree) GetFinalizedBlock() (common.Hash, uint64) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	return tree.finalized.hash, tree.finalized.height
+}
+
+// GetViews - return views of all tips which have one, view of best block first
+func (tree *BlockTree) GetViews() []interface{} {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	result := []interface{}{}
+	if tree.best.view != nil {
+		result = append(result, tree.best.view)
+	}
+	for _, tip := range tree.tips() {
+		if tip != tree.best && tip.view != nil {
+			result = append(result, tip.view)
+		}
+	}
+	return result
+}
+
+// GetNumberOfBranches - return number of tips in tree
+func (tree *BlockTree) GetNumberOfBranches() int {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	return len(tree.tips())
+}
+
+// HasBlock - block is in tree, i.e. it is not finalized yet or it is the last finalized block
+func (tree *BlockTree) HasBlock(hash common.Hash) bool {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	_, ok := tree.nodes[hash]
+	return ok
+}
+
+// initBlockTrees - root block tree of each chain at its best block
+func (blockchain *BlockChain) initBlockTrees() {
+	beaconView := cloneBeaconView(blockchain.BestState.Beacon)
+	blockchain.beaconTree = NewBlockTree(&blockchain.BestState.Beacon.BestBlock, beaconView)
+	blockchain.shardTreesLock.Lock()
+	defer blockchain.shardTreesLock.Unlock()
+	blockchain.shardTrees = make(map[byte]*BlockTree)
+	for shardID, shardBestState := range blockchain.BestState.Shard {
+		if shardBestState.BestBlock == nil {
+			continue
+		}
+		blockchain.shardTrees[shardID] = NewBlockTree(shardBestState.BestBlock, cloneShardView(shardBestState))
+	}
+}
+
+// cloneBeaconView - copy of beacon best state kept by block tree, nil if it can not be copied
+func cloneBeaconView(beaconBestState *BeaconBestState) interface{} {
+	beaconView := NewBeaconBestState()
+	if err := beaconView.cloneBeaconBestStateFrom(beaconBestState); err != nil {
+		Logger.log.Error(err)
+		return nil
+	}
+	return beaconView
+}
+
+// cloneShardView - copy of shard best state kept by block tree, nil if it can not be copied
+func cloneShardView(shardBestState *ShardBestState) interface{} {
+	shardView := &ShardBestState{}
+	if err := shardView.cloneShardBestStateFrom(shardBestState); err != nil {
+		Logger.log.Error(err)
+		return nil
+	}
+	return shardView
+}
+
+// addBeaconView - add inserted beacon block into block tree with a copy of current beacon best state
+func (blockchain *BlockChain) addBeaconView(beaconBlock *BeaconBlock) {
+	view := cloneBeaconView(blockchain.BestState.Beacon)
+	if blockchain.beaconTree == nil {
+		blockchain.beaconTree = NewBlockTree(beaconBlock, view)
+		return
+	}
+	blockchain.beaconTree.AddBlock(beaconBlock, beaconBlock.Header.PreviousBlockHash, view)
+}
+
+// addShardView - add inserted shard block into block tree of its shard with a copy of current shard best state
+func (blockchain *BlockChain) addShardView(shardBlock *ShardBlock) {
+	shardID := shardBlock.Header.ShardID
+	view := cloneShardView(blockchain.BestState.Shard[shardID])
+	blockchain.shardTreesLock.Lock()
+	defer blockchain.shardTreesLock.Unlock()
+	if blockchain.shardTrees == nil {
+		blockchain.shardTrees = make(map[byte]*BlockTree)
+	}
+	tree, ok := blockchain.shardTrees[shardID]
+	if !ok {
+		blockchain.shardTrees[shardID] = NewBlockTree(shardBlock, view)
+		return
+	}
+	tree.AddBlock(shardBlock, shardBlock.Header.PreviousBlockHash, view)
+}
+
+// setBeaconBestView - switch best block of beacon block tree after best state is reverted
+func (blockchain *BlockChain) setBeaconBestView(beaconBestState *BeaconBestState) {
+	if blockchain.beaconTree == nil {
+		return
+	}
+	if err := blockchain.beaconTree.SetBest(beaconBestState.BestBlockHash, cloneBeaconView(beaconBestState)); err != nil {
+		Logger.log.Error(err)
+	}
+}
+
+// setShardBestView - switch best block of shard block tree after best state is reverted
+func (blockchain *BlockChain) setShardBestView(shardID byte, shardBestState *ShardBestState) {
+	tree := blockchain.GetShardBlockTree(shardID)
+	if tree == nil {
+		return
+	}
+	if err := tree.SetBest(shardBestState.BestBlockHash, cloneShardView(shardBestState)); err != nil {
+		Logger.log.Error(err)
+	}
+}
+
+// switchBeaconView - switch to the branch of the parent of beaconBlock if it is a tip sibling of the best block:
+// check beaconBlock against the view of the tip, revert the best block and insert the tip block again.
+// Chain must be locked
+func (blockchain *BlockChain) switchBeaconView(beaconBlock *BeaconBlock) error {
+	if blockchain.beaconTree == nil || beaconBlock.Header.PreviousBlockHash.IsEqual(&blockchain.BestState.Beacon.BestBlockHash) {
+		return nil
+	}
+	block, view, ok := blockchain.beaconTree.GetSiblingTipView(beaconBlock.Header.PreviousBlockHash)
+	if !ok {
+		return nil
+	}
+	tipBlock, tipView := block.(*BeaconBlock), view.(*BeaconBestState)
+	if err := tipView.verifyBestStateWithBeaconBlock(beaconBlock, true, blockchain.config.ChainParams.Epoch); err != nil {
+		return err
+	}
+	Logger.log.Infof("BEACON | Switch best view from block %+v to block %+v at height %+v", blockchain.BestState.Beacon.BestBlockHash.String(), tipView.BestBlockHash.String(), tipView.BeaconHeight)
+	if err := blockchain.revertBeaconState(); err != nil {
+		return NewBlockChainError(RevertStateError, err)
+	}
+	return blockchain.insertBeaconBlock(tipBlock, true)
+}
+
+// switchShardView - switch to the branch of the parent of shardBlock if it is a tip sibling of the best block:
+// check shardBlock against the view of the tip, revert the best block and insert the tip block again.
+// Chain and shard best state must be locked
+func (blockchain *BlockChain) switchShardView(shardBlock *ShardBlock) error {
+	shardID := shardBlock.Header.ShardID
+	tree := blockchain.GetShardBlockTree(shardID)
+	if tree == nil || shardBlock.Header.PreviousBlockHash.IsEqual(&blockchain.BestState.Shard[shardID].BestBlockHash) {
+		return nil
+	}
+	block, view, ok := tree.GetSiblingTipView(shardBlock.Header.PreviousBlockHash)
+	if !ok {
+		return nil
+	}
+	tipBlock, tipView := block.(*ShardBlock), view.(*ShardBestState)
+	if err := tipView.verifyBestStateWithShardBlock(shardBlock, true, shardID); err != nil {
+		return err
+	}
+	Logger.log.Infof("SHARD %+v | Switch best view from block %+v to block %+v at height %+v", shardID, blockchain.BestState.Shard[shardID].BestBlockHash.String(), tipView.BestBlockHash.String(), tipView.ShardHeight)
+	if err := blockchain.revertShardState(shardID); err != nil {
+		return NewBlockChainError(RevertStateError, err)
+	}
+	return blockchain.insertShardBlock(tipBlock, true)
+}
+
+// GetBeaconViews - return beacon best states of all branches, the best one first
+func (blockchain *BlockChain) GetBeaconViews() []*BeaconBestState {
+	result := []*BeaconBestState{}
+	if blockchain.beaconTree == nil {
+		return result
+	}
+	for _, view := range blockchain.beaconTree.GetViews() {
+		result = append(result, view.(*BeaconBestState))
+	}
+	return result
+}
+
+// GetShardViews - return shard best states of all branches, the best one first
+func (blockchain *BlockChain) GetShardViews(shardID byte) []*ShardBestState {
+	result := []*ShardBestState{}
+	tree := blockchain.GetShardBlockTree(shardID)
+	if tree == nil {
+		return result
+	}
+	for _, view := range tree.GetViews() {
+		result = append(result, view.(*ShardBestState))
+	}
+	return result
+}
+
+func (blockchain *BlockChain) GetBeaconBlockTree() *BlockTree {
+	return blockchain.beaconTree
+}
+
+func (blockchain *BlockChain) GetShardBlockTree(shardID byte) *BlockTree {
+	blockchain.shardTreesLock.RLock()
+	defer blockchain.shardTreesLock.RUnlock()
+	return blockchain.shardTrees[shardID]
+}
@@ -0,0 +1,147 @@
+package blockchain
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBlockTreeTestBlock(prevBlock *ShardBlock, round int, producer string) *ShardBlock {
+	block := &ShardBlock{
+		Header: ShardHeader{
+			Height:   1,
+			Round:    round,
+			Producer: producer,
+		},
+	}
+	if prevBlock != nil {
+		block.Header.Height = prevBlock.Header.Height + 1
+		block.Header.PreviousBlockHash = *prevBlock.Hash()
+	}
+	return block
+}
+
+func TestBlockTree_Finality(t *testing.T) {
+	root := newBlockTreeTestBlock(nil, 1, "a")
+	tree := NewBlockTree(root, "view1")
+	block2 := newBlockTreeTestBlock(root, 1, "b")
+	tree.AddBlock(block2, *root.Hash(), "view2")
+	// block 3 is proposed after a timeout, block 2 is not finalized
+	block3 := newBlockTreeTestBlock(block2, 2, "c")
+	tree.AddBlock(block3, *block2.Hash(), "view3")
+	_, finalizedHeight := tree.GetFinalizedBlock()
+	assert.Equal(t, uint64(1), finalizedHeight)
+	// block 4 is proposed by the next proposer right after block 3
+	block4 := newBlockTreeTestBlock(block3, 1, "d")
+	tree.AddBlock(block4, *block3.Hash(), "view4")
+	finalizedHash, finalizedHeight := tree.GetFinalizedBlock()
+	assert.Equal(t, uint64(3), finalizedHeight)
+	assert.Equal(t, *block3.Hash(), finalizedHash)
+	assert.False(t, tree.HasBlock(*block2.Hash()))
+	bestHash, bestHeight := tree.GetBestBlock()
+	assert.Equal(t, uint64(4), bestHeight)
+	assert.Equal(t, *block4.Hash(), bestHash)
+	assert.Equal(t, 1, tree.GetNumberOfBranches())
+	// only tips keep their view
+	assert.Equal(t, []interface{}{"view4"}, tree.GetViews())
+}
+
+func TestBlockTree_Fork(t *testing.T) {
+	root := newBlockTreeTestBlock(nil, 1, "a")
+	tree := NewBlockTree(root, "view1")
+	forkA := newBlockTreeTestBlock(root, 2, "b")
+	tree.AddBlock(forkA, *root.Hash(), "viewA")
+	// chain is reverted and another block at the same height is inserted
+	err := tree.SetBest(*root.Hash(), "view1")
+	assert.Nil(t, err)
+	forkB := newBlockTreeTestBlock(root, 3, "c")
+	tree.AddBlock(forkB, *root.Hash(), "viewB")
+	assert.Equal(t, 2, tree.GetNumberOfBranches())
+	bestHash, _ := tree.GetBestBlock()
+	assert.Equal(t, *forkB.Hash(), bestHash)
+	assert.Equal(t, []interface{}{"viewB", "viewA"}, tree.GetViews())
+	err = tree.SetBest(common.HashH([]byte("unknown")), nil)
+	assert.NotNil(t, err)
+
+	// a block extending fork A makes it win, fork A keeps its block and view to switch to
+	block, view, ok := tree.GetSiblingTipView(*forkA.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, forkA, block)
+	assert.Equal(t, "viewA", view)
+	_, _, ok = tree.GetSiblingTipView(*forkB.Hash())
+	assert.False(t, ok)
+	_, _, ok = tree.GetSiblingTipView(*root.Hash())
+	assert.False(t, ok)
+	// chain reverts fork B and inserts fork A again
+	assert.Nil(t, tree.SetBest(*root.Hash(), "view1"))
+	tree.AddBlock(forkA, *root.Hash(), "viewA")
+	bestHash, _ = tree.GetBestBlock()
+	assert.Equal(t, *forkA.Hash(), bestHash)
+	assert.Equal(t, []interface{}{"viewA", "viewB"}, tree.GetViews())
+	_, view, ok = tree.GetSiblingTipView(*forkB.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, "viewB", view)
+	// and back to fork B
+	assert.Nil(t, tree.SetBest(*root.Hash(), "view1"))
+	tree.AddBlock(forkB, *root.Hash(), "viewB")
+
+	// branch A is obsoleted when branch B is 2 blocks longer
+	forkB2 := newBlockTreeTestBlock(forkB, 2, "d")
+	tree.AddBlock(forkB2, *forkB.Hash(), "viewB2")
+	assert.Equal(t, 2, tree.GetNumberOfBranches())
+	// fork A is not a sibling of best block anymore, extending it does not make it win
+	_, _, ok = tree.GetSiblingTipView(*forkA.Hash())
+	assert.False(t, ok)
+	forkB3 := newBlockTreeTestBlock(forkB2, 2, "a")
+	tree.AddBlock(forkB3, *forkB2.Hash(), "viewB3")
+	assert.Equal(t, 1, tree.GetNumberOfBranches())
+	assert.False(t, tree.HasBlock(*forkA.Hash()))
+	assert.True(t, tree.HasBlock(*root.Hash()))
+	_, finalizedHeight := tree.GetFinalizedBlock()
+	assert.Equal(t, uint64(1), finalizedHeight)
+
+	// block whose parent is unknown resets tree
+	orphan := newBlockTreeTestBlock(forkA, 1, "b")
+	orphan.Header.Height = 10
+	tree.AddBlock(orphan, common.HashH([]byte("unknown")), "viewOrphan")
+	_, finalizedHeight = tree.GetFinalizedBlock()
+	assert.Equal(t, uint64(10), finalizedHeight)
+	assert.Equal(t, 1, tree.GetNumberOfBranches())
+}
+
+func TestBlockChain_addShardView(t *testing.T) {
+	blockchain := &BlockChain{BestState: &BestState{Shard: make(map[byte]*ShardBestState)}}
+	for shardID := 0; shardID < 8; shardID++ {
+		blockchain.BestState.Shard[byte(shardID)] = &ShardBestState{ShardID: byte(shardID), ShardHeight: 1}
+	}
+	wg := sync.WaitGroup{}
+	for shardID := 0; shardID < 8; shardID++ {
+		wg.Add(2)
+		go func(shardID byte) {
+			defer wg.Done()
+			var prevBlock *ShardBlock
+			for i := 0; i < 10; i++ {
+				block := newBlockTreeTestBlock(prevBlock, 1, "a")
+				block.Header.ShardID = shardID
+				blockchain.addShardView(block)
+				prevBlock = block
+			}
+		}(byte(shardID))
+		go func(shardID byte) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				blockchain.GetShardBlockTree(shardID)
+			}
+		}(byte(shardID))
+	}
+	wg.Wait()
+	for shardID := 0; shardID < 8; shardID++ {
+		tree := blockchain.GetShardBlockTree(byte(shardID))
+		if assert.NotNil(t, tree) {
+			_, bestHeight := tree.GetBestBlock()
+			assert.Equal(t, uint64(10), bestHeight)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package btc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/incognitochain/incognito-chain/common/base58"
+)
+
+// EncodeBase58Address encodes a P2PKH or P2SH address,
+// the checksum is the first 4 bytes of the double sha256 of the payload
+func EncodeBase58Address(hash160 []byte, netID byte) string {
+	payload := make([]byte, 0, 1+len(hash160)+4)
+	payload = append(payload, netID)
+	payload = append(payload, hash160...)
+	checksum := doubleHashH(payload)
+	payload = append(payload, checksum[:4]...)
+	return base58.Base58{}.Encode(payload)
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups bits of data from fromBits to toBits per group, padding the last group
+func convertBits(data []byte, fromBits uint, toBits uint) []byte {
+	acc := uint32(0)
+	bits := uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	result := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		acc = acc<<fromBits | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxValue))
+		}
+	}
+	if bits > 0 {
+		result = append(result, byte(acc<<(toBits-bits)&maxValue))
+	}
+	return result
+}
+
+// EncodeSegwitAddress encodes a version 0 witness program into a bech32 address (BIP173)
+func EncodeSegwitAddress(hrp string, witnessVersion byte, witnessProgram []byte) (string, error) {
+	if witnessVersion != 0 {
+		return "", NewBTCAPIError(ParseAddressError, fmt.Errorf("witness version %d is not supported", witnessVersion))
+	}
+	if len(witnessProgram) != hash160Size && len(witnessProgram) != witnessScriptHashSize {
+		return "", NewBTCAPIError(ParseAddressError, fmt.Errorf("invalid witness program size %d", len(witnessProgram)))
+	}
+	data := append([]byte{witnessVersion}, convertBits(witnessProgram, 8, 5)...)
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
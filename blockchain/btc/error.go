@@ -23,6 +23,12 @@ const (
 	GetBlockHeaderResultError
 	ParseNonceResultError
 	ParseTimestampResultError
+	ParseBlockHeaderError
+	ParseTransactionError
+	ParseAddressError
+	ProofOfWorkError
+	MerkleProofError
+	UnknownBlockHeaderError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -41,6 +47,12 @@ var ErrCodeMessage = map[int]struct {
 	GetBlockHeaderResultError: {-10, "Get Block Header Result Error"},
 	ParseNonceResultError:     {-11, "Parse Nonce Result Error"},
 	ParseTimestampResultError: {-12, "Parse Timestamp Result Error"},
+	ParseBlockHeaderError:     {-13, "Parse Block Header Error"},
+	ParseTransactionError:     {-14, "Parse Transaction Error"},
+	ParseAddressError:         {-15, "Parse Address Error"},
+	ProofOfWorkError:          {-16, "Proof Of Work Error"},
+	MerkleProofError:          {-17, "Merkle Proof Error"},
+	UnknownBlockHeaderError:   {-18, "Unknown Block Header Error"},
 }
 
 type BTCAPIError struct {
@@ -0,0 +1,182 @@
+package btc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+const BlockHeaderSize = 80
+
+// BlockHeader is a bitcoin block header, hashes are kept in internal byte order
+type BlockHeader struct {
+	Version    int32
+	PrevBlock  common.Hash
+	MerkleRoot common.Hash
+	Timestamp  uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// doubleHashH returns sha256(sha256(b)), the hash used all over bitcoin
+func doubleHashH(b []byte) common.Hash {
+	first := sha256.Sum256(b)
+	return common.Hash(sha256.Sum256(first[:]))
+}
+
+// ParseBlockHeader parses an 80 bytes serialized block header
+func ParseBlockHeader(raw []byte) (*BlockHeader, error) {
+	if len(raw) != BlockHeaderSize {
+		return nil, NewBTCAPIError(ParseBlockHeaderError, fmt.Errorf("header size is %d, expected %d", len(raw), BlockHeaderSize))
+	}
+	header := &BlockHeader{
+		Version:   int32(binary.LittleEndian.Uint32(raw[0:4])),
+		Timestamp: binary.LittleEndian.Uint32(raw[68:72]),
+		Bits:      binary.LittleEndian.Uint32(raw[72:76]),
+		Nonce:     binary.LittleEndian.Uint32(raw[76:80]),
+	}
+	copy(header.PrevBlock[:], raw[4:36])
+	copy(header.MerkleRoot[:], raw[36:68])
+	return header, nil
+}
+
+// ParseBlockHeaderHex parses a block header serialized in hex
+func ParseBlockHeaderHex(headerStr string) (*BlockHeader, error) {
+	raw, err := hex.DecodeString(headerStr)
+	if err != nil {
+		return nil, NewBTCAPIError(ParseBlockHeaderError, err)
+	}
+	return ParseBlockHeader(raw)
+}
+
+// Bytes serializes the header into 80 bytes
+func (header *BlockHeader) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, header.Version)
+	buf.Write(header.PrevBlock[:])
+	buf.Write(header.MerkleRoot[:])
+	_ = binary.Write(&buf, binary.LittleEndian, header.Timestamp)
+	_ = binary.Write(&buf, binary.LittleEndian, header.Bits)
+	_ = binary.Write(&buf, binary.LittleEndian, header.Nonce)
+	return buf.Bytes()
+}
+
+// Hex serializes the header into hex
+func (header *BlockHeader) Hex() string {
+	return hex.EncodeToString(header.Bytes())
+}
+
+// BlockHash returns the block hash, its String() is the hash shown by block explorers
+func (header *BlockHeader) BlockHash() common.Hash {
+	return doubleHashH(header.Bytes())
+}
+
+// CheckProofOfWork makes sure the target of the header is in range and the block hash meets it
+func (header *BlockHeader) CheckProofOfWork(params *Params) error {
+	target := CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		return NewBTCAPIError(ProofOfWorkError, fmt.Errorf("target %064x is not positive", target))
+	}
+	if target.Cmp(params.PowLimit) > 0 {
+		return NewBTCAPIError(ProofOfWorkError, fmt.Errorf("target %064x is higher than the pow limit %064x", target, params.PowLimit))
+	}
+	hash := header.BlockHash()
+	if HashToBig(hash).Cmp(target) > 0 {
+		return NewBTCAPIError(ProofOfWorkError, fmt.Errorf("block hash %s is higher than the target %064x", hash.String(), target))
+	}
+	return nil
+}
+
+// HashToBig interprets a hash in internal byte order as a little endian number
+func HashToBig(hash common.Hash) *big.Int {
+	buf := hash
+	for i := 0; i < common.HashSize/2; i++ {
+		buf[i], buf[common.HashSize-1-i] = buf[common.HashSize-1-i], buf[i]
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// CompactToBig converts the compact form of a target (the bits of a header) to a big number
+func CompactToBig(compact uint32) *big.Int {
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+	return bn
+}
+
+// BigToCompact converts a target to its compact form
+func BigToCompact(n *big.Int) uint32 {
+	if n.Sign() == 0 {
+		return 0
+	}
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Bits()[0])
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		tn := new(big.Int).Set(n)
+		mantissa = uint32(tn.Rsh(tn, 8*(exponent-3)).Bits()[0])
+	}
+	// the sign bit is set, so move the mantissa one byte to the right
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// CalcWork returns the expected number of hashes to mine a block with the given bits
+func CalcWork(bits uint32) *big.Int {
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	// work = 2^256 / (target + 1)
+	denominator := new(big.Int).Add(target, bigOne)
+	return new(big.Int).Div(new(big.Int).Lsh(bigOne, 256), denominator)
+}
+
+// CalcRetargetBits returns bits of the first block of a retarget interval,
+// from bits of the last block of the previous interval and the time that interval took
+func CalcRetargetBits(params *Params, lastBits uint32, firstTimestamp uint32, lastTimestamp uint32) uint32 {
+	if params.PoWNoRetargeting {
+		return lastBits
+	}
+	minTimespan := params.TargetTimespan / params.RetargetAdjustment
+	maxTimespan := params.TargetTimespan * params.RetargetAdjustment
+	actualTimespan := int64(lastTimestamp) - int64(firstTimestamp)
+	if actualTimespan < minTimespan {
+		actualTimespan = minTimespan
+	} else if actualTimespan > maxTimespan {
+		actualTimespan = maxTimespan
+	}
+	newTarget := CompactToBig(lastBits)
+	newTarget.Mul(newTarget, big.NewInt(actualTimespan))
+	newTarget.Div(newTarget, big.NewInt(params.TargetTimespan))
+	if newTarget.Cmp(params.PowLimit) > 0 {
+		newTarget.Set(params.PowLimit)
+	}
+	return BigToCompact(newTarget)
+}
@@ -0,0 +1,79 @@
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+// ChainHeader is a block header placed in a header chain
+type ChainHeader struct {
+	Header    *BlockHeader
+	Height    uint64
+	ChainWork *big.Int // total work of the chain up to this header
+}
+
+// HeaderGetter looks up a header of the chain by its hash, returns nil if it is unknown
+type HeaderGetter func(hash common.Hash) (*ChainHeader, error)
+
+// ConnectHeader validates a header against the chain it extends and places it on top of its parent,
+// the chain must be started at the first block of a retarget interval
+func ConnectHeader(params *Params, header *BlockHeader, getHeader HeaderGetter) (*ChainHeader, error) {
+	prev, err := getHeader(header.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		return nil, NewBTCAPIError(UnknownBlockHeaderError, fmt.Errorf("previous block %s is unknown", header.PrevBlock.String()))
+	}
+	err = header.CheckProofOfWork(params)
+	if err != nil {
+		return nil, err
+	}
+	height := prev.Height + 1
+	blocksPerRetarget := params.BlocksPerRetarget()
+	if height%blocksPerRetarget == 0 {
+		first := prev
+		for i := uint64(1); i < blocksPerRetarget; i++ {
+			first, err = getHeader(first.Header.PrevBlock)
+			if err != nil {
+				return nil, err
+			}
+			if first == nil {
+				return nil, NewBTCAPIError(UnknownBlockHeaderError, errors.New("headers of the previous retarget interval are unknown"))
+			}
+		}
+		requiredBits := CalcRetargetBits(params, prev.Header.Bits, first.Header.Timestamp, prev.Header.Timestamp)
+		if header.Bits != requiredBits {
+			return nil, NewBTCAPIError(ProofOfWorkError, fmt.Errorf("bits %08x of a retarget block, expected %08x", header.Bits, requiredBits))
+		}
+	} else if !params.ReduceMinDifficulty && header.Bits != prev.Header.Bits {
+		return nil, NewBTCAPIError(ProofOfWorkError, fmt.Errorf("bits %08x changed between retargets, expected %08x", header.Bits, prev.Header.Bits))
+	}
+	return &ChainHeader{
+		Header:    header,
+		Height:    height,
+		ChainWork: new(big.Int).Add(prev.ChainWork, CalcWork(header.Bits)),
+	}, nil
+}
+
+// IsInChain checks whether a header is an ancestor of (or is) the tip, looking back at most maxDepth headers
+func IsInChain(tip *ChainHeader, hash common.Hash, height uint64, maxDepth uint64, getHeader HeaderGetter) (bool, error) {
+	if tip == nil || height > tip.Height || tip.Height-height > maxDepth {
+		return false, nil
+	}
+	current := tip
+	for current.Height > height {
+		prev, err := getHeader(current.Header.PrevBlock)
+		if err != nil {
+			return false, err
+		}
+		if prev == nil {
+			return false, nil
+		}
+		current = prev
+	}
+	return current.Header.BlockHash() == hash, nil
+}
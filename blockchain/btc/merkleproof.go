@@ -0,0 +1,102 @@
+package btc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+// max depth of the merkle tree of a block, far more than a block can hold txs for
+const maxMerklePathLength = 32
+
+// MerkleProof proves a tx is included in a block, hashes are in hex as shown by block explorers
+type MerkleProof struct {
+	BlockHash  string   // hash of the block including the tx
+	TxIndex    uint32   // position of the tx in the block
+	MerklePath []string // sibling hashes from the tx up to the merkle root
+	RawTx      string   // serialized tx, in hex
+}
+
+// ParseMerkleProof decodes a proof encoded as base64 of its json
+func ParseMerkleProof(proofStr string) (*MerkleProof, error) {
+	proofBytes, err := base64.StdEncoding.DecodeString(proofStr)
+	if err != nil {
+		return nil, NewBTCAPIError(MerkleProofError, err)
+	}
+	var proof MerkleProof
+	err = json.Unmarshal(proofBytes, &proof)
+	if err != nil {
+		return nil, NewBTCAPIError(MerkleProofError, err)
+	}
+	if len(proof.MerklePath) > maxMerklePathLength {
+		return nil, NewBTCAPIError(MerkleProofError, fmt.Errorf("merkle path length %d is too long", len(proof.MerklePath)))
+	}
+	if uint64(proof.TxIndex)>>uint(len(proof.MerklePath)) != 0 {
+		return nil, NewBTCAPIError(MerkleProofError, fmt.Errorf("tx index %d is out of the merkle tree", proof.TxIndex))
+	}
+	return &proof, nil
+}
+
+// Encode encodes the proof as base64 of its json
+func (proof *MerkleProof) Encode() (string, error) {
+	proofBytes, err := json.Marshal(proof)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(proofBytes), nil
+}
+
+// GetBlockHash returns hash of the block including the tx, in internal byte order
+func (proof *MerkleProof) GetBlockHash() (*common.Hash, error) {
+	blockHash, err := common.Hash{}.NewHashFromStr(proof.BlockHash)
+	if err != nil {
+		return nil, NewBTCAPIError(MerkleProofError, err)
+	}
+	return blockHash, nil
+}
+
+// Verify parses the tx of the proof and makes sure it is included in a block with the given merkle root
+func (proof *MerkleProof) Verify(merkleRoot common.Hash) (*Tx, error) {
+	tx, err := ParseTxHex(proof.RawTx)
+	if err != nil {
+		return nil, err
+	}
+	path := make([]common.Hash, 0, len(proof.MerklePath))
+	for _, siblingStr := range proof.MerklePath {
+		sibling, err := common.Hash{}.NewHashFromStr(siblingStr)
+		if err != nil {
+			return nil, NewBTCAPIError(MerkleProofError, err)
+		}
+		path = append(path, *sibling)
+	}
+	if !VerifyMerklePath(tx.TxHash(), merkleRoot, path, proof.TxIndex) {
+		return nil, NewBTCAPIError(MerkleProofError, errors.New("merkle path does not lead to the merkle root of the block"))
+	}
+	return tx, nil
+}
+
+// VerifyMerklePath hashes a tx id up along the path, the bits of the tx index tell
+// whether the sibling at each level is on the left or the right
+func VerifyMerklePath(txHash common.Hash, merkleRoot common.Hash, path []common.Hash, txIndex uint32) bool {
+	if len(path) > maxMerklePathLength || uint64(txIndex)>>uint(len(path)) != 0 {
+		return false
+	}
+	current := txHash
+	index := txIndex
+	var buf [common.HashSize * 2]byte
+	for _, sibling := range path {
+		if index&1 == 1 {
+			copy(buf[:common.HashSize], sibling[:])
+			copy(buf[common.HashSize:], current[:])
+		} else {
+			copy(buf[:common.HashSize], current[:])
+			copy(buf[common.HashSize:], sibling[:])
+		}
+		current = doubleHashH(buf[:])
+		index >>= 1
+	}
+	return current == merkleRoot
+}
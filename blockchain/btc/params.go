@@ -0,0 +1,109 @@
+package btc
+
+import (
+	"math/big"
+)
+
+var (
+	bigOne = big.NewInt(1)
+	// 2^224 - 1
+	mainPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne)
+	// 2^255 - 1
+	regressionPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne)
+)
+
+// Params defines a bitcoin network by the rules its headers and addresses follow
+type Params struct {
+	Name string
+
+	// Address encoding
+	PubKeyHashAddrID byte   // first byte of a P2PKH address
+	ScriptHashAddrID byte   // first byte of a P2SH address
+	Bech32HRPSegwit  string // human readable part of a segwit address
+
+	// Proof of work
+	PowLimit           *big.Int // highest allowed proof of work target
+	PowLimitBits       uint32   // PowLimit in compact form
+	TargetTimespan     int64    // seconds between two difficulty retargets
+	TargetTimePerBlock int64    // seconds
+	RetargetAdjustment int64    // max factor a retarget can change the difficulty by
+	// ReduceMinDifficulty networks allow blocks at minimum difficulty, so the
+	// bits of a header between two retargets are not checked
+	ReduceMinDifficulty bool
+	PoWNoRetargeting    bool
+}
+
+// BlocksPerRetarget returns number of blocks between two difficulty retargets
+func (params *Params) BlocksPerRetarget() uint64 {
+	return uint64(params.TargetTimespan / params.TargetTimePerBlock)
+}
+
+var MainNetParams = Params{
+	Name:               "mainnet",
+	PubKeyHashAddrID:   0x00,
+	ScriptHashAddrID:   0x05,
+	Bech32HRPSegwit:    "bc",
+	PowLimit:           mainPowLimit,
+	PowLimitBits:       0x1d00ffff,
+	TargetTimespan:     60 * 60 * 24 * 14, // 14 days
+	TargetTimePerBlock: BTC_BLOCK_INTERVAL,
+	RetargetAdjustment: 4,
+}
+
+var TestNet3Params = Params{
+	Name:                "testnet3",
+	PubKeyHashAddrID:    0x6f,
+	ScriptHashAddrID:    0xc4,
+	Bech32HRPSegwit:     "tb",
+	PowLimit:            mainPowLimit,
+	PowLimitBits:        0x1d00ffff,
+	TargetTimespan:      60 * 60 * 24 * 14, // 14 days
+	TargetTimePerBlock:  BTC_BLOCK_INTERVAL,
+	RetargetAdjustment:  4,
+	ReduceMinDifficulty: true,
+}
+
+var RegressionNetParams = Params{
+	Name:                "regtest",
+	PubKeyHashAddrID:    0x6f,
+	ScriptHashAddrID:    0xc4,
+	Bech32HRPSegwit:     "bcrt",
+	PowLimit:            regressionPowLimit,
+	PowLimitBits:        0x207fffff,
+	TargetTimespan:      60 * 60 * 24 * 14, // 14 days
+	TargetTimePerBlock:  BTC_BLOCK_INTERVAL,
+	RetargetAdjustment:  4,
+	ReduceMinDifficulty: true,
+	PoWNoRetargeting:    true,
+}
+
+// GetParamsByName returns params of a bitcoin network by its name
+func GetParamsByName(name string) (*Params, bool) {
+	switch name {
+	case MainNetParams.Name:
+		return &MainNetParams, true
+	case TestNet3Params.Name:
+		return &TestNet3Params, true
+	case RegressionNetParams.Name:
+		return &RegressionNetParams, true
+	}
+	return nil, false
+}
+
+// Checkpoint is a trusted header which the relayed header chain starts from,
+// it must be the first block of a retarget interval
+type Checkpoint struct {
+	Header string // serialized header, in hex
+	Height uint64
+}
+
+// genesis blocks are the checkpoints, a later retarget block can be used to relay fewer headers
+var MainNetCheckpoint = Checkpoint{
+	Header: "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c",
+	Height: 0,
+}
+
+var TestNet3Checkpoint = Checkpoint{
+	Header: "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4adae5494dffff001d1aa4ae18",
+	Height: 0,
+}
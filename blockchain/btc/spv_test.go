@@ -0,0 +1,347 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mainNetGenesisHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	mainNetGenesisHash   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	genesisMerkleRoot    = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	genesisCoinbaseTx    = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseBlockHeader(t *testing.T) {
+	header, err := ParseBlockHeaderHex(mainNetGenesisHeader)
+	assert.Nil(t, err)
+	assert.Equal(t, mainNetGenesisHash, header.BlockHash().String())
+	assert.Equal(t, genesisMerkleRoot, header.MerkleRoot.String())
+	assert.Equal(t, uint32(0x1d00ffff), header.Bits)
+	assert.Equal(t, mainNetGenesisHeader, header.Hex())
+	assert.Nil(t, header.CheckProofOfWork(&MainNetParams))
+
+	testNetHeader, err := ParseBlockHeaderHex(TestNet3Checkpoint.Header)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943", testNetHeader.BlockHash().String())
+	assert.Nil(t, testNetHeader.CheckProofOfWork(&TestNet3Params))
+
+	_, err = ParseBlockHeader(mustDecodeHex(t, mainNetGenesisHeader)[:79])
+	assert.NotNil(t, err)
+
+	// a different nonce makes the hash miss the target
+	header.Nonce++
+	assert.NotNil(t, header.CheckProofOfWork(&MainNetParams))
+}
+
+func TestCompactConversion(t *testing.T) {
+	assert.Equal(t, uint32(0x1d00ffff), BigToCompact(CompactToBig(0x1d00ffff)))
+	assert.Equal(t, uint32(0x1d00ffff), BigToCompact(MainNetParams.PowLimit))
+	assert.Equal(t, uint32(0x207fffff), BigToCompact(RegressionNetParams.PowLimit))
+	assert.Equal(t, uint32(0x1b0404cb), BigToCompact(CompactToBig(0x1b0404cb)))
+	assert.Equal(t, big.NewInt(0x12), CompactToBig(0x01120000))
+	// work of the min difficulty is 2^32 + 2^16 + 1 hashes
+	assert.Equal(t, big.NewInt(0x100010001), CalcWork(0x1d00ffff))
+}
+
+func TestCalcRetargetBits(t *testing.T) {
+	params := &MainNetParams
+	cases := []struct {
+		name     string
+		bits     uint32
+		timespan int64
+		expected uint32
+	}{
+		{"on target", 0x1d00ffff, params.TargetTimespan, 0x1d00ffff},
+		{"twice as fast", 0x1d00ffff, params.TargetTimespan / 2, 0x1c7fff80},
+		{"too fast is clamped", 0x1d00ffff, 1, 0x1c3fffc0},
+		{"too slow is capped at the pow limit", 0x1d00ffff, params.TargetTimespan * 10, 0x1d00ffff},
+		{"slower", 0x1c3fffc0, params.TargetTimespan * 2, 0x1c7fff80},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := CalcRetargetBits(params, tc.bits, 1000, uint32(1000+tc.timespan))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+	assert.Equal(t, uint32(0x207fffff), CalcRetargetBits(&RegressionNetParams, 0x207fffff, 1000, 1001))
+}
+
+func TestExtractAddress(t *testing.T) {
+	hash160 := mustDecodeHex(t, "010966776006953d5567439e5e39f86a0d273bee")
+	p2pkh := append(append([]byte{0x76, 0xa9, 0x14}, hash160...), 0x88, 0xac)
+	address, ok := ExtractAddress(p2pkh, &MainNetParams)
+	assert.True(t, ok)
+	assert.Equal(t, "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM", address)
+
+	scriptHash := mustDecodeHex(t, "f815b036d9bbbce5e9f2a00abd1bf3dc91e95510")
+	p2sh := append(append([]byte{0xa9, 0x14}, scriptHash...), 0x87)
+	address, ok = ExtractAddress(p2sh, &MainNetParams)
+	assert.True(t, ok)
+	assert.Equal(t, "3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC", address)
+
+	p2wpkh := append([]byte{0x00, 0x14}, mustDecodeHex(t, "751e76e8199196d454941c45d1b3a323f1433bd6")...)
+	address, ok = ExtractAddress(p2wpkh, &MainNetParams)
+	assert.True(t, ok)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", address)
+
+	p2wsh := append([]byte{0x00, 0x20}, mustDecodeHex(t, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262")...)
+	address, ok = ExtractAddress(p2wsh, &TestNet3Params)
+	assert.True(t, ok)
+	assert.Equal(t, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", address)
+
+	// pay to pubkey and op_return outputs have no address
+	_, ok = ExtractAddress([]byte{0x6a, 0x01, 0x01}, &MainNetParams)
+	assert.False(t, ok)
+}
+
+// buildTx serializes a tx with one input, adding a witness if it is given
+func buildTx(pkScripts [][]byte, values []uint64, witness []byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, int32(2))
+	if witness != nil {
+		buf.Write([]byte{witnessMarker, witnessFlag})
+	}
+	buf.WriteByte(1)
+	buf.Write(bytes.Repeat([]byte{0x11}, common.HashSize))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.WriteByte(byte(len(pkScripts)))
+	for i, pkScript := range pkScripts {
+		_ = binary.Write(&buf, binary.LittleEndian, values[i])
+		buf.WriteByte(byte(len(pkScript)))
+		buf.Write(pkScript)
+	}
+	if witness != nil {
+		buf.WriteByte(1)
+		buf.WriteByte(byte(len(witness)))
+		buf.Write(witness)
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+func TestParseTx(t *testing.T) {
+	coinbase, err := ParseTxHex(genesisCoinbaseTx)
+	assert.Nil(t, err)
+	assert.Equal(t, genesisMerkleRoot, coinbase.TxHash().String())
+	assert.Equal(t, 1, len(coinbase.TxOut))
+	assert.Equal(t, int64(5000000000), coinbase.TxOut[0].Value)
+	// the output pays to a public key, not to an address
+	assert.Equal(t, 0, len(coinbase.OutputsByAddress(&MainNetParams)))
+
+	p2wpkh := append([]byte{0x00, 0x14}, mustDecodeHex(t, "751e76e8199196d454941c45d1b3a323f1433bd6")...)
+	p2pkh := append(append([]byte{0x76, 0xa9, 0x14}, mustDecodeHex(t, "010966776006953d5567439e5e39f86a0d273bee")...), 0x88, 0xac)
+	pkScripts := [][]byte{p2wpkh, p2pkh, p2wpkh}
+	values := []uint64{1000, 2000, 3000}
+	legacyTx, err := ParseTx(buildTx(pkScripts, values, nil))
+	assert.Nil(t, err)
+	segwitTx, err := ParseTx(buildTx(pkScripts, values, []byte{0xaa, 0xbb}))
+	assert.Nil(t, err)
+	// the witness does not change the tx id
+	assert.Equal(t, legacyTx.TxHash(), segwitTx.TxHash())
+	assert.Equal(t, map[string]uint64{
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 4000,
+		"16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM":          2000,
+	}, segwitTx.OutputsByAddress(&MainNetParams))
+
+	// trailing bytes
+	_, err = ParseTx(append(buildTx(pkScripts, values, nil), 0))
+	assert.NotNil(t, err)
+	// truncated
+	raw := buildTx(pkScripts, values, nil)
+	_, err = ParseTx(raw[:len(raw)-1])
+	assert.NotNil(t, err)
+	// 64 bytes txs could be mistaken for inner nodes of merkle trees
+	raw = buildTx([][]byte{{0x51, 0x51, 0x51, 0x51}}, []uint64{1}, nil)
+	assert.Equal(t, 64, len(raw))
+	_, err = ParseTx(raw)
+	assert.NotNil(t, err)
+}
+
+func hashPair(left common.Hash, right common.Hash) common.Hash {
+	return doubleHashH(append(left[:], right[:]...))
+}
+
+func TestVerifyMerklePath(t *testing.T) {
+	txHashes := []common.Hash{
+		doubleHashH([]byte{0}), doubleHashH([]byte{1}), doubleHashH([]byte{2}),
+	}
+	// the last node of an odd level is paired with itself
+	left := hashPair(txHashes[0], txHashes[1])
+	right := hashPair(txHashes[2], txHashes[2])
+	root := hashPair(left, right)
+
+	assert.True(t, VerifyMerklePath(txHashes[0], root, []common.Hash{txHashes[1], right}, 0))
+	assert.True(t, VerifyMerklePath(txHashes[1], root, []common.Hash{txHashes[0], right}, 1))
+	assert.True(t, VerifyMerklePath(txHashes[2], root, []common.Hash{txHashes[2], left}, 2))
+
+	assert.False(t, VerifyMerklePath(txHashes[0], root, []common.Hash{txHashes[1], right}, 1))
+	assert.False(t, VerifyMerklePath(txHashes[0], root, []common.Hash{txHashes[1]}, 0))
+	assert.False(t, VerifyMerklePath(txHashes[0], root, []common.Hash{txHashes[1], right}, 4))
+}
+
+func TestMerkleProof(t *testing.T) {
+	proof := &MerkleProof{
+		BlockHash:  mainNetGenesisHash,
+		TxIndex:    0,
+		MerklePath: []string{},
+		RawTx:      genesisCoinbaseTx,
+	}
+	proofStr, err := proof.Encode()
+	assert.Nil(t, err)
+	parsedProof, err := ParseMerkleProof(proofStr)
+	assert.Nil(t, err)
+	assert.Equal(t, proof, parsedProof)
+
+	blockHash, err := parsedProof.GetBlockHash()
+	assert.Nil(t, err)
+	header, _ := ParseBlockHeaderHex(mainNetGenesisHeader)
+	assert.Equal(t, header.BlockHash(), *blockHash)
+
+	tx, err := parsedProof.Verify(header.MerkleRoot)
+	assert.Nil(t, err)
+	assert.Equal(t, header.MerkleRoot, tx.TxHash())
+
+	_, err = parsedProof.Verify(header.BlockHash())
+	assert.NotNil(t, err)
+
+	_, err = ParseMerkleProof("not a proof")
+	assert.NotNil(t, err)
+	proof.TxIndex = 1
+	proofStr, _ = proof.Encode()
+	_, err = ParseMerkleProof(proofStr)
+	assert.NotNil(t, err)
+}
+
+// mineHeader finds a nonce for a regtest header on top of prev
+func mineHeader(prev *BlockHeader, bits uint32, timestamp uint32, params *Params) *BlockHeader {
+	header := &BlockHeader{
+		Version:    4,
+		PrevBlock:  prev.BlockHash(),
+		MerkleRoot: doubleHashH([]byte{byte(timestamp)}),
+		Timestamp:  timestamp,
+		Bits:       bits,
+	}
+	for header.CheckProofOfWork(params) != nil {
+		header.Nonce++
+	}
+	return header
+}
+
+func TestConnectHeader(t *testing.T) {
+	params := RegressionNetParams
+	params.ReduceMinDifficulty = false
+	bits := params.PowLimitBits
+
+	start := &ChainHeader{
+		Header:    &BlockHeader{Version: 4, Timestamp: 1000, Bits: bits},
+		Height:    100,
+		ChainWork: big.NewInt(0),
+	}
+	chain := map[common.Hash]*ChainHeader{start.Header.BlockHash(): start}
+	getHeader := func(hash common.Hash) (*ChainHeader, error) {
+		return chain[hash], nil
+	}
+
+	tip := start
+	for i := uint32(1); i <= 3; i++ {
+		header := mineHeader(tip.Header, bits, 1000+i*600, &params)
+		chainHeader, err := ConnectHeader(&params, header, getHeader)
+		assert.Nil(t, err)
+		assert.Equal(t, tip.Height+1, chainHeader.Height)
+		assert.Equal(t, new(big.Int).Add(tip.ChainWork, CalcWork(bits)), chainHeader.ChainWork)
+		chain[header.BlockHash()] = chainHeader
+		tip = chainHeader
+	}
+
+	// fork from the start
+	fork := mineHeader(start.Header, bits, 5000, &params)
+	forkHeader, err := ConnectHeader(&params, fork, getHeader)
+	assert.Nil(t, err)
+	chain[fork.BlockHash()] = forkHeader
+
+	// unknown parent
+	orphan := mineHeader(&BlockHeader{Nonce: 1}, bits, 5000, &params)
+	_, err = ConnectHeader(&params, orphan, getHeader)
+	assert.NotNil(t, err)
+
+	// bits changing between retargets
+	lowerBits := BigToCompact(new(big.Int).Rsh(params.PowLimit, 1))
+	harder := mineHeader(tip.Header, lowerBits, 5000, &params)
+	_, err = ConnectHeader(&params, harder, getHeader)
+	assert.NotNil(t, err)
+	params.ReduceMinDifficulty = true
+	_, err = ConnectHeader(&params, harder, getHeader)
+	assert.Nil(t, err)
+
+	// not enough work
+	unmined := &BlockHeader{Version: 4, PrevBlock: tip.Header.BlockHash(), Bits: bits}
+	for unmined.CheckProofOfWork(&params) == nil {
+		unmined.Nonce++
+	}
+	_, err = ConnectHeader(&params, unmined, getHeader)
+	assert.NotNil(t, err)
+
+	inChain, err := IsInChain(tip, start.Header.BlockHash(), start.Height, 10, getHeader)
+	assert.Nil(t, err)
+	assert.True(t, inChain)
+	inChain, err = IsInChain(tip, tip.Header.BlockHash(), tip.Height, 0, getHeader)
+	assert.Nil(t, err)
+	assert.True(t, inChain)
+	inChain, err = IsInChain(tip, fork.BlockHash(), forkHeader.Height, 10, getHeader)
+	assert.Nil(t, err)
+	assert.False(t, inChain)
+	inChain, err = IsInChain(tip, start.Header.BlockHash(), start.Height, 2, getHeader)
+	assert.Nil(t, err)
+	assert.False(t, inChain)
+}
+
+func TestConnectHeaderRetarget(t *testing.T) {
+	params := RegressionNetParams
+	params.PoWNoRetargeting = false
+	params.TargetTimespan = 4 * params.TargetTimePerBlock // retarget every 4 blocks
+	bits := params.PowLimitBits
+
+	start := &ChainHeader{
+		Header:    &BlockHeader{Version: 4, Timestamp: 1000, Bits: bits},
+		Height:    4,
+		ChainWork: big.NewInt(0),
+	}
+	chain := map[common.Hash]*ChainHeader{start.Header.BlockHash(): start}
+	getHeader := func(hash common.Hash) (*ChainHeader, error) {
+		return chain[hash], nil
+	}
+	// the interval takes half of the target timespan
+	tip := start
+	for i := uint32(1); i <= 3; i++ {
+		header := mineHeader(tip.Header, bits, 1000+i*100, &params)
+		chainHeader, err := ConnectHeader(&params, header, getHeader)
+		assert.Nil(t, err)
+		chain[header.BlockHash()] = chainHeader
+		tip = chainHeader
+	}
+	requiredBits := CalcRetargetBits(&params, bits, 1000, 1300)
+	assert.NotEqual(t, bits, requiredBits)
+
+	_, err := ConnectHeader(&params, mineHeader(tip.Header, bits, 1400, &params), getHeader)
+	assert.NotNil(t, err)
+	retarget, err := ConnectHeader(&params, mineHeader(tip.Header, requiredBits, 1400, &params), getHeader)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(8), retarget.Height)
+}
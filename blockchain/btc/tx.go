@@ -0,0 +1,298 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+const (
+	maxTxSize             = 4000000 // max block weight, no tx can be larger
+	witnessMarker         = 0x00
+	witnessFlag           = 0x01
+	hash160Size           = 20
+	witnessScriptHashSize = 32
+)
+
+// TxOut is an output of a bitcoin tx
+type TxOut struct {
+	Value    int64 // satoshi
+	PkScript []byte
+}
+
+// Tx is a bitcoin tx, only what is needed to verify where its outputs go is kept
+type Tx struct {
+	Version  int32
+	TxOut    []*TxOut
+	LockTime uint32
+	hash     common.Hash
+}
+
+// TxHash returns the tx id, which does not commit to witness data
+func (tx *Tx) TxHash() common.Hash {
+	return tx.hash
+}
+
+type txReader struct {
+	*bytes.Reader
+}
+
+func (r txReader) readUint32() (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func (r txReader) readUint64() (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func (r txReader) readVarInt() (uint64, error) {
+	discriminant, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var b [8]byte
+	switch discriminant {
+	case 0xff:
+		if _, err := io.ReadFull(r, b[:8]); err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(b[:]), nil
+	case 0xfe:
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint32(b[:4])), nil
+	case 0xfd:
+		if _, err := io.ReadFull(r, b[:2]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint16(b[:2])), nil
+	}
+	return uint64(discriminant), nil
+}
+
+func (r txReader) readVarBytes() ([]byte, error) {
+	size, err := r.readVarInt()
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func writeVarInt(buf *bytes.Buffer, n uint64) {
+	var b [8]byte
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xfd)
+		binary.LittleEndian.PutUint16(b[:2], uint16(n))
+		buf.Write(b[:2])
+	case n <= math.MaxUint32:
+		buf.WriteByte(0xfe)
+		binary.LittleEndian.PutUint32(b[:4], uint32(n))
+		buf.Write(b[:4])
+	default:
+		buf.WriteByte(0xff)
+		binary.LittleEndian.PutUint64(b[:], n)
+		buf.Write(b[:])
+	}
+}
+
+// ParseTx parses a serialized tx, with or without witness data,
+// and computes its id from the serialization without witness data
+func ParseTx(raw []byte) (*Tx, error) {
+	tx, err := parseTx(raw)
+	if err != nil {
+		return nil, NewBTCAPIError(ParseTransactionError, err)
+	}
+	return tx, nil
+}
+
+func parseTx(raw []byte) (*Tx, error) {
+	if len(raw) > maxTxSize {
+		return nil, fmt.Errorf("tx size %d is too large", len(raw))
+	}
+	r := txReader{bytes.NewReader(raw)}
+	// serialization without witness data, which the tx id is hashed from
+	var stripped bytes.Buffer
+
+	version, err := r.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	stripped.Write(raw[:4])
+
+	txInCount, err := r.readVarInt()
+	if err != nil {
+		return nil, err
+	}
+	hasWitness := false
+	if txInCount == witnessMarker {
+		flag, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if flag != witnessFlag {
+			return nil, fmt.Errorf("invalid witness flag %d", flag)
+		}
+		hasWitness = true
+		txInCount, err = r.readVarInt()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if txInCount == 0 {
+		return nil, errors.New("tx has no input")
+	}
+	// each input takes at least 41 bytes
+	if txInCount > uint64(r.Len()/41) {
+		return nil, fmt.Errorf("too many inputs %d", txInCount)
+	}
+	writeVarInt(&stripped, txInCount)
+	for i := uint64(0); i < txInCount; i++ {
+		prevOut := make([]byte, common.HashSize+4)
+		if _, err := io.ReadFull(r, prevOut); err != nil {
+			return nil, err
+		}
+		sigScript, err := r.readVarBytes()
+		if err != nil {
+			return nil, err
+		}
+		sequence, err := r.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		stripped.Write(prevOut)
+		writeVarInt(&stripped, uint64(len(sigScript)))
+		stripped.Write(sigScript)
+		_ = binary.Write(&stripped, binary.LittleEndian, sequence)
+	}
+
+	txOutCount, err := r.readVarInt()
+	if err != nil {
+		return nil, err
+	}
+	// each output takes at least 9 bytes
+	if txOutCount > uint64(r.Len()/9) {
+		return nil, fmt.Errorf("too many outputs %d", txOutCount)
+	}
+	writeVarInt(&stripped, txOutCount)
+	txOuts := make([]*TxOut, 0, txOutCount)
+	for i := uint64(0); i < txOutCount; i++ {
+		value, err := r.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := r.readVarBytes()
+		if err != nil {
+			return nil, err
+		}
+		if value > math.MaxInt64 {
+			return nil, fmt.Errorf("output value %d is out of range", value)
+		}
+		_ = binary.Write(&stripped, binary.LittleEndian, value)
+		writeVarInt(&stripped, uint64(len(pkScript)))
+		stripped.Write(pkScript)
+		txOuts = append(txOuts, &TxOut{Value: int64(value), PkScript: pkScript})
+	}
+
+	if hasWitness {
+		for i := uint64(0); i < txInCount; i++ {
+			itemCount, err := r.readVarInt()
+			if err != nil {
+				return nil, err
+			}
+			for j := uint64(0); j < itemCount; j++ {
+				if _, err := r.readVarBytes(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	lockTime, err := r.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	_ = binary.Write(&stripped, binary.LittleEndian, lockTime)
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after tx", r.Len())
+	}
+	// a 64 bytes tx could be passed off as an inner node of a merkle tree
+	if stripped.Len() == 64 {
+		return nil, errors.New("tx size without witness data must not be 64 bytes")
+	}
+	return &Tx{
+		Version:  int32(version),
+		TxOut:    txOuts,
+		LockTime: lockTime,
+		hash:     doubleHashH(stripped.Bytes()),
+	}, nil
+}
+
+// ParseTxHex parses a tx serialized in hex
+func ParseTxHex(txStr string) (*Tx, error) {
+	raw, err := hex.DecodeString(txStr)
+	if err != nil {
+		return nil, NewBTCAPIError(ParseTransactionError, err)
+	}
+	return ParseTx(raw)
+}
+
+// ExtractAddress returns the address an output script pays to, outputs of other
+// script types than P2PKH, P2SH, P2WPKH and P2WSH return false
+func ExtractAddress(pkScript []byte, params *Params) (string, bool) {
+	switch {
+	// OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+	case len(pkScript) == 25 && pkScript[0] == 0x76 && pkScript[1] == 0xa9 && pkScript[2] == hash160Size &&
+		pkScript[23] == 0x88 && pkScript[24] == 0xac:
+		return EncodeBase58Address(pkScript[3:23], params.PubKeyHashAddrID), true
+	// OP_HASH160 <20 bytes> OP_EQUAL
+	case len(pkScript) == 23 && pkScript[0] == 0xa9 && pkScript[1] == hash160Size && pkScript[22] == 0x87:
+		return EncodeBase58Address(pkScript[2:22], params.ScriptHashAddrID), true
+	// OP_0 <20 bytes> or OP_0 <32 bytes>
+	case len(pkScript) == 2+hash160Size && pkScript[0] == 0x00 && pkScript[1] == hash160Size,
+		len(pkScript) == 2+witnessScriptHashSize && pkScript[0] == 0x00 && pkScript[1] == witnessScriptHashSize:
+		address, err := EncodeSegwitAddress(params.Bech32HRPSegwit, 0, pkScript[2:])
+		if err != nil {
+			return "", false
+		}
+		return address, true
+	}
+	return "", false
+}
+
+// OutputsByAddress sums up amounts (in satoshi) the tx pays to each address
+func (tx *Tx) OutputsByAddress(params *Params) map[string]uint64 {
+	outputs := make(map[string]uint64)
+	for _, txOut := range tx.TxOut {
+		address, ok := ExtractAddress(txOut.PkScript, params)
+		if !ok {
+			continue
+		}
+		outputs[address] += uint64(txOut.Value)
+	}
+	return outputs
+}
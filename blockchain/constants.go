@@ -68,6 +68,15 @@ const (
 	MainETHContractAddressStr               = "0x0261DB5AfF8E5eC99fBc8FBBA5D4B9f8EcD44ec7"                                                              // v2-main - mainnet, branch master-temp-B-deploy, support erc20 with decimals > 18
 	MainnetIncognitoDAOAddress              = "12S32fSyF4h8VxFHt4HfHvU1m9KHvBQsab5zp4TpQctmMdWuveXFH9KYWNemo7DRKvaBEvMgqm4XAuq1a1R4cNk2kfUfvXR3DdxCho3" // community fund
 	MainnetCentralizedWebsitePaymentAddress = "12Rvjw6J3FWY3YZ1eDZ5uTy6DTPjFeLhCK7SXgppjivg9ShX2RRq3s8pdoapnH8AMoqvUSqZm1Gqzw7rrKsNzRJwSK2kWbWf1ogy885"
+
+	// portal
+	MainnetPortalTimeOutWaitingPortingRequest   = 2160 // ~24 hours with 40s beacon blocks
+	MainnetPortalTimeOutCustodianReturnPubToken = 2160
+	MainnetPortalMinPercentLockedCollateral     = 150
+
+	// pde
+	MainnetPDETradingFeeBPS               = 30 // 0.3%
+	MainnetPDEProtocolFeeActivationHeight = 600000
 	// ------------- end Mainnet --------------------------------------
 )
 
@@ -108,6 +117,15 @@ const (
 	TestnetETHContractAddressStr            = "0x6e8CDB333ba1573Fffe195A545F3031Cff9Da008"
 	TestnetIncognitoDAOAddress              = "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci" // community fund
 	TestnetCentralizedWebsitePaymentAddress = "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci"
+
+	// portal
+	TestnetPortalTimeOutWaitingPortingRequest   = 360 // ~1 hour with 10s beacon blocks
+	TestnetPortalTimeOutCustodianReturnPubToken = 360
+	TestnetPortalMinPercentLockedCollateral     = 150
+
+	// pde
+	TestnetPDETradingFeeBPS               = 30 // 0.3%
+	TestnetPDEProtocolFeeActivationHeight = 800000
 )
 
 // VARIABLE for testnet
@@ -183,10 +201,11 @@ func init() {
 // -------------- FOR INSTRUCTION --------------
 // Action for instruction
 const (
-	SetAction     = "set"
-	SwapAction    = "swap"
-	RandomAction  = "random"
-	StakeAction   = "stake"
-	AssignAction  = "assign"
-	StopAutoStake = "stopautostake"
+	SetAction        = "set"
+	SwapAction       = "swap"
+	RandomAction     = "random"
+	StakeAction      = "stake"
+	AssignAction     = "assign"
+	StopAutoStake    = "stopautostake"
+	DoubleSignAction = "doublesign"
 )
@@ -164,6 +164,18 @@ const (
 	NotEnoughRewardError
 	InitPDETradeResponseTransactionError
 	ProcessPDEInstructionError
+	ProcessStatefulInstructionError
+	InitPortalResponseTransactionError
+	RegisterStatefulModuleError
+	BackupStatefulStateError
+	RestoreStatefulStateError
+	ExportStateSnapshotError
+	ImportStateSnapshotError
+	BlockPrunedError
+	PruneBlockError
+	StoreTxHistoryError
+	GetTxHistoryError
+	DoubleSignEvidenceError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -313,6 +325,18 @@ var ErrCodeMessage = map[int]struct {
 	NotEnoughRewardError:                              {-1140, "Not enough reward Error"},
 	InitPDETradeResponseTransactionError:              {-1141, "Init PDE trade response tx Error"},
 	ProcessPDEInstructionError:                        {-1142, "Process PDE instruction Error"},
+	ProcessStatefulInstructionError:                   {-1143, "Process stateful instruction Error"},
+	InitPortalResponseTransactionError:                {-1144, "Init Portal response tx Error"},
+	RegisterStatefulModuleError:                       {-1145, "Register stateful module Error"},
+	BackupStatefulStateError:                          {-1146, "Backup stateful state Error"},
+	RestoreStatefulStateError:                         {-1147, "Restore stateful state Error"},
+	ExportStateSnapshotError:                          {-1148, "Export state snapshot Error"},
+	ImportStateSnapshotError:                          {-1149, "Import state snapshot Error"},
+	BlockPrunedError:                                  {-1150, "Block is pruned Error"},
+	PruneBlockError:                                   {-1151, "Prune block Error"},
+	StoreTxHistoryError:                               {-1152, "Store tx history Error"},
+	GetTxHistoryError:                                 {-1153, "Get tx history Error"},
+	DoubleSignEvidenceError:                           {-1154, "Double sign evidence Error"},
 }
 
 type BlockChainError struct {
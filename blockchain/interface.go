@@ -110,6 +110,8 @@ type ChainInterface interface {
 	ValidateBlockSignatures(block common.BlockInterface, committee []incognitokey.CommitteePublicKey) error
 	ValidatePreSignBlock(block common.BlockInterface) error
 	GetShardID() int
+	// AddDoubleSignEvidence - keep evidence of a validator signing conflicting blocks until beacon punishes it
+	AddDoubleSignEvidence(evidence *DoubleSignEvidence) error
 }
 
 type BestStateInterface interface {
@@ -3,9 +3,24 @@ package blockchain
 import (
 	"time"
 
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
 	"github.com/incognitochain/incognito-chain/common"
 )
 
+type PortalParams struct {
+	TimeOutWaitingPortingRequest   uint64         // number of beacon blocks a porting request waits for public tokens
+	TimeOutCustodianReturnPubToken uint64         // number of beacon blocks custodians have to return public tokens to redeemers
+	MinPercentLockedCollateral     uint64         // collateral (in PRV) locked for a porting request, in percent of the ported value
+	BTCParams                      *btc.Params    // bitcoin network which headers are relayed from
+	BTCCheckpoint                  btc.Checkpoint // trusted header the relayed btc header chain starts from
+}
+
+type PDEParams struct {
+	TradingFeeBPS               uint64            // protocol fee taken from every pool trade and paid to liquidity providers, in basis points
+	TradingFeeBPSByPairs        map[string]uint64 // per pool pair overrides of TradingFeeBPS, keyed by "<token1ID>-<token2ID>" with sorted token ids
+	ProtocolFeeActivationHeight uint64            // first beacon height taking the protocol fee, blocks before it are built and replayed without it
+}
+
 type SlashLevel struct {
 	MinRange        uint8
 	PunishedEpoches uint8
@@ -45,6 +60,8 @@ type Params struct {
 	ChainVersion                     string
 	AssignOffset                     int
 	BeaconHeightBreakPointBurnAddr   uint64
+	PortalParams                     PortalParams
+	PDEParams                        PDEParams
 }
 
 type GenesisParams struct {
@@ -108,6 +125,18 @@ func init() {
 		CheckForce:                     false,
 		ChainVersion:                   "version-chain-test.json",
 		BeaconHeightBreakPointBurnAddr: 250000,
+		PortalParams: PortalParams{
+			TimeOutWaitingPortingRequest:   TestnetPortalTimeOutWaitingPortingRequest,
+			TimeOutCustodianReturnPubToken: TestnetPortalTimeOutCustodianReturnPubToken,
+			MinPercentLockedCollateral:     TestnetPortalMinPercentLockedCollateral,
+			BTCParams:                      &btc.TestNet3Params,
+			BTCCheckpoint:                  btc.TestNet3Checkpoint,
+		},
+		PDEParams: PDEParams{
+			TradingFeeBPS:               TestnetPDETradingFeeBPS,
+			TradingFeeBPSByPairs:        map[string]uint64{},
+			ProtocolFeeActivationHeight: TestnetPDEProtocolFeeActivationHeight,
+		},
 	}
 	// END TESTNET
 	// FOR MAINNET
@@ -153,5 +182,17 @@ func init() {
 		CheckForce:                     false,
 		ChainVersion:                   "version-chain-main.json",
 		BeaconHeightBreakPointBurnAddr: 150500,
+		PortalParams: PortalParams{
+			TimeOutWaitingPortingRequest:   MainnetPortalTimeOutWaitingPortingRequest,
+			TimeOutCustodianReturnPubToken: MainnetPortalTimeOutCustodianReturnPubToken,
+			MinPercentLockedCollateral:     MainnetPortalMinPercentLockedCollateral,
+			BTCParams:                      &btc.MainNetParams,
+			BTCCheckpoint:                  btc.MainNetCheckpoint,
+		},
+		PDEParams: PDEParams{
+			TradingFeeBPS:               MainnetPDETradingFeeBPS,
+			TradingFeeBPSByPairs:        map[string]uint64{},
+			ProtocolFeeActivationHeight: MainnetPDEProtocolFeeActivationHeight,
+		},
 	}
 }
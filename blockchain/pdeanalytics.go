@@ -0,0 +1,400 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultPDEPairHistoryLimit = 100
+	MaxPDEPairHistoryLimit     = 1000
+	// MaxPDEPairHistoryHeights bounds the number of beacon heights scanned by one history query
+	MaxPDEPairHistoryHeights = 100000
+)
+
+// PDEPairCandle aggregates the history of a pool pair over a range of beacon heights,
+// prices are the prices of token 1 in token 2 given by the reserves of the pool pair
+type PDEPairCandle struct {
+	FromHeight      uint64
+	ToHeight        uint64
+	Open            float64
+	High            float64
+	Low             float64
+	Close           float64
+	Token1Volume    uint64
+	Token2Volume    uint64
+	Token1FeeAmount uint64
+	Token2FeeAmount uint64
+	TradeCount      uint64
+	Token1PoolValue uint64 // reserves at ToHeight
+	Token2PoolValue uint64
+	TotalShares     uint64
+}
+
+// pdeAnalyticsState collects the activity of pool pairs while instructions of a beacon block are processed
+type pdeAnalyticsState struct {
+	statsByPairs map[string]*lvdb.PDEPairStats // keyed by pool pair id
+}
+
+func (state *pdeAnalyticsState) getPairStats(token1IDStr string, token2IDStr string) *lvdb.PDEPairStats {
+	pairID := BuildPDEPoolPairID(token1IDStr, token2IDStr)
+	stats, found := state.statsByPairs[pairID]
+	if !found {
+		tokenIDStrs := []string{token1IDStr, token2IDStr}
+		sort.Strings(tokenIDStrs)
+		stats = &lvdb.PDEPairStats{
+			Token1IDStr: tokenIDStrs[0],
+			Token2IDStr: tokenIDStrs[1],
+		}
+		state.statsByPairs[pairID] = stats
+	}
+	return stats
+}
+
+// addTrade records an amount of token bought from a pool pair and the protocol fee paid for it
+func (state *pdeAnalyticsState) addTrade(
+	token1IDStr string,
+	token2IDStr string,
+	tokenIDToBuyStr string,
+	receiveAmt uint64,
+	feeTokenIDStr string,
+	protocolFee uint64,
+) {
+	stats := state.getPairStats(token1IDStr, token2IDStr)
+	if stats.Token1IDStr == tokenIDToBuyStr {
+		stats.Token1Volume += receiveAmt
+	} else {
+		stats.Token2Volume += receiveAmt
+	}
+	if stats.Token1IDStr == feeTokenIDStr {
+		stats.Token1FeeAmount += protocolFee
+	} else {
+		stats.Token2FeeAmount += protocolFee
+	}
+	stats.TradeCount++
+}
+
+// pdeAnalyticsModule indexes reserves, volumes and fees of pool pairs for analytics RPCs,
+// it does not build any instruction and only follows the ones of the pde module
+type pdeAnalyticsModule struct{}
+
+func (module *pdeAnalyticsModule) Name() string {
+	return "pdeanalytics"
+}
+
+func (module *pdeAnalyticsModule) Priority() int {
+	return pdeAnalyticsModulePriority
+}
+
+func (module *pdeAnalyticsModule) ActionMetaTypes() []int {
+	return []int{}
+}
+
+func (module *pdeAnalyticsModule) InstructionMetaTypes() []int {
+	return []int{metadata.PDETradeRequestMeta, metadata.PDEMultiHopTradeRequestMeta, metadata.PDELimitOrderRequestMeta}
+}
+
+func (module *pdeAnalyticsModule) InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error) {
+	return &pdeAnalyticsState{
+		statsByPairs: make(map[string]*lvdb.PDEPairStats),
+	}, nil
+}
+
+func (module *pdeAnalyticsModule) BuildInstructions(
+	blockchain *BlockChain,
+	beaconHeight uint64,
+	state interface{},
+	actionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	return [][]string{}, nil
+}
+
+func (module *pdeAnalyticsModule) ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error {
+	analyticsState, ok := state.(*pdeAnalyticsState)
+	if !ok {
+		return errors.New("invalid state of pde analytics module")
+	}
+	if len(inst) != 4 {
+		return nil // skip the instruction
+	}
+	switch inst[0] {
+	case strconv.Itoa(metadata.PDETradeRequestMeta):
+		if inst[2] != common.PDETradeAcceptedChainStatus {
+			return nil
+		}
+		var tradeAcceptedContent metadata.PDETradeAcceptedContent
+		err := json.Unmarshal([]byte(inst[3]), &tradeAcceptedContent)
+		if err != nil {
+			Logger.log.Errorf("WARNING: an error occured while unmarshaling PDETradeAcceptedContent: %+v", err)
+			return nil
+		}
+		analyticsState.addTrade(
+			tradeAcceptedContent.Token1IDStr, tradeAcceptedContent.Token2IDStr,
+			tradeAcceptedContent.TokenIDToBuyStr, tradeAcceptedContent.ReceiveAmount,
+			pdeSellingTokenIDStr(tradeAcceptedContent.Token1IDStr, tradeAcceptedContent.Token2IDStr, tradeAcceptedContent.Token1PoolValueOperation),
+			tradeAcceptedContent.ProtocolFee,
+		)
+
+	case strconv.Itoa(metadata.PDEMultiHopTradeRequestMeta):
+		if inst[2] != common.PDETradeAcceptedChainStatus {
+			return nil
+		}
+		var multiHopTradeAcceptedContent metadata.PDEMultiHopTradeAcceptedContent
+		err := json.Unmarshal([]byte(inst[3]), &multiHopTradeAcceptedContent)
+		if err != nil {
+			Logger.log.Errorf("WARNING: an error occured while unmarshaling PDEMultiHopTradeAcceptedContent: %+v", err)
+			return nil
+		}
+		for _, hop := range multiHopTradeAcceptedContent.Hops {
+			analyticsState.addTrade(
+				hop.Token1IDStr, hop.Token2IDStr,
+				hop.TokenIDToBuyStr, hop.ReceiveAmount,
+				hop.TokenIDToSellStr, hop.ProtocolFee,
+			)
+		}
+
+	case strconv.Itoa(metadata.PDELimitOrderRequestMeta):
+		if inst[2] != common.PDELimitOrderFilledChainStatus {
+			return nil
+		}
+		var filledContent metadata.PDELimitOrderFilledContent
+		err := json.Unmarshal([]byte(inst[3]), &filledContent)
+		if err != nil {
+			Logger.log.Errorf("WARNING: an error occured while unmarshaling PDELimitOrderFilledContent: %+v", err)
+			return nil
+		}
+		analyticsState.addTrade(
+			filledContent.Token1IDStr, filledContent.Token2IDStr,
+			filledContent.TokenIDToBuyStr, filledContent.ReceiveAmount,
+			pdeSellingTokenIDStr(filledContent.Token1IDStr, filledContent.Token2IDStr, filledContent.Token1PoolValueOperation),
+			filledContent.ProtocolFee,
+		)
+	}
+	return nil
+}
+
+// StoreState stores stats of pool pairs traded in the block or whose reserves were changed by it,
+// reserves and shares are read from the pde state that is stored before by the pde module
+func (module *pdeAnalyticsModule) StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error {
+	analyticsState, ok := state.(*pdeAnalyticsState)
+	if !ok {
+		return errors.New("invalid state of pde analytics module")
+	}
+	pdePoolPairs, err := getPDEPoolPair(db, beaconHeight)
+	if err != nil {
+		return err
+	}
+	prevPDEPoolPairs, err := getPDEPoolPair(db, beaconHeight-1)
+	if err != nil {
+		return err
+	}
+	prevPoolPairsByIDs := make(map[string]*lvdb.PDEPoolForPair)
+	for _, poolPair := range prevPDEPoolPairs {
+		prevPoolPairsByIDs[BuildPDEPoolPairID(poolPair.Token1IDStr, poolPair.Token2IDStr)] = poolPair
+	}
+	var pdeShares map[string]uint64
+	for _, poolPair := range pdePoolPairs {
+		pairID := BuildPDEPoolPairID(poolPair.Token1IDStr, poolPair.Token2IDStr)
+		_, isTraded := analyticsState.statsByPairs[pairID]
+		prevPoolPair, found := prevPoolPairsByIDs[pairID]
+		isChanged := !found ||
+			prevPoolPair.Token1PoolValue != poolPair.Token1PoolValue ||
+			prevPoolPair.Token2PoolValue != poolPair.Token2PoolValue
+		if !isTraded && !isChanged {
+			continue
+		}
+		if pdeShares == nil {
+			pdeShares, err = getPDEShares(db, beaconHeight)
+			if err != nil {
+				return err
+			}
+		}
+		stats := analyticsState.getPairStats(poolPair.Token1IDStr, poolPair.Token2IDStr)
+		stats.Token1PoolValue, stats.Token2PoolValue = poolPair.Token1PoolValue, poolPair.Token2PoolValue
+		if stats.Token1IDStr != poolPair.Token1IDStr {
+			stats.Token1PoolValue, stats.Token2PoolValue = poolPair.Token2PoolValue, poolPair.Token1PoolValue
+		}
+		stats.TotalShares = getPDETotalSharesForPair(beaconHeight, stats.Token1IDStr, stats.Token2IDStr, pdeShares)
+		statsBytes, err := json.Marshal(stats)
+		if err != nil {
+			return err
+		}
+		err = db.Put(lvdb.BuildPDEPairStatsKey(beaconHeight, stats.Token1IDStr, stats.Token2IDStr), statsBytes)
+		if err != nil {
+			return database.NewDatabaseError(database.StorePDEPairStatsError, errors.Wrap(err, "db.lvdb.put"))
+		}
+	}
+	return nil
+}
+
+// BackupState does nothing since stats are only stored at the height of their block
+func (module *pdeAnalyticsModule) BackupState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
+
+// RestoreState removes stats stored at the height of the reverted beacon block
+func (module *pdeAnalyticsModule) RestoreState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return deleteRecordsByPrefixes(db, block.Header.Height, [][]byte{lvdb.PDEPairStatsPrefix})
+}
+
+func getPDETotalSharesForPair(
+	beaconHeight uint64,
+	token1IDStr string,
+	token2IDStr string,
+	pdeShares map[string]uint64,
+) uint64 {
+	sharesForPairPrefix := string(lvdb.BuildPDESharesKeyV2(beaconHeight, token1IDStr, token2IDStr, ""))
+	totalShares := uint64(0)
+	for shareKey, shareAmt := range pdeShares {
+		if strings.HasPrefix(shareKey, sharesForPairPrefix) {
+			totalShares += shareAmt
+		}
+	}
+	return totalShares
+}
+
+func computePDEPairPrice(token1PoolValue uint64, token2PoolValue uint64) float64 {
+	if token1PoolValue == 0 {
+		return 0
+	}
+	return float64(token2PoolValue) / float64(token1PoolValue)
+}
+
+func getPDEPairStats(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	token1IDStr string,
+	token2IDStr string,
+) (*lvdb.PDEPairStats, error) {
+	statsKey := lvdb.BuildPDEPairStatsKey(beaconHeight, token1IDStr, token2IDStr)
+	hasStats, err := db.HasValue(statsKey)
+	if err != nil || !hasStats {
+		return nil, err
+	}
+	statsBytes, err := db.Get(statsKey)
+	if err != nil {
+		return nil, err
+	}
+	var stats lvdb.PDEPairStats
+	err = json.Unmarshal(statsBytes, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetPDEPairHistory returns candles of interval beacon heights for a pool pair from fromHeight to toHeight,
+// at most limit candles are returned along with the height the next page starts from (0 if there is none)
+func GetPDEPairHistory(
+	db database.DatabaseInterface,
+	token1IDStr string,
+	token2IDStr string,
+	fromHeight uint64,
+	toHeight uint64,
+	interval uint64,
+	limit int,
+) ([]*PDEPairCandle, uint64, error) {
+	if fromHeight == 0 || fromHeight > toHeight {
+		return nil, 0, errors.Errorf("invalid range of beacon heights from %d to %d", fromHeight, toHeight)
+	}
+	if interval == 0 {
+		interval = 1
+	}
+	if interval > MaxPDEPairHistoryHeights {
+		return nil, 0, errors.Errorf("interval should not be larger than %d beacon heights", MaxPDEPairHistoryHeights)
+	}
+	if limit <= 0 {
+		limit = DefaultPDEPairHistoryLimit
+	}
+	if limit > MaxPDEPairHistoryLimit {
+		limit = MaxPDEPairHistoryLimit
+	}
+	if uint64(limit)*interval > MaxPDEPairHistoryHeights {
+		limit = int(MaxPDEPairHistoryHeights / interval)
+	}
+	tokenIDStrs := []string{token1IDStr, token2IDStr}
+	sort.Strings(tokenIDStrs)
+
+	// reserves and shares before the range are read from the pde state
+	current := lvdb.PDEPairStats{Token1IDStr: tokenIDStrs[0], Token2IDStr: tokenIDStrs[1]}
+	poolPairBytes, err := db.GetPDEPoolForPair(fromHeight-1, tokenIDStrs[0], tokenIDStrs[1])
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(poolPairBytes) > 0 {
+		var poolPair lvdb.PDEPoolForPair
+		err = json.Unmarshal(poolPairBytes, &poolPair)
+		if err != nil {
+			return nil, 0, err
+		}
+		current.Token1PoolValue, current.Token2PoolValue = poolPair.Token1PoolValue, poolPair.Token2PoolValue
+		if poolPair.Token1IDStr != current.Token1IDStr {
+			current.Token1PoolValue, current.Token2PoolValue = poolPair.Token2PoolValue, poolPair.Token1PoolValue
+		}
+		pdeShares, err := getPDEShares(db, fromHeight-1)
+		if err != nil {
+			return nil, 0, err
+		}
+		current.TotalShares = getPDETotalSharesForPair(fromHeight-1, current.Token1IDStr, current.Token2IDStr, pdeShares)
+	}
+
+	candles := []*PDEPairCandle{}
+	candleFromHeight := fromHeight
+	for candleFromHeight <= toHeight && len(candles) < limit {
+		candleToHeight := candleFromHeight + interval - 1
+		if candleToHeight > toHeight {
+			candleToHeight = toHeight
+		}
+		price := computePDEPairPrice(current.Token1PoolValue, current.Token2PoolValue)
+		candle := &PDEPairCandle{
+			FromHeight: candleFromHeight,
+			ToHeight:   candleToHeight,
+			Open:       price,
+			High:       price,
+			Low:        price,
+		}
+		for height := candleFromHeight; height <= candleToHeight; height++ {
+			stats, err := getPDEPairStats(db, height, current.Token1IDStr, current.Token2IDStr)
+			if err != nil {
+				return nil, 0, err
+			}
+			if stats == nil {
+				continue
+			}
+			current.Token1PoolValue, current.Token2PoolValue = stats.Token1PoolValue, stats.Token2PoolValue
+			current.TotalShares = stats.TotalShares
+			price = computePDEPairPrice(current.Token1PoolValue, current.Token2PoolValue)
+			if price > candle.High {
+				candle.High = price
+			}
+			if price < candle.Low || candle.Low == 0 {
+				candle.Low = price
+			}
+			candle.Token1Volume += stats.Token1Volume
+			candle.Token2Volume += stats.Token2Volume
+			candle.Token1FeeAmount += stats.Token1FeeAmount
+			candle.Token2FeeAmount += stats.Token2FeeAmount
+			candle.TradeCount += stats.TradeCount
+		}
+		candle.Close = price
+		candle.Token1PoolValue = current.Token1PoolValue
+		candle.Token2PoolValue = current.Token2PoolValue
+		candle.TotalShares = current.TotalShares
+		candles = append(candles, candle)
+		candleFromHeight = candleToHeight + 1
+	}
+	nextFromHeight := uint64(0)
+	if candleFromHeight <= toHeight {
+		nextFromHeight = candleFromHeight
+	}
+	return candles, nextFromHeight, nil
+}
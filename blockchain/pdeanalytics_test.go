@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPDEAnalyticsModule(t *testing.T) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_pdeanalytics_")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dbPath)
+	db, err := database.Open("leveldb", dbPath)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	token1IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token2IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	contributorAddrStr := "12S2jM1TBbX2V5TBTvpJkJmsdaYxbCspGNedQkvJpYcbnV4gad7FDEbzY9P3zbpZRJTsGD5vxJRia3UiiUwMUbXbjfgezewq6rtPNtj"
+	storePool := func(beaconHeight uint64, token1PoolValue uint64, token2PoolValue uint64) {
+		pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, token1IDStr, token2IDStr))
+		shareKey := string(lvdb.BuildPDESharesKeyV2(beaconHeight, token1IDStr, token2IDStr, contributorAddrStr))
+		assert.Nil(t, storePDEPoolPairs(db, beaconHeight, map[string]*lvdb.PDEPoolForPair{
+			pairKey: {Token1IDStr: token1IDStr, Token1PoolValue: token1PoolValue, Token2IDStr: token2IDStr, Token2PoolValue: token2PoolValue},
+		}))
+		assert.Nil(t, storePDEShares(db, beaconHeight, map[string]uint64{shareKey: 1000}))
+	}
+	module := &pdeAnalyticsModule{}
+
+	// block 11 trades token 2 for 1000 of token 1
+	storePool(10, 100000, 200000)
+	storePool(11, 99000, 202030)
+	state, err := module.InitState(db, 10)
+	assert.Nil(t, err)
+	tradeAcceptedContent := metadata.PDETradeAcceptedContent{
+		TokenIDToBuyStr:          token1IDStr,
+		ReceiveAmount:            1000,
+		Token1IDStr:              token1IDStr,
+		Token2IDStr:              token2IDStr,
+		Token1PoolValueOperation: metadata.TokenPoolValueOperation{Operator: "-", Value: 1000},
+		Token2PoolValueOperation: metadata.TokenPoolValueOperation{Operator: "+", Value: 2024},
+		ProtocolFee:              6,
+	}
+	contentBytes, _ := json.Marshal(tradeAcceptedContent)
+	inst := []string{strconv.Itoa(metadata.PDETradeRequestMeta), "1", common.PDETradeAcceptedChainStatus, string(contentBytes)}
+	assert.Nil(t, module.ProcessInstruction(nil, 10, inst, state))
+	assert.Nil(t, module.StoreState(db, 11, state))
+
+	// block 12 does not change the pool pair
+	storePool(12, 99000, 202030)
+	state, err = module.InitState(db, 11)
+	assert.Nil(t, err)
+	assert.Nil(t, module.StoreState(db, 12, state))
+	stats, err := getPDEPairStats(db, 12, token1IDStr, token2IDStr)
+	assert.Nil(t, err)
+	assert.Nil(t, stats)
+
+	candles, nextFromHeight, err := GetPDEPairHistory(db, token2IDStr, token1IDStr, 11, 12, 1, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), nextFromHeight)
+	assert.Equal(t, 2, len(candles))
+	assert.Equal(t, float64(2), candles[0].Open)
+	assert.Equal(t, float64(202030)/float64(99000), candles[0].Close)
+	assert.Equal(t, candles[0].Close, candles[0].High)
+	assert.Equal(t, float64(2), candles[0].Low)
+	assert.Equal(t, uint64(1000), candles[0].Token1Volume)
+	assert.Equal(t, uint64(6), candles[0].Token2FeeAmount)
+	assert.Equal(t, uint64(1), candles[0].TradeCount)
+	assert.Equal(t, uint64(99000), candles[0].Token1PoolValue)
+	assert.Equal(t, uint64(1000), candles[0].TotalShares)
+	assert.Equal(t, candles[0].Close, candles[1].Open)
+	assert.Equal(t, uint64(0), candles[1].TradeCount)
+	assert.Equal(t, uint64(202030), candles[1].Token2PoolValue)
+
+	// pagination
+	candles, nextFromHeight, err = GetPDEPairHistory(db, token1IDStr, token2IDStr, 11, 12, 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(candles))
+	assert.Equal(t, uint64(12), nextFromHeight)
+
+	// reverting block 11 removes its stats
+	block := &BeaconBlock{}
+	block.Header.Height = 11
+	assert.Nil(t, module.RestoreState(db, block))
+	stats, err = getPDEPairStats(db, 11, token1IDStr, token2IDStr)
+	assert.Nil(t, err)
+	assert.Nil(t, stats)
+}
@@ -212,6 +212,119 @@ func (blockGenerator *BlockGenerator) buildPDETradeIssuanceTx(
 	)
 }
 
+func (blockGenerator *BlockGenerator) buildPDEMultiHopTradeIssuanceTx(
+	instStatus string,
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	Logger.log.Info("[PDE Multi-hop Trade] Starting...")
+	var traderAddressStr, tokenIDStr string
+	var receiveAmt uint64
+	var requestedTxID common.Hash
+	if instStatus == common.PDETradeRefundChainStatus {
+		pdeMultiHopTradeRequestAction, err := metadata.ParsePDEMultiHopTradeRequestAction(contentStr)
+		if err != nil || len(pdeMultiHopTradeRequestAction.Meta.TradePath) == 0 {
+			Logger.log.Errorf("ERROR: an error occured while parsing pde multi-hop trade refund content: %+v", err)
+			return nil, nil
+		}
+		if shardID != pdeMultiHopTradeRequestAction.ShardID {
+			return nil, nil
+		}
+		traderAddressStr = pdeMultiHopTradeRequestAction.Meta.TraderAddressStr
+		tokenIDStr = pdeMultiHopTradeRequestAction.Meta.TradePath[0]
+		receiveAmt = pdeMultiHopTradeRequestAction.Meta.SellAmount + pdeMultiHopTradeRequestAction.Meta.TradingFee
+		requestedTxID = pdeMultiHopTradeRequestAction.TxReqID
+	} else {
+		var pdeMultiHopTradeAcceptedContent metadata.PDEMultiHopTradeAcceptedContent
+		err := json.Unmarshal([]byte(contentStr), &pdeMultiHopTradeAcceptedContent)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while unmarshaling pde multi-hop trade accepted content: %+v", err)
+			return nil, nil
+		}
+		if shardID != pdeMultiHopTradeAcceptedContent.ShardID {
+			return nil, nil
+		}
+		traderAddressStr = pdeMultiHopTradeAcceptedContent.TraderAddressStr
+		tokenIDStr = pdeMultiHopTradeAcceptedContent.TokenIDToBuyStr
+		receiveAmt = pdeMultiHopTradeAcceptedContent.ReceiveAmount
+		requestedTxID = pdeMultiHopTradeAcceptedContent.RequestedTxID
+	}
+	resTx, err := buildTradeResTx(
+		instStatus,
+		traderAddressStr,
+		receiveAmt,
+		tokenIDStr,
+		requestedTxID,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while initializing multi-hop trading response tx: %+v", err)
+		return nil, nil
+	}
+	Logger.log.Info("[PDE Multi-hop Trade] Create response tx ok.")
+	return resTx, nil
+}
+
+func (blockGenerator *BlockGenerator) buildPDELimitOrderIssuanceTx(
+	instStatus string,
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	Logger.log.Info("[PDE Limit Order] Starting...")
+	var traderAddressStr, tokenIDStr string
+	var receiveAmt uint64
+	var requestedTxID common.Hash
+	if instStatus == common.PDELimitOrderRefundChainStatus {
+		var refundContent metadata.PDELimitOrderRefundContent
+		err := json.Unmarshal([]byte(contentStr), &refundContent)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while unmarshaling pde limit order refund content: %+v", err)
+			return nil, nil
+		}
+		if shardID != refundContent.ShardID {
+			return nil, nil
+		}
+		traderAddressStr = refundContent.TraderAddressStr
+		tokenIDStr = refundContent.TokenIDStr
+		receiveAmt = refundContent.Amount
+		requestedTxID = refundContent.RequestedTxID
+	} else {
+		var filledContent metadata.PDELimitOrderFilledContent
+		err := json.Unmarshal([]byte(contentStr), &filledContent)
+		if err != nil {
+			Logger.log.Errorf("ERROR: an error occured while unmarshaling pde limit order filled content: %+v", err)
+			return nil, nil
+		}
+		if shardID != filledContent.ShardID {
+			return nil, nil
+		}
+		traderAddressStr = filledContent.TraderAddressStr
+		tokenIDStr = filledContent.TokenIDToBuyStr
+		receiveAmt = filledContent.ReceiveAmount
+		requestedTxID = filledContent.RequestedTxID
+	}
+	resTx, err := buildTradeResTx(
+		instStatus,
+		traderAddressStr,
+		receiveAmt,
+		tokenIDStr,
+		requestedTxID,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while initializing limit order response tx: %+v", err)
+		return nil, nil
+	}
+	Logger.log.Info("[PDE Limit Order] Create response tx ok.")
+	return resTx, nil
+}
+
 func (blockGenerator *BlockGenerator) buildPDEWithdrawalTx(
 	contentStr string,
 	producerPrivateKey *privacy.PrivateKey,
@@ -233,8 +346,11 @@ func (blockGenerator *BlockGenerator) buildPDEWithdrawalTx(
 	meta := metadata.NewPDEWithdrawalResponse(
 		withdrawalTokenIDStr,
 		wdAcceptedContent.TxReqID,
+		wdAcceptedContent.TradingFeeAmt,
 		metadata.PDEWithdrawalResponseMeta,
 	)
+	// accrued trading fees are paid together with the withdrawn pool value
+	withdrawalAmt := wdAcceptedContent.DeductingPoolValue + wdAcceptedContent.TradingFeeAmt
 	tokenID, err := common.Hash{}.NewHashFromStr(withdrawalTokenIDStr)
 	if err != nil {
 		Logger.log.Errorf("ERROR: an error occured while converting tokenid to hash: %+v", err)
@@ -250,7 +366,7 @@ func (blockGenerator *BlockGenerator) buildPDEWithdrawalTx(
 	if withdrawalTokenIDStr == common.PRVCoinID.String() {
 		resTx := new(transaction.Tx)
 		err = resTx.InitTxSalary(
-			wdAcceptedContent.DeductingPoolValue,
+			withdrawalAmt,
 			&receiverAddr,
 			producerPrivateKey,
 			blockGenerator.chain.config.DataBase,
@@ -267,7 +383,7 @@ func (blockGenerator *BlockGenerator) buildPDEWithdrawalTx(
 
 	// in case the returned currency is privacy custom token
 	receiver := &privacy.PaymentInfo{
-		Amount:         wdAcceptedContent.DeductingPoolValue,
+		Amount:         withdrawalAmt,
 		PaymentAddress: receiverAddr,
 	}
 	var propertyID [common.HashSize]byte
@@ -277,7 +393,7 @@ func (blockGenerator *BlockGenerator) buildPDEWithdrawalTx(
 		PropertyID: propID.String(),
 		// PropertyName:   tokeName,
 		// PropertySymbol: tokenSymbol,
-		Amount:      wdAcceptedContent.DeductingPoolValue,
+		Amount:      withdrawalAmt,
 		TokenTxType: transaction.CustomTokenInit,
 		Receiver:    []*privacy.PaymentInfo{receiver},
 		TokenInput:  []*privacy.InputCoin{},
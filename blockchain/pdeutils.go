@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
 	"github.com/pkg/errors"
 )
 
@@ -17,13 +19,19 @@ type CurrentPDEState struct {
 	WaitingPDEContributions map[string]*lvdb.PDEContribution
 	PDEPoolPairs            map[string]*lvdb.PDEPoolForPair
 	PDEShares               map[string]uint64
+	PDELimitOrders          map[string]*lvdb.PDELimitOrder
+	PDETradingFees          map[string]uint64
+	// keys of PDEShares by the share key prefix of their pool pair, built on first use and kept up to date by setPDEShare
+	pdeShareKeysByPair map[string][]string
 }
 
 type DeductingAmountsByWithdrawal struct {
 	Token1IDStr string
 	PoolValue1  uint64
+	TradingFee1 uint64
 	Token2IDStr string
 	PoolValue2  uint64
+	TradingFee2 uint64
 	Shares      uint64
 }
 
@@ -99,6 +107,42 @@ func storePDEPoolPairs(
 	return nil
 }
 
+func storePDELimitOrders(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	pdeLimitOrders map[string]*lvdb.PDELimitOrder,
+) error {
+	for orderKey, order := range pdeLimitOrders {
+		newKey := replaceNewBCHeightInKeyStr(orderKey, beaconHeight)
+		orderBytes, err := json.Marshal(order)
+		if err != nil {
+			return err
+		}
+		err = db.Put([]byte(newKey), orderBytes)
+		if err != nil {
+			return database.NewDatabaseError(database.StorePDELimitOrderError, errors.Wrap(err, "db.lvdb.put"))
+		}
+	}
+	return nil
+}
+
+func storePDETradingFees(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	pdeTradingFees map[string]uint64,
+) error {
+	for feeKey, feeAmt := range pdeTradingFees {
+		newKey := replaceNewBCHeightInKeyStr(feeKey, beaconHeight)
+		buf := make([]byte, binary.MaxVarintLen64)
+		binary.LittleEndian.PutUint64(buf, feeAmt)
+		dbErr := db.Put([]byte(newKey), buf)
+		if dbErr != nil {
+			return database.NewDatabaseError(database.StorePDETradingFeeError, errors.Wrap(dbErr, "db.lvdb.put"))
+		}
+	}
+	return nil
+}
+
 func getWaitingPDEContributions(
 	db database.DatabaseInterface,
 	beaconHeight uint64,
@@ -155,6 +199,42 @@ func getPDEShares(
 	return pdeShares, nil
 }
 
+func getPDELimitOrders(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (map[string]*lvdb.PDELimitOrder, error) {
+	pdeLimitOrders := make(map[string]*lvdb.PDELimitOrder)
+	orderKeysBytes, orderValuesBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PDELimitOrderPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for idx, orderKeyBytes := range orderKeysBytes {
+		var order lvdb.PDELimitOrder
+		err = json.Unmarshal(orderValuesBytes[idx], &order)
+		if err != nil {
+			return nil, err
+		}
+		pdeLimitOrders[string(orderKeyBytes)] = &order
+	}
+	return pdeLimitOrders, nil
+}
+
+func getPDETradingFees(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (map[string]uint64, error) {
+	pdeTradingFees := make(map[string]uint64)
+	feeKeysBytes, feeValuesBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PDETradingFeePrefix)
+	if err != nil {
+		return nil, err
+	}
+	for idx, feeKeyBytes := range feeKeysBytes {
+		feeAmt := uint64(binary.LittleEndian.Uint64(feeValuesBytes[idx]))
+		pdeTradingFees[string(feeKeyBytes)] = feeAmt
+	}
+	return pdeTradingFees, nil
+}
+
 func InitCurrentPDEStateFromDB(
 	db database.DatabaseInterface,
 	beaconHeight uint64,
@@ -171,10 +251,20 @@ func InitCurrentPDEStateFromDB(
 	if err != nil {
 		return nil, err
 	}
+	pdeLimitOrders, err := getPDELimitOrders(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+	pdeTradingFees, err := getPDETradingFees(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
 	return &CurrentPDEState{
 		WaitingPDEContributions: waitingPDEContributions,
 		PDEPoolPairs:            pdePoolPairs,
 		PDEShares:               pdeShares,
+		PDELimitOrders:          pdeLimitOrders,
+		PDETradingFees:          pdeTradingFees,
 	}, nil
 }
 
@@ -195,6 +285,14 @@ func storePDEStateToDB(
 	if err != nil {
 		return err
 	}
+	err = storePDELimitOrders(db, beaconHeight, currentPDEState.PDELimitOrders)
+	if err != nil {
+		return err
+	}
+	err = storePDETradingFees(db, beaconHeight, currentPDEState.PDETradingFees)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -216,13 +314,13 @@ func addShareAmountUpV2(
 	}
 	pdeShareKey := string(lvdb.BuildPDESharesKeyV2(beaconHeight, token1IDStr, token2IDStr, contributorAddrStr))
 	if totalSharesOnToken == 0 {
-		currentPDEState.PDEShares[pdeShareKey] = amt
+		setPDEShare(currentPDEState, pdeShareKey, amt)
 		return
 	}
 	poolPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, token1IDStr, token2IDStr))
 	poolPair, found := currentPDEState.PDEPoolPairs[poolPairKey]
 	if !found || poolPair == nil {
-		currentPDEState.PDEShares[pdeShareKey] = amt
+		setPDEShare(currentPDEState, pdeShareKey, amt)
 		return
 	}
 	poolValue := poolPair.Token1PoolValue
@@ -230,7 +328,7 @@ func addShareAmountUpV2(
 		poolValue = poolPair.Token2PoolValue
 	}
 	if poolValue == 0 {
-		currentPDEState.PDEShares[pdeShareKey] = amt
+		setPDEShare(currentPDEState, pdeShareKey, amt)
 	}
 	increasingAmt := big.NewInt(0)
 	increasingAmt.Mul(big.NewInt(int64(totalSharesOnToken)), big.NewInt(int64(amt)))
@@ -240,7 +338,156 @@ func addShareAmountUpV2(
 	if found {
 		addedUpAmt += currentShare
 	}
-	currentPDEState.PDEShares[pdeShareKey] = addedUpAmt
+	setPDEShare(currentPDEState, pdeShareKey, addedUpAmt)
+}
+
+// BuildPDEPoolPairID returns the id of a pool pair used to configure its trading fee in PDEParams
+func BuildPDEPoolPairID(token1IDStr string, token2IDStr string) string {
+	tokenIDStrs := []string{token1IDStr, token2IDStr}
+	sort.Strings(tokenIDStrs)
+	return tokenIDStrs[0] + "-" + tokenIDStrs[1]
+}
+
+// getPDETradingFeeBPS returns the protocol fee rate of a pool pair in basis points
+func (blockchain *BlockChain) getPDETradingFeeBPS(token1IDStr string, token2IDStr string) uint64 {
+	if blockchain.config.ChainParams == nil {
+		return 0
+	}
+	pdeParams := blockchain.config.ChainParams.PDEParams
+	if feeBPS, found := pdeParams.TradingFeeBPSByPairs[BuildPDEPoolPairID(token1IDStr, token2IDStr)]; found {
+		return feeBPS
+	}
+	return pdeParams.TradingFeeBPS
+}
+
+// isPDEProtocolFeeActive tells if the beacon block following beaconHeight takes the protocol fee
+func (blockchain *BlockChain) isPDEProtocolFeeActive(beaconHeight uint64) bool {
+	if blockchain.config.ChainParams == nil {
+		return false
+	}
+	return beaconHeight+1 >= blockchain.config.ChainParams.PDEParams.ProtocolFeeActivationHeight
+}
+
+// computePDEProtocolFee returns the protocol fee taken from an amount sold to a pool pair by the beacon block following beaconHeight,
+// it is zero before the activation height so instructions of those blocks are built as they were without protocol fee
+func (blockchain *BlockChain) computePDEProtocolFee(beaconHeight uint64, token1IDStr string, token2IDStr string, sellAmount uint64) uint64 {
+	if !blockchain.isPDEProtocolFeeActive(beaconHeight) {
+		return 0
+	}
+	feeBPS := blockchain.getPDETradingFeeBPS(token1IDStr, token2IDStr)
+	if feeBPS == 0 {
+		return 0
+	}
+	protocolFee := new(big.Int).Mul(new(big.Int).SetUint64(sellAmount), new(big.Int).SetUint64(feeBPS))
+	protocolFee.Div(protocolFee, big.NewInt(10000))
+	if protocolFee.Uint64() > sellAmount {
+		return sellAmount
+	}
+	return protocolFee.Uint64()
+}
+
+// pdeSellingTokenIDStr returns the token added to a pool pair by a trade
+func pdeSellingTokenIDStr(token1IDStr string, token2IDStr string, token1PoolValueOperation metadata.TokenPoolValueOperation) string {
+	if token1PoolValueOperation.Operator == "+" {
+		return token1IDStr
+	}
+	return token2IDStr
+}
+
+// pdeSharesPairPrefix returns the part of a share key built by BuildPDESharesKeyV2 that is the same for
+// every contributor to its pool pair: the prefix, then the beacon height and two token ids each followed by a dash
+func pdeSharesPairPrefix(shareKey string) string {
+	end := len(lvdb.PDESharePrefix)
+	for i := 0; i < 3; i++ {
+		if end > len(shareKey) {
+			return shareKey
+		}
+		dashIdx := strings.Index(shareKey[end:], "-")
+		if dashIdx < 0 {
+			return shareKey
+		}
+		end += dashIdx + 1
+	}
+	return shareKey[:end]
+}
+
+// getPDEShareKeysForPair returns keys of the shares of a pool pair without scanning shares of the other pairs
+func getPDEShareKeysForPair(currentPDEState *CurrentPDEState, sharesForPairPrefix string) []string {
+	if currentPDEState.pdeShareKeysByPair == nil {
+		currentPDEState.pdeShareKeysByPair = make(map[string][]string)
+		for shareKey := range currentPDEState.PDEShares {
+			pairPrefix := pdeSharesPairPrefix(shareKey)
+			currentPDEState.pdeShareKeysByPair[pairPrefix] = append(currentPDEState.pdeShareKeysByPair[pairPrefix], shareKey)
+		}
+	}
+	return currentPDEState.pdeShareKeysByPair[sharesForPairPrefix]
+}
+
+// setPDEShare sets a share amount, a new share key is added to the index of share keys by pool pair
+func setPDEShare(currentPDEState *CurrentPDEState, shareKey string, amt uint64) {
+	_, found := currentPDEState.PDEShares[shareKey]
+	if !found && currentPDEState.pdeShareKeysByPair != nil {
+		pairPrefix := pdeSharesPairPrefix(shareKey)
+		currentPDEState.pdeShareKeysByPair[pairPrefix] = append(currentPDEState.pdeShareKeysByPair[pairPrefix], shareKey)
+	}
+	currentPDEState.PDEShares[shareKey] = amt
+}
+
+// creditPDETradingFee splits a protocol fee among share holders of a pool pair pro-rata to their shares,
+// the remainder of the division (or the whole fee if the pair has no share holder) goes to the pool value
+func creditPDETradingFee(
+	beaconHeight uint64,
+	pdePoolPair *lvdb.PDEPoolForPair,
+	feeTokenIDStr string,
+	fee uint64,
+	currentPDEState *CurrentPDEState,
+) {
+	if fee == 0 {
+		return
+	}
+	if currentPDEState.PDETradingFees == nil {
+		currentPDEState.PDETradingFees = make(map[string]uint64)
+	}
+	sharesForPairPrefix := string(lvdb.BuildPDESharesKeyV2(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, ""))
+	shareKeysForPair := getPDEShareKeysForPair(currentPDEState, sharesForPairPrefix)
+	totalSharesForPair := uint64(0)
+	for _, shareKey := range shareKeysForPair {
+		totalSharesForPair += currentPDEState.PDEShares[shareKey]
+	}
+	creditedFee := uint64(0)
+	if totalSharesForPair > 0 {
+		for _, shareKey := range shareKeysForPair {
+			shareAmt := currentPDEState.PDEShares[shareKey]
+			if shareAmt == 0 {
+				continue
+			}
+			feeForContributor := new(big.Int).Mul(new(big.Int).SetUint64(fee), new(big.Int).SetUint64(shareAmt))
+			feeForContributor.Div(feeForContributor, new(big.Int).SetUint64(totalSharesForPair))
+			if feeForContributor.Uint64() == 0 {
+				continue
+			}
+			contributorAddrStr := shareKey[len(sharesForPairPrefix):]
+			feeKey := string(lvdb.BuildPDETradingFeeKey(beaconHeight, pdePoolPair.Token1IDStr, pdePoolPair.Token2IDStr, feeTokenIDStr, contributorAddrStr))
+			currentPDEState.PDETradingFees[feeKey] += feeForContributor.Uint64()
+			creditedFee += feeForContributor.Uint64()
+		}
+	}
+	addPDEPoolValue(pdePoolPair, feeTokenIDStr, fee-creditedFee)
+}
+
+// claimPDETradingFee removes and returns trading fees accrued to a contributor in a token of a pool pair
+func claimPDETradingFee(
+	beaconHeight uint64,
+	token1IDStr string,
+	token2IDStr string,
+	feeTokenIDStr string,
+	contributorAddrStr string,
+	currentPDEState *CurrentPDEState,
+) uint64 {
+	feeKey := string(lvdb.BuildPDETradingFeeKey(beaconHeight, token1IDStr, token2IDStr, feeTokenIDStr, contributorAddrStr))
+	feeAmt := currentPDEState.PDETradingFees[feeKey]
+	delete(currentPDEState.PDETradingFees, feeKey)
+	return feeAmt
 }
 
 func updateWaitingContributionPairToPoolV2(
@@ -285,3 +532,125 @@ func updateWaitingContributionPairToPoolV2(
 		currentPDEState,
 	)
 }
+
+// pdeModule handles contributions, trades and withdrawals of the decentralized exchange
+type pdeModule struct{}
+
+func init() {
+	if err := RegisterStatefulModule(&pdeModule{}); err != nil {
+		panic("failed to register pde stateful module")
+	}
+	if err := RegisterStatefulModule(&pdeAnalyticsModule{}); err != nil {
+		panic("failed to register pde analytics stateful module")
+	}
+}
+
+func (module *pdeModule) Name() string {
+	return "pde"
+}
+
+func (module *pdeModule) Priority() int {
+	return pdeModulePriority
+}
+
+func (module *pdeModule) ActionMetaTypes() []int {
+	return []int{metadata.PDEContributionMeta, metadata.PDETradeRequestMeta, metadata.PDEMultiHopTradeRequestMeta, metadata.PDEWithdrawalRequestMeta, metadata.PDELimitOrderRequestMeta, metadata.PDECancelLimitOrderRequestMeta}
+}
+
+func (module *pdeModule) InstructionMetaTypes() []int {
+	return []int{metadata.PDEContributionMeta, metadata.PDETradeRequestMeta, metadata.PDEMultiHopTradeRequestMeta, metadata.PDEWithdrawalRequestMeta, metadata.PDELimitOrderRequestMeta, metadata.PDECancelLimitOrderRequestMeta}
+}
+
+func (module *pdeModule) InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error) {
+	return InitCurrentPDEStateFromDB(db, beaconHeight)
+}
+
+func (module *pdeModule) BuildInstructions(
+	blockchain *BlockChain,
+	beaconHeight uint64,
+	state interface{},
+	actionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	currentPDEState, ok := state.(*CurrentPDEState)
+	if !ok {
+		return [][]string{}, errors.New("invalid state of pde module")
+	}
+	pdeContributionActionsByShardID := map[byte][][]string{}
+	pdeTradeActionsByShardID := map[byte][][]string{}
+	pdeMultiHopTradeActionsByShardID := map[byte][][]string{}
+	pdeWithdrawalActionsByShardID := map[byte][][]string{}
+	pdeLimitOrderActionsByShardID := map[byte][][]string{}
+	pdeCancelLimitOrderActionsByShardID := map[byte][][]string{}
+	for shardID, actions := range actionsByShardID {
+		for _, action := range actions {
+			switch action[0] {
+			case strconv.Itoa(metadata.PDEContributionMeta):
+				pdeContributionActionsByShardID = groupPDEActionsByShardID(pdeContributionActionsByShardID, action, shardID)
+			case strconv.Itoa(metadata.PDETradeRequestMeta):
+				pdeTradeActionsByShardID = groupPDEActionsByShardID(pdeTradeActionsByShardID, action, shardID)
+			case strconv.Itoa(metadata.PDEMultiHopTradeRequestMeta):
+				pdeMultiHopTradeActionsByShardID = groupPDEActionsByShardID(pdeMultiHopTradeActionsByShardID, action, shardID)
+			case strconv.Itoa(metadata.PDEWithdrawalRequestMeta):
+				pdeWithdrawalActionsByShardID = groupPDEActionsByShardID(pdeWithdrawalActionsByShardID, action, shardID)
+			case strconv.Itoa(metadata.PDELimitOrderRequestMeta):
+				pdeLimitOrderActionsByShardID = groupPDEActionsByShardID(pdeLimitOrderActionsByShardID, action, shardID)
+			case strconv.Itoa(metadata.PDECancelLimitOrderRequestMeta):
+				pdeCancelLimitOrderActionsByShardID = groupPDEActionsByShardID(pdeCancelLimitOrderActionsByShardID, action, shardID)
+			}
+		}
+	}
+	return blockchain.handlePDEInsts(
+		beaconHeight, currentPDEState,
+		pdeContributionActionsByShardID,
+		pdeTradeActionsByShardID,
+		pdeMultiHopTradeActionsByShardID,
+		pdeWithdrawalActionsByShardID,
+		pdeLimitOrderActionsByShardID,
+		pdeCancelLimitOrderActionsByShardID,
+	)
+}
+
+func (module *pdeModule) ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error {
+	currentPDEState, ok := state.(*CurrentPDEState)
+	if !ok {
+		return errors.New("invalid state of pde module")
+	}
+	switch inst[0] {
+	case strconv.Itoa(metadata.PDEContributionMeta):
+		return blockchain.processPDEContributionV2(beaconHeight, inst, currentPDEState)
+	case strconv.Itoa(metadata.PDETradeRequestMeta):
+		return blockchain.processPDETrade(beaconHeight, inst, currentPDEState)
+	case strconv.Itoa(metadata.PDEMultiHopTradeRequestMeta):
+		return blockchain.processPDEMultiHopTrade(beaconHeight, inst, currentPDEState)
+	case strconv.Itoa(metadata.PDEWithdrawalRequestMeta):
+		return blockchain.processPDEWithdrawal(beaconHeight, inst, currentPDEState)
+	case strconv.Itoa(metadata.PDELimitOrderRequestMeta):
+		return blockchain.processPDELimitOrder(beaconHeight, inst, currentPDEState)
+	case strconv.Itoa(metadata.PDECancelLimitOrderRequestMeta):
+		return blockchain.processPDECancelLimitOrder(beaconHeight, inst, currentPDEState)
+	}
+	return nil
+}
+
+func (module *pdeModule) StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error {
+	currentPDEState, ok := state.(*CurrentPDEState)
+	if !ok {
+		return errors.New("invalid state of pde module")
+	}
+	return storePDEStateToDB(db, beaconHeight, currentPDEState)
+}
+
+// BackupState does nothing since pde state of the previous beacon height is kept
+func (module *pdeModule) BackupState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
+
+// RestoreState removes pde state stored at the height of the reverted beacon block
+func (module *pdeModule) RestoreState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return deleteRecordsByPrefixes(
+		db,
+		block.Header.Height,
+		[][]byte{lvdb.WaitingPDEContributionPrefix, lvdb.PDEPoolPrefix, lvdb.PDESharePrefix, lvdb.PDELimitOrderPrefix, lvdb.PDETradingFeePrefix},
+	)
+}
@@ -0,0 +1,213 @@
+package blockchain
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+func buildPortalResTx(
+	receiverAddressStr string,
+	receiveAmt uint64,
+	tokenIDStr string,
+	meta metadata.Metadata,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+	db database.DatabaseInterface,
+) (metadata.Transaction, error) {
+	tokenID, err := common.Hash{}.NewHashFromStr(tokenIDStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while converting tokenid to hash: %+v", err)
+		return nil, err
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(receiverAddressStr)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while deserializing receiver address string: %+v", err)
+		return nil, err
+	}
+	receiverAddr := keyWallet.KeySet.PaymentAddress
+	// the returned currency is PRV
+	if tokenIDStr == common.PRVCoinID.String() {
+		resTx := new(transaction.Tx)
+		err = resTx.InitTxSalary(
+			receiveAmt,
+			&receiverAddr,
+			producerPrivateKey,
+			db,
+			meta,
+		)
+		if err != nil {
+			return nil, NewBlockChainError(InitPortalResponseTransactionError, err)
+		}
+		return resTx, nil
+	}
+
+	// in case the returned currency is privacy custom token
+	receiver := &privacy.PaymentInfo{
+		Amount:         receiveAmt,
+		PaymentAddress: receiverAddr,
+	}
+	var propertyID [common.HashSize]byte
+	copy(propertyID[:], tokenID[:])
+	propID := common.Hash(propertyID)
+	tokenParams := &transaction.CustomTokenPrivacyParamTx{
+		PropertyID:  propID.String(),
+		Amount:      receiveAmt,
+		TokenTxType: transaction.CustomTokenInit,
+		Receiver:    []*privacy.PaymentInfo{receiver},
+		TokenInput:  []*privacy.InputCoin{},
+		Mintable:    true,
+	}
+	resTx := &transaction.TxCustomTokenPrivacy{}
+	initErr := resTx.Init(
+		transaction.NewTxPrivacyTokenInitParams(
+			producerPrivateKey,
+			[]*privacy.PaymentInfo{},
+			nil,
+			0,
+			tokenParams,
+			db,
+			meta,
+			false,
+			false,
+			shardID,
+			nil,
+		),
+	)
+	if initErr != nil {
+		Logger.log.Errorf("ERROR: an error occured while initializing portal response tx: %+v", initErr)
+		return nil, NewBlockChainError(InitPortalResponseTransactionError, initErr)
+	}
+	return resTx, nil
+}
+
+// buildPortalRefundPortingFeeTx refunds the porting fee (PRV) of a rejected porting request
+func (blockGenerator *BlockGenerator) buildPortalRefundPortingFeeTx(
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	var portingReqContent metadata.PortalPortingRequestContent
+	err := json.Unmarshal([]byte(contentStr), &portingReqContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal porting request content: %+v", err)
+		return nil, nil
+	}
+	if portingReqContent.ShardID != shardID || portingReqContent.PortingFee == 0 {
+		return nil, nil
+	}
+	meta := metadata.NewPortalUserRegisterResponse(
+		common.PortalPortingRequestRejectedChainStatus,
+		portingReqContent.TxReqID,
+		metadata.PortalUserRegisterResponseMeta,
+	)
+	return buildPortalResTx(
+		portingReqContent.IncogAddressStr,
+		portingReqContent.PortingFee,
+		common.PRVCoinID.String(),
+		meta,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+}
+
+// buildPortalAcceptedRequestPTokensTx mints pTokens to the porter
+func (blockGenerator *BlockGenerator) buildPortalAcceptedRequestPTokensTx(
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	var reqPTokensContent metadata.PortalRequestPTokensContent
+	err := json.Unmarshal([]byte(contentStr), &reqPTokensContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal request ptokens content: %+v", err)
+		return nil, nil
+	}
+	if reqPTokensContent.ShardID != shardID {
+		return nil, nil
+	}
+	meta := metadata.NewPortalUserRequestPTokenResponse(
+		common.PortalReqPTokensAcceptedChainStatus,
+		reqPTokensContent.TxReqID,
+		metadata.PortalUserRequestPTokenResponseMeta,
+	)
+	return buildPortalResTx(
+		reqPTokensContent.IncogAddressStr,
+		reqPTokensContent.PortingAmount,
+		reqPTokensContent.TokenID,
+		meta,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+}
+
+// buildPortalRejectedRedeemRequestTx refunds the burned pTokens of a rejected redeem request
+func (blockGenerator *BlockGenerator) buildPortalRejectedRedeemRequestTx(
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	var redeemReqContent metadata.PortalRedeemRequestContent
+	err := json.Unmarshal([]byte(contentStr), &redeemReqContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal redeem request content: %+v", err)
+		return nil, nil
+	}
+	if redeemReqContent.ShardID != shardID {
+		return nil, nil
+	}
+	meta := metadata.NewPortalRedeemRequestResponse(
+		common.PortalRedeemRequestRejectedChainStatus,
+		redeemReqContent.TxReqID,
+		metadata.PortalRedeemRequestResponseMeta,
+	)
+	return buildPortalResTx(
+		redeemReqContent.RedeemerIncAddressStr,
+		redeemReqContent.RedeemAmount,
+		redeemReqContent.TokenID,
+		meta,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+}
+
+// buildPortalLiquidateCustodianResponseTx pays the collateral of a liquidated custodian to the redeemer
+func (blockGenerator *BlockGenerator) buildPortalLiquidateCustodianResponseTx(
+	contentStr string,
+	producerPrivateKey *privacy.PrivateKey,
+	shardID byte,
+) (metadata.Transaction, error) {
+	var liquidationContent metadata.PortalLiquidateCustodianContent
+	err := json.Unmarshal([]byte(contentStr), &liquidationContent)
+	if err != nil {
+		Logger.log.Errorf("ERROR: an error occured while unmarshaling portal liquidate custodian content: %+v", err)
+		return nil, nil
+	}
+	if liquidationContent.ShardID != shardID || liquidationContent.MintedCollateralAmount == 0 {
+		return nil, nil
+	}
+	meta := metadata.NewPortalLiquidateCustodianResponse(
+		liquidationContent.UniqueRedeemID,
+		liquidationContent.MintedCollateralAmount,
+		liquidationContent.RedeemerIncAddressStr,
+		liquidationContent.CustodianIncAddressStr,
+		metadata.PortalLiquidateCustodianResponseMeta,
+	)
+	return buildPortalResTx(
+		liquidationContent.RedeemerIncAddressStr,
+		liquidationContent.MintedCollateralAmount,
+		common.PRVCoinID.String(),
+		meta,
+		producerPrivateKey,
+		shardID,
+		blockGenerator.chain.config.DataBase,
+	)
+}
@@ -0,0 +1,731 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+)
+
+type CurrentPortalState struct {
+	CustodianPoolState     map[string]*lvdb.CustodianState // key : beaconHeight || custodian_address
+	WaitingPortingRequests map[string]*lvdb.PortingRequest // key : beaconHeight || UniquePortingID
+	WaitingRedeemRequests  map[string]*lvdb.RedeemRequest  // key : beaconHeight || UniqueRedeemID
+	BTCHeaderChainTip      *lvdb.BTCHeaderState            // tip of the relayed btc header chain, nil until headers are relayed
+	RelayedBTCHeaders      map[string]*lvdb.BTCHeaderState // key : btc block hash, headers relayed in the current beacon block
+}
+
+func getCustodianPoolState(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (map[string]*lvdb.CustodianState, error) {
+	custodianPoolState := make(map[string]*lvdb.CustodianState)
+	custodianPoolStateKeysBytes, custodianPoolStateValuesBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PortalCustodianStatePrefix)
+	if err != nil {
+		return nil, err
+	}
+	for idx, custodianStateKeyBytes := range custodianPoolStateKeysBytes {
+		var custodianState lvdb.CustodianState
+		err = json.Unmarshal(custodianPoolStateValuesBytes[idx], &custodianState)
+		if err != nil {
+			return nil, err
+		}
+		custodianPoolState[string(custodianStateKeyBytes)] = &custodianState
+	}
+	return custodianPoolState, nil
+}
+
+func getWaitingPortingRequests(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (map[string]*lvdb.PortingRequest, error) {
+	waitingPortingReqs := make(map[string]*lvdb.PortingRequest)
+	waitingPortingReqsKeyBytes, waitingPortingReqsValueBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PortalWaitingPortingRequestsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for idx, waitingPortingReqKeyBytes := range waitingPortingReqsKeyBytes {
+		var portingReq lvdb.PortingRequest
+		err = json.Unmarshal(waitingPortingReqsValueBytes[idx], &portingReq)
+		if err != nil {
+			return nil, err
+		}
+		waitingPortingReqs[string(waitingPortingReqKeyBytes)] = &portingReq
+	}
+	return waitingPortingReqs, nil
+}
+
+func getWaitingRedeemRequests(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (map[string]*lvdb.RedeemRequest, error) {
+	waitingRedeemReqs := make(map[string]*lvdb.RedeemRequest)
+	waitingRedeemReqsKeyBytes, waitingRedeemReqsValueBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PortalWaitingRedeemRequestsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for idx, waitingRedeemReqKeyBytes := range waitingRedeemReqsKeyBytes {
+		var redeemReq lvdb.RedeemRequest
+		err = json.Unmarshal(waitingRedeemReqsValueBytes[idx], &redeemReq)
+		if err != nil {
+			return nil, err
+		}
+		waitingRedeemReqs[string(waitingRedeemReqKeyBytes)] = &redeemReq
+	}
+	return waitingRedeemReqs, nil
+}
+
+func getBTCHeaderChainTip(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (*lvdb.BTCHeaderState, error) {
+	_, btcHeaderChainTipsValueBytes, err := db.GetAllRecordsByPrefix(beaconHeight, lvdb.PortalBTCHeaderChainTipPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(btcHeaderChainTipsValueBytes) == 0 {
+		return nil, nil
+	}
+	var btcHeaderChainTip lvdb.BTCHeaderState
+	err = json.Unmarshal(btcHeaderChainTipsValueBytes[0], &btcHeaderChainTip)
+	if err != nil {
+		return nil, err
+	}
+	return &btcHeaderChainTip, nil
+}
+
+func InitCurrentPortalStateFromDB(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+) (*CurrentPortalState, error) {
+	custodianPoolState, err := getCustodianPoolState(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+	waitingPortingReqs, err := getWaitingPortingRequests(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+	waitingRedeemReqs, err := getWaitingRedeemRequests(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+	btcHeaderChainTip, err := getBTCHeaderChainTip(db, beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &CurrentPortalState{
+		CustodianPoolState:     custodianPoolState,
+		WaitingPortingRequests: waitingPortingReqs,
+		WaitingRedeemRequests:  waitingRedeemReqs,
+		BTCHeaderChainTip:      btcHeaderChainTip,
+		RelayedBTCHeaders:      make(map[string]*lvdb.BTCHeaderState),
+	}, nil
+}
+
+func storePortalRecords(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	records map[string]interface{},
+) error {
+	for key, record := range records {
+		newKey := replaceNewBCHeightInKeyStr(key, beaconHeight)
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		err = db.Put([]byte(newKey), recordBytes)
+		if err != nil {
+			return database.NewDatabaseError(database.StorePortalStateError, err)
+		}
+	}
+	return nil
+}
+
+func storePortalStateToDB(
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	currentPortalState *CurrentPortalState,
+) error {
+	records := make(map[string]interface{})
+	for key, custodianState := range currentPortalState.CustodianPoolState {
+		records[key] = custodianState
+	}
+	for key, portingReq := range currentPortalState.WaitingPortingRequests {
+		records[key] = portingReq
+	}
+	for key, redeemReq := range currentPortalState.WaitingRedeemRequests {
+		records[key] = redeemReq
+	}
+	if currentPortalState.BTCHeaderChainTip != nil {
+		records[string(lvdb.BuildPortalBTCHeaderChainTipKey(beaconHeight))] = currentPortalState.BTCHeaderChainTip
+	}
+	err := storePortalRecords(db, beaconHeight, records)
+	if err != nil {
+		return err
+	}
+	// relayed headers are not kept by beacon height, they are stored once
+	for blockHashStr, btcHeader := range currentPortalState.RelayedBTCHeaders {
+		btcHeaderBytes, err := json.Marshal(btcHeader)
+		if err != nil {
+			return err
+		}
+		err = db.Put(lvdb.BuildPortalBTCHeaderKey(blockHashStr), btcHeaderBytes)
+		if err != nil {
+			return database.NewDatabaseError(database.StorePortalStateError, err)
+		}
+	}
+	return nil
+}
+
+func newBTCHeaderState(chainHeader *btc.ChainHeader, beaconHeight uint64) *lvdb.BTCHeaderState {
+	return &lvdb.BTCHeaderState{
+		Header:       chainHeader.Header.Hex(),
+		Height:       chainHeader.Height,
+		ChainWork:    chainHeader.ChainWork.String(),
+		BeaconHeight: beaconHeight,
+	}
+}
+
+func newBTCChainHeader(btcHeader *lvdb.BTCHeaderState) (*btc.ChainHeader, error) {
+	header, err := btc.ParseBlockHeaderHex(btcHeader.Header)
+	if err != nil {
+		return nil, err
+	}
+	chainWork, ok := new(big.Int).SetString(btcHeader.ChainWork, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain work %s of btc header", btcHeader.ChainWork)
+	}
+	return &btc.ChainHeader{
+		Header:    header,
+		Height:    btcHeader.Height,
+		ChainWork: chainWork,
+	}, nil
+}
+
+// getBTCCheckpoint returns the header the relayed btc header chain starts from
+func (blockchain *BlockChain) getBTCCheckpoint() (*btc.ChainHeader, error) {
+	portalParams := blockchain.config.ChainParams.PortalParams
+	if portalParams.BTCParams == nil {
+		return nil, errors.New("btc header relaying is not configured")
+	}
+	header, err := btc.ParseBlockHeaderHex(portalParams.BTCCheckpoint.Header)
+	if err != nil {
+		return nil, err
+	}
+	return &btc.ChainHeader{
+		Header:    header,
+		Height:    portalParams.BTCCheckpoint.Height,
+		ChainWork: btc.CalcWork(header.Bits),
+	}, nil
+}
+
+// btcHeaderGetter looks up a btc header among the headers relayed in the current beacon block first, then in db
+func (blockchain *BlockChain) btcHeaderGetter(
+	currentPortalState *CurrentPortalState,
+	db database.DatabaseInterface,
+) btc.HeaderGetter {
+	return func(hash common.Hash) (*btc.ChainHeader, error) {
+		blockHashStr := hash.String()
+		if btcHeader, found := currentPortalState.RelayedBTCHeaders[blockHashStr]; found {
+			return newBTCChainHeader(btcHeader)
+		}
+		checkpoint, err := blockchain.getBTCCheckpoint()
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint.Header.BlockHash() == hash {
+			return checkpoint, nil
+		}
+		btcHeaderKey := lvdb.BuildPortalBTCHeaderKey(blockHashStr)
+		found, err := db.HasValue(btcHeaderKey)
+		if err != nil || !found {
+			return nil, err
+		}
+		btcHeaderBytes, err := db.Get(btcHeaderKey)
+		if err != nil {
+			return nil, err
+		}
+		var btcHeader lvdb.BTCHeaderState
+		err = json.Unmarshal(btcHeaderBytes, &btcHeader)
+		if err != nil {
+			return nil, err
+		}
+		return newBTCChainHeader(&btcHeader)
+	}
+}
+
+// getBTCHeaderChainTip returns tip of the relayed btc header chain, the checkpoint if no header was relayed
+func (blockchain *BlockChain) getBTCHeaderChainTip(currentPortalState *CurrentPortalState) (*btc.ChainHeader, error) {
+	if currentPortalState.BTCHeaderChainTip == nil {
+		return blockchain.getBTCCheckpoint()
+	}
+	return newBTCChainHeader(currentPortalState.BTCHeaderChainTip)
+}
+
+// connectBTCHeaders validates relayed btc headers and adds them to the header chain, headers that
+// were relayed before are skipped, the tip moves to the header with the most work.
+// Nothing is added if any of the headers is invalid
+func (blockchain *BlockChain) connectBTCHeaders(
+	currentPortalState *CurrentPortalState,
+	db database.DatabaseInterface,
+	beaconHeight uint64,
+	headerStrs []string,
+) error {
+	params := blockchain.config.ChainParams.PortalParams.BTCParams
+	if params == nil {
+		return errors.New("btc header relaying is not configured")
+	}
+	tip, err := blockchain.getBTCHeaderChainTip(currentPortalState)
+	if err != nil {
+		return err
+	}
+	getHeader := blockchain.btcHeaderGetter(currentPortalState, db)
+	newHeaders := make(map[common.Hash]*btc.ChainHeader)
+	getNewOrKnownHeader := func(hash common.Hash) (*btc.ChainHeader, error) {
+		if chainHeader, found := newHeaders[hash]; found {
+			return chainHeader, nil
+		}
+		return getHeader(hash)
+	}
+	newHeaderHashes := []common.Hash{}
+	for _, headerStr := range headerStrs {
+		header, err := btc.ParseBlockHeaderHex(headerStr)
+		if err != nil {
+			return err
+		}
+		blockHash := header.BlockHash()
+		knownHeader, err := getNewOrKnownHeader(blockHash)
+		if err != nil {
+			return err
+		}
+		if knownHeader != nil {
+			continue
+		}
+		chainHeader, err := btc.ConnectHeader(params, header, getNewOrKnownHeader)
+		if err != nil {
+			return err
+		}
+		newHeaders[blockHash] = chainHeader
+		newHeaderHashes = append(newHeaderHashes, blockHash)
+		if chainHeader.ChainWork.Cmp(tip.ChainWork) > 0 {
+			tip = chainHeader
+		}
+	}
+	if len(newHeaderHashes) == 0 {
+		return nil
+	}
+	for _, blockHash := range newHeaderHashes {
+		// headers are stored with the height of the beacon block relaying them
+		currentPortalState.RelayedBTCHeaders[blockHash.String()] = newBTCHeaderState(newHeaders[blockHash], beaconHeight+1)
+	}
+	currentPortalState.BTCHeaderChainTip = newBTCHeaderState(tip, beaconHeight+1)
+	return nil
+}
+
+// verifyPortalExternalTx verifies the proof of a tx on the public blockchain of tokenIDStr against
+// the relayed header chain, returns id of the tx and amounts it pays to each address
+func (blockchain *BlockChain) verifyPortalExternalTx(
+	currentPortalState *CurrentPortalState,
+	db database.DatabaseInterface,
+	tokenIDStr string,
+	proofStr string,
+) (string, map[string]uint64, error) {
+	if tokenIDStr != common.PortalBTCIDStr {
+		return "", nil, fmt.Errorf("there is no external tx verifier for token %s", tokenIDStr)
+	}
+	params := blockchain.config.ChainParams.PortalParams.BTCParams
+	if params == nil {
+		return "", nil, errors.New("btc header relaying is not configured")
+	}
+	proof, err := btc.ParseMerkleProof(proofStr)
+	if err != nil {
+		return "", nil, err
+	}
+	blockHash, err := proof.GetBlockHash()
+	if err != nil {
+		return "", nil, err
+	}
+	getHeader := blockchain.btcHeaderGetter(currentPortalState, db)
+	block, err := getHeader(*blockHash)
+	if err != nil {
+		return "", nil, err
+	}
+	if block == nil {
+		return "", nil, fmt.Errorf("btc block %s was not relayed", proof.BlockHash)
+	}
+	tip, err := blockchain.getBTCHeaderChainTip(currentPortalState)
+	if err != nil {
+		return "", nil, err
+	}
+	if block.Height+metadata.PortalBTCMinConfirmations > tip.Height+1 {
+		return "", nil, fmt.Errorf("btc block %s does not have %d confirmations", proof.BlockHash, metadata.PortalBTCMinConfirmations)
+	}
+	inChain, err := btc.IsInChain(tip, *blockHash, block.Height, metadata.PortalBTCMaxProofDepth, getHeader)
+	if err != nil {
+		return "", nil, err
+	}
+	if !inChain {
+		return "", nil, fmt.Errorf("btc block %s is not in the best chain or is too deep", proof.BlockHash)
+	}
+	tx, err := proof.Verify(block.Header.MerkleRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	txHash := tx.TxHash()
+	return txHash.String(), tx.OutputsByAddress(params), nil
+}
+
+// convertPTokenToPRV converts an amount of pToken to PRV using the spot price of the PRV-pToken pool on PDE
+func convertPTokenToPRV(
+	beaconHeight uint64,
+	currentPDEState *CurrentPDEState,
+	tokenIDStr string,
+	amount uint64,
+) (uint64, error) {
+	if currentPDEState == nil {
+		return 0, errors.New("current PDE state is null")
+	}
+	poolPairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, common.PRVCoinID.String(), tokenIDStr))
+	poolPair, found := currentPDEState.PDEPoolPairs[poolPairKey]
+	if !found || poolPair == nil {
+		return 0, errors.New("there is no PRV pool for the portal token")
+	}
+	prvPoolValue := poolPair.Token1PoolValue
+	tokenPoolValue := poolPair.Token2PoolValue
+	if poolPair.Token1IDStr == tokenIDStr {
+		prvPoolValue = poolPair.Token2PoolValue
+		tokenPoolValue = poolPair.Token1PoolValue
+	}
+	if prvPoolValue == 0 || tokenPoolValue == 0 {
+		return 0, errors.New("the PRV pool for the portal token is empty")
+	}
+	prvAmount := big.NewInt(0)
+	prvAmount.Mul(new(big.Int).SetUint64(amount), new(big.Int).SetUint64(prvPoolValue))
+	prvAmount.Div(prvAmount, new(big.Int).SetUint64(tokenPoolValue))
+	if !prvAmount.IsUint64() {
+		return 0, errors.New("converted PRV amount is out of range")
+	}
+	return prvAmount.Uint64(), nil
+}
+
+// calculateLockedCollateral returns collateral (PRV) that custodians need to lock for a porting amount
+func calculateLockedCollateral(prvAmount uint64, minPercentLockedCollateral uint64) uint64 {
+	lockedAmount := big.NewInt(0)
+	lockedAmount.Mul(new(big.Int).SetUint64(prvAmount), new(big.Int).SetUint64(minPercentLockedCollateral))
+	lockedAmount.Div(lockedAmount, big.NewInt(100))
+	return lockedAmount.Uint64()
+}
+
+type custodianStateWithKey struct {
+	key   string
+	state *lvdb.CustodianState
+}
+
+func sortCustodians(
+	custodianPoolState map[string]*lvdb.CustodianState,
+	less func(a, b *lvdb.CustodianState) bool,
+) []custodianStateWithKey {
+	custodians := make([]custodianStateWithKey, 0, len(custodianPoolState))
+	for key, state := range custodianPoolState {
+		custodians = append(custodians, custodianStateWithKey{key: key, state: state})
+	}
+	sort.Slice(custodians, func(i, j int) bool {
+		if less(custodians[i].state, custodians[j].state) {
+			return true
+		}
+		if less(custodians[j].state, custodians[i].state) {
+			return false
+		}
+		return custodians[i].key < custodians[j].key
+	})
+	return custodians
+}
+
+// pickUpCustodiansForPorting greedily picks custodians with the most free collateral
+// until the whole porting amount is covered, returns nil if the custodian pool can not cover it
+func pickUpCustodiansForPorting(
+	custodianPoolState map[string]*lvdb.CustodianState,
+	tokenIDStr string,
+	portingAmount uint64,
+	lockedCollateral uint64,
+) []*lvdb.MatchingPortingCustodianDetail {
+	sortedCustodians := sortCustodians(custodianPoolState, func(a, b *lvdb.CustodianState) bool {
+		return a.FreeCollateral > b.FreeCollateral
+	})
+	matchedCustodians := []*lvdb.MatchingPortingCustodianDetail{}
+	remainingCollateral := lockedCollateral
+	remainingAmount := portingAmount
+	for _, custodian := range sortedCustodians {
+		if remainingCollateral == 0 || remainingAmount == 0 {
+			break
+		}
+		remoteAddr, found := custodian.state.RemoteAddresses[tokenIDStr]
+		if !found || custodian.state.FreeCollateral == 0 {
+			continue
+		}
+		lockedAmount := custodian.state.FreeCollateral
+		if lockedAmount > remainingCollateral {
+			lockedAmount = remainingCollateral
+		}
+		amount := remainingAmount
+		if lockedAmount < remainingCollateral {
+			ratioAmount := big.NewInt(0)
+			ratioAmount.Mul(new(big.Int).SetUint64(portingAmount), new(big.Int).SetUint64(lockedAmount))
+			ratioAmount.Div(ratioAmount, new(big.Int).SetUint64(lockedCollateral))
+			amount = ratioAmount.Uint64()
+		}
+		if amount == 0 {
+			continue
+		}
+		matchedCustodians = append(matchedCustodians, &lvdb.MatchingPortingCustodianDetail{
+			IncAddress:             custodian.state.IncognitoAddress,
+			RemoteAddress:          remoteAddr,
+			Amount:                 amount,
+			LockedAmountCollateral: lockedAmount,
+		})
+		remainingCollateral -= lockedAmount
+		remainingAmount -= amount
+	}
+	if remainingCollateral > 0 || remainingAmount > 0 {
+		return nil
+	}
+	return matchedCustodians
+}
+
+// pickUpCustodiansForRedeem picks custodians holding the most public tokens until the redeem amount is covered,
+// the unlock amount of each custodian is proportional to its locked collateral, returns nil if it can not be covered
+func pickUpCustodiansForRedeem(
+	custodianPoolState map[string]*lvdb.CustodianState,
+	tokenIDStr string,
+	redeemAmount uint64,
+) []*lvdb.MatchingRedeemCustodianDetail {
+	sortedCustodians := sortCustodians(custodianPoolState, func(a, b *lvdb.CustodianState) bool {
+		return a.HoldingPubTokens[tokenIDStr] > b.HoldingPubTokens[tokenIDStr]
+	})
+	matchedCustodians := []*lvdb.MatchingRedeemCustodianDetail{}
+	remainingAmount := redeemAmount
+	for _, custodian := range sortedCustodians {
+		if remainingAmount == 0 {
+			break
+		}
+		holdingAmount := custodian.state.HoldingPubTokens[tokenIDStr]
+		if holdingAmount == 0 {
+			continue
+		}
+		amount := holdingAmount
+		if amount > remainingAmount {
+			amount = remainingAmount
+		}
+		unlockAmount := big.NewInt(0)
+		unlockAmount.Mul(new(big.Int).SetUint64(custodian.state.LockedAmountCollateral[tokenIDStr]), new(big.Int).SetUint64(amount))
+		unlockAmount.Div(unlockAmount, new(big.Int).SetUint64(holdingAmount))
+		matchedCustodians = append(matchedCustodians, &lvdb.MatchingRedeemCustodianDetail{
+			IncAddress:    custodian.state.IncognitoAddress,
+			RemoteAddress: custodian.state.RemoteAddresses[tokenIDStr],
+			Amount:        amount,
+			UnlockAmount:  unlockAmount.Uint64(),
+		})
+		remainingAmount -= amount
+	}
+	if remainingAmount > 0 {
+		return nil
+	}
+	return matchedCustodians
+}
+
+// portalModule handles custodian deposits, porting and redeem requests of the portal
+type portalModule struct{}
+
+func init() {
+	if err := RegisterStatefulModule(&portalModule{}); err != nil {
+		panic("failed to register portal stateful module")
+	}
+}
+
+func (module *portalModule) Name() string {
+	return "portal"
+}
+
+func (module *portalModule) Priority() int {
+	return portalModulePriority
+}
+
+func (module *portalModule) ActionMetaTypes() []int {
+	return []int{
+		metadata.PortalCustodianDepositMeta,
+		metadata.PortalUserRegisterMeta,
+		metadata.PortalUserRequestPTokenMeta,
+		metadata.PortalRedeemRequestMeta,
+		metadata.PortalRequestUnlockCollateralMeta,
+		metadata.PortalRelayingBTCHeaderMeta,
+	}
+}
+
+func (module *portalModule) InstructionMetaTypes() []int {
+	return []int{
+		metadata.PortalCustodianDepositMeta,
+		metadata.PortalUserRegisterMeta,
+		metadata.PortalUserRequestPTokenMeta,
+		metadata.PortalRedeemRequestMeta,
+		metadata.PortalRequestUnlockCollateralMeta,
+		metadata.PortalLiquidateCustodianMeta,
+		metadata.PortalRelayingBTCHeaderMeta,
+	}
+}
+
+func (module *portalModule) InitState(db database.DatabaseInterface, beaconHeight uint64) (interface{}, error) {
+	return InitCurrentPortalStateFromDB(db, beaconHeight)
+}
+
+func (module *portalModule) BuildInstructions(
+	blockchain *BlockChain,
+	beaconHeight uint64,
+	state interface{},
+	actionsByShardID map[byte][][]string,
+	db database.DatabaseInterface,
+) ([][]string, error) {
+	currentPortalState, ok := state.(*CurrentPortalState)
+	if !ok {
+		return [][]string{}, errors.New("invalid state of portal module")
+	}
+	// collaterals are valued with PDE pool prices of the previous beacon block
+	currentPDEState, err := InitCurrentPDEStateFromDB(db, beaconHeight)
+	if err != nil {
+		Logger.log.Error(err)
+	}
+	return blockchain.handlePortalInsts(
+		beaconHeight, currentPortalState, currentPDEState,
+		actionsByShardID,
+		db,
+	)
+}
+
+func (module *portalModule) ProcessInstruction(blockchain *BlockChain, beaconHeight uint64, inst []string, state interface{}) error {
+	currentPortalState, ok := state.(*CurrentPortalState)
+	if !ok {
+		return errors.New("invalid state of portal module")
+	}
+	if len(inst) < 4 {
+		return nil // Not error, just not Portal instruction
+	}
+	switch inst[0] {
+	case strconv.Itoa(metadata.PortalCustodianDepositMeta):
+		return blockchain.processPortalCustodianDeposit(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalUserRegisterMeta):
+		return blockchain.processPortalPortingRequest(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalUserRequestPTokenMeta):
+		return blockchain.processPortalReqPTokens(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalRedeemRequestMeta):
+		return blockchain.processPortalRedeemRequest(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalRequestUnlockCollateralMeta):
+		return blockchain.processPortalReqUnlockCollateral(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalLiquidateCustodianMeta):
+		return blockchain.processPortalLiquidateCustodian(beaconHeight, inst, currentPortalState)
+	case strconv.Itoa(metadata.PortalRelayingBTCHeaderMeta):
+		return blockchain.processPortalRelayingBTCHeader(beaconHeight, inst, currentPortalState)
+	}
+	return nil
+}
+
+func (module *portalModule) StoreState(db database.DatabaseInterface, beaconHeight uint64, state interface{}) error {
+	currentPortalState, ok := state.(*CurrentPortalState)
+	if !ok {
+		return errors.New("invalid state of portal module")
+	}
+	return storePortalStateToDB(db, beaconHeight, currentPortalState)
+}
+
+// BackupState does nothing since portal state of the previous beacon height is kept
+func (module *portalModule) BackupState(db database.DatabaseInterface, block *BeaconBlock) error {
+	return nil
+}
+
+// RestoreState removes portal state stored at the height of the reverted beacon block,
+// releases external txs used as proofs in it and removes btc headers first relayed in it
+func (module *portalModule) RestoreState(db database.DatabaseInterface, block *BeaconBlock) error {
+	err := deleteRecordsByPrefixes(
+		db,
+		block.Header.Height,
+		[][]byte{
+			lvdb.PortalCustodianStatePrefix,
+			lvdb.PortalWaitingPortingRequestsPrefix,
+			lvdb.PortalWaitingRedeemRequestsPrefix,
+			lvdb.PortalBTCHeaderChainTipPrefix,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, inst := range block.Body.Instructions {
+		if len(inst) < 4 {
+			continue
+		}
+		switch {
+		case inst[0] == strconv.Itoa(metadata.PortalUserRequestPTokenMeta) && inst[2] == common.PortalReqPTokensAcceptedChainStatus:
+			var reqPTokensContent metadata.PortalRequestPTokensContent
+			if err := json.Unmarshal([]byte(inst[3]), &reqPTokensContent); err != nil {
+				return err
+			}
+			err = db.Delete(lvdb.BuildPortalExternalTxKey(reqPTokensContent.TokenID, reqPTokensContent.ExternalTxID))
+		case inst[0] == strconv.Itoa(metadata.PortalRequestUnlockCollateralMeta) && inst[2] == common.PortalReqUnlockCollateralAcceptedChainStatus:
+			var unlockContent metadata.PortalRequestUnlockCollateralContent
+			if err := json.Unmarshal([]byte(inst[3]), &unlockContent); err != nil {
+				return err
+			}
+			err = db.Delete(lvdb.BuildPortalExternalTxKey(unlockContent.TokenID, unlockContent.ExternalTxID))
+		case inst[0] == strconv.Itoa(metadata.PortalRelayingBTCHeaderMeta) && inst[2] == common.PortalRelayingBTCHeaderAcceptedChainStatus:
+			var relayingHeaderContent metadata.PortalRelayingBTCHeaderContent
+			if err := json.Unmarshal([]byte(inst[3]), &relayingHeaderContent); err != nil {
+				return err
+			}
+			err = deleteBTCHeadersRelayedAt(db, block.Header.Height, relayingHeaderContent.Headers)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteBTCHeadersRelayedAt removes the headers which were first relayed at the beacon height,
+// headers relayed before it are kept
+func deleteBTCHeadersRelayedAt(db database.DatabaseInterface, beaconHeight uint64, headerStrs []string) error {
+	for _, headerStr := range headerStrs {
+		header, err := btc.ParseBlockHeaderHex(headerStr)
+		if err != nil {
+			return err
+		}
+		btcHeaderKey := lvdb.BuildPortalBTCHeaderKey(header.BlockHash().String())
+		found, err := db.HasValue(btcHeaderKey)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		btcHeaderBytes, err := db.Get(btcHeaderKey)
+		if err != nil {
+			return err
+		}
+		var btcHeader lvdb.BTCHeaderState
+		if err := json.Unmarshal(btcHeaderBytes, &btcHeader); err != nil {
+			return err
+		}
+		if btcHeader.BeaconHeight != beaconHeight {
+			continue
+		}
+		if err := db.Delete(btcHeaderKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
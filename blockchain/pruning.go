@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinPruneBlockEpochs is the smallest number of epochs of blocks kept by a pruning node,
+	// shard blocks refer to beacon blocks of the current and previous epoch when being processed
+	MinPruneBlockEpochs = 2
+	// maximum number of blocks pruned after inserting one block,
+	// keeps block insertion fast when pruning is turned on for a database which has never been pruned
+	maxPruneBlocksPerRound = 100
+)
+
+// IsPruningEnabled returns true when the node discards old block bodies and transaction indexes
+func (blockchain *BlockChain) IsPruningEnabled() bool {
+	return blockchain.config.PruneBlockEpochs > 0
+}
+
+func (blockchain *BlockChain) getPruneBlockEpochs() uint64 {
+	if blockchain.config.PruneBlockEpochs > 0 && blockchain.config.PruneBlockEpochs < MinPruneBlockEpochs {
+		return MinPruneBlockEpochs
+	}
+	return blockchain.config.PruneBlockEpochs
+}
+
+// GetPrunedHeight returns the highest block height of a chain whose body is not stored anymore, 0 if nothing is pruned
+func (blockchain *BlockChain) GetPrunedHeight(isBeacon bool, shardID byte) uint64 {
+	prunedHeight, err := blockchain.config.DataBase.FetchPrunedHeight(isBeacon, shardID)
+	if err != nil {
+		Logger.log.Error(err)
+		return 0
+	}
+	return prunedHeight
+}
+
+// getPruneBeaconHeight returns the beacon height below which blocks can be pruned.
+// Shards synced by this node must still be able to process beacon blocks after their current beacon height,
+// shards which have never been synced (height 1) are ignored, a pruning node can not start syncing a new shard from genesis.
+func (blockchain *BlockChain) getPruneBeaconHeight() uint64 {
+	retainedHeight := blockchain.getPruneBlockEpochs() * blockchain.config.ChainParams.Epoch
+	beaconHeight := blockchain.BestState.Beacon.BeaconHeight
+	for _, shardBestState := range blockchain.BestState.Shard {
+		if shardBestState == nil || shardBestState.ShardHeight <= 1 {
+			continue
+		}
+		if shardBestState.BeaconHeight < beaconHeight {
+			beaconHeight = shardBestState.BeaconHeight
+		}
+	}
+	if beaconHeight <= retainedHeight {
+		return 0
+	}
+	return beaconHeight - retainedHeight
+}
+
+// pruneBeaconBlocks deletes bodies of beacon blocks below the prune beacon height
+func (blockchain *BlockChain) pruneBeaconBlocks() error {
+	pruneBeaconHeight := blockchain.getPruneBeaconHeight()
+	prunedHeight, err := blockchain.config.DataBase.FetchPrunedHeight(true, 0)
+	if err != nil {
+		return err
+	}
+	height := prunedHeight + 1
+	for ; height < pruneBeaconHeight && height <= prunedHeight+maxPruneBlocksPerRound; height++ {
+		blockHash, err := blockchain.config.DataBase.GetBeaconBlockHashByIndex(height)
+		if err != nil {
+			return err
+		}
+		if err := blockchain.config.DataBase.PruneBlock(blockHash); err != nil {
+			return err
+		}
+	}
+	if height-1 == prunedHeight {
+		return nil
+	}
+	return blockchain.config.DataBase.StorePrunedHeight(true, 0, height-1)
+}
+
+// pruneShardBlocks deletes bodies and transaction indexes of shard blocks
+// which refer to a beacon height below the prune beacon height
+func (blockchain *BlockChain) pruneShardBlocks(shardID byte) error {
+	pruneBeaconHeight := blockchain.getPruneBeaconHeight()
+	prunedHeight, err := blockchain.config.DataBase.FetchPrunedHeight(false, shardID)
+	if err != nil {
+		return err
+	}
+	height := prunedHeight + 1
+	for ; height < blockchain.BestState.Shard[shardID].ShardHeight && height <= prunedHeight+maxPruneBlocksPerRound; height++ {
+		blockHash, err := blockchain.config.DataBase.GetBlockByIndex(height, shardID)
+		if err != nil {
+			return err
+		}
+		block, _, err := blockchain.GetShardBlockByHash(blockHash)
+		if err != nil {
+			return err
+		}
+		if block.Header.BeaconHeight >= pruneBeaconHeight {
+			break
+		}
+		for _, tx := range block.Body.Transactions {
+			if err := blockchain.config.DataBase.DeleteTransactionIndex(*tx.Hash()); err != nil {
+				return err
+			}
+		}
+		if err := blockchain.config.DataBase.PruneBlock(blockHash); err != nil {
+			return err
+		}
+	}
+	if height-1 == prunedHeight {
+		return nil
+	}
+	return blockchain.config.DataBase.StorePrunedHeight(false, shardID, height-1)
+}
+
+// pruneChain is called after a block is inserted, failing to prune never fails block insertion
+func (blockchain *BlockChain) pruneChain(isBeacon bool, shardID byte) {
+	if !blockchain.IsPruningEnabled() {
+		return
+	}
+	var err error
+	if isBeacon {
+		err = blockchain.pruneBeaconBlocks()
+	} else {
+		err = blockchain.pruneShardBlocks(shardID)
+	}
+	if err != nil {
+		Logger.log.Error(NewBlockChainError(PruneBlockError, err))
+	}
+}
+
+// getBlockPrunedError returns a BlockPrunedError when the block at height of a chain has been pruned, nil otherwise
+func (blockchain *BlockChain) getBlockPrunedError(isBeacon bool, shardID byte, height uint64, hash *common.Hash) error {
+	prunedHeight := blockchain.GetPrunedHeight(isBeacon, shardID)
+	if height == 0 || height > prunedHeight {
+		return nil
+	}
+	chainName := common.BeaconChainKey
+	if !isBeacon {
+		chainName = common.GetShardChainKey(shardID)
+	}
+	if hash != nil {
+		return NewBlockChainError(BlockPrunedError, errors.Errorf("%+v block %+v at height %+v is pruned, this node only keeps blocks above height %+v", chainName, hash.String(), height, prunedHeight))
+	}
+	return NewBlockChainError(BlockPrunedError, errors.Errorf("%+v block at height %+v is pruned, this node only keeps blocks above height %+v", chainName, height, prunedHeight))
+}
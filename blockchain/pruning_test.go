@@ -0,0 +1,99 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneBlocks(t *testing.T) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_pruning_")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dbPath)
+	db, err := database.Open("leveldb", dbPath)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	params := ChainTestParam
+	params.Epoch = 5
+	bc := &BlockChain{
+		config: Config{DataBase: db, ChainParams: &params, PruneBlockEpochs: 1},
+		BestState: &BestState{
+			Beacon: &BeaconBestState{BeaconHeight: 30},
+			Shard:  map[byte]*ShardBestState{0: {ShardHeight: 20, BeaconHeight: 25}, 1: {ShardHeight: 1}},
+		},
+	}
+	for height := uint64(1); height <= 30; height++ {
+		beaconBlock := NewBeaconBlock()
+		beaconBlock.Header.Height = height
+		assert.Nil(t, db.StoreBeaconBlock(beaconBlock, beaconBlock.Header.Hash(), nil))
+		assert.Nil(t, db.StoreBeaconBlockIndex(beaconBlock.Header.Hash(), height))
+	}
+	// shard block at height h is produced on top of beacon height h+5
+	shardBlockHashes := make(map[uint64]common.Hash)
+	for height := uint64(1); height <= 20; height++ {
+		shardBlock := *ChainTestParam.GenesisShardBlock
+		shardBlock.Header.Height = height
+		shardBlock.Header.BeaconHeight = height + 5
+		shardBlock.Header.BeaconHash = common.HashH([]byte("beacon"))
+		if height > 1 {
+			shardBlock.ValidationData = "{}"
+			shardBlock.Header.PreviousBlockHash = shardBlockHashes[height-1]
+			shardBlock.Header.CommitteeRoot = common.HashH([]byte("committee"))
+		}
+		shardBlockHashes[height] = shardBlock.Header.Hash()
+		assert.Nil(t, db.StoreShardBlock(shardBlock, shardBlockHashes[height], 0, nil))
+		assert.Nil(t, db.StoreShardBlockIndex(shardBlockHashes[height], height, 0, nil))
+	}
+	txHash := common.HashH([]byte("tx"))
+	if len(ChainTestParam.GenesisShardBlock.Body.Transactions) > 0 {
+		txHash = *ChainTestParam.GenesisShardBlock.Body.Transactions[0].Hash()
+	}
+	assert.Nil(t, db.StoreTransactionIndex(txHash, shardBlockHashes[5], 0, nil))
+
+	// pruning is disabled
+	bc.config.PruneBlockEpochs = 0
+	bc.pruneChain(true, 0)
+	assert.Equal(t, uint64(0), bc.GetPrunedHeight(true, 0))
+
+	// blocks referring to beacon height below min(30, 25) - 2 epochs * 5 = 15 are pruned,
+	// shard 1 has never been synced and does not hold beacon pruning back
+	bc.config.PruneBlockEpochs = 1
+	bc.pruneChain(true, 0)
+	bc.pruneChain(false, 0)
+	assert.Equal(t, uint64(14), bc.GetPrunedHeight(true, 0))
+	assert.Equal(t, uint64(9), bc.GetPrunedHeight(false, 0))
+
+	isBlockPrunedError := func(err error) bool {
+		bcErr, ok := err.(*BlockChainError)
+		return ok && bcErr.Code == ErrCodeMessage[BlockPrunedError].Code
+	}
+	_, err = bc.GetBeaconBlockByHeight(14)
+	assert.True(t, isBlockPrunedError(err))
+	beaconBlock, err := bc.GetBeaconBlockByHeight(15)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(15), beaconBlock.Header.Height)
+	_, err = bc.GetShardBlockByHeight(9, 0)
+	assert.True(t, isBlockPrunedError(err))
+	_, _, err = bc.GetShardBlockByHash(shardBlockHashes[1])
+	assert.True(t, isBlockPrunedError(err))
+	shardBlock, err := bc.GetShardBlockByHeight(10, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), shardBlock.Header.Height)
+	_, _, err = db.GetTransactionIndexById(txHash)
+	assert.NotNil(t, err)
+
+	// nothing more to prune until the chain moves forward
+	bc.pruneChain(true, 0)
+	assert.Equal(t, uint64(14), bc.GetPrunedHeight(true, 0))
+	bc.BestState.Beacon.BeaconHeight = 40
+	bc.BestState.Shard[0].BeaconHeight = 40
+	bc.pruneChain(true, 0)
+	bc.pruneChain(false, 0)
+	assert.Equal(t, uint64(29), bc.GetPrunedHeight(true, 0))
+	assert.Equal(t, uint64(19), bc.GetPrunedHeight(false, 0))
+}
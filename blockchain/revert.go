@@ -88,6 +88,7 @@ func (blockchain *BlockChain) revertShardBestState(shardID byte) error {
 	}
 
 	SetBestStateShard(shardID, &shardBestState)
+	blockchain.setShardBestView(shardID, &shardBestState)
 
 	blockchain.config.ShardPool[shardID].RevertShardPool(shardBestState.ShardHeight)
 	for sid, height := range shardBestState.BestCrossShard {
@@ -123,6 +124,11 @@ func (blockchain *BlockChain) revertShardState(shardID byte) error {
 			return NewBlockChainError(RevertStateError, err)
 		}
 	}
+	if blockchain.IsTxHistoryIndexEnabled() {
+		if err := blockchain.deleteTxHistory(currentBestStateBlk); err != nil {
+			return NewBlockChainError(RevertStateError, err)
+		}
+	}
 
 	if err := blockchain.restoreFromTxViewPoint(currentBestStateBlk); err != nil {
 		return NewBlockChainError(RevertStateError, err)
@@ -713,6 +719,7 @@ func (blockchain *BlockChain) revertBeaconBestState() error {
 		return NewBlockChainError(RevertStateError, errors.New("can't revert same beststate"))
 	}
 	SetBeaconBestState(&beaconBestState)
+	blockchain.setBeaconBestView(&beaconBestState)
 
 	blockchain.config.BeaconPool.RevertBeconPool(beaconBestState.BeaconHeight)
 	for sid, height := range blockchain.BestState.Beacon.GetBestShardHeight() {
@@ -791,6 +798,10 @@ func (blockchain *BlockChain) revertBeaconState() error {
 			}
 		}
 	}
+	err = blockchain.restoreStatefulStates(&currentBestStateBlk)
+	if err != nil {
+		return err
+	}
 	err = blockchain.config.DataBase.DeleteBeaconBlock(currentBestStateBlk.Header.Hash(), currentBestStateBlk.Header.Height)
 	if err != nil {
 		return err
@@ -849,5 +860,5 @@ func (blockchain *BlockChain) BackupCurrentBeaconState(block *BeaconBlock) error
 			}
 		}
 	}
-	return nil
+	return blockchain.backupStatefulStates(block)
 }
@@ -64,7 +64,7 @@ func (chain *ShardChain) GetLastProposerIndex() int {
 func (chain *ShardChain) CreateNewBlock(round int) (common.BlockInterface, error) {
 	chain.lock.Lock()
 	defer chain.lock.Unlock()
-	start := time.Now()
+	start := chain.Blockchain.now()
 	Logger.log.Infof("Begin Create New Block %+v", start)
 	beaconHeight := chain.Blockchain.Synker.States.ClosestState.ClosestBeaconState
 	if chain.Blockchain.BestState.Beacon.BeaconHeight < beaconHeight {
@@ -161,3 +161,7 @@ func (chain *ShardChain) UnmarshalBlock(blockString []byte) (common.BlockInterfa
 func (chain *ShardChain) ValidatePreSignBlock(block common.BlockInterface) error {
 	return chain.Blockchain.VerifyPreSignShardBlock(block.(*ShardBlock), chain.BestState.ShardID)
 }
+
+func (chain *ShardChain) AddDoubleSignEvidence(evidence *DoubleSignEvidence) error {
+	return chain.Blockchain.AddDoubleSignEvidence(evidence)
+}
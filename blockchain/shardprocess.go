@@ -77,12 +77,20 @@ func (blockchain *BlockChain) InsertShardBlock(shardBlock *ShardBlock, isValidat
 	blockchain.chainLock.Lock()
 	defer blockchain.chainLock.Unlock()
 	shardID := shardBlock.Header.ShardID
-	blockHash := shardBlock.Header.Hash()
 
 	shardLock := &blockchain.BestState.Shard[shardID].lock
 	shardLock.Lock()
 	defer shardLock.Unlock()
+	return blockchain.insertShardBlock(shardBlock, isValidated)
+}
 
+func (blockchain *BlockChain) insertShardBlock(shardBlock *ShardBlock, isValidated bool) error {
+	shardID := shardBlock.Header.ShardID
+	blockHash := shardBlock.Header.Hash()
+	// the branch of the parent of shard block wins over the best block
+	if err := blockchain.switchShardView(shardBlock); err != nil {
+		return err
+	}
 	if shardBlock.Header.Height != GetBestStateShard(shardID).ShardHeight+1 {
 		return errors.New("Not expected height")
 	}
@@ -205,6 +213,8 @@ func (blockchain *BlockChain) InsertShardBlock(shardBlock *ShardBlock, isValidat
 		}
 		return err
 	}
+	blockchain.addShardView(shardBlock)
+	blockchain.pruneChain(false, shardID)
 	go blockchain.config.PubSubManager.PublishMessage(pubsub.NewMessage(pubsub.NewShardblockTopic, shardBlock))
 	go blockchain.config.PubSubManager.PublishMessage(pubsub.NewMessage(pubsub.ShardBeststateTopic, blockchain.BestState.Shard[shardID]))
 	//shardIDForMetric := strconv.Itoa(int(shardBlock.Header.ShardID))
@@ -474,6 +484,9 @@ func (blockchain *BlockChain) verifyPreProcessingShardBlock(shardBlock *ShardBlo
 	if err != nil {
 		return NewBlockChainError(ResponsedTransactionWithMetadataError, err)
 	}
+	if err := blockchain.BestState.Shard[shardID].verifyForfeitedStakingNotReturned(shardBlock, beaconBlocks); err != nil {
+		return err
+	}
 	// Get cross shard shardBlock from pool
 	// @NOTICE: COMMENT to bypass verify cross shard shardBlock
 	if isPreSign {
@@ -695,6 +708,8 @@ func (shardBestState *ShardBestState) updateShardBestState(blockchain *BlockChai
 	for stakePublicKey, txHash := range stakingTx {
 		shardBestState.StakingTx[stakePublicKey] = txHash
 	}
+	// staking amount of double signer is forfeited, it is never returned
+	shardBestState.processDoubleSigners(beaconBlocks)
 	err = shardBestState.processShardBlockInstruction(blockchain, shardBlock)
 	if err != nil {
 		return err
@@ -961,6 +976,12 @@ func (blockchain *BlockChain) processStoreShardBlockAndUpdateDatabase(shardBlock
 		return NewBlockChainError(UpdateBridgeIssuanceStatusError, err)
 	}
 
+	if blockchain.IsTxHistoryIndexEnabled() {
+		if err := blockchain.storeTxHistory(shardBlock, &batchPutData); err != nil {
+			return NewBlockChainError(StoreTxHistoryError, err)
+		}
+	}
+
 	// call FeeEstimator for processing
 	if feeEstimator, ok := blockchain.config.FeeEstimator[shardBlock.Header.ShardID]; ok {
 		err := feeEstimator.RegisterBlock(shardBlock)
@@ -154,7 +154,7 @@ func (blockGenerator *BlockGenerator) NewBlockShard(shardID byte, round int, cro
 	// })
 	// Get Transaction for new block
 	// // startStep = time.Now()
-	blockCreationLeftOver := blockGenerator.chain.BestState.Shard[shardID].BlockMaxCreateTime.Nanoseconds() - time.Since(start).Nanoseconds()
+	blockCreationLeftOver := blockGenerator.chain.BestState.Shard[shardID].BlockMaxCreateTime.Nanoseconds() - blockGenerator.chain.now().Sub(start).Nanoseconds()
 	txsToAddFromBlock, err := blockGenerator.getTransactionForNewBlock(&tempPrivateKey, shardID, blockGenerator.chain.config.DataBase, beaconBlocks, blockCreationLeftOver, beaconHeight)
 	if err != nil {
 		return nil, err
@@ -414,6 +414,7 @@ func (blockGenerator *BlockGenerator) buildResponseTxsFromBeaconInstructions(bea
 	responsedTxs := []metadata.Transaction{}
 	responsedHashTxs := []common.Hash{} // capture hash of responsed tx
 	errorInstructions := [][]string{}   // capture error instruction -> which instruction can not create tx
+	shardBestState := blockGenerator.chain.BestState.Shard[shardID]
 	for _, beaconBlock := range beaconBlocks {
 		autoStaking := make(map[string]bool)
 		autoStakingBytes, err := blockGenerator.chain.config.DataBase.FetchAutoStakingByHeight(beaconBlock.Header.Height)
@@ -431,6 +432,10 @@ func (blockGenerator *BlockGenerator) buildResponseTxsFromBeaconInstructions(bea
 					if _, ok := autoStaking[outPublicKeys]; ok {
 						continue
 					}
+					// staking amount of double signer is forfeited
+					if shardBestState.isStakingForfeited(outPublicKeys, beaconBlocks) {
+						continue
+					}
 					tx, err := blockGenerator.buildReturnStakingAmountTx(outPublicKeys, producerPrivateKey)
 					if err != nil {
 						Logger.log.Error(err)
@@ -472,6 +477,14 @@ func (blockGenerator *BlockGenerator) buildResponseTxsFromBeaconInstructions(bea
 				if len(l) >= 4 {
 					newTx, err = blockGenerator.buildPDETradeIssuanceTx(l[2], l[3], producerPrivateKey, shardID)
 				}
+			case metadata.PDEMultiHopTradeRequestMeta:
+				if len(l) >= 4 {
+					newTx, err = blockGenerator.buildPDEMultiHopTradeIssuanceTx(l[2], l[3], producerPrivateKey, shardID)
+				}
+			case metadata.PDELimitOrderRequestMeta:
+				if len(l) >= 4 && (l[2] == common.PDELimitOrderFilledChainStatus || l[2] == common.PDELimitOrderRefundChainStatus) {
+					newTx, err = blockGenerator.buildPDELimitOrderIssuanceTx(l[2], l[3], producerPrivateKey, shardID)
+				}
 			case metadata.PDEWithdrawalRequestMeta:
 				if len(l) >= 4 && l[2] == common.PDEWithdrawalAcceptedChainStatus {
 					newTx, err = blockGenerator.buildPDEWithdrawalTx(l[3], producerPrivateKey, shardID)
@@ -484,6 +497,22 @@ func (blockGenerator *BlockGenerator) buildResponseTxsFromBeaconInstructions(bea
 						newTx, err = blockGenerator.buildPDEMatchedNReturnedContributionTx(l[3], producerPrivateKey, shardID)
 					}
 				}
+			case metadata.PortalUserRegisterMeta:
+				if len(l) >= 4 && l[2] == common.PortalPortingRequestRejectedChainStatus {
+					newTx, err = blockGenerator.buildPortalRefundPortingFeeTx(l[3], producerPrivateKey, shardID)
+				}
+			case metadata.PortalUserRequestPTokenMeta:
+				if len(l) >= 4 && l[2] == common.PortalReqPTokensAcceptedChainStatus {
+					newTx, err = blockGenerator.buildPortalAcceptedRequestPTokensTx(l[3], producerPrivateKey, shardID)
+				}
+			case metadata.PortalRedeemRequestMeta:
+				if len(l) >= 4 && l[2] == common.PortalRedeemRequestRejectedChainStatus {
+					newTx, err = blockGenerator.buildPortalRejectedRedeemRequestTx(l[3], producerPrivateKey, shardID)
+				}
+			case metadata.PortalLiquidateCustodianMeta:
+				if len(l) >= 4 && l[2] == common.PortalLiquidateCustodianSuccessChainStatus {
+					newTx, err = blockGenerator.buildPortalLiquidateCustodianResponseTx(l[3], producerPrivateKey, shardID)
+				}
 
 			default:
 				continue
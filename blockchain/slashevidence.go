@@ -0,0 +1,386 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/bridgesig"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/pkg/errors"
+)
+
+/*
+Double signing (equivocation) evidence:
+- A validator who proposes or votes for two different blocks at the same height and round of a chain is punished
+- Evidence holds the headers of both blocks with the signatures of the validator on them, so it is verified by itself:
+  propose evidence carries the producer signatures of block hashes, vote evidence carries the vote signatures
+  (BLS, BRI and the confirmation signing them with the block hash)
+- Signer must be in the committee of the chain at the height of the blocks, as stored by beacon: beacon committee
+  after the previous beacon block, shard committee at the beacon height of the shard blocks
+- Consensus collects evidence and gossips it, beacon producer includes it as instruction
+["doublesign" "{evidence json}"], every node verifies it before accepting the beacon block
+- Signer is put into producers black list for doubleSignPunishedEpoches, loses auto staking
+and its staking tx is forfeited: shards keep the signer in their best state until it is swapped out,
+then drop its staking tx instead of returning the staking amount
+- Evidence older than one epoch of blocks of its chain is expired
+*/
+
+const (
+	DoubleProposeEvidence = "propose"
+	DoubleVoteEvidence    = "vote"
+)
+
+const doubleSignPunishedEpoches = uint8(math.MaxUint8)
+
+type DoubleSignSignature struct {
+	Sig []byte // producer signature of block hash, or vote confirmation
+	BLS []byte `json:",omitempty"`
+	BRI []byte `json:",omitempty"`
+}
+
+type DoubleSignEvidence struct {
+	Type       string
+	ShardID    int    // shard of conflicting blocks, -1 for beacon
+	Signer     string // base58 committee public key
+	Headers    [2]json.RawMessage
+	Signatures [2]DoubleSignSignature
+}
+
+type doubleSignHeader struct {
+	hash     common.Hash
+	height   uint64
+	round    int
+	producer string
+	// beacon height of the stored committee which signs the block
+	committeeHeight uint64
+}
+
+func (evidence *DoubleSignEvidence) parseHeader(data json.RawMessage) (*doubleSignHeader, error) {
+	if evidence.ShardID == -1 {
+		header := BeaconHeader{}
+		if err := json.Unmarshal(data, &header); err != nil {
+			return nil, err
+		}
+		if header.Height == 0 {
+			return nil, errors.New("beacon block at height 0")
+		}
+		return &doubleSignHeader{hash: header.Hash(), height: header.Height, round: header.Round, producer: header.Producer, committeeHeight: header.Height - 1}, nil
+	}
+	header := ShardHeader{}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if int(header.ShardID) != evidence.ShardID {
+		return nil, fmt.Errorf("expect header of shard %+v but get shard %+v", evidence.ShardID, header.ShardID)
+	}
+	return &doubleSignHeader{hash: header.Hash(), height: header.Height, round: header.Round, producer: header.Producer, committeeHeight: header.BeaconHeight}, nil
+}
+
+// Verify - check both blocks are different blocks at the same height and round signed by signer, return their height
+func (evidence *DoubleSignEvidence) Verify() (uint64, error) {
+	headers, err := evidence.verify()
+	if err != nil {
+		return 0, err
+	}
+	return headers[0].height, nil
+}
+
+func (evidence *DoubleSignEvidence) verify() ([2]*doubleSignHeader, error) {
+	headers := [2]*doubleSignHeader{}
+	if evidence.Type != DoubleProposeEvidence && evidence.Type != DoubleVoteEvidence {
+		return headers, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("unknown evidence type %+v", evidence.Type))
+	}
+	if evidence.ShardID < -1 || evidence.ShardID > math.MaxUint8 {
+		return headers, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("invalid shard %+v", evidence.ShardID))
+	}
+	signerKey := incognitokey.CommitteePublicKey{}
+	if err := signerKey.FromBase58(evidence.Signer); err != nil {
+		return headers, NewBlockChainError(DoubleSignEvidenceError, err)
+	}
+	for i := range evidence.Headers {
+		header, err := evidence.parseHeader(evidence.Headers[i])
+		if err != nil {
+			return headers, NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+		headers[i] = header
+	}
+	if headers[0].hash.IsEqual(&headers[1].hash) {
+		return headers, NewBlockChainError(DoubleSignEvidenceError, errors.New("blocks are the same"))
+	}
+	if headers[0].height != headers[1].height || headers[0].round != headers[1].round {
+		return headers, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("blocks are at height %+v round %+v and height %+v round %+v", headers[0].height, headers[0].round, headers[1].height, headers[1].round))
+	}
+	for i, header := range headers {
+		signature := evidence.Signatures[i]
+		data := header.hash.GetBytes()
+		if evidence.Type == DoubleProposeEvidence {
+			if header.producer != evidence.Signer {
+				return headers, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("block %+v is produced by %+v", header.hash.String(), header.producer))
+			}
+		} else {
+			data = append(data, signature.BLS...)
+			data = append(data, signature.BRI...)
+			data = common.HashB(data)
+		}
+		ok, err := bridgesig.Verify(signerKey.MiningPubKey[common.BridgeConsensus], data, signature.Sig)
+		if err != nil {
+			return headers, NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+		if !ok {
+			return headers, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("invalid signature of block %+v", header.hash.String()))
+		}
+	}
+	return headers, nil
+}
+
+// verifyWithBeaconBestState - verify evidence, check it is not expired, i.e. its blocks are at most one epoch behind
+// the best block of its chain known by beacon best state, and its signer is in the committee signing both blocks
+func (evidence *DoubleSignEvidence) verifyWithBeaconBestState(beaconBestState *BeaconBestState, epoch uint64, db database.DatabaseInterface) error {
+	headers, err := evidence.verify()
+	if err != nil {
+		return err
+	}
+	height := headers[0].height
+	bestHeight := beaconBestState.BeaconHeight
+	if evidence.ShardID != -1 {
+		bestHeight = beaconBestState.BestShardHeight[byte(evidence.ShardID)]
+	}
+	if height+epoch < bestHeight {
+		return NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("evidence at height %+v is expired, best height %+v", height, bestHeight))
+	}
+	for _, header := range headers {
+		if err := evidence.verifySignerInCommittee(db, header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySignerInCommittee - check signer is in the committee of the chain of evidence at the committee height of header
+func (evidence *DoubleSignEvidence) verifySignerInCommittee(db database.DatabaseInterface, header *doubleSignHeader) error {
+	committee := []incognitokey.CommitteePublicKey{}
+	if evidence.ShardID == -1 {
+		data, err := db.FetchBeaconCommitteeByHeight(header.committeeHeight)
+		if err != nil {
+			return NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+		if err := json.Unmarshal(data, &committee); err != nil {
+			return NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+	} else {
+		data, err := db.FetchShardCommitteeByHeight(header.committeeHeight)
+		if err != nil {
+			return NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+		shardCommittees := make(map[byte][]incognitokey.CommitteePublicKey)
+		if err := json.Unmarshal(data, &shardCommittees); err != nil {
+			return NewBlockChainError(DoubleSignEvidenceError, err)
+		}
+		committee = shardCommittees[byte(evidence.ShardID)]
+	}
+	committeeStr, err := incognitokey.CommitteeKeyListToString(committee)
+	if err != nil {
+		return NewBlockChainError(DoubleSignEvidenceError, err)
+	}
+	if common.IndexOfStr(evidence.Signer, committeeStr) == -1 {
+		return NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("%+v is not in committee of block %+v at beacon height %+v", evidence.Signer, header.hash.String(), header.committeeHeight))
+	}
+	return nil
+}
+
+func NewDoubleSignInstruction(evidence *DoubleSignEvidence) ([]string, error) {
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		return nil, err
+	}
+	return []string{DoubleSignAction, string(data)}, nil
+}
+
+func ParseDoubleSignInstruction(inst []string) (*DoubleSignEvidence, error) {
+	if len(inst) != 2 || inst[0] != DoubleSignAction {
+		return nil, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("invalid instruction %+v", inst))
+	}
+	evidence := &DoubleSignEvidence{}
+	if err := json.Unmarshal([]byte(inst[1]), evidence); err != nil {
+		return nil, NewBlockChainError(DoubleSignEvidenceError, err)
+	}
+	return evidence, nil
+}
+
+// doubleSignEvidencePool - evidence waiting to be included in beacon block, one evidence per signer
+type doubleSignEvidencePool struct {
+	lock      sync.Mutex
+	evidences map[string]*DoubleSignEvidence
+}
+
+// AddDoubleSignEvidence - verify evidence collected by consensus and keep it until a beacon block includes it
+func (blockchain *BlockChain) AddDoubleSignEvidence(evidence *DoubleSignEvidence) error {
+	if err := evidence.verifyWithBeaconBestState(blockchain.BestState.Beacon, blockchain.config.ChainParams.Epoch, blockchain.config.DataBase); err != nil {
+		return err
+	}
+	pool := &blockchain.doubleSignEvidences
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if pool.evidences == nil {
+		pool.evidences = make(map[string]*DoubleSignEvidence)
+	}
+	if _, ok := pool.evidences[evidence.Signer]; !ok {
+		Logger.log.Infof("Double sign evidence of %+v at shard %+v is added", evidence.Signer, evidence.ShardID)
+		pool.evidences[evidence.Signer] = evidence
+	}
+	return nil
+}
+
+// GetDoubleSignEvidences - evidence in pool sorted by signer
+func (blockchain *BlockChain) GetDoubleSignEvidences() []*DoubleSignEvidence {
+	pool := &blockchain.doubleSignEvidences
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	signers := []string{}
+	for signer := range pool.evidences {
+		signers = append(signers, signer)
+	}
+	sort.Strings(signers)
+	result := []*DoubleSignEvidence{}
+	for _, signer := range signers {
+		result = append(result, pool.evidences[signer])
+	}
+	return result
+}
+
+// buildDoubleSignInstructions - instructions of unexpired evidence in pool for a new beacon block, expired evidence is removed
+func (blockchain *BlockChain) buildDoubleSignInstructions(beaconBestState *BeaconBestState) [][]string {
+	instructions := [][]string{}
+	for _, evidence := range blockchain.GetDoubleSignEvidences() {
+		if err := evidence.verifyWithBeaconBestState(beaconBestState, blockchain.config.ChainParams.Epoch, blockchain.config.DataBase); err != nil {
+			Logger.log.Error(err)
+			blockchain.removeDoubleSignEvidences([]string{evidence.Signer})
+			continue
+		}
+		inst, err := NewDoubleSignInstruction(evidence)
+		if err != nil {
+			Logger.log.Error(err)
+			continue
+		}
+		instructions = append(instructions, inst)
+	}
+	return instructions
+}
+
+// verifyDoubleSignInstructions - verify double sign instructions of a beacon block, return them in order of block
+func (blockchain *BlockChain) verifyDoubleSignInstructions(beaconBestState *BeaconBestState, beaconBlock *BeaconBlock) ([][]string, error) {
+	instructions := [][]string{}
+	signers := make(map[string]bool)
+	for _, inst := range beaconBlock.Body.Instructions {
+		if len(inst) == 0 || inst[0] != DoubleSignAction {
+			continue
+		}
+		evidence, err := ParseDoubleSignInstruction(inst)
+		if err != nil {
+			return nil, err
+		}
+		if signers[evidence.Signer] {
+			return nil, NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("duplicated evidence of %+v", evidence.Signer))
+		}
+		signers[evidence.Signer] = true
+		if err := evidence.verifyWithBeaconBestState(beaconBestState, blockchain.config.ChainParams.Epoch, blockchain.config.DataBase); err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, inst)
+	}
+	return instructions, nil
+}
+
+// removeDoubleSignEvidences - remove evidence of signers out of pool
+func (blockchain *BlockChain) removeDoubleSignEvidences(signers []string) {
+	pool := &blockchain.doubleSignEvidences
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	for _, signer := range signers {
+		delete(pool.evidences, signer)
+	}
+}
+
+// getDoubleSigners - signers of double sign instructions in beacon blocks, instructions are verified by beacon committee
+func getDoubleSigners(beaconBlocks ...*BeaconBlock) []string {
+	signers := []string{}
+	for _, beaconBlock := range beaconBlocks {
+		for _, inst := range beaconBlock.Body.Instructions {
+			if len(inst) == 0 || inst[0] != DoubleSignAction {
+				continue
+			}
+			evidence, err := ParseDoubleSignInstruction(inst)
+			if err != nil {
+				Logger.log.Error(err)
+				continue
+			}
+			signers = append(signers, evidence.Signer)
+		}
+	}
+	return signers
+}
+
+// processDoubleSigners - keep double signers of beacon blocks who have a staking tx in shard, and drop the staking tx
+// of kept signers swapped out by beacon blocks instead of returning it, so every forfeit is applied once
+// whichever beacon blocks carry the evidence and the swap
+func (shardBestState *ShardBestState) processDoubleSigners(beaconBlocks []*BeaconBlock) {
+	if shardBestState.DoubleSigners == nil {
+		shardBestState.DoubleSigners = make(map[string]bool)
+	}
+	for _, signer := range getDoubleSigners(beaconBlocks...) {
+		if _, ok := shardBestState.StakingTx[signer]; ok {
+			shardBestState.DoubleSigners[signer] = true
+		}
+	}
+	for _, beaconBlock := range beaconBlocks {
+		for _, inst := range beaconBlock.Body.Instructions {
+			if len(inst) < 3 || inst[0] != SwapAction {
+				continue
+			}
+			for _, outPublicKey := range strings.Split(inst[2], ",") {
+				if shardBestState.DoubleSigners[outPublicKey] {
+					delete(shardBestState.StakingTx, outPublicKey)
+					delete(shardBestState.DoubleSigners, outPublicKey)
+				}
+			}
+		}
+	}
+}
+
+// isStakingForfeited - staking amount of publicKey is not returned, it double signed before or in beacon blocks
+func (shardBestState *ShardBestState) isStakingForfeited(publicKey string, beaconBlocks []*BeaconBlock) bool {
+	if shardBestState.DoubleSigners[publicKey] {
+		return true
+	}
+	return common.IndexOfStr(publicKey, getDoubleSigners(beaconBlocks...)) != -1
+}
+
+// verifyForfeitedStakingNotReturned - check shard block returns no staking amount forfeited by double signers
+func (shardBestState *ShardBestState) verifyForfeitedStakingNotReturned(shardBlock *ShardBlock, beaconBlocks []*BeaconBlock) error {
+	forfeitedTxs := make(map[string]string)
+	for publicKey, txHash := range shardBestState.StakingTx {
+		if shardBestState.isStakingForfeited(publicKey, beaconBlocks) {
+			forfeitedTxs[txHash] = publicKey
+		}
+	}
+	for _, tx := range shardBlock.Body.Transactions {
+		if tx.GetMetadata() == nil || tx.GetMetadata().GetType() != metadata.ReturnStakingMeta {
+			continue
+		}
+		returnStakingMeta, ok := tx.GetMetadata().(*metadata.ReturnStakingMetadata)
+		if !ok {
+			continue
+		}
+		if publicKey, ok := forfeitedTxs[returnStakingMeta.TxID]; ok {
+			return NewBlockChainError(DoubleSignEvidenceError, fmt.Errorf("staking amount of double signer %+v is returned", publicKey))
+		}
+	}
+	return nil
+}
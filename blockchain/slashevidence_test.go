@@ -0,0 +1,182 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/bridgesig"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDoubleSignTestKey(t *testing.T, seed string) ([]byte, string) {
+	priKey, pubKey := bridgesig.KeyGen([]byte(seed))
+	committeeKey := incognitokey.CommitteePublicKey{
+		IncPubKey:    []byte(seed),
+		MiningPubKey: map[string][]byte{common.BridgeConsensus: bridgesig.PKBytes(&pubKey)},
+	}
+	signer, err := committeeKey.ToBase58()
+	assert.Nil(t, err)
+	return bridgesig.SKBytes(&priKey), signer
+}
+
+func newDoubleSignTestHeader(t *testing.T, producer string, height uint64, round int, timestamp int64) (ShardHeader, json.RawMessage) {
+	header := ShardHeader{Producer: producer, ShardID: 1, Height: height, Round: round, Timestamp: timestamp}
+	data, err := json.Marshal(header)
+	assert.Nil(t, err)
+	return header, data
+}
+
+func TestDoubleSignEvidence_Propose(t *testing.T) {
+	priKey, signer := newDoubleSignTestKey(t, "validator")
+	header1, data1 := newDoubleSignTestHeader(t, signer, 10, 2, 1)
+	header2, data2 := newDoubleSignTestHeader(t, signer, 10, 2, 2)
+	hash1, hash2 := header1.Hash(), header2.Hash()
+	sig1, err := bridgesig.Sign(priKey, hash1.GetBytes())
+	assert.Nil(t, err)
+	sig2, err := bridgesig.Sign(priKey, hash2.GetBytes())
+	assert.Nil(t, err)
+	evidence := &DoubleSignEvidence{
+		Type:       DoubleProposeEvidence,
+		ShardID:    1,
+		Signer:     signer,
+		Headers:    [2]json.RawMessage{data1, data2},
+		Signatures: [2]DoubleSignSignature{{Sig: sig1}, {Sig: sig2}},
+	}
+	height, err := evidence.Verify()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), height)
+
+	inst, err := NewDoubleSignInstruction(evidence)
+	assert.Nil(t, err)
+	parsedEvidence, err := ParseDoubleSignInstruction(inst)
+	assert.Nil(t, err)
+	_, err = parsedEvidence.Verify()
+	assert.Nil(t, err)
+	beaconBlock := NewBeaconBlock()
+	beaconBlock.Body.Instructions = [][]string{{RandomAction, "1"}, inst}
+	assert.Equal(t, []string{signer}, getDoubleSigners(beaconBlock))
+
+	// headers of another shard
+	evidence.ShardID = 2
+	_, err = evidence.Verify()
+	assert.NotNil(t, err)
+	evidence.ShardID = 1
+
+	// same block signed twice is not evidence
+	evidence.Headers[1] = data1
+	evidence.Signatures[1] = DoubleSignSignature{Sig: sig1}
+	_, err = evidence.Verify()
+	assert.NotNil(t, err)
+
+	// blocks of different rounds are not conflicting
+	header3, data3 := newDoubleSignTestHeader(t, signer, 10, 3, 2)
+	hash3 := header3.Hash()
+	sig3, err := bridgesig.Sign(priKey, hash3.GetBytes())
+	assert.Nil(t, err)
+	evidence.Headers[1] = data3
+	evidence.Signatures[1] = DoubleSignSignature{Sig: sig3}
+	_, err = evidence.Verify()
+	assert.NotNil(t, err)
+
+	// blocks are not signed by signer
+	otherPriKey, _ := newDoubleSignTestKey(t, "other")
+	otherSig, err := bridgesig.Sign(otherPriKey, hash2.GetBytes())
+	assert.Nil(t, err)
+	evidence.Headers[1] = data2
+	evidence.Signatures[1] = DoubleSignSignature{Sig: otherSig}
+	_, err = evidence.Verify()
+	assert.NotNil(t, err)
+}
+
+func TestDoubleSignEvidence_Vote(t *testing.T) {
+	priKey, signer := newDoubleSignTestKey(t, "validator")
+	_, producer := newDoubleSignTestKey(t, "producer")
+	headers := [2]json.RawMessage{}
+	signatures := [2]DoubleSignSignature{}
+	for i := range headers {
+		header, data := newDoubleSignTestHeader(t, producer, 5, 1, int64(i))
+		headers[i] = data
+		hash := header.Hash()
+		signature := DoubleSignSignature{BLS: []byte("bls"), BRI: []byte{}}
+		confirmData := append(hash.GetBytes(), signature.BLS...)
+		sig, err := bridgesig.Sign(priKey, common.HashB(confirmData))
+		assert.Nil(t, err)
+		signature.Sig = sig
+		signatures[i] = signature
+	}
+	evidence := &DoubleSignEvidence{
+		Type:       DoubleVoteEvidence,
+		ShardID:    1,
+		Signer:     signer,
+		Headers:    headers,
+		Signatures: signatures,
+	}
+	_, err := evidence.Verify()
+	assert.Nil(t, err)
+
+	// vote evidence is not propose evidence of the validator
+	evidence.Type = DoubleProposeEvidence
+	_, err = evidence.Verify()
+	assert.NotNil(t, err)
+	evidence.Type = DoubleVoteEvidence
+
+	db, closeDB := openSnapshotTestDB(t, "test_doublesign")
+	defer closeDB()
+	signerKey := incognitokey.CommitteePublicKey{}
+	assert.Nil(t, signerKey.FromBase58(signer))
+	producerKey := incognitokey.CommitteePublicKey{}
+	assert.Nil(t, producerKey.FromBase58(producer))
+	assert.Nil(t, db.StoreShardCommitteeByHeight(0, map[byte][]incognitokey.CommitteePublicKey{1: {producerKey, signerKey}}))
+
+	// expired evidence
+	beaconBestState := &BeaconBestState{BestShardHeight: map[byte]uint64{1: 100}}
+	assert.NotNil(t, evidence.verifyWithBeaconBestState(beaconBestState, 50, db))
+	beaconBestState.BestShardHeight[1] = 20
+	assert.Nil(t, evidence.verifyWithBeaconBestState(beaconBestState, 50, db))
+
+	// signer out of the shard committee at the beacon height of the blocks
+	assert.Nil(t, db.StoreShardCommitteeByHeight(0, map[byte][]incognitokey.CommitteePublicKey{1: {producerKey}, 2: {signerKey}}))
+	assert.NotNil(t, evidence.verifyWithBeaconBestState(beaconBestState, 50, db))
+}
+
+func TestDoubleSignForfeitStakingOnce(t *testing.T) {
+	_, signer := newDoubleSignTestKey(t, "validator")
+	shardBestState := NewShardBestState()
+	shardBestState.StakingTx = map[string]string{signer: "stakingtx", "other": "othertx"}
+	evidenceBlock := NewBeaconBlock()
+	evidenceBlock.Body.Instructions = [][]string{{DoubleSignAction, `{"Signer":"` + signer + `"}`}, {DoubleSignAction, `{"Signer":"unstaked"}`}}
+	swapBlock := NewBeaconBlock()
+	swapBlock.Body.Instructions = [][]string{{SwapAction, "", signer + ",other", "shard", "1"}}
+
+	// the evidence and the swap are in beacon blocks of different shard blocks
+	shardBestState.processDoubleSigners([]*BeaconBlock{evidenceBlock})
+	assert.Equal(t, map[string]bool{signer: true}, shardBestState.DoubleSigners)
+	assert.Equal(t, "stakingtx", shardBestState.StakingTx[signer])
+	assert.True(t, shardBestState.isStakingForfeited(signer, []*BeaconBlock{swapBlock}))
+	assert.False(t, shardBestState.isStakingForfeited("other", []*BeaconBlock{swapBlock}))
+
+	returnStakingTx := func(txID string) *ShardBlock {
+		tx := &transaction.Tx{Metadata: &metadata.ReturnStakingMetadata{TxID: txID, MetadataBase: *metadata.NewMetadataBase(metadata.ReturnStakingMeta)}}
+		shardBlock := NewShardBlock()
+		shardBlock.Body.Transactions = []metadata.Transaction{tx}
+		return shardBlock
+	}
+	assert.NotNil(t, shardBestState.verifyForfeitedStakingNotReturned(returnStakingTx("stakingtx"), []*BeaconBlock{swapBlock}))
+	assert.Nil(t, shardBestState.verifyForfeitedStakingNotReturned(returnStakingTx("othertx"), []*BeaconBlock{swapBlock}))
+
+	shardBestState.processDoubleSigners([]*BeaconBlock{swapBlock})
+	assert.Empty(t, shardBestState.DoubleSigners)
+	_, ok := shardBestState.StakingTx[signer]
+	assert.False(t, ok)
+	assert.Equal(t, "othertx", shardBestState.StakingTx["other"])
+
+	// a new staking tx of the signer is returned as usual
+	shardBestState.StakingTx[signer] = "restakingtx"
+	shardBestState.processDoubleSigners([]*BeaconBlock{swapBlock})
+	assert.Equal(t, "restakingtx", shardBestState.StakingTx[signer])
+	assert.False(t, shardBestState.isStakingForfeited(signer, []*BeaconBlock{swapBlock}))
+}
@@ -128,6 +128,14 @@ func (blockchain *BlockChain) processForSlashing(block *BeaconBlock) error {
 		if len(inst) == 0 {
 			continue
 		}
+		if inst[0] == DoubleSignAction {
+			evidence, err := ParseDoubleSignInstruction(inst)
+			if err != nil {
+				return err
+			}
+			producersBlackList[evidence.Signer] = doubleSignPunishedEpoches
+			continue
+		}
 		if inst[0] != SwapAction {
 			continue
 		}
@@ -0,0 +1,311 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	StateSnapshotVersion = 1
+	// number of records written to database in one batch when importing a snapshot
+	stateSnapshotBatchSize = 1000
+)
+
+// StateSnapshotHeader identifies the chain view a state snapshot was taken at.
+// A snapshot is made of length prefixed frames (see CalculateNumberOfByteToRead):
+// header, beacon best state, one best state per active shard, key/value record pairs ended by an empty key,
+// then the sha256 checksum of all previous frames. The checksum covers the block hashes in the header,
+// and every best state must match those hashes, so a snapshot can not be replayed on top of another chain view.
+type StateSnapshotHeader struct {
+	Version          int
+	BeaconHeight     uint64
+	BeaconBlockHash  common.Hash
+	ActiveShards     int
+	ShardHeights     map[byte]uint64
+	ShardBlockHashes map[byte]common.Hash
+	// set once the whole snapshot has been written or read
+	Checksum common.Hash `json:"-"`
+}
+
+type stateSnapshot struct {
+	header           *StateSnapshotHeader
+	beaconBestState  *BeaconBestState
+	beaconStateBytes []byte
+	shardBestStates  map[byte]*ShardBestState
+	shardStateBytes  map[byte][]byte
+}
+
+func writeStateSnapshotFrame(writer io.Writer, data []byte) error {
+	if _, err := writer.Write(CalculateNumberOfByteToRead(len(data))); err != nil {
+		return err
+	}
+	_, err := writer.Write(data)
+	return err
+}
+
+func readStateSnapshotFrame(reader io.Reader) ([]byte, error) {
+	sizeBytes := make([]byte, 8)
+	if _, err := io.ReadFull(reader, sizeBytes); err != nil {
+		return nil, err
+	}
+	size, err := GetNumberOfByteToRead(sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ExportStateSnapshot writes the chain state stored in db to writer.
+// Serial numbers, commitments and SNDerivators are only kept as cumulative sets,
+// so a snapshot can only be taken at the best beacon height of db, beaconHeight 0 means the best beacon height.
+func ExportStateSnapshot(db database.DatabaseInterface, writer io.Writer, beaconHeight uint64) (*StateSnapshotHeader, error) {
+	beaconStateBytes, err := db.FetchBeaconBestState()
+	if err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	beaconBestState := &BeaconBestState{}
+	if err := json.Unmarshal(beaconStateBytes, beaconBestState); err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	if beaconHeight != 0 && beaconHeight != beaconBestState.BeaconHeight {
+		return nil, NewBlockChainError(ExportStateSnapshotError, errors.Errorf("snapshot can only be taken at best beacon height %+v, requested %+v", beaconBestState.BeaconHeight, beaconHeight))
+	}
+	header := &StateSnapshotHeader{
+		Version:          StateSnapshotVersion,
+		BeaconHeight:     beaconBestState.BeaconHeight,
+		BeaconBlockHash:  beaconBestState.BestBlockHash,
+		ActiveShards:     beaconBestState.ActiveShards,
+		ShardHeights:     make(map[byte]uint64),
+		ShardBlockHashes: make(map[byte]common.Hash),
+	}
+	shardStatesBytes := [][]byte{}
+	for shardID := byte(0); int(shardID) < beaconBestState.ActiveShards; shardID++ {
+		shardStateBytes, err := db.FetchShardBestState(shardID)
+		if err != nil {
+			return nil, NewBlockChainError(ExportStateSnapshotError, err)
+		}
+		shardBestState := &ShardBestState{}
+		if err := json.Unmarshal(shardStateBytes, shardBestState); err != nil {
+			return nil, NewBlockChainError(ExportStateSnapshotError, err)
+		}
+		header.ShardHeights[shardID] = shardBestState.ShardHeight
+		header.ShardBlockHashes[shardID] = shardBestState.BestBlockHash
+		shardStatesBytes = append(shardStatesBytes, shardStateBytes)
+	}
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	hasher := sha256.New()
+	hashWriter := io.MultiWriter(writer, hasher)
+	frames := append([][]byte{headerBytes, beaconStateBytes}, shardStatesBytes...)
+	for _, frame := range frames {
+		if err := writeStateSnapshotFrame(hashWriter, frame); err != nil {
+			return nil, NewBlockChainError(ExportStateSnapshotError, err)
+		}
+	}
+	numOfRecords := 0
+	for _, prefix := range lvdb.StateSnapshotPrefixes(header.BeaconHeight) {
+		err := db.IterateRecordsByPrefix(prefix, func(key, value []byte) error {
+			if err := writeStateSnapshotFrame(hashWriter, key); err != nil {
+				return err
+			}
+			numOfRecords++
+			return writeStateSnapshotFrame(hashWriter, value)
+		})
+		if err != nil {
+			return nil, NewBlockChainError(ExportStateSnapshotError, err)
+		}
+	}
+	if err := writeStateSnapshotFrame(hashWriter, []byte{}); err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	if err := header.Checksum.SetBytes(hasher.Sum(nil)); err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	if err := writeStateSnapshotFrame(writer, header.Checksum.GetBytes()); err != nil {
+		return nil, NewBlockChainError(ExportStateSnapshotError, err)
+	}
+	log.Printf("Finish export state snapshot at beacon height %+v with %+v records, checksum %+v", header.BeaconHeight, numOfRecords, header.Checksum.String())
+	return header, nil
+}
+
+// readStateSnapshot reads a whole snapshot, calling onRecord (when not nil) for each state record,
+// and fails if the snapshot is not consistent or its checksum does not match.
+func readStateSnapshot(reader io.Reader, onRecord func(key, value []byte) error) (*stateSnapshot, error) {
+	hasher := sha256.New()
+	hashReader := io.TeeReader(reader, hasher)
+	headerBytes, err := readStateSnapshotFrame(hashReader)
+	if err != nil {
+		return nil, err
+	}
+	header := &StateSnapshotHeader{}
+	if err := json.Unmarshal(headerBytes, header); err != nil {
+		return nil, err
+	}
+	if header.Version != StateSnapshotVersion {
+		return nil, errors.Errorf("unsupported state snapshot version %+v", header.Version)
+	}
+	snapshot := &stateSnapshot{
+		header:          header,
+		shardBestStates: make(map[byte]*ShardBestState),
+		shardStateBytes: make(map[byte][]byte),
+	}
+	snapshot.beaconStateBytes, err = readStateSnapshotFrame(hashReader)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.beaconBestState = &BeaconBestState{}
+	if err := json.Unmarshal(snapshot.beaconStateBytes, snapshot.beaconBestState); err != nil {
+		return nil, err
+	}
+	beaconBestState := snapshot.beaconBestState
+	if beaconBestState.BeaconHeight != header.BeaconHeight || !beaconBestState.BestBlockHash.IsEqual(&header.BeaconBlockHash) || !beaconBestState.BestBlock.Hash().IsEqual(&header.BeaconBlockHash) {
+		return nil, errors.Errorf("beacon best state does not match snapshot beacon block %+v at height %+v", header.BeaconBlockHash.String(), header.BeaconHeight)
+	}
+	if beaconBestState.ActiveShards != header.ActiveShards {
+		return nil, errors.Errorf("expect %+v active shards, got %+v", header.ActiveShards, beaconBestState.ActiveShards)
+	}
+	for shardID := byte(0); int(shardID) < header.ActiveShards; shardID++ {
+		shardStateBytes, err := readStateSnapshotFrame(hashReader)
+		if err != nil {
+			return nil, err
+		}
+		shardBestState := &ShardBestState{}
+		if err := json.Unmarshal(shardStateBytes, shardBestState); err != nil {
+			return nil, err
+		}
+		shardBlockHash := header.ShardBlockHashes[shardID]
+		if shardBestState.BestBlock == nil || shardBestState.ShardHeight != header.ShardHeights[shardID] || !shardBestState.BestBlockHash.IsEqual(&shardBlockHash) || !shardBestState.BestBlock.Hash().IsEqual(&shardBlockHash) {
+			return nil, errors.Errorf("shard %+v best state does not match snapshot shard block %+v", shardID, shardBlockHash.String())
+		}
+		snapshot.shardBestStates[shardID] = shardBestState
+		snapshot.shardStateBytes[shardID] = shardStateBytes
+	}
+	prefixes := lvdb.StateSnapshotPrefixes(header.BeaconHeight)
+	for {
+		key, err := readStateSnapshotFrame(hashReader)
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			break
+		}
+		value, err := readStateSnapshotFrame(hashReader)
+		if err != nil {
+			return nil, err
+		}
+		isStateRecord := false
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(key, prefix) {
+				isStateRecord = true
+				break
+			}
+		}
+		if !isStateRecord {
+			return nil, errors.Errorf("record %+v is not part of chain state", string(key))
+		}
+		if onRecord != nil {
+			if err := onRecord(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	checksum, err := readStateSnapshotFrame(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(checksum, hasher.Sum(nil)) {
+		return nil, errors.New("state snapshot checksum mismatch")
+	}
+	if err := header.Checksum.SetBytes(checksum); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ImportStateSnapshot bootstraps an empty db from a snapshot written by ExportStateSnapshot.
+// The checksum embedded in a snapshot only proves the file is intact, so the snapshot must match trustedChecksum,
+// the checksum printed by ExportStateSnapshot on a node the operator trusts, otherwise anyone could hand out a forged state.
+// The snapshot is verified in full before anything is written, and best states are stored last,
+// so on success the next BlockChain.Init starts from the snapshot height and syncs forward from there.
+func ImportStateSnapshot(db database.DatabaseInterface, reader io.ReadSeeker, trustedChecksum common.Hash) (*StateSnapshotHeader, error) {
+	if trustedChecksum.IsEqual(&common.Hash{}) {
+		return nil, NewBlockChainError(ImportStateSnapshotError, errors.New("trusted checksum of state snapshot is required"))
+	}
+	if _, err := db.FetchBeaconBestState(); err == nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, errors.New("database already contains chain state"))
+	}
+	verified, err := readStateSnapshot(reader, nil)
+	if err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	if !verified.header.Checksum.IsEqual(&trustedChecksum) {
+		return nil, NewBlockChainError(ImportStateSnapshotError, errors.Errorf("state snapshot checksum %+v does not match trusted checksum %+v", verified.header.Checksum.String(), trustedChecksum.String()))
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	batchData := []database.BatchData{}
+	snapshot, err := readStateSnapshot(reader, func(key, value []byte) error {
+		batchData = append(batchData, database.BatchData{Key: key, Value: value})
+		if len(batchData) < stateSnapshotBatchSize {
+			return nil
+		}
+		err := db.PutBatch(batchData)
+		batchData = []database.BatchData{}
+		return err
+	})
+	if err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	if len(batchData) > 0 {
+		if err := db.PutBatch(batchData); err != nil {
+			return nil, NewBlockChainError(ImportStateSnapshotError, err)
+		}
+	}
+	header := snapshot.header
+	for shardID := byte(0); int(shardID) < header.ActiveShards; shardID++ {
+		shardBestState := snapshot.shardBestStates[shardID]
+		if err := db.StoreShardBlock(shardBestState.BestBlock, shardBestState.BestBlockHash, shardID, nil); err != nil {
+			return nil, NewBlockChainError(ImportStateSnapshotError, err)
+		}
+		if err := db.StoreShardBlockIndex(shardBestState.BestBlockHash, shardBestState.ShardHeight, shardID, nil); err != nil {
+			return nil, NewBlockChainError(ImportStateSnapshotError, err)
+		}
+		// blocks below the snapshot are not stored, report them as pruned
+		if err := db.StorePrunedHeight(false, shardID, shardBestState.ShardHeight-1); err != nil {
+			return nil, NewBlockChainError(ImportStateSnapshotError, err)
+		}
+		if err := db.StoreShardBestState(json.RawMessage(snapshot.shardStateBytes[shardID]), shardID, nil); err != nil {
+			return nil, NewBlockChainError(ImportStateSnapshotError, err)
+		}
+	}
+	beaconBestState := snapshot.beaconBestState
+	if err := db.StoreBeaconBlock(&beaconBestState.BestBlock, beaconBestState.BestBlockHash, nil); err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	if err := db.StoreBeaconBlockIndex(beaconBestState.BestBlockHash, beaconBestState.BeaconHeight); err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	if err := db.StorePrunedHeight(true, 0, beaconBestState.BeaconHeight-1); err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	if err := db.StoreBeaconBestState(json.RawMessage(snapshot.beaconStateBytes), nil); err != nil {
+		return nil, NewBlockChainError(ImportStateSnapshotError, err)
+	}
+	log.Printf("Finish import state snapshot at beacon height %+v, checksum %+v", header.BeaconHeight, header.Checksum.String())
+	return header, nil
+}
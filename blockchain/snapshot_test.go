@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func openSnapshotTestDB(t *testing.T, name string) (database.DatabaseInterface, func()) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), name)
+	assert.Nil(t, err)
+	db, err := database.Open("leveldb", dbPath)
+	assert.Nil(t, err)
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+}
+
+// storeSnapshotTestBestStates stores best states of a chain with one shard at beacon height 1
+func storeSnapshotTestBestStates(t *testing.T, db database.DatabaseInterface) (*BeaconBestState, *ShardBestState) {
+	beaconBestState := NewBeaconBestState()
+	beaconBestState.BestBlock = *ChainTestParam.GenesisBeaconBlock
+	beaconBestState.BeaconHeight = 1
+	beaconBestState.BestBlockHash = *beaconBestState.BestBlock.Hash()
+	beaconBestState.ActiveShards = 1
+	assert.Nil(t, db.StoreBeaconBestState(beaconBestState, nil))
+	shardBestState := NewShardBestState()
+	shardBestState.BestBlock = ChainTestParam.GenesisShardBlock
+	shardBestState.ShardHeight = 1
+	shardBestState.BestBlockHash = *shardBestState.BestBlock.Hash()
+	assert.Nil(t, db.StoreShardBestState(shardBestState, 0, nil))
+	return beaconBestState, shardBestState
+}
+
+func TestStateSnapshotExportImport(t *testing.T) {
+	srcDB, closeSrcDB := openSnapshotTestDB(t, "test_snapshot_src_")
+	defer closeSrcDB()
+
+	beaconBestState, shardBestState := storeSnapshotTestBestStates(t, srcDB)
+
+	token1IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token2IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	for _, beaconHeight := range []uint64{0, 1} {
+		pairKey := string(lvdb.BuildPDEPoolForPairKey(beaconHeight, token1IDStr, token2IDStr))
+		assert.Nil(t, storePDEPoolPairs(srcDB, beaconHeight, map[string]*lvdb.PDEPoolForPair{
+			pairKey: {Token1IDStr: token1IDStr, Token1PoolValue: beaconHeight, Token2IDStr: token2IDStr, Token2PoolValue: beaconHeight},
+		}))
+	}
+	serialNumberKey := []byte("serinalnumbers-abc")
+	assert.Nil(t, srcDB.Put(serialNumberKey, []byte{1}))
+	txIndexKey := []byte("tx-abc")
+	assert.Nil(t, srcDB.Put(txIndexKey, []byte{1}))
+
+	// only the best beacon height can be exported
+	_, err := ExportStateSnapshot(srcDB, &bytes.Buffer{}, 2)
+	assert.NotNil(t, err)
+	snapshot := &bytes.Buffer{}
+	header, err := ExportStateSnapshot(srcDB, snapshot, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), header.BeaconHeight)
+	assert.Equal(t, shardBestState.BestBlockHash, header.ShardBlockHashes[0])
+
+	// tampered snapshot is rejected before anything is written
+	tampered := append([]byte{}, snapshot.Bytes()...)
+	tampered[len(tampered)-50] ^= 1
+	tamperedDB, closeTamperedDB := openSnapshotTestDB(t, "test_snapshot_tampered_")
+	defer closeTamperedDB()
+	_, err = ImportStateSnapshot(tamperedDB, bytes.NewReader(tampered), header.Checksum)
+	assert.NotNil(t, err)
+	_, err = tamperedDB.FetchBeaconBestState()
+	assert.NotNil(t, err)
+
+	dstDB, closeDstDB := openSnapshotTestDB(t, "test_snapshot_dst_")
+	defer closeDstDB()
+	// a self-consistent snapshot is rejected without a trusted checksum or with another one
+	_, err = ImportStateSnapshot(dstDB, bytes.NewReader(snapshot.Bytes()), common.Hash{})
+	assert.NotNil(t, err)
+	_, err = ImportStateSnapshot(dstDB, bytes.NewReader(snapshot.Bytes()), common.HashH([]byte("other snapshot")))
+	assert.NotNil(t, err)
+	_, err = dstDB.FetchBeaconBestState()
+	assert.NotNil(t, err)
+	importedHeader, err := ImportStateSnapshot(dstDB, bytes.NewReader(snapshot.Bytes()), header.Checksum)
+	assert.Nil(t, err)
+	assert.Equal(t, header.Checksum, importedHeader.Checksum)
+	_, err = ImportStateSnapshot(dstDB, bytes.NewReader(snapshot.Bytes()), header.Checksum)
+	assert.NotNil(t, err)
+
+	_, err = dstDB.FetchBeaconBestState()
+	assert.Nil(t, err)
+	_, err = dstDB.FetchShardBestState(0)
+	assert.Nil(t, err)
+	ok, err := dstDB.HasBeaconBlock(beaconBestState.BestBlockHash)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, _ = dstDB.HasValue(serialNumberKey)
+	assert.True(t, ok)
+	ok, _ = dstDB.HasValue(txIndexKey)
+	assert.False(t, ok)
+	pdeState, err := InitCurrentPDEStateFromDB(dstDB, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pdeState.PDEPoolPairs))
+	pdeState, err = InitCurrentPDEStateFromDB(dstDB, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pdeState.PDEPoolPairs))
+}
+
+func TestStateSnapshotPortalState(t *testing.T) {
+	srcDB, closeSrcDB := openSnapshotTestDB(t, "test_snapshot_portal_src_")
+	defer closeSrcDB()
+	storeSnapshotTestBestStates(t, srcDB)
+
+	checkpoint := minePortalTestBTCHeader(common.Hash{}, common.Hash{}, 1500000000)
+	bc := &BlockChain{
+		config: Config{
+			ChainParams: &Params{
+				PortalParams: PortalParams{
+					BTCParams:     &btc.RegressionNetParams,
+					BTCCheckpoint: btc.Checkpoint{Header: checkpoint.Hex(), Height: 0},
+				},
+			},
+		},
+	}
+	custodianKey := string(lvdb.BuildCustodianStateKey(1, portalTestCustodian1Addr))
+	portalState := &CurrentPortalState{
+		CustodianPoolState: map[string]*lvdb.CustodianState{
+			custodianKey: {IncognitoAddress: portalTestCustodian1Addr, TotalCollateral: 1000, FreeCollateral: 1000},
+		},
+		WaitingPortingRequests: make(map[string]*lvdb.PortingRequest),
+		WaitingRedeemRequests:  make(map[string]*lvdb.RedeemRequest),
+		RelayedBTCHeaders:      make(map[string]*lvdb.BTCHeaderState),
+	}
+	headers := []*btc.BlockHeader{}
+	prev := checkpoint
+	headerStrs := []string{}
+	for i := 0; i < 3; i++ {
+		prev = minePortalTestBTCHeader(prev.BlockHash(), common.Hash{byte(i)}, prev.Timestamp+600)
+		headers = append(headers, prev)
+		headerStrs = append(headerStrs, prev.Hex())
+	}
+	assert.Nil(t, bc.connectBTCHeaders(portalState, srcDB, 0, headerStrs))
+	assert.Nil(t, storePortalStateToDB(srcDB, 1, portalState))
+	trackPortalStatus(srcDB, lvdb.PortalRelayingBTCHeaderStatusPrefix, "relaying-1", metadata.PortalRelayingBTCHeaderStatus{
+		Status:  common.PortalRelayingBTCHeaderAcceptedStatus,
+		Headers: headerStrs,
+	})
+	token1IDStr := "0000000000000000000000000000000000000000000000000000000000000005"
+	token2IDStr := "0000000000000000000000000000000000000000000000000000000000000007"
+	pairStatsKey := lvdb.BuildPDEPairStatsKey(0, token1IDStr, token2IDStr)
+	assert.Nil(t, srcDB.Put(pairStatsKey, []byte("{}")))
+
+	snapshot := &bytes.Buffer{}
+	header, err := ExportStateSnapshot(srcDB, snapshot, 0)
+	assert.Nil(t, err)
+	dstDB, closeDstDB := openSnapshotTestDB(t, "test_snapshot_portal_dst_")
+	defer closeDstDB()
+	_, err = ImportStateSnapshot(dstDB, bytes.NewReader(snapshot.Bytes()), header.Checksum)
+	assert.Nil(t, err)
+
+	importedState, err := InitCurrentPortalStateFromDB(dstDB, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(importedState.CustodianPoolState))
+	assert.Equal(t, uint64(1000), importedState.CustodianPoolState[custodianKey].FreeCollateral)
+	assert.NotNil(t, importedState.BTCHeaderChainTip)
+	assert.Equal(t, headers[2].Hex(), importedState.BTCHeaderChainTip.Header)
+	assert.Equal(t, uint64(3), importedState.BTCHeaderChainTip.Height)
+	// relayed headers are found in db, so the imported chain can be extended
+	for _, btcHeader := range headers {
+		chainHeader, err := bc.btcHeaderGetter(importedState, dstDB)(btcHeader.BlockHash())
+		assert.Nil(t, err)
+		assert.NotNil(t, chainHeader)
+	}
+	next := minePortalTestBTCHeader(headers[2].BlockHash(), common.Hash{3}, headers[2].Timestamp+600)
+	assert.Nil(t, bc.connectBTCHeaders(importedState, dstDB, 1, []string{next.Hex()}))
+	assert.Equal(t, uint64(4), importedState.BTCHeaderChainTip.Height)
+	assert.True(t, isPortalStatusTracked(dstDB, lvdb.PortalRelayingBTCHeaderStatusPrefix, "relaying-1"))
+	ok, _ := dstDB.HasValue(pairStatsKey)
+	assert.True(t, ok)
+}
@@ -0,0 +1,214 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/pkg/errors"
+)
+
+const (
+	TxHistoryDirectionIn  = "in"
+	TxHistoryDirectionOut = "out"
+	// DefaultTxHistoryPageSize is the number of entries returned when no limit is given
+	DefaultTxHistoryPageSize = 50
+	// MaxTxHistoryPageSize is the biggest number of entries returned by one call of GetTxHistory
+	MaxTxHistoryPageSize = 500
+)
+
+// TxHistoryEntry is a movement of one token to or from a public key made by a transaction.
+// A transaction sending PRV and a privacy token to two receivers makes four entries, one for each receiver and token.
+type TxHistoryEntry struct {
+	TxHash       common.Hash
+	TxType       string
+	ShardID      byte
+	BlockHeight  uint64
+	BeaconHeight uint64
+	TxIndex      int
+	Direction    string
+	TokenID      common.Hash
+	IsPrivacy    bool
+	// Amount received, or sent to others for an outgoing entry, 0 when hidden by privacy
+	Amount uint64
+	// Fee paid in this token, only set on outgoing entries
+	Fee uint64
+	// OutputCoins received in this entry, the read-only key of the receiver reveals their hidden amount
+	OutputCoins [][]byte `json:",omitempty"`
+}
+
+type txHistoryRecord struct {
+	publicKey []byte
+	sortKey   []byte
+	entry     *TxHistoryEntry
+}
+
+// IsTxHistoryIndexEnabled returns true when the node indexes the transactions of every public key,
+// only blocks stored while the index is enabled are indexed
+func (blockchain *BlockChain) IsTxHistoryIndexEnabled() bool {
+	return blockchain.config.TxHistoryIndex
+}
+
+// buildTxHistory returns the history entries of every public key involved in the transactions of a shard block.
+// Receivers are always known; the sender only when the transaction does not hide it,
+// so the change of a privacy transaction shows up as received by its sender.
+func buildTxHistory(block *ShardBlock) []txHistoryRecord {
+	records := []txHistoryRecord{}
+	for txIndex, tx := range block.Body.Transactions {
+		entryIndex := uint16(0)
+		addEntries := func(normalTx *transaction.Tx, tokenID common.Hash) {
+			for _, entry := range buildTxHistoryEntries(normalTx, tokenID) {
+				entry.TxHash = *tx.Hash()
+				entry.TxType = tx.GetType()
+				entry.ShardID = block.Header.ShardID
+				entry.BlockHeight = block.Header.Height
+				entry.BeaconHeight = block.Header.BeaconHeight
+				entry.TxIndex = txIndex
+				records = append(records, txHistoryRecord{
+					publicKey: entry.publicKey,
+					sortKey:   lvdb.BuildTxHistorySortKey(block.Header.BeaconHeight, block.Header.ShardID, block.Header.Height, uint32(txIndex), entryIndex),
+					entry:     &entry.TxHistoryEntry,
+				})
+				entryIndex++
+			}
+		}
+		switch tx.GetType() {
+		case common.TxNormalType, common.TxRewardType, common.TxReturnStakingType:
+			normalTx, ok := tx.(*transaction.Tx)
+			if !ok {
+				continue
+			}
+			addEntries(normalTx, common.PRVCoinID)
+		case common.TxCustomTokenPrivacyType:
+			tokenTx, ok := tx.(*transaction.TxCustomTokenPrivacy)
+			if !ok {
+				continue
+			}
+			addEntries(&tokenTx.Tx, common.PRVCoinID)
+			addEntries(&tokenTx.TxPrivacyTokenData.TxNormal, tokenTx.TxPrivacyTokenData.PropertyID)
+		}
+	}
+	return records
+}
+
+type txHistoryEntryOfKey struct {
+	TxHistoryEntry
+	publicKey []byte
+}
+
+// buildTxHistoryEntries returns an outgoing entry for a public sender and an incoming entry for each other receiver,
+// in the order receivers first appear in the outputs of the transaction
+func buildTxHistoryEntries(tx *transaction.Tx, tokenID common.Hash) []*txHistoryEntryOfKey {
+	if tx.Proof == nil {
+		return nil
+	}
+	isPrivacy := tx.IsPrivacy()
+	sender := tx.GetSender()
+	var sent uint64
+	received := []*txHistoryEntryOfKey{}
+	for _, coin := range tx.Proof.GetOutputCoins() {
+		if coin == nil || coin.CoinDetails == nil || coin.CoinDetails.GetPublicKey() == nil {
+			continue
+		}
+		publicKey := coin.CoinDetails.GetPublicKey().ToBytesS()
+		if sender != nil && bytes.Equal(publicKey, sender) {
+			// change
+			continue
+		}
+		sent += coin.CoinDetails.GetValue()
+		var entry *txHistoryEntryOfKey
+		for _, e := range received {
+			if bytes.Equal(e.publicKey, publicKey) {
+				entry = e
+				break
+			}
+		}
+		if entry == nil {
+			entry = &txHistoryEntryOfKey{
+				TxHistoryEntry: TxHistoryEntry{Direction: TxHistoryDirectionIn, TokenID: tokenID, IsPrivacy: isPrivacy},
+				publicKey:      publicKey,
+			}
+			received = append(received, entry)
+		}
+		if !isPrivacy {
+			entry.Amount += coin.CoinDetails.GetValue()
+		}
+		entry.OutputCoins = append(entry.OutputCoins, coin.Bytes())
+	}
+	if sender == nil {
+		return received
+	}
+	out := &txHistoryEntryOfKey{
+		TxHistoryEntry: TxHistoryEntry{Direction: TxHistoryDirectionOut, TokenID: tokenID, IsPrivacy: isPrivacy, Amount: sent, Fee: tx.Fee},
+		publicKey:      sender,
+	}
+	return append([]*txHistoryEntryOfKey{out}, received...)
+}
+
+// storeTxHistory indexes the transactions of a shard block by the public keys involved
+func (blockchain *BlockChain) storeTxHistory(block *ShardBlock, bd *[]database.BatchData) error {
+	for _, record := range buildTxHistory(block) {
+		value, err := json.Marshal(record.entry)
+		if err != nil {
+			return err
+		}
+		if err := blockchain.config.DataBase.StoreTxHistory(record.publicKey, record.sortKey, value, bd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTxHistory removes the entries made by the transactions of a reverted shard block
+func (blockchain *BlockChain) deleteTxHistory(block *ShardBlock) error {
+	for _, record := range buildTxHistory(block) {
+		if err := blockchain.config.DataBase.DeleteTxHistory(record.publicKey, record.sortKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTxHistory returns at most limit history entries of a public key following cursor, oldest first,
+// and the cursor of the last entry returned. Entries are ordered by the beacon height of their shard block
+// so transactions of all shards interleave in the order the beacon chain saw them.
+// An empty cursor starts from the first entry; the returned cursor can be kept to poll for new entries later.
+func (blockchain *BlockChain) GetTxHistory(publicKey []byte, cursor string, limit int) ([]*TxHistoryEntry, string, error) {
+	if !blockchain.IsTxHistoryIndexEnabled() {
+		return nil, "", NewBlockChainError(GetTxHistoryError, errors.New("transaction history index is disabled, restart the node with --txhistoryindex"))
+	}
+	var afterSortKey []byte
+	if cursor != "" {
+		var err error
+		afterSortKey, err = hex.DecodeString(cursor)
+		if err != nil || len(afterSortKey) != lvdb.TxHistorySortKeyLength {
+			return nil, "", NewBlockChainError(GetTxHistoryError, errors.Errorf("invalid cursor %+v", cursor))
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultTxHistoryPageSize
+	}
+	if limit > MaxTxHistoryPageSize {
+		limit = MaxTxHistoryPageSize
+	}
+	sortKeys, values, err := blockchain.config.DataBase.ListTxHistory(publicKey, afterSortKey, limit)
+	if err != nil {
+		return nil, "", NewBlockChainError(GetTxHistoryError, err)
+	}
+	entries := make([]*TxHistoryEntry, 0, len(values))
+	for _, value := range values {
+		entry := new(TxHistoryEntry)
+		if err := json.Unmarshal(value, entry); err != nil {
+			return nil, "", NewBlockChainError(GetTxHistoryError, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(sortKeys) > 0 {
+		cursor = hex.EncodeToString(sortKeys[len(sortKeys)-1])
+	}
+	return entries, cursor, nil
+}
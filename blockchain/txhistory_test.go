@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxHistory(t *testing.T) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_txhistory_")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dbPath)
+	db, err := database.Open("leveldb", dbPath)
+	assert.Nil(t, err)
+	defer db.Close()
+	bc := &BlockChain{config: Config{DataBase: db, ChainParams: &ChainTestParam}}
+
+	// the genesis block pays public salaries to one receiver
+	genesisTx := ChainTestParam.GenesisShardBlock.Body.Transactions[0].(*transaction.Tx)
+	receiver := genesisTx.Proof.GetOutputCoins()[0].CoinDetails.GetPublicKey().ToBytesS()
+	_, _, err = bc.GetTxHistory(receiver, "", 0)
+	assert.NotNil(t, err)
+
+	bc.config.TxHistoryIndex = true
+	blocks := []*ShardBlock{}
+	for height := uint64(1); height <= 3; height++ {
+		block := *ChainTestParam.GenesisShardBlock
+		block.Header.Height = height
+		block.Header.BeaconHeight = 10 - height
+		blocks = append(blocks, &block)
+		assert.Nil(t, bc.storeTxHistory(&block, nil))
+	}
+
+	// ordered by beacon height, the last cursor is returned again when there is nothing new
+	entries, cursor, err := bc.GetTxHistory(receiver, "", 2)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, uint64(3), entries[0].BlockHeight)
+		assert.Equal(t, uint64(2), entries[1].BlockHeight)
+		assert.Equal(t, TxHistoryDirectionIn, entries[0].Direction)
+		assert.Equal(t, common.PRVCoinID, entries[0].TokenID)
+		assert.Equal(t, *genesisTx.Hash(), entries[0].TxHash)
+		assert.Equal(t, genesisTx.Proof.GetOutputCoins()[0].CoinDetails.GetValue(), entries[0].Amount)
+		assert.Equal(t, 1, len(entries[0].OutputCoins))
+	}
+	entries, cursor, err = bc.GetTxHistory(receiver, cursor, 2)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, uint64(1), entries[0].BlockHeight)
+	}
+	entries, nextCursor, err := bc.GetTxHistory(receiver, cursor, 2)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, cursor, nextCursor)
+
+	assert.Nil(t, bc.deleteTxHistory(blocks[2]))
+	entries, _, err = bc.GetTxHistory(receiver, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+
+	_, _, err = bc.GetTxHistory(receiver, "abcd", 0)
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
 	"io"
 	"log"
 	"os"
@@ -12,8 +15,10 @@ import (
 	"github.com/incognitochain/incognito-chain/blockchain"
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/database"
+	_ "github.com/incognitochain/incognito-chain/database/badgerdb"
 	"github.com/incognitochain/incognito-chain/mempool"
 	"github.com/incognitochain/incognito-chain/pubsub"
+	"github.com/pkg/errors"
 )
 
 func makeBlockChain(databaseDir string, testNet bool) (*blockchain.BlockChain, error) {
@@ -271,3 +276,132 @@ func restoreBeaconChain(bc *blockchain.BlockChain, filename string) error {
 	log.Println("Restore Beacon Chain Successfully")
 	return nil
 }
+
+func exportStateSnapshot(databaseDir string, beaconHeight uint64, outDatadir string, fileName string) error {
+	db, err := database.Open("leveldb", filepath.Join(databaseDir))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if fileName == "" {
+		fileName = "snapshot-incognito"
+	}
+	if outDatadir == "" {
+		outDatadir = "./"
+	}
+	file := filepath.Join(outDatadir, fileName)
+	fileHandler, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fileHandler.Close()
+	writer := bufio.NewWriter(fileHandler)
+	header, err := blockchain.ExportStateSnapshot(db, writer, beaconHeight)
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	log.Printf("Export State Snapshot at Beacon Height %+v, file %+v, checksum %+v", header.BeaconHeight, file, header.Checksum.String())
+	return nil
+}
+
+func importStateSnapshot(databaseDir string, filename string, checksum string) error {
+	trustedChecksum, err := common.Hash{}.NewHashFromStr(checksum)
+	if err != nil {
+		return err
+	}
+	db, err := database.Open("leveldb", filepath.Join(databaseDir))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	fileHandler, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fileHandler.Close()
+	header, err := blockchain.ImportStateSnapshot(db, fileHandler, *trustedChecksum)
+	if err != nil {
+		return err
+	}
+	log.Printf("Import State Snapshot at Beacon Height %+v into %+v, checksum %+v", header.BeaconHeight, databaseDir, header.Checksum.String())
+	return nil
+}
+
+// migrateDatabase copies every record of a database to an empty database of another backend,
+// then reads the copy back and compares it with the source record by record through a checksum
+func migrateDatabase(dbType string, databaseDir string, outDBType string, outDatabaseDir string) error {
+	if dbType == outDBType && filepath.Clean(databaseDir) == filepath.Clean(outDatabaseDir) {
+		return errors.New("source and destination database are the same")
+	}
+	srcDB, err := database.Open(dbType, filepath.Join(databaseDir))
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	dstDB, err := database.Open(outDBType, filepath.Join(outDatabaseDir))
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+	errNotEmpty := errors.New("destination database is not empty")
+	err = dstDB.IterateRecordsByPrefix(nil, func(key, value []byte) error {
+		return errNotEmpty
+	})
+	if err != nil {
+		return err
+	}
+
+	const batchSize = 1000
+	srcHash := sha256.New()
+	batch := make([]database.BatchData, 0, batchSize)
+	count := 0
+	err = srcDB.IterateRecordsByPrefix(nil, func(key, value []byte) error {
+		writeMigrationRecord(srcHash, key, value)
+		batch = append(batch, database.BatchData{Key: append([]byte{}, key...), Value: append([]byte{}, value...)})
+		count++
+		if len(batch) < batchSize {
+			return nil
+		}
+		if err := dstDB.PutBatch(batch); err != nil {
+			return err
+		}
+		batch = make([]database.BatchData, 0, batchSize)
+		log.Printf("Migrated %+v records", count)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := dstDB.PutBatch(batch); err != nil {
+			return err
+		}
+	}
+
+	dstHash := sha256.New()
+	dstCount := 0
+	err = dstDB.IterateRecordsByPrefix(nil, func(key, value []byte) error {
+		writeMigrationRecord(dstHash, key, value)
+		dstCount++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if dstCount != count || !bytes.Equal(srcHash.Sum(nil), dstHash.Sum(nil)) {
+		return errors.Errorf("migrated database does not match source, source has %+v records, destination has %+v records", count, dstCount)
+	}
+	log.Printf("Migrate %+v records from %+v database %+v to %+v database %+v Successfully", count, dbType, databaseDir, outDBType, outDatabaseDir)
+	return nil
+}
+
+// writeMigrationRecord writes a length prefixed key and value, so different record splits never hash the same
+func writeMigrationRecord(writer io.Writer, key, value []byte) {
+	writer.Write(common.Uint32ToBytes(uint32(len(key))))
+	writer.Write(key)
+	writer.Write(common.Uint32ToBytes(uint32(len(value))))
+	writer.Write(value)
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCmdLoadParams(t *testing.T) {
@@ -11,3 +19,58 @@ func TestCmdLoadParams(t *testing.T) {
 	assert.NotEqual(t, nil, params)
 	assert.Equal(t, false, params.TestNet)
 }
+
+func TestMigrateDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "test_migratedb_")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	srcDir := filepath.Join(dir, "leveldb")
+	dstDir := filepath.Join(dir, "badgerdb")
+
+	srcDB, err := database.Open("leveldb", srcDir)
+	assert.Nil(t, err)
+	for i := 0; i < 2500; i++ {
+		assert.Nil(t, srcDB.Put([]byte{byte(i >> 8), byte(i)}, []byte{byte(i)}))
+	}
+	assert.Nil(t, srcDB.Close())
+
+	assert.Nil(t, migrateDatabase("leveldb", srcDir, "badgerdb", dstDir))
+	// destination is not empty anymore
+	assert.NotNil(t, migrateDatabase("leveldb", srcDir, "badgerdb", dstDir))
+
+	dstDB, err := database.Open("badgerdb", dstDir)
+	assert.Nil(t, err)
+	defer dstDB.Close()
+	count := 0
+	assert.Nil(t, dstDB.IterateRecordsByPrefix(nil, func(key, value []byte) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 2500, count)
+	value, err := dstDB.Get([]byte{9, 195})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{195}, value)
+}
+
+func TestConfirmUnsignedTransaction(t *testing.T) {
+	details := &transaction.UnsignedTxDetails{
+		SenderPaymentAddress: "sender",
+		PaymentInfos:         []transaction.UnsignedTxPayment{{PaymentAddress: "receiver", Amount: 1500}},
+		Change:               490,
+		Fee:                  10,
+	}
+
+	out := new(bytes.Buffer)
+	assert.NotNil(t, confirmUnsignedTransaction(details, false, strings.NewReader("\n"), out))
+	assert.Contains(t, out.String(), "Receiver: receiver, amount: 1500 nano PRV")
+	assert.Contains(t, out.String(), "Change: 490 nano PRV")
+	assert.Contains(t, out.String(), "Fee: 10 nano PRV")
+	assert.NotNil(t, confirmUnsignedTransaction(details, false, strings.NewReader(""), new(bytes.Buffer)))
+	assert.Nil(t, confirmUnsignedTransaction(details, false, strings.NewReader("y\n"), new(bytes.Buffer)))
+
+	// --yes prints the details without reading an answer
+	out.Reset()
+	assert.Nil(t, confirmUnsignedTransaction(details, true, strings.NewReader(""), out))
+	assert.Contains(t, out.String(), "Receiver: receiver")
+	assert.NotContains(t, out.String(), "[y/N]")
+}
@@ -35,15 +35,21 @@ type params struct {
 	// shardIDs:
 	// "all": process all shards
 	// 1,2,3,4: shard 1, shard 2, shard 3, shard 4
-	ShardIDs     string `long:"shardids" description:"Process one or many Shard Chain with ShardID"`
-	ChainDataDir string `long:"chaindatadir" description:"Directory of Stored Blockchain Database"`
-	OutDataDir   string `long:"outdatadir" description:"Directory of Export Blockchain Data"`
-	FileName     string `long:"filename" description:"Filename of Backup Blockchin Data"`
+	ShardIDs       string `long:"shardids" description:"Process one or many Shard Chain with ShardID"`
+	ChainDataDir   string `long:"chaindatadir" description:"Directory of Stored Blockchain Database"`
+	OutDataDir     string `long:"outdatadir" description:"Directory of Export Blockchain Data"`
+	FileName       string `long:"filename" description:"Filename of Backup Blockchin Data"`
+	SnapshotHeight uint64 `long:"snapshotheight" description:"Beacon height of state snapshot, default is the best beacon height"`
+	Checksum       string `long:"checksum" description:"Checksum of state snapshot printed by exportsnapshot on a trusted node, required by importsnapshot"`
+	DBType         string `long:"dbtype" description:"Database backend of chaindatadir, default is leveldb"`
+	OutDBType      string `long:"outdbtype" description:"Database backend to migrate chaindatadir to, default is badgerdb"`
 	// wallet
 	WalletName        string `long:"wallet" description:"Wallet Database Name file, default is 'wallet'"`
 	WalletPassphrase  string `long:"walletpassphrase" description:"Wallet passphrase"`
 	WalletAccountName string `long:"walletaccountname" description:"Wallet account name"`
 	ShardID           int8   `long:"shardid" description:"Process Shard Chain with ShardID"`
+	PrivateKey        string `long:"privatekey" description:"Private key signing the transaction, default is the key of walletaccountname"`
+	Yes               bool   `long:"yes" description:"Sign the transaction without asking to confirm its receivers, change and fee"`
 
 	// pToken
 	PNetwork string `long:"pNetwork" description:"Bridge network"`
@@ -58,8 +64,10 @@ func newConfigParser(cfg *params, options flags.Options) *flags.Parser {
 
 func loadParams() (*params, error) {
 	cfg := params{
-		DataDir: defaultDataDir,
-		TestNet: false,
+		DataDir:   defaultDataDir,
+		TestNet:   false,
+		DBType:    "leveldb",
+		OutDBType: "badgerdb",
 	}
 
 	preParser := newConfigParser(&cfg, flags.HelpFlag)
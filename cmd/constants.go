@@ -8,6 +8,10 @@ const (
 	getPrivacyTokenID      = "getprivacytokenid"
 	backupChain            = "backupchain"
 	restoreChain           = "restorechain"
+	exportSnapshot         = "exportsnapshot"
+	importSnapshot         = "importsnapshot"
+	migrateDB              = "migratedb"
+	signTransaction        = "signtransaction"
 )
 
 var CmdList = []string{
@@ -18,4 +22,8 @@ var CmdList = []string{
 	getPrivacyTokenID,
 	backupChain,
 	restoreChain,
+	exportSnapshot,
+	importSnapshot,
+	migrateDB,
+	signTransaction,
 }
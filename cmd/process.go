@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/incognitochain/incognito-chain/privacy"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 
@@ -213,5 +214,52 @@ func processCmd() {
 				}
 			}
 		}
+	case exportSnapshot:
+		{
+			err := exportStateSnapshot(cfg.ChainDataDir, cfg.SnapshotHeight, cfg.OutDataDir, cfg.FileName)
+			if err != nil {
+				log.Printf("Export state snapshot failed, err %+v", err)
+			}
+		}
+	case importSnapshot:
+		{
+			if cfg.FileName == "" {
+				log.Println("No Snapshot File to Process")
+				return
+			}
+			err := importStateSnapshot(cfg.ChainDataDir, cfg.FileName, cfg.Checksum)
+			if err != nil {
+				log.Printf("Import state snapshot failed, err %+v", err)
+			}
+		}
+	case migrateDB:
+		{
+			if cfg.ChainDataDir == "" || cfg.OutDataDir == "" {
+				log.Println("No Expected Params, chaindatadir and outdatadir are required")
+				return
+			}
+			err := migrateDatabase(cfg.DBType, cfg.ChainDataDir, cfg.OutDBType, cfg.OutDataDir)
+			if err != nil {
+				log.Printf("Migrate database failed, err %+v", err)
+			}
+		}
+	case signTransaction:
+		{
+			if cfg.FileName == "" {
+				log.Println("No Unsigned Transaction File to Process")
+				return
+			}
+			signedTx, err := signUnsignedTransaction(cfg.FileName, cfg.PrivateKey, cfg.WalletAccountName, cfg.Yes, os.Stdin, os.Stderr)
+			if err != nil {
+				log.Printf("Sign transaction failed, err %+v", err)
+				return
+			}
+			result, err := parseToJsonString(signedTx)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			log.Println(string(result))
+		}
 	}
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// SignedTransaction is the output of signtransaction, sendtransaction RPC accepts it as is
+type SignedTransaction struct {
+	TxID            string
+	Base58CheckData string
+	ShardID         byte
+	// coin commitment -> serial number of the spent coins, for createunsignedtransaction RPC
+	SerialNumbers map[string]string
+}
+
+// signUnsignedTransaction proves and signs the unsigned tx template in fileName, the output of createunsignedtransaction RPC.
+// The private key is privateKeyStr or, if it is empty, the one of the wallet account accountName.
+// The receivers, the change and the fee are printed to out first, the signer confirms them on in unless yes is set
+func signUnsignedTransaction(fileName string, privateKeyStr string, accountName string, yes bool, in io.Reader, out io.Writer) (*SignedTransaction, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	utx := new(transaction.UnsignedTx)
+	err = json.Unmarshal(data, utx)
+	if err != nil {
+		return nil, err
+	}
+	details, err := utx.Details()
+	if err != nil {
+		return nil, err
+	}
+	err = confirmUnsignedTransaction(details, yes, in, out)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKeyStr == "" {
+		if accountName == "" {
+			return nil, errors.New("private key or wallet account is required")
+		}
+		account, err := getAccount(accountName)
+		if err != nil {
+			return nil, err
+		}
+		privateKeyStr = account.(map[string]interface{})["PrivateKey"].(string)
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKeyStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyWallet.KeySet.PrivateKey) == 0 {
+		return nil, errors.New("private key is invalid")
+	}
+	senderSK := privacy.PrivateKey(keyWallet.KeySet.PrivateKey)
+
+	tx, serialNumbers, err := utx.Sign(&senderSK)
+	if err != nil {
+		return nil, err
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTransaction{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(txBytes, common.ZeroByte),
+		ShardID:         common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte()),
+		SerialNumbers:   serialNumbers,
+	}, nil
+}
+
+// confirmUnsignedTransaction prints what signing details pays and, unless yes is set, asks the signer to confirm it
+func confirmUnsignedTransaction(details *transaction.UnsignedTxDetails, yes bool, in io.Reader, out io.Writer) error {
+	fmt.Fprintf(out, "Sender: %s\n", details.SenderPaymentAddress)
+	for _, p := range details.PaymentInfos {
+		fmt.Fprintf(out, "Receiver: %s, amount: %d nano PRV\n", p.PaymentAddress, p.Amount)
+	}
+	fmt.Fprintf(out, "Change: %d nano PRV\n", details.Change)
+	fmt.Fprintf(out, "Fee: %d nano PRV\n", details.Fee)
+	if len(details.Info) > 0 {
+		fmt.Fprintf(out, "Info: %s\n", string(details.Info))
+	}
+	if yes {
+		return nil
+	}
+	fmt.Fprint(out, "Sign this transaction? [y/N]: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return errors.New("signing is not confirmed")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package common
+
+import "time"
+
+// Clock tells the current time, block producers and consensus read time from it
+// so simulations can replace the wall clock with one they move forward themselves
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the wall clock used by a running node
+var SystemClock Clock = systemClock{}
@@ -114,6 +114,10 @@ const (
 	PDEWithdrawalAcceptedStatus = 1
 	PDEWithdrawalRejectedStatus = 2
 
+	PDELimitOrderPlacedStatus = 1
+	PDELimitOrderFilledStatus = 2
+	PDELimitOrderRefundStatus = 3
+
 	MinTxFeesOnTokenRequirement = 10000000000000 // 10000 prv
 )
 
@@ -129,4 +133,71 @@ const (
 
 	PDEWithdrawalAcceptedChainStatus = "accepted"
 	PDEWithdrawalRejectedChainStatus = "rejected"
+
+	PDELimitOrderPlacedChainStatus = "placed"
+	PDELimitOrderFilledChainStatus = "accepted"
+	PDELimitOrderRefundChainStatus = "refund"
+
+	PDECancelLimitOrderAcceptedChainStatus = "accepted"
+	PDECancelLimitOrderRejectedChainStatus = "rejected"
 )
+
+// Portal statuses for RPCs
+const (
+	PortalNotFoundStatus = 0
+
+	PortalCustodianDepositAcceptedStatus = 1
+
+	PortalPortingReqWaitingStatus  = 1
+	PortalPortingReqRejectedStatus = 2
+	PortalPortingReqSuccessStatus  = 3
+	PortalPortingReqExpiredStatus  = 4
+
+	PortalReqPTokenAcceptedStatus = 1
+	PortalReqPTokenRejectedStatus = 2
+
+	PortalRedeemReqWaitingStatus    = 1
+	PortalRedeemReqRejectedStatus   = 2
+	PortalRedeemReqSuccessStatus    = 3
+	PortalRedeemReqLiquidatedStatus = 4
+
+	PortalReqUnlockCollateralAcceptedStatus = 1
+	PortalReqUnlockCollateralRejectedStatus = 2
+
+	PortalRelayingBTCHeaderAcceptedStatus = 1
+	PortalRelayingBTCHeaderRejectedStatus = 2
+)
+
+// Portal statuses for chain
+const (
+	PortalCustodianDepositAcceptedChainStatus = "accepted"
+
+	PortalPortingRequestAcceptedChainStatus = "accepted"
+	PortalPortingRequestRejectedChainStatus = "rejected"
+	PortalPortingRequestExpiredChainStatus  = "expired"
+
+	PortalReqPTokensAcceptedChainStatus = "accepted"
+	PortalReqPTokensRejectedChainStatus = "rejected"
+
+	PortalRedeemRequestAcceptedChainStatus = "accepted"
+	PortalRedeemRequestRejectedChainStatus = "rejected"
+
+	PortalReqUnlockCollateralAcceptedChainStatus = "accepted"
+	PortalReqUnlockCollateralRejectedChainStatus = "rejected"
+
+	PortalLiquidateCustodianSuccessChainStatus = "success"
+
+	PortalRelayingBTCHeaderAcceptedChainStatus = "accepted"
+	PortalRelayingBTCHeaderRejectedChainStatus = "rejected"
+)
+
+// Portal supported public tokens, amounts of ported tokens are in the smallest unit of the public chain (e.g. satoshi)
+const (
+	PortalBTCIDStr = "b832e5d3b1f01a4f0623f7fe91d6673461e1f5d37d91fe78c5c2e6183ff39696"
+	PortalBNBIDStr = "b2655152784e8639fa19521a7035f331eea1f1e911b2f3200a507ebb4554387b"
+)
+
+// only tokens whose external txs can be verified by beacon are supported
+var PortalSupportedTokenIDs = []string{
+	PortalBTCIDStr,
+}
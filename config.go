@@ -13,9 +13,11 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/rpcserver/rpcservice"
 	"github.com/jessevdk/go-flags"
 )
 
@@ -24,6 +26,7 @@ const (
 	DefaultConfigFilename              = "config.conf"
 	DefaultDataDirname                 = "data"
 	DefaultDatabaseDirname             = "block"
+	DefaultDatabaseType                = "leveldb"
 	DefaultDatabaseMempoolDirname      = "mempool"
 	DefaultLogLevel                    = "info"
 	DefaultLogDirname                  = "logs"
@@ -46,9 +49,11 @@ const (
 	DefaultEnableMining                = true
 	DefaultTxPoolTTL                   = uint(15 * 60) // 15 minutes
 	DefaultTxPoolMaxTx                 = uint64(100000)
-	DefaultLimitFee                    = uint64(1) // 1 nano PRV = 10^-9 PRV
+	DefaultTxPoolMaxSize               = uint64(200000) // kilobytes, 100 full blocks
+	DefaultLimitFee                    = uint64(1)      // 1 nano PRV = 10^-9 PRV
 	// For wallet
 	DefaultWalletName     = "wallet"
+	DefaultKeystoreDir    = "keystore"
 	DefaultPersistMempool = false
 	DefaultBtcClient      = 0
 	DefaultBtcClientPort  = "8332"
@@ -74,6 +79,7 @@ type config struct {
 	ConfigFile         string `short:"C" long:"configfile" description:"Path to configuration file"`
 	DataDir            string `short:"D" long:"datadir" description:"Directory to store data"`
 	DatabaseDir        string `short:"d" long:"datapre" description:"Database dir"`
+	DatabaseType       string `long:"dbtype" description:"Database backend {leveldb, badgerdb}, use cmd migratedb to move an existing database to another backend"`
 	DatabaseMempoolDir string `short:"m" long:"datamempool" description:"Mempool Database Dir"`
 	LogDir             string `short:"l" long:"logdir" description:"Directory to log output."`
 	LogLevel           string `long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
@@ -125,15 +131,25 @@ type config struct {
 	// For Wallet
 	Wallet           bool   `long:"enablewallet" description:"Enable wallet"`
 	WalletName       string `long:"wallet" description:"Wallet Database Name file, default is 'wallet'"`
-	WalletPassphrase string `long:"walletpassphrase" description:"Wallet passphrase"`
+	WalletPassphrase string `long:"walletpassphrase" description:"Wallet passphrase, used only to create the wallet or move a legacy wallet file into the keystore, use the walletpassphrase RPC to unlock it"`
 	WalletAutoInit   bool   `long:"walletautoinit" description:"Init wallet automatically if not exist"`
 	WalletShardID    int    `long:"walletshardid" description:"ShardID which wallet use to create account"`
-
-	FastStartup bool `long:"faststartup" description:"Load existed shard/chain dependencies instead of rebuild from block data"`
-
-	TxPoolTTL   uint   `long:"txpoolttl" description:"Set Time To Live (TTL) Value for transaction that enter pool"`
-	TxPoolMaxTx uint64 `long:"txpoolmaxtx" description:"Set Maximum number of transaction in pool"`
-	LimitFee    uint64 `long:"limitfee" description:"Limited fee for tx(per Kb data), default is 0.00 PRV"`
+	// consolidation of the output coins of the unlocked accounts
+	WalletConsolidateThreshold int           `long:"walletconsolidatethreshold" description:"Number of unspent output coins of an account and token above which the wallet merges them in the background, 0 disables it"`
+	WalletConsolidateBatchSize int           `long:"walletconsolidatebatchsize" description:"Max input coins of a consolidation tx"`
+	WalletConsolidateInterval  time.Duration `long:"walletconsolidateinterval" description:"Interval between two checks of the output coins of the wallet"`
+	WalletConsolidateFeePerKb  int64         `long:"walletconsolidatefeeperkb" description:"Fee per kb of the consolidation txs, -1 estimates it from the recent blocks"`
+
+	FastStartup      bool   `long:"faststartup" description:"Load existed shard/chain dependencies instead of rebuild from block data"`
+	ImportSnapshot   string `long:"importsnapshot" description:"Bootstrap an empty database from a state snapshot file (see chainctl exportsnapshot), then sync forward from the snapshot beacon height"`
+	SnapshotChecksum string `long:"snapshotchecksum" description:"Checksum of the state snapshot printed by exportsnapshot on a trusted node, required by importsnapshot"`
+	PruneBlockEpochs uint64 `long:"pruneblockepochs" description:"Discard block bodies and transaction indexes older than N epochs (at least 2), 0 keeps everything"`
+	TxHistoryIndex   bool   `long:"txhistoryindex" description:"Index the transactions of every public key for the gettransactionhistory RPC, only blocks stored while enabled are indexed"`
+
+	TxPoolTTL     uint   `long:"txpoolttl" description:"Set Time To Live (TTL) Value for transaction that enter pool"`
+	TxPoolMaxTx   uint64 `long:"txpoolmaxtx" description:"Set Maximum number of transaction in pool"`
+	TxPoolMaxSize uint64 `long:"txpoolmaxsize" description:"Set Maximum total size of transactions in pool in kilobytes, transactions with lowest fee rate are evicted when it is reached, 0 is unlimited"`
+	LimitFee      uint64 `long:"limitfee" description:"Limited fee for tx(per Kb data), default is 0.00 PRV"`
 
 	LoadMempool       bool   `long:"loadmempool" description:"Load transactions from Mempool database"`
 	PersistMempool    bool   `long:"persistmempool" description:"Persistence transaction in memepool database"`
@@ -148,6 +164,8 @@ type config struct {
 	PrivateKey        string `long:"privatekey" description:"your wallet privatekey"`
 	Accelerator       bool   `long:"accelerator" description:"Relay Node Configuration For Consensus"`
 
+	ConsensusEngines []string `long:"consensusengine" description:"Run a chain with another consensus implementation, <chain key>:<implementation> e.g. beacon:hotstuff or shard-0:hotstuff, may be repeated"`
+
 	// Highway
 	Libp2pPrivateKey string `long:"libp2pprivatekey" description:"Private key used to create node's PeerID, empty to generate random key each run"`
 }
@@ -314,12 +332,16 @@ func loadConfig() (*config, []string, error) {
 		RPCLimitRequestErrorPerHour: DefaultRPCLimitErrorRequestPerHour,
 		DataDir:                     defaultDataDir,
 		DatabaseDir:                 DefaultDatabaseDirname,
+		DatabaseType:                DefaultDatabaseType,
 		DatabaseMempoolDir:          DefaultDatabaseMempoolDirname,
 		LogDir:                      defaultLogDir,
 		RPCKey:                      defaultRPCKeyFile,
 		RPCCert:                     defaultRPCCertFile,
 		WalletShardID:               -1,
 		WalletName:                  DefaultWalletName,
+		WalletConsolidateBatchSize:  rpcservice.DefaultConsolidationBatchSize,
+		WalletConsolidateInterval:   rpcservice.DefaultConsolidationInterval,
+		WalletConsolidateFeePerKb:   -1,
 		DisableTLS:                  DefaultDisableRpcTLS,
 		DisableRPC:                  false,
 		RPCDisableAuth:              false,
@@ -332,6 +354,7 @@ func loadConfig() (*config, []string, error) {
 		FastStartup:                 DefaultFastStartup,
 		TxPoolTTL:                   DefaultTxPoolTTL,
 		TxPoolMaxTx:                 DefaultTxPoolMaxTx,
+		TxPoolMaxSize:               DefaultTxPoolMaxSize,
 		PersistMempool:              DefaultPersistMempool,
 		LimitFee:                    DefaultLimitFee,
 		MetricUrl:                   DefaultMetricUrl,
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ type BLSBFT struct {
 	BFTMessageCh     chan wire.MessageBFT
 	ProposeMessageCh chan BFTPropose
 	VoteMessageCh    chan BFTVote
+	TimeoutMessageCh chan BFTTimeout
 
 	RoundData struct {
 		Block             common.BlockInterface
@@ -50,10 +52,16 @@ type BLSBFT struct {
 	Blocks         map[string]common.BlockInterface
 	EarlyVotes     map[string]map[string]vote
 	lockEarlyVotes sync.Mutex
+	Timeouts       map[string]map[string]BFTTimeout // round key -> validator -> timeout, for rounds of the next height
+	signedMsgs     signedMessages                   // to detect double signing, see evidence.go
+	journal        *voteJournal                     // proposals and votes signed by this node, see journal.go
 	isOngoing      bool
 	isStarted      bool
+	isManual       bool
 	StopCh         chan struct{}
 	logger         common.Logger
+	// Clock tells the time rounds are measured with, the system clock when nil
+	Clock common.Clock
 }
 
 func (e *BLSBFT) IsOngoing() bool {
@@ -72,6 +80,8 @@ func (e *BLSBFT) Stop() error {
 		default:
 			close(e.StopCh)
 		}
+		// the journal stays closed rather than nil, a tick still running refuses to sign instead of signing unjournaled
+		e.journal.close()
 		e.isStarted = false
 		e.isOngoing = false
 	}
@@ -79,220 +89,281 @@ func (e *BLSBFT) Stop() error {
 }
 
 func (e *BLSBFT) Start() error {
+	if err := e.initActor(); err != nil {
+		return err
+	}
+	ticker := time.Tick(500 * time.Millisecond)
+	e.logger.Info("start bls-bft consensus for chain", e.ChainKey)
+	go func() {
+		fmt.Println("action")
+		for { //actor loop
+			select {
+			case <-e.StopCh:
+				return
+			case proposeMsg := <-e.ProposeMessageCh:
+				e.processProposeMsg(proposeMsg)
+			case msg := <-e.VoteMessageCh:
+				e.processVoteMsg(msg)
+			case msg := <-e.TimeoutMessageCh:
+				e.processTimeoutMsg(msg)
+			case <-ticker:
+				e.Tick()
+			}
+		}
+	}()
+	return nil
+}
+
+// StartManual starts the consensus without its actor loop, so whoever started it drives it:
+// messages given to ProcessBFTMsg are processed before it returns, messages to other nodes are pushed
+// before the call sending them returns and the round only moves forward when Tick is called.
+// Simulations use it with a Clock they control to run the consensus of many nodes deterministically.
+func (e *BLSBFT) StartManual() error {
+	if err := e.initActor(); err != nil {
+		return err
+	}
+	e.isManual = true
+	e.logger.Info("start manual bls-bft consensus for chain", e.ChainKey)
+	return nil
+}
+
+func (e *BLSBFT) initActor() error {
 	if e.isStarted {
 		return consensus.NewConsensusError(consensus.ConsensusAlreadyStartedError, errors.New(e.ChainKey))
 	}
+	if dataDir := e.Node.GetConsensusDataDir(); dataDir != "" {
+		journal, err := openVoteJournal(filepath.Join(dataDir, e.ChainKey+".journal"))
+		if err != nil {
+			return err
+		}
+		e.journal = journal
+	}
 	e.isStarted = true
 	e.isOngoing = false
+	e.isManual = false
 	e.StopCh = make(chan struct{})
 	e.EarlyVotes = make(map[string]map[string]vote)
 	e.Blocks = map[string]common.BlockInterface{}
+	e.Timeouts = make(map[string]map[string]BFTTimeout)
+	e.initSignedMessages()
 	e.ProposeMessageCh = make(chan BFTPropose)
 	e.VoteMessageCh = make(chan BFTVote)
+	e.TimeoutMessageCh = make(chan BFTTimeout)
 	e.InitRoundData()
+	return nil
+}
 
-	ticker := time.Tick(500 * time.Millisecond)
-	e.logger.Info("start bls-bft consensus for chain", e.ChainKey)
-	go func() {
-		fmt.Println("action")
-		for { //actor loop
-			select {
-			case <-e.StopCh:
+func (e *BLSBFT) processProposeMsg(proposeMsg BFTPropose) {
+	block, err := e.Chain.UnmarshalBlock(proposeMsg.Block)
+	if err != nil {
+		e.logger.Info(err)
+		return
+	}
+	e.recordProposal(block)
+	if proposeMsg.TimeoutCert != nil {
+		e.processTimeoutCert(proposeMsg.TimeoutCert)
+	}
+	blockRoundKey := getRoundKey(block.GetHeight(), block.GetRound())
+	e.logger.Info("receive block", blockRoundKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
+	if block.GetHeight() == e.RoundData.NextHeight {
+		if e.RoundData.Round == block.GetRound() {
+			if e.RoundData.Block == nil {
+				e.Blocks[blockRoundKey] = block
 				return
-			case proposeMsg := <-e.ProposeMessageCh:
-				block, err := e.Chain.UnmarshalBlock(proposeMsg.Block)
-				if err != nil {
-					e.logger.Info(err)
-					continue
-				}
-				blockRoundKey := getRoundKey(block.GetHeight(), block.GetRound())
-				e.logger.Info("receive block", blockRoundKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
-				if block.GetHeight() == e.RoundData.NextHeight {
-					if e.RoundData.Round == block.GetRound() {
-						if e.RoundData.Block == nil {
-							e.Blocks[blockRoundKey] = block
-							continue
-						}
-					} else {
-						if e.RoundData.Round < block.GetRound() {
-							e.Blocks[blockRoundKey] = block
-							continue
-						}
+			}
+		} else {
+			if e.RoundData.Round < block.GetRound() {
+				e.Blocks[blockRoundKey] = block
+				return
+			}
+		}
+		return
+	}
+	if block.GetHeight() > e.RoundData.NextHeight {
+		e.Blocks[blockRoundKey] = block
+		return
+	}
+}
+
+func (e *BLSBFT) processVoteMsg(msg BFTVote) {
+	e.logger.Info("Receive vote", msg.RoundKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
+	validatorIdx := common.IndexOfStr(msg.Validator, e.RoundData.CommitteeBLS.StringList)
+	if validatorIdx == -1 {
+		return
+	}
+	e.recordVote(msg)
+	height, round := parseRoundKey(msg.RoundKey)
+	if height < e.RoundData.NextHeight {
+		return
+	}
+	if (height == e.RoundData.NextHeight) && (round < e.RoundData.Round) {
+		return
+	}
+	// roundKey := getRoundKey(e.RoundData.NextHeight, e.RoundData.Round)
+	if (height == e.RoundData.NextHeight) && (round == e.RoundData.Round) {
+		//validate single sig
+		if !(new(common.Hash).IsEqual(&e.RoundData.BlockHash)) {
+			e.RoundData.lockVotes.Lock()
+			if _, ok := e.RoundData.Votes[msg.Validator]; !ok {
+				// committeeArr := []incognitokey.CommitteePublicKey{}
+				// committeeArr = append(committeeArr, e.RoundData.Committee...)
+				e.RoundData.lockVotes.Unlock()
+				validateVote := func(voteMsg BFTVote, blockHash common.Hash, committee []incognitokey.CommitteePublicKey) {
+					if err := e.preValidateVote(blockHash.GetBytes(), &(voteMsg.Vote), committee[validatorIdx].MiningPubKey[common.BridgeConsensus]); err != nil {
+						e.logger.Error(err)
+						return
 					}
-					continue
-				}
-				if block.GetHeight() > e.RoundData.NextHeight {
-					e.Blocks[blockRoundKey] = block
-					continue
-				}
-			case msg := <-e.VoteMessageCh:
-				e.logger.Info("Receive vote", msg.RoundKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
-				validatorIdx := common.IndexOfStr(msg.Validator, e.RoundData.CommitteeBLS.StringList)
-				if validatorIdx == -1 {
-					continue
-				}
-				height, round := parseRoundKey(msg.RoundKey)
-				if height < e.RoundData.NextHeight {
-					continue
-				}
-				if (height == e.RoundData.NextHeight) && (round < e.RoundData.Round) {
-					continue
-				}
-				// roundKey := getRoundKey(e.RoundData.NextHeight, e.RoundData.Round)
-				if (height == e.RoundData.NextHeight) && (round == e.RoundData.Round) {
-					//validate single sig
-					if !(new(common.Hash).IsEqual(&e.RoundData.BlockHash)) {
-						e.RoundData.lockVotes.Lock()
-						if _, ok := e.RoundData.Votes[msg.Validator]; !ok {
-							// committeeArr := []incognitokey.CommitteePublicKey{}
-							// committeeArr = append(committeeArr, e.RoundData.Committee...)
-							e.RoundData.lockVotes.Unlock()
-							go func(voteMsg BFTVote, blockHash common.Hash, committee []incognitokey.CommitteePublicKey) {
-								if err := e.preValidateVote(blockHash.GetBytes(), &(voteMsg.Vote), committee[validatorIdx].MiningPubKey[common.BridgeConsensus]); err != nil {
-									e.logger.Error(err)
-									return
-								}
-								if len(voteMsg.Vote.BRI) != 0 {
-									if err := validateSingleBriSig(&blockHash, voteMsg.Vote.BRI, committee[validatorIdx].MiningPubKey[common.BridgeConsensus]); err != nil {
-										e.logger.Error(err)
-										return
-									}
-								}
-								go func() {
-									voteCtnBytes, err := json.Marshal(voteMsg)
-									if err != nil {
-										e.logger.Error(consensus.NewConsensusError(consensus.UnExpectedError, err))
-										return
-									}
-									msg, _ := wire.MakeEmptyMessage(wire.CmdBFT)
-									msg.(*wire.MessageBFT).ChainKey = e.ChainKey
-									msg.(*wire.MessageBFT).Content = voteCtnBytes
-									msg.(*wire.MessageBFT).Type = MSG_VOTE
-									// TODO uncomment here when switch to non-highway mode
-									// e.Node.PushMessageToChain(msg, e.Chain)
-								}()
-								e.addVote(voteMsg)
-							}(msg, e.RoundData.BlockHash, append([]incognitokey.CommitteePublicKey{}, e.RoundData.Committee...))
-							continue
-						} else {
-							e.RoundData.lockVotes.Unlock()
-							continue
+					if len(voteMsg.Vote.BRI) != 0 {
+						if err := validateSingleBriSig(&blockHash, voteMsg.Vote.BRI, committee[validatorIdx].MiningPubKey[common.BridgeConsensus]); err != nil {
+							e.logger.Error(err)
+							return
 						}
 					}
+					go func() {
+						voteCtnBytes, err := json.Marshal(voteMsg)
+						if err != nil {
+							e.logger.Error(consensus.NewConsensusError(consensus.UnExpectedError, err))
+							return
+						}
+						msg, _ := wire.MakeEmptyMessage(wire.CmdBFT)
+						msg.(*wire.MessageBFT).ChainKey = e.ChainKey
+						msg.(*wire.MessageBFT).Content = voteCtnBytes
+						msg.(*wire.MessageBFT).Type = MSG_VOTE
+						// TODO uncomment here when switch to non-highway mode
+						// e.Node.PushMessageToChain(msg, e.Chain)
+					}()
+					e.addVote(voteMsg)
 				}
-				e.addEarlyVote(msg)
-
-			case <-ticker:
+				committee := append([]incognitokey.CommitteePublicKey{}, e.RoundData.Committee...)
+				if e.isManual {
+					validateVote(msg, e.RoundData.BlockHash, committee)
+				} else {
+					go validateVote(msg, e.RoundData.BlockHash, committee)
+				}
+				return
+			} else {
+				e.RoundData.lockVotes.Unlock()
+				return
+			}
+		}
+	}
+	e.addEarlyVote(msg)
+}
 
-				metrics.SetGlobalParam("RoundKey", getRoundKey(e.RoundData.NextHeight, e.RoundData.Round), "Phase", e.RoundData.State)
+// Tick moves the round forward: it enters the first round of the next height once a block is committed,
+// sends the timeout of the current round when it lasted too long, votes for the block proposed in this round
+// and commits it once a majority voted.
+// The actor loop calls it every 500ms, a consensus started with StartManual only when its caller does.
+func (e *BLSBFT) Tick() {
+	metrics.SetGlobalParam("RoundKey", getRoundKey(e.RoundData.NextHeight, e.RoundData.Round), "Phase", e.RoundData.State)
 
-				pubKey := e.UserKeySet.GetPublicKey()
-				if common.IndexOfStr(pubKey.GetMiningKeyBase58(consensusName), e.RoundData.CommitteeBLS.StringList) == -1 {
-					e.enterNewRound()
-					continue
-				}
+	pubKey := e.UserKeySet.GetPublicKey()
+	if common.IndexOfStr(pubKey.GetMiningKeyBase58(consensusName), e.RoundData.CommitteeBLS.StringList) == -1 {
+		e.enterNewRound()
+		return
+	}
 
-				if !e.Chain.IsReady() {
-					e.isOngoing = false
-					//fmt.Println("CONSENSUS: ticker 1")
-					continue
-				}
+	if !e.Chain.IsReady() {
+		e.isOngoing = false
+		//fmt.Println("CONSENSUS: ticker 1")
+		return
+	}
 
-				if !e.isInTimeFrame() || e.RoundData.State == "" {
-					e.enterNewRound()
-				}
+	if !e.isInTimeFrame() || e.RoundData.State == "" || e.RoundData.State == newround {
+		e.enterNewRound()
+	}
 
-				switch e.RoundData.State {
-				case listenPhase:
-					// timeout or vote nil?
-					//fmt.Println("CONSENSUS: listen phase 1")
-					if e.Chain.CurrentHeight() == e.RoundData.NextHeight {
-						e.enterNewRound()
-						continue
-					}
-					roundKey := getRoundKey(e.RoundData.NextHeight, e.RoundData.Round)
-					if e.Blocks[roundKey] != nil {
-						metrics.SetGlobalParam("ReceiveBlockTime", time.Since(e.RoundData.TimeStart).Seconds())
-						//fmt.Println("CONSENSUS: listen phase 2")
-						if err := e.validatePreSignBlock(e.Blocks[roundKey]); err != nil {
-							delete(e.Blocks, roundKey)
-							e.logger.Error(err)
-							continue
-						}
+	if e.RoundData.State != "" && e.RoundData.State != newround {
+		e.checkRoundTimeout()
+	}
 
-						if e.RoundData.Block == nil {
-							// blockData, _ := json.Marshal(e.Blocks[roundKey])
-							// msg, _ := MakeBFTProposeMsg(blockData, e.ChainKey, e.UserKeySet)
-							// go e.Node.PushMessageToChain(msg, e.Chain)
-
-							e.RoundData.Block = e.Blocks[roundKey]
-							e.RoundData.BlockHash = *e.RoundData.Block.Hash()
-							valData, err := DecodeValidationData(e.RoundData.Block.GetValidationField())
-							if err != nil {
-								e.logger.Error(err)
-								continue
-							}
-							e.RoundData.BlockValidateData = *valData
-							e.enterVotePhase()
-						}
-					}
-				case votePhase:
-					e.logger.Info("Case: In vote phase")
-					if e.RoundData.NotYetSendVote {
-						err := e.sendVote()
-						if err != nil {
-							e.logger.Error(err)
-							continue
-						}
-					}
-					if !(new(common.Hash).IsEqual(&e.RoundData.BlockHash)) && e.isHasMajorityVotes() {
-						e.RoundData.lockVotes.Lock()
-						aggSig, brigSigs, validatorIdx, err := combineVotes(e.RoundData.Votes, e.RoundData.CommitteeBLS.StringList)
-						e.RoundData.lockVotes.Unlock()
-						if err != nil {
-							e.logger.Error(err)
-							continue
-						}
+	switch e.RoundData.State {
+	case listenPhase:
+		// timeout or vote nil?
+		//fmt.Println("CONSENSUS: listen phase 1")
+		if e.Chain.CurrentHeight() == e.RoundData.NextHeight {
+			e.enterNewRound()
+			return
+		}
+		roundKey := getRoundKey(e.RoundData.NextHeight, e.RoundData.Round)
+		if e.Blocks[roundKey] != nil {
+			metrics.SetGlobalParam("ReceiveBlockTime", e.now().Sub(e.RoundData.TimeStart).Seconds())
+			//fmt.Println("CONSENSUS: listen phase 2")
+			if err := e.validatePreSignBlock(e.Blocks[roundKey]); err != nil {
+				delete(e.Blocks, roundKey)
+				e.logger.Error(err)
+				return
+			}
 
-						e.RoundData.BlockValidateData.AggSig = aggSig
-						e.RoundData.BlockValidateData.BridgeSig = brigSigs
-						e.RoundData.BlockValidateData.ValidatiorsIdx = validatorIdx
+			if e.RoundData.Block == nil {
+				// blockData, _ := json.Marshal(e.Blocks[roundKey])
+				// msg, _ := MakeBFTProposeMsg(blockData, e.ChainKey, e.UserKeySet)
+				// go e.Node.PushMessageToChain(msg, e.Chain)
 
-						validationDataString, _ := EncodeValidationData(e.RoundData.BlockValidateData)
-						e.RoundData.Block.(blockValidation).AddValidationField(validationDataString)
+				e.RoundData.Block = e.Blocks[roundKey]
+				e.RoundData.BlockHash = *e.RoundData.Block.Hash()
+				valData, err := DecodeValidationData(e.RoundData.Block.GetValidationField())
+				if err != nil {
+					e.logger.Error(err)
+					return
+				}
+				e.RoundData.BlockValidateData = *valData
+				e.enterVotePhase()
+			}
+		}
+	case votePhase:
+		e.logger.Info("Case: In vote phase")
+		if e.RoundData.NotYetSendVote {
+			err := e.sendVote()
+			if err != nil {
+				e.logger.Error(err)
+				return
+			}
+		}
+		if !(new(common.Hash).IsEqual(&e.RoundData.BlockHash)) && e.isHasMajorityVotes() {
+			e.RoundData.lockVotes.Lock()
+			aggSig, brigSigs, validatorIdx, err := combineVotes(e.RoundData.Votes, e.RoundData.CommitteeBLS.StringList)
+			e.RoundData.lockVotes.Unlock()
+			if err != nil {
+				e.logger.Error(err)
+				return
+			}
 
-						//TODO: check issue invalid sig when swap
-						//TODO 0xakk0r0kamui trace who is malicious node if ValidateCommitteeSig return false
-						err = e.ValidateCommitteeSig(e.RoundData.Block, e.RoundData.Committee)
-						if err != nil {
-							e.logger.Error(err)
-							e.logger.Errorf("e.RoundData.Block.GetValidationField()=%+v\n", e.RoundData.Block.GetValidationField())
-							e.logger.Errorf("e.RoundData.Committee=%+v\n", e.RoundData.Committee)
-							for _, member := range e.RoundData.Committee {
-								e.logger.Errorf("member.MiningPubKey[%+v] %+v\n", consensusName, base58.Base58Check{}.Encode(member.MiningPubKey[consensusName], common.Base58Version))
-							}
-							continue
-						}
+			e.RoundData.BlockValidateData.AggSig = aggSig
+			e.RoundData.BlockValidateData.BridgeSig = brigSigs
+			e.RoundData.BlockValidateData.ValidatiorsIdx = validatorIdx
+
+			validationDataString, _ := EncodeValidationData(e.RoundData.BlockValidateData)
+			e.RoundData.Block.(blockValidation).AddValidationField(validationDataString)
+
+			//TODO: check issue invalid sig when swap
+			//TODO 0xakk0r0kamui trace who is malicious node if ValidateCommitteeSig return false
+			err = e.ValidateCommitteeSig(e.RoundData.Block, e.RoundData.Committee)
+			if err != nil {
+				e.logger.Error(err)
+				e.logger.Errorf("e.RoundData.Block.GetValidationField()=%+v\n", e.RoundData.Block.GetValidationField())
+				e.logger.Errorf("e.RoundData.Committee=%+v\n", e.RoundData.Committee)
+				for _, member := range e.RoundData.Committee {
+					e.logger.Errorf("member.MiningPubKey[%+v] %+v\n", consensusName, base58.Base58Check{}.Encode(member.MiningPubKey[consensusName], common.Base58Version))
+				}
+				return
+			}
 
-						if err := e.Chain.InsertAndBroadcastBlock(e.RoundData.Block); err != nil {
-							e.logger.Error(err)
-							if blockchainError, ok := err.(*blockchain.BlockChainError); ok {
-								if blockchainError.Code != blockchain.ErrCodeMessage[blockchain.DuplicateShardBlockError].Code {
-									e.logger.Error(err)
-								}
-							}
-							continue
-						}
-						metrics.SetGlobalParam("CommitTime", time.Since(time.Unix(e.Chain.GetLastBlockTimeStamp(), 0)).Seconds())
-						// e.Node.PushMessageToAll()
-						e.logger.Infof("Commit block (%d votes) %+v hash=%+v \n Wait for next round", len(e.RoundData.Votes), e.RoundData.Block.GetHeight(), e.RoundData.Block.Hash().String())
-						e.enterNewRound()
+			if err := e.Chain.InsertAndBroadcastBlock(e.RoundData.Block); err != nil {
+				e.logger.Error(err)
+				if blockchainError, ok := err.(*blockchain.BlockChainError); ok {
+					if blockchainError.Code != blockchain.ErrCodeMessage[blockchain.DuplicateShardBlockError].Code {
+						e.logger.Error(err)
 					}
 				}
+				return
 			}
+			metrics.SetGlobalParam("CommitTime", e.now().Sub(time.Unix(e.Chain.GetLastBlockTimeStamp(), 0)).Seconds(), "CommitRoundChanges", e.RoundData.Round-1)
+			// e.Node.PushMessageToAll()
+			e.logger.Infof("Commit block (%d votes) %+v hash=%+v \n Wait for next round", len(e.RoundData.Votes), e.RoundData.Block.GetHeight(), e.RoundData.Block.Hash().String())
+			e.enterNewRound()
 		}
-	}()
-	return nil
+	}
 }
 
 func (e *BLSBFT) enterProposePhase() {
@@ -302,7 +373,7 @@ func (e *BLSBFT) enterProposePhase() {
 	e.setState(proposePhase)
 	e.isOngoing = true
 	block, err := e.createNewBlock()
-	metrics.SetGlobalParam("CreateTime", time.Since(e.RoundData.TimeStart).Seconds())
+	metrics.SetGlobalParam("CreateTime", e.now().Sub(e.RoundData.TimeStart).Seconds())
 	if err != nil {
 		e.isOngoing = false
 		e.logger.Error("can't create block", err)
@@ -312,6 +383,11 @@ func (e *BLSBFT) enterProposePhase() {
 	if e.Chain.CurrentHeight()+1 != block.GetHeight() {
 		return
 	}
+	if err := e.journal.recordSign(journalPropose, block.GetHeight(), block.GetRound(), *block.Hash()); err != nil {
+		e.isOngoing = false
+		e.logger.Error("can't propose block", err)
+		return
+	}
 	validationData := e.CreateValidationData(block)
 	validationDataString, _ := EncodeValidationData(validationData)
 	block.(blockValidation).AddValidationField(validationDataString)
@@ -319,11 +395,16 @@ func (e *BLSBFT) enterProposePhase() {
 	e.RoundData.Block = block
 	e.RoundData.BlockHash = *block.Hash()
 	e.RoundData.BlockValidateData = validationData
+	e.recordProposal(block)
 
+	var timeoutCert *TimeoutCert
+	if e.RoundData.Round > 1 {
+		timeoutCert = e.getTimeoutCert(getRoundKey(e.RoundData.NextHeight, e.RoundData.Round-1))
+	}
 	blockData, _ := json.Marshal(e.RoundData.Block)
-	msg, _ := MakeBFTProposeMsg(blockData, e.ChainKey, e.UserKeySet)
+	msg, _ := MakeBFTProposeMsg(blockData, timeoutCert, e.ChainKey, e.UserKeySet)
 	// e.logger.Info("push block", time.Since(time1).Seconds())
-	go e.Node.PushMessageToChain(msg, e.Chain)
+	e.pushMessageToChain(msg)
 	e.enterVotePhase()
 }
 
@@ -354,7 +435,7 @@ func (e *BLSBFT) enterNewRound() {
 		return
 	}
 	//if already running a round for current timeframe
-	if e.isInTimeFrame() && e.RoundData.State != newround {
+	if e.isInTimeFrame() && e.RoundData.State != newround && e.RoundData.State != "" {
 		return
 	}
 	e.isOngoing = false
@@ -366,6 +447,11 @@ func (e *BLSBFT) enterNewRound() {
 	e.logger.Info("")
 	e.logger.Info("============================================")
 	e.logger.Info("")
+	e.startRound()
+}
+
+// startRound - propose when this node is the proposer of the current round, listen for the proposal otherwise
+func (e *BLSBFT) startRound() {
 	pubKey := e.UserKeySet.GetPublicKey()
 	if e.Chain.GetPubKeyCommitteeIndex(pubKey.GetMiningKeyBase58(consensusName)) == (e.Chain.GetLastProposerIndex()+e.RoundData.Round)%e.Chain.GetCommitteeSize() {
 		e.logger.Info("BFT: new round => PROPOSE", e.RoundData.NextHeight, e.RoundData.Round)
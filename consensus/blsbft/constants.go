@@ -14,8 +14,12 @@ const (
 	consensusName = common.BlsConsensus
 )
 
-//
+// a round times out after roundTimeout, doubled at each round of the same height up to maxRoundTimeout,
+// so the timeout is back to roundTimeout once a block is committed
 const (
-	timeout             = 40 * time.Second       // must be at least twice the time of block interval
-	maxNetworkDelayTime = 150 * time.Millisecond // in ms
+	roundTimeout    = 20 * time.Second // must cover block creation and a round of votes
+	maxRoundTimeout = 5 * time.Minute
+	// a timeout alone is taken for rounds up to timeoutRoundWindow after the current round, so a validator can't
+	// fill memory with timeouts of far rounds, later rounds are only entered with a timeout certificate
+	timeoutRoundWindow = 3
 )
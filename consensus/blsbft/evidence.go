@@ -0,0 +1,225 @@
+package blsbft
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/pkg/errors"
+)
+
+// proposals and votes of the last evidenceKeepHeights heights are kept to detect double signing
+const evidenceKeepHeights = 3
+
+// signedMessages - first proposals and votes signed by each validator in a round
+type signedMessages struct {
+	proposals map[string]map[common.Hash]common.BlockInterface // round key -> block hash -> block
+	votes     map[string]map[string]BFTVote                    // round key -> validator -> vote
+	reported  map[string]map[string]bool                       // round key -> double signers
+}
+
+func (e *BLSBFT) initSignedMessages() {
+	e.signedMsgs.proposals = make(map[string]map[common.Hash]common.BlockInterface)
+	e.signedMsgs.votes = make(map[string]map[string]BFTVote)
+	e.signedMsgs.reported = make(map[string]map[string]bool)
+}
+
+// pruneSignedMessages - forget messages of heights which are too old to be punished
+func (e *BLSBFT) pruneSignedMessages() {
+	isOld := func(roundKey string) bool {
+		height, _ := parseRoundKey(roundKey)
+		return height+evidenceKeepHeights < e.RoundData.NextHeight
+	}
+	for roundKey := range e.signedMsgs.proposals {
+		if isOld(roundKey) {
+			delete(e.signedMsgs.proposals, roundKey)
+		}
+	}
+	for roundKey := range e.signedMsgs.votes {
+		if isOld(roundKey) {
+			delete(e.signedMsgs.votes, roundKey)
+		}
+	}
+	for roundKey := range e.signedMsgs.reported {
+		if isOld(roundKey) {
+			delete(e.signedMsgs.reported, roundKey)
+		}
+	}
+}
+
+// recordProposal - keep a block with valid producer signature, report its producer if it proposed another block in the same round
+func (e *BLSBFT) recordProposal(block common.BlockInterface) {
+	if block.GetHeight()+evidenceKeepHeights < e.RoundData.NextHeight {
+		return
+	}
+	roundKey := getRoundKey(block.GetHeight(), block.GetRound())
+	blockHash := *block.Hash()
+	if _, ok := e.signedMsgs.proposals[roundKey]; !ok {
+		e.signedMsgs.proposals[roundKey] = make(map[common.Hash]common.BlockInterface)
+	}
+	proposals := e.signedMsgs.proposals[roundKey]
+	if _, ok := proposals[blockHash]; ok {
+		return
+	}
+	if err := e.ValidateProducerSig(block); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	proposals[blockHash] = block
+	for hash, otherBlock := range proposals {
+		if hash == blockHash || otherBlock.GetProducer() != block.GetProducer() {
+			continue
+		}
+		evidence, err := e.newDoubleProposeEvidence(otherBlock, block)
+		if err != nil {
+			e.logger.Error(err)
+			return
+		}
+		e.reportDoubleSign(roundKey, evidence)
+		return
+	}
+}
+
+// recordVote - keep the first vote of a validator in a round, report the validator if it votes for another block in the round
+func (e *BLSBFT) recordVote(msg BFTVote) {
+	if msg.BlockHash == "" {
+		return
+	}
+	height, _ := parseRoundKey(msg.RoundKey)
+	if height+evidenceKeepHeights < e.RoundData.NextHeight {
+		return
+	}
+	validatorIdx := common.IndexOfStr(msg.Validator, e.RoundData.CommitteeBLS.StringList)
+	if validatorIdx == -1 {
+		return
+	}
+	if _, ok := e.signedMsgs.votes[msg.RoundKey]; !ok {
+		e.signedMsgs.votes[msg.RoundKey] = make(map[string]BFTVote)
+	}
+	votes := e.signedMsgs.votes[msg.RoundKey]
+	firstVote, ok := votes[msg.Validator]
+	if !ok {
+		votes[msg.Validator] = msg
+		return
+	}
+	if firstVote.BlockHash == msg.BlockHash {
+		return
+	}
+	// conflicting votes are checked only now, a forged vote must not hide a real one
+	candidate := e.RoundData.Committee[validatorIdx].MiningPubKey[common.BridgeConsensus]
+	if err := e.validateVoteOfBlock(msg, candidate); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if err := e.validateVoteOfBlock(firstVote, candidate); err != nil {
+		votes[msg.Validator] = msg
+		return
+	}
+	signer, err := e.RoundData.Committee[validatorIdx].ToBase58()
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	evidence, err := e.newDoubleVoteEvidence(signer, firstVote, msg)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.reportDoubleSign(msg.RoundKey, evidence)
+}
+
+func (e *BLSBFT) validateVoteOfBlock(msg BFTVote, candidate []byte) error {
+	blockHash, err := common.Hash{}.NewHashFromStr(msg.BlockHash)
+	if err != nil {
+		return consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	return e.preValidateVote(blockHash.GetBytes(), &msg.Vote, candidate)
+}
+
+func (e *BLSBFT) newDoubleProposeEvidence(block1 common.BlockInterface, block2 common.BlockInterface) (*blockchain.DoubleSignEvidence, error) {
+	evidence := &blockchain.DoubleSignEvidence{
+		Type:    blockchain.DoubleProposeEvidence,
+		ShardID: e.Chain.GetShardID(),
+		Signer:  block1.GetProducer(),
+	}
+	for i, block := range []common.BlockInterface{block1, block2} {
+		header, err := getBlockHeader(block)
+		if err != nil {
+			return nil, err
+		}
+		valData, err := DecodeValidationData(block.GetValidationField())
+		if err != nil {
+			return nil, err
+		}
+		evidence.Headers[i] = header
+		evidence.Signatures[i] = blockchain.DoubleSignSignature{Sig: valData.ProducerBLSSig}
+	}
+	return evidence, nil
+}
+
+// newDoubleVoteEvidence - evidence of votes for two blocks, both blocks must have been proposed to this node
+func (e *BLSBFT) newDoubleVoteEvidence(signer string, vote1 BFTVote, vote2 BFTVote) (*blockchain.DoubleSignEvidence, error) {
+	evidence := &blockchain.DoubleSignEvidence{
+		Type:    blockchain.DoubleVoteEvidence,
+		ShardID: e.Chain.GetShardID(),
+		Signer:  signer,
+	}
+	for i, msg := range []BFTVote{vote1, vote2} {
+		blockHash, err := common.Hash{}.NewHashFromStr(msg.BlockHash)
+		if err != nil {
+			return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+		}
+		block, ok := e.signedMsgs.proposals[msg.RoundKey][*blockHash]
+		if !ok {
+			return nil, consensus.NewConsensusError(consensus.UnExpectedError, errors.Errorf("block %+v voted by %+v is not received", msg.BlockHash, msg.Validator))
+		}
+		header, err := getBlockHeader(block)
+		if err != nil {
+			return nil, err
+		}
+		evidence.Headers[i] = header
+		evidence.Signatures[i] = blockchain.DoubleSignSignature{Sig: msg.Vote.Confirmation, BLS: msg.Vote.BLS, BRI: msg.Vote.BRI}
+	}
+	return evidence, nil
+}
+
+// reportDoubleSign - keep evidence in pool of chain and send it to beacon committee, once per signer in a round
+func (e *BLSBFT) reportDoubleSign(roundKey string, evidence *blockchain.DoubleSignEvidence) {
+	if _, ok := e.signedMsgs.reported[roundKey]; !ok {
+		e.signedMsgs.reported[roundKey] = make(map[string]bool)
+	}
+	if e.signedMsgs.reported[roundKey][evidence.Signer] {
+		return
+	}
+	e.signedMsgs.reported[roundKey][evidence.Signer] = true
+	e.logger.Warnf("Double %+v of %+v in round %+v", evidence.Type, evidence.Signer, roundKey)
+	if err := e.Chain.AddDoubleSignEvidence(evidence); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	msg, err := MakeBFTEvidenceMsg(evidence)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if e.isManual {
+		e.Node.PushMessageToBeacon(msg, nil)
+		return
+	}
+	go e.Node.PushMessageToBeacon(msg, nil)
+}
+
+func getBlockHeader(block common.BlockInterface) (json.RawMessage, error) {
+	blockData, err := json.Marshal(block)
+	if err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	var blockWithHeader struct {
+		Header json.RawMessage
+	}
+	if err := json.Unmarshal(blockData, &blockWithHeader); err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	return blockWithHeader.Header, nil
+}
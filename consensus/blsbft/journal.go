@@ -0,0 +1,257 @@
+package blsbft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/pkg/errors"
+)
+
+/*
+Vote journal of a chain:
+- Every proposal and vote this node signs is appended to the journal and synced to disk before it is sent,
+so a node restarting in the middle of a round knows what it signed before the restart
+- Signing another block in a round which has a proposal or a vote for a block in the journal is refused,
+signing the same block again is allowed
+- Record format: version(1) | op(1) | height(8) | round(4) | blockHash(32) | crc32(4).
+A record which is incomplete or corrupted (e.g. written while node is crashed) is dropped when the journal is loaded
+- The journal is rewritten with records of heights not yet decided once it has journalCompactSize records
+*/
+
+const journalVersion = byte(1)
+
+// journal record operations
+const (
+	journalPropose = byte(1)
+	journalVote    = byte(2)
+)
+
+const (
+	journalRecordSize  = 1 + 1 + 8 + 4 + common.HashSize + 4
+	journalCompactSize = 1000
+)
+
+type journalRecord struct {
+	Op        byte
+	Height    uint64
+	Round     int
+	BlockHash common.Hash
+}
+
+func (record journalRecord) bytes() []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(journalVersion)
+	buf.WriteByte(record.Op)
+	_ = binary.Write(buf, binary.BigEndian, record.Height)
+	_ = binary.Write(buf, binary.BigEndian, uint32(record.Round))
+	buf.Write(record.BlockHash[:])
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes()
+}
+
+func parseJournalRecord(data []byte) (*journalRecord, error) {
+	if len(data) != journalRecordSize {
+		return nil, errors.Errorf("record size %d instead of %d", len(data), journalRecordSize)
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("checksum mismatch")
+	}
+	if body[0] != journalVersion {
+		return nil, errors.Errorf("unsupported version %d", body[0])
+	}
+	record := &journalRecord{
+		Op:     body[1],
+		Height: binary.BigEndian.Uint64(body[2:10]),
+		Round:  int(binary.BigEndian.Uint32(body[10:14])),
+	}
+	if record.Op != journalPropose && record.Op != journalVote {
+		return nil, errors.Errorf("unknown operation %d", record.Op)
+	}
+	copy(record.BlockHash[:], body[14:])
+	return record, nil
+}
+
+type voteJournal struct {
+	mtx     sync.Mutex
+	path    string
+	file    *os.File
+	records []journalRecord
+	signed  map[byte]map[string]common.Hash // op -> round key -> block hash
+}
+
+// openVoteJournal - load the journal of path and open it to append records, it is created when it does not exist
+func openVoteJournal(path string) (*voteJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	journal := &voteJournal{
+		path: path,
+		signed: map[byte]map[string]common.Hash{
+			journalPropose: {},
+			journalVote:    {},
+		},
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	for offset := 0; offset+journalRecordSize <= len(data); offset += journalRecordSize {
+		record, err := parseJournalRecord(data[offset : offset+journalRecordSize])
+		if err != nil {
+			continue
+		}
+		journal.add(*record)
+	}
+	// rewrite the journal so the records appended next are not behind an incomplete one
+	if err := journal.rewrite(journal.records); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+func (journal *voteJournal) add(record journalRecord) {
+	journal.records = append(journal.records, record)
+	journal.signed[record.Op][getRoundKey(record.Height, record.Round)] = record.BlockHash
+}
+
+// rewrite - replace the journal file by records, the new file is renamed over the old one so a crash leaves either of them
+func (journal *voteJournal) rewrite(records []journalRecord) error {
+	if journal.file != nil {
+		journal.file.Close()
+		journal.file = nil
+	}
+	data := []byte{}
+	for _, record := range records {
+		data = append(data, record.bytes()...)
+	}
+	tmpPath := journal.path + ".tmp"
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	if err := os.Rename(tmpPath, journal.path); err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	if err := syncDir(filepath.Dir(journal.path)); err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	file, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	journal.file = file
+	return nil
+}
+
+// writeFileSync - write data to a new file of path and sync it, so it is complete on disk before it is renamed
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir - sync the entries of dir, so a file renamed in it is still renamed after a crash
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// recordSign - append the proposal or vote of blockHash in a round before it is sent,
+// fail when this node signed another block for the same operation in the round
+func (journal *voteJournal) recordSign(op byte, height uint64, round int, blockHash common.Hash) error {
+	if journal == nil {
+		return nil
+	}
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	roundKey := getRoundKey(height, round)
+	if signedHash, ok := journal.signed[op][roundKey]; ok {
+		if signedHash == blockHash {
+			return nil
+		}
+		return consensus.NewConsensusError(consensus.ConflictingSignError, errors.Errorf("block %+v signed in round %+v before", signedHash.String(), roundKey))
+	}
+	if journal.file == nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, errors.New("journal is closed"))
+	}
+	record := journalRecord{
+		Op:        op,
+		Height:    height,
+		Round:     round,
+		BlockHash: blockHash,
+	}
+	if _, err := journal.file.Write(record.bytes()); err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	if err := journal.file.Sync(); err != nil {
+		return consensus.NewConsensusError(consensus.VoteJournalError, err)
+	}
+	journal.add(record)
+	return nil
+}
+
+// prune - drop the records of heights before height once the journal is big enough to be rewritten
+func (journal *voteJournal) prune(height uint64) error {
+	if journal == nil {
+		return nil
+	}
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	if journal.file == nil || len(journal.records) < journalCompactSize {
+		return nil
+	}
+	records := []journalRecord{}
+	for _, record := range journal.records {
+		if record.Height >= height {
+			records = append(records, record)
+		}
+	}
+	if err := journal.rewrite(records); err != nil {
+		return err
+	}
+	journal.records = nil
+	for op := range journal.signed {
+		journal.signed[op] = make(map[string]common.Hash)
+	}
+	for _, record := range records {
+		journal.add(record)
+	}
+	return nil
+}
+
+func (journal *voteJournal) close() {
+	if journal == nil {
+		return
+	}
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	if journal.file != nil {
+		journal.file.Close()
+		journal.file = nil
+	}
+}
@@ -0,0 +1,129 @@
+package blsbft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestJournalDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "votejournal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func assertConflict(t *testing.T, err error) {
+	consensusErr, ok := err.(*consensus.ConsensusError)
+	if assert.True(t, ok, "%+v", err) {
+		assert.Equal(t, consensus.ErrCodeMessage[consensus.ConflictingSignError].Code, consensusErr.Code)
+	}
+}
+
+func TestVoteJournal_recordSign(t *testing.T) {
+	dir := newTestJournalDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacon.journal")
+	hashA := common.HashH([]byte("a"))
+	hashB := common.HashH([]byte("b"))
+
+	journal, err := openVoteJournal(path)
+	assert.Nil(t, err)
+	assert.Nil(t, journal.recordSign(journalPropose, 10, 1, hashA))
+	assert.Nil(t, journal.recordSign(journalVote, 10, 1, hashA))
+	// signing the same block again is not a conflict
+	assert.Nil(t, journal.recordSign(journalVote, 10, 1, hashA))
+	assertConflict(t, journal.recordSign(journalVote, 10, 1, hashB))
+	assert.Nil(t, journal.recordSign(journalVote, 10, 2, hashB))
+	journal.close()
+
+	// a restarted node still refuses to sign another block in the rounds it signed
+	journal, err = openVoteJournal(path)
+	assert.Nil(t, err)
+	defer journal.close()
+	assert.Equal(t, 3, len(journal.records))
+	assertConflict(t, journal.recordSign(journalPropose, 10, 1, hashB))
+	assertConflict(t, journal.recordSign(journalVote, 10, 2, hashA))
+	assert.Nil(t, journal.recordSign(journalVote, 11, 1, hashB))
+}
+
+func TestVoteJournal_corruptedRecord(t *testing.T) {
+	dir := newTestJournalDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacon.journal")
+	hashA := common.HashH([]byte("a"))
+	hashB := common.HashH([]byte("b"))
+
+	journal, err := openVoteJournal(path)
+	assert.Nil(t, err)
+	assert.Nil(t, journal.recordSign(journalVote, 10, 1, hashA))
+	assert.Nil(t, journal.recordSign(journalVote, 10, 2, hashA))
+	journal.close()
+
+	// the second record is corrupted and a record is cut by a crash
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	data[journalRecordSize+5] ^= 0xff
+	data = append(data, journalRecord{Op: journalVote, Height: 10, Round: 3, BlockHash: hashA}.bytes()[:10]...)
+	assert.Nil(t, ioutil.WriteFile(path, data, 0600))
+
+	journal, err = openVoteJournal(path)
+	assert.Nil(t, err)
+	defer journal.close()
+	assert.Equal(t, 1, len(journal.records))
+	assertConflict(t, journal.recordSign(journalVote, 10, 1, hashB))
+	assert.Nil(t, journal.recordSign(journalVote, 10, 3, hashB))
+
+	journal.close()
+	journal, err = openVoteJournal(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(journal.records))
+}
+
+func TestVoteJournal_prune(t *testing.T) {
+	dir := newTestJournalDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacon.journal")
+	hashA := common.HashH([]byte("a"))
+	hashB := common.HashH([]byte("b"))
+
+	journal, err := openVoteJournal(path)
+	assert.Nil(t, err)
+	defer journal.close()
+	for height := uint64(1); height <= journalCompactSize; height++ {
+		assert.Nil(t, journal.recordSign(journalVote, height, 1, hashA))
+	}
+	assert.Nil(t, journal.prune(journalCompactSize))
+	assert.Equal(t, 1, len(journal.records))
+	assert.Nil(t, journal.recordSign(journalVote, 1, 1, hashB))
+	assertConflict(t, journal.recordSign(journalVote, journalCompactSize, 1, hashB))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2*journalRecordSize), info.Size())
+}
+
+func TestVoteJournal_closed(t *testing.T) {
+	dir := newTestJournalDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacon.journal")
+	hashA := common.HashH([]byte("a"))
+
+	journal, err := openVoteJournal(path)
+	assert.Nil(t, err)
+	journal.close()
+	// a consensus stopped while signing must not sign without journaling
+	err = journal.recordSign(journalVote, 10, 1, hashA)
+	consensusErr, ok := err.(*consensus.ConsensusError)
+	if assert.True(t, ok, "%+v", err) {
+		assert.Equal(t, consensus.ErrCodeMessage[consensus.VoteJournalError].Code, consensusErr.Code)
+	}
+	assert.Nil(t, journal.prune(10))
+	assert.Nil(t, journal.file)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/incognitochain/incognito-chain/blockchain"
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/consensus"
 	"github.com/incognitochain/incognito-chain/metadata"
@@ -11,23 +12,28 @@ import (
 )
 
 const (
-	MSG_PROPOSE = "propose"
-	MSG_VOTE    = "vote"
+	MSG_PROPOSE  = "propose"
+	MSG_VOTE     = "vote"
+	MSG_EVIDENCE = "evidence"
+	MSG_TIMEOUT  = "timeout"
 )
 
 type BFTPropose struct {
-	Block json.RawMessage
+	Block       json.RawMessage
+	TimeoutCert *TimeoutCert `json:",omitempty"` // certificate of the previous round, the proposal of round 1 has none
 }
 
 type BFTVote struct {
 	RoundKey  string
 	Validator string
+	BlockHash string // block voted for, votes of a validator for different blocks in a round are double signing evidence
 	Vote      vote
 }
 
-func MakeBFTProposeMsg(block []byte, chainKey string, userKeySet *MiningKey) (wire.Message, error) {
+func MakeBFTProposeMsg(block []byte, timeoutCert *TimeoutCert, chainKey string, userKeySet *MiningKey) (wire.Message, error) {
 	var proposeCtn BFTPropose
 	proposeCtn.Block = block
+	proposeCtn.TimeoutCert = timeoutCert
 	proposeCtnBytes, err := json.Marshal(proposeCtn)
 	if err != nil {
 		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
@@ -39,10 +45,11 @@ func MakeBFTProposeMsg(block []byte, chainKey string, userKeySet *MiningKey) (wi
 	return msg, nil
 }
 
-func MakeBFTVoteMsg(userPublicKey string, chainKey, roundKey string, vote vote) (wire.Message, error) {
+func MakeBFTVoteMsg(userPublicKey string, chainKey, roundKey string, blockHash common.Hash, vote vote) (wire.Message, error) {
 	var voteCtn BFTVote
 	voteCtn.RoundKey = roundKey
 	voteCtn.Validator = userPublicKey
+	voteCtn.BlockHash = blockHash.String()
 	voteCtn.Vote = vote
 	voteCtnBytes, err := json.Marshal(voteCtn)
 	if err != nil {
@@ -55,6 +62,31 @@ func MakeBFTVoteMsg(userPublicKey string, chainKey, roundKey string, vote vote)
 	return msg, nil
 }
 
+func MakeBFTTimeoutMsg(chainKey string, timeout BFTTimeout) (wire.Message, error) {
+	timeoutCtnBytes, err := json.Marshal(timeout)
+	if err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	msg, _ := wire.MakeEmptyMessage(wire.CmdBFT)
+	msg.(*wire.MessageBFT).ChainKey = chainKey
+	msg.(*wire.MessageBFT).Content = timeoutCtnBytes
+	msg.(*wire.MessageBFT).Type = MSG_TIMEOUT
+	return msg, nil
+}
+
+// MakeBFTEvidenceMsg - message of double sign evidence, it is handled by beacon committee which punishes the signer
+func MakeBFTEvidenceMsg(evidence *blockchain.DoubleSignEvidence) (wire.Message, error) {
+	evidenceCtnBytes, err := json.Marshal(evidence)
+	if err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	msg, _ := wire.MakeEmptyMessage(wire.CmdBFT)
+	msg.(*wire.MessageBFT).ChainKey = common.BeaconChainKey
+	msg.(*wire.MessageBFT).Content = evidenceCtnBytes
+	msg.(*wire.MessageBFT).Type = MSG_EVIDENCE
+	return msg, nil
+}
+
 //TODO merman
 func (e *BLSBFT) ProcessBFTMsg(msg *wire.MessageBFT) {
 	switch msg.Type {
@@ -65,6 +97,10 @@ func (e *BLSBFT) ProcessBFTMsg(msg *wire.MessageBFT) {
 			fmt.Println(err)
 			return
 		}
+		if e.isManual {
+			e.processProposeMsg(msgPropose)
+			return
+		}
 		e.ProposeMessageCh <- msgPropose
 	case MSG_VOTE:
 		var msgVote BFTVote
@@ -73,7 +109,33 @@ func (e *BLSBFT) ProcessBFTMsg(msg *wire.MessageBFT) {
 			fmt.Println(err)
 			return
 		}
+		if e.isManual {
+			e.processVoteMsg(msgVote)
+			return
+		}
 		e.VoteMessageCh <- msgVote
+	case MSG_TIMEOUT:
+		var msgTimeout BFTTimeout
+		err := json.Unmarshal(msg.Content, &msgTimeout)
+		if err != nil {
+			e.logger.Error(err)
+			return
+		}
+		if e.isManual {
+			e.processTimeoutMsg(msgTimeout)
+			return
+		}
+		e.TimeoutMessageCh <- msgTimeout
+	case MSG_EVIDENCE:
+		var evidence blockchain.DoubleSignEvidence
+		err := json.Unmarshal(msg.Content, &evidence)
+		if err != nil {
+			e.logger.Error(err)
+			return
+		}
+		if err := e.Chain.AddDoubleSignEvidence(&evidence); err != nil {
+			e.logger.Error(err)
+		}
 	default:
 		e.logger.Critical("???")
 		return
@@ -128,13 +190,18 @@ func (e *BLSBFT) sendVote() error {
 	}
 	key := e.UserKeySet.GetPublicKey()
 
-	msg, err := MakeBFTVoteMsg(key.GetMiningKeyBase58(consensusName), e.ChainKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round), Vote)
+	if err := e.journal.recordSign(journalVote, e.RoundData.NextHeight, e.RoundData.Round, e.RoundData.BlockHash); err != nil {
+		// asking again gets the same answer, the round has to time out
+		e.RoundData.NotYetSendVote = false
+		return err
+	}
+	msg, err := MakeBFTVoteMsg(key.GetMiningKeyBase58(consensusName), e.ChainKey, getRoundKey(e.RoundData.NextHeight, e.RoundData.Round), e.RoundData.BlockHash, Vote)
 	if err != nil {
 		return consensus.NewConsensusError(consensus.UnExpectedError, err)
 	}
 	e.RoundData.Votes[pubKey.GetMiningKeyBase58(consensusName)] = Vote
 	e.logger.Info("sending vote...", getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
-	go e.Node.PushMessageToChain(msg, e.Chain)
+	e.pushMessageToChain(msg)
 	e.RoundData.NotYetSendVote = false
 	return nil
 }
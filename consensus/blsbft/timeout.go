@@ -0,0 +1,203 @@
+package blsbft
+
+import (
+	"sort"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/metrics"
+	"github.com/pkg/errors"
+)
+
+// BFTTimeout - a validator gives up a round, timeouts of more than 2/3 of the committee for a round
+// make its timeout certificate which moves the committee to the next round
+type BFTTimeout struct {
+	RoundKey  string
+	Validator string
+	Sig       []byte // bridge signature of the chain key and round key
+}
+
+// TimeoutCert - timeouts of more than 2/3 of the committee for a round
+type TimeoutCert struct {
+	RoundKey string
+	Timeouts []BFTTimeout
+}
+
+// getRoundTimeout - time a round lasts before this node sends its timeout, it doubles at each round of the height
+func (e *BLSBFT) getRoundTimeout() time.Duration {
+	result := roundTimeout
+	for i := 1; i < e.RoundData.Round && result < maxRoundTimeout; i++ {
+		result *= 2
+	}
+	if result > maxRoundTimeout {
+		return maxRoundTimeout
+	}
+	return result
+}
+
+func (e *BLSBFT) timeoutSignData(roundKey string) common.Hash {
+	return common.HashH([]byte(e.ChainKey + "_" + roundKey))
+}
+
+// checkRoundTimeout - send the timeout of the current round once it lasted longer than its timeout
+func (e *BLSBFT) checkRoundTimeout() {
+	if e.now().Before(e.RoundData.TimeStart.Add(e.getRoundTimeout())) {
+		return
+	}
+	e.sendTimeout(getRoundKey(e.RoundData.NextHeight, e.RoundData.Round))
+}
+
+// sendTimeout - sign and send the timeout of this node for the round of roundKey, once per round
+func (e *BLSBFT) sendTimeout(roundKey string) {
+	pubKey := e.UserKeySet.GetPublicKey()
+	validator := pubKey.GetMiningKeyBase58(consensusName)
+	if common.IndexOfStr(validator, e.RoundData.CommitteeBLS.StringList) == -1 {
+		return
+	}
+	if _, ok := e.Timeouts[roundKey][validator]; ok {
+		return
+	}
+	dataHash := e.timeoutSignData(roundKey)
+	sig, err := e.UserKeySet.BriSignData(dataHash.GetBytes())
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	timeout := BFTTimeout{
+		RoundKey:  roundKey,
+		Validator: validator,
+		Sig:       sig,
+	}
+	msg, err := MakeBFTTimeoutMsg(e.ChainKey, timeout)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.logger.Info("BFT: round timed out, sending timeout", roundKey)
+	e.addTimeout(timeout)
+	e.pushMessageToChain(msg)
+	e.enterCertifiedRound()
+}
+
+func (e *BLSBFT) processTimeoutMsg(msg BFTTimeout) {
+	height, round := parseRoundKey(msg.RoundKey)
+	if height != e.RoundData.NextHeight || round < e.RoundData.Round || round > e.RoundData.Round+timeoutRoundWindow {
+		return
+	}
+	if _, ok := e.Timeouts[msg.RoundKey][msg.Validator]; ok {
+		return
+	}
+	if err := e.validateTimeout(msg); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.addTimeout(msg)
+	// more than 1/3 of the committee gave up the round, at least one of them is honest
+	if len(e.Timeouts[msg.RoundKey])*3 > len(e.RoundData.Committee) {
+		e.sendTimeout(msg.RoundKey)
+	}
+	e.enterCertifiedRound()
+}
+
+func (e *BLSBFT) validateTimeout(msg BFTTimeout) error {
+	validatorIdx := common.IndexOfStr(msg.Validator, e.RoundData.CommitteeBLS.StringList)
+	if validatorIdx == -1 {
+		return errors.Errorf("timeout of %+v which is not in committee", msg.Validator)
+	}
+	dataHash := e.timeoutSignData(msg.RoundKey)
+	return validateSingleBriSig(&dataHash, msg.Sig, e.RoundData.Committee[validatorIdx].MiningPubKey[common.BridgeConsensus])
+}
+
+func (e *BLSBFT) addTimeout(msg BFTTimeout) {
+	if _, ok := e.Timeouts[msg.RoundKey]; !ok {
+		e.Timeouts[msg.RoundKey] = make(map[string]BFTTimeout)
+	}
+	e.Timeouts[msg.RoundKey][msg.Validator] = msg
+}
+
+// processTimeoutCert - take the timeouts of a certificate received with a proposal, they move this node to the round of the proposal.
+// The round of a certificate is not bounded by timeoutRoundWindow since more than 2/3 of the committee signed it
+func (e *BLSBFT) processTimeoutCert(cert *TimeoutCert) {
+	height, round := parseRoundKey(cert.RoundKey)
+	if height != e.RoundData.NextHeight || round < e.RoundData.Round {
+		return
+	}
+	if err := e.validateTimeoutCert(cert); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	for _, timeout := range cert.Timeouts {
+		e.addTimeout(timeout)
+	}
+	e.enterCertifiedRound()
+}
+
+func (e *BLSBFT) validateTimeoutCert(cert *TimeoutCert) error {
+	validators := make(map[string]bool)
+	for _, timeout := range cert.Timeouts {
+		if timeout.RoundKey != cert.RoundKey {
+			return errors.Errorf("timeout of round %+v in certificate of round %+v", timeout.RoundKey, cert.RoundKey)
+		}
+		if validators[timeout.Validator] {
+			return errors.Errorf("timeout of %+v twice in certificate of round %+v", timeout.Validator, cert.RoundKey)
+		}
+		if err := e.validateTimeout(timeout); err != nil {
+			return err
+		}
+		validators[timeout.Validator] = true
+	}
+	if len(validators) <= 2*len(e.RoundData.Committee)/3 {
+		return errors.Errorf("certificate of round %+v has %+v timeouts", cert.RoundKey, len(validators))
+	}
+	return nil
+}
+
+// getTimeoutCert - certificate of the round of roundKey, nil when less than 2/3 of the committee gave it up
+func (e *BLSBFT) getTimeoutCert(roundKey string) *TimeoutCert {
+	if len(e.Timeouts[roundKey]) <= 2*len(e.RoundData.Committee)/3 {
+		return nil
+	}
+	cert := &TimeoutCert{RoundKey: roundKey}
+	for _, timeout := range e.Timeouts[roundKey] {
+		cert.Timeouts = append(cert.Timeouts, timeout)
+	}
+	sort.Slice(cert.Timeouts, func(i, j int) bool {
+		return cert.Timeouts[i].Validator < cert.Timeouts[j].Validator
+	})
+	return cert
+}
+
+// enterCertifiedRound - enter the round following the highest round of the height with a timeout certificate
+func (e *BLSBFT) enterCertifiedRound() {
+	round := 0
+	for roundKey := range e.Timeouts {
+		height, r := parseRoundKey(roundKey)
+		if height == e.RoundData.NextHeight && r >= e.RoundData.Round && r > round && e.getTimeoutCert(roundKey) != nil {
+			round = r
+		}
+	}
+	if round == 0 {
+		return
+	}
+	e.enterRound(round + 1)
+}
+
+// enterRound - give up the current round of the height for a later one, without waiting for the clock
+func (e *BLSBFT) enterRound(round int) {
+	e.logger.Infof("BFT: height %+v enters round %+v from round %+v", e.RoundData.NextHeight, round, e.RoundData.Round)
+	e.isOngoing = false
+	e.initRound(round)
+	e.setState(newround)
+	metrics.SetGlobalParam("RoundChanges", round-1)
+	e.startRound()
+}
+
+// pruneTimeouts - forget timeouts of heights already decided
+func (e *BLSBFT) pruneTimeouts() {
+	for roundKey := range e.Timeouts {
+		height, _ := parseRoundKey(roundKey)
+		if height < e.RoundData.NextHeight {
+			delete(e.Timeouts, roundKey)
+		}
+	}
+}
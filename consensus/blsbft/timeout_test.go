@@ -0,0 +1,142 @@
+package blsbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBLSBFT_getRoundTimeout(t *testing.T) {
+	tests := []struct {
+		round int
+		want  time.Duration
+	}{
+		{round: 1, want: roundTimeout},
+		{round: 2, want: 2 * roundTimeout},
+		{round: 4, want: 8 * roundTimeout},
+		{round: 100, want: maxRoundTimeout},
+	}
+	for _, tt := range tests {
+		e := &BLSBFT{}
+		e.RoundData.Round = tt.round
+		assert.Equal(t, tt.want, e.getRoundTimeout(), "round %v", tt.round)
+	}
+}
+
+// newTestTimeoutBFT - consensus of the first of size validators at round 1 of height 10, with the keys of every validator
+func newTestTimeoutBFT(t *testing.T, size int) (*BLSBFT, []*MiningKey) {
+	e := &BLSBFT{
+		ChainKey: common.BeaconChainKey,
+		Timeouts: make(map[string]map[string]BFTTimeout),
+		logger:   common.NewBackend(nil).Logger("test", true),
+	}
+	e.RoundData.NextHeight = 10
+	e.RoundData.Round = 1
+	keys := []*MiningKey{}
+	for i := 0; i < size; i++ {
+		seed := common.HashB([]byte{byte(i)})
+		committeeKey, err := incognitokey.NewCommitteeKeyFromSeed(seed, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.RoundData.Committee = append(e.RoundData.Committee, committeeKey)
+		e.RoundData.CommitteeBLS.StringList = append(e.RoundData.CommitteeBLS.StringList, committeeKey.GetMiningKeyBase58(consensusName))
+		if err := e.LoadUserKey(base58.Base58Check{}.Encode(seed, common.Base58Version)); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, e.UserKeySet)
+	}
+	e.UserKeySet = keys[0]
+	return e, keys
+}
+
+func newTestTimeout(t *testing.T, e *BLSBFT, key *MiningKey, roundKey string) BFTTimeout {
+	dataHash := e.timeoutSignData(roundKey)
+	sig, err := key.BriSignData(dataHash.GetBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := key.GetPublicKey()
+	return BFTTimeout{RoundKey: roundKey, Validator: pubKey.GetMiningKeyBase58(consensusName), Sig: sig}
+}
+
+func TestBLSBFT_validateTimeout(t *testing.T) {
+	e, keys := newTestTimeoutBFT(t, 4)
+	roundKey := getRoundKey(10, 1)
+
+	assert.Nil(t, e.validateTimeout(newTestTimeout(t, e, keys[1], roundKey)))
+
+	forged := newTestTimeout(t, e, keys[1], roundKey)
+	forged.Validator = e.RoundData.CommitteeBLS.StringList[2]
+	assert.NotNil(t, e.validateTimeout(forged))
+
+	otherRound := newTestTimeout(t, e, keys[1], getRoundKey(10, 2))
+	otherRound.RoundKey = roundKey
+	assert.NotNil(t, e.validateTimeout(otherRound))
+
+	outsider, outsiderKeys := newTestTimeoutBFT(t, 5)
+	assert.NotNil(t, e.validateTimeout(newTestTimeout(t, outsider, outsiderKeys[4], roundKey)))
+}
+
+func TestBLSBFT_getTimeoutCert(t *testing.T) {
+	e, keys := newTestTimeoutBFT(t, 4)
+	roundKey := getRoundKey(10, 1)
+	for i := 1; i <= 2; i++ {
+		e.addTimeout(newTestTimeout(t, e, keys[i], roundKey))
+	}
+	// 2 of 4 is not more than 2/3
+	assert.Nil(t, e.getTimeoutCert(roundKey))
+
+	e.addTimeout(newTestTimeout(t, e, keys[3], roundKey))
+	cert := e.getTimeoutCert(roundKey)
+	if assert.NotNil(t, cert) {
+		assert.Equal(t, roundKey, cert.RoundKey)
+		assert.Equal(t, 3, len(cert.Timeouts))
+		assert.Nil(t, e.validateTimeoutCert(cert))
+	}
+}
+
+func TestBLSBFT_processTimeoutMsg(t *testing.T) {
+	e, keys := newTestTimeoutBFT(t, 4)
+
+	// a single timeout of a round beyond the window is dropped
+	farRoundKey := getRoundKey(10, 1+timeoutRoundWindow+1)
+	e.processTimeoutMsg(newTestTimeout(t, e, keys[1], farRoundKey))
+	assert.Empty(t, e.Timeouts[farRoundKey])
+
+	nearRoundKey := getRoundKey(10, 1+timeoutRoundWindow)
+	e.processTimeoutMsg(newTestTimeout(t, e, keys[1], nearRoundKey))
+	assert.Equal(t, 1, len(e.Timeouts[nearRoundKey]))
+
+	oldHeightKey := getRoundKey(9, 1)
+	e.processTimeoutMsg(newTestTimeout(t, e, keys[1], oldHeightKey))
+	assert.Empty(t, e.Timeouts[oldHeightKey])
+}
+
+func TestBLSBFT_processTimeoutCert(t *testing.T) {
+	e, keys := newTestTimeoutBFT(t, 4)
+	roundKey := getRoundKey(10, 1)
+
+	cert := &TimeoutCert{RoundKey: roundKey}
+	for i := 1; i < 4; i++ {
+		cert.Timeouts = append(cert.Timeouts, newTestTimeout(t, e, keys[i], roundKey))
+	}
+	// a timeout of another round makes the whole certificate invalid
+	cert.Timeouts[2] = newTestTimeout(t, e, keys[3], getRoundKey(10, 2))
+	assert.NotNil(t, e.validateTimeoutCert(cert))
+	e.processTimeoutCert(cert)
+	assert.Empty(t, e.Timeouts)
+	assert.Equal(t, 1, e.RoundData.Round)
+
+	duplicated := &TimeoutCert{RoundKey: roundKey}
+	for i := 1; i < 4; i++ {
+		duplicated.Timeouts = append(duplicated.Timeouts, newTestTimeout(t, e, keys[1], roundKey))
+	}
+	assert.NotNil(t, e.validateTimeoutCert(duplicated))
+	e.processTimeoutCert(duplicated)
+	assert.Empty(t, e.Timeouts)
+}
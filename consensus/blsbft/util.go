@@ -12,12 +12,29 @@ import (
 	"github.com/incognitochain/incognito-chain/consensus"
 	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/blsmultisig"
 	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/wire"
 
 	"github.com/incognitochain/incognito-chain/common"
 )
 
+func (e *BLSBFT) now() time.Time {
+	if e.Clock == nil {
+		return time.Now()
+	}
+	return e.Clock.Now()
+}
+
 func (e *BLSBFT) getTimeSinceLastBlock() time.Duration {
-	return time.Since(time.Unix(int64(e.Chain.GetLastBlockTimeStamp()), 0))
+	return e.now().Sub(time.Unix(int64(e.Chain.GetLastBlockTimeStamp()), 0))
+}
+
+// pushMessageToChain sends msg to the other nodes of the chain, in the background unless the consensus is driven manually
+func (e *BLSBFT) pushMessageToChain(msg wire.Message) {
+	if e.isManual {
+		e.Node.PushMessageToChain(msg, e.Chain)
+		return
+	}
+	go e.Node.PushMessageToChain(msg, e.Chain)
 }
 
 func (e *BLSBFT) waitForNextRound() bool {
@@ -34,24 +51,12 @@ func (e *BLSBFT) setState(state string) {
 	e.RoundData.State = state
 }
 
-func (e *BLSBFT) getCurrentRound() int {
-	round := int((e.getTimeSinceLastBlock().Seconds() - float64(e.Chain.GetMinBlkInterval().Seconds())) / timeout.Seconds())
-	if round < 0 {
-		return 1
-	}
-
-	return round + 1
-}
-
+// isInTimeFrame - the round data is still about the next height of chain, rounds only change with timeout certificates
 func (e *BLSBFT) isInTimeFrame() bool {
 	if e.Chain.CurrentHeight()+1 != e.RoundData.NextHeight {
 		return false
 	}
 
-	if e.getCurrentRound() != e.RoundData.Round {
-		return false
-	}
-
 	return true
 }
 
@@ -148,12 +153,22 @@ func (e *BLSBFT) InitRoundData() {
 		delete(e.Blocks, roundKey)
 	}
 	e.RoundData.NextHeight = e.Chain.CurrentHeight() + 1
-	e.RoundData.Round = e.getCurrentRound()
+	e.initRound(1)
+	e.RoundData.LastProposerIndex = e.Chain.GetLastProposerIndex()
+	e.UpdateCommitteeBLSList()
+	e.pruneSignedMessages()
+	e.pruneTimeouts()
+	if err := e.journal.prune(e.RoundData.NextHeight); err != nil {
+		e.logger.Error(err)
+	}
+}
+
+// initRound - reset the data of the current round to start round of the same height
+func (e *BLSBFT) initRound(round int) {
+	e.RoundData.Round = round
 	e.RoundData.Votes = make(map[string]vote)
 	e.RoundData.Block = nil
 	e.RoundData.BlockHash = common.Hash{}
 	e.RoundData.NotYetSendVote = true
-	e.RoundData.LastProposerIndex = e.Chain.GetLastProposerIndex()
-	e.RoundData.TimeStart = time.Now()
-	e.UpdateCommitteeBLSList()
+	e.RoundData.TimeStart = e.now()
 }
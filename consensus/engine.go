@@ -18,6 +18,10 @@ import (
 
 var AvailableConsensus map[string]ConsensusInterface
 
+// AvailableImplementations - consensus implementations a chain can be switched to with EngineConfig.ChainConsensus,
+// they sign blocks like the consensus named by their GetConsensusName so other nodes validate their blocks as usual
+var AvailableImplementations map[string]ConsensusInterface
+
 type Engine struct {
 	sync.Mutex
 	cQuit                chan struct{}
@@ -41,6 +45,8 @@ type EngineConfig struct {
 	Blockchain    *blockchain.BlockChain
 	BlockGen      *blockchain.BlockGenerator
 	PubSubManager *pubsub.PubSubManager
+	// ChainConsensus - chain key -> name of the implementation running the chain, the consensus type of the chain by default
+	ChainConsensus map[string]string
 }
 
 func New() *Engine {
@@ -136,6 +142,10 @@ func (engine *Engine) watchConsensusCommittee() {
 	}
 
 	for chainName, chain := range engine.config.Blockchain.Chains {
+		if implementation, ok := engine.config.ChainConsensus[chainName]; ok {
+			engine.ChainConsensusList[chainName] = AvailableImplementations[implementation].NewInstance(chain, chainName, engine.config.Node, Logger.log)
+			continue
+		}
 		if _, ok := AvailableConsensus[chain.GetConsensusType()]; ok {
 			engine.ChainConsensusList[chainName] = AvailableConsensus[chain.GetConsensusType()].NewInstance(chain, chainName, engine.config.Node, Logger.log)
 		}
@@ -351,6 +361,17 @@ func RegisterConsensus(name string, consensus ConsensusInterface) error {
 	return nil
 }
 
+func RegisterImplementation(name string, consensus ConsensusInterface) error {
+	if len(AvailableImplementations) == 0 {
+		AvailableImplementations = make(map[string]ConsensusInterface)
+	}
+	if consensus == nil {
+		return NewConsensusError(UnExpectedError, errors.New("consensus can't be nil"))
+	}
+	AvailableImplementations[name] = consensus
+	return nil
+}
+
 func (engine *Engine) IsOngoing(chainName string) bool {
 	consensusModule, ok := engine.ChainConsensusList[chainName]
 	if ok {
@@ -417,6 +438,11 @@ func (engine *Engine) Init(config *EngineConfig) error {
 	if config.PubSubManager == nil {
 		return NewConsensusError(UnExpectedError, errors.New("PubSubManager can't be nil"))
 	}
+	for chainName, implementation := range config.ChainConsensus {
+		if _, ok := AvailableImplementations[implementation]; !ok {
+			return NewConsensusError(ConsensusTypeNotExistError, fmt.Errorf("%+v of chain %+v", implementation, chainName))
+		}
+	}
 	engine.config = config
 	engine.cQuit = make(chan struct{})
 	engine.chainCommitteeChange = make(chan string)
@@ -19,6 +19,8 @@ const (
 	DecodeValidationDataError
 	EncodeValidationDataError
 	BlockCreationError
+	VoteJournalError
+	ConflictingSignError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -37,6 +39,8 @@ var ErrCodeMessage = map[int]struct {
 	DecodeValidationDataError:    {-1009, "Decode Validation Data error"},
 	EncodeValidationDataError:    {-1010, "Encode Validation Data Error"},
 	BlockCreationError:           {-1011, "Block Creation Error"},
+	VoteJournalError:             {-1012, "Vote journal error"},
+	ConflictingSignError:         {-1013, "Conflicting with data signed in the same round"},
 }
 
 type ConsensusError struct {
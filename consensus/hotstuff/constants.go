@@ -0,0 +1,24 @@
+package hotstuff
+
+import (
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+const (
+	// ConsensusName - name the implementation is registered with, it signs blocks like BLSBFT with the bls keys
+	ConsensusName = "hotstuff"
+	keyType       = common.BlsConsensus
+)
+
+const (
+	MSG_PROPOSE = "hs_propose"
+	MSG_VOTE    = "hs_vote"
+	MSG_NEWVIEW = "hs_newview"
+)
+
+const (
+	viewTimeout  = 20 * time.Second // time a leader has to propose and the next leader to collect votes
+	tickInterval = 200 * time.Millisecond
+)
@@ -0,0 +1,354 @@
+/*
+Package hotstuff is a pipelined BFT consensus in the style of HotStuff. It runs a chain instead of BLSBFT when the
+chain is switched to it (see consensus.EngineConfig.ChainConsensus), blocks are signed like BLSBFT blocks so nodes
+running either implementation validate each other's blocks.
+
+- A height is decided in views, the leader of view v is the committee member at (last proposer index + v) and
+the block it creates has round v, so the producer position rule of the chain holds
+- A block is voted for in two phases. Validators broadcast a prepare vote, a bls signature of the block hash with its
+height and view. More than 2/3 prepare votes of a view make a quorum certificate (QC) which is the lock of the view:
+every node collects them, locks on the block and sends its commit vote, signed like a BLSBFT vote, to the leader of
+the next view. More than 2/3 commit votes make the QC which is the committee signature of the block validation data.
+A commit vote is only sent by a node holding the lock of its view, so a committed block is locked by more than 2/3
+of the committee
+- The leader of view 2 is the leader of the next height: it commits the block of view 1 with the QC of the commit
+votes and proposes the next block right away. The proposal and the votes for it carry the QC of the parent, so
+validators commit the parent when they receive them
+- When a view times out, validators send a new view message with their lock to the next leader, which waits for
+more than 2/3 of them and proposes again the block locked in the highest view, or a new block when none is locked.
+The new view messages are the certificate of the proposal: a locked validator only votes for another block when the
+certificate holds a lock of a higher view. Locks are QCs verified against the bls keys of the committee and compared
+by their view, a node also takes the lock of a higher view it receives in a new view message
+- A validator joins a higher view when more than 1/3 of the committee have sent new view messages for it, or when it
+receives a lock of the view
+
+A lock can not be claimed without the prepare votes of more than 2/3 of the committee, so the engine stays safe with
+less than 1/3 of byzantine validators. Leaders do not wait for the new view messages of all validators, a validator
+locked in a view higher than every lock of the certificate does not vote until a later view, so it is meant for
+networks of known validators, e.g. to measure its throughput against BLSBFT on a private network.
+*/
+package hotstuff
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/consensus/blsbft"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/blsmultisig"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/wire"
+)
+
+type blockValidation interface {
+	common.BlockInterface
+	AddValidationField(validationData string) error
+}
+
+// voteKey - votes of a block, the view is only set for prepare votes since commit votes of any view sign the same data
+type voteKey struct {
+	View      int
+	BlockHash common.Hash
+}
+
+type HotStuff struct {
+	Chain    blockchain.ChainInterface
+	Node     consensus.NodeInterface
+	ChainKey string
+
+	UserKeySet       *blsbft.MiningKey
+	ProposeMessageCh chan ProposeMsg
+	VoteMessageCh    chan VoteMsg
+	NewViewMessageCh chan NewViewMsg
+
+	height            uint64 // height being decided, the height of chain plus one
+	view              int
+	viewStart         time.Time
+	proposed          bool        // leader has proposed in the current view
+	votedView         int         // last view of a prepare vote at the height
+	commitVotedView   int         // last view of a commit vote at the height
+	locked            *QuorumCert // lock of the highest view at the height
+	lastProposerIndex int
+	committee         struct {
+		Keys       []incognitokey.CommitteePublicKey
+		StringList []string
+		ByteList   []blsmultisig.PublicKey
+	}
+	blocks       map[common.Hash]common.BlockInterface // blocks proposed at the height, and at its parent until it is committed
+	validated    map[common.Hash]bool
+	prepareVotes map[voteKey]map[string]VoteMsg // prepare votes of the height, by view and block
+	votes        map[voteKey]map[string]VoteMsg // commit votes collected as leader of the next view, by block
+	newViews     map[int]map[string]NewViewMsg  // new view messages of the height, by view and validator
+	lastQC       *QuorumCert                    // QC of the last block committed by this node
+
+	isOngoing bool
+	isStarted bool
+	isManual  bool
+	StopCh    chan struct{}
+	logger    common.Logger
+	// Clock tells the time views are measured with, the system clock when nil
+	Clock common.Clock
+}
+
+func (e *HotStuff) IsOngoing() bool {
+	return e.isOngoing
+}
+
+// GetConsensusName - the key type of the consensus, HotStuff signs with the keys of BLSBFT
+func (e *HotStuff) GetConsensusName() string {
+	return keyType
+}
+
+func (e *HotStuff) Start() error {
+	if err := e.initActor(); err != nil {
+		return err
+	}
+	ticker := time.Tick(tickInterval)
+	e.logger.Info("start hotstuff consensus for chain", e.ChainKey)
+	go func() {
+		for { //actor loop
+			select {
+			case <-e.StopCh:
+				return
+			case msg := <-e.ProposeMessageCh:
+				e.processProposeMsg(msg)
+			case msg := <-e.VoteMessageCh:
+				e.processVoteMsg(msg)
+			case msg := <-e.NewViewMessageCh:
+				e.processNewViewMsg(msg)
+			case <-ticker:
+				e.Tick()
+			}
+		}
+	}()
+	return nil
+}
+
+// StartManual starts the consensus without its actor loop, like BLSBFT.StartManual:
+// messages are processed by ProcessBFTMsg before it returns and views only change when Tick is called
+func (e *HotStuff) StartManual() error {
+	if err := e.initActor(); err != nil {
+		return err
+	}
+	e.isManual = true
+	e.logger.Info("start manual hotstuff consensus for chain", e.ChainKey)
+	return nil
+}
+
+func (e *HotStuff) Stop() error {
+	if e.isStarted {
+		select {
+		case <-e.StopCh:
+			return nil
+		default:
+			close(e.StopCh)
+		}
+		e.isStarted = false
+		e.isOngoing = false
+	}
+	return consensus.NewConsensusError(consensus.ConsensusAlreadyStoppedError, errors.New(e.ChainKey))
+}
+
+func (e *HotStuff) initActor() error {
+	if e.isStarted {
+		return consensus.NewConsensusError(consensus.ConsensusAlreadyStartedError, errors.New(e.ChainKey))
+	}
+	e.isStarted = true
+	e.isOngoing = false
+	e.isManual = false
+	e.StopCh = make(chan struct{})
+	e.ProposeMessageCh = make(chan ProposeMsg)
+	e.VoteMessageCh = make(chan VoteMsg)
+	e.NewViewMessageCh = make(chan NewViewMsg)
+	e.blocks = make(map[common.Hash]common.BlockInterface)
+	e.validated = make(map[common.Hash]bool)
+	e.prepareVotes = make(map[voteKey]map[string]VoteMsg)
+	e.votes = make(map[voteKey]map[string]VoteMsg)
+	e.newViews = make(map[int]map[string]NewViewMsg)
+	e.height = 0
+	e.syncHeight()
+	return nil
+}
+
+// Tick changes the view when it timed out and proposes when the node leads the current view.
+// The actor loop calls it every tickInterval, a consensus started with StartManual only when its caller does.
+func (e *HotStuff) Tick() {
+	if !e.Chain.IsReady() {
+		e.isOngoing = false
+		return
+	}
+	e.syncHeight()
+	if e.selfIndex() == -1 {
+		return
+	}
+	if !e.now().Before(e.viewDeadline()) {
+		e.logger.Infof("HotStuff: view %+v of height %+v timed out", e.view, e.height)
+		e.enterView(e.view + 1)
+		e.sendNewView()
+	}
+	if !e.proposed && e.isLeader(e.view) {
+		e.propose()
+	}
+}
+
+func (e *HotStuff) now() time.Time {
+	if e.Clock == nil {
+		return time.Now()
+	}
+	return e.Clock.Now()
+}
+
+// syncHeight - start deciding the next height once chain has a new block, committed by this node or synced
+func (e *HotStuff) syncHeight() {
+	height := e.Chain.CurrentHeight() + 1
+	if height == e.height {
+		return
+	}
+	e.height = height
+	e.lastProposerIndex = e.Chain.GetLastProposerIndex()
+	e.updateCommittee()
+	e.view = 1
+	e.viewStart = e.now()
+	e.proposed = false
+	e.votedView = 0
+	e.commitVotedView = 0
+	e.locked = nil
+	e.isOngoing = false
+	for hash, block := range e.blocks {
+		if block.GetHeight() < height {
+			delete(e.blocks, hash)
+			delete(e.validated, hash)
+		}
+	}
+	e.prepareVotes = make(map[voteKey]map[string]VoteMsg)
+	e.votes = make(map[voteKey]map[string]VoteMsg)
+	e.newViews = make(map[int]map[string]NewViewMsg)
+}
+
+func (e *HotStuff) updateCommittee() {
+	committee := e.Chain.GetCommittee()
+	if reflect.DeepEqual(e.committee.Keys, committee) {
+		return
+	}
+	e.committee.Keys = committee
+	e.committee.ByteList = []blsmultisig.PublicKey{}
+	for _, member := range committee {
+		e.committee.ByteList = append(e.committee.ByteList, member.MiningPubKey[keyType])
+	}
+	committeeBLSString, err := incognitokey.ExtractPublickeysFromCommitteeKeyList(committee, keyType)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.committee.StringList = committeeBLSString
+}
+
+func (e *HotStuff) enterView(view int) {
+	e.view = view
+	e.viewStart = e.now()
+	e.proposed = false
+}
+
+// viewDeadline - view 1 starts when the minimum block interval has passed since the last block
+func (e *HotStuff) viewDeadline() time.Time {
+	start := e.viewStart
+	if e.view == 1 {
+		blockTime := time.Unix(e.Chain.GetLastBlockTimeStamp(), 0).Add(e.Chain.GetMinBlkInterval())
+		if blockTime.After(start) {
+			start = blockTime
+		}
+	}
+	return start.Add(viewTimeout)
+}
+
+func (e *HotStuff) selfIndex() int {
+	if e.UserKeySet == nil {
+		return -1
+	}
+	pubKey := e.UserKeySet.GetPublicKey()
+	return common.IndexOfStr(pubKey.GetMiningKeyBase58(keyType), e.committee.StringList)
+}
+
+func (e *HotStuff) leaderIndex(view int) int {
+	if len(e.committee.Keys) == 0 {
+		return -1
+	}
+	return (e.lastProposerIndex + view) % len(e.committee.Keys)
+}
+
+func (e *HotStuff) isLeader(view int) bool {
+	selfIdx := e.selfIndex()
+	return selfIdx != -1 && selfIdx == e.leaderIndex(view)
+}
+
+func (e *HotStuff) hasQuorum(count int) bool {
+	return count > 2*len(e.committee.Keys)/3
+}
+
+// pushMessageToChain sends msg to the other nodes of the chain, in the background unless the consensus is driven manually
+func (e *HotStuff) pushMessageToChain(msg wire.Message) {
+	if e.isManual {
+		e.Node.PushMessageToChain(msg, e.Chain)
+		return
+	}
+	go e.Node.PushMessageToChain(msg, e.Chain)
+}
+
+// scheme - BLSBFT with the key of this node, HotStuff blocks are signed and validated like BLSBFT blocks
+func (e *HotStuff) scheme() *blsbft.BLSBFT {
+	return &blsbft.BLSBFT{UserKeySet: e.UserKeySet}
+}
+
+func (e *HotStuff) ValidateProducerSig(block common.BlockInterface) error {
+	return e.scheme().ValidateProducerSig(block)
+}
+
+func (e *HotStuff) ValidateCommitteeSig(block common.BlockInterface, committee []incognitokey.CommitteePublicKey) error {
+	return e.scheme().ValidateCommitteeSig(block, committee)
+}
+
+func (e *HotStuff) LoadUserKey(miningKey string) error {
+	scheme := e.scheme()
+	if err := scheme.LoadUserKey(miningKey); err != nil {
+		return err
+	}
+	e.UserKeySet = scheme.UserKeySet
+	return nil
+}
+
+func (e *HotStuff) LoadUserKeyFromIncPrivateKey(privateKey string) (string, error) {
+	return e.scheme().LoadUserKeyFromIncPrivateKey(privateKey)
+}
+
+func (e *HotStuff) GetUserPublicKey() *incognitokey.CommitteePublicKey {
+	return e.scheme().GetUserPublicKey()
+}
+
+func (e *HotStuff) ValidateData(data []byte, sig string, publicKey string) error {
+	return e.scheme().ValidateData(data, sig, publicKey)
+}
+
+func (e *HotStuff) SignData(data []byte) (string, error) {
+	return e.scheme().SignData(data)
+}
+
+func (e *HotStuff) ExtractBridgeValidationData(block common.BlockInterface) ([][]byte, []int, error) {
+	return e.scheme().ExtractBridgeValidationData(block)
+}
+
+func (e HotStuff) NewInstance(chain blockchain.ChainInterface, chainKey string, node consensus.NodeInterface, logger common.Logger) consensus.ConsensusInterface {
+	var newInstance HotStuff
+	newInstance.Chain = chain
+	newInstance.ChainKey = chainKey
+	newInstance.Node = node
+	newInstance.UserKeySet = e.UserKeySet
+	newInstance.logger = logger
+	return &newInstance
+}
+
+func init() {
+	consensus.RegisterImplementation(ConsensusName, &HotStuff{})
+}
@@ -0,0 +1,462 @@
+package hotstuff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/blsmultisig"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/bridgesig"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/wire"
+	"github.com/pkg/errors"
+)
+
+type vote struct {
+	BLS          []byte
+	BRI          []byte
+	Confirmation []byte // bridge signature of the vote with its height and view
+}
+
+// vote phases, see the package doc
+const (
+	phasePrepare = byte(1)
+	phaseCommit  = byte(2)
+)
+
+// QuorumCert - votes of more than 2/3 of the committee for a block in a phase. The QC of prepare votes is a lock,
+// the QC of commit votes is the committee signature of the block
+type QuorumCert struct {
+	Height         uint64
+	View           int
+	BlockHash      common.Hash
+	ValidatiorsIdx []int
+	AggSig         []byte
+	BridgeSig      [][]byte
+}
+
+type ProposeMsg struct {
+	Block    json.RawMessage
+	View     int
+	Justify  *QuorumCert  `json:",omitempty"` // QC of the parent block
+	NewViews []NewViewMsg `json:",omitempty"` // certificate of a proposal after view 1
+}
+
+type VoteMsg struct {
+	Height    uint64
+	View      int
+	Phase     byte
+	BlockHash common.Hash
+	Validator string // bls public key
+	Vote      vote
+	Justify   *QuorumCert `json:",omitempty"` // QC of the parent block
+}
+
+type NewViewMsg struct {
+	Height      uint64
+	View        int
+	Validator   string
+	Locked      *QuorumCert     `json:",omitempty"` // lock of validator at the height
+	LockedBlock json.RawMessage `json:",omitempty"` // block of the lock, not kept in certificates
+	Sig         []byte
+}
+
+func makeBFTMsg(chainKey string, msgType string, content interface{}) (wire.Message, error) {
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	msg, _ := wire.MakeEmptyMessage(wire.CmdBFT)
+	msg.(*wire.MessageBFT).ChainKey = chainKey
+	msg.(*wire.MessageBFT).Content = contentBytes
+	msg.(*wire.MessageBFT).Type = msgType
+	return msg, nil
+}
+
+func (e *HotStuff) ProcessBFTMsg(msg *wire.MessageBFT) {
+	switch msg.Type {
+	case MSG_PROPOSE:
+		var proposeMsg ProposeMsg
+		if err := json.Unmarshal(msg.Content, &proposeMsg); err != nil {
+			e.logger.Error(err)
+			return
+		}
+		if e.isManual {
+			e.processProposeMsg(proposeMsg)
+			return
+		}
+		e.ProposeMessageCh <- proposeMsg
+	case MSG_VOTE:
+		var voteMsg VoteMsg
+		if err := json.Unmarshal(msg.Content, &voteMsg); err != nil {
+			e.logger.Error(err)
+			return
+		}
+		if e.isManual {
+			e.processVoteMsg(voteMsg)
+			return
+		}
+		e.VoteMessageCh <- voteMsg
+	case MSG_NEWVIEW:
+		var newViewMsg NewViewMsg
+		if err := json.Unmarshal(msg.Content, &newViewMsg); err != nil {
+			e.logger.Error(err)
+			return
+		}
+		if e.isManual {
+			e.processNewViewMsg(newViewMsg)
+			return
+		}
+		e.NewViewMessageCh <- newViewMsg
+	default:
+		e.logger.Critical("???")
+		return
+	}
+}
+
+func voteConfirmData(msg *VoteMsg) []byte {
+	data := msg.BlockHash.GetBytes()
+	data = append(data, msg.Vote.BLS...)
+	data = append(data, msg.Vote.BRI...)
+	data = append(data, []byte(fmt.Sprint(msg.Height, "_", msg.View, "_", msg.Phase))...)
+	return common.HashB(data)
+}
+
+// voteSignData - data signed with bls by a vote: a commit vote signs the block hash like a BLSBFT vote,
+// a prepare vote signs the block hash with its height and view so its QC is only a lock of the view
+func voteSignData(phase byte, height uint64, view int, blockHash common.Hash) []byte {
+	if phase == phaseCommit {
+		return blockHash.GetBytes()
+	}
+	data := blockHash.GetBytes()
+	data = append(data, []byte(fmt.Sprint("prepare_", height, "_", view))...)
+	return common.HashB(data)
+}
+
+func newViewSignData(msg *NewViewMsg) []byte {
+	data := []byte(fmt.Sprint(msg.Height, "_", msg.View))
+	if msg.Locked != nil {
+		data = append(data, msg.Locked.BlockHash.GetBytes()...)
+		data = append(data, []byte(fmt.Sprint(msg.Locked.View))...)
+	}
+	return common.HashB(data)
+}
+
+// verifyVote - check the confirmation and the bls signature of a vote, an invalid bls signature would spoil its QC
+func (e *HotStuff) verifyVote(msg *VoteMsg) error {
+	if msg.Phase != phasePrepare && msg.Phase != phaseCommit {
+		return errors.Errorf("vote of %+v has unknown phase %+v", msg.Validator, msg.Phase)
+	}
+	if err := e.verifyBridgeSig(msg.Validator, voteConfirmData(msg), msg.Vote.Confirmation); err != nil {
+		return err
+	}
+	validatorIdx := common.IndexOfStr(msg.Validator, e.committee.StringList)
+	ok, err := blsmultisig.Verify(msg.Vote.BLS, voteSignData(msg.Phase, msg.Height, msg.View, msg.BlockHash), []int{validatorIdx}, e.committee.ByteList)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("invalid bls signature of %+v", msg.Validator)
+	}
+	return nil
+}
+
+func (e *HotStuff) verifyBridgeSig(validator string, data []byte, sig []byte) error {
+	validatorIdx := common.IndexOfStr(validator, e.committee.StringList)
+	if validatorIdx == -1 {
+		return errors.Errorf("%+v is not in committee", validator)
+	}
+	ok, err := bridgesig.Verify(e.committee.Keys[validatorIdx].MiningPubKey[common.BridgeConsensus], data, sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("invalid signature of %+v", validator)
+	}
+	return nil
+}
+
+func (e *HotStuff) processProposeMsg(msg ProposeMsg) {
+	block, err := e.Chain.UnmarshalBlock(msg.Block)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if msg.Justify != nil {
+		e.commit(msg.Justify)
+	}
+	e.syncHeight()
+	if block.GetHeight() != e.height {
+		return
+	}
+	blockHash := *block.Hash()
+	if _, ok := e.blocks[blockHash]; !ok {
+		e.blocks[blockHash] = block
+	}
+	e.logger.Info("HotStuff: receive block", block.GetHeight(), "view", msg.View)
+	e.onProposal(e.blocks[blockHash], msg.View, msg.NewViews)
+	// votes may have reached this node before the block
+	e.commitVote()
+	e.tryCommit(blockHash)
+}
+
+// onProposal - send the prepare vote for block proposed in view unless the node already voted in the view
+// or is locked on another block by a QC of a view at least as high as the highest lock of the certificate
+func (e *HotStuff) onProposal(block common.BlockInterface, view int, newViews []NewViewMsg) {
+	if view < e.view || view <= e.votedView || e.selfIndex() == -1 {
+		return
+	}
+	blockHash := *block.Hash()
+	if view == 1 {
+		if block.GetRound() != 1 {
+			e.logger.Errorf("HotStuff: block of view 1 has round %+v", block.GetRound())
+			return
+		}
+	} else {
+		if err := e.verifyNewViews(view, newViews); err != nil {
+			e.logger.Error(err)
+			return
+		}
+		lock := highestLock(newViews)
+		if lock != nil && lock.BlockHash != blockHash {
+			e.logger.Errorf("HotStuff: block %+v is proposed instead of locked block %+v", blockHash.String(), lock.BlockHash.String())
+			return
+		}
+		if lock == nil && block.GetRound() != view {
+			e.logger.Errorf("HotStuff: new block of view %+v has round %+v", view, block.GetRound())
+			return
+		}
+		if e.locked != nil && e.locked.BlockHash != blockHash && (lock == nil || lock.View <= e.locked.View) {
+			e.logger.Infof("HotStuff: locked on block %+v of view %+v", e.locked.BlockHash.String(), e.locked.View)
+			return
+		}
+	}
+	if view > e.view {
+		e.enterView(view)
+	}
+	if err := e.validateBlock(block); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if err := e.vote(block, view, phasePrepare); err != nil {
+		e.logger.Error(err)
+	}
+}
+
+func (e *HotStuff) validateBlock(block common.BlockInterface) error {
+	blockHash := *block.Hash()
+	if e.validated[blockHash] {
+		return nil
+	}
+	scheme := e.scheme()
+	if err := scheme.ValidateProducerPosition(block, e.lastProposerIndex, e.committee.Keys); err != nil {
+		return consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	if err := scheme.ValidateProducerSig(block); err != nil {
+		return consensus.NewConsensusError(consensus.ProducerSignatureError, err)
+	}
+	if err := e.Chain.ValidatePreSignBlock(block); err != nil {
+		return consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	e.validated[blockHash] = true
+	return nil
+}
+
+// vote - sign block in a phase of view and send the vote to the chain
+func (e *HotStuff) vote(block common.BlockInterface, view int, phase byte) error {
+	selfIdx := e.selfIndex()
+	blockHash := *block.Hash()
+	blsSig, err := e.UserKeySet.BLSSignData(voteSignData(phase, e.height, view, blockHash), selfIdx, e.committee.ByteList)
+	if err != nil {
+		return err
+	}
+	bridgeSig := []byte{}
+	if phase == phaseCommit && metadata.HasBridgeInstructions(block.GetInstructions()) {
+		bridgeSig, err = e.UserKeySet.BriSignData(blockHash.GetBytes())
+		if err != nil {
+			return err
+		}
+	}
+	voteMsg := VoteMsg{
+		Height:    e.height,
+		View:      view,
+		Phase:     phase,
+		BlockHash: blockHash,
+		Validator: e.committee.StringList[selfIdx],
+		Vote:      vote{BLS: blsSig, BRI: bridgeSig},
+		Justify:   e.parentQC(),
+	}
+	voteMsg.Vote.Confirmation, err = e.UserKeySet.BriSignData(voteConfirmData(&voteMsg))
+	if err != nil {
+		return err
+	}
+	msg, err := makeBFTMsg(e.ChainKey, MSG_VOTE, voteMsg)
+	if err != nil {
+		return err
+	}
+	if phase == phasePrepare {
+		e.votedView = view
+	} else {
+		e.commitVotedView = view
+	}
+	e.isOngoing = true
+	e.logger.Info("HotStuff: vote for block", e.height, "view", view, "phase", phase)
+	e.pushMessageToChain(msg)
+	e.processVoteMsg(voteMsg)
+	return nil
+}
+
+// parentQC - QC of the parent of the height when this node committed it
+func (e *HotStuff) parentQC() *QuorumCert {
+	if e.lastQC != nil && e.lastQC.Height+1 == e.height {
+		return e.lastQC
+	}
+	return nil
+}
+
+// processVoteMsg - every node collects prepare votes to lock, only the leader of the next view collects commit votes
+func (e *HotStuff) processVoteMsg(msg VoteMsg) {
+	if msg.Justify != nil {
+		e.commit(msg.Justify)
+	}
+	e.syncHeight()
+	if msg.Height != e.height {
+		return
+	}
+	votes := e.votes
+	key := voteKey{BlockHash: msg.BlockHash}
+	if msg.Phase == phasePrepare {
+		votes = e.prepareVotes
+		key.View = msg.View
+	} else if !e.isLeader(msg.View + 1) {
+		return
+	}
+	if _, ok := votes[key][msg.Validator]; ok {
+		return
+	}
+	if err := e.verifyVote(&msg); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if _, ok := votes[key]; !ok {
+		votes[key] = make(map[string]VoteMsg)
+	}
+	votes[key][msg.Validator] = msg
+	if msg.Phase == phasePrepare {
+		e.tryLock(key)
+		return
+	}
+	e.tryCommit(msg.BlockHash)
+}
+
+// tryLock - lock on the block of key once more than 2/3 of the committee sent their prepare vote for it in the view
+func (e *HotStuff) tryLock(key voteKey) {
+	votes := e.prepareVotes[key]
+	if !e.hasQuorum(len(votes)) {
+		return
+	}
+	if e.locked != nil && e.locked.View >= key.View {
+		return
+	}
+	qc, err := e.combineVotes(key.View, key.BlockHash, votes)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	qc.BridgeSig = nil
+	e.lock(qc)
+}
+
+// lock - keep qc as the lock of this node when it is of a higher view than the current lock, and join its view.
+// qc must have been verified
+func (e *HotStuff) lock(qc *QuorumCert) {
+	if e.locked != nil && e.locked.View >= qc.View {
+		return
+	}
+	e.locked = qc
+	e.logger.Infof("HotStuff: lock on block %+v of view %+v", qc.BlockHash.String(), qc.View)
+	// more than 2/3 of the committee voted in the view, at least one of them is honest
+	if qc.View > e.view && e.selfIndex() != -1 {
+		e.enterView(qc.View)
+	}
+	e.commitVote()
+}
+
+// commitVote - send the commit vote for the locked block when it is locked in the current view
+func (e *HotStuff) commitVote() {
+	if e.locked == nil || e.locked.View != e.view || e.commitVotedView >= e.view || e.selfIndex() == -1 {
+		return
+	}
+	block, ok := e.blocks[e.locked.BlockHash]
+	if !ok {
+		return
+	}
+	if err := e.validateBlock(block); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if err := e.vote(block, e.view, phaseCommit); err != nil {
+		e.logger.Error(err)
+	}
+}
+
+// tryCommit - commit block once this node has its block and more than 2/3 commit votes for it
+func (e *HotStuff) tryCommit(blockHash common.Hash) {
+	votes := e.votes[voteKey{BlockHash: blockHash}]
+	if !e.hasQuorum(len(votes)) {
+		return
+	}
+	if _, ok := e.blocks[blockHash]; !ok {
+		return
+	}
+	qc, err := e.combineVotes(e.view, blockHash, votes)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.commit(qc)
+}
+
+func (e *HotStuff) combineVotes(view int, blockHash common.Hash, votes map[string]VoteMsg) (*QuorumCert, error) {
+	aggSig, bridgeSigs, validatorIdx, err := combineVotes(votes, e.committee.StringList)
+	if err != nil {
+		return nil, err
+	}
+	return &QuorumCert{
+		Height:         e.height,
+		View:           view,
+		BlockHash:      blockHash,
+		ValidatiorsIdx: validatorIdx,
+		AggSig:         aggSig,
+		BridgeSig:      bridgeSigs,
+	}, nil
+}
+
+// commit - insert the block of qc into chain when it is the next block and this node has received it
+func (e *HotStuff) commit(qc *QuorumCert) {
+	e.syncHeight()
+	if qc.Height != e.height {
+		return
+	}
+	block, ok := e.blocks[qc.BlockHash]
+	if !ok {
+		return
+	}
+	if err := addQuorumCert(block, qc); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if err := e.ValidateCommitteeSig(block, e.committee.Keys); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.isOngoing = false
+	if err := e.Chain.InsertAndBroadcastBlock(block); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.lastQC = qc
+	e.logger.Infof("HotStuff: commit block %+v hash=%+v (%d votes)", qc.Height, qc.BlockHash.String(), len(qc.ValidatiorsIdx))
+	e.syncHeight()
+}
@@ -0,0 +1,284 @@
+package hotstuff
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/consensus/blsbft"
+	"github.com/incognitochain/incognito-chain/consensus/signatureschemes/blsmultisig"
+	"github.com/pkg/errors"
+)
+
+// propose - create a block in view 1, or once more than 2/3 of the committee have sent new view messages for a later
+// view, propose again the block locked in the highest view or create a new block when none is locked
+func (e *HotStuff) propose() {
+	var block common.BlockInterface
+	var newViews []NewViewMsg
+	if e.view == 1 {
+		if e.now().Before(time.Unix(e.Chain.GetLastBlockTimeStamp(), 0).Add(e.Chain.GetMinBlkInterval())) {
+			return
+		}
+	} else {
+		newViews = e.getNewViewCert(e.view)
+		if newViews == nil {
+			return
+		}
+		if lock := highestLock(newViews); lock != nil {
+			lockedBlock, ok := e.blocks[lock.BlockHash]
+			if !ok {
+				e.logger.Errorf("HotStuff: locked block %+v is not received", lock.BlockHash.String())
+				return
+			}
+			block = lockedBlock
+		}
+	}
+	e.proposed = true
+	if block == nil {
+		var err error
+		block, err = e.createNewBlock()
+		if err != nil {
+			e.logger.Error("can't create block", err)
+			return
+		}
+	}
+	blockData, err := json.Marshal(block)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	msg, err := makeBFTMsg(e.ChainKey, MSG_PROPOSE, ProposeMsg{
+		Block:    blockData,
+		View:     e.view,
+		Justify:  e.parentQC(),
+		NewViews: newViews,
+	})
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.logger.Info("HotStuff: propose block", e.height, "view", e.view)
+	e.pushMessageToChain(msg)
+	e.blocks[*block.Hash()] = block
+	e.onProposal(block, e.view, newViews)
+}
+
+func (e *HotStuff) createNewBlock() (common.BlockInterface, error) {
+	block, err := e.Chain.CreateNewBlock(e.view)
+	if err != nil {
+		return nil, consensus.NewConsensusError(consensus.BlockCreationError, err)
+	}
+	if block.GetHeight() != e.height {
+		return nil, consensus.NewConsensusError(consensus.BlockCreationError, errors.Errorf("block is created at height %+v instead of %+v", block.GetHeight(), e.height))
+	}
+	validationData := blsbft.ValidationData{}
+	validationData.ProducerBLSSig, err = e.UserKeySet.BriSignData(block.Hash().GetBytes())
+	if err != nil {
+		return nil, err
+	}
+	validationDataString, err := blsbft.EncodeValidationData(validationData)
+	if err != nil {
+		return nil, err
+	}
+	if err := block.(blockValidation).AddValidationField(validationDataString); err != nil {
+		return nil, consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	return block, nil
+}
+
+// sendNewView - send the lock of this node to the leader of the current view
+func (e *HotStuff) sendNewView() {
+	selfIdx := e.selfIndex()
+	newViewMsg := NewViewMsg{
+		Height:    e.height,
+		View:      e.view,
+		Validator: e.committee.StringList[selfIdx],
+		Locked:    e.locked,
+	}
+	if e.locked != nil {
+		if block, ok := e.blocks[e.locked.BlockHash]; ok {
+			blockData, err := json.Marshal(block)
+			if err != nil {
+				e.logger.Error(err)
+				return
+			}
+			newViewMsg.LockedBlock = blockData
+		}
+	}
+	var err error
+	newViewMsg.Sig, err = e.UserKeySet.BriSignData(newViewSignData(&newViewMsg))
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	msg, err := makeBFTMsg(e.ChainKey, MSG_NEWVIEW, newViewMsg)
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	e.pushMessageToChain(msg)
+	e.addNewView(newViewMsg)
+}
+
+func (e *HotStuff) processNewViewMsg(msg NewViewMsg) {
+	e.syncHeight()
+	if msg.Height != e.height {
+		return
+	}
+	if _, ok := e.newViews[msg.View][msg.Validator]; ok {
+		return
+	}
+	if err := e.verifyNewView(&msg); err != nil {
+		e.logger.Error(err)
+		return
+	}
+	if msg.Locked != nil && len(msg.LockedBlock) > 0 {
+		if _, ok := e.blocks[msg.Locked.BlockHash]; !ok {
+			block, err := e.Chain.UnmarshalBlock(msg.LockedBlock)
+			if err != nil {
+				e.logger.Error(err)
+			} else if *block.Hash() == msg.Locked.BlockHash && block.GetHeight() == e.height {
+				e.blocks[msg.Locked.BlockHash] = block
+			}
+		}
+	}
+	e.addNewView(msg)
+	if msg.Locked != nil {
+		e.lock(msg.Locked)
+	}
+	// more than 1/3 of the committee is in a higher view, at least one of them is honest
+	if msg.View > e.view && len(e.newViews[msg.View])*3 > len(e.committee.Keys) && e.selfIndex() != -1 {
+		e.logger.Infof("HotStuff: join view %+v of height %+v", msg.View, e.height)
+		e.enterView(msg.View)
+		e.sendNewView()
+	}
+}
+
+func (e *HotStuff) addNewView(msg NewViewMsg) {
+	if _, ok := e.newViews[msg.View]; !ok {
+		e.newViews[msg.View] = make(map[string]NewViewMsg)
+	}
+	e.newViews[msg.View][msg.Validator] = msg
+}
+
+func (e *HotStuff) verifyNewView(msg *NewViewMsg) error {
+	if err := e.verifyBridgeSig(msg.Validator, newViewSignData(msg), msg.Sig); err != nil {
+		return err
+	}
+	if msg.Locked == nil {
+		return nil
+	}
+	if msg.Locked.Height != msg.Height || msg.Locked.View >= msg.View {
+		return errors.Errorf("invalid lock of %+v at height %+v view %+v", msg.Validator, msg.Height, msg.View)
+	}
+	return e.verifyLock(msg.Locked)
+}
+
+// verifyLock - check qc holds prepare votes of more than 2/3 of the committee, its aggregated signature is checked
+// against the bls keys of the committee like the committee signature of a block
+func (e *HotStuff) verifyLock(qc *QuorumCert) error {
+	if qc.Height != e.height {
+		return errors.Errorf("lock of height %+v at height %+v", qc.Height, e.height)
+	}
+	for i, idx := range qc.ValidatiorsIdx {
+		if idx < 0 || idx >= len(e.committee.ByteList) || (i > 0 && idx <= qc.ValidatiorsIdx[i-1]) {
+			return errors.Errorf("invalid validators %+v of lock of view %+v", qc.ValidatiorsIdx, qc.View)
+		}
+	}
+	if !e.hasQuorum(len(qc.ValidatiorsIdx)) {
+		return errors.Errorf("lock of view %+v has %+v validators", qc.View, len(qc.ValidatiorsIdx))
+	}
+	ok, err := blsmultisig.Verify(qc.AggSig, voteSignData(phasePrepare, qc.Height, qc.View, qc.BlockHash), qc.ValidatiorsIdx, e.committee.ByteList)
+	if err != nil {
+		return consensus.NewConsensusError(consensus.UnExpectedError, err)
+	}
+	if !ok {
+		return consensus.NewConsensusError(consensus.UnExpectedError, errors.Errorf("invalid signature of lock of view %+v", qc.View))
+	}
+	return nil
+}
+
+// getNewViewCert - new view messages of more than 2/3 of the committee for view, nil when there are not enough
+func (e *HotStuff) getNewViewCert(view int) []NewViewMsg {
+	if !e.hasQuorum(len(e.newViews[view])) {
+		return nil
+	}
+	newViews := []NewViewMsg{}
+	for _, msg := range e.newViews[view] {
+		msg.LockedBlock = nil
+		newViews = append(newViews, msg)
+	}
+	sort.Slice(newViews, func(i, j int) bool {
+		return newViews[i].Validator < newViews[j].Validator
+	})
+	return newViews
+}
+
+// verifyNewViews - check the certificate of a proposal in view holds new view messages of more than 2/3 of the committee
+func (e *HotStuff) verifyNewViews(view int, newViews []NewViewMsg) error {
+	validators := make(map[string]bool)
+	for i := range newViews {
+		msg := &newViews[i]
+		if msg.Height != e.height || msg.View != view || validators[msg.Validator] {
+			return errors.Errorf("invalid new view certificate of height %+v view %+v", e.height, view)
+		}
+		if err := e.verifyNewView(msg); err != nil {
+			return err
+		}
+		validators[msg.Validator] = true
+	}
+	if !e.hasQuorum(len(validators)) {
+		return errors.Errorf("new view certificate of height %+v view %+v has %+v validators", e.height, view, len(validators))
+	}
+	return nil
+}
+
+// highestLock - lock of the highest view in new view messages, the smallest block hash among locks of the same view
+func highestLock(newViews []NewViewMsg) *QuorumCert {
+	var result *QuorumCert
+	for _, msg := range newViews {
+		lock := msg.Locked
+		if lock == nil {
+			continue
+		}
+		if result == nil || lock.View > result.View || (lock.View == result.View && lock.BlockHash.String() < result.BlockHash.String()) {
+			result = lock
+		}
+	}
+	return result
+}
+
+func combineVotes(votes map[string]VoteMsg, committee []string) (aggSig []byte, bridgeSigs [][]byte, validatorIdx []int, err error) {
+	for validator := range votes {
+		validatorIdx = append(validatorIdx, common.IndexOfStr(validator, committee))
+	}
+	sort.Ints(validatorIdx)
+	blsSigs := [][]byte{}
+	for _, idx := range validatorIdx {
+		blsSigs = append(blsSigs, votes[committee[idx]].Vote.BLS)
+		bridgeSigs = append(bridgeSigs, votes[committee[idx]].Vote.BRI)
+	}
+	aggSig, err = blsmultisig.Combine(blsSigs)
+	if err != nil {
+		return nil, nil, nil, consensus.NewConsensusError(consensus.CombineSignatureError, err)
+	}
+	return
+}
+
+// addQuorumCert - set the committee signature of block validation data to qc
+func addQuorumCert(block common.BlockInterface, qc *QuorumCert) error {
+	validationData, err := blsbft.DecodeValidationData(block.GetValidationField())
+	if err != nil {
+		return err
+	}
+	validationData.AggSig = qc.AggSig
+	validationData.BridgeSig = qc.BridgeSig
+	validationData.ValidatiorsIdx = qc.ValidatiorsIdx
+	validationDataString, err := blsbft.EncodeValidationData(*validationData)
+	if err != nil {
+		return err
+	}
+	return block.(blockValidation).AddValidationField(validationDataString)
+}
@@ -0,0 +1,211 @@
+package hotstuff
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/consensus/blsbft"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestHotStuff - consensus of the first of size validators in view 3 of height 10, with the keys of every validator
+func newTestHotStuff(t *testing.T, size int) (*HotStuff, []*blsbft.MiningKey) {
+	e := &HotStuff{
+		ChainKey:     common.BeaconChainKey,
+		height:       10,
+		view:         3,
+		blocks:       make(map[common.Hash]common.BlockInterface),
+		validated:    make(map[common.Hash]bool),
+		prepareVotes: make(map[voteKey]map[string]VoteMsg),
+		votes:        make(map[voteKey]map[string]VoteMsg),
+		newViews:     make(map[int]map[string]NewViewMsg),
+		logger:       common.NewBackend(nil).Logger("test", true),
+	}
+	keys := []*blsbft.MiningKey{}
+	for i := 0; i < size; i++ {
+		seed := common.HashB([]byte{byte(i)})
+		committeeKey, err := incognitokey.NewCommitteeKeyFromSeed(seed, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.committee.Keys = append(e.committee.Keys, committeeKey)
+		e.committee.StringList = append(e.committee.StringList, committeeKey.GetMiningKeyBase58(keyType))
+		e.committee.ByteList = append(e.committee.ByteList, committeeKey.MiningPubKey[keyType])
+		if err := e.LoadUserKey(base58.Base58Check{}.Encode(seed, common.Base58Version)); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, e.UserKeySet)
+	}
+	e.UserKeySet = keys[0]
+	return e, keys
+}
+
+func newTestPrepareVote(t *testing.T, e *HotStuff, keys []*blsbft.MiningKey, idx int, view int, blockHash common.Hash) VoteMsg {
+	blsSig, err := keys[idx].BLSSignData(voteSignData(phasePrepare, e.height, view, blockHash), idx, e.committee.ByteList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := VoteMsg{
+		Height:    e.height,
+		View:      view,
+		Phase:     phasePrepare,
+		BlockHash: blockHash,
+		Validator: e.committee.StringList[idx],
+		Vote:      vote{BLS: blsSig},
+	}
+	msg.Vote.Confirmation, err = keys[idx].BriSignData(voteConfirmData(&msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// newTestLock - QC of the prepare votes of validators at index in validators
+func newTestLock(t *testing.T, e *HotStuff, keys []*blsbft.MiningKey, validators []int, view int, blockHash common.Hash) *QuorumCert {
+	votes := make(map[string]VoteMsg)
+	for _, idx := range validators {
+		votes[e.committee.StringList[idx]] = newTestPrepareVote(t, e, keys, idx, view, blockHash)
+	}
+	qc, err := e.combineVotes(view, blockHash, votes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qc.BridgeSig = nil
+	return qc
+}
+
+func newTestNewView(t *testing.T, e *HotStuff, keys []*blsbft.MiningKey, idx int, view int, lock *QuorumCert) NewViewMsg {
+	msg := NewViewMsg{
+		Height:    e.height,
+		View:      view,
+		Validator: e.committee.StringList[idx],
+		Locked:    lock,
+	}
+	var err error
+	msg.Sig, err = keys[idx].BriSignData(newViewSignData(&msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestHotStuff_verifyNewView(t *testing.T) {
+	e, keys := newTestHotStuff(t, 4)
+	blockHash := common.HashH([]byte("block"))
+
+	lock := newTestLock(t, e, keys, []int{0, 1, 2}, 2, blockHash)
+	msg := newTestNewView(t, e, keys, 1, 3, lock)
+	assert.Nil(t, e.verifyNewView(&msg))
+
+	// a byzantine validator claims a lock with its own prepare vote only
+	forged := newTestLock(t, e, keys, []int{3}, 2, blockHash)
+	msg = newTestNewView(t, e, keys, 3, 3, forged)
+	assert.NotNil(t, e.verifyNewView(&msg))
+
+	forged = newTestLock(t, e, keys, []int{3}, 2, blockHash)
+	forged.ValidatiorsIdx = []int{1, 2, 3}
+	msg = newTestNewView(t, e, keys, 3, 3, forged)
+	assert.NotNil(t, e.verifyNewView(&msg))
+
+	forged = newTestLock(t, e, keys, []int{3}, 2, blockHash)
+	forged.ValidatiorsIdx = []int{3, 3, 3}
+	msg = newTestNewView(t, e, keys, 3, 3, forged)
+	assert.NotNil(t, e.verifyNewView(&msg))
+
+	// the lock of view 1 is claimed for view 2
+	moved := newTestLock(t, e, keys, []int{0, 1, 2}, 1, blockHash)
+	moved.View = 2
+	msg = newTestNewView(t, e, keys, 1, 3, moved)
+	assert.NotNil(t, e.verifyNewView(&msg))
+
+	// a lock is of a view before the new view
+	msg = newTestNewView(t, e, keys, 1, 2, lock)
+	assert.NotNil(t, e.verifyNewView(&msg))
+}
+
+func TestHotStuff_verifyNewViews(t *testing.T) {
+	e, keys := newTestHotStuff(t, 4)
+	blockHash := common.HashH([]byte("block"))
+	forged := newTestLock(t, e, keys, []int{3}, 2, blockHash)
+
+	newViews := []NewViewMsg{
+		newTestNewView(t, e, keys, 0, 3, nil),
+		newTestNewView(t, e, keys, 1, 3, nil),
+		newTestNewView(t, e, keys, 2, 3, nil),
+	}
+	assert.Nil(t, e.verifyNewViews(3, newViews))
+	assert.NotNil(t, e.verifyNewViews(3, newViews[:2]))
+	assert.NotNil(t, e.verifyNewViews(3, append(newViews[:2:2], newViews[1])))
+	assert.NotNil(t, e.verifyNewViews(3, append(newViews[:2:2], newTestNewView(t, e, keys, 3, 3, forged))))
+}
+
+func TestHotStuff_tryLock(t *testing.T) {
+	e, keys := newTestHotStuff(t, 4)
+	blockHash := common.HashH([]byte("block"))
+	key := voteKey{View: 4, BlockHash: blockHash}
+	e.prepareVotes[key] = make(map[string]VoteMsg)
+
+	for _, idx := range []int{1, 2} {
+		e.prepareVotes[key][e.committee.StringList[idx]] = newTestPrepareVote(t, e, keys, idx, 4, blockHash)
+	}
+	e.tryLock(key)
+	assert.Nil(t, e.locked)
+
+	e.prepareVotes[key][e.committee.StringList[3]] = newTestPrepareVote(t, e, keys, 3, 4, blockHash)
+	e.tryLock(key)
+	if assert.NotNil(t, e.locked) {
+		assert.Equal(t, 4, e.locked.View)
+		assert.Equal(t, blockHash, e.locked.BlockHash)
+		assert.Nil(t, e.verifyLock(e.locked))
+	}
+	// a lock of the view of the QC moves the node to the view
+	assert.Equal(t, 4, e.view)
+
+	lower := newTestLock(t, e, keys, []int{0, 1, 2}, 2, common.HashH([]byte("other")))
+	e.lock(lower)
+	assert.Equal(t, blockHash, e.locked.BlockHash)
+}
+
+func Test_highestLock(t *testing.T) {
+	hashA := common.HashH([]byte("a"))
+	hashB := common.HashH([]byte("b"))
+	lowHash, highHash := hashA, hashB
+	if highHash.String() < lowHash.String() {
+		lowHash, highHash = highHash, lowHash
+	}
+	tests := []struct {
+		name     string
+		newViews []NewViewMsg
+		want     *QuorumCert
+	}{
+		{
+			name:     "no lock",
+			newViews: []NewViewMsg{{Validator: "1"}, {Validator: "2"}},
+			want:     nil,
+		},
+		{
+			name: "highest view",
+			newViews: []NewViewMsg{
+				{Validator: "1", Locked: &QuorumCert{View: 1, BlockHash: lowHash}},
+				{Validator: "2", Locked: &QuorumCert{View: 2, BlockHash: highHash}},
+				{Validator: "3"},
+			},
+			want: &QuorumCert{View: 2, BlockHash: highHash},
+		},
+		{
+			name: "smallest hash in the same view",
+			newViews: []NewViewMsg{
+				{Validator: "1", Locked: &QuorumCert{View: 2, BlockHash: highHash}},
+				{Validator: "2", Locked: &QuorumCert{View: 2, BlockHash: lowHash}},
+			},
+			want: &QuorumCert{View: 2, BlockHash: lowHash},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highestLock(tt.newViews))
+		})
+	}
+}
@@ -5,16 +5,20 @@ import (
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/incognitokey"
 	"github.com/incognitochain/incognito-chain/wire"
+	libp2p "github.com/libp2p/go-libp2p-peer"
 )
 
 type NodeInterface interface {
 	PushMessageToChain(msg wire.Message, chain blockchain.ChainInterface) error
+	PushMessageToBeacon(msg wire.Message, exclusivePeerIDs map[libp2p.ID]bool) error
 	// PushMessageToBlockToAll(msg wire.Message) error
 	UpdateConsensusState(role string, userPbk string, currentShard *byte, beaconCommittee []string, shardCommittee map[byte][]string)
 	IsEnableMining() bool
 	GetMiningKeys() string
 	GetPrivateKey() string
 	DropAllConnections()
+	// GetConsensusDataDir - directory consensus keeps what this node signed in, nothing is kept when it is empty
+	GetConsensusDataDir() string
 }
 
 type ConsensusInterface interface {
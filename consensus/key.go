@@ -30,6 +30,14 @@ func (engine *Engine) LoadMiningKeys(keysString string) error {
 						return errors.New("Key for this consensus can not load - " + keyConsensus)
 					}
 					engine.userMiningPublicKeys[availableConsensus] = *AvailableConsensus[availableConsensus].GetUserPublicKey()
+					for _, implementation := range AvailableImplementations {
+						if implementation.GetConsensusName() != availableConsensus {
+							continue
+						}
+						if err := implementation.LoadUserKey(keyConsensus); err != nil {
+							return errors.New("Key for this consensus can not load - " + keyConsensus)
+						}
+					}
 				} else {
 					return errors.New("Consensus type for this key isn't exist " + availableConsensus)
 				}
@@ -0,0 +1,251 @@
+package badgerdb
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// value log files are rewritten when at least half of their content is stale,
+	// commitments and serial numbers are never deleted so most garbage comes from best states and indexes
+	valueLogGCDiscardRatio = 0.5
+	valueLogGCInterval     = 10 * time.Minute
+)
+
+// store implements lvdb.KeyValueStore on top of BadgerDB,
+// so the badgerdb driver shares the key schema of the leveldb driver
+type store struct {
+	db   *badger.DB
+	quit chan struct{}
+}
+
+func open(dbPath string) (database.DatabaseInterface, error) {
+	bdb, err := badger.Open(badger.DefaultOptions(dbPath).WithLogger(logger{}))
+	if err != nil {
+		return nil, database.NewDatabaseError(database.OpenDbErr, errors.Wrapf(err, "badger.Open %s", dbPath))
+	}
+	s := &store{db: bdb, quit: make(chan struct{})}
+	go s.runValueLogGC()
+	return lvdb.NewDatabase(s), nil
+}
+
+func (s *store) runValueLogGC() {
+	ticker := time.NewTicker(valueLogGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			// one call rewrites at most one file, keep going until nothing is left to collect
+			for s.db.RunValueLogGC(valueLogGCDiscardRatio) == nil {
+			}
+		}
+	}
+}
+
+func (s *store) Has(key []byte) (bool, error) {
+	err := s.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns lvdb.ErrNotFound when key does not exist
+func (s *store) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, lvdb.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *store) Put(key, value []byte) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+func (s *store) Delete(key []byte) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+// Write stores all records in one transaction, badger rejects batches bigger than its transaction limit
+func (s *store) Write(data []database.BatchData) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		for _, v := range data {
+			if err := txn.Set(v.Key, v.Value); err != nil {
+				return errors.Wrapf(err, "batch of %+v records", len(data))
+			}
+		}
+		return nil
+	})
+}
+
+func (s *store) NewIterator(prefix []byte) lvdb.Iterator {
+	return s.NewIteratorFrom(prefix, prefix)
+}
+
+func (s *store) NewIteratorFrom(prefix []byte, start []byte) lvdb.Iterator {
+	txn := s.db.NewTransaction(false)
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
+	return &iterator{
+		txn:    txn,
+		iter:   txn.NewIterator(opts),
+		prefix: prefix,
+		start:  start,
+	}
+}
+
+func (s *store) Close() error {
+	close(s.quit)
+	return s.db.Close()
+}
+
+// iterator reads records from a read-only transaction, so it sees the store as it was when created
+type iterator struct {
+	txn      *badger.Txn
+	iter     *badger.Iterator
+	prefix   []byte
+	start    []byte
+	started  bool
+	done     bool
+	released bool
+	key      []byte
+	value    []byte
+	err      error
+}
+
+func (it *iterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if it.started {
+		it.iter.Next()
+	} else {
+		it.iter.Seek(it.start)
+		it.started = true
+	}
+	return it.load()
+}
+
+// Last moves to the record with the biggest key of the prefix, iteration ends after it
+func (it *iterator) Last() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	it.iter.Close()
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	it.iter = it.txn.NewIterator(opts)
+	it.started = true
+	it.done = true
+	// a reverse seek lands on the biggest key not greater than the seek key,
+	// that is the successor of the prefix itself when it exists
+	successor := prefixSuccessor(it.prefix)
+	if successor == nil {
+		it.iter.Rewind()
+	} else {
+		it.iter.Seek(successor)
+		if it.iter.Valid() && !it.iter.ValidForPrefix(it.prefix) {
+			it.iter.Next()
+		}
+	}
+	return it.load()
+}
+
+func (it *iterator) load() bool {
+	it.key, it.value = nil, nil
+	if !it.iter.ValidForPrefix(it.prefix) {
+		return false
+	}
+	item := it.iter.Item()
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.key = item.KeyCopy(nil)
+	it.value = value
+	return true
+}
+
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release may be called more than once, the read transaction must be discarded or badger keeps old versions forever
+func (it *iterator) Release() {
+	if it.released {
+		return
+	}
+	it.released = true
+	it.done = true
+	it.iter.Close()
+	it.txn.Discard()
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// prefixSuccessor returns the smallest key bigger than every key starting with prefix,
+// nil when there is none (empty prefix or prefix of 0xff only)
+func prefixSuccessor(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			successor := append([]byte{}, prefix[:i+1]...)
+			successor[i]++
+			return successor
+		}
+	}
+	return nil
+}
+
+// logger forwards badger warnings and errors to the database logger
+type logger struct{}
+
+func (logger) Errorf(format string, v ...interface{}) {
+	if database.Logger.Log != nil {
+		database.Logger.Log.Errorf(format, v...)
+	}
+}
+
+func (logger) Warningf(format string, v ...interface{}) {
+	if database.Logger.Log != nil {
+		database.Logger.Log.Warnf(format, v...)
+	}
+}
+
+func (logger) Infof(format string, v ...interface{}) {}
+
+func (logger) Debugf(format string, v ...interface{}) {}
@@ -1,6 +1,10 @@
 package database
 
-import "github.com/pkg/errors"
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
 
 // Driver defines a structure for backend drivers to use when they registered
 // themselves as a backend which implements the DatabaseInterface interface.
@@ -28,3 +32,13 @@ func Open(typ string, args ...interface{}) (DatabaseInterface, error) {
 	}
 	return d.Open(args...)
 }
+
+// Drivers returns the types of all registered drivers in alphabetical order.
+func Drivers() []string {
+	dbTypes := make([]string, 0, len(drivers))
+	for dbType := range drivers {
+		dbTypes = append(dbTypes, dbType)
+	}
+	sort.Strings(dbTypes)
+	return dbTypes
+}
@@ -0,0 +1,3 @@
+// Package drivertest holds the compatibility tests every registered database driver has to pass,
+// drivers shipped with the node are imported by the tests so a new driver only needs to be added there.
+package drivertest
@@ -0,0 +1,247 @@
+package drivertest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	_ "github.com/incognitochain/incognito-chain/database/badgerdb"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/stretchr/testify/assert"
+)
+
+// runWithEveryDriver runs test against a fresh database of every registered driver
+func runWithEveryDriver(t *testing.T, test func(t *testing.T, db database.DatabaseInterface)) {
+	assert.Contains(t, database.Drivers(), "leveldb")
+	assert.Contains(t, database.Drivers(), "badgerdb")
+	for _, dbType := range database.Drivers() {
+		t.Run(dbType, func(t *testing.T) {
+			dbPath, err := ioutil.TempDir(os.TempDir(), "test_drivertest_"+dbType)
+			assert.Nil(t, err)
+			defer os.RemoveAll(dbPath)
+			db, err := database.Open(dbType, dbPath)
+			if !assert.Nil(t, err) {
+				return
+			}
+			defer db.Close()
+			test(t, db)
+		})
+	}
+}
+
+func TestDriverBasicRecords(t *testing.T) {
+	runWithEveryDriver(t, func(t *testing.T, db database.DatabaseInterface) {
+		_, err := db.Get([]byte("missing"))
+		assert.NotNil(t, err)
+		ok, err := db.HasValue([]byte("missing"))
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		assert.Nil(t, db.Put([]byte("a-1"), []byte("v1")))
+		assert.Nil(t, db.Put([]byte("a-empty"), []byte{}))
+		assert.Nil(t, db.PutBatch([]database.BatchData{
+			{Key: []byte("a-3"), Value: []byte("v3")},
+			{Key: []byte("a-2"), Value: []byte("v2")},
+			{Key: []byte{'a', '-', 0xff}, Value: []byte("vff")},
+			{Key: []byte("b-1"), Value: []byte("other")},
+		}))
+		value, err := db.Get([]byte("a-1"))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("v1"), value)
+		ok, err = db.HasValue([]byte("a-empty"))
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// records of a prefix come in key order and only records of the prefix are visited
+		keys := []string{}
+		err = db.IterateRecordsByPrefix([]byte("a-"), func(key, value []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a-1", "a-2", "a-3", "a-empty", string([]byte{'a', '-', 0xff})}, keys)
+
+		assert.Nil(t, db.Delete([]byte("a-1")))
+		ok, err = db.HasValue([]byte("a-1"))
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		// deleting a missing record is not an error
+		assert.Nil(t, db.Delete([]byte("a-1")))
+	})
+}
+
+func TestDriverReopen(t *testing.T) {
+	for _, dbType := range database.Drivers() {
+		dbPath, err := ioutil.TempDir(os.TempDir(), "test_drivertest_reopen_"+dbType)
+		assert.Nil(t, err)
+		defer os.RemoveAll(dbPath)
+		db, err := database.Open(dbType, dbPath)
+		assert.Nil(t, err, dbType)
+		assert.Nil(t, db.Put([]byte("key"), []byte("value")), dbType)
+		assert.Nil(t, db.Close(), dbType)
+
+		db, err = database.Open(dbType, dbPath)
+		assert.Nil(t, err, dbType)
+		value, err := db.Get([]byte("key"))
+		assert.Nil(t, err, dbType)
+		assert.Equal(t, []byte("value"), value, dbType)
+		assert.Nil(t, db.Close(), dbType)
+	}
+}
+
+func TestDriverBlocks(t *testing.T) {
+	runWithEveryDriver(t, func(t *testing.T, db database.DatabaseInterface) {
+		block := map[string]uint64{"Height": 2}
+		blockHash := common.HashH([]byte("block"))
+		assert.Nil(t, db.StoreShardBlock(block, blockHash, 1, nil))
+		assert.Nil(t, db.StoreShardBlockIndex(blockHash, 2, 1, nil))
+		txHash := common.HashH([]byte("tx"))
+		batch := []database.BatchData{}
+		assert.Nil(t, db.StoreTransactionIndex(txHash, blockHash, 3, &batch))
+		assert.Nil(t, db.PutBatch(batch))
+
+		ok, err := db.HasBlock(blockHash)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		hash, err := db.GetBlockByIndex(2, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, blockHash, hash)
+		height, shardID, err := db.GetIndexOfBlock(blockHash)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), height)
+		assert.Equal(t, byte(1), shardID)
+		hash, index, err := db.GetTransactionIndexById(txHash)
+		assert.Nil(t, err)
+		assert.Equal(t, blockHash, hash)
+		assert.Equal(t, 3, index)
+
+		beaconBlockHash := common.HashH([]byte("beacon block"))
+		assert.Nil(t, db.StoreBeaconBlock(block, beaconBlockHash, nil))
+		assert.Nil(t, db.StoreBeaconBlockIndex(beaconBlockHash, 2))
+		hash, err = db.GetBeaconBlockHashByIndex(2)
+		assert.Nil(t, err)
+		assert.Equal(t, beaconBlockHash, hash)
+		_, err = db.FetchBeaconBlock(beaconBlockHash)
+		assert.Nil(t, err)
+
+		assert.Nil(t, db.PruneBlock(blockHash))
+		assert.Nil(t, db.StorePrunedHeight(false, 1, 2))
+		ok, err = db.HasBlock(blockHash)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		_, err = db.FetchBlock(blockHash)
+		assert.NotNil(t, err)
+		prunedHeight, err := db.FetchPrunedHeight(false, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), prunedHeight)
+	})
+}
+
+func TestDriverPrivacyRecords(t *testing.T) {
+	runWithEveryDriver(t, func(t *testing.T, db database.DatabaseInterface) {
+		tokenID := common.PRVCoinID
+		serialNumbers := [][]byte{common.HashB([]byte("sn1")), common.HashB([]byte("sn2"))}
+		assert.Nil(t, db.StoreSerialNumbers(tokenID, serialNumbers, 0))
+		ok, err := db.HasSerialNumber(tokenID, serialNumbers[1], 0)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = db.HasSerialNumber(tokenID, serialNumbers[1], 1)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		listedSerialNumbers, err := db.ListSerialNumber(tokenID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(listedSerialNumbers))
+
+		publicKey := common.HashB([]byte("public key"))
+		commitments := [][]byte{common.HashB([]byte("cm1")), common.HashB([]byte("cm2")), common.HashB([]byte("cm3"))}
+		assert.Nil(t, db.StoreCommitments(tokenID, publicKey, commitments, 0))
+		length, err := db.GetCommitmentLength(tokenID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), length.Uint64())
+		commitment, err := db.GetCommitmentByIndex(tokenID, 1, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, commitments[1], commitment)
+		index, err := db.GetCommitmentIndex(tokenID, commitments[2], 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), index.Uint64())
+		listedCommitments, err := db.ListCommitment(tokenID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(listedCommitments))
+
+		outputCoins := [][]byte{[]byte("coin1"), []byte("coin2")}
+		assert.Nil(t, db.StoreOutputCoins(tokenID, publicKey, outputCoins, 0))
+		storedOutputCoins, err := db.GetOutcoinsByPubkey(tokenID, publicKey, 0)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, outputCoins, storedOutputCoins)
+
+		snds := [][]byte{common.HashB([]byte("snd1"))}
+		assert.Nil(t, db.StoreSNDerivators(tokenID, snds))
+		ok, err = db.HasSNDerivator(tokenID, snds[0])
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestDriverStates(t *testing.T) {
+	runWithEveryDriver(t, func(t *testing.T, db database.DatabaseInterface) {
+		_, err := db.FetchBeaconBestState()
+		assert.NotNil(t, err)
+		assert.Nil(t, db.StoreBeaconBestState(map[string]uint64{"BeaconHeight": 5}, nil))
+		bestState, err := db.FetchBeaconBestState()
+		assert.Nil(t, err)
+		assert.Equal(t, `{"BeaconHeight":5}`, string(bestState))
+
+		assert.Nil(t, db.StorePrevBestState([]byte("prev"), false, 2))
+		prevBestState, err := db.FetchPrevBestState(false, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("prev"), prevBestState)
+		assert.Nil(t, db.CleanBackup(false, 2))
+		_, err = db.FetchPrevBestState(false, 2)
+		assert.NotNil(t, err)
+
+		// a missing pool is not an error, the latest pool is looked up with a reverse iteration
+		pool, err := db.GetLatestPDEPoolForPair("token1", "token2")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(pool))
+		assert.Nil(t, db.UpdatePDEPoolForPair(3, "token1", "token2", []byte("pool at 3")))
+		assert.Nil(t, db.UpdatePDEPoolForPair(4, "token2", "token1", []byte("pool at 4")))
+		assert.Nil(t, db.Put([]byte("pdepoolz"), []byte("after the pool prefix")))
+		pool, err = db.GetLatestPDEPoolForPair("token1", "token2")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("pool at 4"), pool)
+		pool, err = db.GetPDEPoolForPair(3, "token1", "token2")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("pool at 3"), pool)
+	})
+}
+
+func TestDriverTxHistory(t *testing.T) {
+	runWithEveryDriver(t, func(t *testing.T, db database.DatabaseInterface) {
+		publicKey := []byte{1, 2, 3}
+		// history of a longer public key starting with the same bytes must not be listed
+		assert.Nil(t, db.StoreTxHistory([]byte{1, 2, 3, 4}, lvdb.BuildTxHistorySortKey(1, 0, 1, 0, 0), []byte("other"), nil))
+		batch := []database.BatchData{}
+		assert.Nil(t, db.StoreTxHistory(publicKey, lvdb.BuildTxHistorySortKey(5, 1, 3, 0, 0), []byte("e3"), &batch))
+		assert.Nil(t, db.StoreTxHistory(publicKey, lvdb.BuildTxHistorySortKey(5, 0, 9, 1, 1), []byte("e2"), &batch))
+		assert.Nil(t, db.StoreTxHistory(publicKey, lvdb.BuildTxHistorySortKey(2, 7, 100, 2, 0), []byte("e1"), &batch))
+		assert.Nil(t, db.PutBatch(batch))
+		assert.Nil(t, db.StoreTxHistory(publicKey, lvdb.BuildTxHistorySortKey(256, 0, 1, 0, 0), []byte("e4"), nil))
+
+		sortKeys, values, err := db.ListTxHistory(publicKey, nil, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("e1"), []byte("e2")}, values)
+		sortKeys, values, err = db.ListTxHistory(publicKey, sortKeys[1], 2)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("e3"), []byte("e4")}, values)
+		_, values, err = db.ListTxHistory(publicKey, sortKeys[1], 2)
+		assert.Nil(t, err)
+		assert.Empty(t, values)
+
+		assert.Nil(t, db.DeleteTxHistory(publicKey, lvdb.BuildTxHistorySortKey(5, 0, 9, 1, 1)))
+		_, values, err = db.ListTxHistory(publicKey, nil, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("e1"), []byte("e3"), []byte("e4")}, values)
+	})
+}
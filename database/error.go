@@ -103,6 +103,24 @@ const (
 	DeduceShareError
 	TrackPDEStatusError
 	GetPDEStatusError
+	StorePDELimitOrderError
+	StorePDETradingFeeError
+	StorePDEPairStatsError
+
+	// portal
+	StorePortalStateError
+	TrackPortalStatusError
+	GetPortalStatusError
+
+	// prune
+	StorePrunedHeightError
+	FetchPrunedHeightError
+	PruneBlockError
+
+	// Tx history
+	StoreTxHistoryError
+	DeleteTxHistoryError
+	ListTxHistoryError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -206,6 +224,24 @@ var ErrCodeMessage = map[int]struct {
 	DeduceShareError:                       {-13012, "Deduce share error"},
 	TrackPDEStatusError:                    {-13013, "Track pde status error"},
 	GetPDEStatusError:                      {-13014, "Get pde status error"},
+	StorePDELimitOrderError:                {-13015, "Store pde limit order error"},
+	StorePDETradingFeeError:                {-13016, "Store pde trading fee error"},
+	StorePDEPairStatsError:                 {-13017, "Store pde pair stats error"},
+
+	// -14xxx Portal
+	StorePortalStateError:  {-14001, "Store portal state error"},
+	TrackPortalStatusError: {-14002, "Track portal status error"},
+	GetPortalStatusError:   {-14003, "Get portal status error"},
+
+	// -15xxx Prune
+	StorePrunedHeightError: {-15001, "Store pruned height error"},
+	FetchPrunedHeightError: {-15002, "Fetch pruned height error"},
+	PruneBlockError:        {-15003, "Prune block error hash=%+v"},
+
+	// -16xxx Tx history
+	StoreTxHistoryError:  {-16001, "Store tx history error"},
+	DeleteTxHistoryError: {-16002, "Delete tx history error"},
+	ListTxHistoryError:   {-16003, "List tx history error"},
 }
 
 type DatabaseError struct {
@@ -19,6 +19,7 @@ type DatabaseInterface interface {
 	Get(key []byte) ([]byte, error)
 	Delete(key []byte) error
 	HasValue(key []byte) (bool, error)
+	IterateRecordsByPrefix(prefix []byte, fn func(key, value []byte) error) error
 	Close() error
 
 	// Process on Block data
@@ -65,6 +66,11 @@ type DatabaseInterface interface {
 	GetTransactionIndexById(txId common.Hash) (common.Hash, int, error)
 	DeleteTransactionIndex(txId common.Hash) error
 
+	// Pruning
+	StorePrunedHeight(isBeacon bool, shardID byte, height uint64) error
+	FetchPrunedHeight(isBeacon bool, shardID byte) (uint64, error)
+	PruneBlock(hash common.Hash) error
+
 	// Best state of Prev
 	StorePrevBestState(val []byte, isBeacon bool, shardID byte) error
 	FetchPrevBestState(isBeacon bool, shardID byte) ([]byte, error)
@@ -126,6 +132,11 @@ type DatabaseInterface interface {
 	StoreTxByPublicKey(publicKey []byte, txID common.Hash, shardID byte) error
 	GetTxByPublicKey(publicKey []byte) (map[byte][]common.Hash, error)
 
+	// Tx history of public key, ordered by sort key
+	StoreTxHistory(publicKey []byte, sortKey []byte, value []byte, bd *[]BatchData) error
+	DeleteTxHistory(publicKey []byte, sortKey []byte) error
+	ListTxHistory(publicKey []byte, afterSortKey []byte, limit int) ([][]byte, [][]byte, error)
+
 	// Fee estimator
 	StoreFeeEstimator(val []byte, shardID byte) error
 	GetFeeEstimator(shardID byte) ([]byte, error)
@@ -199,4 +210,8 @@ type DatabaseInterface interface {
 	GetPDEStatus(prefix []byte, suffix []byte) (byte, error)
 	TrackPDEContributionStatus(prefix []byte, suffix []byte, statusContent []byte) error
 	GetPDEContributionStatus(prefix []byte, suffix []byte) ([]byte, error)
+
+	// portal
+	TrackPortalStatus(prefix []byte, suffix []byte, statusContent []byte) error
+	GetPortalStatus(prefix []byte, suffix []byte) ([]byte, error)
 }
@@ -241,7 +241,7 @@ func (db *db) StoreBeaconCommitteeByHeight(height uint64, v interface{}) error {
 		return database.NewDatabaseError(database.StoreBeaconCommitteeByHeightError, err)
 	}
 
-	if err := db.lvdb.Put(key, val, nil); err != nil {
+	if err := db.store.Put(key, val); err != nil {
 		return database.NewDatabaseError(database.StoreBeaconCommitteeByHeightError, err)
 	}
 	return nil
@@ -260,7 +260,7 @@ func (db *db) StoreRewardReceiverByHeight(height uint64, v interface{}) error {
 	if err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "json.Marshal"))
 	}
-	if err := db.lvdb.Put(key, val, nil); err != nil {
+	if err := db.store.Put(key, val); err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.lvdb.put"))
 	}
 	return nil
@@ -310,7 +310,7 @@ func (db *db) FetchBeaconCommitteeByHeight(height uint64) ([]byte, error) {
 	binary.LittleEndian.PutUint64(buf, height)
 	key = append(key, buf[:]...)
 
-	b, err := db.lvdb.Get(key, nil)
+	b, err := db.store.Get(key)
 	if err != nil {
 		return nil, database.NewDatabaseError(database.FetchBeaconCommitteeByHeightError, err)
 	}
@@ -327,7 +327,7 @@ func (db *db) FetchRewardReceiverByHeight(height uint64) ([]byte, error) {
 	binary.LittleEndian.PutUint64(buf, height)
 	key = append(key, buf[:]...)
 
-	b, err := db.lvdb.Get(key, nil)
+	b, err := db.store.Get(key)
 	if err != nil {
 		return nil, database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.get"))
 	}
@@ -9,7 +9,6 @@ import (
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/pkg/errors"
 	lvdberr "github.com/syndtr/goleveldb/leveldb/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // TODO - change json to CamelCase
@@ -36,7 +35,7 @@ func (db *db) IsETHTxHashIssued(
 	uniqETHTx []byte,
 ) (bool, error) {
 	key := append(ethTxHashIssuedPrefix, uniqETHTx...)
-	contentBytes, dbErr := db.lvdb.Get(key, nil)
+	contentBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return false, database.NewDatabaseError(database.IsETHTxHashIssuedError, errors.Wrap(dbErr, "db.lvdb.Get"))
 	}
@@ -99,7 +98,7 @@ func (db *db) CanProcessTokenPair(
 	}
 
 	key := append(decentralizedBridgePrefix, incTokenID[:]...)
-	contentBytes, dbErr := db.lvdb.Get(key, nil)
+	contentBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return false, database.NewDatabaseError(database.BridgeUnexpectedError, dbErr)
 	}
@@ -116,7 +115,7 @@ func (db *db) CanProcessTokenPair(
 		return false, nil
 	}
 	// else: could not find incTokenID out
-	iter := db.lvdb.NewIterator(util.BytesPrefix(decentralizedBridgePrefix), nil)
+	iter := db.store.NewIterator(decentralizedBridgePrefix)
 	for iter.Next() {
 		value := iter.Value()
 		itemBytes := make([]byte, len(value))
@@ -160,7 +159,7 @@ func (db *db) UpdateBridgeTokenInfo(
 ) error {
 	prefix := getBridgePrefix(isCentralized)
 	key := append(prefix, incTokenID[:]...)
-	bridgeTokenInfoBytes, dbErr := db.lvdb.Get(key, nil)
+	bridgeTokenInfoBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.BridgeUnexpectedError, dbErr)
 	}
@@ -219,7 +218,7 @@ func (db *db) IsBridgeTokenExistedByType(
 ) (bool, error) {
 	prefix := getBridgePrefix(isCentralized)
 	key := append(prefix, incTokenID[:]...)
-	tokenInfoBytes, dbErr := db.lvdb.Get(key, nil)
+	tokenInfoBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return false, database.NewDatabaseError(database.BridgeUnexpectedError, dbErr)
 	}
@@ -231,7 +230,7 @@ func (db *db) IsBridgeTokenExistedByType(
 
 func (db *db) getBridgeTokensByType(isCentralized bool) ([]*BridgeTokenInfo, error) {
 	prefix := getBridgePrefix(isCentralized)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(prefix), nil)
+	iter := db.store.NewIterator(prefix)
 	bridgeTokenInfos := []*BridgeTokenInfo{}
 	for iter.Next() {
 		value := iter.Value()
@@ -283,7 +282,7 @@ func (db *db) TrackBridgeReqWithStatus(txReqID common.Hash, status byte, bd *[]d
 
 func (db *db) GetBridgeReqWithStatus(txReqID common.Hash) (byte, error) {
 	key := append(bridgePrefix, txReqID[:]...)
-	bridgeRedStatusBytes, dbErr := db.lvdb.Get(key, nil)
+	bridgeRedStatusBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return common.BridgeRequestNotFoundStatus, database.NewDatabaseError(database.BridgeUnexpectedError, dbErr)
 	}
@@ -66,6 +66,30 @@ var (
 	PDEContributionStatusPrefix  = []byte("pdecontributionstatus-")
 	PDETradeStatusPrefix         = []byte("pdetradestatus-")
 	PDEWithdrawalStatusPrefix    = []byte("pdewithdrawalstatus-")
+	PDELimitOrderPrefix          = []byte("pdelimitorder-")
+	PDELimitOrderStatusPrefix    = []byte("pdelimitorderstatus-")
+	PDETradingFeePrefix          = []byte("pdetradingfee-")
+	PDEPairStatsPrefix           = []byte("pdepairstats-")
+
+	// Portal
+	PortalCustodianStatePrefix            = []byte("portalcustodian-")
+	PortalWaitingPortingRequestsPrefix    = []byte("portalwaitingporting-")
+	PortalWaitingRedeemRequestsPrefix     = []byte("portalwaitingredeem-")
+	PortalCustodianDepositStatusPrefix    = []byte("portalcustodiandepositstatus-")
+	PortalPortingRequestStatusPrefix      = []byte("portalportingrequeststatus-")
+	PortalReqPTokenStatusPrefix           = []byte("portalreqptokenstatus-")
+	PortalRedeemRequestStatusPrefix       = []byte("portalredeemrequeststatus-")
+	PortalReqUnlockCollateralStatusPrefix = []byte("portalrequnlockcollateralstatus-")
+	PortalRelayingBTCHeaderStatusPrefix   = []byte("portalrelayingbtcheaderstatus-")
+	PortalExternalTxPrefix                = []byte("portalexternaltx-")
+	PortalBTCHeaderPrefix                 = []byte("portalbtcheader-")
+	PortalBTCHeaderChainTipPrefix         = []byte("portalbtcheaderchaintip-")
+
+	// prune
+	prunedHeightPrefix = []byte("prunedheight-")
+
+	// tx history
+	txHistoryPrefix = []byte("txhistory-")
 )
 
 // value
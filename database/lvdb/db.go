@@ -7,7 +7,7 @@ import (
 )
 
 type db struct {
-	lvdb *leveldb.DB
+	store KeyValueStore
 }
 
 func open(dbPath string) (database.DatabaseInterface, error) {
@@ -15,15 +15,15 @@ func open(dbPath string) (database.DatabaseInterface, error) {
 	if err != nil {
 		return nil, database.NewDatabaseError(database.OpenDbErr, errors.Wrapf(err, "levelvdb.OpenFile %s", dbPath))
 	}
-	return &db{lvdb: lvdb}, nil
+	return NewDatabase(&levelDBStore{lvdb: lvdb}), nil
 }
 
 func (db *db) Close() error {
-	return errors.Wrap(db.lvdb.Close(), "db.lvdb.Close")
+	return errors.Wrap(db.store.Close(), "db.lvdb.Close")
 }
 
 func (db *db) HasValue(key []byte) (bool, error) {
-	ret, err := db.lvdb.Has(key, nil)
+	ret, err := db.store.Has(key)
 	if err != nil {
 		return false, database.NewDatabaseError(database.NotExistValue, err)
 	}
@@ -31,28 +31,18 @@ func (db *db) HasValue(key []byte) (bool, error) {
 }
 
 func (db *db) Put(key, value []byte) error {
-	if err := db.lvdb.Put(key, value, nil); err != nil {
+	if err := db.store.Put(key, value); err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.lvdb.Put"))
 	}
 	return nil
 }
 
-func (db *db) Batch(data []database.BatchData) leveldb.Batch {
-	batch := new(leveldb.Batch)
-	for _, v := range data {
-		batch.Put(v.Key, v.Value)
-	}
-	return *batch
-}
-
 func (db *db) PutBatch(data []database.BatchData) error {
-	batch := db.Batch(data)
-	err := db.lvdb.Write(&batch, nil)
-	return err
+	return db.store.Write(data)
 }
 
 func (db *db) Delete(key []byte) error {
-	err := db.lvdb.Delete(key, nil)
+	err := db.store.Delete(key)
 	if err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.lvdb.Delete"))
 	}
@@ -60,9 +50,25 @@ func (db *db) Delete(key []byte) error {
 }
 
 func (db *db) Get(key []byte) ([]byte, error) {
-	value, err := db.lvdb.Get(key, nil)
+	value, err := db.store.Get(key)
 	if err != nil {
 		return nil, database.NewDatabaseError(database.LvDbNotFound, errors.Wrap(err, "db.lvdb.Get"))
 	}
 	return value, nil
 }
+
+// IterateRecordsByPrefix calls fn for every record whose key starts with prefix, in key order.
+// key and value are only valid during the call and must be copied to be retained.
+func (db *db) IterateRecordsByPrefix(prefix []byte, fn func(key, value []byte) error) error {
+	iter := db.store.NewIterator(prefix)
+	defer iter.Release()
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.lvdb.NewIterator"))
+	}
+	return nil
+}
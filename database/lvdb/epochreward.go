@@ -4,7 +4,6 @@ import (
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/common/base58"
 	"github.com/incognitochain/incognito-chain/database"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 /**
@@ -123,7 +122,8 @@ func (db *db) GetAllTokenIDForReward(
 	keyForSearch = append(keyForSearch, shardRequestRewardPrefix...)
 	keyForSearch = append(keyForSearch, common.Uint64ToBytes(epoch)...)
 	result := map[common.Hash]struct{}{}
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(keyForSearch), nil)
+	iterator := db.store.NewIterator(keyForSearch)
+	defer iterator.Release()
 	for iterator.Next() {
 		key := make([]byte, len(iterator.Key()))
 		copy(key, iterator.Key())
@@ -180,7 +180,8 @@ func (db *db) AddCommitteeReward(
 // ListCommitteeReward - get reward on tokenID of all committee
 func (db *db) ListCommitteeReward() map[string]map[common.Hash]uint64 {
 	result := make(map[string]map[common.Hash]uint64)
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(committeeRewardPrefix), nil)
+	iterator := db.store.NewIterator(committeeRewardPrefix)
+	defer iterator.Release()
 	for iterator.Next() {
 		key := make([]byte, len(iterator.Key()))
 		copy(key, iterator.Key())
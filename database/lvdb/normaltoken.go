@@ -13,7 +13,6 @@ import (
 	"github.com/incognitochain/incognito-chain/databasemp"
 	"github.com/incognitochain/incognito-chain/privacy"
 	"github.com/pkg/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // StoreCustomToken - store data about custom token
@@ -60,7 +59,7 @@ func (db *db) NormalTokenIDExisted(tokenID common.Hash) bool {
 */
 func (db *db) ListNormalToken() ([][]byte, error) {
 	result := make([][]byte, 0)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(tokenInitPrefix), nil)
+	iter := db.store.NewIterator(tokenInitPrefix)
 	for iter.Next() {
 		value := make([]byte, len(iter.Value()))
 		copy(value, iter.Value())
@@ -77,7 +76,7 @@ func (db *db) NormalTokenTxs(tokenID common.Hash) ([]common.Hash, error) {
 	result := make([]common.Hash, 0)
 	key := addPrefixToKeyHash(string(tokenPrefix), tokenID)
 	// PubKey = token-{tokenID}
-	iter := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iter := db.store.NewIterator(key)
 	log.Println(string(key))
 	for iter.Next() {
 		value := iter.Value()
@@ -99,7 +98,7 @@ func (db *db) GetNormalTokenPaymentAddressesBalance(tokenID common.Hash) (map[st
 	prefix := TokenPaymentAddressPrefix
 	prefix = append(prefix, Splitter...)
 	prefix = append(prefix, []byte(tokenID.String())...)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(prefix), nil)
+	iter := db.store.NewIterator(prefix)
 	for iter.Next() {
 		key := string(iter.Key())
 		value := string(iter.Value())
@@ -142,7 +141,7 @@ func (db *db) GetNormalTokenPaymentAddressUTXO(tokenID common.Hash, paymentAddre
 	prefix = append(prefix, base58.Base58Check{}.Encode(paymentAddress, 0x00)...)
 	log.Println(hex.EncodeToString(prefix))
 	results := make(map[string]string)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(prefix), nil)
+	iter := db.store.NewIterator(prefix)
 	for iter.Next() {
 		key := string(iter.Key())
 		// token-paymentAddress  -[-]-  {tokenId}  -[-]-  {paymentAddress}  -[-]-  {txHash}  -[-]-  {voutIndex}
@@ -13,7 +13,6 @@ import (
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/database"
 	lvdberr "github.com/syndtr/goleveldb/leveldb/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 type PDEContribution struct {
@@ -30,6 +29,34 @@ type PDEPoolForPair struct {
 	Token2PoolValue uint64
 }
 
+// PDELimitOrder is a limit order resting in the order book of a pool pair
+type PDELimitOrder struct {
+	OrderID             string
+	TraderAddressStr    string
+	TokenIDToBuyStr     string
+	TokenIDToSellStr    string
+	SellAmount          uint64
+	MinAcceptableAmount uint64
+	TradingFee          uint64
+	ShardID             byte
+	TxReqID             common.Hash
+}
+
+// PDEPairStats is the activity of a pool pair in a beacon block, it is only stored
+// for blocks changing the pool pair and is used by analytics RPCs
+type PDEPairStats struct {
+	Token1IDStr     string
+	Token2IDStr     string
+	Token1PoolValue uint64 // reserves at the end of the block
+	Token2PoolValue uint64
+	TotalShares     uint64
+	Token1Volume    uint64 // amount of token 1 bought from the pool pair in the block
+	Token2Volume    uint64
+	Token1FeeAmount uint64 // protocol fees paid in token 1 in the block
+	Token2FeeAmount uint64
+	TradeCount      uint64
+}
+
 func BuildPDEStatusKey(
 	prefix []byte,
 	suffix []byte,
@@ -89,6 +116,32 @@ func BuildPDETradeFeesKey(
 	return append(pdeTradeFeesByBCHeightPrefix, []byte(tokenIDStrs[0]+"-"+tokenIDStrs[1]+"-"+tokenForFeeIDStr)...)
 }
 
+func BuildPDETradingFeeKey(
+	beaconHeight uint64,
+	token1IDStr string,
+	token2IDStr string,
+	tokenForFeeIDStr string,
+	contributorAddressStr string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	pdeTradingFeeByBCHeightPrefix := append(PDETradingFeePrefix, beaconHeightBytes...)
+	tokenIDStrs := []string{token1IDStr, token2IDStr}
+	sort.Strings(tokenIDStrs)
+	return append(pdeTradingFeeByBCHeightPrefix, []byte(tokenIDStrs[0]+"-"+tokenIDStrs[1]+"-"+tokenForFeeIDStr+"-"+contributorAddressStr)...)
+}
+
+func BuildPDEPairStatsKey(
+	beaconHeight uint64,
+	token1IDStr string,
+	token2IDStr string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	pdePairStatsByBCHeightPrefix := append(PDEPairStatsPrefix, beaconHeightBytes...)
+	tokenIDStrs := []string{token1IDStr, token2IDStr}
+	sort.Strings(tokenIDStrs)
+	return append(pdePairStatsByBCHeightPrefix, []byte(tokenIDStrs[0]+"-"+tokenIDStrs[1])...)
+}
+
 func BuildWaitingPDEContributionKey(
 	beaconHeight uint64,
 	pairID string,
@@ -98,6 +151,15 @@ func BuildWaitingPDEContributionKey(
 	return append(waitingPDEContribByBCHeightPrefix, []byte(pairID)...)
 }
 
+func BuildPDELimitOrderKey(
+	beaconHeight uint64,
+	orderID string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	pdeLimitOrderByBCHeightPrefix := append(PDELimitOrderPrefix, beaconHeightBytes...)
+	return append(pdeLimitOrderByBCHeightPrefix, []byte(orderID)...)
+}
+
 func (db *db) DeleteWaitingPDEContributionByPairID(
 	beaconHeight uint64,
 	pairID string,
@@ -142,7 +204,7 @@ func (db *db) ContributeToPDE(
 	contributedAmount uint64,
 ) error {
 	waitingContributionPairKey := BuildWaitingPDEContributionKey(beaconHeight, pairID)
-	waitingContributionBytes, err := db.lvdb.Get(waitingContributionPairKey, nil)
+	waitingContributionBytes, err := db.store.Get(waitingContributionPairKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetWaitingPDEContributionByPairIDError, err)
 	}
@@ -209,7 +271,7 @@ func (db *db) addShareAmountUp(
 	amt uint64,
 ) error {
 	pdeShareKey := BuildPDESharesKey(beaconHeight, token1IDStr, token2IDStr, contributedTokenIDStr, contributorAddrStr)
-	pdeShareBytes, err := db.lvdb.Get(pdeShareKey, nil)
+	pdeShareBytes, err := db.store.Get(pdeShareKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetPDEShareError, err)
 	}
@@ -236,7 +298,7 @@ func (db *db) GetSharesOfContributorForTokenIDOnAPair(
 	tokenIDStrs := []string{token1IDStr, token2IDStr}
 	sort.Strings(tokenIDStrs)
 	pdeShareKey := append(PDESharePrefix, []byte(tokenIDStrs[0]+"-"+tokenIDStrs[1]+"-"+contributedTokenIDStr+"-"+contributorAddrStr)...)
-	pdeShareBytes, err := db.lvdb.Get(pdeShareKey, nil)
+	pdeShareBytes, err := db.store.Get(pdeShareKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return 0, database.NewDatabaseError(database.GetPDEShareError, err)
 	}
@@ -256,7 +318,7 @@ func (db *db) GetTotalSharesForTokenIDOnAPair(
 	pdeShareForTokenIDPrefix := append(PDESharePrefix, []byte(tokenIDStrs[0]+"-"+tokenIDStrs[1]+"-"+contributedTokenIDStr)...)
 
 	totalShares := uint64(0)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(pdeShareForTokenIDPrefix), nil)
+	iter := db.store.NewIterator(pdeShareForTokenIDPrefix)
 	for iter.Next() {
 		value := iter.Value()
 		itemBytes := make([]byte, len(value))
@@ -304,7 +366,7 @@ func (db *db) updateWaitingContributionPairToPool(
 		return waitingContributions[i].TokenIDStr < waitingContributions[j].TokenIDStr
 	})
 	pdePoolForPairKey := BuildPDEPoolForPairKey(beaconHeight, waitingContributions[0].TokenIDStr, waitingContributions[1].TokenIDStr)
-	pdePoolForPairBytes, err := db.lvdb.Get(pdePoolForPairKey, nil)
+	pdePoolForPairBytes, err := db.store.Get(pdePoolForPairKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetPDEPoolForPairKeyError, err)
 	}
@@ -337,7 +399,7 @@ func (db *db) GetPDEPoolForPair(
 	tokenIDToSellStr string,
 ) ([]byte, error) {
 	pdePoolForPairKey := BuildPDEPoolForPairKey(beaconHeight, tokenIDToBuyStr, tokenIDToSellStr)
-	pdePoolForPairBytes, err := db.lvdb.Get(pdePoolForPairKey, nil)
+	pdePoolForPairBytes, err := db.store.Get(pdePoolForPairKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return []byte{}, database.NewDatabaseError(database.GetPDEPoolForPairKeyError, err)
 	}
@@ -348,10 +410,11 @@ func (db *db) GetLatestPDEPoolForPair(
 	tokenIDToBuyStr string,
 	tokenIDToSellStr string,
 ) ([]byte, error) {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(PDEPoolPrefix), nil)
+	iter := db.store.NewIterator(PDEPoolPrefix)
 	ok := iter.Last()
 	if !ok {
-		return []byte{}, nil
+		iter.Release()
+		return []byte{}, iter.Error()
 	}
 	key := iter.Key()
 	keyBytes := make([]byte, len(key))
@@ -372,7 +435,7 @@ func (db *db) GetLatestPDEPoolForPair(
 	}
 
 	pdePoolForPairKey := BuildPDEPoolForPairKey(beaconHeight, tokenIDToBuyStr, tokenIDToSellStr)
-	pdePoolForPairBytes, err := db.lvdb.Get(pdePoolForPairKey, nil)
+	pdePoolForPairBytes, err := db.store.Get(pdePoolForPairKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return []byte{}, database.NewDatabaseError(database.GetPDEPoolForPairKeyError, err)
 	}
@@ -401,7 +464,7 @@ func (db *db) AddTradeFeeUp(
 	amt uint64,
 ) error {
 	pdeTradeFeeKey := BuildPDETradeFeesKey(beaconHeight, token1IDStr, token2IDStr, targetingTokenIDStr)
-	pdeTradeFeeBytes, err := db.lvdb.Get(pdeTradeFeeKey, nil)
+	pdeTradeFeeBytes, err := db.store.Get(pdeTradeFeeKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetPDETradeFeeError, err)
 	}
@@ -428,7 +491,7 @@ func (db *db) DeductTradeFee(
 	amt uint64,
 ) error {
 	pdeTradeFeeKey := BuildPDETradeFeesKey(beaconHeight, token1IDStr, token2IDStr, targetingTokenIDStr)
-	pdeTradeFeeBytes, err := db.lvdb.Get(pdeTradeFeeKey, nil)
+	pdeTradeFeeBytes, err := db.store.Get(pdeTradeFeeKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetPDETradeFeeError, err)
 	}
@@ -457,7 +520,7 @@ func (db *db) DeductSharesForWithdrawal(
 	amt uint64,
 ) error {
 	pdeShareKey := BuildPDESharesKey(beaconHeight, token1IDStr, token2IDStr, targetingTokenIDStr, withdrawerAddressStr)
-	pdeShareBytes, err := db.lvdb.Get(pdeShareKey, nil)
+	pdeShareBytes, err := db.store.Get(pdeShareKey)
 	if err != nil && err != lvdberr.ErrNotFound {
 		return database.NewDatabaseError(database.GetPDEShareError, err)
 	}
@@ -482,7 +545,7 @@ func (db *db) GetAllRecordsByPrefix(beaconHeight uint64, prefix []byte) ([][]byt
 	values := [][]byte{}
 	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
 	prefixByBeaconHeight := append(prefix, beaconHeightBytes...)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(prefixByBeaconHeight), nil)
+	iter := db.store.NewIterator(prefixByBeaconHeight)
 	for iter.Next() {
 		key := iter.Key()
 		value := iter.Value()
@@ -519,7 +582,7 @@ func (db *db) GetPDEStatus(
 	suffix []byte,
 ) (byte, error) {
 	key := BuildPDEStatusKey(prefix, suffix)
-	pdeStatusBytes, dbErr := db.lvdb.Get(key, nil)
+	pdeStatusBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return common.PDENotFoundStatus, database.NewDatabaseError(database.GetPDEStatusError, dbErr)
 	}
@@ -547,7 +610,7 @@ func (db *db) GetPDEContributionStatus(
 	suffix []byte,
 ) ([]byte, error) {
 	key := BuildPDEStatusKey(prefix, suffix)
-	pdeStatusContentBytes, dbErr := db.lvdb.Get(key, nil)
+	pdeStatusContentBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return []byte{}, database.NewDatabaseError(database.GetPDEStatusError, dbErr)
 	}
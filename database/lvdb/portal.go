@@ -0,0 +1,148 @@
+package lvdb
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	lvdberr "github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+type CustodianState struct {
+	IncognitoAddress       string
+	TotalCollateral        uint64            // prv
+	FreeCollateral         uint64            // prv
+	HoldingPubTokens       map[string]uint64 // tokenID : amount
+	LockedAmountCollateral map[string]uint64 // tokenID : amount
+	RemoteAddresses        map[string]string // tokenID : remote address
+}
+
+type MatchingPortingCustodianDetail struct {
+	IncAddress             string
+	RemoteAddress          string
+	Amount                 uint64
+	LockedAmountCollateral uint64
+}
+
+type PortingRequest struct {
+	UniquePortingID string
+	TxReqID         common.Hash
+	TokenID         string
+	PorterAddress   string
+	Amount          uint64
+	Custodians      []*MatchingPortingCustodianDetail
+	PortingFee      uint64
+	ShardID         byte
+	BeaconHeight    uint64
+}
+
+type MatchingRedeemCustodianDetail struct {
+	IncAddress    string
+	RemoteAddress string
+	Amount        uint64
+	UnlockAmount  uint64 // collateral (prv) backing the redeemed amount
+}
+
+type RedeemRequest struct {
+	UniqueRedeemID        string
+	TxReqID               common.Hash
+	TokenID               string
+	RedeemerAddress       string
+	RedeemerRemoteAddress string
+	RedeemAmount          uint64
+	Custodians            []*MatchingRedeemCustodianDetail
+	ShardID               byte
+	BeaconHeight          uint64
+}
+
+// BTCHeaderState is a bitcoin block header relayed to portal
+type BTCHeaderState struct {
+	Header       string // serialized header, in hex
+	Height       uint64 // height of the block on bitcoin
+	ChainWork    string // total work of the chain up to this header, in decimal
+	BeaconHeight uint64 // beacon height the header was relayed at
+}
+
+func BuildCustodianStateKey(
+	beaconHeight uint64,
+	custodianIncAddrStr string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	custodianStateByBCHeightPrefix := append(PortalCustodianStatePrefix, beaconHeightBytes...)
+	return append(custodianStateByBCHeightPrefix, []byte(custodianIncAddrStr)...)
+}
+
+func BuildWaitingPortingRequestKey(
+	beaconHeight uint64,
+	uniquePortingID string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	waitingPortingReqByBCHeightPrefix := append(PortalWaitingPortingRequestsPrefix, beaconHeightBytes...)
+	return append(waitingPortingReqByBCHeightPrefix, []byte(uniquePortingID)...)
+}
+
+func BuildWaitingRedeemRequestKey(
+	beaconHeight uint64,
+	uniqueRedeemID string,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	waitingRedeemReqByBCHeightPrefix := append(PortalWaitingRedeemRequestsPrefix, beaconHeightBytes...)
+	return append(waitingRedeemReqByBCHeightPrefix, []byte(uniqueRedeemID)...)
+}
+
+func BuildPortalExternalTxKey(
+	tokenIDStr string,
+	externalTxID string,
+) []byte {
+	return append(PortalExternalTxPrefix, []byte(tokenIDStr+"-"+externalTxID)...)
+}
+
+// BuildPortalBTCHeaderKey builds key of a relayed btc header, headers are not kept by beacon height
+// since they never change once relayed
+func BuildPortalBTCHeaderKey(
+	blockHashStr string,
+) []byte {
+	return append(PortalBTCHeaderPrefix, []byte(blockHashStr)...)
+}
+
+func BuildPortalBTCHeaderChainTipKey(
+	beaconHeight uint64,
+) []byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	btcHeaderChainTipByBCHeightPrefix := append(PortalBTCHeaderChainTipPrefix, beaconHeightBytes...)
+	return append(btcHeaderChainTipByBCHeightPrefix, []byte("tip")...)
+}
+
+func BuildPortalStatusKey(
+	prefix []byte,
+	suffix []byte,
+) []byte {
+	return append(prefix, suffix...)
+}
+
+func (db *db) TrackPortalStatus(
+	prefix []byte,
+	suffix []byte,
+	statusContent []byte,
+) error {
+	key := BuildPortalStatusKey(prefix, suffix)
+	err := db.Put(key, statusContent)
+	if err != nil {
+		return database.NewDatabaseError(database.TrackPortalStatusError, errors.Wrap(err, "db.lvdb.put"))
+	}
+	return nil
+}
+
+func (db *db) GetPortalStatus(
+	prefix []byte,
+	suffix []byte,
+) ([]byte, error) {
+	key := BuildPortalStatusKey(prefix, suffix)
+	portalStatusContentBytes, dbErr := db.store.Get(key)
+	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
+		return []byte{}, database.NewDatabaseError(database.GetPortalStatusError, dbErr)
+	}
+	return portalStatusContentBytes, nil
+}
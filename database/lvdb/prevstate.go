@@ -8,7 +8,6 @@ import (
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/pkg/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 func getPrevPrefix(isBeacon bool, shardID byte) []byte {
@@ -31,7 +30,7 @@ func (db *db) StorePrevBestState(val []byte, isBeacon bool, shardID byte) error
 
 func (db *db) FetchPrevBestState(isBeacon bool, shardID byte) ([]byte, error) {
 	key := getPrevPrefix(isBeacon, shardID)
-	beststate, err := db.lvdb.Get(key, nil)
+	beststate, err := db.store.Get(key)
 	if err != nil {
 		return nil, database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.get"))
 	}
@@ -39,7 +38,7 @@ func (db *db) FetchPrevBestState(isBeacon bool, shardID byte) ([]byte, error) {
 }
 
 func (db *db) CleanBackup(isBeacon bool, shardID byte) error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(getPrevPrefix(isBeacon, shardID)), nil)
+	iter := db.store.NewIterator(getPrevPrefix(isBeacon, shardID))
 	for iter.Next() {
 		err := db.Delete(iter.Key())
 		if err != nil {
@@ -332,7 +331,7 @@ func (db *db) BackupBridgedTokenByTokenID(tokenID common.Hash) error {
 	key := append(centralizedBridgePrefix, tokenID[:]...)
 	backupKey := getPrevPrefix(true, 0)
 	backupKey = append(backupKey, key...)
-	tokenWithAmtBytes, dbErr := db.lvdb.Get(key, nil)
+	tokenWithAmtBytes, dbErr := db.store.Get(key)
 	if dbErr != nil {
 		if err := db.Put(backupKey, []byte{}); err != nil {
 			return err
@@ -367,7 +366,7 @@ func (db *db) BackupShardRewardRequest(epoch uint64, shardID byte, tokenID commo
 	backupKey := getPrevPrefix(true, 0)
 	key := newKeyAddShardRewardRequest(epoch, shardID, tokenID)
 	backupKey = append(backupKey, key...)
-	curValue, err := db.lvdb.Get(key, nil)
+	curValue, err := db.store.Get(key)
 	if err != nil {
 		err := db.Put(backupKey, common.Uint64ToBytes(0))
 		if err != nil {
@@ -386,7 +385,7 @@ func (db *db) BackupCommitteeReward(committeeAddress []byte, tokenID common.Hash
 	backupKey := getPrevPrefix(true, 0)
 	key := newKeyAddCommitteeReward(committeeAddress, tokenID)
 	backupKey = append(backupKey, key...)
-	curValue, err := db.lvdb.Get(key, nil)
+	curValue, err := db.store.Get(key)
 	if err != nil {
 		err := db.Put(backupKey, common.Uint64ToBytes(0))
 		if err != nil {
@@ -405,7 +404,7 @@ func (db *db) RestoreShardRewardRequest(epoch uint64, shardID byte, tokenID comm
 	backupKey := getPrevPrefix(true, 0)
 	key := newKeyAddShardRewardRequest(epoch, shardID, tokenID)
 	backupKey = append(backupKey, key...)
-	bakValue, err := db.lvdb.Get(backupKey, nil)
+	bakValue, err := db.store.Get(backupKey)
 	if err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, err)
 	}
@@ -420,7 +419,7 @@ func (db *db) RestoreCommitteeReward(committeeAddress []byte, tokenID common.Has
 	backupKey := getPrevPrefix(true, 0)
 	key := newKeyAddCommitteeReward(committeeAddress, tokenID)
 	backupKey = append(backupKey, key...)
-	bakValue, err := db.lvdb.Get(backupKey, nil)
+	bakValue, err := db.store.Get(backupKey)
 	if err != nil {
 		return database.NewDatabaseError(database.UnexpectedError, err)
 	}
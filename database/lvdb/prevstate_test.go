@@ -77,7 +77,7 @@ func Test_db_StorePrevBestState(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.StorePrevBestState(tt.args.val, tt.args.isBeacon, tt.args.shardID); (err != nil) != tt.wantErr {
 				t.Errorf("db.StorePrevBestState() error = %v, wantErr %v", err, tt.wantErr)
@@ -106,7 +106,7 @@ func Test_db_FetchPrevBestState(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			got, err := db.FetchPrevBestState(tt.args.isBeacon, tt.args.shardID)
 			if (err != nil) != tt.wantErr {
@@ -139,7 +139,7 @@ func Test_db_CleanBackup(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.CleanBackup(tt.args.isBeacon, tt.args.shardID); (err != nil) != tt.wantErr {
 				t.Errorf("db.CleanBackup() error = %v, wantErr %v", err, tt.wantErr)
@@ -168,7 +168,7 @@ func Test_db_BackupCommitmentsOfPubkey(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.BackupCommitmentsOfPubkey(tt.args.tokenID, tt.args.shardID, tt.args.pubkey); (err != nil) != tt.wantErr {
 				t.Errorf("db.BackupCommitmentsOfPubkey() error = %v, wantErr %v", err, tt.wantErr)
@@ -198,7 +198,7 @@ func Test_db_RestoreCommitmentsOfPubkey(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.RestoreCommitmentsOfPubkey(tt.args.tokenID, tt.args.shardID, tt.args.pubkey, tt.args.commitments); (err != nil) != tt.wantErr {
 				t.Errorf("db.RestoreCommitmentsOfPubkey() error = %v, wantErr %v", err, tt.wantErr)
@@ -228,7 +228,7 @@ func Test_db_DeleteOutputCoin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteOutputCoin(tt.args.tokenID, tt.args.publicKey, tt.args.outputCoinArr, tt.args.shardID); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteOutputCoin() error = %v, wantErr %v", err, tt.wantErr)
@@ -256,7 +256,7 @@ func Test_db_BackupSerialNumbersLen(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.BackupSerialNumbersLen(tt.args.tokenID, tt.args.shardID); (err != nil) != tt.wantErr {
 				t.Errorf("db.BackupSerialNumbersLen() error = %v, wantErr %v", err, tt.wantErr)
@@ -285,7 +285,7 @@ func Test_db_RestoreSerialNumber(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.RestoreSerialNumber(tt.args.tokenID, tt.args.shardID, tt.args.serialNumbers); (err != nil) != tt.wantErr {
 				t.Errorf("db.RestoreSerialNumber() error = %v, wantErr %v", err, tt.wantErr)
@@ -312,7 +312,7 @@ func Test_db_DeleteTransactionIndex(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteTransactionIndex(tt.args.txId); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteTransactionIndex() error = %v, wantErr %v", err, tt.wantErr)
@@ -339,7 +339,7 @@ func Test_db_DeleteCustomToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteNormalToken(tt.args.tokenID); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteCustomToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -369,7 +369,7 @@ func Test_db_DeleteCustomTokenTx(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteNormalTokenTx(tt.args.tokenID, tt.args.txIndex, tt.args.shardID, tt.args.blockHeight); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteCustomTokenTx() error = %v, wantErr %v", err, tt.wantErr)
@@ -396,7 +396,7 @@ func Test_db_DeletePrivacyCustomToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeletePrivacyToken(tt.args.tokenID); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeletePrivacyCustomToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -426,7 +426,7 @@ func Test_db_DeletePrivacyCustomTokenTx(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeletePrivacyTokenTx(tt.args.tokenID, tt.args.txIndex, tt.args.shardID, tt.args.blockHeight); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeletePrivacyCustomTokenTx() error = %v, wantErr %v", err, tt.wantErr)
@@ -453,7 +453,7 @@ func Test_db_DeletePrivacyCustomTokenCrossShard(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeletePrivacyTokenCrossShard(tt.args.tokenID); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeletePrivacyCustomTokenCrossShard() error = %v, wantErr %v", err, tt.wantErr)
@@ -482,7 +482,7 @@ func Test_db_RestoreCrossShardNextHeights(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.RestoreCrossShardNextHeights(tt.args.fromShard, tt.args.toShard, tt.args.curHeight); (err != nil) != tt.wantErr {
 				t.Errorf("db.RestoreCrossShardNextHeights() error = %v, wantErr %v", err, tt.wantErr)
@@ -509,7 +509,7 @@ func Test_db_DeleteCommitteeByEpoch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteCommitteeByHeight(tt.args.blkEpoch); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteCommitteeByEpoch() error = %v, wantErr %v", err, tt.wantErr)
@@ -537,7 +537,7 @@ func Test_db_DeleteAcceptedShardToBeacon(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteAcceptedShardToBeacon(tt.args.shardID, tt.args.shardBlkHash); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteAcceptedShardToBeacon() error = %v, wantErr %v", err, tt.wantErr)
@@ -566,7 +566,7 @@ func Test_db_DeleteIncomingCrossShard(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.DeleteIncomingCrossShard(tt.args.shardID, tt.args.crossShardID, tt.args.crossBlkHash); (err != nil) != tt.wantErr {
 				t.Errorf("db.DeleteIncomingCrossShard() error = %v, wantErr %v", err, tt.wantErr)
@@ -593,7 +593,7 @@ func Test_db_BackupBridgedTokenByTokenID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			db := &db{
-				lvdb: tt.fields.lvdb,
+				store: &levelDBStore{lvdb: tt.fields.lvdb},
 			}
 			if err := db.BackupBridgedTokenByTokenID(tt.args.tokenID); (err != nil) != tt.wantErr {
 				t.Errorf("db.BackupBridgedTokenByTokenID() error = %v, wantErr %v", err, tt.wantErr)
@@ -625,7 +625,7 @@ func Test_db_RestoreBridgedTokenByTokenID(t *testing.T) {
 				t.Errorf("db.RestoreBridgedTokenByTokenID() error = %v, wantErr %v", errDB, tt.wantErr)
 			}
 			db := &db{
-				lvdb: testDB,
+				store: &levelDBStore{lvdb: testDB},
 			}
 			if *runScenarioTests && (tt.name == "NotScenarioTest") {
 				t.Skip(tt.name)
@@ -670,7 +670,7 @@ func Test_db_BackupShardRewardRequest(t *testing.T) {
 				t.Errorf("db.BackupShardRewardRequest() error = %v, wantErr %v", errDB, tt.wantErr)
 			}
 			db := &db{
-				lvdb: testDB,
+				store: &levelDBStore{lvdb: testDB},
 			}
 			if *runScenarioTests && (tt.name == "NotScenarioTest") {
 				t.Skip(tt.name)
@@ -710,7 +710,7 @@ func Test_db_BackupCommitteeReward(t *testing.T) {
 				t.Errorf("db.BackupCommitteeReward() error = %v, wantErr %v", errDB, tt.wantErr)
 			}
 			db := &db{
-				lvdb: testDB,
+				store: &levelDBStore{lvdb: testDB},
 			}
 			if *runScenarioTests && (tt.name == "NotScenarioTest") {
 				t.Skip(tt.name)
@@ -751,7 +751,7 @@ func Test_db_RestoreShardRewardRequest(t *testing.T) {
 				t.Errorf("db.RestoreShardRewardRequest() error = %v, wantErr %v", errDB, tt.wantErr)
 			}
 			db := &db{
-				lvdb: testDB,
+				store: &levelDBStore{lvdb: testDB},
 			}
 			if *runScenarioTests && (tt.name == "NotScenarioTest") {
 				t.Skip(tt.name)
@@ -795,7 +795,7 @@ func Test_db_RestoreCommitteeReward(t *testing.T) {
 				t.Errorf("db.RestoreCommitteeReward() error = %v, wantErr %v", errDB, tt.wantErr)
 			}
 			db := &db{
-				lvdb: testDB,
+				store: &levelDBStore{lvdb: testDB},
 			}
 			if *runScenarioTests && (tt.name == "NotScenarioTest") {
 				t.Skip(tt.name)
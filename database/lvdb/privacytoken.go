@@ -8,7 +8,6 @@ import (
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/incognitochain/incognito-chain/databasemp"
 	"github.com/pkg/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // StorePrivacyCustomToken - store data about privacy custom token when init
@@ -71,7 +70,7 @@ func (db *db) PrivacyTokenIDCrossShardExisted(tokenID common.Hash) bool {
 */
 func (db *db) ListPrivacyToken() ([][]byte, error) {
 	result := make([][]byte, 0)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(privacyTokenInitPrefix), nil)
+	iter := db.store.NewIterator(privacyTokenInitPrefix)
 	for iter.Next() {
 		value := make([]byte, len(iter.Value()))
 		copy(value, iter.Value())
@@ -88,7 +87,7 @@ func (db *db) PrivacyTokenTxs(tokenID common.Hash) ([]common.Hash, error) {
 	result := make([]common.Hash, 0)
 	key := addPrefixToKeyHash(string(privacyTokenPrefix), tokenID)
 	// PubKey = token-{tokenID}
-	iter := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iter := db.store.NewIterator(key)
 	log.Println(string(key))
 	for iter.Next() {
 		value := iter.Value()
@@ -116,7 +115,7 @@ func (db *db) StorePrivacyTokenCrossShard(tokenID common.Hash, tokenValue []byte
 */
 func (db *db) ListPrivacyTokenCrossShard() ([][]byte, error) {
 	result := make([][]byte, 0)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(privacyTokenCrossShardPrefix), nil)
+	iter := db.store.NewIterator(privacyTokenCrossShardPrefix)
 	for iter.Next() {
 		value := make([]byte, len(iter.Value()))
 		copy(value, iter.Value())
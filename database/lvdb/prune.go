@@ -0,0 +1,58 @@
+package lvdb
+
+import (
+	"encoding/binary"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/pkg/errors"
+)
+
+func getPrunedHeightKey(isBeacon bool, shardID byte) []byte {
+	key := append([]byte{}, prunedHeightPrefix...)
+	if isBeacon {
+		return append(key, beaconPrefix...)
+	}
+	return append(append(key, shardPrefix...), shardID)
+}
+
+// StorePrunedHeight stores the highest block height of a chain whose body has been pruned
+// key: prunedheight-bea- or prunedheight-shd-{shardID}
+// value: {height}
+func (db *db) StorePrunedHeight(isBeacon bool, shardID byte, height uint64) error {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, height)
+	if err := db.Put(getPrunedHeightKey(isBeacon, shardID), buf); err != nil {
+		return database.NewDatabaseError(database.StorePrunedHeightError, err)
+	}
+	return nil
+}
+
+// FetchPrunedHeight returns 0 when no block of the chain has been pruned
+func (db *db) FetchPrunedHeight(isBeacon bool, shardID byte) (uint64, error) {
+	key := getPrunedHeightKey(isBeacon, shardID)
+	ok, err := db.HasValue(key)
+	if err != nil {
+		return 0, database.NewDatabaseError(database.FetchPrunedHeightError, err)
+	}
+	if !ok {
+		return 0, nil
+	}
+	buf, err := db.Get(key)
+	if err != nil {
+		return 0, database.NewDatabaseError(database.FetchPrunedHeightError, err)
+	}
+	if len(buf) != 8 {
+		return 0, database.NewDatabaseError(database.FetchPrunedHeightError, errors.Errorf("invalid pruned height %+v", buf))
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// PruneBlock deletes the body of a shard or beacon block (b-{hash}) and keeps its index records,
+// so the block can still be found by height and reported as pruned
+func (db *db) PruneBlock(hash common.Hash) error {
+	if err := db.Delete(addPrefixToKeyHash(string(blockKeyPrefix), hash)); err != nil {
+		return database.NewDatabaseError(database.PruneBlockError, err, hash.String())
+	}
+	return nil
+}
@@ -67,7 +67,7 @@ func (db *db) HasBlock(hash common.Hash) (bool, error) {
 	Query a block by hash. Return block if existence
 */
 func (db *db) FetchBlock(hash common.Hash) ([]byte, error) {
-	block, err := db.lvdb.Get(addPrefixToKeyHash(string(blockKeyPrefix), hash), nil)
+	block, err := db.store.Get(addPrefixToKeyHash(string(blockKeyPrefix), hash))
 	if err != nil {
 		if err == lvdberr.ErrNotFound {
 			return nil, database.NewDatabaseError(database.UnexpectedError, errors.Wrap(err, "db.lvdb.Get"))
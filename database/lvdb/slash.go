@@ -14,7 +14,7 @@ func (db *db) GetProducersBlackList(beaconHeight uint64) (map[string]uint8, erro
 	// key := producersBlackListPrefix
 	beaconHeightBytes := []byte(fmt.Sprintf("%d", beaconHeight))
 	key := append(producersBlackListPrefix, beaconHeightBytes...)
-	producersBlackListBytes, dbErr := db.lvdb.Get(key, nil)
+	producersBlackListBytes, dbErr := db.store.Get(key)
 	if dbErr != nil && dbErr != lvdberr.ErrNotFound {
 		return nil, database.NewDatabaseError(database.GetProducersBlackListError, dbErr)
 	}
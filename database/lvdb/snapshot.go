@@ -0,0 +1,85 @@
+package lvdb
+
+import "fmt"
+
+func joinPrefixes(prefixes ...[]byte) []byte {
+	res := []byte{}
+	for _, prefix := range prefixes {
+		res = append(res, prefix...)
+	}
+	return res
+}
+
+// StateSnapshotPrefixes returns the key prefixes of every record a state snapshot taken at beaconHeight must carry:
+// the cumulative serial number, commitment, output coin and SNDerivator sets, token, committee, reward, bridge and
+// cross shard records, PDE pair stats, relayed btc headers, plus the PDE and Portal records stored for beaconHeight itself.
+// Blocks, transaction indexes and previous-state backups are left out, a node started from a snapshot syncs forward only.
+// No returned prefix is a prefix of another one, so iterating all of them visits every record at most once.
+func StateSnapshotPrefixes(beaconHeight uint64) [][]byte {
+	beaconHeightBytes := []byte(fmt.Sprintf("%d-", beaconHeight))
+	prefixes := [][]byte{
+		// privacy
+		joinPrefixes(serialNumbersPrefix),
+		joinPrefixes(commitmentsPrefix),
+		joinPrefixes(outcoinsPrefix),
+		joinPrefixes(snderivatorsPrefix),
+		// tokens, includes init and payment address records
+		joinPrefixes(tokenPrefix),
+		joinPrefixes(privacyTokenPrefix),
+		joinPrefixes(privacyTokenCrossShardPrefix),
+		// committees, reward receivers and auto staking
+		joinPrefixes(beaconPrefix, committeePrefix),
+		joinPrefixes(beaconPrefix, shardIDPrefix, committeePrefix),
+		joinPrefixes(beaconPrefix, rewardReceiverPrefix),
+		joinPrefixes(beaconPrefix, autoStakingPrefix),
+		joinPrefixes(shardPrefix),
+		// epoch reward
+		joinPrefixes(shardRequestRewardPrefix),
+		joinPrefixes(committeeRewardPrefix),
+		// slash
+		joinPrefixes(producersBlackListPrefix),
+		// cross shard and shard to beacon
+		joinPrefixes(crossShardKeyPrefix),
+		joinPrefixes(nextCrossShardKeyPrefix),
+		joinPrefixes(shardToBeaconKeyPrefix),
+		// bridge
+		joinPrefixes(bridgePrefix),
+		joinPrefixes(centralizedBridgePrefix),
+		joinPrefixes(decentralizedBridgePrefix),
+		joinPrefixes(ethTxHashIssuedPrefix),
+		joinPrefixes(burnConfirmPrefix),
+		// PDE statuses
+		joinPrefixes(PDEContributionStatusPrefix),
+		joinPrefixes(PDETradeStatusPrefix),
+		joinPrefixes(PDEWithdrawalStatusPrefix),
+		joinPrefixes(PDELimitOrderStatusPrefix),
+		// PDE pair stats of every height, read by the analytics RPCs
+		joinPrefixes(PDEPairStatsPrefix),
+		// Portal statuses
+		joinPrefixes(PortalCustodianDepositStatusPrefix),
+		joinPrefixes(PortalPortingRequestStatusPrefix),
+		joinPrefixes(PortalReqPTokenStatusPrefix),
+		joinPrefixes(PortalRedeemRequestStatusPrefix),
+		joinPrefixes(PortalReqUnlockCollateralStatusPrefix),
+		joinPrefixes(PortalRelayingBTCHeaderStatusPrefix),
+		joinPrefixes(PortalExternalTxPrefix),
+		// relayed btc headers are stored once, not per beacon height
+		joinPrefixes(PortalBTCHeaderPrefix),
+	}
+	// records stored per beacon height
+	for _, prefix := range [][]byte{
+		WaitingPDEContributionPrefix,
+		PDEPoolPrefix,
+		PDESharePrefix,
+		PDETradeFeePrefix,
+		PDELimitOrderPrefix,
+		PDETradingFeePrefix,
+		PortalCustodianStatePrefix,
+		PortalWaitingPortingRequestsPrefix,
+		PortalWaitingRedeemRequestsPrefix,
+		PortalBTCHeaderChainTipPrefix,
+	} {
+		prefixes = append(prefixes, joinPrefixes(prefix, beaconHeightBytes))
+	}
+	return prefixes
+}
@@ -0,0 +1,84 @@
+package lvdb
+
+import (
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/syndtr/goleveldb/leveldb"
+	lvdberr "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ErrNotFound is returned by KeyValueStore.Get when the key does not exist,
+// every store must return this error so callers can tell a missing record from a failure
+var ErrNotFound = lvdberr.ErrNotFound
+
+// Iterator walks the records of a prefix in ascending key order.
+// Key and Value are only valid until the next call to Next, Last or Release.
+type Iterator interface {
+	Next() bool
+	Last() bool
+	Key() []byte
+	Value() []byte
+	Release()
+	Error() error
+}
+
+// KeyValueStore is the storage engine the key schema of this package is built on,
+// a database driver only has to implement it to provide the whole DatabaseInterface.
+type KeyValueStore interface {
+	Has(key []byte) (bool, error)
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// Write stores all records atomically
+	Write(data []database.BatchData) error
+	// NewIterator returns an iterator over the records whose key starts with prefix
+	NewIterator(prefix []byte) Iterator
+	// NewIteratorFrom returns an iterator over the records whose key starts with prefix and is not less than start
+	NewIteratorFrom(prefix []byte, start []byte) Iterator
+	Close() error
+}
+
+// NewDatabase returns a DatabaseInterface storing its records in store
+func NewDatabase(store KeyValueStore) database.DatabaseInterface {
+	return &db{store: store}
+}
+
+type levelDBStore struct {
+	lvdb *leveldb.DB
+}
+
+func (store *levelDBStore) Has(key []byte) (bool, error) {
+	return store.lvdb.Has(key, nil)
+}
+
+func (store *levelDBStore) Get(key []byte) ([]byte, error) {
+	return store.lvdb.Get(key, nil)
+}
+
+func (store *levelDBStore) Put(key, value []byte) error {
+	return store.lvdb.Put(key, value, nil)
+}
+
+func (store *levelDBStore) Delete(key []byte) error {
+	return store.lvdb.Delete(key, nil)
+}
+
+func (store *levelDBStore) Write(data []database.BatchData) error {
+	batch := new(leveldb.Batch)
+	for _, v := range data {
+		batch.Put(v.Key, v.Value)
+	}
+	return store.lvdb.Write(batch, nil)
+}
+
+func (store *levelDBStore) NewIterator(prefix []byte) Iterator {
+	return store.lvdb.NewIterator(util.BytesPrefix(prefix), nil)
+}
+
+func (store *levelDBStore) NewIteratorFrom(prefix []byte, start []byte) Iterator {
+	return store.lvdb.NewIterator(&util.Range{Start: start, Limit: util.BytesPrefix(prefix).Limit}, nil)
+}
+
+func (store *levelDBStore) Close() error {
+	return store.lvdb.Close()
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/incognitochain/incognito-chain/database"
 	"github.com/incognitochain/incognito-chain/privacy"
 	"github.com/pkg/errors"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // StoreSerialNumbers - store list serialNumbers by shardID
@@ -67,7 +66,8 @@ func (db *db) ListSerialNumber(tokenID common.Hash, shardID byte) (map[string]ui
 	key := addPrefixToKeyHash(string(serialNumbersPrefix), tokenID)
 	key = append(key, shardID)
 
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iterator := db.store.NewIterator(key)
+	defer iterator.Release()
 	for iterator.Next() {
 		key1 := make([]byte, len(iterator.Key()))
 		copy(key1, iterator.Key())
@@ -111,7 +111,7 @@ func (db *db) GetSerialNumbersLength(tokenID common.Hash, shardID byte) (*big.In
 
 // CleanSerialNumbers - clear all list serialNumber in DB
 func (db *db) CleanSerialNumbers() error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(serialNumbersPrefix), nil)
+	iter := db.store.NewIterator(serialNumbersPrefix)
 	for iter.Next() {
 		err := db.Delete(iter.Key())
 		if err != nil {
@@ -220,7 +220,8 @@ func (db *db) ListCommitment(tokenID common.Hash, shardID byte) (map[string]uint
 	key := addPrefixToKeyHash(string(commitmentsPrefix), tokenID)
 	key = append(key, shardID)
 
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iterator := db.store.NewIterator(key)
+	defer iterator.Release()
 	for iterator.Next() {
 		key1 := make([]byte, len(iterator.Key()))
 		copy(key1, iterator.Key())
@@ -247,7 +248,8 @@ func (db *db) ListCommitmentIndices(tokenID common.Hash, shardID byte) (map[uint
 	key := addPrefixToKeyHash(string(commitmentsPrefix), tokenID)
 	key = append(key, shardID)
 
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iterator := db.store.NewIterator(key)
+	defer iterator.Release()
 	for iterator.Next() {
 		key1 := make([]byte, len(iterator.Key()))
 		copy(key1, iterator.Key())
@@ -349,7 +351,7 @@ func (db *db) GetOutcoinsByPubkey(tokenID common.Hash, pubkey []byte, shardID by
 
 	key = append(key, pubkey...)
 	arrDatabyPubkey := make([][]byte, 0)
-	iter := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iter := db.store.NewIterator(key)
 	if iter.Error() != nil {
 		return nil, database.NewDatabaseError(database.GetOutputCoinByPublicKeyError, errors.Wrap(iter.Error(), "db.lvdb.NewIterator"))
 	}
@@ -364,7 +366,7 @@ func (db *db) GetOutcoinsByPubkey(tokenID common.Hash, pubkey []byte, shardID by
 
 // CleanCommitments - clear all list commitments in DB
 func (db *db) CleanCommitments() error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(commitmentsPrefix), nil)
+	iter := db.store.NewIterator(commitmentsPrefix)
 	for iter.Next() {
 		err := db.Delete(iter.Key())
 		if err != nil {
@@ -418,7 +420,8 @@ func (db *db) ListSNDerivator(tokenID common.Hash) ([][]byte, error) {
 	result := make([][]byte, 0)
 	key := addPrefixToKeyHash(string(snderivatorsPrefix), tokenID)
 
-	iterator := db.lvdb.NewIterator(util.BytesPrefix(key), nil)
+	iterator := db.store.NewIterator(key)
+	defer iterator.Release()
 	for iterator.Next() {
 		key1 := make([]byte, len(iterator.Key()))
 		copy(key1, iterator.Key())
@@ -431,7 +434,7 @@ func (db *db) ListSNDerivator(tokenID common.Hash) ([][]byte, error) {
 
 // CleanCommitments - clear all list commitments in DB
 func (db *db) CleanSNDerivator() error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(snderivatorsPrefix), nil)
+	iter := db.store.NewIterator(snderivatorsPrefix)
 	for iter.Next() {
 		err := db.Delete(iter.Key())
 		if err != nil {
@@ -464,7 +467,7 @@ func (db *db) GetFeeEstimator(shardID byte) ([]byte, error) {
 
 // CleanFeeEstimator - Clear FeeEstimator
 func (db *db) CleanFeeEstimator() error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(feeEstimatorPrefix), nil)
+	iter := db.store.NewIterator(feeEstimatorPrefix)
 	for iter.Next() {
 		err := db.Delete(iter.Key())
 		if err != nil {
@@ -544,7 +547,8 @@ func (db *db) StoreTxByPublicKey(publicKey []byte, txID common.Hash, shardID byt
 
 // GetTxByPublicKey -  from public key, use this function to get list all txID which someone send use by txID from any shardID
 func (db *db) GetTxByPublicKey(publicKey []byte) (map[byte][]common.Hash, error) {
-	itertor := db.lvdb.NewIterator(util.BytesPrefix(publicKey), nil)
+	itertor := db.store.NewIterator(publicKey)
+	defer itertor.Release()
 	result := make(map[byte][]common.Hash)
 	for itertor.Next() {
 		iKey := itertor.Key()
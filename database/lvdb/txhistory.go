@@ -0,0 +1,76 @@
+package lvdb
+
+import (
+	"encoding/binary"
+
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/pkg/errors"
+)
+
+// TxHistorySortKeyLength is the length of the sort keys built by BuildTxHistorySortKey
+const TxHistorySortKeyLength = 23
+
+// BuildTxHistorySortKey orders the history of a public key by beacon height first,
+// so transactions of all shards are listed in the order they were confirmed by the beacon chain
+// sort key: {beaconHeight}{shardID}{shardHeight}{txIndex}{entryIndex}
+func BuildTxHistorySortKey(beaconHeight uint64, shardID byte, shardHeight uint64, txIndex uint32, entryIndex uint16) []byte {
+	sortKey := make([]byte, TxHistorySortKeyLength)
+	binary.BigEndian.PutUint64(sortKey[0:8], beaconHeight)
+	sortKey[8] = shardID
+	binary.BigEndian.PutUint64(sortKey[9:17], shardHeight)
+	binary.BigEndian.PutUint32(sortKey[17:21], txIndex)
+	binary.BigEndian.PutUint16(sortKey[21:23], entryIndex)
+	return sortKey
+}
+
+// public keys are length prefixed, so the history of a key never includes records of a longer key
+func getTxHistoryPrefix(publicKey []byte) []byte {
+	key := append([]byte{}, txHistoryPrefix...)
+	key = append(key, byte(len(publicKey)))
+	return append(key, publicKey...)
+}
+
+// StoreTxHistory stores one history entry of a public key
+// key: txhistory-{len(publicKey)}{publicKey}{sortKey}
+// value: entry
+func (db *db) StoreTxHistory(publicKey []byte, sortKey []byte, value []byte, bd *[]database.BatchData) error {
+	key := append(getTxHistoryPrefix(publicKey), sortKey...)
+	if bd != nil {
+		*bd = append(*bd, database.BatchData{Key: key, Value: value})
+		return nil
+	}
+	if err := db.Put(key, value); err != nil {
+		return database.NewDatabaseError(database.StoreTxHistoryError, err)
+	}
+	return nil
+}
+
+func (db *db) DeleteTxHistory(publicKey []byte, sortKey []byte) error {
+	if err := db.Delete(append(getTxHistoryPrefix(publicKey), sortKey...)); err != nil {
+		return database.NewDatabaseError(database.DeleteTxHistoryError, err)
+	}
+	return nil
+}
+
+// ListTxHistory returns at most limit entries of a public key whose sort key is greater than afterSortKey,
+// together with their sort keys, in sort key order. An empty afterSortKey lists from the first entry.
+func (db *db) ListTxHistory(publicKey []byte, afterSortKey []byte, limit int) ([][]byte, [][]byte, error) {
+	prefix := getTxHistoryPrefix(publicKey)
+	start := prefix
+	if len(afterSortKey) > 0 {
+		// smallest key greater than the key of afterSortKey
+		start = append(append(append([]byte{}, prefix...), afterSortKey...), 0)
+	}
+	iter := db.store.NewIteratorFrom(prefix, start)
+	defer iter.Release()
+	sortKeys := [][]byte{}
+	values := [][]byte{}
+	for len(values) < limit && iter.Next() {
+		sortKeys = append(sortKeys, append([]byte{}, iter.Key()[len(prefix):]...))
+		values = append(values, append([]byte{}, iter.Value()...))
+	}
+	if err := iter.Error(); err != nil {
+		return nil, nil, database.NewDatabaseError(database.ListTxHistoryError, errors.Wrap(err, "db.lvdb.NewIterator"))
+	}
+	return sortKeys, values, nil
+}
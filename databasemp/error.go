@@ -20,6 +20,10 @@ const (
 	UnexpectedError
 	KeyExisted
 
+	// Journal err
+	InvalidJournalRecordErr
+	UnsupportedJournalVersionErr
+
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -40,6 +44,10 @@ var ErrCodeMessage = map[int]struct {
 	UnexpectedError:   {-3002, "Unexpected error"},
 	KeyExisted:        {-3003, "PubKey already existed in database"},
 	
+	// -4xxx journal
+	InvalidJournalRecordErr:      {-4000, "Journal record is invalid"},
+	UnsupportedJournalVersionErr: {-4001, "Journal record version is not supported"},
+	
 }
 
 type DatabaseMempoolError struct {
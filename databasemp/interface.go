@@ -17,5 +17,9 @@ type DatabaseInterface interface {
 	Reset() error
 	Load() ([][]byte, [][]byte, error)
 
+	AppendJournal(record *JournalRecord) error
+	LoadJournal() ([][]byte, error)
+	CompactJournal(records []*JournalRecord) error
+
 	Close() error
 }
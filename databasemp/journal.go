@@ -0,0 +1,133 @@
+package databasemp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/pkg/errors"
+)
+
+/*
+Journal of mempool persistence database:
+- Every change of mempool is appended as a record, records are never updated in place
+- A record is self-checked by a checksum, a corrupted record (e.g. written while node is crashed) is skipped alone
+- Record format: version(1) | op(1) | txHash(32) | len(txType)(2) | txType | len(tx)(4) | tx | len(desc)(4) | desc | crc32(4)
+- Compaction replaces all records by add records of txs which are still in mempool
+*/
+
+// JournalVersion - version of journal records written by this node
+const JournalVersion = byte(1)
+
+// journal record operations
+const (
+	JournalAddTx    = byte(1)
+	JournalRemoveTx = byte(2)
+)
+
+const journalRecordMinSize = 1 + 1 + common.HashSize + 2 + 4 + 4 + 4
+
+type JournalRecord struct {
+	Version byte
+	Op      byte
+	TxHash  common.Hash
+	TxType  string
+	Tx      []byte
+	Desc    []byte
+}
+
+// NewAddTxJournalRecord - record of a tx added into mempool
+func NewAddTxJournalRecord(txHash common.Hash, txType string, valueTx []byte, valueDesc []byte) *JournalRecord {
+	return &JournalRecord{
+		Version: JournalVersion,
+		Op:      JournalAddTx,
+		TxHash:  txHash,
+		TxType:  txType,
+		Tx:      valueTx,
+		Desc:    valueDesc,
+	}
+}
+
+// NewRemoveTxJournalRecord - record of a tx removed out of mempool
+func NewRemoveTxJournalRecord(txHash common.Hash) *JournalRecord {
+	return &JournalRecord{
+		Version: JournalVersion,
+		Op:      JournalRemoveTx,
+		TxHash:  txHash,
+	}
+}
+
+func (record JournalRecord) Bytes() []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(record.Version)
+	buf.WriteByte(record.Op)
+	buf.Write(record.TxHash[:])
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(record.TxType)))
+	buf.WriteString(record.TxType)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(record.Tx)))
+	buf.Write(record.Tx)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(record.Desc)))
+	buf.Write(record.Desc)
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes()
+}
+
+// ParseJournalRecord - decode a journal record, return error if it is corrupted or written by an unsupported version
+func ParseJournalRecord(data []byte) (*JournalRecord, error) {
+	if len(data) < journalRecordMinSize {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Errorf("record size %d is too small", len(data)))
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.New("checksum mismatch"))
+	}
+	record := &JournalRecord{
+		Version: body[0],
+		Op:      body[1],
+	}
+	if record.Version == 0 || record.Version > JournalVersion {
+		return nil, NewDatabaseMempoolError(UnsupportedJournalVersionErr, errors.Errorf("version %d", record.Version))
+	}
+	if record.Op != JournalAddTx && record.Op != JournalRemoveTx {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Errorf("unknown operation %d", record.Op))
+	}
+	copy(record.TxHash[:], body[2:2+common.HashSize])
+	reader := bytes.NewReader(body[2+common.HashSize:])
+	var typeLen uint16
+	if err := binary.Read(reader, binary.BigEndian, &typeLen); err != nil {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Wrap(err, "tx type length"))
+	}
+	txType := make([]byte, typeLen)
+	if _, err := io.ReadFull(reader, txType); err != nil {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Wrap(err, "tx type"))
+	}
+	record.TxType = string(txType)
+	var err error
+	if record.Tx, err = readBytes(reader); err != nil {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Wrap(err, "tx"))
+	}
+	if record.Desc, err = readBytes(reader); err != nil {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Wrap(err, "tx desc"))
+	}
+	if reader.Len() != 0 {
+		return nil, NewDatabaseMempoolError(InvalidJournalRecordErr, errors.Errorf("%d trailing bytes", reader.Len()))
+	}
+	return record, nil
+}
+
+func readBytes(reader *bytes.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if int64(length) > int64(reader.Len()) {
+		return nil, errors.Errorf("length %d is out of record", length)
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -1,10 +1,15 @@
 package lvdb
 
-import "github.com/incognitochain/incognito-chain/common"
+import (
+	"encoding/binary"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
 
 // prefix
 var (
-	txKeyPrefix = []byte("tx-")
+	txKeyPrefix      = []byte("tx-")
+	journalKeyPrefix = []byte("journal-")
 )
 
 // splitter
@@ -17,3 +22,10 @@ func getKey(key interface{}) []byte {
 	dbkey = append(txKeyPrefix, key.(*common.Hash)[:]...)
 	return dbkey
 }
+
+func getJournalKey(seq uint64) []byte {
+	key := make([]byte, len(journalKeyPrefix)+8)
+	copy(key, journalKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(journalKeyPrefix):], seq)
+	return key
+}
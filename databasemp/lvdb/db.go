@@ -1,13 +1,17 @@
 package lvdb
 
 import (
+	"sync"
+
 	"github.com/incognitochain/incognito-chain/databasemp"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
 type db struct {
-	lvdb *leveldb.DB
+	lvdb       *leveldb.DB
+	journalMtx sync.Mutex
+	journalSeq uint64 // sequence number of the last journal record
 }
 
 func open(dbPath string) (databasemp.DatabaseInterface, error) {
@@ -15,7 +19,11 @@ func open(dbPath string) (databasemp.DatabaseInterface, error) {
 	if err != nil {
 		return nil, databasemp.NewDatabaseMempoolError(databasemp.OpenDbErr, errors.Wrapf(err, "levelvdb.OpenFile %s", dbPath))
 	}
-	return &db{lvdb: lvdb}, nil
+	dbmp := &db{lvdb: lvdb}
+	if err := dbmp.loadJournalSeq(); err != nil {
+		return nil, err
+	}
+	return dbmp, nil
 }
 
 func (db *db) Close() error {
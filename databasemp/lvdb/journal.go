@@ -0,0 +1,87 @@
+package lvdb
+
+import (
+	"encoding/binary"
+
+	"github.com/incognitochain/incognito-chain/databasemp"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// journal records are synced to disk so an acknowledged record survives a crash
+var journalWriteOptions = &opt.WriteOptions{Sync: true}
+
+// loadJournalSeq - continue sequence number from the last journal record in database
+func (db *db) loadJournalSeq() error {
+	iter := db.lvdb.NewIterator(util.BytesPrefix(journalKeyPrefix), nil)
+	defer iter.Release()
+	if iter.Last() {
+		key := iter.Key()
+		if len(key) == len(journalKeyPrefix)+8 {
+			db.journalSeq = binary.BigEndian.Uint64(key[len(journalKeyPrefix):])
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "iter.Error"))
+	}
+	return nil
+}
+
+// Key: journal-{sequence number}
+// Value: record bytes
+func (db *db) AppendJournal(record *databasemp.JournalRecord) error {
+	db.journalMtx.Lock()
+	defer db.journalMtx.Unlock()
+	if err := db.lvdb.Put(getJournalKey(db.journalSeq+1), record.Bytes(), journalWriteOptions); err != nil {
+		return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "db.lvdb.Put"))
+	}
+	db.journalSeq++
+	return nil
+}
+
+// LoadJournal - return all journal records in order of appending
+func (db *db) LoadJournal() ([][]byte, error) {
+	db.journalMtx.Lock()
+	defer db.journalMtx.Unlock()
+	records := [][]byte{}
+	iter := db.lvdb.NewIterator(util.BytesPrefix(journalKeyPrefix), nil)
+	for iter.Next() {
+		value := iter.Value()
+		newValue := make([]byte, len(value))
+		copy(newValue, value)
+		records = append(records, newValue)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return records, databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "iter.Error"))
+	}
+	return records, nil
+}
+
+// CompactJournal - replace all journal records and transactions stored by older versions with these records.
+// All changes are written in one batch, so database has either old or new journal after a crash
+func (db *db) CompactJournal(records []*databasemp.JournalRecord) error {
+	db.journalMtx.Lock()
+	defer db.journalMtx.Unlock()
+	batch := new(leveldb.Batch)
+	for _, prefix := range [][]byte{journalKeyPrefix, txKeyPrefix} {
+		iter := db.lvdb.NewIterator(util.BytesPrefix(prefix), nil)
+		for iter.Next() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "iter.Error"))
+		}
+	}
+	for i, record := range records {
+		batch.Put(getJournalKey(uint64(i+1)), record.Bytes())
+	}
+	if err := db.lvdb.Write(batch, journalWriteOptions); err != nil {
+		return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "db.lvdb.Write"))
+	}
+	db.journalSeq = uint64(len(records))
+	return nil
+}
@@ -0,0 +1,79 @@
+package lvdb_test
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/databasemp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalRecord_Bytes(t *testing.T) {
+	record := databasemp.NewAddTxJournalRecord(common.HashH([]byte("tx")), common.TxNormalType, []byte("{}"), []byte("{\"Fee\":1}"))
+	data := record.Bytes()
+	parsedRecord, err := databasemp.ParseJournalRecord(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, record, parsedRecord)
+
+	removeRecord := databasemp.NewRemoveTxJournalRecord(common.HashH([]byte("tx")))
+	parsedRecord, err = databasemp.ParseJournalRecord(removeRecord.Bytes())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, databasemp.JournalRemoveTx, parsedRecord.Op)
+	assert.Equal(t, removeRecord.TxHash, parsedRecord.TxHash)
+
+	// corrupted record
+	data[len(data)/2] ^= 0xff
+	_, err = databasemp.ParseJournalRecord(data)
+	assert.NotEqual(t, nil, err)
+	_, err = databasemp.ParseJournalRecord(data[:10])
+	assert.NotEqual(t, nil, err)
+
+	// unsupported version
+	record.Version = databasemp.JournalVersion + 1
+	_, err = databasemp.ParseJournalRecord(record.Bytes())
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDb_Journal(t *testing.T) {
+	err := dbmp.CompactJournal(nil)
+	assert.Equal(t, nil, err)
+	txHash1 := common.HashH([]byte("tx1"))
+	txHash2 := common.HashH([]byte("tx2"))
+	err = dbmp.AppendJournal(databasemp.NewAddTxJournalRecord(txHash1, common.TxNormalType, []byte("tx1"), []byte("desc1")))
+	assert.Equal(t, nil, err)
+	err = dbmp.AppendJournal(databasemp.NewAddTxJournalRecord(txHash2, common.TxNormalType, []byte("tx2"), []byte("desc2")))
+	assert.Equal(t, nil, err)
+	err = dbmp.AppendJournal(databasemp.NewRemoveTxJournalRecord(txHash1))
+	assert.Equal(t, nil, err)
+
+	journal, err := dbmp.LoadJournal()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(journal))
+	for index, op := range []byte{databasemp.JournalAddTx, databasemp.JournalAddTx, databasemp.JournalRemoveTx} {
+		record, err := databasemp.ParseJournalRecord(journal[index])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, op, record.Op)
+	}
+
+	// compaction keeps only given records, appending continues after them
+	err = dbmp.CompactJournal([]*databasemp.JournalRecord{databasemp.NewAddTxJournalRecord(txHash2, common.TxNormalType, []byte("tx2"), []byte("desc2"))})
+	assert.Equal(t, nil, err)
+	err = dbmp.AppendJournal(databasemp.NewRemoveTxJournalRecord(txHash2))
+	assert.Equal(t, nil, err)
+	journal, err = dbmp.LoadJournal()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(journal))
+	record, err := databasemp.ParseJournalRecord(journal[0])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, txHash2, record.TxHash)
+	assert.Equal(t, []byte("tx2"), record.Tx)
+	record, err = databasemp.ParseJournalRecord(journal[1])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, databasemp.JournalRemoveTx, record.Op)
+
+	err = dbmp.Reset()
+	assert.Equal(t, nil, err)
+	journal, err = dbmp.LoadJournal()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(journal))
+}
@@ -46,19 +46,9 @@ func (db *db) HasTransaction(txHash *common.Hash) (bool, error) {
 	return ret, nil
 }
 
+// Reset - delete all transactions and journal records
 func (db *db) Reset() error {
-	iter := db.lvdb.NewIterator(util.BytesPrefix(txKeyPrefix), nil)
-	for iter.Next() {
-		err := db.Delete(iter.Key())
-		if err != nil {
-			return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "db.lvdb.Delete"))
-		}
-	}
-	iter.Release()
-	if err := iter.Error(); err != nil {
-		return databasemp.NewDatabaseMempoolError(databasemp.UnexpectedError, errors.Wrap(err, "iter.Error"))
-	}
-	return nil
+	return db.CompactJournal(nil)
 }
 
 func (db *db) Load() ([][]byte, [][]byte, error) {
@@ -10,12 +10,14 @@ import (
 	"runtime/debug"
 	"strconv"
 
+	"github.com/incognitochain/incognito-chain/blockchain"
 	"github.com/incognitochain/incognito-chain/common"
 
 	"net/http"
 	_ "net/http/pprof"
 
 	"github.com/incognitochain/incognito-chain/database"
+	_ "github.com/incognitochain/incognito-chain/database/badgerdb"
 	_ "github.com/incognitochain/incognito-chain/database/lvdb"
 	"github.com/incognitochain/incognito-chain/databasemp"
 	_ "github.com/incognitochain/incognito-chain/databasemp/lvdb"
@@ -23,6 +25,7 @@ import (
 	"github.com/incognitochain/incognito-chain/wallet"
 
 	_ "github.com/incognitochain/incognito-chain/consensus/blsbft"
+	_ "github.com/incognitochain/incognito-chain/consensus/hotstuff"
 )
 
 //go:generate mockery -dir=database/ -name=DatabaseInterface
@@ -59,13 +62,38 @@ func mainMaster(serverChan chan<- *Server) error {
 	if interruptRequested(interrupt) {
 		return nil
 	}
-	db, err := database.Open("leveldb", filepath.Join(cfg.DataDir, cfg.DatabaseDir))
+	db, err := database.Open(cfg.DatabaseType, filepath.Join(cfg.DataDir, cfg.DatabaseDir))
 	// Create db and use it.
 	if err != nil {
-		Logger.log.Error("could not open connection to leveldb")
+		Logger.log.Errorf("could not open connection to %+v", cfg.DatabaseType)
 		Logger.log.Error(err)
 		panic(err)
 	}
+	// Bootstrap chain state from snapshot, skipped when db already holds chain state (e.g. restart with the same flags)
+	if cfg.ImportSnapshot != "" {
+		if _, err := db.FetchBeaconBestState(); err == nil {
+			Logger.log.Warnf("Database already contains chain state, skip importing snapshot %+v", cfg.ImportSnapshot)
+		} else {
+			trustedChecksum, err := common.Hash{}.NewHashFromStr(cfg.SnapshotChecksum)
+			if err != nil {
+				Logger.log.Errorf("Invalid snapshotchecksum %+v, the checksum of a trusted snapshot is required to import it", cfg.SnapshotChecksum)
+				return err
+			}
+			snapshotFile, err := os.Open(cfg.ImportSnapshot)
+			if err != nil {
+				Logger.log.Error(err)
+				return err
+			}
+			header, err := blockchain.ImportStateSnapshot(db, snapshotFile, *trustedChecksum)
+			snapshotFile.Close()
+			if err != nil {
+				Logger.log.Errorf("Import state snapshot %+v failed", cfg.ImportSnapshot)
+				Logger.log.Error(err)
+				return err
+			}
+			Logger.log.Infof("Imported state snapshot at beacon height %+v, checksum %+v", header.BeaconHeight, header.Checksum.String())
+		}
+	}
 	// Create db for mempool and use it
 	dbmp, err := databasemp.Open("leveldbmempool", filepath.Join(cfg.DataDir, cfg.DatabaseMempoolDir))
 	if err != nil {
@@ -73,38 +101,50 @@ func mainMaster(serverChan chan<- *Server) error {
 		Logger.log.Error(err)
 		panic(err)
 	}
-	// Check wallet and start it
+	// Open the wallet keystore, the node wallet starts locked until the walletpassphrase RPC unlocks it
 	var walletObj *wallet.Wallet
+	var keystore *wallet.Keystore
 	if cfg.Wallet {
-		walletObj = &wallet.Wallet{}
-		walletConf := wallet.WalletConfig{
-			DataDir:        cfg.DataDir,
-			DataFile:       cfg.WalletName,
-			DataPath:       filepath.Join(cfg.DataDir, cfg.WalletName),
-			IncrementalFee: 0, // 0 mili PRV
-		}
-		if cfg.WalletShardID >= 0 {
-			// check shardID of wallet
-			temp := byte(cfg.WalletShardID)
-			walletConf.ShardID = &temp
-		}
-		walletObj.SetConfig(&walletConf)
-		err = walletObj.LoadWallet(cfg.WalletPassphrase)
+		keystore, err = wallet.NewKeystore(filepath.Join(cfg.DataDir, DefaultKeystoreDir), wallet.StandardScryptParams)
 		if err != nil {
-			if cfg.WalletAutoInit {
+			Logger.log.Error("could not open wallet keystore")
+			Logger.log.Error(err)
+			return err
+		}
+		walletObj = keystore.Wallet(cfg.WalletName)
+		if walletObj == nil {
+			legacyPath := filepath.Join(cfg.DataDir, cfg.WalletName)
+			if _, errStat := os.Stat(legacyPath); errStat == nil {
+				// move the wallet file of older versions into the keystore
+				walletObj, err = keystore.ImportLegacyWallet(cfg.WalletName, legacyPath, cfg.WalletPassphrase)
+				if err != nil {
+					Logger.log.Criticalf("Can not move wallet %s into the keystore", legacyPath)
+					return err
+				}
+				Logger.log.Infof("Wallet %s is moved into the keystore, the old file can be removed", legacyPath)
+			} else if cfg.WalletAutoInit {
 				Logger.log.Critical("\n **** Auto init wallet flag is TRUE ****\n")
-				walletObj.Init(cfg.WalletPassphrase, 0, cfg.WalletName)
-				walletObj.Save(cfg.WalletPassphrase)
+				walletObj, err = keystore.CreateWallet(cfg.WalletName, cfg.WalletPassphrase, 0)
+				if err != nil {
+					return err
+				}
 			} else {
 				// write log and exit when can not load wallet
-				Logger.log.Criticalf("Can not load wallet with %s. Please use incognitoctl to create a new wallet", walletObj.GetConfig().DataPath)
-				return err
+				Logger.log.Criticalf("Can not load wallet %s. Please use createwallet RPC or incognitoctl to create a new wallet", cfg.WalletName)
+				return wallet.NewWalletError(wallet.NotFoundWalletErr, nil)
 			}
 		}
+		walletObj.GetConfig().IncrementalFee = 0 // 0 mili PRV
+		if cfg.WalletShardID >= 0 {
+			// check shardID of wallet
+			temp := byte(cfg.WalletShardID)
+			walletObj.GetConfig().ShardID = &temp
+		}
 	}
 	// Create server and start it.
 	server := Server{}
 	server.wallet = walletObj
+	server.keystore = keystore
 	err = server.NewServer(cfg.Listener, db, dbmp, activeNetParams.Params, version, interrupt)
 	if err != nil {
 		Logger.log.Errorf("Unable to start server on %+v", cfg.Listener)
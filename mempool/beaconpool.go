@@ -58,22 +58,29 @@ func InitBeaconPool(pubsubManager *pubsub.PubSubManager) {
 // get singleton instance of ShardToBeacon pool
 func GetBeaconPool() *BeaconPool {
 	if beaconPool == nil {
-		beaconPool = new(BeaconPool)
-		beaconPool.latestValidHeight = 1
-		beaconPool.validPool = []*blockchain.BeaconBlock{}
-		beaconPool.pendingPool = make(map[uint64]*blockchain.BeaconBlock)
-		beaconPool.conflictedPool = make(map[common.Hash]*blockchain.BeaconBlock)
-		beaconPool.config = BeaconPoolConfig{
-			MaxValidBlock:   maxValidBeaconBlockInPool,
-			MaxPendingBlock: maxPendingBeaconBlockInPool,
-			CacheSize:       beaconCacheSize,
-		}
-		beaconPool.cache, _ = lru.New(beaconPool.config.CacheSize)
-		beaconPool.mtx = new(sync.RWMutex)
+		beaconPool = NewBeaconPool()
 	}
 	return beaconPool
 }
 
+// NewBeaconPool returns an empty beacon pool which is not the singleton instance,
+// simulations running several chains in a row use it to start each one with fresh pools
+func NewBeaconPool() *BeaconPool {
+	pool := new(BeaconPool)
+	pool.latestValidHeight = 1
+	pool.validPool = []*blockchain.BeaconBlock{}
+	pool.pendingPool = make(map[uint64]*blockchain.BeaconBlock)
+	pool.conflictedPool = make(map[common.Hash]*blockchain.BeaconBlock)
+	pool.config = BeaconPoolConfig{
+		MaxValidBlock:   maxValidBeaconBlockInPool,
+		MaxPendingBlock: maxPendingBeaconBlockInPool,
+		CacheSize:       beaconCacheSize,
+	}
+	pool.cache, _ = lru.New(pool.config.CacheSize)
+	pool.mtx = new(sync.RWMutex)
+	return pool
+}
+
 func (beaconPool *BeaconPool) Start(cQuit chan struct{}) {
 	for {
 		select {
@@ -201,7 +208,7 @@ func (beaconPool *BeaconPool) insertNewBeaconBlockToPool(block *blockchain.Beaco
 					beaconPool.updateLatestBeaconState()
 					return true
 				} else {
-					fmt.Println("BPool: block is fork at height %v with hash %v (block hash should be %v)", block.Header.Height, blockHeader, preHash)
+					Logger.log.Infof("BPool: block is fork at height %v with hash %v (block hash should be %v)", block.Header.Height, blockHeader, preHash)
 					delete(beaconPool.pendingPool, block.Header.Height)
 					beaconPool.cache.Add(block.Header.Hash(), block) // mark as wrong block for validating later
 					beaconPool.PubSubManager.PublishMessage(pubsub.NewMessage(pubsub.RequestBeaconBlockByHashTopic, preHash))
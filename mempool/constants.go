@@ -36,3 +36,19 @@ const (
 const (
 	maxPendingCrossShardInPool = 2000 //per shardID
 )
+
+// Replace by fee
+const (
+	maxReplacementConflicts = 100  // max number of txs in pool which a replacement tx may evict
+	maxReplacedTxRecords    = 1000 // max number of evicted txs remembered with their replacement tx
+)
+
+// Chained spends in pool
+const (
+	maxTxAncestors = 25 // max number of ancestors in pool of a tx
+)
+
+// Mempool persistence
+const (
+	journalCompactTime = 10 * time.Minute // period of compacting mempool database journal
+)
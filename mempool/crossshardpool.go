@@ -69,18 +69,25 @@ func InitCrossShardPool(pool map[byte]blockchain.CrossShardPool, db database.Dat
 func GetCrossShardPool(shardID byte) *CrossShardPool {
 	p, ok := crossShardPoolMap[shardID]
 	if ok == false {
-		p = new(CrossShardPool)
-		p.shardID = shardID
-		p.validPool = make(map[byte][]*blockchain.CrossShardBlock)
-		p.pendingPool = make(map[byte][]*blockchain.CrossShardBlock)
-		p.mtx = new(sync.RWMutex)
+		p = NewCrossShardPool(shardID, nil)
 		crossShardPoolMap[shardID] = p
-		p.isTest = false
-		p.confirmedHeight = make(map[heightPair][]uint64)
 	}
 	return p
 }
 
+// NewCrossShardPool returns an empty pool of the cross shard blocks sent to shardID which is not the singleton instance
+func NewCrossShardPool(shardID byte, db database.DatabaseInterface) *CrossShardPool {
+	p := new(CrossShardPool)
+	p.shardID = shardID
+	p.validPool = make(map[byte][]*blockchain.CrossShardBlock)
+	p.pendingPool = make(map[byte][]*blockchain.CrossShardBlock)
+	p.mtx = new(sync.RWMutex)
+	p.db = db
+	p.isTest = false
+	p.confirmedHeight = make(map[heightPair][]uint64)
+	return p
+}
+
 // Validate pending pool again, to move pending block to valid block
 
 // When receive new cross shard block or new beacon state arrive
@@ -45,6 +45,7 @@ const (
 	ValidateAggSignatureForCrossShardBlockError
 	DuplicateSerialNumbersHashError
 	CouldNotGetExchangeRateError
+	RejectTooManyAncestorsTx
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -87,6 +88,7 @@ var ErrCodeMessage = map[int]struct {
 	CouldNotGetExchangeRateError:                {-1032, "Could not get the exchange rate error"},
 	RejectSanityTxLocktime:                      {-1033, "Wrong tx locktime"},
 	RejectMetadataWithBlockchainTx:              {-1034, "Reject invalid metadata with blockchain"},
+	RejectTooManyAncestorsTx:                    {-1035, "Reject tx with too many ancestors in pool"},
 }
 
 type MempoolTxError struct {
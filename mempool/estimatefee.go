@@ -64,10 +64,12 @@ type observedTransaction struct {
 	// A transaction hash.
 	hash common.Hash
 
-	// The PRV fee per kilobyte of the transaction in coins.
+	// The fee per kilobyte of the transaction in PRV coins, token fee is
+	// converted to PRV by the PDE price when the transaction is observed.
 	feeRate CoinPerKilobyte
 
-	// The token fee per kilobyte of the transaction in coins.
+	// The token fee per kilobyte of the transaction in token coins, only
+	// for transactions paying fee in privacy token.
 	feeRateForToken map[common.Hash]CoinPerKilobyte
 
 	// The block height when it was observed.
@@ -81,7 +83,22 @@ type observedTransaction struct {
 func (o *observedTransaction) Serialize(w io.Writer) {
 	binary.Write(w, binary.BigEndian, o.hash)
 	binary.Write(w, binary.BigEndian, o.feeRate)
-	binary.Write(w, binary.BigEndian, o.feeRateForToken)
+
+	// Token fee rates are written as a count and pairs of token id and
+	// fee rate, sorted by token id so a serialized state always comes out the same.
+	tokenIDs := make([]common.Hash, 0, len(o.feeRateForToken))
+	for tokenID := range o.feeRateForToken {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool {
+		return strings.Compare(tokenIDs[i].String(), tokenIDs[j].String()) < 0
+	})
+	binary.Write(w, binary.BigEndian, uint32(len(tokenIDs)))
+	for _, tokenID := range tokenIDs {
+		binary.Write(w, binary.BigEndian, tokenID)
+		binary.Write(w, binary.BigEndian, o.feeRateForToken[tokenID])
+	}
+
 	binary.Write(w, binary.BigEndian, o.observed)
 	binary.Write(w, binary.BigEndian, o.mined)
 }
@@ -95,8 +112,24 @@ func deserializeObservedTransaction(r io.Reader) (*observedTransaction, error) {
 	// The next 8 are feeRate
 	binary.Read(r, binary.BigEndian, &ot.feeRate)
 
-	// The next 8 are feeRateForToken
-	binary.Read(r, binary.BigEndian, &ot.feeRateForToken)
+	// The next 4 are the number of token fee rates, followed by
+	// 32 bytes of token id and 8 bytes of fee rate for each one.
+	var numTokens uint32
+	err := binary.Read(r, binary.BigEndian, &numTokens)
+	if err != nil {
+		return nil, err
+	}
+	ot.feeRateForToken = make(map[common.Hash]CoinPerKilobyte)
+	for i := uint32(0); i < numTokens; i++ {
+		var tokenID common.Hash
+		var feeRate CoinPerKilobyte
+		binary.Read(r, binary.BigEndian, &tokenID)
+		err = binary.Read(r, binary.BigEndian, &feeRate)
+		if err != nil {
+			return nil, err
+		}
+		ot.feeRateForToken[tokenID] = feeRate
+	}
 
 	// And next there are two uint32's.
 	binary.Read(r, binary.BigEndian, &ot.observed)
@@ -150,6 +183,9 @@ type FeeEstimator struct {
 	// The cached estimates.
 	cached []CoinPerKilobyte
 
+	// The cached estimates for privacy tokens, in token and in PRV.
+	cachedForToken map[common.Hash]*tokenEstimates
+
 	// Transactions that have been removed from the bins. This allows us to
 	// revert in case of an orphaned block.
 	dropped []*registeredBlock
@@ -190,15 +226,21 @@ func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
 		size := t.Desc.Tx.GetTxActualSize()
 
 		feeRateForToken := make(map[common.Hash]CoinPerKilobyte)
-		if t.Desc.Tx.GetType() == common.TxCustomTokenPrivacyType {
+		if t.Desc.Tx.GetType() == common.TxCustomTokenPrivacyType && t.Desc.FeeToken > 0 {
 			tokenID := t.Desc.Tx.(*transaction.TxCustomTokenPrivacy).GetTokenID()
 			tokenFee := t.Desc.FeeToken
 			feeRateForToken[*tokenID] = NewCoinPerKilobyte(tokenFee, size)
 		}
 
+		// Fee rate of pool includes token fee converted to PRV.
+		feeRate := t.FeeRate
+		if feeRate == 0 {
+			feeRate = NewCoinPerKilobyte(uint64(t.Desc.Fee), size)
+		}
+
 		ef.observed[hash] = &observedTransaction{
 			hash:            hash,
-			feeRate:         NewCoinPerKilobyte(uint64(t.Desc.Fee), size),
+			feeRate:         feeRate,
 			feeRateForToken: feeRateForToken,
 			observed:        t.Desc.Height,
 			mined:           unminedHeight,
@@ -214,6 +256,7 @@ func (ef *FeeEstimator) RegisterBlock(block *blockchain.ShardBlock) error {
 
 	// The previous sorted list is invalid, so delete it.
 	ef.cached = nil
+	ef.cachedForToken = nil
 
 	height := block.Header.Height
 	if height != ef.lastKnownHeight+1 && ef.lastKnownHeight != unminedHeight {
@@ -372,6 +415,7 @@ func (ef *FeeEstimator) Rollback(hash *common.Hash) error {
 func (ef *FeeEstimator) rollback() {
 	// The previous sorted list is invalid, so delete it.
 	ef.cached = nil
+	ef.cachedForToken = nil
 
 	// pop the last list of dropped txs from the stack.
 	last := len(ef.dropped) - 1
@@ -457,9 +501,8 @@ func (ef *FeeEstimator) rollback() {
 // by the fee per kb rate.
 // inherit from golang sorter
 type estimateFeeSet struct {
-	feeRate         []CoinPerKilobyte
-	feeRateForToken map[common.Hash][]CoinPerKilobyte
-	bin             [estimateFeeDepth]uint32
+	feeRate []CoinPerKilobyte
+	bin     [estimateFeeDepth]uint32
 }
 
 func (b *estimateFeeSet) Len() int { return len(b.feeRate) }
@@ -506,135 +549,141 @@ func (b *estimateFeeSet) estimateFee(confirmations int) CoinPerKilobyte {
 	return b.feeRate[feeIndex]
 }
 
-// estimateFee returns the estimated fee for a transaction
-// to confirm in confirmations blocks from now, given
-// the data set we have collected.
-func (b *estimateFeeSet) estimateFeeForToken(confirmations int, tokenId *common.Hash) CoinPerKilobyte {
-	if confirmations <= 0 {
-		return CoinPerKilobyte(math.Inf(1))
-	}
-
-	if confirmations > estimateFeeDepth {
-		return 0
-	}
-
-	// We don't have any privacy token transactions!
-	if len(b.feeRateForToken[*tokenId]) == 0 {
-		return 0
-	}
-
-	// sort feeRateForToken
-
-	var min, max = 0, 0
-	for i := 0; i < confirmations-1; i++ {
-		min += int(b.bin[i])
-	}
-
-	max = min + int(b.bin[confirmations-1]) - 1
-	if max < min {
-		max = min
-	}
-	feeIndex := (min + max) / 2
-	if feeIndex >= len(b.feeRateForToken[*tokenId]) {
-		feeIndex = len(b.feeRateForToken[*tokenId]) - 1
-	}
-
-	return b.feeRateForToken[*tokenId][feeIndex]
-}
-
 // newEstimateFeeSet creates a temporary data structure that
 // can be used to find all fee estimates.
-func (ef *FeeEstimator) newEstimateFeeSet(tokenID *common.Hash) *estimateFeeSet {
+// If tokenID is not nil, only transactions paying fee in that token are
+// in the set, with their fee rate in token if inToken is true or in PRV otherwise.
+func (ef *FeeEstimator) newEstimateFeeSet(tokenID *common.Hash, inToken bool) *estimateFeeSet {
 	set := &estimateFeeSet{}
 
-	capacity := 0
+	set.feeRate = make([]CoinPerKilobyte, 0)
 	for i, b := range ef.bin {
-		l := len(b)
-		set.bin[i] = uint32(l)
-		capacity += l
-	}
-
-	set.feeRate = make([]CoinPerKilobyte, capacity)
-	set.feeRateForToken = make(map[common.Hash][]CoinPerKilobyte)
-
-	i := 0
-	for _, b := range ef.bin {
 		for _, o := range b {
-			set.feeRate[i] = o.feeRate
-
-			if tokenID != nil {
-				for key, value := range o.feeRateForToken {
-					if key.IsEqual(tokenID) {
-						set.feeRateForToken[key] = append(set.feeRateForToken[key], value)
-					}
-				}
+			if tokenID == nil {
+				set.feeRate = append(set.feeRate, o.feeRate)
+				set.bin[i]++
+				continue
 			}
-
-			i++
+			feeRateForToken, ok := o.feeRateForToken[*tokenID]
+			if !ok {
+				continue
+			}
+			if inToken {
+				set.feeRate = append(set.feeRate, feeRateForToken)
+			} else {
+				set.feeRate = append(set.feeRate, o.feeRate)
+			}
+			set.bin[i]++
 		}
 	}
 
-	if tokenID == nil {
-		sort.Sort(set)
-	} else {
-	}
+	sort.Sort(set)
 
 	return set
 }
 
 // estimates returns the set of all fee estimates from 1 to estimateFeeDepth
 // confirmations from now.
-func (ef *FeeEstimator) estimates(tokenID *common.Hash) []CoinPerKilobyte {
-	set := ef.newEstimateFeeSet(tokenID)
+func (ef *FeeEstimator) estimates(tokenID *common.Hash, inToken bool) []CoinPerKilobyte {
+	set := ef.newEstimateFeeSet(tokenID, inToken)
 
 	estimates := make([]CoinPerKilobyte, estimateFeeDepth)
 	for i := 0; i < estimateFeeDepth; i++ {
-		if tokenID != nil {
-			estimates[i] = set.estimateFeeForToken(i+1, tokenID)
-		} else {
-			estimates[i] = set.estimateFee(i + 1)
-		}
+		estimates[i] = set.estimateFee(i + 1)
 	}
 
 	return estimates
 }
 
-// EstimateFee estimates the fee per byte to have a tx confirmed a given
-// number of blocks from now.
-func (ef *FeeEstimator) EstimateFee(numBlocks uint64, tokenId *common.Hash) (CoinPerKilobyte, error) {
-	ef.mtx.Lock()
-	defer ef.mtx.Unlock()
+// tokenEstimates is the set of all fee estimates of a privacy token,
+// in the token and in PRV.
+type tokenEstimates struct {
+	inToken []CoinPerKilobyte
+	inPRV   []CoinPerKilobyte
+}
 
+// checkEstimate returns an error if the estimator can not estimate the fee
+// for a transaction to confirm in numBlocks blocks from now.
+func (ef *FeeEstimator) checkEstimate(numBlocks uint64) error {
 	// If the number of registered blocks is below the minimum, return
 	// an error.
 	if ef.numBlocksRegistered < ef.minRegisteredBlocks {
-		return 0, errors.New("not enough blocks have been observed")
+		return errors.New("not enough blocks have been observed")
 	}
 
 	if numBlocks == 0 {
-		return 0, errors.New("cannot confirm transaction in zero blocks")
+		return errors.New("cannot confirm transaction in zero blocks")
 	}
 
 	if numBlocks > estimateFeeDepth {
-		return 0, fmt.Errorf(
+		return fmt.Errorf(
 			"can only estimate fees for up to %d blocks from now",
 			estimateFeeBinSize)
 	}
+	return nil
+}
+
+// tokenEstimates returns the cached estimates of a privacy token,
+// generating them if there are none.
+func (ef *FeeEstimator) tokenEstimates(tokenID *common.Hash) *tokenEstimates {
+	if ef.cachedForToken == nil {
+		ef.cachedForToken = make(map[common.Hash]*tokenEstimates)
+	}
+	if _, ok := ef.cachedForToken[*tokenID]; !ok {
+		ef.cachedForToken[*tokenID] = &tokenEstimates{
+			inToken: ef.estimates(tokenID, true),
+			inPRV:   ef.estimates(tokenID, false),
+		}
+	}
+	return ef.cachedForToken[*tokenID]
+}
+
+// EstimateFee estimates the fee per byte to have a tx confirmed a given
+// number of blocks from now.
+func (ef *FeeEstimator) EstimateFee(numBlocks uint64, tokenId *common.Hash) (CoinPerKilobyte, error) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	err := ef.checkEstimate(numBlocks)
+	if err != nil {
+		return 0, err
+	}
+
+	if tokenId != nil {
+		return ef.tokenEstimates(tokenId).inToken[int(numBlocks)-1], nil
+	}
 
 	// If there are no cached results, generate them.
 	if ef.cached == nil {
-		ef.cached = ef.estimates(tokenId)
+		ef.cached = ef.estimates(nil, false)
 	}
 
 	result := ef.cached[int(numBlocks)-1]
 	return result, nil
 }
 
+// EstimateFeeForToken estimates the fee per kilobyte to have a transaction
+// paying fee in a privacy token confirmed a given number of blocks from now.
+// It returns the estimate in the token and its PRV equivalent, both are
+// zero if no transaction paying fee in the token has been observed.
+func (ef *FeeEstimator) EstimateFeeForToken(numBlocks uint64, tokenID *common.Hash) (CoinPerKilobyte, CoinPerKilobyte, error) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	err := ef.checkEstimate(numBlocks)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	estimates := ef.tokenEstimates(tokenID)
+	return estimates.inToken[int(numBlocks)-1], estimates.inPRV[int(numBlocks)-1], nil
+}
+
 // In case the format for the serialized version of the feeEstimator changes,
 // we use a version number. If the version number changes, it does not make
 // sense to try to upgrade a previous version to a new version. Instead, just
 // start fee estimation over.
-const estimateFeeSaveVersion = 1
+const estimateFeeSaveVersion = 2
 
 func deserializeRegisteredBlock(r io.Reader, txs map[uint32]*observedTransaction) (*registeredBlock, error) {
 	var lenTransactions uint32
@@ -0,0 +1,75 @@
+package mempool
+
+import (
+	"sort"
+
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+// feeRateIndex keeps txs in pool sorted by priority for block producing:
+// higher fee rate first, then the one entering pool earlier, then by tx hash.
+// The last tx of index is the first one to be evicted when pool is full.
+type feeRateIndex struct {
+	txDescs []*TxDesc
+}
+
+// before - return true if tx a has higher priority than tx b
+func (index *feeRateIndex) before(a *TxDesc, b *TxDesc) bool {
+	if a.FeeRate != b.FeeRate {
+		return a.FeeRate > b.FeeRate
+	}
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	return a.Desc.Tx.Hash().String() < b.Desc.Tx.Hash().String()
+}
+
+// search - return position of tx in index, or position to insert it
+func (index *feeRateIndex) search(txDesc *TxDesc) int {
+	return sort.Search(len(index.txDescs), func(i int) bool {
+		return !index.before(index.txDescs[i], txDesc)
+	})
+}
+
+func (index *feeRateIndex) add(txDesc *TxDesc) {
+	i := index.search(txDesc)
+	index.txDescs = append(index.txDescs, nil)
+	copy(index.txDescs[i+1:], index.txDescs[i:])
+	index.txDescs[i] = txDesc
+}
+
+func (index *feeRateIndex) remove(txDesc *TxDesc) {
+	i := index.search(txDesc)
+	if i < len(index.txDescs) && index.txDescs[i].Desc.Tx.Hash().IsEqual(txDesc.Desc.Tx.Hash()) {
+		index.txDescs = append(index.txDescs[:i], index.txDescs[i+1:]...)
+	}
+}
+
+// lowest - return txs with lowest priority which total size is at least size in kilobytes,
+// only txs with fee rate lower than feeRate and not in excluded are returned, false if they are not enough
+func (index *feeRateIndex) lowest(size uint64, feeRate CoinPerKilobyte, excluded map[common.Hash]struct{}) ([]*TxDesc, bool) {
+	result := []*TxDesc{}
+	freedSize := uint64(0)
+	for i := len(index.txDescs) - 1; i >= 0 && freedSize < size; i-- {
+		if index.txDescs[i].FeeRate >= feeRate {
+			break
+		}
+		if _, ok := excluded[*index.txDescs[i].Desc.Tx.Hash()]; ok {
+			continue
+		}
+		result = append(result, index.txDescs[i])
+		freedSize += index.txDescs[i].Desc.Tx.GetTxActualSize()
+	}
+	return result, freedSize >= size
+}
+
+// list - return all txs, from the highest priority
+func (index *feeRateIndex) list() []*TxDesc {
+	result := make([]*TxDesc, len(index.txDescs))
+	copy(result, index.txDescs)
+	return result
+}
+
+func (index *feeRateIndex) reset() {
+	index.txDescs = []*TxDesc{}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -40,6 +41,7 @@ type Config struct {
 	FeeEstimator      map[byte]*FeeEstimator // FeeEstimatator provides a feeEstimator. If it is not nil, the mempool records all new transactions it observes into the feeEstimator.
 	TxLifeTime        uint                   // Transaction life time in pool
 	MaxTx             uint64                 //Max transaction pool may have
+	MaxSize           uint64                 //Max total size of transactions pool may have in kilobytes, 0 is unlimited
 	IsLoadFromMempool bool                   //Reset mempool database when run node
 	PersistMempool    bool
 	RelayShards       []byte
@@ -53,6 +55,9 @@ type TxDesc struct {
 	Desc            metadata.TxDesc // transaction details
 	StartTime       time.Time       //Unix Time that transaction enter mempool
 	IsFowardMessage bool
+	Replaces        []common.Hash   // txs evicted from pool when this tx replaced them by fee
+	FeeRate         CoinPerKilobyte // fee per kilobyte in PRV, token fee is converted by PDE price
+	Parents         []common.Hash   // txs in pool which have outputs spent by this tx
 }
 
 type TxPool struct {
@@ -60,8 +65,12 @@ type TxPool struct {
 	config                    Config
 	lastUpdated               int64 // last time pool was updated
 	pool                      map[common.Hash]*TxDesc
+	poolFeeRate               feeRateIndex                  // txs in pool sorted by fee rate
+	poolSize                  uint64                        // total size of txs in pool in kilobytes
 	poolSerialNumbersHashList map[common.Hash][]common.Hash // [txHash] -> list hash serialNumbers of input coin
 	poolSerialNumberHash      map[common.Hash]common.Hash   // [hash from list of serialNumber] -> txHash
+	poolOutputCommitments     map[common.Hash]common.Hash   // [hash of tokenID and output commitment] -> txHash
+	poolChildren              map[common.Hash][]common.Hash // [txHash] -> txs in pool spending its outputs
 	mtx                       sync.RWMutex
 	poolCandidate             map[common.Hash]string //Candidate List in mempool
 	candidateMtx              sync.RWMutex
@@ -75,6 +84,11 @@ type TxPool struct {
 	IsBlockGenStarted         bool
 	IsUnlockMempool           bool
 	ReplaceFeeRatio           float64
+	replacedBy                map[common.Hash]common.Hash // [evicted txHash] -> txHash of replacement tx
+	replacedTxs               []common.Hash               // evicted txHash in order of replacement, bound replacedBy
+	journalTxs                map[common.Hash]struct{}    // txs which are added into mempool database journal
+	journalRecords            int                         // number of records in mempool database journal
+	restoreResults            []RestoreResult             // result of restoring txs from mempool database
 
 	//for testing
 	IsTest       bool
@@ -87,8 +101,12 @@ Init Txpool from config
 func (tp *TxPool) Init(cfg *Config) {
 	tp.config = *cfg
 	tp.pool = make(map[common.Hash]*TxDesc)
+	tp.poolFeeRate.reset()
+	tp.poolSize = 0
 	tp.poolSerialNumbersHashList = make(map[common.Hash][]common.Hash)
 	tp.poolSerialNumberHash = make(map[common.Hash]common.Hash)
+	tp.poolOutputCommitments = make(map[common.Hash]common.Hash)
+	tp.poolChildren = make(map[common.Hash][]common.Hash)
 	tp.poolCandidate = make(map[common.Hash]string)
 	tp.poolRequestStopStaking = make(map[common.Hash]string)
 	tp.duplicateTxs = make(map[common.Hash]uint64)
@@ -100,6 +118,10 @@ func (tp *TxPool) Init(cfg *Config) {
 	tp.RoleInCommittees = defaultRoleInCommittees
 	tp.IsTest = defaultIsTest
 	tp.ReplaceFeeRatio = defaultReplaceFeeRatio
+	tp.replacedBy = make(map[common.Hash]common.Hash)
+	tp.replacedTxs = []common.Hash{}
+	tp.journalTxs = make(map[common.Hash]struct{})
+	tp.restoreResults = []RestoreResult{}
 }
 
 // InitChannelMempool - init channel
@@ -118,6 +140,8 @@ func (tp *TxPool) AnnouncePersisDatabaseMempool() {
 
 // LoadOrResetDatabaseMempool - Load and reset database of mempool when start node
 func (tp *TxPool) LoadOrResetDatabaseMempool() error {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
 	if !tp.config.IsLoadFromMempool {
 		err := tp.resetDatabaseMempool()
 		if err != nil {
@@ -178,6 +202,7 @@ func (tp *TxPool) MonitorPool() {
 				txsToBeRemoved = append(txsToBeRemoved, txDesc)
 			}
 		}
+		txsToBeRemoved = tp.withDescendantDescs(txsToBeRemoved)
 		Logger.log.Infof("MonitorPool: End to collect timeout ttl tx - Count of txsToBeRemoved=%+v", len(txsToBeRemoved))
 		for _, txDesc := range txsToBeRemoved {
 			txHash := *txDesc.Desc.Tx.Hash()
@@ -186,10 +211,12 @@ func (tp *TxPool) MonitorPool() {
 			tp.TriggerCRemoveTxs(txDesc.Desc.Tx)
 			tp.removeCandidateByTxHash(txHash)
 			tp.removeRequestStopStakingByTxHash(txHash)
-			err := tp.config.DataBaseMempool.RemoveTransaction(txDesc.Desc.Tx.Hash())
-			if err != nil {
-				Logger.log.Errorf("MonitorPool: RemoveTransaction tx hash=%+v with error %+v", txDesc.Desc.Tx.Hash().String(), err)
-				Logger.log.Error(err)
+			if tp.config.PersistMempool {
+				err := tp.removeTransactionFromDatabaseMP(txDesc.Desc.Tx.Hash())
+				if err != nil {
+					Logger.log.Errorf("MonitorPool: RemoveTransaction tx hash=%+v with error %+v", txDesc.Desc.Tx.Hash().String(), err)
+					Logger.log.Error(err)
+				}
 			}
 			txSize := txDesc.Desc.Tx.GetTxActualSize()
 			go metrics.AnalyzeTimeSeriesMetricData(map[string]interface{}{
@@ -356,8 +383,9 @@ func (tp *TxPool) maybeAcceptTransaction(tx metadata.Transaction, isStore bool,
 	txFee := tx.GetTxFee()
 	txFeeToken := tx.GetTxFeeToken()
 	txD := createTxDescMempool(tx, bestHeight, txFee, txFeeToken)
+	txD.FeeRate = tp.calFeeRate(txD)
 	startAdd := time.Now()
-	err = tp.addTx(txD, isStore)
+	err = tp.acceptTxDesc(txD, isStore, isNewTransaction)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -372,6 +400,33 @@ func (tp *TxPool) maybeAcceptTransaction(tx metadata.Transaction, isStore bool,
 	return tx.Hash(), txD, nil
 }
 
+// acceptTxDesc - check pool policies of a validated tx then add it into pool,
+// txs in pool are only evicted when every check passes
+func (tp *TxPool) acceptTxDesc(txD *TxDesc, isStore bool, isNewTransaction bool) error {
+	tx := txD.Desc.Tx
+	ancestors := tp.findAncestors(tp.findParents(tx))
+	if len(ancestors) > maxTxAncestors {
+		return NewMempoolTxError(RejectTooManyAncestorsTx, fmt.Errorf("Tx %+v has %+v ancestors in pool, expect no more than %+v", tx.Hash().String(), len(ancestors), maxTxAncestors))
+	}
+	conflictTxs := tp.findConflictTxs(tx)
+	// txs for a new block are accepted in priority order, a tx double spending with one already accepted is left out
+	if !isNewTransaction && len(conflictTxs) > 0 {
+		return NewMempoolTxError(RejectDoubleSpendWithMempoolTx, fmt.Errorf("Tx %+v double spends with tx %+v for new block", tx.Hash().String(), conflictTxs[0].Desc.Tx.Hash().String()))
+	}
+	// make room for tx by evicting txs with lower fee rate when pool reaches max size
+	lowFeeRateTxs, err := tp.findLowFeeRateTxs(txD, conflictTxs, ancestors)
+	if err != nil {
+		return err
+	}
+	// tx is accepted, evict txs in pool which it replaces by fee
+	txD.Replaces = tp.evictReplacedTxs(tx)
+	for _, txDesc := range lowFeeRateTxs {
+		tp.evictTx(txDesc)
+		Logger.log.Infof("Tx %+v is evicted by tx %+v with higher fee rate \n", txDesc.Desc.Tx.Hash().String(), tx.Hash().String())
+	}
+	return tp.addTx(txD, isStore)
+}
+
 // createTxDescMempool - return an object TxDesc for mempool from original Tx
 func createTxDescMempool(tx metadata.Transaction, height uint64, fee uint64, feeToken uint64) *TxDesc {
 	txDesc := &TxDesc{
@@ -581,27 +636,27 @@ func (tp *TxPool) validateTransaction(tx metadata.Transaction, beaconHeight int6
 	})
 	if err != nil {
 		now := time.Now()
-		replaceErr, isReplacedTx := tp.validateTransactionReplacement(tx)
+		conflictTxs := tp.findConflictTxs(tx)
+		if len(conflictTxs) == 0 {
+			return NewMempoolTxError(RejectDoubleSpendWithMempoolTx, err)
+		}
+		replaceErr := tp.validateTransactionReplacement(tx, conflictTxs)
 		go metrics.AnalyzeTimeSeriesMetricData(map[string]interface{}{
 			metrics.Measurement:      metrics.TxPoolValidationDetails,
 			metrics.MeasurementValue: float64(time.Since(now).Seconds()),
 			metrics.TagValue:         metrics.ReplaceTxMetic,
 			metrics.Tag:              metrics.ValidateConditionTag,
 		})
-		// if replace tx success (no replace error found) then continue with next validate condition
-		if isReplacedTx {
-			if replaceErr != nil {
-				return replaceErr
-			}
-		} else {
-			// replace fail
-			return NewMempoolTxError(RejectDoubleSpendWithMempoolTx, err)
+		// a valid replacement continues with next validate condition,
+		// txs to be replaced are only evicted when tx is accepted into pool
+		if replaceErr != nil {
+			return replaceErr
 		}
 	}
 	// Condition 6: ValidateTransaction tx by it self
 	shardID = common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte())
 	now = time.Now()
-	validated, errValidateTxByItself := tx.ValidateTxByItself(tx.IsPrivacy(), tp.config.DataBase, tp.config.BlockChain, shardID)
+	validated, errValidateTxByItself := tx.ValidateTxByItself(tx.IsPrivacy(), tp.commitmentDatabase(), tp.config.BlockChain, shardID)
 	go metrics.AnalyzeTimeSeriesMetricData(map[string]interface{}{
 		metrics.Measurement:      metrics.TxPoolValidationDetails,
 		metrics.MeasurementValue: float64(time.Since(now).Seconds()),
@@ -695,67 +750,190 @@ func (tp *TxPool) isTxInPool(hash *common.Hash) bool {
 	return false
 }
 
-func (tp *TxPool) validateTransactionReplacement(tx metadata.Transaction) (error, bool) {
-	// calculate match serial number list in pool for replaced tx
-	serialNumberHashList := tx.ListSerialNumbersHashH()
-	hash := common.HashArrayOfHashArray(serialNumberHashList)
-	// find replace tx in pool
-	if txHashToBeReplaced, ok := tp.poolSerialNumberHash[hash]; ok {
-		if txDescToBeReplaced, ok := tp.pool[txHashToBeReplaced]; ok {
-			var baseReplaceFee float64
-			var baseReplaceFeeToken float64
-			var replaceFee float64
-			var replaceFeeToken float64
-			var isReplaced = false
-			if txDescToBeReplaced.Desc.Fee > 0 && txDescToBeReplaced.Desc.FeeToken == 0 {
-				// paid by prv fee only
-				baseReplaceFee = float64(txDescToBeReplaced.Desc.Fee)
-				replaceFee = float64(tx.GetTxFee())
-				// not a higher enough fee than return error
-				if baseReplaceFee*tp.ReplaceFeeRatio >= replaceFee {
-					return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be greater than %+v but get %+v ", baseReplaceFee, replaceFee)), true
-				}
-				isReplaced = true
-			} else if txDescToBeReplaced.Desc.Fee == 0 && txDescToBeReplaced.Desc.FeeToken > 0 {
-				//paid by token fee only
-				baseReplaceFeeToken = float64(txDescToBeReplaced.Desc.FeeToken)
-				replaceFeeToken = float64(tx.GetTxFeeToken())
-				// not a higher enough fee than return error
-				if baseReplaceFeeToken*tp.ReplaceFeeRatio >= replaceFeeToken {
-					return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be greater than %+v but get %+v ", baseReplaceFeeToken, replaceFeeToken)), true
-				}
-				isReplaced = true
-			} else if txDescToBeReplaced.Desc.Fee > 0 && txDescToBeReplaced.Desc.FeeToken > 0 {
-				// paid by both prv fee and token fee
-				// then only one of fee is higher then it will be accepted
-				baseReplaceFee = float64(txDescToBeReplaced.Desc.Fee)
-				replaceFee = float64(tx.GetTxFee())
-				baseReplaceFeeToken = float64(txDescToBeReplaced.Desc.FeeToken)
-				replaceFeeToken = float64(tx.GetTxFeeToken())
-				// not a higher enough fee than return error
-				if baseReplaceFee*tp.ReplaceFeeRatio >= replaceFee || baseReplaceFeeToken*tp.ReplaceFeeRatio >= replaceFeeToken {
-					return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be greater than %+v but get %+v ", baseReplaceFee, replaceFee)), true
+// findConflictTxs - return all txs in pool which spend at least one serial number of tx,
+// sorted by tx hash
+func (tp *TxPool) findConflictTxs(tx metadata.Transaction) []*TxDesc {
+	serialNumbers := make(map[common.Hash]struct{})
+	for _, serialNumberHash := range tx.ListSerialNumbersHashH() {
+		serialNumbers[serialNumberHash] = struct{}{}
+	}
+	conflictTxs := []*TxDesc{}
+	for txHash, serialNumberHashList := range tp.poolSerialNumbersHashList {
+		for _, serialNumberHash := range serialNumberHashList {
+			if _, ok := serialNumbers[serialNumberHash]; ok {
+				if txDesc, ok := tp.pool[txHash]; ok {
+					conflictTxs = append(conflictTxs, txDesc)
 				}
-				isReplaced = true
+				break
 			}
-			if isReplaced {
-				txToBeReplaced := txDescToBeReplaced.Desc.Tx
-				tp.removeTx(txToBeReplaced)
-				tp.TriggerCRemoveTxs(txToBeReplaced)
-				tp.removeRequestStopStakingByTxHash(*txToBeReplaced.Hash())
-				// send tx into channel of CRmoveTxs
-				tp.TriggerCRemoveTxs(tx)
-				return nil, true
-			} else {
-				return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Unexpected error occur")), true
+		}
+	}
+	// descendants of conflict txs are evicted with them
+	conflictTxs = tp.withDescendantDescs(conflictTxs)
+	sort.Slice(conflictTxs, func(i, j int) bool {
+		return conflictTxs[i].Desc.Tx.Hash().String() < conflictTxs[j].Desc.Tx.Hash().String()
+	})
+	return conflictTxs
+}
+
+/*
+validateTransactionReplacement - check replace by fee policy of tx against all txs it double spends with in pool
+1. Conflict set must not be larger than maxReplacementConflicts
+2. PRV fee must be greater than ReplaceFeeRatio * total PRV fee of conflict set
+3. PRV fee increment must pay for the replacement tx itself at the limit fee of pool
+4. Token fee paid by conflict set must still be paid in the same token,
+and greater than ReplaceFeeRatio * total token fee of conflict set
+*/
+func (tp *TxPool) validateTransactionReplacement(tx metadata.Transaction, conflictTxs []*TxDesc) error {
+	if len(conflictTxs) > maxReplacementConflicts {
+		return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Tx %+v replaces %+v txs, expect no more than %+v", tx.Hash().String(), len(conflictTxs), maxReplacementConflicts))
+	}
+	// a tx can not replace its own ancestors
+	for _, ancestor := range tp.findAncestors(tp.findParents(tx)) {
+		for _, txDesc := range conflictTxs {
+			if ancestor.Desc.Tx.Hash().IsEqual(txDesc.Desc.Tx.Hash()) {
+				return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Tx %+v spends outputs of tx %+v which it replaces", tx.Hash().String(), txDesc.Desc.Tx.Hash().String()))
 			}
+		}
+	}
+	baseReplaceFee := uint64(0)
+	baseReplaceFeeToken := make(map[common.Hash]uint64)
+	for _, txDesc := range conflictTxs {
+		baseReplaceFee += txDesc.Desc.Fee
+		if txDesc.Desc.FeeToken > 0 {
+			baseReplaceFeeToken[*txDesc.Desc.Tx.GetTokenID()] += txDesc.Desc.FeeToken
+		}
+	}
+	// paid by prv fee
+	if baseReplaceFee > 0 {
+		replaceFee := tx.GetTxFee()
+		// not a higher enough fee than return error
+		if float64(baseReplaceFee)*tp.ReplaceFeeRatio >= float64(replaceFee) {
+			return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be greater than %+v but get %+v ", float64(baseReplaceFee)*tp.ReplaceFeeRatio, replaceFee))
+		}
+		minFeeBump := tp.minReplacementFeeBump(tx)
+		if replaceFee-baseReplaceFee < minFeeBump {
+			return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be increased by at least %+v but get %+v ", minFeeBump, replaceFee-baseReplaceFee))
+		}
+	}
+	// paid by token fee
+	for tokenID, baseFeeToken := range baseReplaceFeeToken {
+		if !tx.GetTokenID().IsEqual(&tokenID) {
+			return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee to be paid in token %+v but get token %+v ", tokenID.String(), tx.GetTokenID().String()))
+		}
+		replaceFeeToken := tx.GetTxFeeToken()
+		// not a higher enough fee than return error
+		if float64(baseFeeToken)*tp.ReplaceFeeRatio >= float64(replaceFeeToken) {
+			return NewMempoolTxError(RejectReplacementTxError, fmt.Errorf("Expect fee token to be greater than %+v but get %+v ", float64(baseFeeToken)*tp.ReplaceFeeRatio, replaceFeeToken))
+		}
+	}
+	return nil
+}
+
+// minReplacementFeeBump - minimum PRV fee increment of a replacement tx,
+// which is the limit fee of pool for the size of replacement tx
+func (tp *TxPool) minReplacementFeeBump(tx metadata.Transaction) uint64 {
+	shardID := common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte())
+	if feeEstimator, ok := tp.config.FeeEstimator[shardID]; ok {
+		return feeEstimator.GetLimitFeeForNativeToken() * tx.GetTxActualSize()
+	}
+	return 0
+}
+
+// evictReplacedTxs - remove all txs in pool which double spend with a validated replacement tx,
+// return hash of evicted txs
+func (tp *TxPool) evictReplacedTxs(tx metadata.Transaction) []common.Hash {
+	replacedTxs := []common.Hash{}
+	for _, txDesc := range tp.findConflictTxs(tx) {
+		txHash := *txDesc.Desc.Tx.Hash()
+		tp.evictTx(txDesc)
+		tp.recordReplacement(txHash, *tx.Hash())
+		replacedTxs = append(replacedTxs, txHash)
+		Logger.log.Infof("Tx %+v is replaced by tx %+v \n", txHash.String(), tx.Hash().String())
+	}
+	return replacedTxs
+}
+
+// evictTx - remove a valid tx out of pool, mempool database and block generator
+func (tp *TxPool) evictTx(txDesc *TxDesc) {
+	tx := txDesc.Desc.Tx
+	txHash := *tx.Hash()
+	if _, ok := tp.pool[txHash]; !ok {
+		return
+	}
+	// outputs of tx are spent by its children which are no longer valid without it
+	for _, childHash := range append([]common.Hash{}, tp.poolChildren[txHash]...) {
+		if childDesc, ok := tp.pool[childHash]; ok {
+			tp.evictTx(childDesc)
+		}
+	}
+	if tp.config.PersistMempool {
+		err := tp.removeTransactionFromDatabaseMP(&txHash)
+		if err != nil {
+			Logger.log.Error(err)
+		}
+	}
+	tp.removeTx(tx)
+	tp.TriggerCRemoveTxs(tx)
+	tp.removeCandidateByTxHash(txHash)
+	tp.removeRequestStopStakingByTxHash(txHash)
+}
+
+// calFeeRate - return fee per kilobyte of tx in PRV,
+// token fee is converted to PRV by the latest PDE price and not counted if there is no PDE price
+func (tp *TxPool) calFeeRate(txD *TxDesc) CoinPerKilobyte {
+	fee := txD.Desc.Fee
+	if txD.Desc.FeeToken > 0 {
+		feeTokenToNativeToken, err := metadata.ConvertPrivacyTokenToNativeToken(txD.Desc.FeeToken, txD.Desc.Tx.GetTokenID(), -1, tp.config.DataBase)
+		if err != nil {
+			Logger.log.Debugf("Can not convert fee token of tx %+v to PRV, error %+v \n", txD.Desc.Tx.Hash().String(), err)
 		} else {
-			//found no tx to be replaced
-			return nil, false
+			fee += uint64(math.Ceil(feeTokenToNativeToken))
 		}
-	} else {
-		// no match serial number list to be replaced
-		return nil, false
+	}
+	size := txD.Desc.Tx.GetTxActualSize()
+	if size == 0 {
+		return CoinPerKilobyte(fee)
+	}
+	return NewCoinPerKilobyte(fee, size)
+}
+
+// findLowFeeRateTxs - return txs with lowest fee rate which must be evicted to make room for tx when pool reaches max size,
+// space of conflict txs replaced by tx is freed first, ancestors of tx are never evicted because tx spends their outputs,
+// tx is rejected if txs with lower fee rate are not enough
+func (tp *TxPool) findLowFeeRateTxs(txD *TxDesc, conflictTxs []*TxDesc, ancestors []*TxDesc) ([]*TxDesc, error) {
+	if tp.config.MaxSize == 0 {
+		return nil, nil
+	}
+	poolSize := tp.poolSize
+	excluded := make(map[common.Hash]struct{})
+	for _, txDesc := range conflictTxs {
+		excluded[*txDesc.Desc.Tx.Hash()] = struct{}{}
+		poolSize -= txDesc.Desc.Tx.GetTxActualSize()
+	}
+	for _, txDesc := range ancestors {
+		excluded[*txDesc.Desc.Tx.Hash()] = struct{}{}
+	}
+	txSize := txD.Desc.Tx.GetTxActualSize()
+	if poolSize+txSize <= tp.config.MaxSize {
+		return nil, nil
+	}
+	lowFeeRateTxs, ok := tp.poolFeeRate.lowest(poolSize+txSize-tp.config.MaxSize, txD.FeeRate, excluded)
+	if !ok {
+		return nil, NewMempoolTxError(MaxPoolSizeError, fmt.Errorf("Pool reach max size %+v KB, tx %+v fee rate %+v is too low", tp.config.MaxSize, txD.Desc.Tx.Hash().String(), txD.FeeRate))
+	}
+	return lowFeeRateTxs, nil
+}
+
+// recordReplacement - remember which tx replaced an evicted tx, keep at most maxReplacedTxRecords records
+func (tp *TxPool) recordReplacement(replacedTxHash common.Hash, txHash common.Hash) {
+	if _, ok := tp.replacedBy[replacedTxHash]; !ok {
+		tp.replacedTxs = append(tp.replacedTxs, replacedTxHash)
+	}
+	tp.replacedBy[replacedTxHash] = txHash
+	if len(tp.replacedTxs) > maxReplacedTxRecords {
+		delete(tp.replacedBy, tp.replacedTxs[0])
+		tp.replacedTxs = tp.replacedTxs[1:]
 	}
 }
 
@@ -786,6 +964,12 @@ func (tp *TxPool) addTx(txD *TxDesc, isStore bool) error {
 		}
 	}
 	tp.pool[*txHash] = txD
+	if txD.FeeRate == 0 {
+		txD.FeeRate = tp.calFeeRate(txD)
+	}
+	tp.poolFeeRate.add(txD)
+	tp.poolSize += tx.GetTxActualSize()
+	tp.addTxRelations(txD)
 	var serialNumberList []common.Hash
 	serialNumberList = append(serialNumberList, txD.Desc.Tx.ListSerialNumbersHashH()...)
 	serialNumberListHash := common.HashArrayOfHashArray(serialNumberList)
@@ -869,6 +1053,10 @@ func (tp *TxPool) checkPublicKeyRole(tx metadata.Transaction) bool {
 func (tp *TxPool) RemoveTx(txs []metadata.Transaction, isInBlock bool) {
 	tp.mtx.Lock()
 	defer tp.mtx.Unlock()
+	// descendants of txs which are not in a new block are no longer valid
+	if !isInBlock {
+		txs = tp.withDescendants(txs)
+	}
 	// remove transaction from database mempool
 	for _, tx := range txs {
 		var now time.Time
@@ -962,7 +1150,7 @@ func (tp *TxPool) RemoveTx(txs []metadata.Transaction, isInBlock bool) {
 func (tp *TxPool) removeTx(tx metadata.Transaction) {
 	//Logger.log.Infof((*tx).Hash().String())
 	if _, exists := tp.pool[*tx.Hash()]; exists {
-		delete(tp.pool, *tx.Hash())
+		tp.deleteTxDesc(*tx.Hash())
 		atomic.StoreInt64(&tp.lastUpdated, time.Now().Unix())
 	}
 	if _, exists := tp.poolSerialNumbersHashList[*tx.Hash()]; exists {
@@ -975,7 +1163,7 @@ func (tp *TxPool) removeTx(tx metadata.Transaction) {
 		// Using the same list serial number to delete new transaction out of pool
 		// this new transaction maybe not exist
 		if _, exists := tp.pool[hash]; exists {
-			delete(tp.pool, hash)
+			tp.deleteTxDesc(hash)
 			atomic.StoreInt64(&tp.lastUpdated, time.Now().Unix())
 		}
 		if _, exists := tp.poolSerialNumbersHashList[hash]; exists {
@@ -984,6 +1172,15 @@ func (tp *TxPool) removeTx(tx metadata.Transaction) {
 	}
 }
 
+// deleteTxDesc - delete tx description out of pool and fee rate index
+func (tp *TxPool) deleteTxDesc(txHash common.Hash) {
+	txDesc := tp.pool[txHash]
+	tp.removeTxRelations(txDesc)
+	delete(tp.pool, txHash)
+	tp.poolFeeRate.remove(txDesc)
+	tp.poolSize -= txDesc.Desc.Tx.GetTxActualSize()
+}
+
 func (tp *TxPool) addCandidateToList(txHash common.Hash, candidate string) {
 	tp.candidateMtx.Lock()
 	defer tp.candidateMtx.Unlock()
@@ -1082,13 +1279,34 @@ func (tp *TxPool) GetTx(txHash *common.Hash) (metadata.Transaction, error) {
 	return nil, err
 }
 
+// GetReplacedTxs - return hash of txs which were evicted from pool when tx in pool replaced them by fee
+func (tp *TxPool) GetReplacedTxs(txHash *common.Hash) []common.Hash {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+	txDesc, exists := tp.pool[*txHash]
+	if !exists {
+		return nil
+	}
+	replacedTxs := make([]common.Hash, len(txDesc.Replaces))
+	copy(replacedTxs, txDesc.Replaces)
+	return replacedTxs
+}
+
+// GetReplacedBy - return hash of tx which replaced an evicted tx by fee
+func (tp *TxPool) GetReplacedBy(txHash *common.Hash) (common.Hash, bool) {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+	replacedBy, ok := tp.replacedBy[*txHash]
+	return replacedBy, ok
+}
+
 // // MiningDescs returns a slice of mining descriptors for all the transactions
 // // in the pool.
 func (tp *TxPool) MiningDescs() []*metadata.TxDesc {
 	tp.mtx.Lock()
 	defer tp.mtx.Unlock()
 	descs := []*metadata.TxDesc{}
-	for _, desc := range tp.pool {
+	for _, desc := range tp.listPackages() {
 		descs = append(descs, &desc.Desc)
 	}
 	return descs
@@ -1128,11 +1346,7 @@ Sum of all transactions sizes
 func (tp *TxPool) Size() uint64 {
 	tp.mtx.RLock()
 	defer tp.mtx.RUnlock()
-	size := uint64(0)
-	for _, tx := range tp.pool {
-		size += tx.Desc.Tx.GetTxActualSize()
-	}
-	return size
+	return tp.poolSize
 }
 
 // Get Max fee
@@ -1224,8 +1438,12 @@ func (tp *TxPool) EmptyPool() bool {
 		return true
 	}
 	tp.pool = make(map[common.Hash]*TxDesc)
+	tp.poolFeeRate.reset()
+	tp.poolSize = 0
 	tp.poolSerialNumbersHashList = make(map[common.Hash][]common.Hash)
 	tp.poolSerialNumberHash = make(map[common.Hash]common.Hash)
+	tp.poolOutputCommitments = make(map[common.Hash]common.Hash)
+	tp.poolChildren = make(map[common.Hash][]common.Hash)
 	tp.poolCandidate = make(map[common.Hash]string)
 	tp.poolRequestStopStaking = make(map[common.Hash]string)
 	if len(tp.pool) == 0 && len(tp.poolSerialNumbersHashList) == 0 && len(tp.poolSerialNumberHash) == 0 && len(tp.poolCandidate) == 0 && len(tp.poolRequestStopStaking) == 0 {
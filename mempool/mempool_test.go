@@ -12,6 +12,7 @@ import (
 	"github.com/incognitochain/incognito-chain/memcache"
 	"github.com/incognitochain/incognito-chain/metadata"
 	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/privacy/zeroknowledge"
 	"github.com/incognitochain/incognito-chain/pubsub"
 	"github.com/incognitochain/incognito-chain/transaction"
 	"github.com/incognitochain/incognito-chain/wallet"
@@ -183,10 +184,16 @@ var _ = func() (_ struct{}) {
 
 func ResetMempoolTest() {
 	tp.pool = make(map[common.Hash]*TxDesc)
+	tp.poolFeeRate.reset()
+	tp.poolSize = 0
 	tp.poolSerialNumbersHashList = make(map[common.Hash][]common.Hash)
 	tp.poolSerialNumberHash = make(map[common.Hash]common.Hash)
-	tp.poolTokenID = make(map[common.Hash]string)
+	tp.poolOutputCommitments = make(map[common.Hash]common.Hash)
+	tp.poolChildren = make(map[common.Hash][]common.Hash)
 	tp.poolCandidate = make(map[common.Hash]string)
+	tp.poolRequestStopStaking = make(map[common.Hash]string)
+	tp.replacedBy = make(map[common.Hash]common.Hash)
+	tp.replacedTxs = []common.Hash{}
 	tp.duplicateTxs = make(map[common.Hash]uint64)
 	tp.RoleInCommittees = -1
 	tp.IsBlockGenStarted = false
@@ -198,6 +205,12 @@ func ResetMempoolTest() {
 	tp.CRemoveTxs = cRemoveTxs
 	tp.config.DataBaseMempool.Reset()
 }
+
+// hasTxInDatabaseMempool - tx is added into mempool database journal and not removed from it
+func hasTxInDatabaseMempool(txHash *common.Hash) (bool, error) {
+	_, err := tp.getTransactionFromDatabaseMempool(txHash)
+	return err == nil, nil
+}
 func initTx(amount string, privateKey string, db database.DatabaseInterface) []metadata.Transaction {
 	var initTxs []metadata.Transaction
 	var initAmount, _ = strconv.Atoi(amount) // amount init
@@ -355,9 +368,9 @@ func CreateAndSaveTestStakingTransaction(privateKey string, privateSeed string,
 
 	receiversPaymentAddressStrParam := make(map[string]interface{})
 	if isBeacon {
-		receiversPaymentAddressStrParam[common.BurningAddress] = tp.config.ChainParams.StakingAmountShard * 3
+		receiversPaymentAddressStrParam[tp.config.BlockChain.GetBurningAddress(0)] = tp.config.ChainParams.StakingAmountShard * 3
 	} else {
-		receiversPaymentAddressStrParam[common.BurningAddress] = tp.config.ChainParams.StakingAmountShard
+		receiversPaymentAddressStrParam[tp.config.BlockChain.GetBurningAddress(0)] = tp.config.ChainParams.StakingAmountShard
 	}
 	paymentInfos := make([]*privacy.PaymentInfo, 0)
 	for paymentAddressStr, amount := range receiversPaymentAddressStrParam {
@@ -747,7 +760,7 @@ func TestTxPoolAddTx(t *testing.T) {
 	txDesc1 := createTxDescMempool(tx1, 1, 10, 0)
 	txDesc2 := createTxDescMempool(tx2, 1, 10, 0)
 	txDesc3 := createTxDescMempool(tx3, 1, 10, 0)
-	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[3], commonFee, defaultTokenParams, false)
+	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[3], commonFee, defaultTokenParams, false)
 	//fmt.Println(txInitCustomToken.)
 	txStakingShard := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, false)
 	txStakingBeacon := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, true)
@@ -781,12 +794,6 @@ func TestTxPoolAddTx(t *testing.T) {
 	if len(tp.poolCandidate) != 1 {
 		t.Fatalf("Expect 1 but get %+v", len(tp.poolCandidate))
 	}
-	if common.IndexOfStrInHashMap(normalTokenID, tp.poolTokenID) < 0 {
-		t.Fatalf("Expect %+v in pool but get %+v", tokenID, tp.poolTokenID)
-	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
 	ResetMempoolTest()
 	tp.addTx(txDesc1, true)
 	tp.addTx(txDesc2, true)
@@ -812,31 +819,25 @@ func TestTxPoolAddTx(t *testing.T) {
 	if len(tp.poolCandidate) != 1 {
 		t.Fatalf("Expect 1 but get %+v", len(tp.poolCandidate))
 	}
-	if common.IndexOfStrInHashMap(normalTokenID, tp.poolTokenID) < 0 {
-		t.Fatalf("Expect %+v in pool but get %+v", stakingPublicKey, tp.poolCandidate)
-	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx1.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx1.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx1.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx2.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx2.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx2.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx3.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx3.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx3.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx6.Hash()); !isOk && err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx6.Hash()); !isOk && err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx6.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txInitCustomToken.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(txInitCustomToken.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", txInitCustomToken.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txStakingBeacon.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(txStakingBeacon.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", txStakingBeacon.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txStakingShard.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(txStakingShard.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", txStakingShard.Hash())
 	}
 }
@@ -859,7 +860,6 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	salaryTx := initTx("100", privateKeyShard0[0], db)
 	tx1 := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], commonFee, false, maxAmount)
 	tx1Replace := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], higherFee, false, maxAmount)
-	tx1DoubleSpend := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], commonFee, false, int(sum)-maxAmount)
 	// get sender key set from private key
 	tx1ReplaceFailed := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], lowerFee, false, maxAmount)
 	txInitCustomTokenPrivacy := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[0], commonFee, defaultTokenParams, false)
@@ -869,8 +869,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	tx3 := CreateAndSaveTestNormalTransaction(privateKeyShard0[2], commonFee, false, normalTranferAmount)
 	tx4 := CreateAndSaveTestNormalTransaction(privateKeyShard0[3], noFee, false, normalTranferAmount)
 	tx5 := CreateAndSaveTestNormalTransaction(privateKeyShard0[4], commonFee, false, normalTranferAmount)
-	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[3], commonFee, defaultTokenParams, false)
-	txInitCustomTokenFailed := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[4], commonFee, defaultTokenParams, false)
+	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[3], commonFee, defaultTokenParams, false)
 	txStakingShard := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, false)
 	//txStakingBeacon := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, true)
 	txDesc1 := createTxDescMempool(tx1, 1, tx1.GetTxFee(), tx1.GetTxFeeToken())
@@ -878,7 +877,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	// Check condition 1: Sanity - Max version error
 	ResetMempoolTest()
 	tx1.(*transaction.Tx).Version = 2
-	err1 := tp.validateTransaction(tx1)
+	err1 := tp.validateTransaction(tx1, -1)
 	if err1 == nil {
 		t.Fatal("Expect max version error error but no error")
 	} else {
@@ -891,7 +890,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	ResetMempoolTest()
 	common.MaxTxSize = 0
 	common.MaxBlockSize = 2000
-	err2 := tp.validateTransaction(tx2)
+	err2 := tp.validateTransaction(tx2, -1)
 	if err2 == nil {
 		t.Fatal("Expect size error error but no error")
 	} else {
@@ -904,7 +903,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	// Check Condition 1: Sanity Validate type
 	ResetMempoolTest()
 	tx3.(*transaction.Tx).Type = "abc"
-	err3 := tp.validateTransaction(tx3)
+	err3 := tp.validateTransaction(tx3, -1)
 	if err3 == nil {
 		t.Fatal("Expect type error error but no error")
 	} else {
@@ -917,7 +916,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	ResetMempoolTest()
 	tempLockTime := tx4.(*transaction.Tx).LockTime
 	tx4.(*transaction.Tx).LockTime = time.Now().Unix() + 1000000
-	err4 := tp.validateTransaction(tx4)
+	err4 := tp.validateTransaction(tx4, -1)
 	if err4 == nil {
 		t.Fatal("Expect type error error but no error")
 	} else {
@@ -933,7 +932,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 		tempByte = append(tempByte, byte(i))
 	}
 	tx4.(*transaction.Tx).Info = tempByte
-	err5 := tp.validateTransaction(tx4)
+	err5 := tp.validateTransaction(tx4, -1)
 	if err5 == nil {
 		t.Fatal("Expect type error error but no error")
 	} else {
@@ -945,7 +944,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	// Check condition 2: tx exist in pool
 	tp.pool[*tx1.Hash()] = txDesc1
 	tp.poolSerialNumbersHashList[*tx1.Hash()] = tx1.ListSerialNumbersHashH()
-	err6 := tp.validateTransaction(tx1)
+	err6 := tp.validateTransaction(tx1, -1)
 	if err6 == nil {
 		t.Fatal("Expect reject duplicate error but no error")
 	} else {
@@ -955,7 +954,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	}
 	// Check Condition 3: Salary Transaction
 	ResetMempoolTest()
-	err7 := tp.validateTransaction(salaryTx[0])
+	err7 := tp.validateTransaction(salaryTx[0], -1)
 	if err7 == nil {
 		t.Fatal("Expect salary error error but no error")
 	} else {
@@ -965,7 +964,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	}
 	// Check Condition 4: Validate fee
 	ResetMempoolTest()
-	err8 := tp.validateTransaction(tx4)
+	err8 := tp.validateTransaction(tx4, -1)
 	if err8 == nil {
 		t.Fatal("Expect fee error error but no error")
 	} else {
@@ -977,14 +976,14 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	// Check Condition 5: replace (normal tx)
 	ResetMempoolTest()
 	tp.addTx(txDesc1, false)
-	err9 := tp.validateTransaction(tx1Replace)
+	err9 := tp.validateTransaction(tx1Replace, -1)
 	if err9 != nil {
 		t.Fatal("Expect no error error but get ", err9)
 	}
 	// Check Condition 5: Check replace with mempool (normal tx)
 	ResetMempoolTest()
 	tp.addTx(txDesc1, false)
-	err91 := tp.validateTransaction(tx1ReplaceFailed)
+	err91 := tp.validateTransaction(tx1ReplaceFailed, -1)
 	if err91 == nil {
 		t.Fatal("Expect replace fail error in mempool error error but no error")
 	} else {
@@ -995,14 +994,14 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	// Check Condition 5: replace (custom token privacy tx)
 	ResetMempoolTest()
 	tp.addTx(txDesc1CustomTokenPrivacy, false)
-	err92 := tp.validateTransaction(txInitCustomTokenPrivacyReplace)
+	err92 := tp.validateTransaction(txInitCustomTokenPrivacyReplace, -1)
 	if err92 != nil {
 		t.Fatal("Expect no error error but get ", err92)
 	}
 	// Check Condition 5: Check replace with mempool (custom token privacy tx)
 	ResetMempoolTest()
 	tp.addTx(txDesc1CustomTokenPrivacy, false)
-	err93 := tp.validateTransaction(txInitCustomTokenPrivacyReplaceFailed)
+	err93 := tp.validateTransaction(txInitCustomTokenPrivacyReplaceFailed, -1)
 	if err93 == nil {
 		t.Fatal("Expect replace fail error in mempool error error but no error")
 	} else {
@@ -1010,19 +1009,18 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 			t.Fatalf("Expect Error %+v but get %+v", ErrCodeMessage[RejectReplacementTxError], err93)
 		}
 	}
-	// Check Condition 5: Check double spend with mempool
+	// Check Condition 5: Check double spend with mempool, a double spend which does not pay more fee is not a replacement
 	ResetMempoolTest()
-	tp.addTx(txDesc1, false)
+	tp.addTx(createTxDescMempool(tx1Replace, 1, tx1Replace.GetTxFee(), tx1Replace.GetTxFeeToken()), false)
 	log.Println(tx1.ListSerialNumbersHashH())
 	log.Println(tx1Replace.ListSerialNumbersHashH())
 	log.Println(tx1ReplaceFailed.ListSerialNumbersHashH())
-	log.Println(tx1DoubleSpend.ListSerialNumbersHashH())
-	err10 := tp.validateTransaction(tx1DoubleSpend)
+	err10 := tp.validateTransaction(tx1, -1)
 	if err10 == nil {
 		t.Fatal("Expect double spend error in mempool error error but no error")
 	} else {
-		if err10.(*MempoolTxError).Code != ErrCodeMessage[RejectDoubleSpendWithMempoolTx].Code {
-			t.Fatalf("Expect Error %+v but get %+v", ErrCodeMessage[RejectDoubleSpendWithMempoolTx], err10)
+		if err10.(*MempoolTxError).Code != ErrCodeMessage[RejectReplacementTxError].Code {
+			t.Fatalf("Expect Error %+v but get %+v", ErrCodeMessage[RejectReplacementTxError], err10)
 		}
 	}
 	// check Condition 6: validate by it self
@@ -1037,7 +1035,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 		t.Fatalf("Expect no error but get %+v", err)
 	}
 	// snd existed
-	err11 := tp.validateTransaction(tx1)
+	err11 := tp.validateTransaction(tx1, -1)
 	if err11 == nil {
 		t.Fatal("Expect double spend with blockchain error error but no error")
 	} else {
@@ -1046,21 +1044,10 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 		}
 	}
 	// check Condition 7: Check double spend with blockchain
-	// check Condition 8: Check Init Custom Token
-	ResetMempoolTest()
-	tp.poolTokenID[*txInitCustomToken.Hash()] = normalTokenID
-	err12 := tp.validateTransaction(txInitCustomTokenFailed)
-	if err12 == nil {
-		t.Fatal("Expect duplicate init token error error but no error")
-	} else {
-		if err12.(*MempoolTxError).Code != ErrCodeMessage[RejectDuplicateInitTokenTx].Code {
-			t.Fatalf("Expect Error %+v but get %+v", ErrCodeMessage[RejectDuplicateInitTokenTx], err)
-		}
-	}
 	// check Condition 9: Check Init Custom Token
 	ResetMempoolTest()
 	tp.poolCandidate[*txStakingShard.Hash()] = stakingPublicKey
-	err13 := tp.validateTransaction(txStakingShard)
+	err13 := tp.validateTransaction(txStakingShard, -1)
 	if err13 == nil {
 		t.Fatal("Expect duplicate staking pubkey error error but no error")
 	} else {
@@ -1068,7 +1055,7 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 			t.Fatalf("Expect Error %+v but get %+v", ErrCodeMessage[RejectDuplicateStakePubkey], err)
 		}
 	}
-	err13 = tp.validateTransaction(txStakingShard)
+	err13 = tp.validateTransaction(txStakingShard, -1)
 	if err13 == nil {
 		t.Fatal("Expect duplicate staking pubkey error error but no error")
 	} else {
@@ -1078,15 +1065,15 @@ func TestTxPoolValidateTransaction(t *testing.T) {
 	}
 	ResetMempoolTest()
 	// Pass all case
-	err14 := tp.validateTransaction(txStakingShard)
+	err14 := tp.validateTransaction(txStakingShard, -1)
 	if err14 != nil {
 		t.Fatal("Expect no err but get ", err14)
 	}
-	err14 = tp.validateTransaction(tx3)
+	err14 = tp.validateTransaction(tx3, -1)
 	if err14 != nil {
 		t.Fatal("Expect no err but get ", err14)
 	}
-	err14 = tp.validateTransaction(txInitCustomToken)
+	err14 = tp.validateTransaction(txInitCustomToken, -1)
 	if err14 != nil {
 		t.Fatal("Expect no err but get ", err14)
 	}
@@ -1096,39 +1083,34 @@ func TestTxPoolmayBeAcceptTransaction(t *testing.T) {
 	tx1 := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], commonFee, false, normalTranferAmount)
 	tx2 := CreateAndSaveTestNormalTransaction(privateKeyShard0[1], commonFee, false, normalTranferAmount)
 	tx3 := CreateAndSaveTestNormalTransaction(privateKeyShard0[2], commonFee, false, normalTranferAmount)
-	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[3], commonFee, defaultTokenParams, false)
-	txInitCustomTokenFailed := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[4], commonFee, defaultTokenParams, false)
+	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[3], commonFee, defaultTokenParams, false)
 	txStakingBeacon := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, true)
 	tx6 := CreateAndSaveTestNormalTransaction(privateKeyShard0[5], commonFee, true, 50)
-	_, _, err1 := tp.maybeAcceptTransaction(tx1, false, true)
+	_, _, err1 := tp.maybeAcceptTransaction(tx1, false, true, -1)
 	if err1 != nil {
 		t.Fatal("Expect no error but get ", err1)
 	}
-	_, _, err2 := tp.maybeAcceptTransaction(tx2, false, true)
+	_, _, err2 := tp.maybeAcceptTransaction(tx2, false, true, -1)
 	if err2 != nil {
 		t.Fatal("Expect no error but get ", err2)
 	}
-	_, _, err3 := tp.maybeAcceptTransaction(tx3, false, true)
+	_, _, err3 := tp.maybeAcceptTransaction(tx3, false, true, -1)
 	if err3 != nil {
 		t.Fatal("Expect no error but get ", err3)
 	}
-	_, _, err4 := tp.maybeAcceptTransaction(txInitCustomToken, false, true)
+	_, _, err4 := tp.maybeAcceptTransaction(txInitCustomToken, false, true, -1)
 	if err4 != nil {
 		t.Fatal("Expect no error but get ", err4)
 	}
 	/* can not stake beacon
-	_, _, err5 := tp.maybeAcceptTransaction(txStakingBeacon, false, true)
+	_, _, err5 := tp.maybeAcceptTransaction(txStakingBeacon, false, true, -1)
 	if err5 != nil {
 		t.Fatal("Expect no error but get ", err5)
 	}*/
-	_, _, err6 := tp.maybeAcceptTransaction(tx6, false, true)
+	_, _, err6 := tp.maybeAcceptTransaction(tx6, false, true, -1)
 	if err6 != nil {
 		t.Fatal("Expect no error but get ", err6)
 	}
-	_, _, err7 := tp.maybeAcceptTransaction(txInitCustomTokenFailed, false, true)
-	if err7 == nil {
-		t.Fatalf("Expect error %+v but get no error", err7)
-	}
 	if len(tp.pool) != 5 {
 		t.Fatalf("Expect 5 transaction from mempool but get %+v", len(tp.pool))
 	}
@@ -1141,54 +1123,48 @@ func TestTxPoolmayBeAcceptTransaction(t *testing.T) {
 	if len(tp.poolCandidate) != 1 {
 		t.Fatalf("Expect 1 but get %+v", len(tp.poolCandidate))
 	}*/
-	if common.IndexOfStrInHashMap(normalTokenID, tp.poolTokenID) < 0 {
-		t.Fatalf("Expect %+v in pool but get %+v", stakingPublicKey, tp.poolCandidate)
-	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx1.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx1.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx1.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx2.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx2.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx2.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx3.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx3.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx3.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx6.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx6.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx6.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txInitCustomToken.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(txInitCustomToken.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", txInitCustomToken.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txStakingBeacon.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(txStakingBeacon.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", txStakingBeacon.Hash())
 	}
 	// persist mempool
 	ResetMempoolTest()
-	tp.maybeAcceptTransaction(tx1, true, true)
-	tp.maybeAcceptTransaction(tx2, true, true)
-	tp.maybeAcceptTransaction(tx3, true, true)
-	tp.maybeAcceptTransaction(txInitCustomToken, true, true)
-	tp.maybeAcceptTransaction(txStakingBeacon, true, true)
-	tp.maybeAcceptTransaction(tx6, true, true)
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx1.Hash()); !isOk || err != nil {
+	tp.maybeAcceptTransaction(tx1, true, true, -1)
+	tp.maybeAcceptTransaction(tx2, true, true, -1)
+	tp.maybeAcceptTransaction(tx3, true, true, -1)
+	tp.maybeAcceptTransaction(txInitCustomToken, true, true, -1)
+	tp.maybeAcceptTransaction(txStakingBeacon, true, true, -1)
+	tp.maybeAcceptTransaction(tx6, true, true, -1)
+	if isOk, err := hasTxInDatabaseMempool(tx1.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx1.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx2.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx2.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx2.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx3.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx3.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx3.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx6.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(tx6.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", tx6.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txInitCustomToken.Hash()); !isOk || err != nil {
+	if isOk, err := hasTxInDatabaseMempool(txInitCustomToken.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", txInitCustomToken.Hash())
 	}
-	/*if isOk, err := tp.config.DataBaseMempool.HasTransaction(txStakingBeacon.Hash()); !isOk || err != nil {
+	/*if isOk, err := hasTxInDatabaseMempool(txStakingBeacon.Hash()); !isOk || err != nil {
 		t.Fatalf("Expect tx hash %+v in database mempool but counter err", txStakingBeacon.Hash())
 	}*/
 
@@ -1202,7 +1178,7 @@ func TestTxPoolmayBeAcceptTransaction(t *testing.T) {
 
 	err = tp.removeTransactionFromDatabaseMP(tx1.Hash())
 	assert.Equal(t, nil, err)
-	isOk, err := tp.config.DataBaseMempool.HasTransaction(tx1.Hash())
+	isOk, err := hasTxInDatabaseMempool(tx1.Hash())
 	assert.Equal(t, nil, err)
 	assert.Equal(t, false, isOk)
 
@@ -1258,16 +1234,16 @@ func TestTxPoolRemoveTx(t *testing.T) {
 	tx1 := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], 10, false, normalTranferAmount)
 	tx2 := CreateAndSaveTestNormalTransaction(privateKeyShard0[1], 10, false, normalTranferAmount)
 	tx3 := CreateAndSaveTestNormalTransaction(privateKeyShard0[2], 10, false, normalTranferAmount)
-	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[3], commonFee, defaultTokenParams, false)
+	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[3], commonFee, defaultTokenParams, false)
 	txStakingBeacon := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, true)
 	tx6 := CreateAndSaveTestNormalTransaction(privateKeyShard0[5], commonFee, true, 50)
 	txs := []metadata.Transaction{tx1, tx2, tx3, txInitCustomToken, txStakingBeacon, tx6}
-	tp.maybeAcceptTransaction(tx1, false, true)
-	tp.maybeAcceptTransaction(tx2, false, true)
-	tp.maybeAcceptTransaction(tx3, false, true)
-	tp.maybeAcceptTransaction(txInitCustomToken, false, true)
-	tp.maybeAcceptTransaction(txStakingBeacon, false, true) // this is fail because can not stake beacon now
-	tp.maybeAcceptTransaction(tx6, false, true)
+	tp.maybeAcceptTransaction(tx1, false, true, -1)
+	tp.maybeAcceptTransaction(tx2, false, true, -1)
+	tp.maybeAcceptTransaction(tx3, false, true, -1)
+	tp.maybeAcceptTransaction(txInitCustomToken, false, true, -1)
+	tp.maybeAcceptTransaction(txStakingBeacon, false, true, -1) // this is fail because can not stake beacon now
+	tp.maybeAcceptTransaction(tx6, false, true, -1)
 	if len(tp.pool) != 5 {
 		t.Fatalf("Expect 5 transaction from pool but get %+v", len(tp.pool))
 	}
@@ -1283,12 +1259,6 @@ func TestTxPoolRemoveTx(t *testing.T) {
 	if len(tp.poolCandidate) != 0 { // because can not stake beacon
 		t.Fatalf("Expect 0 but get %+v", len(tp.poolCandidate))
 	}
-	if common.IndexOfStrInHashMap(tokenID, tp.poolTokenID) >= 0 {
-		t.Fatalf("Expect %+v in pool but get %+v", stakingPublicKey, tp.poolCandidate)
-	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
 	tp.RemoveTx(txs, true)
 	if len(tp.pool) != 0 {
 		t.Fatalf("Expect 0 transaction from mempool but get %+v", len(tp.pool))
@@ -1305,52 +1275,39 @@ func TestTxPoolRemoveTx(t *testing.T) {
 	if len(tp.poolCandidate) != 0 { // beacause can not stake to beacon
 		t.Fatalf("Expect 1 but get %+v", len(tp.poolCandidate))
 	}
-	if common.IndexOfStrInHashMap(normalTokenID, tp.poolTokenID) < 0 {
-		t.Fatalf("Expect %+v in pool but get %+v", stakingPublicKey, tp.poolCandidate)
-	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
 	tp.RemoveCandidateList([]string{stakingPublicKey})
-	tp.RemoveTokenIDList([]string{tokenID})
 	if len(tp.poolCandidate) != 0 {
 		t.Fatalf("Expect 0 but get %+v", len(tp.poolCandidate))
 	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
 	if common.IndexOfStrInHashMap(stakingPublicKey, tp.poolCandidate) > 0 {
 		t.Fatalf("Expect %+v NOT in pool but get %+v", stakingPublicKey, tp.poolCandidate)
 	}
-	if common.IndexOfStrInHashMap(tokenID, tp.poolTokenID) > 0 {
-		t.Fatalf("Expect %+v NOT in pool but get %+v", stakingPublicKey, tp.poolCandidate)
-	}
 	// no persist mempool
 	ResetMempoolTest()
 	tp.config.PersistMempool = true
-	tp.maybeAcceptTransaction(tx1, true, true)
-	tp.maybeAcceptTransaction(tx2, true, true)
-	tp.maybeAcceptTransaction(tx3, true, true)
-	tp.maybeAcceptTransaction(txInitCustomToken, true, true)
-	tp.maybeAcceptTransaction(txStakingBeacon, true, true)
-	tp.maybeAcceptTransaction(tx6, true, true)
+	tp.maybeAcceptTransaction(tx1, true, true, -1)
+	tp.maybeAcceptTransaction(tx2, true, true, -1)
+	tp.maybeAcceptTransaction(tx3, true, true, -1)
+	tp.maybeAcceptTransaction(txInitCustomToken, true, true, -1)
+	tp.maybeAcceptTransaction(txStakingBeacon, true, true, -1)
+	tp.maybeAcceptTransaction(tx6, true, true, -1)
 	tp.RemoveTx(txs, true)
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx1.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx1.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx1.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx2.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx2.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx2.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx3.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx3.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx3.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(tx6.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(tx6.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", tx6.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txInitCustomToken.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(txInitCustomToken.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", txInitCustomToken.Hash())
 	}
-	if isOk, err := tp.config.DataBaseMempool.HasTransaction(txStakingBeacon.Hash()); isOk && err == nil {
+	if isOk, err := hasTxInDatabaseMempool(txStakingBeacon.Hash()); isOk && err == nil {
 		t.Fatalf("Expect tx hash %+v NOT in database mempool but counter err", txStakingBeacon.Hash())
 	}
 }
@@ -1360,7 +1317,7 @@ func TestTxPoolMaybeAcceptTransaction(t *testing.T) {
 	// test relay shard and role in committeess
 	tp.config.RelayShards = []byte{}
 	tp.RoleInCommittees = -1
-	_, _, err1 := tp.MaybeAcceptTransaction(tx1)
+	_, _, err1 := tp.MaybeAcceptTransaction(tx1, -1)
 	if err1 == nil {
 		t.Fatal("Expect unexpected transaction error error but no error")
 	} else {
@@ -1370,7 +1327,7 @@ func TestTxPoolMaybeAcceptTransaction(t *testing.T) {
 	}
 	// test size of mempool
 	tp.config.RelayShards = []byte{0}
-	_, _, err2 := tp.MaybeAcceptTransaction(tx1)
+	_, _, err2 := tp.MaybeAcceptTransaction(tx1, -1)
 	if err2 == nil {
 		t.Fatal("Expect max pool size error error but no error")
 	} else {
@@ -1379,7 +1336,7 @@ func TestTxPoolMaybeAcceptTransaction(t *testing.T) {
 		}
 	}
 	tp.RoleInCommittees = 0
-	_, _, err3 := tp.MaybeAcceptTransaction(tx1)
+	_, _, err3 := tp.MaybeAcceptTransaction(tx1, -1)
 	if err3 == nil {
 		t.Fatal("Expect max pool size error error but no error")
 	} else {
@@ -1388,7 +1345,7 @@ func TestTxPoolMaybeAcceptTransaction(t *testing.T) {
 		}
 	}
 	tp.config.MaxTx = 1
-	_, _, err4 := tp.MaybeAcceptTransaction(tx1)
+	_, _, err4 := tp.MaybeAcceptTransaction(tx1, -1)
 	if err4 != nil {
 		t.Fatal("Expect no error but get ", err4)
 	}
@@ -1399,21 +1356,19 @@ func TestTxPoolMaybeAcceptTransaction(t *testing.T) {
 	tp.config.RelayShards = []byte{0}
 	tp.RoleInCommittees = 0
 	// test push transaction to block gen
-	_, _, err5 := tp.MaybeAcceptTransaction(tx1)
+	_, _, err5 := tp.MaybeAcceptTransaction(tx1, -1)
 	if err5 != nil {
 		t.Fatal("Expect no error but get ", err5)
 	}
-	go func() {
-		tx := <-cPendingTxs
-		if !tx.Hash().IsEqual(tx1.Hash()) {
-			t.Fatalf("Expect get %+v but get %+v ", tx1.Hash(), tx.Hash())
-		}
-	}()
+	tx := <-cPendingTxs
+	if !tx.Hash().IsEqual(tx1.Hash()) {
+		t.Fatalf("Expect get %+v but get %+v ", tx1.Hash(), tx.Hash())
+	}
 }
 func TestTxPoolMarkForwardedTransaction(t *testing.T) {
 	ResetMempoolTest()
 	tx1 := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], 10, false, normalTranferAmount)
-	txHash1, txDesc1, err := tp.maybeAcceptTransaction(tx1, false, true)
+	txHash1, txDesc1, err := tp.maybeAcceptTransaction(tx1, false, true, -1)
 	if err != nil {
 		t.Fatal("Expect no error but get ", err)
 	}
@@ -1427,15 +1382,15 @@ func TestTxPoolEmptyPool(t *testing.T) {
 	tx1 := CreateAndSaveTestNormalTransaction(privateKeyShard0[0], 10, false, normalTranferAmount)
 	tx2 := CreateAndSaveTestNormalTransaction(privateKeyShard0[1], 10, false, normalTranferAmount)
 	tx3 := CreateAndSaveTestNormalTransaction(privateKeyShard0[2], 10, false, normalTranferAmount)
-	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransaction(privateKeyShard0[3], commonFee, defaultTokenParams, false)
+	txInitCustomToken := CreateAndSaveTestInitCustomTokenTransactionPrivacy(privateKeyShard0[3], commonFee, defaultTokenParams, false)
 	txStakingBeacon := CreateAndSaveTestStakingTransaction(privateKeyShard0[4], miningSeedShard0[4], commonFee, true)
 	tx6 := CreateAndSaveTestNormalTransaction(privateKeyShard0[5], commonFee, true, 50)
-	tp.maybeAcceptTransaction(tx1, true, true)
-	tp.maybeAcceptTransaction(tx2, true, true)
-	tp.maybeAcceptTransaction(tx3, true, true)
-	tp.maybeAcceptTransaction(txInitCustomToken, true, true)
-	tp.maybeAcceptTransaction(txStakingBeacon, true, true) // this is fail because can not stake beacon now
-	tp.maybeAcceptTransaction(tx6, true, true)
+	tp.maybeAcceptTransaction(tx1, true, true, -1)
+	tp.maybeAcceptTransaction(tx2, true, true, -1)
+	tp.maybeAcceptTransaction(tx3, true, true, -1)
+	tp.maybeAcceptTransaction(txInitCustomToken, true, true, -1)
+	tp.maybeAcceptTransaction(txStakingBeacon, true, true, -1) // this is fail because can not stake beacon now
+	tp.maybeAcceptTransaction(tx6, true, true, -1)
 	if len(tp.pool) != 5 {
 		t.Fatalf("Expect 5 transaction from mempool but get %+v", len(tp.pool))
 	}
@@ -1445,9 +1400,6 @@ func TestTxPoolEmptyPool(t *testing.T) {
 	if len(tp.poolCandidate) != 0 { // because can not stake beacon
 		t.Fatalf("Expect 0 but get %+v", len(tp.poolCandidate))
 	}
-	if len(tp.poolTokenID) != 1 {
-		t.Fatalf("Expect 1 but get %+v", len(tp.poolTokenID))
-	}
 	tp.EmptyPool()
 
 	if len(tp.pool) != 0 {
@@ -1459,7 +1411,334 @@ func TestTxPoolEmptyPool(t *testing.T) {
 	if len(tp.poolCandidate) != 0 {
 		t.Fatal("Can't empty candidate pool")
 	}
-	if len(tp.poolTokenID) != 0 {
-		t.Fatal("Can't empty token id pool")
+}
+
+// fakeTx - tx with fixed fees, size, serial numbers and coins, used to test pool policies without creating proofs
+type fakeTx struct {
+	metadata.Transaction
+	hash          common.Hash
+	fee           uint64
+	feeToken      uint64
+	tokenID       common.Hash
+	size          uint64
+	serialNumbers []common.Hash
+	proof         *zkp.PaymentProof
+}
+
+// fakeTxPublicKey - owner of outputs of fake txs, outputs stay in shard of sender so they can be spent in pool
+var fakeTxPublicKey = privacy.RandomPoint()
+
+// newFakeTx - tx paying fee PRV for size KB which spends serial numbers and the output of each parent,
+// and has one output of its own
+func newFakeTx(name string, fee uint64, size uint64, serialNumbers []string, parents ...*fakeTx) *fakeTx {
+	tx := &fakeTx{
+		hash:    common.HashH([]byte(name)),
+		fee:     fee,
+		tokenID: common.PRVCoinID,
+		size:    size,
+		proof:   &zkp.PaymentProof{},
+	}
+	for _, serialNumber := range serialNumbers {
+		tx.serialNumbers = append(tx.serialNumbers, common.HashH([]byte(serialNumber)))
+	}
+	inputCoins := []*privacy.InputCoin{}
+	for _, parent := range parents {
+		coin := new(privacy.Coin)
+		coin.SetCoinCommitment(parent.proof.GetOutputCoins()[0].CoinDetails.GetCoinCommitment())
+		inputCoins = append(inputCoins, &privacy.InputCoin{CoinDetails: coin})
+	}
+	tx.proof.SetInputCoins(inputCoins)
+	coin := new(privacy.Coin)
+	coin.SetPublicKey(fakeTxPublicKey)
+	coin.SetCoinCommitment(privacy.RandomPoint())
+	tx.proof.SetOutputCoins([]*privacy.OutputCoin{{CoinDetails: coin}})
+	return tx
+}
+
+// withFeeToken - tx also pays feeToken in token
+func (tx *fakeTx) withFeeToken(tokenID common.Hash, feeToken uint64) *fakeTx {
+	tx.tokenID = tokenID
+	tx.feeToken = feeToken
+	return tx
+}
+
+func (tx *fakeTx) Hash() *common.Hash             { return &tx.hash }
+func (tx *fakeTx) GetType() string                { return common.TxReturnStakingType }
+func (tx *fakeTx) GetMetadata() metadata.Metadata { return nil }
+func (tx *fakeTx) GetTxFee() uint64               { return tx.fee }
+func (tx *fakeTx) GetTxFeeToken() uint64          { return tx.feeToken }
+func (tx *fakeTx) GetTokenID() *common.Hash       { return &tx.tokenID }
+func (tx *fakeTx) GetTxActualSize() uint64        { return tx.size }
+func (tx *fakeTx) GetSenderAddrLastByte() byte {
+	return fakeTxPublicKey.ToBytesS()[privacy.Ed25519KeySize-1]
+}
+func (tx *fakeTx) ListSerialNumbersHashH() []common.Hash { return tx.serialNumbers }
+func (tx *fakeTx) GetProof() *zkp.PaymentProof           { return tx.proof }
+
+// newFakeTxDesc - description of tx which is validated and waits for pool policies
+func newFakeTxDesc(tx *fakeTx) *TxDesc {
+	txD := createTxDescMempool(tx, 1, tx.fee, tx.feeToken)
+	txD.FeeRate = tp.calFeeRate(txD)
+	return txD
+}
+
+// addFakeTxs - add txs into pool without validating them
+func addFakeTxs(t *testing.T, txs ...*fakeTx) {
+	for _, tx := range txs {
+		if err := tp.addTx(createTxDescMempool(tx, 1, tx.fee, tx.feeToken), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTxPoolValidateTransactionReplacement(t *testing.T) {
+	tokenA := common.HashH([]byte("tokenA"))
+	tokenB := common.HashH([]byte("tokenB"))
+	parent := newFakeTx("parent", 100, 10, []string{"sn1"})
+	tests := []struct {
+		name    string
+		inPool  []*fakeTx
+		tx      *fakeTx
+		wantErr bool
+	}{
+		{
+			name:   "higher prv fee",
+			inPool: []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"})},
+			tx:     newFakeTx("b", 200, 10, []string{"sn1"}),
+		},
+		{
+			name:    "prv fee not higher than fee ratio",
+			inPool:  []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"})},
+			tx:      newFakeTx("b", 110, 1, []string{"sn1"}),
+			wantErr: true,
+		},
+		{
+			name:    "prv fee increment does not pay for tx size",
+			inPool:  []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"})},
+			tx:      newFakeTx("b", 115, 20, []string{"sn1"}),
+			wantErr: true,
+		},
+		{
+			name:   "prv fee increment pays for tx size",
+			inPool: []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"})},
+			tx:     newFakeTx("b", 120, 20, []string{"sn1"}),
+		},
+		{
+			name:    "prv fee not higher than fee of whole conflict set",
+			inPool:  []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"}), newFakeTx("c", 100, 10, []string{"sn2"})},
+			tx:      newFakeTx("b", 200, 10, []string{"sn1", "sn2"}),
+			wantErr: true,
+		},
+		{
+			name:   "prv fee higher than fee of whole conflict set",
+			inPool: []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"}), newFakeTx("c", 100, 10, []string{"sn2"})},
+			tx:     newFakeTx("b", 300, 10, []string{"sn1", "sn2"}),
+		},
+		{
+			name:    "prv fee not higher than fee of conflict tx with its descendants",
+			inPool:  []*fakeTx{parent, newFakeTx("child", 100, 10, []string{"sn2"}, parent)},
+			tx:      newFakeTx("b", 200, 10, []string{"sn1"}),
+			wantErr: true,
+		},
+		{
+			name:   "prv fee higher than fee of conflict tx with its descendants",
+			inPool: []*fakeTx{parent, newFakeTx("child", 100, 10, []string{"sn2"}, parent)},
+			tx:     newFakeTx("b", 300, 10, []string{"sn1"}),
+		},
+		{
+			name:    "tx replaces its own parent",
+			inPool:  []*fakeTx{parent},
+			tx:      newFakeTx("b", 300, 10, []string{"sn1"}, parent),
+			wantErr: true,
+		},
+		{
+			name:   "higher token fee",
+			inPool: []*fakeTx{newFakeTx("a", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:     newFakeTx("b", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 120),
+		},
+		{
+			name:    "token fee not higher than fee ratio",
+			inPool:  []*fakeTx{newFakeTx("a", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:      newFakeTx("b", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 110),
+			wantErr: true,
+		},
+		{
+			name:    "token fee paid in another token",
+			inPool:  []*fakeTx{newFakeTx("a", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:      newFakeTx("b", 0, 10, []string{"sn1"}).withFeeToken(tokenB, 200),
+			wantErr: true,
+		},
+		{
+			name:    "token fee replaced by prv fee",
+			inPool:  []*fakeTx{newFakeTx("a", 0, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:      newFakeTx("b", 1000, 10, []string{"sn1"}),
+			wantErr: true,
+		},
+		{
+			name:    "higher prv fee but token fee not higher than fee ratio",
+			inPool:  []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:      newFakeTx("b", 200, 10, []string{"sn1"}).withFeeToken(tokenA, 105),
+			wantErr: true,
+		},
+		{
+			name:   "higher prv fee and token fee",
+			inPool: []*fakeTx{newFakeTx("a", 100, 10, []string{"sn1"}).withFeeToken(tokenA, 100)},
+			tx:     newFakeTx("b", 200, 10, []string{"sn1"}).withFeeToken(tokenA, 120),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetMempoolTest()
+			addFakeTxs(t, tt.inPool...)
+			err := tp.validateTransactionReplacement(tt.tx, tp.findConflictTxs(tt.tx))
+			if tt.wantErr {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, ErrCodeMessage[RejectReplacementTxError].Code, err.(*MempoolTxError).Code)
+				}
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestTxPoolValidateTransactionReplacementConflictLimit(t *testing.T) {
+	ResetMempoolTest()
+	serialNumbers := []string{}
+	for i := 0; i <= maxReplacementConflicts; i++ {
+		serialNumber := fmt.Sprintf("sn%d", i)
+		serialNumbers = append(serialNumbers, serialNumber)
+		addFakeTxs(t, newFakeTx(serialNumber, 1, 1, []string{serialNumber}))
+	}
+	tx := newFakeTx("replacement", 10000, 1, serialNumbers[:maxReplacementConflicts])
+	assert.Nil(t, tp.validateTransactionReplacement(tx, tp.findConflictTxs(tx)))
+	tx = newFakeTx("replacement", 10000, 1, serialNumbers)
+	err := tp.validateTransactionReplacement(tx, tp.findConflictTxs(tx))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeMessage[RejectReplacementTxError].Code, err.(*MempoolTxError).Code)
 	}
 }
+
+func TestTxPoolEvictReplacedTxs(t *testing.T) {
+	ResetMempoolTest()
+	parent := newFakeTx("parent", 100, 10, []string{"sn1"})
+	child := newFakeTx("child", 100, 10, []string{"sn2"}, parent)
+	other := newFakeTx("other", 100, 10, []string{"sn3"})
+	addFakeTxs(t, parent, child, other)
+	tx := newFakeTx("replacement", 300, 10, []string{"sn1"})
+	replaced := tp.evictReplacedTxs(tx)
+	assert.ElementsMatch(t, []common.Hash{parent.hash, child.hash}, replaced)
+	for _, txHash := range replaced {
+		replacedBy, ok := tp.GetReplacedBy(&txHash)
+		assert.True(t, ok)
+		assert.Equal(t, tx.hash, replacedBy)
+	}
+	assert.Equal(t, 1, len(tp.pool))
+	assert.True(t, tp.isTxInPool(&other.hash))
+	assert.Equal(t, uint64(10), tp.poolSize)
+	assert.Empty(t, tp.poolChildren)
+	assert.Equal(t, []*TxDesc{tp.pool[other.hash]}, tp.poolFeeRate.list())
+}
+
+func TestTxPoolRecordReplacement(t *testing.T) {
+	ResetMempoolTest()
+	replacement := common.HashH([]byte("replacement"))
+	txHashes := []common.Hash{}
+	for i := 0; i <= maxReplacedTxRecords; i++ {
+		txHash := common.HashH([]byte(fmt.Sprintf("tx%d", i)))
+		txHashes = append(txHashes, txHash)
+		tp.recordReplacement(txHash, replacement)
+	}
+	// the oldest record is dropped
+	assert.Equal(t, maxReplacedTxRecords, len(tp.replacedTxs))
+	assert.Equal(t, maxReplacedTxRecords, len(tp.replacedBy))
+	_, ok := tp.GetReplacedBy(&txHashes[0])
+	assert.False(t, ok)
+	replacedBy, ok := tp.GetReplacedBy(&txHashes[maxReplacedTxRecords])
+	assert.True(t, ok)
+	assert.Equal(t, replacement, replacedBy)
+
+	// recording a tx again updates its replacement without adding a record
+	other := common.HashH([]byte("other"))
+	tp.recordReplacement(txHashes[1], other)
+	assert.Equal(t, maxReplacedTxRecords, len(tp.replacedTxs))
+	replacedBy, ok = tp.GetReplacedBy(&txHashes[1])
+	assert.True(t, ok)
+	assert.Equal(t, other, replacedBy)
+}
+
+func TestTxPoolMiningDescs(t *testing.T) {
+	ResetMempoolTest()
+	low := newFakeTx("low", 100, 10, []string{"sn1"})
+	high := newFakeTx("high", 300, 10, []string{"sn2"})
+	middle := newFakeTx("middle", 400, 20, []string{"sn3"})
+	addFakeTxs(t, low, high, middle)
+	hashes := []common.Hash{}
+	for _, txDesc := range tp.MiningDescs() {
+		hashes = append(hashes, *txDesc.Tx.Hash())
+	}
+	assert.Equal(t, []common.Hash{high.hash, middle.hash, low.hash}, hashes)
+}
+
+func TestTxPoolAcceptTxDescMaxSize(t *testing.T) {
+	ResetMempoolTest()
+	defer func(maxSize uint64) {
+		tp.config.MaxSize = maxSize
+	}(tp.config.MaxSize)
+	tp.config.MaxSize = 30
+	assertPool := func(txs ...*fakeTx) {
+		hashes := []common.Hash{}
+		for _, tx := range txs {
+			hashes = append(hashes, tx.hash)
+		}
+		poolHashes := []common.Hash{}
+		for txHash := range tp.pool {
+			poolHashes = append(poolHashes, txHash)
+		}
+		assert.ElementsMatch(t, hashes, poolHashes)
+		assert.Equal(t, uint64(10*len(txs)), tp.poolSize)
+	}
+	a := newFakeTx("a", 100, 10, []string{"sn1"})
+	b := newFakeTx("b", 200, 10, []string{"sn2"})
+	c := newFakeTx("c", 300, 10, []string{"sn3"})
+	for _, tx := range []*fakeTx{a, b, c} {
+		assert.Nil(t, tp.acceptTxDesc(newFakeTxDesc(tx), false, true))
+	}
+	assertPool(a, b, c)
+
+	// tx with the lowest fee rate is evicted
+	d := newFakeTx("d", 250, 10, []string{"sn4"})
+	assert.Nil(t, tp.acceptTxDesc(newFakeTxDesc(d), false, true))
+	assertPool(b, c, d)
+
+	// fee rate is too low to evict any tx
+	err := tp.acceptTxDesc(newFakeTxDesc(newFakeTx("e", 50, 10, []string{"sn5"})), false, true)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeMessage[MaxPoolSizeError].Code, err.(*MempoolTxError).Code)
+	}
+	assertPool(b, c, d)
+
+	// txs with lower fee rate are not enough, nothing is evicted
+	err = tp.acceptTxDesc(newFakeTxDesc(newFakeTx("e", 500, 20, []string{"sn5"})), false, true)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeMessage[MaxPoolSizeError].Code, err.(*MempoolTxError).Code)
+	}
+	assertPool(b, c, d)
+
+	// tx double spending for a new block is rejected before any eviction
+	err = tp.acceptTxDesc(newFakeTxDesc(newFakeTx("f", 1000, 10, []string{"sn3"})), false, false)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeMessage[RejectDoubleSpendWithMempoolTx].Code, err.(*MempoolTxError).Code)
+	}
+	assertPool(b, c, d)
+
+	// space of replaced tx is reused, no tx with lower fee rate is evicted
+	f := newFakeTx("f", 1000, 10, []string{"sn3"})
+	txD := newFakeTxDesc(f)
+	assert.Nil(t, tp.acceptTxDesc(txD, false, true))
+	assertPool(b, d, f)
+	assert.Equal(t, []common.Hash{c.hash}, txD.Replaces)
+	replacedBy, ok := tp.GetReplacedBy(&c.hash)
+	assert.True(t, ok)
+	assert.Equal(t, f.hash, replacedBy)
+}
@@ -2,10 +2,13 @@ package mempool
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/databasemp"
 	"github.com/incognitochain/incognito-chain/databasemp/lvdb"
 	"github.com/incognitochain/incognito-chain/transaction"
 )
@@ -18,8 +21,15 @@ type TempDesc struct {
 	FeePerKB      int32
 }
 
-// addTransactionToDatabaseMempool - Add a transaction data into mempool database
-func (tp *TxPool) addTransactionToDatabaseMempool(txHash *common.Hash, txDesc TxDesc) error {
+// RestoreResult - result of restoring a tx from mempool database when node starts
+type RestoreResult struct {
+	TxHash   common.Hash
+	Restored bool
+	Reason   string // reason of dropping tx, empty if tx is restored
+}
+
+// newAddTxJournalRecord - journal record of a tx added into pool
+func newAddTxJournalRecord(txHash *common.Hash, txDesc TxDesc) (*databasemp.JournalRecord, error) {
 	tx := txDesc.Desc.Tx
 	tempDesc := TempDesc{
 		StartTime:     txDesc.StartTime,
@@ -28,125 +38,308 @@ func (tp *TxPool) addTransactionToDatabaseMempool(txHash *common.Hash, txDesc Tx
 		Fee:           txDesc.Desc.Fee,
 		FeePerKB:      txDesc.Desc.FeePerKB,
 	}
+	var valueTx []byte
+	var err error
 	switch tx.GetType() {
 	//==================For PRV Transfer Only
 	case common.TxNormalType:
-		{
-			normalTx := tx.(*transaction.Tx)
-			valueTx, err := json.Marshal(normalTx)
-			if err != nil {
-				return err
-			}
-			valueDesc, err := json.Marshal(tempDesc)
-			if err != nil {
-				return err
-			}
-			err = tp.config.DataBaseMempool.AddTransaction(txHash, common.TxNormalType, valueTx, valueDesc)
-			if err != nil {
-				return err
-			}
-		}
+		valueTx, err = json.Marshal(tx.(*transaction.Tx))
 	//==================For PRV & TxNormalToken Transfer
 	case common.TxCustomTokenPrivacyType:
-		{
-			customTokenPrivacyTx := tx.(*transaction.TxCustomTokenPrivacy)
-			valueTx, err := json.Marshal(customTokenPrivacyTx)
-			if err != nil {
-				return err
-			}
-			valueDesc, err := json.Marshal(tempDesc)
-			if err != nil {
-				return err
-			}
-			err = tp.config.DataBaseMempool.AddTransaction(txHash, common.TxCustomTokenPrivacyType, valueTx, valueDesc)
-			if err != nil {
-				return err
-			}
-		}
+		valueTx, err = json.Marshal(tx.(*transaction.TxCustomTokenPrivacy))
+	default:
+		return nil, fmt.Errorf("tx type %+v is not persisted", tx.GetType())
+	}
+	if err != nil {
+		return nil, err
+	}
+	valueDesc, err := json.Marshal(tempDesc)
+	if err != nil {
+		return nil, err
+	}
+	return databasemp.NewAddTxJournalRecord(*txHash, tx.GetType(), valueTx, valueDesc), nil
+}
+
+// addTransactionToDatabaseMempool - Add a transaction data into mempool database
+func (tp *TxPool) addTransactionToDatabaseMempool(txHash *common.Hash, txDesc TxDesc) error {
+	record, err := newAddTxJournalRecord(txHash, txDesc)
+	if err != nil {
+		return err
+	}
+	err = tp.config.DataBaseMempool.AppendJournal(record)
+	if err != nil {
+		return err
 	}
+	tp.journalRecords++
+	tp.journalTxs[*txHash] = struct{}{}
 	return nil
 }
 
 // getTransactionFromDatabaseMempool - get tx from mempool database
 func (tp *TxPool) getTransactionFromDatabaseMempool(txHash *common.Hash) (*TxDesc, error) {
-	value, err := tp.config.DataBaseMempool.GetTransaction(txHash)
-	values := strings.Split(string(value), string(lvdb.Splitter))
-	if len(values) != 3 {
-		return nil, err
-	}
-	txDesc, err := unMarshallTxDescFromDatabase(values[0], []byte(values[1]), []byte(values[2]))
+	journal, err := tp.config.DataBaseMempool.LoadJournal()
 	if err != nil {
 		return nil, err
 	}
-	return txDesc, nil
+	var found *databasemp.JournalRecord
+	for _, data := range journal {
+		record, err := databasemp.ParseJournalRecord(data)
+		if err != nil || !record.TxHash.IsEqual(txHash) {
+			continue
+		}
+		found = record
+	}
+	if found == nil || found.Op != databasemp.JournalAddTx {
+		return nil, fmt.Errorf("tx %+v is not found in mempool database", txHash.String())
+	}
+	return unMarshallTxDescFromDatabase(found.TxType, found.Tx, found.Desc)
 }
 
 // resetDatabaseMempool - reset data in data mempool
 func (tp *TxPool) resetDatabaseMempool() error {
-	return tp.config.DataBaseMempool.Reset()
+	err := tp.config.DataBaseMempool.Reset()
+	if err != nil {
+		return err
+	}
+	tp.journalRecords = 0
+	tp.journalTxs = make(map[common.Hash]struct{})
+	return nil
+}
+
+// loadJournal - replay records of mempool database, return add records of txs which are not removed yet.
+// Corrupted records are skipped with their reason, they do not affect other records
+func (tp *TxPool) loadJournal() ([]*databasemp.JournalRecord, error) {
+	records := []*databasemp.JournalRecord{}
+	// txs stored by the old format, before journal
+	allTxHashes, allTxs, err := tp.config.DataBaseMempool.Load()
+	if err != nil {
+		return nil, err
+	}
+	for index, value := range allTxs {
+		txHash := common.Hash{}
+		copy(txHash[:], allTxHashes[index][len("tx-"):])
+		values := strings.Split(string(value), string(lvdb.Splitter))
+		if len(values) != 3 {
+			tp.dropRestoredTx(txHash, "stored tx is corrupted")
+			continue
+		}
+		records = append(records, &databasemp.JournalRecord{
+			Op:     databasemp.JournalAddTx,
+			TxHash: txHash,
+			TxType: values[0],
+			Tx:     []byte(values[1]),
+			Desc:   []byte(values[2]),
+		})
+	}
+	journal, err := tp.config.DataBaseMempool.LoadJournal()
+	if err != nil {
+		return nil, err
+	}
+	for index, data := range journal {
+		record, err := databasemp.ParseJournalRecord(data)
+		if err != nil {
+			tp.dropRestoredTx(common.Hash{}, fmt.Sprintf("journal record %d is skipped: %+v", index+1, err))
+			continue
+		}
+		records = append(records, record)
+	}
+	tp.journalRecords = len(journal)
+	// replay
+	live := make(map[common.Hash]int)
+	for index, record := range records {
+		switch record.Op {
+		case databasemp.JournalAddTx:
+			if _, ok := live[record.TxHash]; !ok {
+				live[record.TxHash] = index
+			}
+		case databasemp.JournalRemoveTx:
+			delete(live, record.TxHash)
+		}
+	}
+	result := []*databasemp.JournalRecord{}
+	for index, record := range records {
+		if i, ok := live[record.TxHash]; ok && i == index {
+			result = append(result, record)
+		}
+	}
+	return result, nil
 }
 
-// loadDatabaseMP - Get all tx in mempool database persistence
+// dropRestoredTx - record a tx which is not restored into pool
+func (tp *TxPool) dropRestoredTx(txHash common.Hash, reason string) {
+	Logger.log.Errorf("Drop tx %+v from mempool database: %+v", txHash.String(), reason)
+	tp.restoreResults = append(tp.restoreResults, RestoreResult{TxHash: txHash, Reason: reason})
+}
+
+// loadDatabaseMP - Get all tx in mempool database persistence,
+// revalidate them against current best state, parents before their children, and add valid ones into pool
 func (tp *TxPool) loadDatabaseMP() ([]TxDesc, error) {
 	txDescs := []TxDesc{}
-	allTxHashes, allTxs, err := tp.config.DataBaseMempool.Load()
-	ttl := time.Duration(tp.config.TxLifeTime) * time.Second
+	tp.restoreResults = []RestoreResult{}
+	records, err := tp.loadJournal()
 	if err != nil {
 		return txDescs, err
 	}
-	for index, tx := range allTxs {
-		values := strings.Split(string(tx), string(lvdb.Splitter))
-		txDesc, err := unMarshallTxDescFromDatabase(values[0], []byte(values[1]), []byte(values[2]))
+	ttl := time.Duration(tp.config.TxLifeTime) * time.Second
+	decodedTxDescs := []*TxDesc{}
+	for _, record := range records {
+		txDesc, err := unMarshallTxDescFromDatabase(record.TxType, record.Tx, record.Desc)
 		if err != nil {
-			Logger.log.Error(err)
-			txHash, err := common.Hash{}.NewHash(allTxHashes[index][3:])
-			if err != nil {
-				Logger.log.Error(err)
-				continue
-			}
-			// fail to ummarshall transaction then remove
-			err1 := tp.removeTransactionFromDatabaseMP(txHash)
-			if err1 != nil {
-				Logger.log.Error(err1)
-			}
+			tp.dropRestoredTx(record.TxHash, fmt.Sprintf("fail to decode tx: %+v", err))
+			continue
+		}
+		if !txDesc.Desc.Tx.Hash().IsEqual(&record.TxHash) {
+			tp.dropRestoredTx(record.TxHash, fmt.Sprintf("decoded tx has a different hash %+v", txDesc.Desc.Tx.Hash().String()))
 			continue
 		}
+		decodedTxDescs = append(decodedTxDescs, txDesc)
+	}
+	// a parent enters pool before its children
+	sort.SliceStable(decodedTxDescs, func(i, j int) bool {
+		return decodedTxDescs[i].StartTime.Before(decodedTxDescs[j].StartTime)
+	})
+	droppedOutputs := make(map[common.Hash]common.Hash)
+	for _, txDesc := range decodedTxDescs {
+		tx := txDesc.Desc.Tx
+		txHash := *tx.Hash()
+		reason := ""
 		//if transaction is timeout then remove
-		if time.Since(txDesc.StartTime) > ttl {
-			err1 := tp.removeTransactionFromDatabaseMP(txDesc.Desc.Tx.Hash())
-			if err1 != nil {
-				Logger.log.Error(err1)
+		if ttl > 0 && time.Since(txDesc.StartTime) > ttl {
+			reason = fmt.Sprintf("tx is expired, it entered mempool at %+v", txDesc.StartTime)
+		}
+		if reason == "" {
+			for _, commitmentHash := range listInputCommitments(tx) {
+				if parentHash, ok := droppedOutputs[commitmentHash]; ok {
+					reason = fmt.Sprintf("parent tx %+v is dropped", parentHash.String())
+					break
+				}
 			}
 		}
 		//if not validated by current blockchain db then remove
-		err = tp.validateTransaction(txDesc.Desc.Tx, -1)
-		if err != nil {
-			Logger.log.Error(err)
-			err1 := tp.removeTransactionFromDatabaseMP(txDesc.Desc.Tx.Hash())
-			if err1 != nil {
-				Logger.log.Error(err1)
+		if reason == "" {
+			if err := tp.validateTransaction(tx, -1); err != nil {
+				reason = fmt.Sprintf("tx is invalid with current best state: %+v", err)
+			}
+		}
+		if reason == "" {
+			txDesc.Replaces = tp.evictReplacedTxs(tx)
+			if err := tp.addTx(txDesc, false); err != nil {
+				reason = fmt.Sprintf("fail to add tx into pool: %+v", err)
+			}
+		}
+		if reason != "" {
+			tp.dropRestoredTx(txHash, reason)
+			for _, commitmentHash := range listOutputCommitments(tx) {
+				droppedOutputs[commitmentHash] = txHash
 			}
 			continue
 		}
-
-		err = tp.addTx(txDesc, false)
-		if err != nil {
-			Logger.log.Error(err)
+		tp.restoreResults = append(tp.restoreResults, RestoreResult{TxHash: txHash, Restored: true})
+	}
+	// a restored tx may be evicted later by a replacement tx, or together with its evicted parent
+	for index, restoreResult := range tp.restoreResults {
+		if !restoreResult.Restored {
+			continue
+		}
+		txDesc, ok := tp.pool[restoreResult.TxHash]
+		if !ok {
+			tp.restoreResults[index].Restored = false
+			if replacedBy, ok := tp.replacedBy[restoreResult.TxHash]; ok {
+				tp.restoreResults[index].Reason = fmt.Sprintf("tx is replaced by tx %+v", replacedBy.String())
+			} else {
+				tp.restoreResults[index].Reason = "tx is evicted together with its replaced parent"
+			}
+			continue
 		}
+		tp.journalTxs[restoreResult.TxHash] = struct{}{}
 		txDescs = append(txDescs, *txDesc)
 	}
+	// rewrite journal with restored txs only
+	if err := tp.compactDatabaseMempool(); err != nil {
+		Logger.log.Error(err)
+	}
 	return txDescs, nil
 }
 
 // removeTransactionFromDatabaseMP - remove tx from mempool db persistence
 func (tp *TxPool) removeTransactionFromDatabaseMP(txHash *common.Hash) error {
-	if has, _ := tp.config.DataBaseMempool.HasTransaction(txHash); has {
-		err := tp.config.DataBaseMempool.RemoveTransaction(txHash)
+	if _, ok := tp.journalTxs[*txHash]; !ok {
+		return nil
+	}
+	err := tp.config.DataBaseMempool.AppendJournal(databasemp.NewRemoveTxJournalRecord(*txHash))
+	if err != nil {
+		return err
+	}
+	tp.journalRecords++
+	delete(tp.journalTxs, *txHash)
+	return nil
+}
+
+// compactDatabaseMempool - replace mempool database journal by add records of txs which are still in pool,
+// txs are written in order of entering pool so parents come before their children
+func (tp *TxPool) compactDatabaseMempool() error {
+	txDescs := []*TxDesc{}
+	for txHash := range tp.journalTxs {
+		if txDesc, ok := tp.pool[txHash]; ok {
+			txDescs = append(txDescs, txDesc)
+		}
+	}
+	sort.SliceStable(txDescs, func(i, j int) bool {
+		if !txDescs[i].StartTime.Equal(txDescs[j].StartTime) {
+			return txDescs[i].StartTime.Before(txDescs[j].StartTime)
+		}
+		return txDescs[i].Desc.Tx.Hash().String() < txDescs[j].Desc.Tx.Hash().String()
+	})
+	records := []*databasemp.JournalRecord{}
+	journalTxs := make(map[common.Hash]struct{})
+	for _, txDesc := range txDescs {
+		record, err := newAddTxJournalRecord(txDesc.Desc.Tx.Hash(), *txDesc)
+		if err != nil {
+			Logger.log.Error(err)
+			continue
+		}
+		records = append(records, record)
+		journalTxs[*txDesc.Desc.Tx.Hash()] = struct{}{}
+	}
+	err := tp.config.DataBaseMempool.CompactJournal(records)
+	if err != nil {
 		return err
 	}
+	tp.journalRecords = len(records)
+	tp.journalTxs = journalTxs
 	return nil
 }
 
+// MonitorDatabaseMempool - compact mempool database journal periodically,
+// records of txs which already left pool are dropped
+func (tp *TxPool) MonitorDatabaseMempool() {
+	if !tp.config.PersistMempool {
+		return
+	}
+	ticker := time.NewTicker(journalCompactTime)
+	defer ticker.Stop()
+	for _ = range ticker.C {
+		tp.mtx.Lock()
+		if tp.journalRecords > len(tp.journalTxs) {
+			Logger.log.Infof("MonitorDatabaseMempool: compact journal with %+v records of %+v txs", tp.journalRecords, len(tp.journalTxs))
+			err := tp.compactDatabaseMempool()
+			if err != nil {
+				Logger.log.Errorf("MonitorDatabaseMempool: fail to compact journal with error %+v", err)
+			}
+		}
+		tp.mtx.Unlock()
+	}
+}
+
+// GetRestoreResults - result of restoring txs from mempool database when node started
+func (tp *TxPool) GetRestoreResults() []RestoreResult {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+	result := make([]RestoreResult, len(tp.restoreResults))
+	copy(result, tp.restoreResults)
+	return result
+}
+
 // unMarshallTxDescFromDatabase - convert tx data in mempool database persistence into TxDesc
 func unMarshallTxDescFromDatabase(txType string, valueTx []byte, valueDesc []byte) (*TxDesc, error) {
 	txDesc := TxDesc{}
@@ -170,6 +363,8 @@ func unMarshallTxDescFromDatabase(txType string, valueTx []byte, valueDesc []byt
 			}
 			txDesc.Desc.Tx = &customTokenPrivacyTx
 		}
+	default:
+		return nil, fmt.Errorf("unknown tx type %+v", txType)
 	}
 	tempDesc := TempDesc{}
 	err := json.Unmarshal(valueDesc, &tempDesc)
@@ -92,21 +92,26 @@ func (shardPool *ShardPool) Start(cQuit chan struct{}) {
 
 func getShardPool(shardID byte) *ShardPool {
 	if shardPoolMap[shardID] == nil {
-		shardPool := new(ShardPool)
-		shardPool.shardID = shardID
-		shardPool.latestValidHeight = 1
-		shardPool.RoleInCommittees = -1
-		shardPool.validPool = []*blockchain.ShardBlock{}
-		shardPool.conflictedPool = make(map[common.Hash]*blockchain.ShardBlock)
-		shardPool.config = defaultConfig
-		shardPool.pendingPool = make(map[uint64]*blockchain.ShardBlock)
-		shardPool.cache, _ = lru.New(shardPool.config.CacheSize)
-		shardPool.mtx = new(sync.RWMutex)
-		shardPoolMap[shardID] = shardPool
+		shardPoolMap[shardID] = NewShardPool(shardID)
 	}
 	return shardPoolMap[shardID]
 }
 
+// NewShardPool returns an empty pool of the blocks of shardID which is not the singleton instance
+func NewShardPool(shardID byte) *ShardPool {
+	shardPool := new(ShardPool)
+	shardPool.shardID = shardID
+	shardPool.latestValidHeight = 1
+	shardPool.RoleInCommittees = -1
+	shardPool.validPool = []*blockchain.ShardBlock{}
+	shardPool.conflictedPool = make(map[common.Hash]*blockchain.ShardBlock)
+	shardPool.config = defaultConfig
+	shardPool.pendingPool = make(map[uint64]*blockchain.ShardBlock)
+	shardPool.cache, _ = lru.New(shardPool.config.CacheSize)
+	shardPool.mtx = new(sync.RWMutex)
+	return shardPool
+}
+
 // get singleton instance of Shard Pool with lock
 func GetShardPool(shardID byte) *ShardPool {
 	shardPoolMapMu.Lock()
@@ -45,21 +45,27 @@ func InitShardToBeaconPool() {
 // get singleton instance of ShardToBeacon pool
 func GetShardToBeaconPool() *ShardToBeaconPool {
 	if shardToBeaconPool == nil {
-		shardToBeaconPool = new(ShardToBeaconPool)
-		shardToBeaconPool.pool = make(map[byte][]*blockchain.ShardToBeaconBlock)
-		// add to pool
-		for i := 0; i < 255; i++ {
-			shardID := byte(i)
-			if shardToBeaconPool.pool[shardID] == nil {
-				shardToBeaconPool.pool[shardID] = []*blockchain.ShardToBeaconBlock{}
-			}
-		}
-		shardToBeaconPool.mtx = new(sync.RWMutex)
-		shardToBeaconPool.latestValidHeight = make(map[byte]uint64)
-		shardToBeaconPool.latestValidHeightMutex = new(sync.RWMutex)
+		shardToBeaconPool = NewShardToBeaconPool()
 	}
 	return shardToBeaconPool
 }
+
+// NewShardToBeaconPool returns an empty shard to beacon pool which is not the singleton instance
+func NewShardToBeaconPool() *ShardToBeaconPool {
+	pool := new(ShardToBeaconPool)
+	pool.pool = make(map[byte][]*blockchain.ShardToBeaconBlock)
+	// add to pool
+	for i := 0; i < 255; i++ {
+		shardID := byte(i)
+		if pool.pool[shardID] == nil {
+			pool.pool[shardID] = []*blockchain.ShardToBeaconBlock{}
+		}
+	}
+	pool.mtx = new(sync.RWMutex)
+	pool.latestValidHeight = make(map[byte]uint64)
+	pool.latestValidHeightMutex = new(sync.RWMutex)
+	return pool
+}
 func (shardToBeaconPool *ShardToBeaconPool) RevertShardToBeaconPool(shardID byte, latestValidHeight uint64) {
 	shardToBeaconPool.mtx.Lock()
 	defer shardToBeaconPool.mtx.Unlock()
@@ -0,0 +1,293 @@
+package mempool
+
+import (
+	"sort"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/privacy/zeroknowledge"
+	"github.com/incognitochain/incognito-chain/transaction"
+)
+
+/*
+In-pool parent/child relationships (chained spends):
+- A parent tx has an output coin which is spent by a child tx in pool, matched by coin commitment of the same token
+- Only no privacy input coins reveal their commitment, so only they can spend outputs of txs in pool
+- Cross shard outputs are not spendable in pool, they only exist in receiver shard after cross shard block
+- Txs are selected for a new block as packages: a tx comes with all of its ancestors,
+ordered by aggregate fee rate of the package so a child with high fee can pay for its parents
+*/
+
+// poolCommitmentDatabase - database of blockchain which also has output commitments of txs in pool,
+// used to validate a tx spending outputs of its parents in pool
+type poolCommitmentDatabase struct {
+	database.DatabaseInterface
+	tp *TxPool
+}
+
+// HasCommitment - commitment exists in blockchain or is an output of a tx in pool
+func (db poolCommitmentDatabase) HasCommitment(tokenID common.Hash, commitment []byte, shardID byte) (bool, error) {
+	if _, ok := db.tp.poolOutputCommitments[hashCommitment(tokenID, commitment)]; ok {
+		return true, nil
+	}
+	return db.DatabaseInterface.HasCommitment(tokenID, commitment, shardID)
+}
+
+func (tp *TxPool) commitmentDatabase() database.DatabaseInterface {
+	if tp.config.DataBase == nil {
+		return nil
+	}
+	return poolCommitmentDatabase{DatabaseInterface: tp.config.DataBase, tp: tp}
+}
+
+func hashCommitment(tokenID common.Hash, commitment []byte) common.Hash {
+	return common.HashH(append(tokenID[:], commitment...))
+}
+
+// listProofs - return payment proofs of tx with their token id
+func listProofs(tx metadata.Transaction) map[common.Hash]*zkp.PaymentProof {
+	proofs := make(map[common.Hash]*zkp.PaymentProof)
+	if tx.GetProof() != nil {
+		proofs[common.PRVCoinID] = tx.GetProof()
+	}
+	if tx.GetType() == common.TxCustomTokenPrivacyType {
+		txCustomTokenPrivacy, ok := tx.(*transaction.TxCustomTokenPrivacy)
+		if ok && txCustomTokenPrivacy.TxPrivacyTokenData.TxNormal.Proof != nil {
+			proofs[txCustomTokenPrivacy.TxPrivacyTokenData.PropertyID] = txCustomTokenPrivacy.TxPrivacyTokenData.TxNormal.Proof
+		}
+	}
+	return proofs
+}
+
+// listOutputCommitments - return commitment hash of output coins which stay in shard of tx sender
+func listOutputCommitments(tx metadata.Transaction) []common.Hash {
+	shardID := common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte())
+	result := []common.Hash{}
+	for tokenID, proof := range listProofs(tx) {
+		for _, outputCoin := range proof.GetOutputCoins() {
+			if outputCoin.CoinDetails == nil || outputCoin.CoinDetails.GetCoinCommitment() == nil || outputCoin.CoinDetails.GetPublicKey() == nil {
+				continue
+			}
+			if common.GetShardIDFromLastByte(outputCoin.CoinDetails.GetPubKeyLastByte()) != shardID {
+				continue
+			}
+			result = append(result, hashCommitment(tokenID, outputCoin.CoinDetails.GetCoinCommitment().ToBytesS()))
+		}
+	}
+	return result
+}
+
+// listInputCommitments - return commitment hash of input coins which reveal their commitment
+func listInputCommitments(tx metadata.Transaction) []common.Hash {
+	result := []common.Hash{}
+	for tokenID, proof := range listProofs(tx) {
+		for _, inputCoin := range proof.GetInputCoins() {
+			if inputCoin.CoinDetails == nil || inputCoin.CoinDetails.GetCoinCommitment() == nil {
+				continue
+			}
+			if inputCoin.CoinDetails.GetCoinCommitment().IsIdentity() {
+				continue
+			}
+			result = append(result, hashCommitment(tokenID, inputCoin.CoinDetails.GetCoinCommitment().ToBytesS()))
+		}
+	}
+	return result
+}
+
+// findParents - return hash of txs in pool which have outputs spent by tx
+func (tp *TxPool) findParents(tx metadata.Transaction) []common.Hash {
+	parents := make(map[common.Hash]struct{})
+	for _, commitmentHash := range listInputCommitments(tx) {
+		if parentHash, ok := tp.poolOutputCommitments[commitmentHash]; ok {
+			if _, ok := tp.pool[parentHash]; ok {
+				parents[parentHash] = struct{}{}
+			}
+		}
+	}
+	result := []common.Hash{}
+	for parentHash := range parents {
+		result = append(result, parentHash)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+// findAncestors - return all ancestors in pool of txs with these parents, parents come before their children
+func (tp *TxPool) findAncestors(parents []common.Hash) []*TxDesc {
+	ancestors := []*TxDesc{}
+	visited := make(map[common.Hash]struct{})
+	var visit func(txHash common.Hash)
+	visit = func(txHash common.Hash) {
+		if _, ok := visited[txHash]; ok {
+			return
+		}
+		visited[txHash] = struct{}{}
+		txDesc, ok := tp.pool[txHash]
+		if !ok {
+			return
+		}
+		for _, parentHash := range txDesc.Parents {
+			visit(parentHash)
+		}
+		ancestors = append(ancestors, txDesc)
+	}
+	for _, parentHash := range parents {
+		visit(parentHash)
+	}
+	return ancestors
+}
+
+// findDescendants - return all descendants in pool of a tx
+func (tp *TxPool) findDescendants(txHash common.Hash) []*TxDesc {
+	descendants := []*TxDesc{}
+	visited := map[common.Hash]struct{}{txHash: {}}
+	queue := []common.Hash{txHash}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, childHash := range tp.poolChildren[current] {
+			if _, ok := visited[childHash]; ok {
+				continue
+			}
+			visited[childHash] = struct{}{}
+			if childDesc, ok := tp.pool[childHash]; ok {
+				descendants = append(descendants, childDesc)
+				queue = append(queue, childHash)
+			}
+		}
+	}
+	return descendants
+}
+
+// withDescendants - return txs and all of their descendants in pool
+func (tp *TxPool) withDescendants(txs []metadata.Transaction) []metadata.Transaction {
+	added := make(map[common.Hash]struct{})
+	for _, tx := range txs {
+		added[*tx.Hash()] = struct{}{}
+	}
+	result := append([]metadata.Transaction{}, txs...)
+	for _, tx := range txs {
+		for _, txDesc := range tp.findDescendants(*tx.Hash()) {
+			txHash := *txDesc.Desc.Tx.Hash()
+			if _, ok := added[txHash]; !ok {
+				added[txHash] = struct{}{}
+				result = append(result, txDesc.Desc.Tx)
+			}
+		}
+	}
+	return result
+}
+
+// withDescendantDescs - return txs and all of their descendants in pool
+func (tp *TxPool) withDescendantDescs(txDescs []*TxDesc) []*TxDesc {
+	txs := []metadata.Transaction{}
+	for _, txDesc := range txDescs {
+		txs = append(txs, txDesc.Desc.Tx)
+	}
+	result := append([]*TxDesc{}, txDescs...)
+	for _, tx := range tp.withDescendants(txs)[len(txs):] {
+		if txDesc, ok := tp.pool[*tx.Hash()]; ok {
+			result = append(result, txDesc)
+		}
+	}
+	return result
+}
+
+// addTxRelations - index outputs of tx and link it to its parents in pool
+func (tp *TxPool) addTxRelations(txD *TxDesc) {
+	txHash := *txD.Desc.Tx.Hash()
+	for _, commitmentHash := range listOutputCommitments(txD.Desc.Tx) {
+		tp.poolOutputCommitments[commitmentHash] = txHash
+	}
+	txD.Parents = tp.findParents(txD.Desc.Tx)
+	for _, parentHash := range txD.Parents {
+		tp.poolChildren[parentHash] = append(tp.poolChildren[parentHash], txHash)
+	}
+}
+
+// removeTxRelations - remove outputs of tx out of index and unlink it from its parents and children,
+// children stay in pool because outputs of a tx removed by a new block are now in blockchain
+func (tp *TxPool) removeTxRelations(txD *TxDesc) {
+	txHash := *txD.Desc.Tx.Hash()
+	for _, commitmentHash := range listOutputCommitments(txD.Desc.Tx) {
+		if tp.poolOutputCommitments[commitmentHash] == txHash {
+			delete(tp.poolOutputCommitments, commitmentHash)
+		}
+	}
+	for _, parentHash := range txD.Parents {
+		tp.poolChildren[parentHash] = removeHash(tp.poolChildren[parentHash], txHash)
+		if len(tp.poolChildren[parentHash]) == 0 {
+			delete(tp.poolChildren, parentHash)
+		}
+	}
+	for _, childHash := range tp.poolChildren[txHash] {
+		if childDesc, ok := tp.pool[childHash]; ok {
+			childDesc.Parents = removeHash(childDesc.Parents, txHash)
+		}
+	}
+	delete(tp.poolChildren, txHash)
+}
+
+func removeHash(hashes []common.Hash, hash common.Hash) []common.Hash {
+	result := []common.Hash{}
+	for _, h := range hashes {
+		if !h.IsEqual(&hash) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// packageFeeRate - aggregate fee rate of a tx with its ancestors
+func packageFeeRate(txDesc *TxDesc, ancestors []*TxDesc) CoinPerKilobyte {
+	size := txDesc.Desc.Tx.GetTxActualSize()
+	fee := uint64(txDesc.FeeRate) * size
+	for _, ancestor := range ancestors {
+		ancestorSize := ancestor.Desc.Tx.GetTxActualSize()
+		size += ancestorSize
+		fee += uint64(ancestor.FeeRate) * ancestorSize
+	}
+	if size == 0 {
+		return CoinPerKilobyte(fee)
+	}
+	return NewCoinPerKilobyte(fee, size)
+}
+
+// listPackages - return txs in pool ordered for a new block: packages of a tx with its ancestors from the highest aggregate fee rate,
+// ancestors come right before the first tx needing them
+func (tp *TxPool) listPackages() []*TxDesc {
+	type txPackage struct {
+		txDesc    *TxDesc
+		ancestors []*TxDesc
+		feeRate   CoinPerKilobyte
+	}
+	txDescs := tp.poolFeeRate.list()
+	packages := make([]txPackage, 0, len(txDescs))
+	for _, txDesc := range txDescs {
+		ancestors := tp.findAncestors(txDesc.Parents)
+		packages = append(packages, txPackage{
+			txDesc:    txDesc,
+			ancestors: ancestors,
+			feeRate:   packageFeeRate(txDesc, ancestors),
+		})
+	}
+	// keep fee rate index order of txs with the same package fee rate
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packages[i].feeRate > packages[j].feeRate
+	})
+	result := make([]*TxDesc, 0, len(txDescs))
+	added := make(map[common.Hash]struct{})
+	for _, txPackage := range packages {
+		for _, txDesc := range append(txPackage.ancestors, txPackage.txDesc) {
+			txHash := *txDesc.Desc.Tx.Hash()
+			if _, ok := added[txHash]; !ok {
+				added[txHash] = struct{}{}
+				result = append(result, txDesc)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func listTxDescHashes(txDescs []*TxDesc) []common.Hash {
+	hashes := []common.Hash{}
+	for _, txDesc := range txDescs {
+		hashes = append(hashes, *txDesc.Desc.Tx.Hash())
+	}
+	return hashes
+}
+
+func TestTxPoolFindParentsAndAncestors(t *testing.T) {
+	ResetMempoolTest()
+	a := newFakeTx("a", 100, 10, []string{"sn1"})
+	b := newFakeTx("b", 100, 10, []string{"sn2"}, a)
+	d := newFakeTx("d", 100, 10, []string{"sn3"})
+	addFakeTxs(t, a, b, d)
+
+	c := newFakeTx("c", 100, 10, []string{"sn4"}, b, d)
+	parents := tp.findParents(c)
+	assert.ElementsMatch(t, []common.Hash{b.hash, d.hash}, parents)
+	assert.True(t, parents[0].String() < parents[1].String())
+	ancestors := listTxDescHashes(tp.findAncestors(parents))
+	assert.ElementsMatch(t, []common.Hash{a.hash, b.hash, d.hash}, ancestors)
+	// parents come before their children
+	for i, txHash := range ancestors {
+		if txHash.IsEqual(&b.hash) {
+			assert.Contains(t, ancestors[:i], a.hash)
+		}
+	}
+	assert.Equal(t, []common.Hash{a.hash}, tp.pool[b.hash].Parents)
+	assert.Empty(t, tp.findParents(newFakeTx("e", 100, 10, []string{"sn5"})))
+
+	// outputs of a tx removed from pool are not spent in pool any more
+	tp.removeTx(a)
+	assert.Empty(t, tp.findParents(newFakeTx("e", 100, 10, []string{"sn5"}, a)))
+	assert.Empty(t, tp.pool[b.hash].Parents)
+	assert.True(t, tp.isTxInPool(&b.hash))
+}
+
+func TestTxPoolEvictTx(t *testing.T) {
+	ResetMempoolTest()
+	a := newFakeTx("a", 100, 10, []string{"sn1"})
+	b := newFakeTx("b", 100, 10, []string{"sn2"}, a)
+	c := newFakeTx("c", 100, 10, []string{"sn3"}, b)
+	d := newFakeTx("d", 100, 10, []string{"sn4"})
+	e := newFakeTx("e", 100, 10, []string{"sn5"}, b, d)
+	addFakeTxs(t, a, b, c, d, e)
+
+	// descendants are evicted with their ancestor
+	tp.evictTx(tp.pool[a.hash])
+	assert.Equal(t, 1, len(tp.pool))
+	assert.True(t, tp.isTxInPool(&d.hash))
+	assert.Equal(t, uint64(10), tp.poolSize)
+	assert.Empty(t, tp.poolChildren)
+	assert.Equal(t, 1, len(tp.poolOutputCommitments))
+	assert.Equal(t, []common.Hash{d.hash}, listTxDescHashes(tp.poolFeeRate.list()))
+}
+
+func TestTxPoolListPackages(t *testing.T) {
+	ResetMempoolTest()
+	parent := newFakeTx("parent", 10, 10, []string{"sn1"})
+	child := newFakeTx("child", 1000, 10, []string{"sn2"}, parent)
+	other := newFakeTx("other", 500, 10, []string{"sn3"})
+	low := newFakeTx("low", 100, 10, []string{"sn4"})
+	addFakeTxs(t, parent, child, other, low)
+
+	// child pays for its parent: package fee rate is (10 + 1000) / 20 which is higher than 500 / 10
+	assert.Equal(t, []common.Hash{parent.hash, child.hash, other.hash, low.hash}, listTxDescHashes(tp.listPackages()))
+
+	tp.evictTx(tp.pool[child.hash])
+	assert.Equal(t, []common.Hash{other.hash, low.hash, parent.hash}, listTxDescHashes(tp.listPackages()))
+}
+
+func TestTxPoolAcceptTxDescMaxSizeAncestor(t *testing.T) {
+	ResetMempoolTest()
+	defer func(maxSize uint64) {
+		tp.config.MaxSize = maxSize
+	}(tp.config.MaxSize)
+	tp.config.MaxSize = 20
+	parent := newFakeTx("parent", 10, 10, []string{"sn1"})
+	other := newFakeTx("other", 200, 10, []string{"sn2"})
+	addFakeTxs(t, parent, other)
+
+	// parent has the lowest fee rate but child spends its output
+	child := newFakeTx("child", 1000, 10, []string{"sn3"}, parent)
+	assert.Nil(t, tp.acceptTxDesc(newFakeTxDesc(child), false, true))
+	assert.ElementsMatch(t, []common.Hash{parent.hash, child.hash}, listTxDescHashes(tp.poolFeeRate.list()))
+
+	// only ancestors have a lower fee rate
+	ResetMempoolTest()
+	addFakeTxs(t, parent, newFakeTx("other", 2000, 10, []string{"sn2"}))
+	err := tp.acceptTxDesc(newFakeTxDesc(child), false, true)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeMessage[MaxPoolSizeError].Code, err.(*MempoolTxError).Code)
+	}
+	assert.Equal(t, 2, len(tp.pool))
+	assert.True(t, tp.isTxInPool(&parent.hash))
+}
@@ -63,12 +63,38 @@ func ParseMetadata(meta interface{}) (Metadata, error) {
 		md = &PDETradeRequest{}
 	case PDETradeResponseMeta:
 		md = &PDETradeResponse{}
+	case PDEMultiHopTradeRequestMeta:
+		md = &PDEMultiHopTradeRequest{}
+	case PDELimitOrderRequestMeta:
+		md = &PDELimitOrderRequest{}
+	case PDECancelLimitOrderRequestMeta:
+		md = &PDECancelLimitOrderRequest{}
 	case PDEWithdrawalRequestMeta:
 		md = &PDEWithdrawalRequest{}
 	case PDEWithdrawalResponseMeta:
 		md = &PDEWithdrawalResponse{}
 	case PDEContributionResponseMeta:
 		md = &PDEContributionResponse{}
+	case PortalCustodianDepositMeta:
+		md = &PortalCustodianDeposit{}
+	case PortalUserRegisterMeta:
+		md = &PortalUserRegister{}
+	case PortalUserRegisterResponseMeta:
+		md = &PortalUserRegisterResponse{}
+	case PortalUserRequestPTokenMeta:
+		md = &PortalUserRequestPToken{}
+	case PortalUserRequestPTokenResponseMeta:
+		md = &PortalUserRequestPTokenResponse{}
+	case PortalRedeemRequestMeta:
+		md = &PortalRedeemRequest{}
+	case PortalRedeemRequestResponseMeta:
+		md = &PortalRedeemRequestResponse{}
+	case PortalRequestUnlockCollateralMeta:
+		md = &PortalRequestUnlockCollateral{}
+	case PortalLiquidateCustodianResponseMeta:
+		md = &PortalLiquidateCustodianResponse{}
+	case PortalRelayingBTCHeaderMeta:
+		md = &PortalRelayingBTCHeader{}
 	default:
 		Logger.log.Debug("[db] parse meta err: %+v\n", meta)
 		return nil, errors.Errorf("Could not parse metadata with type: %d", int(mtTemp["Type"].(float64)))
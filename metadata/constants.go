@@ -34,12 +34,28 @@ const (
 	BurningConfirmMeta    = 72
 
 	// pde
-	PDEContributionMeta         = 90
-	PDETradeRequestMeta         = 91
-	PDETradeResponseMeta        = 92
-	PDEWithdrawalRequestMeta    = 93
-	PDEWithdrawalResponseMeta   = 94
-	PDEContributionResponseMeta = 95
+	PDEContributionMeta            = 90
+	PDETradeRequestMeta            = 91
+	PDETradeResponseMeta           = 92
+	PDEWithdrawalRequestMeta       = 93
+	PDEWithdrawalResponseMeta      = 94
+	PDEContributionResponseMeta    = 95
+	PDEMultiHopTradeRequestMeta    = 96
+	PDELimitOrderRequestMeta       = 97
+	PDECancelLimitOrderRequestMeta = 98
+
+	// portal
+	PortalCustodianDepositMeta           = 100
+	PortalUserRegisterMeta               = 101
+	PortalUserRegisterResponseMeta       = 102
+	PortalUserRequestPTokenMeta          = 103
+	PortalUserRequestPTokenResponseMeta  = 104
+	PortalRedeemRequestMeta              = 105
+	PortalRedeemRequestResponseMeta      = 106
+	PortalRequestUnlockCollateralMeta    = 107
+	PortalLiquidateCustodianMeta         = 108
+	PortalLiquidateCustodianResponseMeta = 109
+	PortalRelayingBTCHeaderMeta          = 110
 )
 
 var minerCreatedMetaTypes = []int{
@@ -52,6 +68,10 @@ var minerCreatedMetaTypes = []int{
 	PDETradeResponseMeta,
 	PDEWithdrawalResponseMeta,
 	PDEContributionResponseMeta,
+	PortalUserRegisterResponseMeta,
+	PortalUserRequestPTokenResponseMeta,
+	PortalRedeemRequestResponseMeta,
+	PortalLiquidateCustodianResponseMeta,
 }
 
 // Special rules for shardID: stored as 2nd param of instruction of BeaconBlock
@@ -76,3 +96,6 @@ var (
 const (
 	StopAutoStakingAmount = 0
 )
+
+// MaxPDETradePathLength is the maximum number of tokens on the path of a multi-hop trade
+const MaxPDETradePathLength = 4
@@ -41,6 +41,16 @@ const (
 	PDEWithdrawalRequestFromMapError
 	CouldNotGetExchangeRateError
 	RejectInvalidFee
+	PDEMultiHopTradeRequestParamError
+	PDELimitOrderRequestParamError
+	PDECancelLimitOrderRequestParamError
+
+	// portal
+	PortalRequestPTokenParamError
+	PortalRedeemRequestParamError
+	PortalVerifyExternalTxError
+	PortalBuildReqActionsError
+	PortalRelayingBTCHeaderParamError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -83,9 +93,19 @@ var ErrCodeMessage = map[int]struct {
 	WrongIncognitoDAOPaymentAddressError: {-5001, "Invalid dev account"},
 
 	// pde
-	PDEWithdrawalRequestFromMapError: {-6001, "PDE withdrawal request Error"},
-	CouldNotGetExchangeRateError:     {-6002, "Could not get the exchange rate error"},
-	RejectInvalidFee:                 {-6003, "Reject invalid fee"},
+	PDEWithdrawalRequestFromMapError:     {-6001, "PDE withdrawal request Error"},
+	CouldNotGetExchangeRateError:         {-6002, "Could not get the exchange rate error"},
+	RejectInvalidFee:                     {-6003, "Reject invalid fee"},
+	PDEMultiHopTradeRequestParamError:    {-6004, "PDE multi-hop trade request param error"},
+	PDELimitOrderRequestParamError:       {-6005, "PDE limit order request param error"},
+	PDECancelLimitOrderRequestParamError: {-6006, "PDE cancel limit order request param error"},
+
+	// portal
+	PortalRequestPTokenParamError:     {-7001, "Portal request ptoken param error"},
+	PortalRedeemRequestParamError:     {-7002, "Portal redeem request param error"},
+	PortalVerifyExternalTxError:       {-7003, "Portal verify external tx error"},
+	PortalBuildReqActionsError:        {-7004, "Portal build request action error"},
+	PortalRelayingBTCHeaderParamError: {-7005, "Portal relaying btc header param error"},
 }
 
 type MetadataTxError struct {
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PDECancelLimitOrderRequest - cancel a resting limit order, the remaining selling amount
+// is refunded to the trader through PDETradeResponse
+type PDECancelLimitOrderRequest struct {
+	OrderID          string // tx id of the limit order request
+	TraderAddressStr string
+	MetadataBase
+}
+
+type PDECancelLimitOrderRequestAction struct {
+	Meta    PDECancelLimitOrderRequest
+	TxReqID common.Hash
+	ShardID byte
+}
+
+func NewPDECancelLimitOrderRequest(
+	orderID string,
+	traderAddressStr string,
+	metaType int,
+) (*PDECancelLimitOrderRequest, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	pdeCancelLimitOrderRequest := &PDECancelLimitOrderRequest{
+		OrderID:          orderID,
+		TraderAddressStr: traderAddressStr,
+	}
+	pdeCancelLimitOrderRequest.MetadataBase = metadataBase
+	return pdeCancelLimitOrderRequest, nil
+}
+
+func (pc PDECancelLimitOrderRequest) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	// NOTE: existence and ownership of the order are verified on beacon chain
+	return true, nil
+}
+
+func (pc PDECancelLimitOrderRequest) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(pc.TraderAddressStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDECancelLimitOrderRequestParamError, errors.New("TraderAddressStr incorrect"))
+	}
+	traderAddr := keyWallet.KeySet.PaymentAddress
+	if len(traderAddr.Pk) == 0 {
+		return false, false, errors.New("Wrong request info's trader address")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], traderAddr.Pk[:]) {
+		return false, false, errors.New("TraderAddress incorrect")
+	}
+	_, err = common.Hash{}.NewHashFromStr(pc.OrderID)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDECancelLimitOrderRequestParamError, errors.New("OrderID incorrect"))
+	}
+	return true, true, nil
+}
+
+func (pc PDECancelLimitOrderRequest) ValidateMetadataByItself() bool {
+	return pc.Type == PDECancelLimitOrderRequestMeta
+}
+
+func (pc PDECancelLimitOrderRequest) Hash() *common.Hash {
+	record := pc.MetadataBase.Hash().String()
+	record += pc.OrderID
+	record += pc.TraderAddressStr
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (pc *PDECancelLimitOrderRequest) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PDECancelLimitOrderRequestAction{
+		Meta:    *pc,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PDECancelLimitOrderRequestMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (pc *PDECancelLimitOrderRequest) CalculateSize() uint64 {
+	return calculateSize(pc)
+}
+
+// ParsePDECancelLimitOrderRequestAction parses the base64 content of a limit order cancellation action
+func ParsePDECancelLimitOrderRequestAction(contentStr string) (*PDECancelLimitOrderRequestAction, error) {
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		return nil, err
+	}
+	var pdeCancelLimitOrderRequestAction PDECancelLimitOrderRequestAction
+	err = json.Unmarshal(contentBytes, &pdeCancelLimitOrderRequestAction)
+	if err != nil {
+		return nil, err
+	}
+	return &pdeCancelLimitOrderRequestAction, nil
+}
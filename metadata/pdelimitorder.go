@@ -0,0 +1,191 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PDELimitOrderRequest - privacy dex limit order, it rests in the order book of beacon chain
+// until SellAmount can be sold for at least MinAcceptableAmount or it is cancelled by the trader
+type PDELimitOrderRequest struct {
+	TokenIDToBuyStr     string
+	TokenIDToSellStr    string
+	SellAmount          uint64 // must be equal to vout value
+	MinAcceptableAmount uint64
+	TradingFee          uint64
+	TraderAddressStr    string
+	MetadataBase
+}
+
+type PDELimitOrderRequestAction struct {
+	Meta    PDELimitOrderRequest
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PDELimitOrderFilledContent is the content of an instruction filling a limit order,
+// pool value operations are applied to the pool pair of the order
+type PDELimitOrderFilledContent struct {
+	OrderID                  string
+	TraderAddressStr         string
+	TokenIDToBuyStr          string
+	ReceiveAmount            uint64
+	Token1IDStr              string
+	Token2IDStr              string
+	Token1PoolValueOperation TokenPoolValueOperation
+	Token2PoolValueOperation TokenPoolValueOperation
+	ProtocolFee              uint64 `json:",omitempty"` // taken from the selling amount when the order is filled by the pool pair, only emitted from the protocol fee activation height
+	ShardID                  byte
+	RequestedTxID            common.Hash
+}
+
+// PDELimitOrderRefundContent is the content of an instruction refunding a rejected or cancelled limit order
+type PDELimitOrderRefundContent struct {
+	OrderID          string
+	TraderAddressStr string
+	TokenIDStr       string
+	Amount           uint64
+	ShardID          byte
+	RequestedTxID    common.Hash
+}
+
+func NewPDELimitOrderRequest(
+	tokenIDToBuyStr string,
+	tokenIDToSellStr string,
+	sellAmount uint64,
+	minAcceptableAmount uint64,
+	tradingFee uint64,
+	traderAddressStr string,
+	metaType int,
+) (*PDELimitOrderRequest, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	pdeLimitOrderRequest := &PDELimitOrderRequest{
+		TokenIDToBuyStr:     tokenIDToBuyStr,
+		TokenIDToSellStr:    tokenIDToSellStr,
+		SellAmount:          sellAmount,
+		MinAcceptableAmount: minAcceptableAmount,
+		TradingFee:          tradingFee,
+		TraderAddressStr:    traderAddressStr,
+	}
+	pdeLimitOrderRequest.MetadataBase = metadataBase
+	return pdeLimitOrderRequest, nil
+}
+
+func (pc PDELimitOrderRequest) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	// NOTE: existence of the pool pair is verified on beacon chain
+	return true, nil
+}
+
+func (pc PDELimitOrderRequest) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	// Note: the metadata was already verified with *transaction.TxCustomToken level so no need to verify with *transaction.Tx level again as *transaction.Tx is embedding property of *transaction.TxCustomToken
+	if txr.GetType() == common.TxCustomTokenPrivacyType && reflect.TypeOf(txr).String() == "*transaction.Tx" {
+		return true, true, nil
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(pc.TraderAddressStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDELimitOrderRequestParamError, errors.New("TraderAddressStr incorrect"))
+	}
+	traderAddr := keyWallet.KeySet.PaymentAddress
+	if len(traderAddr.Pk) == 0 {
+		return false, false, errors.New("Wrong request info's trader address")
+	}
+	if !txr.IsCoinsBurning(bcr) {
+		return false, false, errors.New("Must send coin to burning address")
+	}
+	if pc.SellAmount == 0 || pc.MinAcceptableAmount == 0 {
+		return false, false, NewMetadataTxError(PDELimitOrderRequestParamError, errors.New("SellAmount and MinAcceptableAmount should be larger than 0"))
+	}
+	if (pc.SellAmount + pc.TradingFee) != txr.CalculateTxValue() {
+		return false, false, errors.New("Total of selling amount and trading fee should be equal to the tx value")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], traderAddr.Pk[:]) {
+		return false, false, errors.New("TraderAddress incorrect")
+	}
+	_, err = common.Hash{}.NewHashFromStr(pc.TokenIDToBuyStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDELimitOrderRequestParamError, errors.New("TokenIDToBuyStr incorrect"))
+	}
+	tokenIDToSell, err := common.Hash{}.NewHashFromStr(pc.TokenIDToSellStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDELimitOrderRequestParamError, errors.New("TokenIDToSellStr incorrect"))
+	}
+	if pc.TokenIDToBuyStr == pc.TokenIDToSellStr {
+		return false, false, NewMetadataTxError(PDELimitOrderRequestParamError, errors.New("TokenIDToBuyStr should be different from TokenIDToSellStr"))
+	}
+	if !bytes.Equal(txr.GetTokenID()[:], tokenIDToSell[:]) {
+		return false, false, errors.New("Wrong request info's token id, it should be equal to tx's token id.")
+	}
+	if txr.GetType() == common.TxNormalType && pc.TokenIDToSellStr != common.PRVCoinID.String() {
+		return false, false, errors.New("With tx normal privacy, the tokenIDStr should be PRV, not custom token.")
+	}
+	if txr.GetType() == common.TxCustomTokenPrivacyType && pc.TokenIDToSellStr == common.PRVCoinID.String() {
+		return false, false, errors.New("With tx custome token privacy, the tokenIDStr should not be PRV, but custom token.")
+	}
+	return true, true, nil
+}
+
+func (pc PDELimitOrderRequest) ValidateMetadataByItself() bool {
+	return pc.Type == PDELimitOrderRequestMeta
+}
+
+func (pc PDELimitOrderRequest) Hash() *common.Hash {
+	record := pc.MetadataBase.Hash().String()
+	record += pc.TokenIDToBuyStr
+	record += pc.TokenIDToSellStr
+	record += pc.TraderAddressStr
+	record += strconv.FormatUint(pc.SellAmount, 10)
+	record += strconv.FormatUint(pc.MinAcceptableAmount, 10)
+	record += strconv.FormatUint(pc.TradingFee, 10)
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (pc *PDELimitOrderRequest) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PDELimitOrderRequestAction{
+		Meta:    *pc,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PDELimitOrderRequestMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (pc *PDELimitOrderRequest) CalculateSize() uint64 {
+	return calculateSize(pc)
+}
+
+// ParsePDELimitOrderRequestAction parses the base64 content of a limit order action
+func ParsePDELimitOrderRequestAction(contentStr string) (*PDELimitOrderRequestAction, error) {
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		return nil, err
+	}
+	var pdeLimitOrderRequestAction PDELimitOrderRequestAction
+	err = json.Unmarshal(contentBytes, &pdeLimitOrderRequestAction)
+	if err != nil {
+		return nil, err
+	}
+	return &pdeLimitOrderRequestAction, nil
+}
@@ -0,0 +1,193 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PDEMultiHopTradeRequest - privacy dex trade through an ordered path of pool pairs,
+// TradePath[0] is the token to sell and the last token of TradePath is the token to buy
+type PDEMultiHopTradeRequest struct {
+	TradePath           []string
+	SellAmount          uint64 // must be equal to vout value
+	MinAcceptableAmount uint64
+	TradingFee          uint64
+	TraderAddressStr    string
+	MetadataBase
+}
+
+type PDEMultiHopTradeRequestAction struct {
+	Meta    PDEMultiHopTradeRequest
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PDETradeHop describes changes of a pool pair made by a hop of a multi-hop trade
+type PDETradeHop struct {
+	TokenIDToSellStr         string
+	TokenIDToBuyStr          string
+	SellAmount               uint64
+	ReceiveAmount            uint64
+	Token1IDStr              string
+	Token2IDStr              string
+	Token1PoolValueOperation TokenPoolValueOperation
+	Token2PoolValueOperation TokenPoolValueOperation
+	ProtocolFee              uint64 `json:",omitempty"` // taken from the selling amount of the hop and paid to liquidity providers, only emitted from the protocol fee activation height
+}
+
+type PDEMultiHopTradeAcceptedContent struct {
+	TraderAddressStr string
+	TokenIDToBuyStr  string
+	ReceiveAmount    uint64
+	Hops             []PDETradeHop
+	ShardID          byte
+	RequestedTxID    common.Hash
+}
+
+func NewPDEMultiHopTradeRequest(
+	tradePath []string,
+	sellAmount uint64,
+	minAcceptableAmount uint64,
+	tradingFee uint64,
+	traderAddressStr string,
+	metaType int,
+) (*PDEMultiHopTradeRequest, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	pdeMultiHopTradeRequest := &PDEMultiHopTradeRequest{
+		TradePath:           tradePath,
+		SellAmount:          sellAmount,
+		MinAcceptableAmount: minAcceptableAmount,
+		TradingFee:          tradingFee,
+		TraderAddressStr:    traderAddressStr,
+	}
+	pdeMultiHopTradeRequest.MetadataBase = metadataBase
+	return pdeMultiHopTradeRequest, nil
+}
+
+func (pc PDEMultiHopTradeRequest) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	// NOTE: existence of pool pairs on the path is verified on beacon chain
+	return true, nil
+}
+
+func (pc PDEMultiHopTradeRequest) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	// Note: the metadata was already verified with *transaction.TxCustomToken level so no need to verify with *transaction.Tx level again as *transaction.Tx is embedding property of *transaction.TxCustomToken
+	if txr.GetType() == common.TxCustomTokenPrivacyType && reflect.TypeOf(txr).String() == "*transaction.Tx" {
+		return true, true, nil
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(pc.TraderAddressStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PDEMultiHopTradeRequestParamError, errors.New("TraderAddressStr incorrect"))
+	}
+	traderAddr := keyWallet.KeySet.PaymentAddress
+
+	if len(traderAddr.Pk) == 0 {
+		return false, false, errors.New("Wrong request info's trader address")
+	}
+	if !txr.IsCoinsBurning(bcr) {
+		return false, false, errors.New("Must send coin to burning address")
+	}
+	if (pc.SellAmount + pc.TradingFee) != txr.CalculateTxValue() {
+		return false, false, errors.New("Total of selling amount and trading fee should be equal to the tx value")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], traderAddr.Pk[:]) {
+		return false, false, errors.New("TraderAddress incorrect")
+	}
+
+	if len(pc.TradePath) < 2 || len(pc.TradePath) > MaxPDETradePathLength {
+		return false, false, NewMetadataTxError(PDEMultiHopTradeRequestParamError, errors.New("TradePath length is invalid"))
+	}
+	usedTokenIDs := make(map[string]bool)
+	for _, tokenIDStr := range pc.TradePath {
+		_, err = common.Hash{}.NewHashFromStr(tokenIDStr)
+		if err != nil {
+			return false, false, NewMetadataTxError(PDEMultiHopTradeRequestParamError, errors.New("TradePath contains an incorrect token id"))
+		}
+		// a token appears once on the path so that every pool pair is used at most once
+		if usedTokenIDs[tokenIDStr] {
+			return false, false, NewMetadataTxError(PDEMultiHopTradeRequestParamError, errors.New("TradePath contains a duplicated token id"))
+		}
+		usedTokenIDs[tokenIDStr] = true
+	}
+
+	tokenIDToSellStr := pc.TradePath[0]
+	tokenIDToSell, _ := common.Hash{}.NewHashFromStr(tokenIDToSellStr)
+	if !bytes.Equal(txr.GetTokenID()[:], tokenIDToSell[:]) {
+		return false, false, errors.New("Wrong request info's token id, it should be equal to tx's token id.")
+	}
+
+	if txr.GetType() == common.TxNormalType && tokenIDToSellStr != common.PRVCoinID.String() {
+		return false, false, errors.New("With tx normal privacy, the tokenIDStr should be PRV, not custom token.")
+	}
+
+	if txr.GetType() == common.TxCustomTokenPrivacyType && tokenIDToSellStr == common.PRVCoinID.String() {
+		return false, false, errors.New("With tx custome token privacy, the tokenIDStr should not be PRV, but custom token.")
+	}
+
+	return true, true, nil
+}
+
+func (pc PDEMultiHopTradeRequest) ValidateMetadataByItself() bool {
+	return pc.Type == PDEMultiHopTradeRequestMeta
+}
+
+func (pc PDEMultiHopTradeRequest) Hash() *common.Hash {
+	record := pc.MetadataBase.Hash().String()
+	record += strings.Join(pc.TradePath, "")
+	record += pc.TraderAddressStr
+	record += strconv.FormatUint(pc.SellAmount, 10)
+	record += strconv.FormatUint(pc.MinAcceptableAmount, 10)
+	record += strconv.FormatUint(pc.TradingFee, 10)
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (pc *PDEMultiHopTradeRequest) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PDEMultiHopTradeRequestAction{
+		Meta:    *pc,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PDEMultiHopTradeRequestMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (pc *PDEMultiHopTradeRequest) CalculateSize() uint64 {
+	return calculateSize(pc)
+}
+
+// ParsePDEMultiHopTradeRequestAction parses the content of a refunded multi-hop trade instruction
+func ParsePDEMultiHopTradeRequestAction(contentStr string) (*PDEMultiHopTradeRequestAction, error) {
+	contentBytes, err := base64.StdEncoding.DecodeString(contentStr)
+	if err != nil {
+		return nil, err
+	}
+	var pdeMultiHopTradeRequestAction PDEMultiHopTradeRequestAction
+	err = json.Unmarshal(contentBytes, &pdeMultiHopTradeRequestAction)
+	if err != nil {
+		return nil, err
+	}
+	return &pdeMultiHopTradeRequestAction, nil
+}
@@ -43,6 +43,7 @@ type PDETradeAcceptedContent struct {
 	Token2IDStr              string
 	Token1PoolValueOperation TokenPoolValueOperation
 	Token2PoolValueOperation TokenPoolValueOperation
+	ProtocolFee              uint64 `json:",omitempty"` // taken from the selling amount and paid to liquidity providers, only emitted from the protocol fee activation height
 	ShardID                  byte
 	RequestedTxID            common.Hash
 }
@@ -83,7 +83,9 @@ func (iRes PDETradeResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
 		}
 		instMetaType := inst[0]
 		if instUsed[i] > 0 ||
-			instMetaType != strconv.Itoa(PDETradeRequestMeta) {
+			(instMetaType != strconv.Itoa(PDETradeRequestMeta) &&
+				instMetaType != strconv.Itoa(PDEMultiHopTradeRequestMeta) &&
+				instMetaType != strconv.Itoa(PDELimitOrderRequestMeta)) {
 			continue
 		}
 		instTradeStatus := inst[2]
@@ -96,7 +98,54 @@ func (iRes PDETradeResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
 		var receiverAddrStrFromInst string
 		var receivingAmtFromInst uint64
 		var receivingTokenIDStr string
-		if instTradeStatus == common.PDETradeRefundChainStatus {
+		if instMetaType == strconv.Itoa(PDELimitOrderRequestMeta) && instTradeStatus == common.PDELimitOrderRefundChainStatus {
+			var refundContent PDELimitOrderRefundContent
+			err := json.Unmarshal([]byte(inst[3]), &refundContent)
+			if err != nil {
+				Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
+				continue
+			}
+			shardIDFromInst = refundContent.ShardID
+			txReqIDFromInst = refundContent.RequestedTxID
+			receiverAddrStrFromInst = refundContent.TraderAddressStr
+			receivingTokenIDStr = refundContent.TokenIDStr
+			receivingAmtFromInst = refundContent.Amount
+		} else if instMetaType == strconv.Itoa(PDELimitOrderRequestMeta) { // limit order filled
+			var filledContent PDELimitOrderFilledContent
+			err := json.Unmarshal([]byte(inst[3]), &filledContent)
+			if err != nil {
+				Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
+				continue
+			}
+			shardIDFromInst = filledContent.ShardID
+			txReqIDFromInst = filledContent.RequestedTxID
+			receiverAddrStrFromInst = filledContent.TraderAddressStr
+			receivingTokenIDStr = filledContent.TokenIDToBuyStr
+			receivingAmtFromInst = filledContent.ReceiveAmount
+		} else if instMetaType == strconv.Itoa(PDEMultiHopTradeRequestMeta) && instTradeStatus == common.PDETradeRefundChainStatus {
+			pdeMultiHopTradeRequestAction, err := ParsePDEMultiHopTradeRequestAction(inst[3])
+			if err != nil || len(pdeMultiHopTradeRequestAction.Meta.TradePath) == 0 {
+				Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
+				continue
+			}
+			shardIDFromInst = pdeMultiHopTradeRequestAction.ShardID
+			txReqIDFromInst = pdeMultiHopTradeRequestAction.TxReqID
+			receiverAddrStrFromInst = pdeMultiHopTradeRequestAction.Meta.TraderAddressStr
+			receivingTokenIDStr = pdeMultiHopTradeRequestAction.Meta.TradePath[0]
+			receivingAmtFromInst = pdeMultiHopTradeRequestAction.Meta.SellAmount + pdeMultiHopTradeRequestAction.Meta.TradingFee
+		} else if instMetaType == strconv.Itoa(PDEMultiHopTradeRequestMeta) { // multi-hop trade accepted
+			var pdeMultiHopTradeAcceptedContent PDEMultiHopTradeAcceptedContent
+			err := json.Unmarshal([]byte(inst[3]), &pdeMultiHopTradeAcceptedContent)
+			if err != nil {
+				Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
+				continue
+			}
+			shardIDFromInst = pdeMultiHopTradeAcceptedContent.ShardID
+			txReqIDFromInst = pdeMultiHopTradeAcceptedContent.RequestedTxID
+			receiverAddrStrFromInst = pdeMultiHopTradeAcceptedContent.TraderAddressStr
+			receivingTokenIDStr = pdeMultiHopTradeAcceptedContent.TokenIDToBuyStr
+			receivingAmtFromInst = pdeMultiHopTradeAcceptedContent.ReceiveAmount
+		} else if instTradeStatus == common.PDETradeRefundChainStatus {
 			contentBytes, err := base64.StdEncoding.DecodeString(inst[3])
 			if err != nil {
 				Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
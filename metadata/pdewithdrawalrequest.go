@@ -32,6 +32,7 @@ type PDEWithdrawalAcceptedContent struct {
 	WithdrawerAddressStr string
 	DeductingPoolValue   uint64
 	DeductingShares      uint64
+	TradingFeeAmt        uint64 `json:",omitempty"` // trading fees accrued to the withdrawer in the withdrawal token, only emitted from the protocol fee activation height
 	PairToken1IDStr      string
 	PairToken2IDStr      string
 	TxReqID              common.Hash
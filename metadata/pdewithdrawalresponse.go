@@ -15,11 +15,13 @@ type PDEWithdrawalResponse struct {
 	MetadataBase
 	RequestedTxID common.Hash
 	TokenIDStr    string
+	TradingFeeAmt uint64 `json:",omitempty"` // part of the returned amount coming from trading fees accrued to the withdrawer
 }
 
 func NewPDEWithdrawalResponse(
 	tokenIDStr string,
 	requestedTxID common.Hash,
+	tradingFeeAmt uint64,
 	metaType int,
 ) *PDEWithdrawalResponse {
 	metadataBase := MetadataBase{
@@ -28,6 +30,7 @@ func NewPDEWithdrawalResponse(
 	return &PDEWithdrawalResponse{
 		RequestedTxID: requestedTxID,
 		TokenIDStr:    tokenIDStr,
+		TradingFeeAmt: tradingFeeAmt,
 		MetadataBase:  metadataBase,
 	}
 }
@@ -54,6 +57,9 @@ func (iRes PDEWithdrawalResponse) ValidateMetadataByItself() bool {
 func (iRes PDEWithdrawalResponse) Hash() *common.Hash {
 	record := iRes.RequestedTxID.String()
 	record += iRes.TokenIDStr
+	if iRes.TradingFeeAmt > 0 {
+		record += strconv.FormatUint(iRes.TradingFeeAmt, 10)
+	}
 	record += iRes.MetadataBase.Hash().String()
 
 	// final hash
@@ -106,7 +112,8 @@ func (iRes PDEWithdrawalResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
 
 		_, pk, amount, assetID := tx.GetTransferData()
 		if !bytes.Equal(key.KeySet.PaymentAddress.Pk[:], pk[:]) ||
-			withdrawalAcceptedContent.DeductingPoolValue != amount ||
+			withdrawalAcceptedContent.DeductingPoolValue+withdrawalAcceptedContent.TradingFeeAmt != amount ||
+			withdrawalAcceptedContent.TradingFeeAmt != iRes.TradingFeeAmt ||
 			withdrawalAcceptedContent.WithdrawalTokenIDStr != assetID.String() {
 			continue
 		}
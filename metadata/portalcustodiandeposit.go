@@ -0,0 +1,158 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PortalCustodianDeposit - portal custodian deposits collateral (PRV)
+// metadata - custodian deposit - create normal tx with this metadata
+type PortalCustodianDeposit struct {
+	MetadataBase
+	IncogAddressStr string
+	RemoteAddresses map[string]string // tokenID : remote address
+	DepositedAmount uint64
+}
+
+// PortalCustodianDepositAction - shard validator creates instruction that contain this action content
+// it will be append to ShardToBeaconBlock
+type PortalCustodianDepositAction struct {
+	Meta    PortalCustodianDeposit
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalCustodianDepositContent - Beacon builds a new instruction with this content after receiving a instruction from shard
+// It will be appended to beaconBlock
+type PortalCustodianDepositContent struct {
+	IncogAddressStr string
+	RemoteAddresses map[string]string
+	DepositedAmount uint64
+	TxReqID         common.Hash
+	ShardID         byte
+}
+
+// PortalCustodianDepositStatus - Beacon tracks status of custodian deposit tx into db
+type PortalCustodianDepositStatus struct {
+	Status          byte
+	IncogAddressStr string
+	RemoteAddresses map[string]string
+	DepositedAmount uint64
+}
+
+func NewPortalCustodianDeposit(
+	metaType int,
+	incognitoAddrStr string,
+	remoteAddrs map[string]string,
+	amount uint64,
+) (*PortalCustodianDeposit, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	custodianDepositMeta := &PortalCustodianDeposit{
+		IncogAddressStr: incognitoAddrStr,
+		RemoteAddresses: remoteAddrs,
+		DepositedAmount: amount,
+	}
+	custodianDepositMeta.MetadataBase = metadataBase
+	return custodianDepositMeta, nil
+}
+
+func (custodianDeposit PortalCustodianDeposit) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	// NOTE: verify supported tokens pair as needed
+	return true, nil
+}
+
+func (custodianDeposit PortalCustodianDeposit) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	// Note: the metadata was already verified with *transaction.TxCustomToken level so no need to verify with *transaction.Tx level again as *transaction.Tx is embedding property of *transaction.TxCustomToken
+	if txr.GetType() == common.TxCustomTokenPrivacyType {
+		return false, false, errors.New("Custodian deposit tx should be a normal tx (PRV)")
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(custodianDeposit.IncogAddressStr)
+	if err != nil {
+		return false, false, errors.New("IncogAddressStr of custodian incorrect")
+	}
+	incogAddr := keyWallet.KeySet.PaymentAddress
+	if len(incogAddr.Pk) == 0 {
+		return false, false, errors.New("wrong custodian incognito address")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], incogAddr.Pk[:]) {
+		return false, false, errors.New("custodian incognito address is not signer tx")
+	}
+	if !txr.IsCoinsBurning(bcr) {
+		return false, false, errors.New("must send coin to burning address")
+	}
+	if custodianDeposit.DepositedAmount == 0 {
+		return false, false, errors.New("deposit amount should be larger than 0")
+	}
+	if custodianDeposit.DepositedAmount != txr.CalculateTxValue() {
+		return false, false, errors.New("deposit amount should be equal to the tx value")
+	}
+	if len(custodianDeposit.RemoteAddresses) == 0 {
+		return false, false, errors.New("remote addresses should be at least one")
+	}
+	for tokenID, remoteAddr := range custodianDeposit.RemoteAddresses {
+		if !IsPortalToken(tokenID) {
+			return false, false, errors.New("remote address is invalid")
+		}
+		if remoteAddr == "" {
+			return false, false, errors.New("remote address should not be empty")
+		}
+	}
+	return true, true, nil
+}
+
+func (custodianDeposit PortalCustodianDeposit) ValidateMetadataByItself() bool {
+	return custodianDeposit.Type == PortalCustodianDepositMeta
+}
+
+func (custodianDeposit PortalCustodianDeposit) Hash() *common.Hash {
+	record := custodianDeposit.MetadataBase.Hash().String()
+	record += custodianDeposit.IncogAddressStr
+	tokenIDKeys := make([]string, 0)
+	for tokenID := range custodianDeposit.RemoteAddresses {
+		tokenIDKeys = append(tokenIDKeys, tokenID)
+	}
+	sort.Strings(tokenIDKeys)
+	for _, tokenID := range tokenIDKeys {
+		record += tokenID
+		record += custodianDeposit.RemoteAddresses[tokenID]
+	}
+	record += strconv.FormatUint(custodianDeposit.DepositedAmount, 10)
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (custodianDeposit *PortalCustodianDeposit) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalCustodianDepositAction{
+		Meta:    *custodianDeposit,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalCustodianDepositMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (custodianDeposit *PortalCustodianDeposit) CalculateSize() uint64 {
+	return calculateSize(custodianDeposit)
+}
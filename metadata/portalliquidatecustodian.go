@@ -0,0 +1,131 @@
+package metadata
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+)
+
+// PortalLiquidateCustodianContent - beacon liquidates a custodian who did not send public tokens
+// to the redeemer in time, the collateral backing the redeem amount is paid to the redeemer (in PRV)
+type PortalLiquidateCustodianContent struct {
+	UniqueRedeemID         string
+	TokenID                string
+	RedeemAmount           uint64
+	MintedCollateralAmount uint64 // prv
+	RedeemerIncAddressStr  string
+	CustodianIncAddressStr string
+	ShardID                byte
+}
+
+type PortalLiquidateCustodianStatus struct {
+	Status                 byte
+	UniqueRedeemID         string
+	TokenID                string
+	RedeemAmount           uint64
+	MintedCollateralAmount uint64
+	RedeemerIncAddressStr  string
+	CustodianIncAddressStr string
+	BeaconHeight           uint64
+}
+
+// PortalLiquidateCustodianResponse - pays the liquidated collateral to the redeemer
+type PortalLiquidateCustodianResponse struct {
+	MetadataBase
+	UniqueRedeemID         string
+	MintedCollateralAmount uint64 // prv
+	RedeemerIncAddressStr  string
+	CustodianIncAddressStr string
+}
+
+func NewPortalLiquidateCustodianResponse(
+	uniqueRedeemID string,
+	mintedAmount uint64,
+	redeemerIncAddressStr string,
+	custodianIncAddressStr string,
+	metaType int,
+) *PortalLiquidateCustodianResponse {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	return &PortalLiquidateCustodianResponse{
+		MetadataBase:           metadataBase,
+		UniqueRedeemID:         uniqueRedeemID,
+		MintedCollateralAmount: mintedAmount,
+		RedeemerIncAddressStr:  redeemerIncAddressStr,
+		CustodianIncAddressStr: custodianIncAddressStr,
+	}
+}
+
+func (iRes PortalLiquidateCustodianResponse) CheckTransactionFee(tr Transaction, minFee uint64, beaconHeight int64, db database.DatabaseInterface) bool {
+	// no need to have fee for this tx
+	return true
+}
+
+func (iRes PortalLiquidateCustodianResponse) ValidateTxWithBlockChain(txr Transaction, bcr BlockchainRetriever, shardID byte, db database.DatabaseInterface) (bool, error) {
+	// no need to validate tx with blockchain, just need to validate with the liquidation instruction
+	return false, nil
+}
+
+func (iRes PortalLiquidateCustodianResponse) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	return false, true, nil
+}
+
+func (iRes PortalLiquidateCustodianResponse) ValidateMetadataByItself() bool {
+	// The validation just need to check at tx level, so returning true here
+	return iRes.Type == PortalLiquidateCustodianResponseMeta
+}
+
+func (iRes PortalLiquidateCustodianResponse) Hash() *common.Hash {
+	record := iRes.MetadataBase.Hash().String()
+	record += iRes.UniqueRedeemID
+	record += iRes.RedeemerIncAddressStr
+	record += iRes.CustodianIncAddressStr
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (iRes *PortalLiquidateCustodianResponse) CalculateSize() uint64 {
+	return calculateSize(iRes)
+}
+
+// VerifyMinerCreatedTxBeforeGettingInBlock matches the response with the liquidation instruction
+// by the liquidation key (unique redeem id + custodian address) instead of a requested tx id
+func (iRes PortalLiquidateCustodianResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
+	txsInBlock []Transaction,
+	txsUsed []int,
+	insts [][]string,
+	instUsed []int,
+	shardID byte,
+	tx Transaction,
+	bcr BlockchainRetriever,
+	ac *AccumulatedValues,
+) (bool, error) {
+	liquidationID := BuildPortalLiquidationID(iRes.UniqueRedeemID, iRes.CustodianIncAddressStr)
+	return verifyPortalResponseTx(
+		insts, instUsed, shardID, tx,
+		PortalLiquidateCustodianMeta,
+		common.PortalLiquidateCustodianSuccessChainStatus,
+		liquidationID,
+		func(content string) (common.Hash, byte, string, string, uint64, error) {
+			var liquidationContent PortalLiquidateCustodianContent
+			err := json.Unmarshal([]byte(content), &liquidationContent)
+			if err != nil {
+				return common.Hash{}, 0, "", "", 0, err
+			}
+			return BuildPortalLiquidationID(liquidationContent.UniqueRedeemID, liquidationContent.CustodianIncAddressStr),
+				liquidationContent.ShardID,
+				liquidationContent.RedeemerIncAddressStr,
+				common.PRVCoinID.String(),
+				liquidationContent.MintedCollateralAmount,
+				nil
+		},
+	)
+}
+
+// BuildPortalLiquidationID identifies a liquidation of a custodian in a redeem request
+func BuildPortalLiquidationID(uniqueRedeemID string, custodianIncAddressStr string) common.Hash {
+	return common.HashH([]byte(uniqueRedeemID + custodianIncAddressStr))
+}
@@ -0,0 +1,164 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PortalRedeemRequest - user burns pTokens to get public tokens back from custodians
+// metadata - redeem request - create custom token privacy tx with this metadata
+type PortalRedeemRequest struct {
+	MetadataBase
+	UniqueRedeemID        string
+	TokenID               string // pTokenID in incognito chain
+	RedeemAmount          uint64
+	RedeemerIncAddressStr string
+	RemoteAddress         string // address of the redeemer on the public blockchain
+}
+
+type PortalRedeemRequestAction struct {
+	Meta    PortalRedeemRequest
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalRedeemRequestContent - content of redeem request instructions (accepted, rejected)
+type PortalRedeemRequestContent struct {
+	UniqueRedeemID          string
+	TokenID                 string
+	RedeemAmount            uint64
+	RedeemerIncAddressStr   string
+	RemoteAddress           string
+	MatchingCustodianDetail []*lvdb.MatchingRedeemCustodianDetail
+	TxReqID                 common.Hash
+	ShardID                 byte
+}
+
+type PortalRedeemRequestStatus struct {
+	Status                  byte
+	UniqueRedeemID          string
+	TokenID                 string
+	RedeemAmount            uint64
+	RedeemerIncAddressStr   string
+	RemoteAddress           string
+	MatchingCustodianDetail []*lvdb.MatchingRedeemCustodianDetail
+	TxReqID                 common.Hash
+}
+
+func NewPortalRedeemRequest(
+	metaType int,
+	uniqueRedeemID string,
+	tokenID string,
+	redeemAmount uint64,
+	incAddressStr string,
+	remoteAddr string,
+) (*PortalRedeemRequest, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	requestPTokenMeta := &PortalRedeemRequest{
+		UniqueRedeemID:        uniqueRedeemID,
+		TokenID:               tokenID,
+		RedeemAmount:          redeemAmount,
+		RedeemerIncAddressStr: incAddressStr,
+		RemoteAddress:         remoteAddr,
+	}
+	requestPTokenMeta.MetadataBase = metadataBase
+	return requestPTokenMeta, nil
+}
+
+func (redeemReq PortalRedeemRequest) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	return true, nil
+}
+
+func (redeemReq PortalRedeemRequest) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	// Note: the metadata was already verified with *transaction.TxCustomToken level so no need to verify with *transaction.Tx level again as *transaction.Tx is embedding property of *transaction.TxCustomToken
+	if txr.GetType() == common.TxCustomTokenPrivacyType && reflect.TypeOf(txr).String() == "*transaction.Tx" {
+		return true, true, nil
+	}
+	if txr.GetType() != common.TxCustomTokenPrivacyType {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("redeem request tx should be a custom token privacy tx"))
+	}
+	if redeemReq.UniqueRedeemID == "" {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("unique redeem id should not be empty"))
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(redeemReq.RedeemerIncAddressStr)
+	if err != nil {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("RedeemerIncAddressStr incorrect"))
+	}
+	incAddr := keyWallet.KeySet.PaymentAddress
+	if len(incAddr.Pk) == 0 {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("wrong redeemer incognito address"))
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], incAddr.Pk[:]) {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("redeemer incognito address is not signer tx"))
+	}
+	if !txr.IsCoinsBurning(bcr) {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("must send coin to burning address"))
+	}
+	if !IsPortalToken(redeemReq.TokenID) {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("token is not supported by portal"))
+	}
+	if txr.GetTokenID().String() != redeemReq.TokenID {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("token id should be equal to tx's token id"))
+	}
+	if redeemReq.RedeemAmount == 0 {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("redeem amount should be larger than 0"))
+	}
+	if redeemReq.RedeemAmount != txr.CalculateTxValue() {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("redeem amount should be equal to the tx value"))
+	}
+	if redeemReq.RemoteAddress == "" {
+		return false, false, NewMetadataTxError(PortalRedeemRequestParamError, errors.New("remote address should not be empty"))
+	}
+	return true, true, nil
+}
+
+func (redeemReq PortalRedeemRequest) ValidateMetadataByItself() bool {
+	return redeemReq.Type == PortalRedeemRequestMeta
+}
+
+func (redeemReq PortalRedeemRequest) Hash() *common.Hash {
+	record := redeemReq.MetadataBase.Hash().String()
+	record += redeemReq.UniqueRedeemID
+	record += redeemReq.TokenID
+	record += strconv.FormatUint(redeemReq.RedeemAmount, 10)
+	record += redeemReq.RedeemerIncAddressStr
+	record += redeemReq.RemoteAddress
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (redeemReq *PortalRedeemRequest) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalRedeemRequestAction{
+		Meta:    *redeemReq,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalRedeemRequestMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (redeemReq *PortalRedeemRequest) CalculateSize() uint64 {
+	return calculateSize(redeemReq)
+}
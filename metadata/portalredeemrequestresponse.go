@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+)
+
+// PortalRedeemRequestResponse - refunds the burned pTokens when a redeem request is rejected
+type PortalRedeemRequestResponse struct {
+	MetadataBase
+	RequestStatus string
+	ReqTxID       common.Hash
+}
+
+func NewPortalRedeemRequestResponse(
+	requestStatus string,
+	reqTxID common.Hash,
+	metaType int,
+) *PortalRedeemRequestResponse {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	return &PortalRedeemRequestResponse{
+		RequestStatus: requestStatus,
+		ReqTxID:       reqTxID,
+		MetadataBase:  metadataBase,
+	}
+}
+
+func (iRes PortalRedeemRequestResponse) CheckTransactionFee(tr Transaction, minFee uint64, beaconHeight int64, db database.DatabaseInterface) bool {
+	// no need to have fee for this tx
+	return true
+}
+
+func (iRes PortalRedeemRequestResponse) ValidateTxWithBlockChain(txr Transaction, bcr BlockchainRetriever, shardID byte, db database.DatabaseInterface) (bool, error) {
+	// no need to validate tx with blockchain, just need to validate with requested tx (via ReqTxID)
+	return false, nil
+}
+
+func (iRes PortalRedeemRequestResponse) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	return false, true, nil
+}
+
+func (iRes PortalRedeemRequestResponse) ValidateMetadataByItself() bool {
+	// The validation just need to check at tx level, so returning true here
+	return iRes.Type == PortalRedeemRequestResponseMeta
+}
+
+func (iRes PortalRedeemRequestResponse) Hash() *common.Hash {
+	record := iRes.MetadataBase.Hash().String()
+	record += iRes.RequestStatus
+	record += iRes.ReqTxID.String()
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (iRes *PortalRedeemRequestResponse) CalculateSize() uint64 {
+	return calculateSize(iRes)
+}
+
+func (iRes PortalRedeemRequestResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
+	txsInBlock []Transaction,
+	txsUsed []int,
+	insts [][]string,
+	instUsed []int,
+	shardID byte,
+	tx Transaction,
+	bcr BlockchainRetriever,
+	ac *AccumulatedValues,
+) (bool, error) {
+	return verifyPortalResponseTx(
+		insts, instUsed, shardID, tx,
+		PortalRedeemRequestMeta,
+		common.PortalRedeemRequestRejectedChainStatus,
+		iRes.ReqTxID,
+		func(content string) (common.Hash, byte, string, string, uint64, error) {
+			var redeemReqContent PortalRedeemRequestContent
+			err := json.Unmarshal([]byte(content), &redeemReqContent)
+			if err != nil {
+				return common.Hash{}, 0, "", "", 0, err
+			}
+			return redeemReqContent.TxReqID,
+				redeemReqContent.ShardID,
+				redeemReqContent.RedeemerIncAddressStr,
+				redeemReqContent.TokenID,
+				redeemReqContent.RedeemAmount,
+				nil
+		},
+	)
+}
@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+)
+
+// PortalRelayingBTCHeader - anyone relays bitcoin block headers so beacon can verify proofs of btc txs
+// metadata - relaying btc header - create normal tx with this metadata
+type PortalRelayingBTCHeader struct {
+	MetadataBase
+	Headers []string // serialized headers in hex, each one extends the previous one
+}
+
+// PortalRelayingBTCHeaderAction - shard validator creates instruction that contain this action content
+type PortalRelayingBTCHeaderAction struct {
+	Meta    PortalRelayingBTCHeader
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalRelayingBTCHeaderContent - content of relaying btc header instructions (accepted, rejected)
+type PortalRelayingBTCHeaderContent struct {
+	Headers []string
+	TxReqID common.Hash
+	ShardID byte
+}
+
+type PortalRelayingBTCHeaderStatus struct {
+	Status  byte
+	Headers []string
+}
+
+func NewPortalRelayingBTCHeader(
+	metaType int,
+	headers []string,
+) (*PortalRelayingBTCHeader, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	relayingHeaderMeta := &PortalRelayingBTCHeader{
+		Headers: headers,
+	}
+	relayingHeaderMeta.MetadataBase = metadataBase
+	return relayingHeaderMeta, nil
+}
+
+func (relayingHeader PortalRelayingBTCHeader) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	return true, nil
+}
+
+func (relayingHeader PortalRelayingBTCHeader) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	if txr.GetType() == common.TxCustomTokenPrivacyType {
+		return false, false, NewMetadataTxError(PortalRelayingBTCHeaderParamError, errors.New("relaying btc header tx should be a normal tx (PRV)"))
+	}
+	if len(relayingHeader.Headers) == 0 || len(relayingHeader.Headers) > PortalMaxRelayingBTCHeaders {
+		return false, false, NewMetadataTxError(PortalRelayingBTCHeaderParamError, fmt.Errorf("number of headers should be from 1 to %d", PortalMaxRelayingBTCHeaders))
+	}
+	var prevHash common.Hash
+	for i, headerStr := range relayingHeader.Headers {
+		header, err := btc.ParseBlockHeaderHex(headerStr)
+		if err != nil {
+			return false, false, NewMetadataTxError(PortalRelayingBTCHeaderParamError, err)
+		}
+		if i > 0 && header.PrevBlock != prevHash {
+			return false, false, NewMetadataTxError(PortalRelayingBTCHeaderParamError, fmt.Errorf("header %d does not extend the previous header", i))
+		}
+		prevHash = header.BlockHash()
+	}
+	return true, true, nil
+}
+
+func (relayingHeader PortalRelayingBTCHeader) ValidateMetadataByItself() bool {
+	return relayingHeader.Type == PortalRelayingBTCHeaderMeta
+}
+
+func (relayingHeader PortalRelayingBTCHeader) Hash() *common.Hash {
+	record := relayingHeader.MetadataBase.Hash().String()
+	for _, header := range relayingHeader.Headers {
+		record += header
+	}
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (relayingHeader *PortalRelayingBTCHeader) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalRelayingBTCHeaderAction{
+		Meta:    *relayingHeader,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, NewMetadataTxError(PortalBuildReqActionsError, err)
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalRelayingBTCHeaderMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (relayingHeader *PortalRelayingBTCHeader) CalculateSize() uint64 {
+	return calculateSize(relayingHeader)
+}
@@ -0,0 +1,155 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PortalRequestUnlockCollateral - custodian proves that public tokens were sent to the redeemer
+// and requests to unlock the collateral backing them
+// metadata - custodian requests unlock collateral - create normal tx with this metadata
+type PortalRequestUnlockCollateral struct {
+	MetadataBase
+	UniqueRedeemID      string
+	TokenID             string // pTokenID in incognito chain
+	CustodianAddressStr string
+	RedeemAmount        uint64
+	RedeemProof         string // base64 encoded merkle proof of the tx on the public blockchain of TokenID
+}
+
+// PortalRequestUnlockCollateralAction - shard validator creates instruction that contain this action content,
+// beacon verifies the redeem proof against the relayed headers
+type PortalRequestUnlockCollateralAction struct {
+	Meta    PortalRequestUnlockCollateral
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalRequestUnlockCollateralContent - content of request unlock collateral instructions (accepted, rejected)
+type PortalRequestUnlockCollateralContent struct {
+	UniqueRedeemID      string
+	TokenID             string
+	CustodianAddressStr string
+	RedeemAmount        uint64
+	UnlockAmount        uint64 // prv
+	RedeemProof         string
+	ExternalTxID        string // id of the proven tx, set when the request is accepted
+	TxReqID             common.Hash
+	ShardID             byte
+}
+
+type PortalRequestUnlockCollateralStatus struct {
+	Status              byte
+	UniqueRedeemID      string
+	TokenID             string
+	CustodianAddressStr string
+	RedeemAmount        uint64
+	UnlockAmount        uint64
+	RedeemProof         string
+}
+
+func NewPortalRequestUnlockCollateral(
+	metaType int,
+	uniqueRedeemID string,
+	tokenID string,
+	custodianAddressStr string,
+	redeemAmount uint64,
+	redeemProof string,
+) (*PortalRequestUnlockCollateral, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	requestUnlockCollateralMeta := &PortalRequestUnlockCollateral{
+		UniqueRedeemID:      uniqueRedeemID,
+		TokenID:             tokenID,
+		CustodianAddressStr: custodianAddressStr,
+		RedeemAmount:        redeemAmount,
+		RedeemProof:         redeemProof,
+	}
+	requestUnlockCollateralMeta.MetadataBase = metadataBase
+	return requestUnlockCollateralMeta, nil
+}
+
+func (meta PortalRequestUnlockCollateral) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	return true, nil
+}
+
+func (meta PortalRequestUnlockCollateral) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	if txr.GetType() == common.TxCustomTokenPrivacyType {
+		return false, false, errors.New("request unlock collateral tx should be a normal tx (PRV)")
+	}
+	if meta.UniqueRedeemID == "" {
+		return false, false, errors.New("unique redeem id should not be empty")
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(meta.CustodianAddressStr)
+	if err != nil {
+		return false, false, errors.New("CustodianAddressStr incorrect")
+	}
+	custodianAddr := keyWallet.KeySet.PaymentAddress
+	if len(custodianAddr.Pk) == 0 {
+		return false, false, errors.New("wrong custodian incognito address")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], custodianAddr.Pk[:]) {
+		return false, false, errors.New("custodian incognito address is not signer tx")
+	}
+	if !IsPortalToken(meta.TokenID) {
+		return false, false, errors.New("token is not supported by portal")
+	}
+	if meta.RedeemAmount == 0 {
+		return false, false, errors.New("redeem amount should be larger than 0")
+	}
+	if meta.RedeemProof == "" {
+		return false, false, errors.New("redeem proof should not be empty")
+	}
+	if err := validatePortalExternalTxProof(meta.TokenID, meta.RedeemProof); err != nil {
+		return false, false, NewMetadataTxError(PortalVerifyExternalTxError, err)
+	}
+	return true, true, nil
+}
+
+func (meta PortalRequestUnlockCollateral) ValidateMetadataByItself() bool {
+	return meta.Type == PortalRequestUnlockCollateralMeta
+}
+
+func (meta PortalRequestUnlockCollateral) Hash() *common.Hash {
+	record := meta.MetadataBase.Hash().String()
+	record += meta.UniqueRedeemID
+	record += meta.TokenID
+	record += meta.CustodianAddressStr
+	record += strconv.FormatUint(meta.RedeemAmount, 10)
+	record += meta.RedeemProof
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (meta *PortalRequestUnlockCollateral) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalRequestUnlockCollateralAction{
+		Meta:    *meta,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, NewMetadataTxError(PortalBuildReqActionsError, err)
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalRequestUnlockCollateralMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (meta *PortalRequestUnlockCollateral) CalculateSize() uint64 {
+	return calculateSize(meta)
+}
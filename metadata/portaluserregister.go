@@ -0,0 +1,155 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PortalUserRegister - user registers a porting request (public token -> pToken)
+// metadata - user register porting - create normal tx with this metadata, the tx value is the porting fee (PRV)
+type PortalUserRegister struct {
+	MetadataBase
+	UniqueRegisterId string
+	IncogAddressStr  string
+	PTokenId         string
+	RegisterAmount   uint64
+	PortingFee       uint64
+}
+
+type PortalUserRegisterAction struct {
+	Meta    PortalUserRegister
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalPortingRequestContent - content of porting request instructions (accepted, rejected, expired)
+type PortalPortingRequestContent struct {
+	UniqueRegisterId string
+	IncogAddressStr  string
+	PTokenId         string
+	RegisterAmount   uint64
+	PortingFee       uint64
+	Custodian        []*lvdb.MatchingPortingCustodianDetail
+	TxReqID          common.Hash
+	ShardID          byte
+}
+
+type PortalPortingRequestStatus struct {
+	Status          byte
+	UniquePortingID string
+	TxReqID         common.Hash
+	TokenID         string
+	PorterAddress   string
+	Amount          uint64
+	Custodians      []*lvdb.MatchingPortingCustodianDetail
+	PortingFee      uint64
+	BeaconHeight    uint64
+}
+
+func NewPortalUserRegister(
+	uniqueRegisterId string,
+	incogAddressStr string,
+	pTokenId string,
+	registerAmount uint64,
+	portingFee uint64,
+	metaType int,
+) (*PortalUserRegister, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	portalUserRegisterMeta := &PortalUserRegister{
+		UniqueRegisterId: uniqueRegisterId,
+		IncogAddressStr:  incogAddressStr,
+		PTokenId:         pTokenId,
+		RegisterAmount:   registerAmount,
+		PortingFee:       portingFee,
+	}
+	portalUserRegisterMeta.MetadataBase = metadataBase
+	return portalUserRegisterMeta, nil
+}
+
+func (portalUserRegister PortalUserRegister) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	return true, nil
+}
+
+func (portalUserRegister PortalUserRegister) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	if txr.GetType() == common.TxCustomTokenPrivacyType {
+		return false, false, errors.New("Porting request tx should be a normal tx (PRV)")
+	}
+	if portalUserRegister.UniqueRegisterId == "" {
+		return false, false, errors.New("Unique register id should not be empty")
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(portalUserRegister.IncogAddressStr)
+	if err != nil {
+		return false, false, errors.New("IncogAddressStr of porter incorrect")
+	}
+	incogAddr := keyWallet.KeySet.PaymentAddress
+	if len(incogAddr.Pk) == 0 {
+		return false, false, errors.New("wrong porter incognito address")
+	}
+	if !bytes.Equal(txr.GetSigPubKey()[:], incogAddr.Pk[:]) {
+		return false, false, errors.New("porter incognito address is not signer tx")
+	}
+	if !txr.IsCoinsBurning(bcr) {
+		return false, false, errors.New("must send coin to burning address")
+	}
+	if !IsPortalToken(portalUserRegister.PTokenId) {
+		return false, false, errors.New("public token is not supported by portal")
+	}
+	if portalUserRegister.RegisterAmount == 0 {
+		return false, false, errors.New("register amount should be larger than 0")
+	}
+	if portalUserRegister.PortingFee != txr.CalculateTxValue() {
+		return false, false, errors.New("porting fee should be equal to the tx value")
+	}
+	return true, true, nil
+}
+
+func (portalUserRegister PortalUserRegister) ValidateMetadataByItself() bool {
+	return portalUserRegister.Type == PortalUserRegisterMeta
+}
+
+func (portalUserRegister PortalUserRegister) Hash() *common.Hash {
+	record := portalUserRegister.MetadataBase.Hash().String()
+	record += portalUserRegister.UniqueRegisterId
+	record += portalUserRegister.IncogAddressStr
+	record += portalUserRegister.PTokenId
+	record += strconv.FormatUint(portalUserRegister.RegisterAmount, 10)
+	record += strconv.FormatUint(portalUserRegister.PortingFee, 10)
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (portalUserRegister *PortalUserRegister) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalUserRegisterAction{
+		Meta:    *portalUserRegister,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, err
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalUserRegisterMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (portalUserRegister *PortalUserRegister) CalculateSize() uint64 {
+	return calculateSize(portalUserRegister)
+}
@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+)
+
+// PortalUserRegisterResponse - refunds the porting fee when a porting request is rejected
+type PortalUserRegisterResponse struct {
+	MetadataBase
+	RequestStatus string
+	ReqTxID       common.Hash
+}
+
+func NewPortalUserRegisterResponse(
+	requestStatus string,
+	reqTxID common.Hash,
+	metaType int,
+) *PortalUserRegisterResponse {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	return &PortalUserRegisterResponse{
+		RequestStatus: requestStatus,
+		ReqTxID:       reqTxID,
+		MetadataBase:  metadataBase,
+	}
+}
+
+func (iRes PortalUserRegisterResponse) CheckTransactionFee(tr Transaction, minFee uint64, beaconHeight int64, db database.DatabaseInterface) bool {
+	// no need to have fee for this tx
+	return true
+}
+
+func (iRes PortalUserRegisterResponse) ValidateTxWithBlockChain(txr Transaction, bcr BlockchainRetriever, shardID byte, db database.DatabaseInterface) (bool, error) {
+	// no need to validate tx with blockchain, just need to validate with requested tx (via ReqTxID)
+	return false, nil
+}
+
+func (iRes PortalUserRegisterResponse) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	return false, true, nil
+}
+
+func (iRes PortalUserRegisterResponse) ValidateMetadataByItself() bool {
+	// The validation just need to check at tx level, so returning true here
+	return iRes.Type == PortalUserRegisterResponseMeta
+}
+
+func (iRes PortalUserRegisterResponse) Hash() *common.Hash {
+	record := iRes.MetadataBase.Hash().String()
+	record += iRes.RequestStatus
+	record += iRes.ReqTxID.String()
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (iRes *PortalUserRegisterResponse) CalculateSize() uint64 {
+	return calculateSize(iRes)
+}
+
+func (iRes PortalUserRegisterResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
+	txsInBlock []Transaction,
+	txsUsed []int,
+	insts [][]string,
+	instUsed []int,
+	shardID byte,
+	tx Transaction,
+	bcr BlockchainRetriever,
+	ac *AccumulatedValues,
+) (bool, error) {
+	return verifyPortalResponseTx(
+		insts, instUsed, shardID, tx,
+		PortalUserRegisterMeta,
+		common.PortalPortingRequestRejectedChainStatus,
+		iRes.ReqTxID,
+		func(content string) (common.Hash, byte, string, string, uint64, error) {
+			var portingRequestContent PortalPortingRequestContent
+			err := json.Unmarshal([]byte(content), &portingRequestContent)
+			if err != nil {
+				return common.Hash{}, 0, "", "", 0, err
+			}
+			return portingRequestContent.TxReqID,
+				portingRequestContent.ShardID,
+				portingRequestContent.IncogAddressStr,
+				common.PRVCoinID.String(),
+				portingRequestContent.PortingFee,
+				nil
+		},
+	)
+}
@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// PortalUserRequestPToken - porter requests pTokens after sending public tokens to the matched custodians
+// metadata - user requests ptoken - create normal tx with this metadata
+type PortalUserRequestPToken struct {
+	MetadataBase
+	UniquePortingID string
+	TokenID         string
+	IncogAddressStr string
+	PortingAmount   uint64
+	PortingProof    string // base64 encoded merkle proof of the tx on the public blockchain of TokenID
+}
+
+// PortalRequestPTokensAction - shard validator creates instruction that contain this action content,
+// beacon verifies the porting proof against the relayed headers
+type PortalRequestPTokensAction struct {
+	Meta    PortalUserRequestPToken
+	TxReqID common.Hash
+	ShardID byte
+}
+
+// PortalRequestPTokensContent - content of request ptokens instructions (accepted, rejected)
+type PortalRequestPTokensContent struct {
+	UniquePortingID string
+	TokenID         string
+	IncogAddressStr string
+	PortingAmount   uint64
+	PortingProof    string
+	ExternalTxID    string // id of the proven tx, set when the request is accepted
+	TxReqID         common.Hash
+	ShardID         byte
+}
+
+type PortalRequestPTokensStatus struct {
+	Status          byte
+	UniquePortingID string
+	TokenID         string
+	IncogAddressStr string
+	PortingAmount   uint64
+	PortingProof    string
+}
+
+func NewPortalUserRequestPToken(
+	uniquePortingID string,
+	incogAddressStr string,
+	tokenID string,
+	portingAmount uint64,
+	portingProof string,
+	metaType int,
+) (*PortalUserRequestPToken, error) {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	requestPTokenMeta := &PortalUserRequestPToken{
+		UniquePortingID: uniquePortingID,
+		IncogAddressStr: incogAddressStr,
+		TokenID:         tokenID,
+		PortingAmount:   portingAmount,
+		PortingProof:    portingProof,
+	}
+	requestPTokenMeta.MetadataBase = metadataBase
+	return requestPTokenMeta, nil
+}
+
+func (reqPToken PortalUserRequestPToken) ValidateTxWithBlockChain(
+	txr Transaction,
+	bcr BlockchainRetriever,
+	shardID byte,
+	db database.DatabaseInterface,
+) (bool, error) {
+	return true, nil
+}
+
+func (reqPToken PortalUserRequestPToken) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	if txr.GetType() == common.TxCustomTokenPrivacyType {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("request ptoken tx should be a normal tx (PRV)"))
+	}
+	if reqPToken.UniquePortingID == "" {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("unique porting id should not be empty"))
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(reqPToken.IncogAddressStr)
+	if err != nil || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("IncogAddressStr of porter incorrect"))
+	}
+	if !IsPortalToken(reqPToken.TokenID) {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("public token is not supported by portal"))
+	}
+	if reqPToken.PortingAmount == 0 {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("porting amount should be larger than 0"))
+	}
+	if reqPToken.PortingProof == "" {
+		return false, false, NewMetadataTxError(PortalRequestPTokenParamError, errors.New("porting proof should not be empty"))
+	}
+	if err := validatePortalExternalTxProof(reqPToken.TokenID, reqPToken.PortingProof); err != nil {
+		return false, false, NewMetadataTxError(PortalVerifyExternalTxError, err)
+	}
+	return true, true, nil
+}
+
+func (reqPToken PortalUserRequestPToken) ValidateMetadataByItself() bool {
+	return reqPToken.Type == PortalUserRequestPTokenMeta
+}
+
+func (reqPToken PortalUserRequestPToken) Hash() *common.Hash {
+	record := reqPToken.MetadataBase.Hash().String()
+	record += reqPToken.UniquePortingID
+	record += reqPToken.TokenID
+	record += reqPToken.IncogAddressStr
+	record += strconv.FormatUint(reqPToken.PortingAmount, 10)
+	record += reqPToken.PortingProof
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (reqPToken *PortalUserRequestPToken) BuildReqActions(tx Transaction, bcr BlockchainRetriever, shardID byte) ([][]string, error) {
+	actionContent := PortalRequestPTokensAction{
+		Meta:    *reqPToken,
+		TxReqID: *tx.Hash(),
+		ShardID: shardID,
+	}
+	actionContentBytes, err := json.Marshal(actionContent)
+	if err != nil {
+		return [][]string{}, NewMetadataTxError(PortalBuildReqActionsError, err)
+	}
+	actionContentBase64Str := base64.StdEncoding.EncodeToString(actionContentBytes)
+	action := []string{strconv.Itoa(PortalUserRequestPTokenMeta), actionContentBase64Str}
+	return [][]string{action}, nil
+}
+
+func (reqPToken *PortalUserRequestPToken) CalculateSize() uint64 {
+	return calculateSize(reqPToken)
+}
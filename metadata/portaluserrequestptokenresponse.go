@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/database"
+)
+
+// PortalUserRequestPTokenResponse - mints pTokens to the porter once the public tokens were received by custodians
+type PortalUserRequestPTokenResponse struct {
+	MetadataBase
+	RequestStatus string
+	ReqTxID       common.Hash
+}
+
+func NewPortalUserRequestPTokenResponse(
+	requestStatus string,
+	reqTxID common.Hash,
+	metaType int,
+) *PortalUserRequestPTokenResponse {
+	metadataBase := MetadataBase{
+		Type: metaType,
+	}
+	return &PortalUserRequestPTokenResponse{
+		RequestStatus: requestStatus,
+		ReqTxID:       reqTxID,
+		MetadataBase:  metadataBase,
+	}
+}
+
+func (iRes PortalUserRequestPTokenResponse) CheckTransactionFee(tr Transaction, minFee uint64, beaconHeight int64, db database.DatabaseInterface) bool {
+	// no need to have fee for this tx
+	return true
+}
+
+func (iRes PortalUserRequestPTokenResponse) ValidateTxWithBlockChain(txr Transaction, bcr BlockchainRetriever, shardID byte, db database.DatabaseInterface) (bool, error) {
+	// no need to validate tx with blockchain, just need to validate with requested tx (via ReqTxID)
+	return false, nil
+}
+
+func (iRes PortalUserRequestPTokenResponse) ValidateSanityData(bcr BlockchainRetriever, txr Transaction) (bool, bool, error) {
+	return false, true, nil
+}
+
+func (iRes PortalUserRequestPTokenResponse) ValidateMetadataByItself() bool {
+	// The validation just need to check at tx level, so returning true here
+	return iRes.Type == PortalUserRequestPTokenResponseMeta
+}
+
+func (iRes PortalUserRequestPTokenResponse) Hash() *common.Hash {
+	record := iRes.MetadataBase.Hash().String()
+	record += iRes.RequestStatus
+	record += iRes.ReqTxID.String()
+	// final hash
+	hash := common.HashH([]byte(record))
+	return &hash
+}
+
+func (iRes *PortalUserRequestPTokenResponse) CalculateSize() uint64 {
+	return calculateSize(iRes)
+}
+
+func (iRes PortalUserRequestPTokenResponse) VerifyMinerCreatedTxBeforeGettingInBlock(
+	txsInBlock []Transaction,
+	txsUsed []int,
+	insts [][]string,
+	instUsed []int,
+	shardID byte,
+	tx Transaction,
+	bcr BlockchainRetriever,
+	ac *AccumulatedValues,
+) (bool, error) {
+	return verifyPortalResponseTx(
+		insts, instUsed, shardID, tx,
+		PortalUserRequestPTokenMeta,
+		common.PortalReqPTokensAcceptedChainStatus,
+		iRes.ReqTxID,
+		func(content string) (common.Hash, byte, string, string, uint64, error) {
+			var reqPTokensContent PortalRequestPTokensContent
+			err := json.Unmarshal([]byte(content), &reqPTokensContent)
+			if err != nil {
+				return common.Hash{}, 0, "", "", 0, err
+			}
+			return reqPTokensContent.TxReqID,
+				reqPTokensContent.ShardID,
+				reqPTokensContent.IncogAddressStr,
+				reqPTokensContent.TokenID,
+				reqPTokensContent.PortingAmount,
+				nil
+		},
+	)
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/incognitochain/incognito-chain/blockchain/btc"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/wallet"
+	"github.com/pkg/errors"
+)
+
+const (
+	// number of confirmations on bitcoin required before an external tx is accepted by portal
+	PortalBTCMinConfirmations = 6
+	// btc txs in blocks deeper than this under the tip of the relayed header chain are not accepted
+	PortalBTCMaxProofDepth = 1008
+	// max number of headers relayed by one tx
+	PortalMaxRelayingBTCHeaders = 100
+)
+
+func IsPortalToken(tokenIDStr string) bool {
+	return common.IndexOfStr(tokenIDStr, common.PortalSupportedTokenIDs) != -1
+}
+
+// validatePortalExternalTxProof makes sure the proof of an external tx is well-formed,
+// it is verified against relayed headers by beacon
+func validatePortalExternalTxProof(tokenIDStr string, proofStr string) error {
+	switch tokenIDStr {
+	case common.PortalBTCIDStr:
+		_, err := btc.ParseMerkleProof(proofStr)
+		return err
+	default:
+		return fmt.Errorf("there is no external tx verifier for token %s", tokenIDStr)
+	}
+}
+
+// verifyPortalResponseTx looks for the unused instruction (with type instMetaType and status instStatus) that
+// the response tx was built from, the instruction's content is decoded by parseContent
+func verifyPortalResponseTx(
+	insts [][]string,
+	instUsed []int,
+	shardID byte,
+	tx Transaction,
+	instMetaType int,
+	instStatus string,
+	requestedTxID common.Hash,
+	parseContent func(content string) (txReqID common.Hash, shardID byte, receiverAddrStr string, receivingTokenIDStr string, receivingAmt uint64, err error),
+) (bool, error) {
+	idx := -1
+	for i, inst := range insts {
+		if len(inst) < 4 {
+			continue
+		}
+		if instUsed[i] > 0 ||
+			inst[0] != fmt.Sprintf("%d", instMetaType) ||
+			inst[2] != instStatus {
+			continue
+		}
+		txReqIDFromInst, shardIDFromInst, receiverAddrStrFromInst, receivingTokenIDStr, receivingAmtFromInst, err := parseContent(inst[3])
+		if err != nil {
+			Logger.log.Error("WARNING - VALIDATION: an error occured while parsing instruction content: ", err)
+			continue
+		}
+		if !bytes.Equal(requestedTxID[:], txReqIDFromInst[:]) ||
+			shardID != shardIDFromInst {
+			continue
+		}
+		key, err := wallet.Base58CheckDeserialize(receiverAddrStrFromInst)
+		if err != nil {
+			Logger.log.Info("WARNING - VALIDATION: an error occured while deserializing receiver address string: ", err)
+			continue
+		}
+		_, pk, paidAmount, assetID := tx.GetTransferData()
+		if !bytes.Equal(key.KeySet.PaymentAddress.Pk[:], pk[:]) ||
+			receivingAmtFromInst != paidAmount ||
+			receivingTokenIDStr != assetID.String() {
+			continue
+		}
+		idx = i
+		break
+	}
+	if idx == -1 {
+		return false, errors.Errorf("no portal instruction (type %d) found for response tx %s", instMetaType, tx.Hash().String())
+	}
+	instUsed[idx] = 1
+	return true, nil
+}
@@ -474,6 +474,20 @@ func (_m *DatabaseInterface) DeleteTransactionIndex(txId common.Hash) error {
 	return r0
 }
 
+// DeleteTxHistory provides a mock function with given fields: publicKey, sortKey
+func (_m *DatabaseInterface) DeleteTxHistory(publicKey []byte, sortKey []byte) error {
+	ret := _m.Called(publicKey, sortKey)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte, []byte) error); ok {
+		r0 = rf(publicKey, sortKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteWaitingPDEContributionByPairID provides a mock function with given fields: beaconHeight, pairID
 func (_m *DatabaseInterface) DeleteWaitingPDEContributionByPairID(beaconHeight uint64, pairID string) error {
 	ret := _m.Called(beaconHeight, pairID)
@@ -670,6 +684,27 @@ func (_m *DatabaseInterface) FetchRewardReceiverByHeight(height uint64) ([]byte,
 	return r0, r1
 }
 
+// FetchPrunedHeight provides a mock function with given fields: isBeacon, shardID
+func (_m *DatabaseInterface) FetchPrunedHeight(isBeacon bool, shardID byte) (uint64, error) {
+	ret := _m.Called(isBeacon, shardID)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(bool, byte) uint64); ok {
+		r0 = rf(isBeacon, shardID)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(bool, byte) error); ok {
+		r1 = rf(isBeacon, shardID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchShardBestState provides a mock function with given fields: shardID
 func (_m *DatabaseInterface) FetchShardBestState(shardID byte) ([]byte, error) {
 	ret := _m.Called(shardID)
@@ -1222,6 +1257,29 @@ func (_m *DatabaseInterface) GetPDEStatus(prefix []byte, suffix []byte) (byte, e
 	return r0, r1
 }
 
+// GetPortalStatus provides a mock function with given fields: prefix, suffix
+func (_m *DatabaseInterface) GetPortalStatus(prefix []byte, suffix []byte) ([]byte, error) {
+	ret := _m.Called(prefix, suffix)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func([]byte, []byte) []byte); ok {
+		r0 = rf(prefix, suffix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]byte, []byte) error); ok {
+		r1 = rf(prefix, suffix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProducersBlackList provides a mock function with given fields: beaconHeight
 func (_m *DatabaseInterface) GetProducersBlackList(beaconHeight uint64) (map[string]uint8, error) {
 	ret := _m.Called(beaconHeight)
@@ -1613,6 +1671,20 @@ func (_m *DatabaseInterface) IsETHTxHashIssued(uniqETHTx []byte) (bool, error) {
 	return r0, r1
 }
 
+// IterateRecordsByPrefix provides a mock function with given fields: prefix, fn
+func (_m *DatabaseInterface) IterateRecordsByPrefix(prefix []byte, fn func([]byte, []byte) error) error {
+	ret := _m.Called(prefix, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte, func([]byte, []byte) error) error); ok {
+		r0 = rf(prefix, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ListCommitment provides a mock function with given fields: tokenID, shardID
 func (_m *DatabaseInterface) ListCommitment(tokenID common.Hash, shardID byte) (map[string]uint64, error) {
 	ret := _m.Called(tokenID, shardID)
@@ -1767,6 +1839,38 @@ func (_m *DatabaseInterface) ListSerialNumber(tokenID common.Hash, shardID byte)
 	return r0, r1
 }
 
+// ListTxHistory provides a mock function with given fields: publicKey, afterSortKey, limit
+func (_m *DatabaseInterface) ListTxHistory(publicKey []byte, afterSortKey []byte, limit int) ([][]byte, [][]byte, error) {
+	ret := _m.Called(publicKey, afterSortKey, limit)
+
+	var r0 [][]byte
+	if rf, ok := ret.Get(0).(func([]byte, []byte, int) [][]byte); ok {
+		r0 = rf(publicKey, afterSortKey, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]byte)
+		}
+	}
+
+	var r1 [][]byte
+	if rf, ok := ret.Get(1).(func([]byte, []byte, int) [][]byte); ok {
+		r1 = rf(publicKey, afterSortKey, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([][]byte)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func([]byte, []byte, int) error); ok {
+		r2 = rf(publicKey, afterSortKey, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // PrivacyTokenIDCrossShardExisted provides a mock function with given fields: tokenID
 func (_m *DatabaseInterface) PrivacyTokenIDCrossShardExisted(tokenID common.Hash) bool {
 	ret := _m.Called(tokenID)
@@ -1818,6 +1922,20 @@ func (_m *DatabaseInterface) PrivacyTokenTxs(tokenID common.Hash) ([]common.Hash
 	return r0, r1
 }
 
+// PruneBlock provides a mock function with given fields: hash
+func (_m *DatabaseInterface) PruneBlock(hash common.Hash) error {
+	ret := _m.Called(hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(common.Hash) error); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Put provides a mock function with given fields: key, value
 func (_m *DatabaseInterface) Put(key []byte, value []byte) error {
 	ret := _m.Called(key, value)
@@ -2182,6 +2300,20 @@ func (_m *DatabaseInterface) StoreProducersBlackList(beaconHeight uint64, produc
 	return r0
 }
 
+// StorePrunedHeight provides a mock function with given fields: isBeacon, shardID, height
+func (_m *DatabaseInterface) StorePrunedHeight(isBeacon bool, shardID byte, height uint64) error {
+	ret := _m.Called(isBeacon, shardID, height)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(bool, byte, uint64) error); ok {
+		r0 = rf(isBeacon, shardID, height)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreRewardReceiverByHeight provides a mock function with given fields: height, v
 func (_m *DatabaseInterface) StoreRewardReceiverByHeight(height uint64, v interface{}) error {
 	ret := _m.Called(height, v)
@@ -2308,6 +2440,20 @@ func (_m *DatabaseInterface) StoreTxByPublicKey(publicKey []byte, txID common.Ha
 	return r0
 }
 
+// StoreTxHistory provides a mock function with given fields: publicKey, sortKey, value, bd
+func (_m *DatabaseInterface) StoreTxHistory(publicKey []byte, sortKey []byte, value []byte, bd *[]database.BatchData) error {
+	ret := _m.Called(publicKey, sortKey, value, bd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte, []byte, []byte, *[]database.BatchData) error); ok {
+		r0 = rf(publicKey, sortKey, value, bd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TrackBridgeReqWithStatus provides a mock function with given fields: txReqID, status, bd
 func (_m *DatabaseInterface) TrackBridgeReqWithStatus(txReqID common.Hash, status byte, bd *[]database.BatchData) error {
 	ret := _m.Called(txReqID, status, bd)
@@ -2350,6 +2496,20 @@ func (_m *DatabaseInterface) TrackPDEStatus(prefix []byte, suffix []byte, status
 	return r0
 }
 
+// TrackPortalStatus provides a mock function with given fields: prefix, suffix, statusContent
+func (_m *DatabaseInterface) TrackPortalStatus(prefix []byte, suffix []byte, statusContent []byte) error {
+	ret := _m.Called(prefix, suffix, statusContent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte, []byte, []byte) error); ok {
+		r0 = rf(prefix, suffix, statusContent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateBridgeTokenInfo provides a mock function with given fields: incTokenID, externalTokenID, isCentralized, updatingAmt, updateType, bd
 func (_m *DatabaseInterface) UpdateBridgeTokenInfo(incTokenID common.Hash, externalTokenID []byte, isCentralized bool, updatingAmt uint64, updateType string, bd *[]database.BatchData) error {
 	ret := _m.Called(incTokenID, externalTokenID, isCentralized, updatingAmt, updateType, bd)
@@ -43,6 +43,30 @@ func GetKeySetFromPrivateKeyParams(privateKeyWalletStr string) (*incognitokey.Ke
 	return &keyWallet.KeySet, shardID, nil
 }
 
+func getPaymentInfosFromReceiversParam(receivers map[string]interface{}) ([]*privacy.PaymentInfo, error) {
+	paymentInfos := make([]*privacy.PaymentInfo, 0)
+	for paymentAddressStr, amount := range receivers {
+		keyWalletReceiver, err := wallet.Base58CheckDeserialize(paymentAddressStr)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyWalletReceiver.KeySet.PaymentAddress.Pk) == 0 {
+			return nil, fmt.Errorf("payment info %+v is invalid", paymentAddressStr)
+		}
+
+		amountParam, ok := amount.(float64)
+		if !ok {
+			return nil, errors.New("amount payment address is invalid")
+		}
+		paymentInfo := &privacy.PaymentInfo{
+			Amount:         uint64(amountParam),
+			PaymentAddress: keyWalletReceiver.KeySet.PaymentAddress,
+		}
+		paymentInfos = append(paymentInfos, paymentInfo)
+	}
+	return paymentInfos, nil
+}
+
 func NewCreateRawTxParam(params interface{}) (*CreateRawTxParam, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if len(arrayParams) < 3 {
@@ -67,25 +91,9 @@ func NewCreateRawTxParam(params interface{}) (*CreateRawTxParam, error) {
 			return nil, errors.New("receivers param is invalid")
 		}
 	}
-	paymentInfos := make([]*privacy.PaymentInfo, 0)
-	for paymentAddressStr, amount := range receivers {
-		keyWalletReceiver, err := wallet.Base58CheckDeserialize(paymentAddressStr)
-		if err != nil {
-			return nil, err
-		}
-		if len(keyWalletReceiver.KeySet.PaymentAddress.Pk) == 0 {
-			return nil, fmt.Errorf("payment info %+v is invalid", paymentAddressStr)
-		}
-
-		amountParam, ok := amount.(float64)
-		if !ok {
-			return nil, errors.New("amount payment address is invalid")
-		}
-		paymentInfo := &privacy.PaymentInfo{
-			Amount:         uint64(amountParam),
-			PaymentAddress: keyWalletReceiver.KeySet.PaymentAddress,
-		}
-		paymentInfos = append(paymentInfos, paymentInfo)
+	paymentInfos, err := getPaymentInfosFromReceiversParam(receivers)
+	if err != nil {
+		return nil, err
 	}
 
 	// param #3: estimation fee nano P per kb
@@ -0,0 +1,108 @@
+package bean
+
+import (
+	"errors"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+type CreateUnsignedTxParam struct {
+	SenderKeySet         *incognitokey.KeySet // payment address and readonly key, no private key
+	ShardIDSender        byte
+	PaymentInfos         []*privacy.PaymentInfo
+	EstimateFeeCoinPerKb int64
+	HasPrivacyCoin       bool
+	SerialNumbers        map[string]string // base58 check coin commitment -> serial number, from the offline signer
+	Info                 []byte
+}
+
+func NewCreateUnsignedTxParam(params interface{}) (*CreateUnsignedTxParam, error) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 3 {
+		return nil, errors.New("not enough param")
+	}
+
+	// param #1: viewing key of sender
+	senderKeyParam, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, errors.New("sender viewing key is invalid")
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(senderKeyParam)
+	if err != nil {
+		return nil, err
+	}
+	if !keyWallet.IsWatchOnly() || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 || len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, errors.New("sender viewing key is invalid")
+	}
+	lastByte := keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1]
+	shardIDSender := common.GetShardIDFromLastByte(lastByte)
+
+	// param #2: list receivers
+	receivers := make(map[string]interface{})
+	if arrayParams[1] != nil {
+		receivers, ok = arrayParams[1].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("receivers param is invalid")
+		}
+	}
+	paymentInfos, err := getPaymentInfosFromReceiversParam(receivers)
+	if err != nil {
+		return nil, err
+	}
+
+	// param #3: estimation fee nano P per kb
+	estimateFeeCoinPerKb, ok := arrayParams[2].(float64)
+	if !ok {
+		return nil, errors.New("estimate fee coin per kb is invalid")
+	}
+
+	// param #4: hasPrivacyCoin flag: 1 or -1
+	// default: -1 (has no privacy) (if missing this param)
+	hasPrivacyCoinParam := float64(-1)
+	if len(arrayParams) > 3 {
+		hasPrivacyCoinParam, ok = arrayParams[3].(float64)
+		if !ok {
+			return nil, errors.New("has privacy for tx is invalid")
+		}
+	}
+	hasPrivacyCoin := int(hasPrivacyCoinParam) > 0
+
+	// param #5: serial numbers of the coins spent by signed txs (optional)
+	serialNumbers := make(map[string]string)
+	if len(arrayParams) > 4 && arrayParams[4] != nil {
+		serialNumbersParam, ok := arrayParams[4].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("serial numbers param is invalid")
+		}
+		for commitment, serialNumber := range serialNumbersParam {
+			serialNumberStr, ok := serialNumber.(string)
+			if !ok {
+				return nil, errors.New("serial number is invalid")
+			}
+			serialNumbers[commitment] = serialNumberStr
+		}
+	}
+
+	// param #6: info (optional)
+	info := []byte{}
+	if len(arrayParams) > 5 && arrayParams[5] != nil {
+		infoStr, ok := arrayParams[5].(string)
+		if !ok {
+			return nil, errors.New("info is invalid")
+		}
+		info = []byte(infoStr)
+	}
+
+	return &CreateUnsignedTxParam{
+		SenderKeySet:         &keyWallet.KeySet,
+		ShardIDSender:        shardIDSender,
+		PaymentInfos:         paymentInfos,
+		EstimateFeeCoinPerKb: int64(estimateFeeCoinPerKb),
+		HasPrivacyCoin:       hasPrivacyCoin,
+		SerialNumbers:        serialNumbers,
+		Info:                 info,
+	}, nil
+}
@@ -26,6 +26,7 @@ const (
 	getNumberOfTxsInMempool       = "getnumberoftxsinmempool"
 	getMempoolEntry               = "getmempoolentry"
 	removeTxInMempool             = "removetxinmempool"
+	getMempoolRestoreResult       = "getmempoolrestoreresult"
 	getBeaconPoolState            = "getbeaconpoolstate"
 	getShardPoolState             = "getshardpoolstate"
 	getShardPoolLatestValidHeight = "getshardpoollatestvalidheight"
@@ -52,6 +53,7 @@ const (
 	listOutputCoins                            = "listoutputcoins"
 	createRawTransaction                       = "createtransaction"
 	sendRawTransaction                         = "sendtransaction"
+	createUnsignedTransaction                  = "createunsignedtransaction"
 	createAndSendTransaction                   = "createandsendtransaction"
 	createAndSendCustomTokenTransaction        = "createandsendcustomtokentransaction"
 	sendRawCustomTokenTransaction              = "sendrawcustomtokentransaction"
@@ -70,6 +72,7 @@ const (
 	getTransactionByHash                       = "gettransactionbyhash"
 	gettransactionhashbyreceiver               = "gettransactionhashbyreceiver"
 	gettransactionbyreceiver                   = "gettransactionbyreceiver"
+	getTransactionHistory                      = "gettransactionhistory"
 	listCustomToken                            = "listcustomtoken"
 	listPrivacyCustomToken                     = "listprivacycustomtoken"
 	getBalancePrivacyCustomToken               = "getbalanceprivacycustomtoken"
@@ -108,14 +111,22 @@ const (
 	getAddressesByAccount      = "getaddressesbyaccount"
 	getAccountAddress          = "getaccountaddress"
 	dumpPrivkey                = "dumpprivkey"
+	dumpViewingKey             = "dumpviewingkey"
 	importAccount              = "importaccount"
+	importViewingKey           = "importviewingkey"
 	removeAccount              = "removeaccount"
 	listUnspentOutputCoins     = "listunspentoutputcoins"
 	getBalance                 = "getbalance"
 	getBalanceByPrivatekey     = "getbalancebyprivatekey"
 	getBalanceByPaymentAddress = "getbalancebypaymentaddress"
+	getBalanceByViewingKey     = "getbalancebyviewingkey"
 	getReceivedByAccount       = "getreceivedbyaccount"
 	setTxFee                   = "settxfee"
+	walletPassphrase           = "walletpassphrase"
+	walletLock                 = "walletlock"
+	listWallets                = "listwallets"
+	createWallet               = "createwallet"
+	getConsolidationStatus     = "getconsolidationstatus"
 
 	// walletsta
 	getPublicKeyFromPaymentAddress = "getpublickeyfrompaymentaddress"
@@ -166,20 +177,50 @@ const (
 	getProducersBlackListDetail = "getproducersblacklistdetail"
 
 	// pde
-	getPDEState                           = "getpdestate"
-	createAndSendTxWithWithdrawalReq      = "createandsendtxwithwithdrawalreq"
-	createAndSendTxWithPTokenTradeReq     = "createandsendtxwithptokentradereq"
-	createAndSendTxWithPRVTradeReq        = "createandsendtxwithprvtradereq"
-	createAndSendTxWithPTokenContribution = "createandsendtxwithptokencontribution"
-	createAndSendTxWithPRVContribution    = "createandsendtxwithprvcontribution"
-	convertNativeTokenToPrivacyToken      = "convertnativetokentoprivacytoken"
-	convertPrivacyTokenToNativeToken      = "convertprivacytokentonativetoken"
-	getPDEContributionStatus              = "getpdecontributionstatus"
-	getPDEContributionStatusV2            = "getpdecontributionstatusv2"
-	getPDETradeStatus                     = "getpdetradestatus"
-	getPDEWithdrawalStatus                = "getpdewithdrawalstatus"
-	convertPDEPrices                      = "convertpdeprices"
-	extractPDEInstsFromBeaconBlock        = "extractpdeinstsfrombeaconblock"
+	getPDEState                               = "getpdestate"
+	createAndSendTxWithWithdrawalReq          = "createandsendtxwithwithdrawalreq"
+	createAndSendTxWithPTokenTradeReq         = "createandsendtxwithptokentradereq"
+	createAndSendTxWithPRVTradeReq            = "createandsendtxwithprvtradereq"
+	createAndSendTxWithPTokenMultiHopTradeReq = "createandsendtxwithptokenmultihoptradereq"
+	createAndSendTxWithPRVMultiHopTradeReq    = "createandsendtxwithprvmultihoptradereq"
+	createAndSendTxWithPTokenLimitOrder       = "createandsendtxwithptokenlimitorder"
+	createAndSendTxWithPRVLimitOrder          = "createandsendtxwithprvlimitorder"
+	createAndSendTxWithCancelLimitOrder       = "createandsendtxwithcancellimitorder"
+	createAndSendTxWithPTokenContribution     = "createandsendtxwithptokencontribution"
+	createAndSendTxWithPRVContribution        = "createandsendtxwithprvcontribution"
+	convertNativeTokenToPrivacyToken          = "convertnativetokentoprivacytoken"
+	convertPrivacyTokenToNativeToken          = "convertprivacytokentonativetoken"
+	getPDEContributionStatus                  = "getpdecontributionstatus"
+	getPDEContributionStatusV2                = "getpdecontributionstatusv2"
+	getPDETradeStatus                         = "getpdetradestatus"
+	getPDEWithdrawalStatus                    = "getpdewithdrawalstatus"
+	getPDELimitOrderStatus                    = "getpdelimitorderstatus"
+	convertPDEPrices                          = "convertpdeprices"
+	extractPDEInstsFromBeaconBlock            = "extractpdeinstsfrombeaconblock"
+	getPDEPriceHistory                        = "getpdepricehistory"
+	getPDEVolumeHistory                       = "getpdevolumehistory"
+	getPDELiquidityHistory                    = "getpdeliquidityhistory"
+
+	// portal
+	getPortalState                         = "getportalstate"
+	createRawTxWithCustodianDeposit        = "createrawtxwithcustodiandeposit"
+	createAndSendTxWithCustodianDeposit    = "createandsendtxwithcustodiandeposit"
+	createRawTxWithPortingRequest          = "createrawtxwithportingrequest"
+	createAndSendTxWithPortingRequest      = "createandsendtxwithportingrequest"
+	createRawTxWithReqPToken               = "createrawtxwithreqptoken"
+	createAndSendTxWithReqPToken           = "createandsendtxwithreqptoken"
+	createRawTxWithRedeemReq               = "createrawtxwithredeemreq"
+	createAndSendTxWithRedeemReq           = "createandsendtxwithredeemreq"
+	createRawTxWithReqUnlockCollateral     = "createrawtxwithrequnlockcollateral"
+	createAndSendTxWithReqUnlockCollateral = "createandsendtxwithrequnlockcollateral"
+	getPortalCustodianDepositStatus        = "getportalcustodiandepositstatus"
+	getPortalPortingRequestStatus          = "getportalportingrequeststatus"
+	getPortalReqPTokenStatus               = "getportalreqptokenstatus"
+	getPortalRedeemRequestStatus           = "getportalredeemrequeststatus"
+	getPortalReqUnlockCollateralStatus     = "getportalrequnlockcollateralstatus"
+	createRawTxWithRelayingBTCHeader       = "createrawtxwithrelayingbtcheader"
+	createAndSendTxWithRelayingBTCHeader   = "createandsendtxwithrelayingbtcheader"
+	getPortalRelayingBTCHeaderStatus       = "getportalrelayingbtcheaderstatus"
 
 	// get burning address
 	getBurningAddress = "getburningaddress"
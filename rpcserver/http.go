@@ -17,6 +17,7 @@ import (
 
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/rpcserver/rpcservice"
+	"github.com/incognitochain/incognito-chain/wire"
 )
 
 type HttpServer struct {
@@ -85,8 +86,18 @@ func (httpServer *HttpServer) Init(config *RpcServerConfig) {
 	}
 	httpServer.walletService = &rpcservice.WalletService{
 		Wallet:     httpServer.config.Wallet,
+		Keystore:   httpServer.config.Keystore,
 		BlockChain: httpServer.config.BlockChain,
 	}
+	if httpServer.config.Wallet != nil && httpServer.config.WalletConsolidation.Threshold > 0 {
+		httpServer.walletService.Consolidator = rpcservice.NewWalletConsolidator(httpServer.config.WalletConsolidation,
+			httpServer.config.Wallet, httpServer.txService, func(message wire.Message) error {
+				if httpServer.config.Server == nil {
+					return nil
+				}
+				return httpServer.config.Server.PushMessageToAll(message)
+			})
+	}
 	httpServer.poolStateService = &rpcservice.PoolStateService{}
 }
 
@@ -118,6 +129,9 @@ func (httpServer *HttpServer) Start() error {
 			Logger.log.Infof("RPC Http listener done for %s", listen.Addr())
 		}(listen)
 	}
+	if httpServer.walletService.Consolidator != nil {
+		httpServer.walletService.Consolidator.Start()
+	}
 	atomic.StoreInt32(&httpServer.started, 1)
 	return nil
 }
@@ -128,6 +142,9 @@ func (httpServer *HttpServer) Stop() {
 		Logger.log.Info("RPC server is already in the process of shutting down")
 	}
 	Logger.log.Info("RPC server shutting down")
+	if httpServer.walletService.Consolidator != nil {
+		httpServer.walletService.Consolidator.Stop()
+	}
 	if httpServer.started != 0 {
 		err := httpServer.server.Close()
 		fmt.Println(err)
@@ -211,13 +211,17 @@ func (httpServer *HttpServer) handleGetBlockChainInfo(params interface{}, closeC
 	}
 	shardsBestState := httpServer.blockService.GetShardBestStates()
 	for shardID, bestState := range shardsBestState {
-		result.BestBlocks[int(shardID)] = *(jsonresult.NewGetBestBlockItemFromShard(bestState))
+		bestBlockItem := jsonresult.NewGetBestBlockItemFromShard(bestState)
+		bestBlockItem.SetFinality(httpServer.blockService.GetShardBlockTree(shardID))
+		result.BestBlocks[int(shardID)] = *bestBlockItem
 	}
 	beaconBestState, err := httpServer.blockService.GetBeaconBestState()
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetClonedBeaconBestStateError, err)
 	}
-	result.BestBlocks[-1] = *(jsonresult.NewGetBestBlockItemFromBeacon(beaconBestState))
+	bestBlockItem := jsonresult.NewGetBestBlockItemFromBeacon(beaconBestState)
+	bestBlockItem.SetFinality(httpServer.blockService.GetBeaconBlockTree())
+	result.BestBlocks[-1] = *bestBlockItem
 	Logger.log.Debugf("handleGetBlockChainInfo result: %+v", result)
 	return result, nil
 }
@@ -355,7 +359,7 @@ func (httpServer *HttpServer) handleGetCrossShardBlock(params interface{}, close
 	shardBlock, err := httpServer.config.BlockChain.GetShardBlockByHeight(blockHeight, byte(shardID))
 	if err != nil {
 		Logger.log.Debugf("handleGetCrossShardBlock result: %+v", nil)
-		return nil, rpcservice.NewRPCError(rpcservice.GetShardBlockByHeightError, err)
+		return nil, rpcservice.NewBlockRPCError(rpcservice.GetShardBlockByHeightError, err)
 	}
 
 	result := jsonresult.CrossShardDataResult{HasCrossShard: false}
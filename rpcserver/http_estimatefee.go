@@ -162,6 +162,13 @@ func (httpServer *HttpServer) handleEstimateFeeWithEstimator(params interface{},
 	}
 
 	result := jsonresult.NewEstimateFeeResult(estimateFeeCoinPerKb, 0)
+	if tokenId != nil {
+		estimateFeeCoinPerKbInPRV, err := httpServer.txService.EstimateFeeWithEstimatorInPRV(defaultFeeCoinPerKb, shardIDSender, numblock, tokenId, int64(beaconHeight), *httpServer.config.Database)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+		}
+		result.EstimateFeeCoinPerKbInPRV = estimateFeeCoinPerKbInPRV
+	}
 	Logger.log.Debugf("handleEstimateFeeWithEstimator result: %+v", result)
 	return result, nil
 }
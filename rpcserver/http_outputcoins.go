@@ -62,7 +62,7 @@ func (httpServer *HttpServer) handleListUnspentOutputCoins(params interface{}, c
 //component:
 //Parameter #1—the minimum number of confirmations an output must have
 //Parameter #2—the maximum number of confirmations an output may have
-//Parameter #3—the list paymentaddress-readonlykey which be used to view list outputcoin
+//Parameter #3—the list paymentaddress-readonlykey or viewingkey which be used to view list outputcoin
 //Parameter #4 - optional - token id - default prv coin
 func (httpServer *HttpServer) handleListOutputCoins(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	Logger.log.Debugf("handleListOutputCoins params: %+v", params)
@@ -24,6 +24,7 @@ type PDEWithdrawal struct {
 	WithdrawerAddressStr string
 	DeductingPoolValue   uint64
 	DeductingShares      uint64
+	TradingFeeAmt        uint64
 	PairToken1IDStr      string
 	PairToken2IDStr      string
 	TxReqID              common.Hash
@@ -44,6 +45,18 @@ type PDETrade struct {
 	BeaconHeight        uint64
 }
 
+type PDEMultiHopTrade struct {
+	TraderAddressStr    string
+	ReceivingTokenIDStr string
+	ReceiveAmount       uint64
+	TradePath           []string
+	Hops                []metadata.PDETradeHop
+	ShardID             byte
+	RequestedTxID       common.Hash
+	Status              string
+	BeaconHeight        uint64
+}
+
 type PDEContribution struct {
 	PDEContributionPairID string
 	ContributorAddressStr string
@@ -56,10 +69,11 @@ type PDEContribution struct {
 }
 
 type PDEInfoFromBeaconBlock struct {
-	PDEContributions []*PDEContribution `json:"PDEContributions"`
-	PDETrades        []*PDETrade        `json:"PDETrades"`
-	PDEWithdrawals   []*PDEWithdrawal   `json:"PDEWithdrawals"`
-	BeaconTimeStamp  int64              `json:"BeaconTimeStamp"`
+	PDEContributions  []*PDEContribution  `json:"PDEContributions"`
+	PDETrades         []*PDETrade         `json:"PDETrades"`
+	PDEMultiHopTrades []*PDEMultiHopTrade `json:"PDEMultiHopTrades"`
+	PDEWithdrawals    []*PDEWithdrawal    `json:"PDEWithdrawals"`
+	BeaconTimeStamp   int64               `json:"BeaconTimeStamp"`
 }
 
 type ConvertedPrice struct {
@@ -389,6 +403,363 @@ func (httpServer *HttpServer) handleCreateAndSendTxWithPTokenTradeReq(params int
 	return sendResult, nil
 }
 
+// newPDEMultiHopTradeRequestFromParams builds multi-hop trade metadata from rpc params
+func newPDEMultiHopTradeRequestFromParams(data map[string]interface{}) (*metadata.PDEMultiHopTradeRequest, *rpcservice.RPCError) {
+	tradePathData, ok := data["TradePath"].([]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	tradePath := []string{}
+	for _, tokenIDData := range tradePathData {
+		tokenIDStr, ok := tokenIDData.(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+		}
+		tradePath = append(tradePath, tokenIDStr)
+	}
+	sellAmountData, ok := data["SellAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	traderAddressStr, ok := data["TraderAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	minAcceptableAmountData, ok := data["MinAcceptableAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	tradingFeeData, ok := data["TradingFee"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, _ := metadata.NewPDEMultiHopTradeRequest(
+		tradePath,
+		uint64(sellAmountData),
+		uint64(minAcceptableAmountData),
+		uint64(tradingFeeData),
+		traderAddressStr,
+		metadata.PDEMultiHopTradeRequestMeta,
+	)
+	return meta, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithPRVMultiHopTradeReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, rpcErr := newPDEMultiHopTradeRequestFromParams(data)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	// create new param to build raw tx from param interface
+	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
+	if errNewParam != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errNewParam)
+	}
+
+	tx, err1 := httpServer.txService.BuildRawTransaction(createRawTxParam, meta, *httpServer.config.Database)
+	if err1 != nil {
+		Logger.log.Error(err1)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	byteArrays, err2 := json.Marshal(tx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithPRVMultiHopTradeReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithPRVMultiHopTradeReq(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	result := jsonresult.NewCreateTransactionResult(nil, sendResult.(jsonresult.CreateTransactionResult).TxID, nil, sendResult.(jsonresult.CreateTransactionResult).ShardID)
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithPTokenMultiHopTradeReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+
+	if len(arrayParams) >= 7 {
+		hasPrivacyToken := int(arrayParams[6].(float64)) > 0
+		if hasPrivacyToken {
+			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("The privacy mode must be disabled"))
+		}
+	}
+	tokenParamsRaw, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, rpcErr := newPDEMultiHopTradeRequestFromParams(tokenParamsRaw)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	customTokenTx, rpcErr := httpServer.txService.BuildRawPrivacyCustomTokenTransaction(params, meta, *httpServer.config.Database)
+	if rpcErr != nil {
+		Logger.log.Error(rpcErr)
+		return nil, rpcErr
+	}
+
+	byteArrays, err2 := json.Marshal(customTokenTx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            customTokenTx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithPTokenMultiHopTradeReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithPTokenMultiHopTradeReq(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err1 := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
+	if err1 != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	return sendResult, nil
+}
+
+func newPDELimitOrderRequestFromParams(data map[string]interface{}) (*metadata.PDELimitOrderRequest, *rpcservice.RPCError) {
+	tokenIDToBuyStr, ok := data["TokenIDToBuyStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	tokenIDToSellStr, ok := data["TokenIDToSellStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	sellAmountData, ok := data["SellAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	traderAddressStr, ok := data["TraderAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	minAcceptableAmountData, ok := data["MinAcceptableAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	tradingFeeData, ok := data["TradingFee"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, _ := metadata.NewPDELimitOrderRequest(
+		tokenIDToBuyStr,
+		tokenIDToSellStr,
+		uint64(sellAmountData),
+		uint64(minAcceptableAmountData),
+		uint64(tradingFeeData),
+		traderAddressStr,
+		metadata.PDELimitOrderRequestMeta,
+	)
+	return meta, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithPRVLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, rpcErr := newPDELimitOrderRequestFromParams(data)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	// create new param to build raw tx from param interface
+	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
+	if errNewParam != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errNewParam)
+	}
+
+	tx, err1 := httpServer.txService.BuildRawTransaction(createRawTxParam, meta, *httpServer.config.Database)
+	if err1 != nil {
+		Logger.log.Error(err1)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	byteArrays, err2 := json.Marshal(tx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithPRVLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithPRVLimitOrder(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	result := jsonresult.NewCreateTransactionResult(nil, sendResult.(jsonresult.CreateTransactionResult).TxID, nil, sendResult.(jsonresult.CreateTransactionResult).ShardID)
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithPTokenLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+
+	if len(arrayParams) >= 7 {
+		hasPrivacyToken := int(arrayParams[6].(float64)) > 0
+		if hasPrivacyToken {
+			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("The privacy mode must be disabled"))
+		}
+	}
+	tokenParamsRaw, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, rpcErr := newPDELimitOrderRequestFromParams(tokenParamsRaw)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	customTokenTx, rpcErr := httpServer.txService.BuildRawPrivacyCustomTokenTransaction(params, meta, *httpServer.config.Database)
+	if rpcErr != nil {
+		Logger.log.Error(rpcErr)
+		return nil, rpcErr
+	}
+
+	byteArrays, err2 := json.Marshal(customTokenTx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            customTokenTx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithPTokenLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithPTokenLimitOrder(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err1 := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
+	if err1 != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	return sendResult, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithCancelLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	orderID, ok := data["OrderID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	traderAddressStr, ok := data["TraderAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	meta, _ := metadata.NewPDECancelLimitOrderRequest(
+		orderID,
+		traderAddressStr,
+		metadata.PDECancelLimitOrderRequestMeta,
+	)
+
+	// create new param to build raw tx from param interface
+	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
+	if errNewParam != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errNewParam)
+	}
+
+	tx, err1 := httpServer.txService.BuildRawTransaction(createRawTxParam, meta, *httpServer.config.Database)
+	if err1 != nil {
+		Logger.log.Error(err1)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	byteArrays, err2 := json.Marshal(tx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithCancelLimitOrder(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithCancelLimitOrder(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	result := jsonresult.NewCreateTransactionResult(nil, sendResult.(jsonresult.CreateTransactionResult).TxID, nil, sendResult.(jsonresult.CreateTransactionResult).ShardID)
+	return result, nil
+}
+
 func (httpServer *HttpServer) handleCreateRawTxWithWithdrawalReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	arrayParams := common.InterfaceSlice(params)
 
@@ -490,6 +861,8 @@ func (httpServer *HttpServer) handleGetPDEState(params interface{}, closeChan <-
 		WaitingPDEContributions map[string]*lvdb.PDEContribution `json:"WaitingPDEContributions"`
 		PDEPoolPairs            map[string]*lvdb.PDEPoolForPair  `json:"PDEPoolPairs"`
 		PDEShares               map[string]uint64                `json:"PDEShares"`
+		PDELimitOrders          map[string]*lvdb.PDELimitOrder   `json:"PDELimitOrders"`
+		PDETradingFees          map[string]uint64                `json:"PDETradingFees"`
 		BeaconTimeStamp         int64                            `json:"BeaconTimeStamp"`
 	}
 	result := CurrentPDEState{
@@ -497,6 +870,8 @@ func (httpServer *HttpServer) handleGetPDEState(params interface{}, closeChan <-
 		PDEPoolPairs:            pdeState.PDEPoolPairs,
 		PDEShares:               pdeState.PDEShares,
 		WaitingPDEContributions: pdeState.WaitingPDEContributions,
+		PDELimitOrders:          pdeState.PDELimitOrders,
+		PDETradingFees:          pdeState.PDETradingFees,
 	}
 	return result, nil
 }
@@ -627,6 +1002,24 @@ func (httpServer *HttpServer) handleGetPDEWithdrawalStatus(params interface{}, c
 	return status, nil
 }
 
+func (httpServer *HttpServer) handleGetPDELimitOrderStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	data := arrayParams[0].(map[string]interface{})
+	txRequestIDStr, ok := data["TxRequestIDStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload is invalid"))
+	}
+	txIDHash, err := common.Hash{}.NewHashFromStr(txRequestIDStr)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.GetPDEStateError, err)
+	}
+	status, err := httpServer.databaseService.GetPDEStatus(lvdb.PDELimitOrderStatusPrefix, txIDHash[:])
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.GetPDEStateError, err)
+	}
+	return status, nil
+}
+
 func parsePDEContributionInst(inst []string, beaconHeight uint64) (*PDEContribution, error) {
 	status := inst[2]
 	shardID, err := strconv.Atoi(inst[1])
@@ -730,6 +1123,58 @@ func parsePDETradeInst(inst []string, beaconHeight uint64) (*PDETrade, error) {
 	return nil, nil
 }
 
+func parsePDEMultiHopTradeInst(inst []string, beaconHeight uint64) (*PDEMultiHopTrade, error) {
+	status := inst[2]
+	shardID, err := strconv.Atoi(inst[1])
+	if err != nil {
+		return nil, err
+	}
+	if status == common.PDETradeRefundChainStatus {
+		pdeMultiHopTradeReqAction, err := metadata.ParsePDEMultiHopTradeRequestAction(inst[3])
+		if err != nil {
+			return nil, err
+		}
+		if len(pdeMultiHopTradeReqAction.Meta.TradePath) == 0 {
+			return nil, errors.New("trade path is empty")
+		}
+		return &PDEMultiHopTrade{
+			TraderAddressStr:    pdeMultiHopTradeReqAction.Meta.TraderAddressStr,
+			ReceivingTokenIDStr: pdeMultiHopTradeReqAction.Meta.TradePath[0],
+			ReceiveAmount:       pdeMultiHopTradeReqAction.Meta.SellAmount + pdeMultiHopTradeReqAction.Meta.TradingFee,
+			TradePath:           pdeMultiHopTradeReqAction.Meta.TradePath,
+			Hops:                []metadata.PDETradeHop{},
+			ShardID:             byte(shardID),
+			RequestedTxID:       pdeMultiHopTradeReqAction.TxReqID,
+			Status:              "refunded",
+			BeaconHeight:        beaconHeight,
+		}, nil
+	}
+	if status == common.PDETradeAcceptedChainStatus {
+		var tradeAcceptedContent metadata.PDEMultiHopTradeAcceptedContent
+		err := json.Unmarshal([]byte(inst[3]), &tradeAcceptedContent)
+		if err != nil {
+			return nil, err
+		}
+		tradePath := []string{}
+		for _, hop := range tradeAcceptedContent.Hops {
+			tradePath = append(tradePath, hop.TokenIDToSellStr)
+		}
+		tradePath = append(tradePath, tradeAcceptedContent.TokenIDToBuyStr)
+		return &PDEMultiHopTrade{
+			TraderAddressStr:    tradeAcceptedContent.TraderAddressStr,
+			ReceivingTokenIDStr: tradeAcceptedContent.TokenIDToBuyStr,
+			ReceiveAmount:       tradeAcceptedContent.ReceiveAmount,
+			TradePath:           tradePath,
+			Hops:                tradeAcceptedContent.Hops,
+			ShardID:             byte(shardID),
+			RequestedTxID:       tradeAcceptedContent.RequestedTxID,
+			Status:              "accepted",
+			BeaconHeight:        beaconHeight,
+		}, nil
+	}
+	return nil, nil
+}
+
 func parsePDEWithdrawalInst(inst []string, beaconHeight uint64) (*PDEWithdrawal, error) {
 	status := inst[2]
 	shardID, err := strconv.Atoi(inst[1])
@@ -752,6 +1197,7 @@ func parsePDEWithdrawalInst(inst []string, beaconHeight uint64) (*PDEWithdrawal,
 			WithdrawerAddressStr: withdrawalAcceptedContent.WithdrawerAddressStr,
 			DeductingPoolValue:   withdrawalAcceptedContent.DeductingPoolValue,
 			DeductingShares:      withdrawalAcceptedContent.DeductingShares,
+			TradingFeeAmt:        withdrawalAcceptedContent.TradingFeeAmt,
 			PairToken1IDStr:      tokenIDStrs[0],
 			PairToken2IDStr:      tokenIDStrs[1],
 			TxReqID:              withdrawalAcceptedContent.TxReqID,
@@ -790,10 +1236,11 @@ func (httpServer *HttpServer) handleExtractPDEInstsFromBeaconBlock(
 	}
 	bcBlk := beaconBlocks[0]
 	pdeInfoFromBeaconBlock := PDEInfoFromBeaconBlock{
-		PDEContributions: []*PDEContribution{},
-		PDETrades:        []*PDETrade{},
-		PDEWithdrawals:   []*PDEWithdrawal{},
-		BeaconTimeStamp:  bcBlk.Header.Timestamp,
+		PDEContributions:  []*PDEContribution{},
+		PDETrades:         []*PDETrade{},
+		PDEMultiHopTrades: []*PDEMultiHopTrade{},
+		PDEWithdrawals:    []*PDEWithdrawal{},
+		BeaconTimeStamp:   bcBlk.Header.Timestamp,
 	}
 	insts := bcBlk.Body.Instructions
 	for _, inst := range insts {
@@ -813,6 +1260,12 @@ func (httpServer *HttpServer) handleExtractPDEInstsFromBeaconBlock(
 				continue
 			}
 			pdeInfoFromBeaconBlock.PDETrades = append(pdeInfoFromBeaconBlock.PDETrades, pdeTrade)
+		case strconv.Itoa(metadata.PDEMultiHopTradeRequestMeta):
+			pdeMultiHopTrade, err := parsePDEMultiHopTradeInst(inst, bcHeight)
+			if err != nil || pdeMultiHopTrade == nil {
+				continue
+			}
+			pdeInfoFromBeaconBlock.PDEMultiHopTrades = append(pdeInfoFromBeaconBlock.PDEMultiHopTrades, pdeMultiHopTrade)
 		case strconv.Itoa(metadata.PDEWithdrawalRequestMeta):
 			pdeWithdrawal, err := parsePDEWithdrawalInst(inst, bcHeight)
 			if err != nil || pdeWithdrawal == nil {
@@ -945,3 +1398,147 @@ func (httpServer *HttpServer) handleConvertPDEPrices(params interface{}, closeCh
 	}
 	return results, nil
 }
+
+// getPDEPairHistory parses the payload shared by pde history RPCs and returns candles of the pool pair,
+// NextFromHeight of the result is the FromHeight of the next page or 0 on the last page
+func (httpServer *HttpServer) getPDEPairHistory(params interface{}) ([]*blockchain.PDEPairCandle, uint64, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) == 0 {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
+	}
+	data, ok := arrayParams[0].(map[string]interface{})
+	if !ok {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
+	}
+	token1IDStr, ok := data["Token1IDStr"].(string)
+	if !ok {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Token1IDStr is invalid"))
+	}
+	token2IDStr, ok := data["Token2IDStr"].(string)
+	if !ok {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Token2IDStr is invalid"))
+	}
+	fromHeight, ok := data["FromHeight"].(float64)
+	if !ok {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("FromHeight is invalid"))
+	}
+	toHeight, ok := data["ToHeight"].(float64)
+	if !ok {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("ToHeight is invalid"))
+	}
+	// Interval and Limit are optional
+	interval, _ := data["Interval"].(float64)
+	limit, _ := data["Limit"].(float64)
+	candles, nextFromHeight, err := blockchain.GetPDEPairHistory(
+		httpServer.config.BlockChain.GetDatabase(),
+		token1IDStr,
+		token2IDStr,
+		uint64(fromHeight),
+		uint64(toHeight),
+		uint64(interval),
+		int(limit),
+	)
+	if err != nil {
+		return nil, 0, rpcservice.NewRPCError(rpcservice.GetPDEHistoryError, err)
+	}
+	return candles, nextFromHeight, nil
+}
+
+func (httpServer *HttpServer) handleGetPDEPriceHistory(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	candles, nextFromHeight, rpcErr := httpServer.getPDEPairHistory(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	type PDEPriceCandle struct {
+		FromHeight uint64
+		ToHeight   uint64
+		Open       float64
+		High       float64
+		Low        float64
+		Close      float64
+	}
+	result := struct {
+		Candles        []PDEPriceCandle
+		NextFromHeight uint64
+	}{
+		Candles:        []PDEPriceCandle{},
+		NextFromHeight: nextFromHeight,
+	}
+	for _, candle := range candles {
+		result.Candles = append(result.Candles, PDEPriceCandle{
+			FromHeight: candle.FromHeight,
+			ToHeight:   candle.ToHeight,
+			Open:       candle.Open,
+			High:       candle.High,
+			Low:        candle.Low,
+			Close:      candle.Close,
+		})
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleGetPDEVolumeHistory(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	candles, nextFromHeight, rpcErr := httpServer.getPDEPairHistory(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	type PDEVolumeCandle struct {
+		FromHeight      uint64
+		ToHeight        uint64
+		Token1Volume    uint64
+		Token2Volume    uint64
+		Token1FeeAmount uint64
+		Token2FeeAmount uint64
+		TradeCount      uint64
+	}
+	result := struct {
+		Candles        []PDEVolumeCandle
+		NextFromHeight uint64
+	}{
+		Candles:        []PDEVolumeCandle{},
+		NextFromHeight: nextFromHeight,
+	}
+	for _, candle := range candles {
+		result.Candles = append(result.Candles, PDEVolumeCandle{
+			FromHeight:      candle.FromHeight,
+			ToHeight:        candle.ToHeight,
+			Token1Volume:    candle.Token1Volume,
+			Token2Volume:    candle.Token2Volume,
+			Token1FeeAmount: candle.Token1FeeAmount,
+			Token2FeeAmount: candle.Token2FeeAmount,
+			TradeCount:      candle.TradeCount,
+		})
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleGetPDELiquidityHistory(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	candles, nextFromHeight, rpcErr := httpServer.getPDEPairHistory(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	type PDELiquidityCandle struct {
+		FromHeight      uint64
+		ToHeight        uint64
+		Token1PoolValue uint64
+		Token2PoolValue uint64
+		TotalShares     uint64
+	}
+	result := struct {
+		Candles        []PDELiquidityCandle
+		NextFromHeight uint64
+	}{
+		Candles:        []PDELiquidityCandle{},
+		NextFromHeight: nextFromHeight,
+	}
+	for _, candle := range candles {
+		result.Candles = append(result.Candles, PDELiquidityCandle{
+			FromHeight:      candle.FromHeight,
+			ToHeight:        candle.ToHeight,
+			Token1PoolValue: candle.Token1PoolValue,
+			Token2PoolValue: candle.Token2PoolValue,
+			TotalShares:     candle.TotalShares,
+		})
+	}
+	return result, nil
+}
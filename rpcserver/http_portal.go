@@ -0,0 +1,478 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/rpcserver/bean"
+	"github.com/incognitochain/incognito-chain/rpcserver/jsonresult"
+	"github.com/incognitochain/incognito-chain/rpcserver/rpcservice"
+)
+
+func (httpServer *HttpServer) handleGetPortalState(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	data, ok := arrayParams[0].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
+	}
+	beaconHeight, ok := data["BeaconHeight"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Beacon height is invalid"))
+	}
+	portalState, err := blockchain.InitCurrentPortalStateFromDB(httpServer.config.BlockChain.GetDatabase(), uint64(beaconHeight))
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.GetPortalStateError, err)
+	}
+	beaconBlock, err := httpServer.config.BlockChain.GetBeaconBlockByHeight(uint64(beaconHeight))
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.GetPortalStateError, err)
+	}
+	type CurrentPortalState struct {
+		CustodianPoolState     map[string]*lvdb.CustodianState `json:"CustodianPool"`
+		WaitingPortingRequests map[string]*lvdb.PortingRequest `json:"WaitingPortingRequests"`
+		WaitingRedeemRequests  map[string]*lvdb.RedeemRequest  `json:"WaitingRedeemRequests"`
+		BTCHeaderChainTip      *lvdb.BTCHeaderState            `json:"BTCHeaderChainTip"`
+		BeaconTimeStamp        int64                           `json:"BeaconTimeStamp"`
+	}
+	result := CurrentPortalState{
+		BeaconTimeStamp:        beaconBlock.Header.Timestamp,
+		CustodianPoolState:     portalState.CustodianPoolState,
+		WaitingPortingRequests: portalState.WaitingPortingRequests,
+		WaitingRedeemRequests:  portalState.WaitingRedeemRequests,
+		BTCHeaderChainTip:      portalState.BTCHeaderChainTip,
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) createRawTxWithPortalMeta(params interface{}, meta metadata.Metadata) (interface{}, *rpcservice.RPCError) {
+	// create new param to build raw tx from param interface
+	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
+	if errNewParam != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errNewParam)
+	}
+
+	tx, err1 := httpServer.txService.BuildRawTransaction(createRawTxParam, meta, *httpServer.config.Database)
+	if err1 != nil {
+		Logger.log.Error(err1)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+
+	byteArrays, err2 := json.Marshal(tx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) sendRawTxWithPortalMeta(data interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	result := jsonresult.NewCreateTransactionResult(nil, sendResult.(jsonresult.CreateTransactionResult).TxID, nil, sendResult.(jsonresult.CreateTransactionResult).ShardID)
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithCustodianDeposit(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	incognitoAddress, ok := data["IncognitoAddress"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata IncognitoAddress is invalid"))
+	}
+	remoteAddressesData, ok := data["RemoteAddresses"].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RemoteAddresses is invalid"))
+	}
+	remoteAddresses := make(map[string]string)
+	for tokenID, remoteAddressData := range remoteAddressesData {
+		remoteAddress, ok := remoteAddressData.(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RemoteAddresses is invalid"))
+		}
+		remoteAddresses[tokenID] = remoteAddress
+	}
+	depositedAmountData, ok := data["DepositedAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata DepositedAmount is invalid"))
+	}
+	meta, _ := metadata.NewPortalCustodianDeposit(
+		metadata.PortalCustodianDepositMeta,
+		incognitoAddress,
+		remoteAddresses,
+		uint64(depositedAmountData),
+	)
+	return httpServer.createRawTxWithPortalMeta(params, meta)
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithCustodianDeposit(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithCustodianDeposit(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return httpServer.sendRawTxWithPortalMeta(data, closeChan)
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithPortingRequest(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	uniqueRegisterID, ok := data["UniqueRegisterId"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata UniqueRegisterId is invalid"))
+	}
+	incognitoAddress, ok := data["IncogAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata IncogAddressStr is invalid"))
+	}
+	pTokenID, ok := data["PTokenId"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata PTokenId is invalid"))
+	}
+	registerAmountData, ok := data["RegisterAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RegisterAmount is invalid"))
+	}
+	portingFeeData, ok := data["PortingFee"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata PortingFee is invalid"))
+	}
+	meta, _ := metadata.NewPortalUserRegister(
+		uniqueRegisterID,
+		incognitoAddress,
+		pTokenID,
+		uint64(registerAmountData),
+		uint64(portingFeeData),
+		metadata.PortalUserRegisterMeta,
+	)
+	return httpServer.createRawTxWithPortalMeta(params, meta)
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithPortingRequest(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithPortingRequest(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return httpServer.sendRawTxWithPortalMeta(data, closeChan)
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithReqPToken(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	uniquePortingID, ok := data["UniquePortingID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata UniquePortingID is invalid"))
+	}
+	tokenID, ok := data["TokenID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata TokenID is invalid"))
+	}
+	incognitoAddress, ok := data["IncogAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata IncogAddressStr is invalid"))
+	}
+	portingAmountData, ok := data["PortingAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata PortingAmount is invalid"))
+	}
+	portingProof, ok := data["PortingProof"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata PortingProof is invalid"))
+	}
+	meta, _ := metadata.NewPortalUserRequestPToken(
+		uniquePortingID,
+		incognitoAddress,
+		tokenID,
+		uint64(portingAmountData),
+		portingProof,
+		metadata.PortalUserRequestPTokenMeta,
+	)
+	return httpServer.createRawTxWithPortalMeta(params, meta)
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithReqPToken(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithReqPToken(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return httpServer.sendRawTxWithPortalMeta(data, closeChan)
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithRedeemReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+	if len(arrayParams) >= 7 {
+		hasPrivacyToken := int(arrayParams[6].(float64)) > 0
+		if hasPrivacyToken {
+			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("The privacy mode must be disabled"))
+		}
+	}
+	tokenParamsRaw, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	uniqueRedeemID, ok := tokenParamsRaw["UniqueRedeemID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata UniqueRedeemID is invalid"))
+	}
+	redeemTokenID, ok := tokenParamsRaw["RedeemTokenID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RedeemTokenID is invalid"))
+	}
+	redeemAmountData, ok := tokenParamsRaw["RedeemAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RedeemAmount is invalid"))
+	}
+	redeemerIncAddressStr, ok := tokenParamsRaw["RedeemerIncAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RedeemerIncAddressStr is invalid"))
+	}
+	remoteAddress, ok := tokenParamsRaw["RemoteAddress"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RemoteAddress is invalid"))
+	}
+	meta, _ := metadata.NewPortalRedeemRequest(
+		metadata.PortalRedeemRequestMeta,
+		uniqueRedeemID,
+		redeemTokenID,
+		uint64(redeemAmountData),
+		redeemerIncAddressStr,
+		remoteAddress,
+	)
+
+	customTokenTx, rpcErr := httpServer.txService.BuildRawPrivacyCustomTokenTransaction(params, meta, *httpServer.config.Database)
+	if rpcErr != nil {
+		Logger.log.Error(rpcErr)
+		return nil, rpcErr
+	}
+
+	byteArrays, err2 := json.Marshal(customTokenTx)
+	if err2 != nil {
+		Logger.log.Error(err2)
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
+	}
+	result := jsonresult.CreateTransactionResult{
+		TxID:            customTokenTx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+	}
+	return result, nil
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithRedeemReq(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithRedeemReq(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+
+	tx := data.(jsonresult.CreateTransactionResult)
+	base58CheckData := tx.Base58CheckData
+	newParam := make([]interface{}, 0)
+	newParam = append(newParam, base58CheckData)
+	sendResult, err1 := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
+	if err1 != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
+	}
+	return sendResult, nil
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithReqUnlockCollateral(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	uniqueRedeemID, ok := data["UniqueRedeemID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata UniqueRedeemID is invalid"))
+	}
+	tokenID, ok := data["TokenID"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata TokenID is invalid"))
+	}
+	custodianAddressStr, ok := data["CustodianAddressStr"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata CustodianAddressStr is invalid"))
+	}
+	redeemAmountData, ok := data["RedeemAmount"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RedeemAmount is invalid"))
+	}
+	redeemProof, ok := data["RedeemProof"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata RedeemProof is invalid"))
+	}
+	meta, _ := metadata.NewPortalRequestUnlockCollateral(
+		metadata.PortalRequestUnlockCollateralMeta,
+		uniqueRedeemID,
+		tokenID,
+		custodianAddressStr,
+		uint64(redeemAmountData),
+		redeemProof,
+	)
+	return httpServer.createRawTxWithPortalMeta(params, meta)
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithReqUnlockCollateral(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithReqUnlockCollateral(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return httpServer.sendRawTxWithPortalMeta(data, closeChan)
+}
+
+func (httpServer *HttpServer) handleCreateRawTxWithRelayingBTCHeader(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 5 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 5"))
+	}
+
+	// get meta data from params
+	data, ok := arrayParams[4].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata is invalid"))
+	}
+	headersData, ok := data["Headers"].([]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata Headers is invalid"))
+	}
+	headers := make([]string, 0, len(headersData))
+	for _, headerData := range headersData {
+		header, ok := headerData.(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata Headers is invalid"))
+		}
+		headers = append(headers, header)
+	}
+	meta, _ := metadata.NewPortalRelayingBTCHeader(
+		metadata.PortalRelayingBTCHeaderMeta,
+		headers,
+	)
+	return httpServer.createRawTxWithPortalMeta(params, meta)
+}
+
+func (httpServer *HttpServer) handleCreateAndSendTxWithRelayingBTCHeader(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	data, err := httpServer.handleCreateRawTxWithRelayingBTCHeader(params, closeChan)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return httpServer.sendRawTxWithPortalMeta(data, closeChan)
+}
+
+func getPortalStatusParam(params interface{}, paramName string) (string, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 1 {
+		return "", rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Param array must be at least 1"))
+	}
+	data, ok := arrayParams[0].(map[string]interface{})
+	if !ok {
+		return "", rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload is invalid"))
+	}
+	value, ok := data[paramName].(string)
+	if !ok {
+		return "", rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload is invalid"))
+	}
+	return value, nil
+}
+
+func (httpServer *HttpServer) getPortalStatus(prefix []byte, suffix string, status interface{}) (interface{}, *rpcservice.RPCError) {
+	found, err := httpServer.databaseService.GetPortalStatus(prefix, []byte(suffix), status)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.GetPortalStateError, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return status, nil
+}
+
+func (httpServer *HttpServer) handleGetPortalCustodianDepositStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	txRequestIDStr, rpcErr := getPortalStatusParam(params, "DepositTxID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalCustodianDepositStatusPrefix, txRequestIDStr, &metadata.PortalCustodianDepositStatus{})
+}
+
+func (httpServer *HttpServer) handleGetPortalPortingRequestStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	uniquePortingID, rpcErr := getPortalStatusParam(params, "UniquePortingID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalPortingRequestStatusPrefix, uniquePortingID, &metadata.PortalPortingRequestStatus{})
+}
+
+func (httpServer *HttpServer) handleGetPortalReqPTokenStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	txRequestIDStr, rpcErr := getPortalStatusParam(params, "ReqTxID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalReqPTokenStatusPrefix, txRequestIDStr, &metadata.PortalRequestPTokensStatus{})
+}
+
+func (httpServer *HttpServer) handleGetPortalRedeemRequestStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	uniqueRedeemID, rpcErr := getPortalStatusParam(params, "UniqueRedeemID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalRedeemRequestStatusPrefix, uniqueRedeemID, &metadata.PortalRedeemRequestStatus{})
+}
+
+func (httpServer *HttpServer) handleGetPortalReqUnlockCollateralStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	txRequestIDStr, rpcErr := getPortalStatusParam(params, "ReqTxID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalReqUnlockCollateralStatusPrefix, txRequestIDStr, &metadata.PortalRequestUnlockCollateralStatus{})
+}
+
+func (httpServer *HttpServer) handleGetPortalRelayingBTCHeaderStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	txRequestIDStr, rpcErr := getPortalStatusParam(params, "ReqTxID")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.getPortalStatus(lvdb.PortalRelayingBTCHeaderStatusPrefix, txRequestIDStr, &metadata.PortalRelayingBTCHeaderStatus{})
+}
@@ -35,8 +35,32 @@ func (httpServer *HttpServer) handleCreateRawTransaction(params interface{}, clo
 	return result, nil
 }
 
+// handleCreateUnsignedTransaction - RPC builds a PRV transfer template for a sender known by its viewing key,
+// the tx is proved and signed offline by the signtransaction command of incognitoctl
+// Parameter #1—the viewing key of the sender
+// Parameter #2—the receivers: payment address -> amount
+// Parameter #3—the fee per kb
+// Parameter #4—1 with privacy, -1 without
+// Parameter #5—the serial numbers of the coins spent by the previous signed txs: coin commitment -> serial number (optional)
+// Parameter #6—info (optional)
+func (httpServer *HttpServer) handleCreateUnsignedTransaction(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	Logger.log.Debugf("handleCreateUnsignedTransaction params: %+v", params)
+
+	createUnsignedTxParam, errNewParam := bean.NewCreateUnsignedTxParam(params)
+	if errNewParam != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errNewParam)
+	}
+
+	result, err := httpServer.txService.BuildUnsignedTransaction(createUnsignedTxParam, *httpServer.config.Database)
+	if err != nil {
+		return nil, err
+	}
+	Logger.log.Debugf("handleCreateUnsignedTransaction result: %+v", result)
+	return result, nil
+}
+
 // handleSendTransaction implements the sendtransaction command.
-// Parameter #1—a serialized transaction to broadcast
+// Parameter #1—a serialized transaction to broadcast, or the output of the offline signer
 // Parameter #2–whether to allow high fees
 // Result—a TXID or error Message
 func (httpServer *HttpServer) handleSendRawTransaction(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
@@ -48,7 +72,14 @@ func (httpServer *HttpServer) handleSendRawTransaction(params interface{}, close
 
 	base58CheckData, ok := arrayParams[0].(string)
 	if !ok {
-		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("base58 check data is invalid"))
+		// the output of the offline signer is accepted as is
+		signedTx, isMap := arrayParams[0].(map[string]interface{})
+		if isMap {
+			base58CheckData, ok = signedTx["Base58CheckData"].(string)
+		}
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("base58 check data is invalid"))
+		}
 	}
 
 	txMsg, txHash, LastBytePubKeySender, err := httpServer.txService.SendRawTransaction(base58CheckData)
@@ -121,6 +152,15 @@ func (httpServer *HttpServer) handleGetTransactionByReceiver(params interface{},
 	// create a key set
 	keySet := incognitokey.KeySet{}
 
+	// a viewing key holds both the payment address and the readonly key
+	if viewingKeyStr, ok := keys["ViewingKey"].(string); ok && viewingKeyStr != "" {
+		viewingKeySet, _, err := rpcservice.GetKeySetFromViewingKeyParam(viewingKeyStr)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+		}
+		return httpServer.txService.GetTransactionByReceiver(*viewingKeySet)
+	}
+
 	// get keyset only contain readonly-key by deserializing
 	readonlyKeyStr, ok := keys["ReadonlyKey"].(string)
 	if ok {
@@ -148,6 +188,63 @@ func (httpServer *HttpServer) handleGetTransactionByReceiver(params interface{},
 	return result, err
 }
 
+// handleGetTransactionHistory - get a page of the transactions sending or receiving coins of a payment address,
+// params: keys {"PaymentAddress", optional "ReadonlyKey" to reveal hidden amounts} or {"ViewingKey"}, optional cursor, optional limit
+func (httpServer *HttpServer) handleGetTransactionHistory(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	paramsArray := common.InterfaceSlice(params)
+	if len(paramsArray) < 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
+	}
+	keys, ok := paramsArray[0].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("key param is invalid"))
+	}
+
+	keySet := incognitokey.KeySet{}
+	if viewingKeyStr, ok := keys["ViewingKey"].(string); ok && viewingKeyStr != "" {
+		viewingKeySet, _, err := rpcservice.GetKeySetFromViewingKeyParam(viewingKeyStr)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+		}
+		keySet = *viewingKeySet
+	} else {
+		paymentAddressStr, ok := keys["PaymentAddress"].(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("payment address is invalid"))
+		}
+		paymentAddress, err := wallet.Base58CheckDeserialize(paymentAddressStr)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+		}
+		keySet.PaymentAddress = paymentAddress.KeySet.PaymentAddress
+		if readonlyKeyStr, ok := keys["ReadonlyKey"].(string); ok && readonlyKeyStr != "" {
+			readonlyKey, err := wallet.Base58CheckDeserialize(readonlyKeyStr)
+			if err != nil {
+				return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+			}
+			keySet.ReadonlyKey = readonlyKey.KeySet.ReadonlyKey
+		}
+	}
+
+	cursor := ""
+	if len(paramsArray) > 1 && paramsArray[1] != nil {
+		cursor, ok = paramsArray[1].(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("cursor is invalid"))
+		}
+	}
+	limit := 0
+	if len(paramsArray) > 2 && paramsArray[2] != nil {
+		limitParam, ok := paramsArray[2].(float64)
+		if !ok || limitParam < 0 {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("limit is invalid"))
+		}
+		limit = int(limitParam)
+	}
+
+	return httpServer.txService.GetTransactionHistory(keySet, cursor, limit)
+}
+
 // Get transaction by Hash
 func (httpServer *HttpServer) handleGetTransactionByHash(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	Logger.log.Debugf("handleGetTransactionByHash params: %+v", params)
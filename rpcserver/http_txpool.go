@@ -17,6 +17,17 @@ func (httpServer *HttpServer) handleGetMempoolInfo(params interface{}, closeChan
 	return result, nil
 }
 
+/*
+handleGetMempoolRestoreResult - RPC returns txs restored from mempool database when node started,
+and reason of each dropped tx
+*/
+func (httpServer *HttpServer) handleGetMempoolRestoreResult(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	Logger.log.Debugf("handleGetMempoolRestoreResult params: %+v", params)
+	result := jsonresult.NewGetMempoolRestoreResult(httpServer.config.TxMemPool)
+	Logger.log.Debugf("handleGetMempoolRestoreResult result: %+v", result)
+	return result, nil
+}
+
 /*
 handleGetRawMempool - RPC returns all transaction ids in memory pool as a json array of string transaction ids
 Hint: use getmempoolentry to fetch a specific transaction from the mempool.
@@ -58,6 +69,10 @@ func (httpServer *HttpServer) handleMempoolEntry(params interface{}, closeChan <
 
 	txInPool, shardID, err := httpServer.txMemPoolService.MempoolEntry(txIDParam)
 	if err != nil {
+		// tx may be evicted from mempool by a replacement tx
+		if replacedBy, ok := httpServer.txMemPoolService.MempoolEntryReplacedBy(txIDParam); ok {
+			return &jsonresult.TransactionDetail{Hash: txIDParam, ReplacedBy: replacedBy}, nil
+		}
 		return nil, err
 	}
 
@@ -67,6 +82,7 @@ func (httpServer *HttpServer) handleMempoolEntry(params interface{}, closeChan <
 		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, errM)
 	}
 	tx.IsInMempool = true
+	tx.Replaces = httpServer.txMemPoolService.MempoolEntryReplacedTxs(txIDParam)
 	Logger.log.Debugf("handleMempoolEntry result: %+v", tx)
 	return tx, nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"time"
 
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/common/base58"
@@ -103,6 +104,24 @@ func (httpServer *HttpServer) handleDumpPrivkey(params interface{}, closeChan <-
 	return result, nil
 }
 
+/*
+handleDumpViewingKey - RPC returns the viewing key of the wallet account of a payment address,
+the viewing key shows the coins of the account and their amounts to whoever holds it without letting them spend
+Parameter #1—the payment address of the account
+Result—the viewing key
+*/
+func (httpServer *HttpServer) handleDumpViewingKey(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
+	}
+	paymentAddress, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("payment address is invalid"))
+	}
+	return httpServer.walletService.DumpViewingKey(paymentAddress), nil
+}
+
 /*
 handleImportAccount - import a new account by private-key
 - Param #1: private-key string
@@ -140,6 +159,40 @@ func (httpServer *HttpServer) handleImportAccount(params interface{}, closeChan
 	return result, nil
 }
 
+/*
+handleImportViewingKey - import a watch-only account by viewing key, the node never gets its private key
+- Param #1: viewing key string
+- Param #2: account name
+- Param #3: passPhrase of wallet
+*/
+func (httpServer *HttpServer) handleImportViewingKey(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 3 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 3 elements"))
+	}
+
+	viewingKey, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("viewingKey is invalid"))
+	}
+
+	accountName, ok := arrayParams[1].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("accountName is invalid"))
+	}
+
+	passPhrase, ok := arrayParams[2].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("passPhrase is invalid"))
+	}
+
+	result, err := httpServer.walletService.ImportViewingKey(viewingKey, accountName, passPhrase)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	}
+	return result, nil
+}
+
 func (httpServer *HttpServer) handleRemoveAccount(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	Logger.log.Debugf("handleRemoveAccount params: %+v", params)
 	arrayParams := common.InterfaceSlice(params)
@@ -177,7 +230,7 @@ func (httpServer *HttpServer) handleGetBalanceByPrivatekey(params interface{}, c
 	return httpServer.walletService.GetBalanceByPrivateKey(senderKeyParam)
 }
 
-// handleGetBalanceByPaymentAddress -  return balance of paymentaddress
+// handleGetBalanceByPaymentAddress -  return balance of paymentaddress, a viewing key can be given instead to count the amounts hidden by privacy
 func (httpServer *HttpServer) handleGetBalanceByPaymentAddress(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 
 	// all component
@@ -194,6 +247,35 @@ func (httpServer *HttpServer) handleGetBalanceByPaymentAddress(params interface{
 	return httpServer.walletService.GetBalanceByPaymentAddress(paymentAddressParam)
 }
 
+// handleGetBalanceByViewingKey - return the amount received by the payment address of a viewing key,
+// spent coins are counted too since only the private key tells them apart
+// Param #1: viewing key
+// Param #2: optional - token id - default prv coin
+func (httpServer *HttpServer) handleGetBalanceByViewingKey(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
+	}
+	viewingKeyParam, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("viewing key is invalid"))
+	}
+	tokenID := common.PRVCoinID
+	if len(arrayParams) > 1 && arrayParams[1] != nil {
+		tokenIDParam, ok := arrayParams[1].(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("token id param is invalid"))
+		}
+		tokenIDTemp, err := common.Hash{}.NewHashFromStr(tokenIDParam)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.TokenIsInvalidError, err)
+		}
+		tokenID = *tokenIDTemp
+	}
+
+	return httpServer.walletService.GetBalanceByViewingKey(viewingKeyParam, tokenID)
+}
+
 /*
 handleGetBalance - RPC gets the balances in decimal
 */
@@ -201,7 +283,7 @@ func (httpServer *HttpServer) handleGetBalance(params interface{}, closeChan <-c
 	if httpServer.config.Wallet == nil {
 		return uint64(0), rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("wallet is not existed"))
 	}
-	if len(httpServer.config.Wallet.MasterAccount.Child) == 0 {
+	if len(httpServer.config.Wallet.Accounts()) == 0 {
 		return uint64(0), rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("no account is existed"))
 	}
 
@@ -230,7 +312,7 @@ func (httpServer *HttpServer) handleGetBalance(params interface{}, closeChan <-c
 		return uint64(0), rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("passPhrase is invalid"))
 	}
 
-	if passPhrase != httpServer.config.Wallet.PassPhrase {
+	if httpServer.config.Wallet.CheckPassPhrase(passPhrase) != nil {
 		return uint64(0), rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("password phrase is wrong for local wallet"))
 	}
 
@@ -247,7 +329,7 @@ func (httpServer *HttpServer) handleGetReceivedByAccount(params interface{}, clo
 	if httpServer.config.Wallet == nil {
 		return balance, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("wallet is not existed"))
 	}
-	if len(httpServer.config.Wallet.MasterAccount.Child) == 0 {
+	if len(httpServer.config.Wallet.Accounts()) == 0 {
 		return balance, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("no account is existed"))
 	}
 
@@ -276,7 +358,7 @@ func (httpServer *HttpServer) handleGetReceivedByAccount(params interface{}, clo
 		return balance, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("passPhrase is invalid"))
 	}
 
-	if passPhrase != httpServer.config.Wallet.PassPhrase {
+	if httpServer.config.Wallet.CheckPassPhrase(passPhrase) != nil {
 		return balance, rpcservice.NewRPCError(rpcservice.UnexpectedError, errors.New("password phrase is wrong for local wallet"))
 	}
 
@@ -297,6 +379,112 @@ func (httpServer *HttpServer) handleSetTxFee(params interface{}, closeChan <-cha
 	return err == nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
 }
 
+/*
+handleWalletPassphrase - RPC unlocks a wallet of the keystore for a while, its private keys are zeroed when the timeout expires
+- Param #1: wallet name, empty string for the node wallet
+- Param #2: passPhrase of wallet
+- Param #3: timeout in seconds
+- Param #4: (optional) account name, only the private key of this account is unlocked
+*/
+func (httpServer *HttpServer) handleWalletPassphrase(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 3 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 3 elements"))
+	}
+
+	walletName, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("walletName is invalid"))
+	}
+
+	passPhrase, ok := arrayParams[1].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("passPhrase is invalid"))
+	}
+
+	timeout, ok := arrayParams[2].(float64)
+	if !ok || timeout <= 0 || timeout > maxWalletUnlockTimeoutSeconds {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("timeout is invalid"))
+	}
+
+	accountName := ""
+	if len(arrayParams) > 3 {
+		accountName, ok = arrayParams[3].(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("accountName is invalid"))
+		}
+	}
+
+	return httpServer.walletService.WalletPassphrase(walletName, passPhrase, time.Duration(timeout*float64(time.Second)), accountName)
+}
+
+/*
+handleWalletLock - RPC locks a wallet of the keystore right away
+- Param #1: wallet name, empty string for the node wallet
+*/
+func (httpServer *HttpServer) handleWalletLock(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
+	}
+
+	walletName, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("walletName is invalid"))
+	}
+
+	return httpServer.walletService.WalletLock(walletName)
+}
+
+/*
+handleListWallets - RPC lists the wallets of the keystore and which of them are unlocked
+*/
+func (httpServer *HttpServer) handleListWallets(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	return httpServer.walletService.ListWallets()
+}
+
+/*
+handleGetConsolidationStatus - RPC gets the number of unspent output coins of every account and token of the wallet
+and the txs which consolidated them
+*/
+func (httpServer *HttpServer) handleGetConsolidationStatus(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	return httpServer.walletService.GetConsolidationStatus(), nil
+}
+
+/*
+handleCreateWallet - RPC creates a new wallet in the keystore, it stays locked until walletpassphrase
+- Param #1: wallet name
+- Param #2: passPhrase of wallet
+- Param #3: (optional) number of accounts, default is 1
+*/
+func (httpServer *HttpServer) handleCreateWallet(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if arrayParams == nil || len(arrayParams) < 2 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 2 elements"))
+	}
+
+	walletName, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("walletName is invalid"))
+	}
+
+	passPhrase, ok := arrayParams[1].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("passPhrase is invalid"))
+	}
+
+	numOfAccount := uint32(0)
+	if len(arrayParams) > 2 {
+		numOfAccountTemp, ok := arrayParams[2].(float64)
+		if !ok || numOfAccountTemp < 0 {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("numOfAccount is invalid"))
+		}
+		numOfAccount = uint32(numOfAccountTemp)
+	}
+
+	return httpServer.walletService.CreateWallet(walletName, passPhrase, numOfAccount)
+}
+
 func (httpServer *HttpServer) handleListPrivacyCustomToken(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	listPrivacyToken, listPrivacyTokenCrossShard, err := httpServer.blockService.ListPrivacyCustomTokenCached()
 	if err != nil {
@@ -1,8 +1,9 @@
 package jsonresult
 
 type EstimateFeeResult struct {
-	EstimateFeeCoinPerKb uint64
-	EstimateTxSizeInKb   uint64
+	EstimateFeeCoinPerKb      uint64
+	EstimateTxSizeInKb        uint64
+	EstimateFeeCoinPerKbInPRV uint64 // PRV equivalent of EstimateFeeCoinPerKb, which is in pToken if a token id is given
 }
 
 func NewEstimateFeeResult(estimateFeeCoinPerKb uint64, estimateTxSizeInKb uint64) *EstimateFeeResult {
@@ -10,5 +11,6 @@ func NewEstimateFeeResult(estimateFeeCoinPerKb uint64, estimateTxSizeInKb uint64
 		EstimateFeeCoinPerKb: estimateFeeCoinPerKb,
 		EstimateTxSizeInKb:   estimateTxSizeInKb,
 	}
+	result.EstimateFeeCoinPerKbInPRV = estimateFeeCoinPerKb
 	return result
 }
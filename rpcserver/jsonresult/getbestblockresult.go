@@ -14,6 +14,21 @@ type GetBestBlockItem struct {
 	ValidationData string `json:"ValidationData"`
 	Epoch          uint64 `json:"Epoch"`
 	Time           int64  `json:"Time"`
+
+	FinalizedHeight  uint64 `json:"FinalizedHeight,omitempty"`
+	FinalizedHash    string `json:"FinalizedHash,omitempty"`
+	NumberOfBranches int    `json:"NumberOfBranches,omitempty"`
+}
+
+// SetFinality - add the last finalized block and number of fork branches of chain
+func (item *GetBestBlockItem) SetFinality(tree *blockchain.BlockTree) {
+	if tree == nil {
+		return
+	}
+	finalizedHash, finalizedHeight := tree.GetFinalizedBlock()
+	item.FinalizedHeight = finalizedHeight
+	item.FinalizedHash = finalizedHash.String()
+	item.NumberOfBranches = tree.GetNumberOfBranches()
 }
 
 func NewGetBestBlockItemFromShard(bestState *blockchain.ShardBestState) *GetBestBlockItem {
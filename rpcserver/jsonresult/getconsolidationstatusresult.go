@@ -0,0 +1,20 @@
+package jsonresult
+
+type ConsolidationStatus struct {
+	Account      string `json:"Account"`
+	TokenID      string `json:"TokenID"`
+	UnspentCoins int    `json:"UnspentCoins"`
+	// PendingTxs are the consolidation txs of this account and token still in mem pool
+	PendingTxs      []string `json:"PendingTxs"`
+	ConsolidatedTxs uint64   `json:"ConsolidatedTxs"`
+	LastCheck       int64    `json:"LastCheck"`
+	LastError       string   `json:"LastError"`
+}
+
+type GetConsolidationStatusResult struct {
+	Enabled   bool                  `json:"Enabled"`
+	Threshold int                   `json:"Threshold"`
+	BatchSize int                   `json:"BatchSize"`
+	Interval  int64                 `json:"Interval"` // seconds
+	Status    []ConsolidationStatus `json:"Status"`
+}
@@ -3,6 +3,7 @@ package jsonresult
 import (
 	"sort"
 
+	"github.com/incognitochain/incognito-chain/common"
 	"github.com/incognitochain/incognito-chain/mempool"
 	"github.com/incognitochain/incognito-chain/metadata"
 )
@@ -54,6 +55,41 @@ func NewGetMempoolInfoTx(tx metadata.Transaction) *GetMempoolInfoTx {
 	return result
 }
 
+type GetMempoolRestoreResult struct {
+	Restored int                 `json:"Restored"`
+	Dropped  int                 `json:"Dropped"`
+	Txs      []MempoolRestoredTx `json:"Txs"`
+}
+
+type MempoolRestoredTx struct {
+	TxID     string `json:"TxID,omitempty"`
+	Restored bool   `json:"Restored"`
+	Reason   string `json:"Reason,omitempty"`
+}
+
+func NewGetMempoolRestoreResult(txMempool *mempool.TxPool) *GetMempoolRestoreResult {
+	result := &GetMempoolRestoreResult{
+		Txs: []MempoolRestoredTx{},
+	}
+	for _, restoreResult := range txMempool.GetRestoreResults() {
+		item := MempoolRestoredTx{
+			Restored: restoreResult.Restored,
+			Reason:   restoreResult.Reason,
+		}
+		// corrupted journal record has no tx id
+		if !restoreResult.TxHash.IsEqual(&common.Hash{}) {
+			item.TxID = restoreResult.TxHash.String()
+		}
+		if item.Restored {
+			result.Restored++
+		} else {
+			result.Dropped++
+		}
+		result.Txs = append(result.Txs, item)
+	}
+	return result
+}
+
 type GetRawMempoolResult struct {
 	TxHashes []string
 }
@@ -45,6 +45,9 @@ type TransactionDetail struct {
 	IsInMempool bool `json:"IsInMempool"`
 	IsInBlock   bool `json:"IsInBlock"`
 
+	Replaces   []string `json:"Replaces,omitempty"`   // txs evicted from mempool when this tx replaced them by fee
+	ReplacedBy string   `json:"ReplacedBy,omitempty"` // tx which replaced this tx in mempool by fee
+
 	Info string `json:"Info"`
 }
 
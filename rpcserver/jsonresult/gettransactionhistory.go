@@ -0,0 +1,27 @@
+package jsonresult
+
+import (
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+type TransactionHistoryEntry struct {
+	TxHash       string      `json:"TxHash"`
+	TxType       string      `json:"TxType"`
+	ShardID      byte        `json:"ShardID"`
+	BlockHeight  uint64      `json:"BlockHeight"`
+	BeaconHeight uint64      `json:"BeaconHeight"`
+	TxIndex      int         `json:"TxIndex"`
+	Direction    string      `json:"Direction"`
+	TokenID      common.Hash `json:"TokenID"`
+	IsPrivacy    bool        `json:"IsPrivacy"`
+	Amount       uint64      `json:"Amount"`
+	// IsAmountHidden is true when Amount could not be revealed, privacy entries need the read-only key of the receiver
+	IsAmountHidden bool   `json:"IsAmountHidden"`
+	Fee            uint64 `json:"Fee"`
+}
+
+type TransactionHistory struct {
+	Entries []TransactionHistoryEntry `json:"Entries"`
+	// NextCursor is passed to the next call to get the following entries, it stays the same when there is no new entry
+	NextCursor string `json:"NextCursor"`
+}
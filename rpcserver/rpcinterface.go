@@ -32,6 +32,7 @@ var HttpHandler = map[string]httpHandler{
 	getNumberOfTxsInMempool: (*HttpServer).handleGetNumberOfTxsInMempool,
 	getMempoolEntry:         (*HttpServer).handleMempoolEntry,
 	removeTxInMempool:       (*HttpServer).handleRemoveTxInMempool,
+	getMempoolRestoreResult: (*HttpServer).handleGetMempoolRestoreResult,
 	getMempoolInfo:          (*HttpServer).handleGetMempoolInfo,
 	getPendingTxsInBlockgen: (*HttpServer).handleGetPendingTxsInBlockgen,
 
@@ -64,10 +65,12 @@ var HttpHandler = map[string]httpHandler{
 	listOutputCoins:                         (*HttpServer).handleListOutputCoins,
 	createRawTransaction:                    (*HttpServer).handleCreateRawTransaction,
 	sendRawTransaction:                      (*HttpServer).handleSendRawTransaction,
+	createUnsignedTransaction:               (*HttpServer).handleCreateUnsignedTransaction,
 	createAndSendTransaction:                (*HttpServer).handleCreateAndSendTx,
 	getTransactionByHash:                    (*HttpServer).handleGetTransactionByHash,
 	gettransactionhashbyreceiver:            (*HttpServer).handleGetTransactionHashByReceiver,
 	gettransactionbyreceiver:                (*HttpServer).handleGetTransactionByReceiver,
+	getTransactionHistory:                   (*HttpServer).handleGetTransactionHistory,
 	createAndSendStakingTransaction:         (*HttpServer).handleCreateAndSendStakingTx,
 	createAndSendStopAutoStakingTransaction: (*HttpServer).handleCreateAndSendStopAutoStakingTransaction,
 	randomCommitments:                       (*HttpServer).handleRandomCommitments,
@@ -153,18 +156,48 @@ var HttpHandler = map[string]httpHandler{
 	getProducersBlackListDetail: (*HttpServer).handleGetProducersBlackListDetail,
 
 	// pde
-	getPDEState:                           (*HttpServer).handleGetPDEState,
-	createAndSendTxWithWithdrawalReq:      (*HttpServer).handleCreateAndSendTxWithWithdrawalReq,
-	createAndSendTxWithPTokenTradeReq:     (*HttpServer).handleCreateAndSendTxWithPTokenTradeReq,
-	createAndSendTxWithPRVTradeReq:        (*HttpServer).handleCreateAndSendTxWithPRVTradeReq,
-	createAndSendTxWithPTokenContribution: (*HttpServer).handleCreateAndSendTxWithPTokenContribution,
-	createAndSendTxWithPRVContribution:    (*HttpServer).handleCreateAndSendTxWithPRVContribution,
-	getPDEContributionStatus:              (*HttpServer).handleGetPDEContributionStatus,
-	getPDEContributionStatusV2:            (*HttpServer).handleGetPDEContributionStatusV2,
-	getPDETradeStatus:                     (*HttpServer).handleGetPDETradeStatus,
-	getPDEWithdrawalStatus:                (*HttpServer).handleGetPDEWithdrawalStatus,
-	convertPDEPrices:                      (*HttpServer).handleConvertPDEPrices,
-	extractPDEInstsFromBeaconBlock:        (*HttpServer).handleExtractPDEInstsFromBeaconBlock,
+	getPDEState:                               (*HttpServer).handleGetPDEState,
+	createAndSendTxWithWithdrawalReq:          (*HttpServer).handleCreateAndSendTxWithWithdrawalReq,
+	createAndSendTxWithPTokenTradeReq:         (*HttpServer).handleCreateAndSendTxWithPTokenTradeReq,
+	createAndSendTxWithPRVTradeReq:            (*HttpServer).handleCreateAndSendTxWithPRVTradeReq,
+	createAndSendTxWithPTokenMultiHopTradeReq: (*HttpServer).handleCreateAndSendTxWithPTokenMultiHopTradeReq,
+	createAndSendTxWithPRVMultiHopTradeReq:    (*HttpServer).handleCreateAndSendTxWithPRVMultiHopTradeReq,
+	createAndSendTxWithPTokenLimitOrder:       (*HttpServer).handleCreateAndSendTxWithPTokenLimitOrder,
+	createAndSendTxWithPRVLimitOrder:          (*HttpServer).handleCreateAndSendTxWithPRVLimitOrder,
+	createAndSendTxWithCancelLimitOrder:       (*HttpServer).handleCreateAndSendTxWithCancelLimitOrder,
+	createAndSendTxWithPTokenContribution:     (*HttpServer).handleCreateAndSendTxWithPTokenContribution,
+	createAndSendTxWithPRVContribution:        (*HttpServer).handleCreateAndSendTxWithPRVContribution,
+	getPDEContributionStatus:                  (*HttpServer).handleGetPDEContributionStatus,
+	getPDEContributionStatusV2:                (*HttpServer).handleGetPDEContributionStatusV2,
+	getPDETradeStatus:                         (*HttpServer).handleGetPDETradeStatus,
+	getPDEWithdrawalStatus:                    (*HttpServer).handleGetPDEWithdrawalStatus,
+	getPDELimitOrderStatus:                    (*HttpServer).handleGetPDELimitOrderStatus,
+	convertPDEPrices:                          (*HttpServer).handleConvertPDEPrices,
+	extractPDEInstsFromBeaconBlock:            (*HttpServer).handleExtractPDEInstsFromBeaconBlock,
+	getPDEPriceHistory:                        (*HttpServer).handleGetPDEPriceHistory,
+	getPDEVolumeHistory:                       (*HttpServer).handleGetPDEVolumeHistory,
+	getPDELiquidityHistory:                    (*HttpServer).handleGetPDELiquidityHistory,
+
+	// portal
+	getPortalState:                         (*HttpServer).handleGetPortalState,
+	createRawTxWithCustodianDeposit:        (*HttpServer).handleCreateRawTxWithCustodianDeposit,
+	createAndSendTxWithCustodianDeposit:    (*HttpServer).handleCreateAndSendTxWithCustodianDeposit,
+	createRawTxWithPortingRequest:          (*HttpServer).handleCreateRawTxWithPortingRequest,
+	createAndSendTxWithPortingRequest:      (*HttpServer).handleCreateAndSendTxWithPortingRequest,
+	createRawTxWithReqPToken:               (*HttpServer).handleCreateRawTxWithReqPToken,
+	createAndSendTxWithReqPToken:           (*HttpServer).handleCreateAndSendTxWithReqPToken,
+	createRawTxWithRedeemReq:               (*HttpServer).handleCreateRawTxWithRedeemReq,
+	createAndSendTxWithRedeemReq:           (*HttpServer).handleCreateAndSendTxWithRedeemReq,
+	createRawTxWithReqUnlockCollateral:     (*HttpServer).handleCreateRawTxWithReqUnlockCollateral,
+	createAndSendTxWithReqUnlockCollateral: (*HttpServer).handleCreateAndSendTxWithReqUnlockCollateral,
+	getPortalCustodianDepositStatus:        (*HttpServer).handleGetPortalCustodianDepositStatus,
+	getPortalPortingRequestStatus:          (*HttpServer).handleGetPortalPortingRequestStatus,
+	getPortalReqPTokenStatus:               (*HttpServer).handleGetPortalReqPTokenStatus,
+	getPortalRedeemRequestStatus:           (*HttpServer).handleGetPortalRedeemRequestStatus,
+	getPortalReqUnlockCollateralStatus:     (*HttpServer).handleGetPortalReqUnlockCollateralStatus,
+	createRawTxWithRelayingBTCHeader:       (*HttpServer).handleCreateRawTxWithRelayingBTCHeader,
+	createAndSendTxWithRelayingBTCHeader:   (*HttpServer).handleCreateAndSendTxWithRelayingBTCHeader,
+	getPortalRelayingBTCHeaderStatus:       (*HttpServer).handleGetPortalRelayingBTCHeaderStatus,
 
 	getBurningAddress: (*HttpServer).handleGetBurningAddress,
 }
@@ -177,14 +210,22 @@ var LimitedHttpHandler = map[string]httpHandler{
 	getAddressesByAccount:            (*HttpServer).handleGetAddressesByAccount,
 	getAccountAddress:                (*HttpServer).handleGetAccountAddress,
 	dumpPrivkey:                      (*HttpServer).handleDumpPrivkey,
+	dumpViewingKey:                   (*HttpServer).handleDumpViewingKey,
 	importAccount:                    (*HttpServer).handleImportAccount,
+	importViewingKey:                 (*HttpServer).handleImportViewingKey,
 	removeAccount:                    (*HttpServer).handleRemoveAccount,
 	listUnspentOutputCoins:           (*HttpServer).handleListUnspentOutputCoins,
 	getBalance:                       (*HttpServer).handleGetBalance,
 	getBalanceByPrivatekey:           (*HttpServer).handleGetBalanceByPrivatekey,
 	getBalanceByPaymentAddress:       (*HttpServer).handleGetBalanceByPaymentAddress,
+	getBalanceByViewingKey:           (*HttpServer).handleGetBalanceByViewingKey,
 	getReceivedByAccount:             (*HttpServer).handleGetReceivedByAccount,
 	setTxFee:                         (*HttpServer).handleSetTxFee,
+	walletPassphrase:                 (*HttpServer).handleWalletPassphrase,
+	walletLock:                       (*HttpServer).handleWalletLock,
+	listWallets:                      (*HttpServer).handleListWallets,
+	createWallet:                     (*HttpServer).handleCreateWallet,
+	getConsolidationStatus:           (*HttpServer).handleGetConsolidationStatus,
 	convertNativeTokenToPrivacyToken: (*HttpServer).handleConvertNativeTokenToPrivacyToken,
 	convertPrivacyTokenToNativeToken: (*HttpServer).handleConvertPrivacyTokenToNativeToken,
 }
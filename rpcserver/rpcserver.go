@@ -15,15 +15,17 @@ import (
 	"github.com/incognitochain/incognito-chain/mempool"
 	"github.com/incognitochain/incognito-chain/netsync"
 	"github.com/incognitochain/incognito-chain/pubsub"
+	"github.com/incognitochain/incognito-chain/rpcserver/rpcservice"
 	"github.com/incognitochain/incognito-chain/wallet"
 	"github.com/incognitochain/incognito-chain/wire"
 	peer2 "github.com/libp2p/go-libp2p-peer"
 )
 
 const (
-	rpcAuthTimeoutSeconds    = 60
-	rpcProcessTimeoutSeconds = 90
-	RpcServerVersion         = "1.0"
+	rpcAuthTimeoutSeconds         = 60
+	rpcProcessTimeoutSeconds      = 90
+	RpcServerVersion              = "1.0"
+	maxWalletUnlockTimeoutSeconds = 100000000
 )
 
 // timeZeroVal is simply the zero value for a time.Time and is used to avoid
@@ -66,6 +68,7 @@ type RpcServerConfig struct {
 	MemCache        *memcache.MemoryCache
 	Database        *database.DatabaseInterface
 	Wallet          *wallet.Wallet
+	Keystore        *wallet.Keystore
 	ConnMgr         *connmanager.ConnManager
 	AddrMgr         *addrmanager.AddrManager
 	NodeMode        string
@@ -110,6 +113,9 @@ type RpcServerConfig struct {
 	// IsMiningNode    bool   // flag mining node. True: mining, False: not mining
 	MiningKeys    string // encode of mining key
 	PubSubManager *pubsub.PubSubManager
+
+	// background consolidation of the output coins of Wallet
+	WalletConsolidation rpcservice.WalletConsolidationConfig
 }
 
 func (rpcServer *RpcServer) Init(config *RpcServerConfig) {
@@ -16,6 +16,14 @@ import (
 	"github.com/incognitochain/incognito-chain/transaction"
 )
 
+// NewBlockRPCError reports blocks pruned on this node with BlockPrunedError instead of the generic block error
+func NewBlockRPCError(key int, err error) *RPCError {
+	if bcErr, ok := err.(*blockchain.BlockChainError); ok && bcErr.Code == blockchain.ErrCodeMessage[blockchain.BlockPrunedError].Code {
+		return NewRPCError(BlockPrunedError, err)
+	}
+	return NewRPCError(key, err)
+}
+
 type BlockService struct {
 	BlockChain *blockchain.BlockChain
 	DB         *database.DatabaseInterface
@@ -54,6 +62,14 @@ func (blockService BlockService) GetShardBestStateByShardID(shardID byte) (*bloc
 	return shard, err
 }
 
+func (blockService BlockService) GetBeaconBlockTree() *blockchain.BlockTree {
+	return blockService.BlockChain.GetBeaconBlockTree()
+}
+
+func (blockService BlockService) GetShardBlockTree(shardID byte) *blockchain.BlockTree {
+	return blockService.BlockChain.GetShardBlockTree(shardID)
+}
+
 func (blockService BlockService) GetShardBestBlocks() map[byte]blockchain.ShardBlock {
 	bestBlocks := make(map[byte]blockchain.ShardBlock)
 	shards := blockService.BlockChain.BestState.GetClonedAllShardBestState()
@@ -135,7 +151,7 @@ func (blockService BlockService) RetrieveShardBlock(hashString string, verbosity
 	block, _, errD := blockService.BlockChain.GetShardBlockByHash(*hash)
 	if errD != nil {
 		Logger.log.Debugf("handleRetrieveBlock result: %+v, err: %+v", nil, errD)
-		return nil, NewRPCError(GetShardBlockByHashError, errD)
+		return nil, NewBlockRPCError(GetShardBlockByHashError, errD)
 	}
 	result := jsonresult.GetBlockResult{}
 
@@ -158,7 +174,7 @@ func (blockService BlockService) RetrieveShardBlock(hashString string, verbosity
 		if blockHeight < best.Header.Height {
 			nextHash, err := blockService.BlockChain.GetShardBlockByHeight(blockHeight+1, shardID)
 			if err != nil {
-				return nil, NewRPCError(GetShardBlockByHeightError, err)
+				return nil, NewBlockRPCError(GetShardBlockByHeightError, err)
 			}
 			nextHashString = nextHash.Hash().String()
 		}
@@ -203,7 +219,7 @@ func (blockService BlockService) RetrieveShardBlock(hashString string, verbosity
 			nextHash, err := blockService.BlockChain.GetShardBlockByHeight(blockHeight+1, shardID)
 			if err != nil {
 				Logger.log.Debugf("handleRetrieveBlock result: %+v, err: %+v", nil, err)
-				return nil, NewRPCError(GetShardBlockByHeightError, err)
+				return nil, NewBlockRPCError(GetShardBlockByHeightError, err)
 			}
 			nextHashString = nextHash.Hash().String()
 		}
@@ -265,7 +281,7 @@ func (blockService BlockService) RetrieveShardBlockByHeight(blockHeight uint64,
 	block, errD := blockService.BlockChain.GetShardBlockByHeight(blockHeight, byte(shardId))
 	if errD != nil {
 		Logger.log.Debugf("handleRetrieveBlock result: %+v, err: %+v", nil, errD)
-		return nil, NewRPCError(GetShardBlockByHashError, errD)
+		return nil, NewBlockRPCError(GetShardBlockByHashError, errD)
 	}
 	result := jsonresult.GetBlockResult{}
 
@@ -287,7 +303,7 @@ func (blockService BlockService) RetrieveShardBlockByHeight(blockHeight uint64,
 		if blockHeight < best.Header.Height {
 			nextHash, err := blockService.BlockChain.GetShardBlockByHeight(blockHeight+1, shardID)
 			if err != nil {
-				return nil, NewRPCError(GetShardBlockByHeightError, err)
+				return nil, NewBlockRPCError(GetShardBlockByHeightError, err)
 			}
 			nextHashString = nextHash.Hash().String()
 		}
@@ -332,7 +348,7 @@ func (blockService BlockService) RetrieveShardBlockByHeight(blockHeight uint64,
 			nextHash, err := blockService.BlockChain.GetShardBlockByHeight(blockHeight+1, shardID)
 			if err != nil {
 				Logger.log.Debugf("handleRetrieveBlock result: %+v, err: %+v", nil, err)
-				return nil, NewRPCError(GetShardBlockByHeightError, err)
+				return nil, NewBlockRPCError(GetShardBlockByHeightError, err)
 			}
 			nextHashString = nextHash.Hash().String()
 		}
@@ -399,7 +415,7 @@ func (blockService BlockService) RetrieveBeaconBlock(hashString string) (*jsonre
 	block, _, errD := blockService.BlockChain.GetBeaconBlockByHash(*hash)
 	if errD != nil {
 		Logger.log.Debugf("handleRetrieveBeaconBlock result: %+v, err: %+v", nil, errD)
-		return nil, NewRPCError(GetBeaconBlockByHashError, errD)
+		return nil, NewBlockRPCError(GetBeaconBlockByHashError, errD)
 	}
 
 	best := blockService.BlockChain.BestState.Beacon.BestBlock
@@ -411,7 +427,7 @@ func (blockService BlockService) RetrieveBeaconBlock(hashString string) (*jsonre
 		nextHash, err := blockService.BlockChain.GetBeaconBlockByHeight(blockHeight + 1)
 		if err != nil {
 			Logger.log.Debugf("handleRetrieveBeaconBlock result: %+v, err: %+v", nil, err)
-			return nil, NewRPCError(GetBeaconBlockByHeightError, err)
+			return nil, NewBlockRPCError(GetBeaconBlockByHeightError, err)
 		}
 		nextHashString = nextHash.Hash().String()
 	}
@@ -427,7 +443,7 @@ func (blockService BlockService) RetrieveBeaconBlockByHeigh(blockHeight uint64)
 	block, errD := blockService.BlockChain.GetBeaconBlockByHeight(blockHeight)
 	if errD != nil {
 		Logger.log.Debugf("handleRetrieveBeaconBlock result: %+v, err: %+v", nil, errD)
-		return nil, NewRPCError(GetBeaconBlockByHashError, errD)
+		return nil, NewBlockRPCError(GetBeaconBlockByHashError, errD)
 	}
 
 	best := blockService.BlockChain.BestState.Beacon.BestBlock
@@ -438,7 +454,7 @@ func (blockService BlockService) RetrieveBeaconBlockByHeigh(blockHeight uint64)
 		nextHash, err := blockService.BlockChain.GetBeaconBlockByHeight(blockHeight + 1)
 		if err != nil {
 			Logger.log.Debugf("handleRetrieveBeaconBlock result: %+v, err: %+v", nil, err)
-			return nil, NewRPCError(GetBeaconBlockByHeightError, err)
+			return nil, NewBlockRPCError(GetBeaconBlockByHeightError, err)
 		}
 		nextHashString = nextHash.Hash().String()
 	}
@@ -493,7 +509,7 @@ func (blockService BlockService) GetBlocks(shardIDParam int, numBlock int) (inte
 				block, size, errD := blockService.BlockChain.GetShardBlockByHash(*previousHash)
 				if errD != nil {
 					Logger.log.Debugf("handleGetBlocks result: %+v, err: %+v", nil, errD)
-					return nil, NewRPCError(GetShardBlockByHashError, errD)
+					return nil, NewBlockRPCError(GetShardBlockByHashError, errD)
 				}
 				blockResult := jsonresult.NewGetBlockResult(block, size, common.EmptyString)
 				result = append(result, *blockResult)
@@ -527,7 +543,7 @@ func (blockService BlockService) GetBlocks(shardIDParam int, numBlock int) (inte
 				// block, errD := blockService.BlockChain.GetBlockByHash(previousHash)
 				block, size, errD := blockService.BlockChain.GetBeaconBlockByHash(*previousHash)
 				if errD != nil {
-					return nil, NewRPCError(GetBeaconBlockByHashError, errD)
+					return nil, NewBlockRPCError(GetBeaconBlockByHashError, errD)
 				}
 				blockResult := jsonresult.NewGetBlocksBeaconResult(block, size, common.EmptyString)
 				resultBeacon = append(resultBeacon, *blockResult)
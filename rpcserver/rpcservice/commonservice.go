@@ -131,6 +131,25 @@ func GetKeySetFromPaymentAddressParam(paymentAddressStr string) (*incognitokey.K
 	return &keyWallet.KeySet, shardID, nil
 }
 
+// GetKeySetFromViewingKeyParam - deserialize a viewing key string(wallet serialized)
+// into a key set with the payment address and readonly key of an account but no private key
+// return key set and shard ID
+func GetKeySetFromViewingKeyParam(viewingKeyStr string) (*incognitokey.KeySet, byte, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(viewingKeyStr)
+	if err != nil {
+		return nil, byte(0), err
+	}
+	if !keyWallet.IsWatchOnly() || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 || len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, byte(0), errors.New("invalid viewing key string")
+	}
+
+	// calculate shard ID
+	lastByte := keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1]
+	shardID := common.GetShardIDFromLastByte(lastByte)
+
+	return &keyWallet.KeySet, shardID, nil
+}
+
 func NewPaymentInfosFromReceiversParam(receiversParam map[string]interface{}) ([]*privacy.PaymentInfo, error) {
 	paymentInfos := make([]*privacy.PaymentInfo, 0)
 	for paymentAddressStr, amount := range receiversParam {
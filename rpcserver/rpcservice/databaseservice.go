@@ -151,6 +151,22 @@ func (dbService DatabaseService) GetPDEStatus(pdePrefix []byte, pdeSuffix []byte
 	return (*dbService.DB).GetPDEStatus(pdePrefix, pdeSuffix)
 }
 
+// GetPortalStatus unmarshals the tracked portal status into status, returns false if it is not found
+func (dbService DatabaseService) GetPortalStatus(portalPrefix []byte, portalSuffix []byte, status interface{}) (bool, error) {
+	portalStatusContentBytes, err := (*dbService.DB).GetPortalStatus(portalPrefix, portalSuffix)
+	if err != nil {
+		return false, err
+	}
+	if len(portalStatusContentBytes) == 0 {
+		return false, nil
+	}
+	err = json.Unmarshal(portalStatusContentBytes, status)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (dbService DatabaseService) GetPDEContributionStatus(pdePrefix []byte, pdeSuffix []byte) (*metadata.PDEContributionStatus, error) {
 	pdeStatusContentBytes, err := (*dbService.DB).GetPDEContributionStatus(pdePrefix, pdeSuffix)
 	if err != nil {
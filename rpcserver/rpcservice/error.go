@@ -30,6 +30,7 @@ const (
 	SendTxDataError
 	Base58ChedkDataOfTxInvalid
 	JsonDataOfTxInvalid
+	GetTxHistoryError
 	TxTypeInvalidError
 	TxNotExistedInMemAndBLockError
 	UnsubcribeError
@@ -45,10 +46,15 @@ const (
 	GetShardBlockByHashError
 	GetBeaconBlockByHashError
 	GetBeaconBlockByHeightError
+	BlockPrunedError
 	GeTxFromPoolError
 	NoSwapConfirmInst
 	GetKeySetFromPrivateKeyError
 	GetPDEStateError
+	GetPDEHistoryError
+	GetPortalStateError
+	WalletKeystoreError
+	WalletConsolidationError
 
 	// reject tx
 	RejectInvalidTxFeeError
@@ -102,6 +108,7 @@ var ErrCodeMessage = map[int]struct {
 	GetBeaconBlockByHeightError: {-2004, "Get beacon block by height error"},
 	GetBeaconBestBlockHashError: {-2004, "Get beacon best block hash error"},
 	GetBeaconBestBlockError:     {-2005, "Get beacon best block error"},
+	BlockPrunedError:            {-2006, "Block is pruned on this node"},
 
 	// best state -3xxx
 	GetClonedBeaconBestStateError: {-3000, "Get Cloned Beacon Best State Error"},
@@ -112,6 +119,7 @@ var ErrCodeMessage = map[int]struct {
 	SendTxDataError:            {-4002, "Can not send tx"},
 	Base58ChedkDataOfTxInvalid: {-4003, "Base58Check encode data of tx is invalid, can not decode"},
 	JsonDataOfTxInvalid:        {-4004, "Json string data of tx is invalid, can not unmarshal"},
+	GetTxHistoryError:          {-4005, "Get transaction history error"},
 
 	// socket/subcribe -5xxx
 	SubcribeError:   {-5000, "Failed to subcribe"},
@@ -132,7 +140,15 @@ var ErrCodeMessage = map[int]struct {
 	NoSwapConfirmInst: {-7000, "No swap confirm instruction found in block"},
 
 	// pde
-	GetPDEStateError: {-8000, "Get pde state error"},
+	GetPDEStateError:   {-8000, "Get pde state error"},
+	GetPDEHistoryError: {-8001, "Get pde history error"},
+
+	// portal
+	GetPortalStateError: {-9000, "Get portal state error"},
+
+	// wallet keystore
+	WalletKeystoreError:      {-10000, "Wallet keystore error"},
+	WalletConsolidationError: {-10001, "Wallet consolidation error"},
 }
 
 // RPCError represents an error that is used as a part of a JSON-RPC JsonResponse
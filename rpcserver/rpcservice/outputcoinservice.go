@@ -78,6 +78,24 @@ func (coinService CoinService) ListOutputCoinsByKey(listKeyParams []interface{},
 			return nil, NewRPCError(RPCInvalidParamsError, errors.New("key param is invalid"))
 		}
 
+		// a viewing key holds both the payment address and the readonly key
+		if viewingKeyStr, ok := keys["ViewingKey"].(string); ok && viewingKeyStr != "" {
+			keySet, shardID, err := GetKeySetFromViewingKeyParam(viewingKeyStr)
+			if err != nil {
+				return nil, NewRPCError(RPCInvalidParamsError, err)
+			}
+			outputCoins, err := coinService.BlockChain.GetListOutputCoinsByKeyset(keySet, shardID, &tokenID)
+			if err != nil {
+				return nil, NewRPCError(UnexpectedError, err)
+			}
+			item := make([]jsonresult.OutCoin, 0)
+			for _, outCoin := range outputCoins {
+				item = append(item, jsonresult.NewOutCoin(outCoin))
+			}
+			result.Outputs[viewingKeyStr] = item
+			continue
+		}
+
 		// get keyset only contain readonly-key by deserializing(optional)
 		var readonlyKey *wallet.KeyWallet
 		var err error
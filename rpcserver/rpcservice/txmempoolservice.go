@@ -48,6 +48,32 @@ func (txMemPoolService TxMemPoolService) MempoolEntry(txIDString string) (metada
 	return txInPool, shardIDTemp, nil
 }
 
+// MempoolEntryReplacedTxs - return txs which were replaced by fee by the tx in mempool
+func (txMemPoolService TxMemPoolService) MempoolEntryReplacedTxs(txIDString string) []string {
+	txID, err := common.Hash{}.NewHashFromStr(txIDString)
+	if err != nil {
+		return nil
+	}
+	result := []string{}
+	for _, replacedTx := range txMemPoolService.TxMemPool.GetReplacedTxs(txID) {
+		result = append(result, replacedTx.String())
+	}
+	return result
+}
+
+// MempoolEntryReplacedBy - return tx which replaced by fee a tx no longer in mempool
+func (txMemPoolService TxMemPoolService) MempoolEntryReplacedBy(txIDString string) (string, bool) {
+	txID, err := common.Hash{}.NewHashFromStr(txIDString)
+	if err != nil {
+		return "", false
+	}
+	replacedBy, ok := txMemPoolService.TxMemPool.GetReplacedBy(txID)
+	if !ok {
+		return "", false
+	}
+	return replacedBy.String(), true
+}
+
 func (txMemPoolService * TxMemPoolService) RemoveTxInMempool(txIDString string) (bool, *RPCError) {
 	txID, err := common.Hash{}.NewHashFromStr(txIDString)
 	if err != nil {
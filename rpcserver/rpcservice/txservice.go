@@ -106,16 +106,6 @@ func (txService TxService) chooseOutsCoinByKeyset(
 	unitFeePToken int64,
 	db database.DatabaseInterface,
 ) ([]*privacy.InputCoin, uint64, *RPCError) {
-	// estimate fee according to 8 recent block
-	if numBlock == 0 {
-		numBlock = 1000
-	}
-	// calculate total amount to send
-	totalAmmount := uint64(0)
-	for _, receiver := range paymentInfos {
-		totalAmmount += receiver.Amount
-	}
-
 	// get list outputcoins tx
 	prvCoinID := &common.Hash{}
 	prvCoinID.SetBytes(common.PRVCoinID[:])
@@ -128,6 +118,30 @@ func (txService TxService) chooseOutsCoinByKeyset(
 	if err != nil {
 		return nil, 0, NewRPCError(GetOutputCoinError, err)
 	}
+	return txService.chooseOutsCoin(outCoins, paymentInfos, unitFeeNativeToken, numBlock, keySet, shardIDSender, hasPrivacy,
+		metadataParam, privacyCustomTokenParams, db)
+}
+
+// chooseOutsCoin returns the input coins native token to spent among the unspent outCoins of keySet and the real fee
+func (txService TxService) chooseOutsCoin(
+	outCoins []*privacy.OutputCoin,
+	paymentInfos []*privacy.PaymentInfo,
+	unitFeeNativeToken int64, numBlock uint64, keySet *incognitokey.KeySet, shardIDSender byte,
+	hasPrivacy bool,
+	metadataParam metadata.Metadata,
+	privacyCustomTokenParams *transaction.CustomTokenPrivacyParamTx,
+	db database.DatabaseInterface,
+) ([]*privacy.InputCoin, uint64, *RPCError) {
+	// estimate fee according to 8 recent block
+	if numBlock == 0 {
+		numBlock = 1000
+	}
+	// calculate total amount to send
+	totalAmmount := uint64(0)
+	for _, receiver := range paymentInfos {
+		totalAmmount += receiver.Amount
+	}
+
 	if len(outCoins) == 0 && totalAmmount > 0 {
 		return nil, 0, NewRPCError(GetOutputCoinError, errors.New("not enough output coin"))
 	}
@@ -293,6 +307,46 @@ func (txService TxService) EstimateFeeWithEstimator(defaultFee int64, shardID by
 	}
 }
 
+// EstimateFeeWithEstimatorInPRV - PRV equivalent of fee per kb estimated by EstimateFeeWithEstimator
+// if tokenID != nil: estimate from txs paying fee in pToken observed by estimator, fee in pToken is converted by pde price if there is none
+// if tokenID == nil: return fee per kb for native token
+func (txService TxService) EstimateFeeWithEstimatorInPRV(defaultFee int64, shardID byte, numBlock uint64, tokenId *common.Hash, beaconHeight int64, db database.DatabaseInterface) (uint64, error) {
+	if tokenId == nil {
+		return txService.EstimateFeeWithEstimator(defaultFee, shardID, numBlock, tokenId, beaconHeight, db)
+	}
+	if defaultFee == 0 {
+		return uint64(defaultFee), nil
+	}
+
+	unitFee := uint64(0)
+	if defaultFee == -1 {
+		// estimate fee on the blocks before from txs paying fee in pToken
+		if feeEstimator, ok := txService.FeeEstimator[shardID]; ok {
+			_, temp, _ := feeEstimator.EstimateFeeForToken(numBlock, tokenId)
+			unitFee = uint64(temp)
+		}
+	}
+	if unitFee == 0 {
+		unitFeePToken, err := txService.EstimateFeeWithEstimator(defaultFee, shardID, numBlock, tokenId, beaconHeight, db)
+		if err != nil {
+			return uint64(0), err
+		}
+		unitFeeTmp, err := metadata.ConvertPrivacyTokenToNativeToken(unitFeePToken, tokenId, beaconHeight, db)
+		if err != nil {
+			return uint64(0), err
+		}
+		unitFee = uint64(math.Ceil(unitFeeTmp))
+	}
+
+	// check with limit fee
+	if feeEstimator, ok := txService.FeeEstimator[shardID]; ok {
+		if limitFee := feeEstimator.GetLimitFeeForNativeToken(); unitFee < limitFee {
+			unitFee = limitFee
+		}
+	}
+	return unitFee, nil
+}
+
 func (txService TxService) BuildRawTransaction(params *bean.CreateRawTxParam, meta metadata.Metadata, db database.DatabaseInterface) (*transaction.Tx, *RPCError) {
 	Logger.log.Infof("Params: \n%+v\n\n\n", params)
 
@@ -345,6 +399,51 @@ func (txService TxService) CreateRawTransaction(params *bean.CreateRawTxParam, m
 	return tx.Hash(), txBytes, txShardID, nil
 }
 
+// BuildUnsignedTransaction chooses the coins and the fee of a PRV transfer for a sender known only by its viewing key
+// and returns the template the offline signer proves and signs.
+// Without the private key the serial numbers of the coins are unknown: the ones given in params,
+// reported by the signer of the previous txs, leave out the coins already spent on chain or in mem pool
+func (txService TxService) BuildUnsignedTransaction(params *bean.CreateUnsignedTxParam, db database.DatabaseInterface) (*transaction.UnsignedTx, *RPCError) {
+	prvCoinID := &common.Hash{}
+	prvCoinID.SetBytes(common.PRVCoinID[:])
+	outCoins, err := txService.BlockChain.GetListOutputCoinsByKeyset(params.SenderKeySet, params.ShardIDSender, prvCoinID)
+	if err != nil {
+		return nil, NewRPCError(GetOutputCoinError, err)
+	}
+	unspentOutCoins := make([]*privacy.OutputCoin, 0, len(outCoins))
+	for _, outCoin := range outCoins {
+		commitment := base58.Base58Check{}.Encode(outCoin.CoinDetails.GetCoinCommitment().ToBytesS(), common.ZeroByte)
+		serialNumberStr, ok := params.SerialNumbers[commitment]
+		if !ok {
+			unspentOutCoins = append(unspentOutCoins, outCoin)
+			continue
+		}
+		serialNumber, _, err := base58.Base58Check{}.Decode(serialNumberStr)
+		if err != nil {
+			return nil, NewRPCError(RPCInvalidParamsError, err)
+		}
+		spent, err := db.HasSerialNumber(*prvCoinID, serialNumber, params.ShardIDSender)
+		if err != nil {
+			return nil, NewRPCError(GetOutputCoinError, err)
+		}
+		if !spent && txService.TxMemPool.ValidateSerialNumberHashH(serialNumber) == nil {
+			unspentOutCoins = append(unspentOutCoins, outCoin)
+		}
+	}
+
+	inputCoins, realFee, err1 := txService.chooseOutsCoin(unspentOutCoins, params.PaymentInfos, params.EstimateFeeCoinPerKb, 0,
+		params.SenderKeySet, params.ShardIDSender, params.HasPrivacyCoin, nil, nil, db)
+	if err1 != nil {
+		return nil, err1
+	}
+	utx, err := transaction.NewUnsignedTx(params.SenderKeySet.PaymentAddress, params.PaymentInfos, inputCoins, realFee,
+		params.HasPrivacyCoin, db, params.Info)
+	if err != nil {
+		return nil, NewRPCError(CreateTxDataError, err)
+	}
+	return utx, nil
+}
+
 func (txService TxService) SendRawTransaction(txB58Check string) (wire.Message, *common.Hash, byte, *RPCError) {
 	// Decode base58check data of tx
 	rawTxBytes, _, err := base58.Base58Check{}.Decode(txB58Check)
@@ -628,6 +727,9 @@ func (txService TxService) GetTransactionByHash(txHashStr string) (*jsonresult.T
 		// maybe tx is still in tx mempool -> check mempool
 		tx, errM := txService.TxMemPool.GetTx(txHash)
 		if errM != nil {
+			if txService.BlockChain.IsPruningEnabled() {
+				return nil, NewRPCError(TxNotExistedInMemAndBLockError, errors.New("Tx is not existed in block or mempool, transactions of pruned blocks are not indexed on this node"))
+			}
 			return nil, NewRPCError(TxNotExistedInMemAndBLockError, errors.New("Tx is not existed in block or mempool"))
 		}
 		shardIDTemp := common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte())
@@ -1278,3 +1380,65 @@ func (txService TxService) GetTransactionByReceiver(keySet incognitokey.KeySet)
 	}
 	return &result, nil
 }
+
+// GetTransactionHistory returns a page of the transaction history of a payment address, see BlockChain.GetTxHistory.
+// Amounts hidden by privacy are revealed when keySet contains the read-only key of the payment address.
+func (txService TxService) GetTransactionHistory(keySet incognitokey.KeySet, cursor string, limit int) (*jsonresult.TransactionHistory, *RPCError) {
+	if len(keySet.PaymentAddress.Pk) == 0 {
+		return nil, NewRPCError(RPCInvalidParamsError, errors.New("Missing payment address"))
+	}
+	canDecrypt := len(keySet.ReadonlyKey.Rk) != 0 && bytes.Equal(keySet.ReadonlyKey.Pk, keySet.PaymentAddress.Pk)
+	entries, nextCursor, err := txService.BlockChain.GetTxHistory(keySet.PaymentAddress.Pk, cursor, limit)
+	if err != nil {
+		return nil, NewRPCError(GetTxHistoryError, err)
+	}
+	result := &jsonresult.TransactionHistory{
+		Entries:    make([]jsonresult.TransactionHistoryEntry, 0, len(entries)),
+		NextCursor: nextCursor,
+	}
+	for _, entry := range entries {
+		item := jsonresult.TransactionHistoryEntry{
+			TxHash:         entry.TxHash.String(),
+			TxType:         entry.TxType,
+			ShardID:        entry.ShardID,
+			BlockHeight:    entry.BlockHeight,
+			BeaconHeight:   entry.BeaconHeight,
+			TxIndex:        entry.TxIndex,
+			Direction:      entry.Direction,
+			TokenID:        entry.TokenID,
+			IsPrivacy:      entry.IsPrivacy,
+			Amount:         entry.Amount,
+			IsAmountHidden: entry.IsPrivacy && entry.Direction == blockchain.TxHistoryDirectionIn,
+			Fee:            entry.Fee,
+		}
+		if item.IsAmountHidden && canDecrypt {
+			amount, err := decryptOutputCoinsValue(entry.OutputCoins, keySet.ReadonlyKey)
+			if err != nil {
+				Logger.log.Error(err)
+			} else {
+				item.Amount = amount
+				item.IsAmountHidden = false
+			}
+		}
+		result.Entries = append(result.Entries, item)
+	}
+	return result, nil
+}
+
+// decryptOutputCoinsValue returns the total value of serialized output coins
+func decryptOutputCoinsValue(outputCoins [][]byte, readonlyKey privacy.ViewingKey) (uint64, error) {
+	var amount uint64
+	for _, outputCoinBytes := range outputCoins {
+		outputCoin := new(privacy.OutputCoin)
+		if err := outputCoin.SetBytes(outputCoinBytes); err != nil {
+			return 0, err
+		}
+		if outputCoin.CoinDetailsEncrypted != nil && !outputCoin.CoinDetailsEncrypted.IsNil() {
+			if err := outputCoin.Decrypt(readonlyKey); err != nil {
+				return 0, err
+			}
+		}
+		amount += outputCoin.CoinDetails.GetValue()
+	}
+	return amount, nil
+}
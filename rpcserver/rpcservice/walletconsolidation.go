@@ -0,0 +1,354 @@
+package rpcservice
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/rpcserver/jsonresult"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/incognitochain/incognito-chain/wallet"
+	"github.com/incognitochain/incognito-chain/wire"
+)
+
+const (
+	DefaultConsolidationBatchSize = 32
+	DefaultConsolidationInterval  = 10 * time.Minute
+)
+
+type WalletConsolidationConfig struct {
+	Threshold  int           // number of unspent output coins of an account and token above which they are consolidated, 0 disables it
+	BatchSize  int           // max input coins of a consolidation tx
+	Interval   time.Duration // between two checks of the accounts
+	FeePerKb   int64         // -1: estimate it from the recent blocks
+	HasPrivacy bool
+}
+
+type consolidationToken struct {
+	name   string
+	symbol string
+}
+
+// WalletConsolidator merges the small output coins of the accounts of the node wallet into a few ones,
+// so they can be spent later without hitting the max number of input coins of a tx.
+// Only the accounts with their private key, the unlocked ones, are consolidated
+type WalletConsolidator struct {
+	config    WalletConsolidationConfig
+	wallet    *wallet.Wallet
+	txService *TxService
+	broadcast func(message wire.Message) error
+
+	started int32
+	quit    chan struct{}
+	wg      sync.WaitGroup
+
+	mtx    sync.Mutex
+	status map[string]*jsonresult.ConsolidationStatus // account name + token ID -> status
+}
+
+func NewWalletConsolidator(config WalletConsolidationConfig, wallet *wallet.Wallet, txService *TxService, broadcast func(message wire.Message) error) *WalletConsolidator {
+	if config.BatchSize < 2 {
+		config.BatchSize = DefaultConsolidationBatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultConsolidationInterval
+	}
+	return &WalletConsolidator{
+		config:    config,
+		wallet:    wallet,
+		txService: txService,
+		broadcast: broadcast,
+		quit:      make(chan struct{}),
+		status:    make(map[string]*jsonresult.ConsolidationStatus),
+	}
+}
+
+func (consolidator *WalletConsolidator) Start() {
+	if !atomic.CompareAndSwapInt32(&consolidator.started, 0, 1) {
+		return
+	}
+	consolidator.wg.Add(1)
+	go func() {
+		defer consolidator.wg.Done()
+		ticker := time.NewTicker(consolidator.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-consolidator.quit:
+				return
+			case <-ticker.C:
+				consolidator.check()
+			}
+		}
+	}()
+}
+
+func (consolidator *WalletConsolidator) Stop() {
+	if !atomic.CompareAndSwapInt32(&consolidator.started, 1, 2) {
+		return
+	}
+	close(consolidator.quit)
+	consolidator.wg.Wait()
+}
+
+func (consolidator *WalletConsolidator) Status() jsonresult.GetConsolidationStatusResult {
+	consolidator.mtx.Lock()
+	defer consolidator.mtx.Unlock()
+	result := jsonresult.GetConsolidationStatusResult{
+		Enabled:   true,
+		Threshold: consolidator.config.Threshold,
+		BatchSize: consolidator.config.BatchSize,
+		Interval:  int64(consolidator.config.Interval / time.Second),
+		Status:    make([]jsonresult.ConsolidationStatus, 0, len(consolidator.status)),
+	}
+	for _, status := range consolidator.status {
+		item := *status
+		item.PendingTxs = append([]string{}, status.PendingTxs...)
+		result.Status = append(result.Status, item)
+	}
+	sort.Slice(result.Status, func(i, j int) bool {
+		if result.Status[i].Account != result.Status[j].Account {
+			return result.Status[i].Account < result.Status[j].Account
+		}
+		return result.Status[i].TokenID < result.Status[j].TokenID
+	})
+	return result
+}
+
+// check counts the unspent output coins of every account and token once, and consolidates the ones above the threshold
+func (consolidator *WalletConsolidator) check() {
+	tokens := map[common.Hash]consolidationToken{}
+	listPrivacyToken, listPrivacyTokenCrossShard, err := consolidator.txService.BlockChain.ListPrivacyCustomToken()
+	if err != nil {
+		Logger.log.Errorf("Wallet consolidation can not list privacy tokens, error %+v", err)
+	}
+	for tokenID, tx := range listPrivacyToken {
+		tokens[tokenID] = consolidationToken{name: tx.TxPrivacyTokenData.PropertyName, symbol: tx.TxPrivacyTokenData.PropertySymbol}
+	}
+	for tokenID, crossShardToken := range listPrivacyTokenCrossShard {
+		if _, ok := tokens[tokenID]; !ok {
+			tokens[tokenID] = consolidationToken{name: crossShardToken.PropertyName, symbol: crossShardToken.PropertySymbol}
+		}
+	}
+	beaconHeight := uint64(0)
+	beaconState, err := consolidator.txService.BlockChain.BestState.GetClonedBeaconBestState()
+	if err == nil {
+		beaconHeight = beaconState.BeaconHeight
+	}
+
+	for accountName, account := range consolidator.wallet.ListAccounts() {
+		if account.Key.IsWatchOnly() {
+			consolidator.updateStatus(accountName, common.PRVCoinID, -1, nil, errors.New("account is locked"))
+			continue
+		}
+		keySet := account.Key.KeySet
+		lastByte := keySet.PaymentAddress.Pk[len(keySet.PaymentAddress.Pk)-1]
+		shardID := common.GetShardIDFromLastByte(lastByte)
+
+		consolidator.checkToken(accountName, &keySet, shardID, common.PRVCoinID, nil, beaconHeight)
+		for tokenID, token := range tokens {
+			consolidator.checkToken(accountName, &keySet, shardID, tokenID, &token, beaconHeight)
+		}
+	}
+}
+
+func (consolidator *WalletConsolidator) checkToken(accountName string, keySet *incognitokey.KeySet, shardID byte, tokenID common.Hash, token *consolidationToken, beaconHeight uint64) {
+	outCoins, err := consolidator.txService.BlockChain.GetListOutputCoinsByKeyset(keySet, shardID, &tokenID)
+	if err == nil {
+		outCoins, err = consolidator.txService.filterMemPoolOutcoinsToSpent(outCoins)
+	}
+	if err != nil {
+		consolidator.updateStatus(accountName, tokenID, -1, nil, err)
+		return
+	}
+	if token != nil && len(outCoins) == 0 {
+		// no need to follow the tokens the account never received
+		return
+	}
+	if len(outCoins) <= consolidator.config.Threshold || consolidator.hasPendingTxs(accountName, tokenID) {
+		consolidator.updateStatus(accountName, tokenID, len(outCoins), nil, nil)
+		return
+	}
+
+	var txHashes []string
+	if token == nil {
+		txHashes, err = consolidator.consolidatePRV(keySet, shardID, outCoins, beaconHeight)
+	} else {
+		txHashes, err = consolidator.consolidateToken(keySet, shardID, tokenID, token, outCoins)
+	}
+	if err != nil {
+		Logger.log.Errorf("Wallet consolidation of account %s token %s failed, error %+v", accountName, tokenID.String(), err)
+	}
+	consolidator.updateStatus(accountName, tokenID, len(outCoins), txHashes, err)
+}
+
+// consolidatePRV sends every batch to the account itself, the fee is paid from the batch
+func (consolidator *WalletConsolidator) consolidatePRV(keySet *incognitokey.KeySet, shardID byte, outCoins []*privacy.OutputCoin, beaconHeight uint64) ([]string, error) {
+	db := *consolidator.txService.DB
+	txHashes := []string{}
+	for _, batch := range consolidator.batches(outCoins) {
+		amount := uint64(0)
+		for _, outCoin := range batch {
+			amount += outCoin.CoinDetails.GetValue()
+		}
+		paymentInfos := []*privacy.PaymentInfo{{PaymentAddress: keySet.PaymentAddress, Amount: amount}}
+		realFee, _, _, err := consolidator.txService.EstimateFee(consolidator.config.FeePerKb, false, batch, paymentInfos, shardID,
+			0, consolidator.config.HasPrivacy, nil, nil, db, int64(beaconHeight))
+		if err != nil {
+			return txHashes, err
+		}
+		if amount <= realFee {
+			// dust, merging it costs more than it is worth
+			continue
+		}
+		paymentInfos[0].Amount = amount - realFee
+
+		tx := &transaction.Tx{}
+		err = tx.Init(transaction.NewTxPrivacyInitParams(&keySet.PrivateKey, paymentInfos, transaction.ConvertOutputCoinToInputCoin(batch),
+			realFee, consolidator.config.HasPrivacy, db, nil, nil, []byte{}))
+		if err != nil {
+			return txHashes, err
+		}
+		txBytes, err := json.Marshal(tx)
+		if err != nil {
+			return txHashes, err
+		}
+		txMsg, txHash, _, rpcErr := consolidator.txService.SendRawTransaction(base58.Base58Check{}.Encode(txBytes, common.ZeroByte))
+		if rpcErr != nil {
+			return txHashes, rpcErr
+		}
+		consolidator.broadcastTx(txMsg, txHash)
+		txHashes = append(txHashes, txHash.String())
+	}
+	return txHashes, nil
+}
+
+// consolidateToken sends every batch to the account itself, the fee is paid in PRV
+func (consolidator *WalletConsolidator) consolidateToken(keySet *incognitokey.KeySet, shardID byte, tokenID common.Hash, token *consolidationToken, outCoins []*privacy.OutputCoin) ([]string, error) {
+	db := *consolidator.txService.DB
+	txHashes := []string{}
+	for _, batch := range consolidator.batches(outCoins) {
+		amount := uint64(0)
+		for _, outCoin := range batch {
+			amount += outCoin.CoinDetails.GetValue()
+		}
+		tokenParams := &transaction.CustomTokenPrivacyParamTx{
+			PropertyID:     tokenID.String(),
+			PropertyName:   token.name,
+			PropertySymbol: token.symbol,
+			Amount:         amount,
+			TokenTxType:    transaction.CustomTokenTransfer,
+			Receiver:       []*privacy.PaymentInfo{{PaymentAddress: keySet.PaymentAddress, Amount: amount}},
+			TokenInput:     transaction.ConvertOutputCoinToInputCoin(batch),
+		}
+		// the PRV coins of the previous batches are in mem pool already, they are not chosen again
+		inputCoins, realFee, rpcErr := consolidator.txService.chooseOutsCoinByKeyset([]*privacy.PaymentInfo{}, consolidator.config.FeePerKb, 0,
+			keySet, shardID, consolidator.config.HasPrivacy, nil, tokenParams, false, 0, db)
+		if rpcErr != nil {
+			return txHashes, rpcErr
+		}
+		hasPrivacyCoin := consolidator.config.HasPrivacy && realFee > 0
+
+		tx := &transaction.TxCustomTokenPrivacy{}
+		err := tx.Init(transaction.NewTxPrivacyTokenInitParams(&keySet.PrivateKey, []*privacy.PaymentInfo{}, inputCoins, realFee,
+			tokenParams, db, nil, hasPrivacyCoin, true, shardID, []byte{}))
+		if err != nil {
+			return txHashes, err
+		}
+		txBytes, err := json.Marshal(tx)
+		if err != nil {
+			return txHashes, err
+		}
+		txMsg, sentTx, err := consolidator.txService.SendRawPrivacyCustomTokenTransaction(base58.Base58Check{}.Encode(txBytes, common.ZeroByte))
+		if err != nil {
+			return txHashes, err
+		}
+		consolidator.broadcastTx(txMsg, sentTx.Hash())
+		txHashes = append(txHashes, sentTx.Hash().String())
+	}
+	return txHashes, nil
+}
+
+// batches splits outCoins, the smallest first, into batches of at least 2 coins and at most BatchSize coins
+func (consolidator *WalletConsolidator) batches(outCoins []*privacy.OutputCoin) [][]*privacy.OutputCoin {
+	sorted := make([]*privacy.OutputCoin, len(outCoins))
+	copy(sorted, outCoins)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CoinDetails.GetValue() < sorted[j].CoinDetails.GetValue()
+	})
+	result := [][]*privacy.OutputCoin{}
+	for len(sorted) >= 2 {
+		size := consolidator.config.BatchSize
+		if size > len(sorted) {
+			size = len(sorted)
+		}
+		result = append(result, sorted[:size])
+		sorted = sorted[size:]
+	}
+	return result
+}
+
+func (consolidator *WalletConsolidator) broadcastTx(txMsg wire.Message, txHash *common.Hash) {
+	if consolidator.broadcast == nil {
+		return
+	}
+	err := consolidator.broadcast(txMsg)
+	if err != nil {
+		Logger.log.Errorf("Wallet consolidation broadcast tx %s with error %+v", txHash.String(), err)
+		return
+	}
+	consolidator.txService.TxMemPool.MarkForwardedTransaction(*txHash)
+}
+
+// hasPendingTxs tells whether consolidation txs of the account and token are still waiting in mem pool,
+// the next consolidation waits for them to be in a block to count their outputs
+func (consolidator *WalletConsolidator) hasPendingTxs(accountName string, tokenID common.Hash) bool {
+	consolidator.mtx.Lock()
+	defer consolidator.mtx.Unlock()
+	status, ok := consolidator.status[accountName+"-"+tokenID.String()]
+	if !ok {
+		return false
+	}
+	pendingTxs := []string{}
+	for _, txHashStr := range status.PendingTxs {
+		txHash, err := common.Hash{}.NewHashFromStr(txHashStr)
+		if err == nil && consolidator.txService.TxMemPool.HaveTransaction(txHash) {
+			pendingTxs = append(pendingTxs, txHashStr)
+		}
+	}
+	status.PendingTxs = pendingTxs
+	return len(pendingTxs) > 0
+}
+
+// updateStatus records a check of the account and token, unspentCoins is -1 when they could not be counted
+func (consolidator *WalletConsolidator) updateStatus(accountName string, tokenID common.Hash, unspentCoins int, txHashes []string, err error) {
+	consolidator.mtx.Lock()
+	defer consolidator.mtx.Unlock()
+	key := accountName + "-" + tokenID.String()
+	status, ok := consolidator.status[key]
+	if !ok {
+		status = &jsonresult.ConsolidationStatus{
+			Account:    accountName,
+			TokenID:    tokenID.String(),
+			PendingTxs: []string{},
+		}
+		consolidator.status[key] = status
+	}
+	if unspentCoins >= 0 {
+		status.UnspentCoins = unspentCoins
+	}
+	status.PendingTxs = append(status.PendingTxs, txHashes...)
+	status.ConsolidatedTxs += uint64(len(txHashes))
+	status.LastCheck = time.Now().Unix()
+	status.LastError = common.EmptyString
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
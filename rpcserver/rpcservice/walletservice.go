@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"math/rand"
+	"time"
 
 	"github.com/incognitochain/incognito-chain/blockchain"
 	"github.com/incognitochain/incognito-chain/common"
@@ -13,8 +14,10 @@ import (
 )
 
 type WalletService struct {
-	Wallet     *wallet.Wallet
-	BlockChain *blockchain.BlockChain
+	Wallet       *wallet.Wallet
+	Keystore     *wallet.Keystore
+	BlockChain   *blockchain.BlockChain
+	Consolidator *WalletConsolidator
 }
 
 func (walletService WalletService) ListAccounts() (jsonresult.ListAccounts, *RPCError) {
@@ -50,7 +53,7 @@ func (walletService WalletService) GetAccount(paymentAddrStr string) (string, er
 		return "", NewRPCError(RPCInvalidParamsError, errors.New("payment address is invalid"))
 	}
 
-	for _, account := range walletService.Wallet.MasterAccount.Child {
+	for _, account := range walletService.Wallet.Accounts() {
 		address := account.Key.Base58CheckSerialize(wallet.PaymentAddressType)
 		if address == paymentAddrStr {
 			return account.Name, nil
@@ -85,6 +88,10 @@ func (walletService WalletService) DumpPrivkey(param string) wallet.KeySerialize
 	return walletService.Wallet.DumpPrivateKey(param)
 }
 
+func (walletService WalletService) DumpViewingKey(paymentAddress string) wallet.KeySerializedData {
+	return walletService.Wallet.DumpViewingKey(paymentAddress)
+}
+
 func (walletService *WalletService) ImportAccount(privateKey string, accountName string, passPhrase string) (wallet.KeySerializedData, error) {
 	account, err := walletService.Wallet.ImportAccount(privateKey, accountName, passPhrase)
 	if err != nil {
@@ -94,6 +101,22 @@ func (walletService *WalletService) ImportAccount(privateKey string, accountName
 		PaymentAddress: account.Key.Base58CheckSerialize(wallet.PaymentAddressType),
 		Pubkey:         hex.EncodeToString(account.Key.KeySet.PaymentAddress.Pk),
 		ReadonlyKey:    account.Key.Base58CheckSerialize(wallet.ReadonlyKeyType),
+		ViewingKey:     account.Key.Base58CheckSerialize(wallet.ViewingKeyType),
+	}
+
+	return result, nil
+}
+
+func (walletService *WalletService) ImportViewingKey(viewingKey string, accountName string, passPhrase string) (wallet.KeySerializedData, error) {
+	account, err := walletService.Wallet.ImportViewingKey(viewingKey, accountName, passPhrase)
+	if err != nil {
+		return wallet.KeySerializedData{}, err
+	}
+	result := wallet.KeySerializedData{
+		PaymentAddress: account.Key.Base58CheckSerialize(wallet.PaymentAddressType),
+		Pubkey:         hex.EncodeToString(account.Key.KeySet.PaymentAddress.Pk),
+		ReadonlyKey:    account.Key.Base58CheckSerialize(wallet.ReadonlyKeyType),
+		ViewingKey:     account.Key.Base58CheckSerialize(wallet.ViewingKeyType),
 	}
 
 	return result, nil
@@ -162,6 +185,24 @@ func (walletService WalletService) GetBalanceByPaymentAddress(paymentAddress str
 	return balance, nil
 }
 
+// GetBalanceByViewingKey returns the total amount of tokenID received by the payment address of viewingKey.
+// Only the private key tells which coins are spent, so the coins spent since are counted too.
+func (walletService WalletService) GetBalanceByViewingKey(viewingKey string, tokenID common.Hash) (uint64, *RPCError) {
+	keySet, shardID, err := GetKeySetFromViewingKeyParam(viewingKey)
+	if err != nil {
+		return uint64(0), NewRPCError(RPCInvalidParamsError, err)
+	}
+	outCoins, err := walletService.BlockChain.GetListOutputCoinsByKeyset(keySet, shardID, &tokenID)
+	if err != nil {
+		return uint64(0), NewRPCError(UnexpectedError, err)
+	}
+	balance := uint64(0)
+	for _, out := range outCoins {
+		balance += out.CoinDetails.GetValue()
+	}
+	return balance, nil
+}
+
 func (walletService WalletService) GetBalance(accountName string) (uint64, *RPCError) {
 	prvCoinID := &common.Hash{}
 	err1 := prvCoinID.SetBytes(common.PRVCoinID[:])
@@ -172,7 +213,7 @@ func (walletService WalletService) GetBalance(accountName string) (uint64, *RPCE
 	balance := uint64(0)
 	if accountName == "*" {
 		// get balance for all accounts in wallet
-		for _, account := range walletService.Wallet.MasterAccount.Child {
+		for _, account := range walletService.Wallet.Accounts() {
 			lastByte := account.Key.KeySet.PaymentAddress.Pk[len(account.Key.KeySet.PaymentAddress.Pk)-1]
 			shardIDSender := common.GetShardIDFromLastByte(lastByte)
 			outCoins, err := walletService.BlockChain.GetListOutputCoinsByKeyset(&account.Key.KeySet, shardIDSender, prvCoinID)
@@ -184,7 +225,7 @@ func (walletService WalletService) GetBalance(accountName string) (uint64, *RPCE
 			}
 		}
 	} else {
-		for _, account := range walletService.Wallet.MasterAccount.Child {
+		for _, account := range walletService.Wallet.Accounts() {
 			if account.Name == accountName {
 				// get balance for accountName in wallet
 				lastByte := account.Key.KeySet.PaymentAddress.Pk[len(account.Key.KeySet.PaymentAddress.Pk)-1]
@@ -206,7 +247,7 @@ func (walletService WalletService) GetBalance(accountName string) (uint64, *RPCE
 
 func (walletService WalletService) GetReceivedByAccount(accountName string) (uint64, *RPCError) {
 	balance := uint64(0)
-	for _, account := range walletService.Wallet.MasterAccount.Child {
+	for _, account := range walletService.Wallet.Accounts() {
 		if account.Name == accountName {
 			// get balance for accountName in wallet
 			lastByte := account.Key.KeySet.PaymentAddress.Pk[len(account.Key.KeySet.PaymentAddress.Pk)-1]
@@ -228,3 +269,78 @@ func (walletService WalletService) GetReceivedByAccount(accountName string) (uin
 	}
 	return balance, nil
 }
+
+// keystoreWalletName returns walletName, or the name of the node wallet when it is empty
+func (walletService WalletService) keystoreWalletName(walletName string) (string, *RPCError) {
+	if walletService.Keystore == nil {
+		return "", NewRPCError(WalletKeystoreError, errors.New("wallet keystore is not enabled"))
+	}
+	if walletName == "" {
+		if walletService.Wallet == nil {
+			return "", NewRPCError(RPCInvalidParamsError, errors.New("walletName is empty"))
+		}
+		walletName = walletService.Wallet.Name
+	}
+	return walletName, nil
+}
+
+// WalletPassphrase unlocks the whole wallet, or only the account accountName when it is not empty, for timeout
+func (walletService WalletService) WalletPassphrase(walletName string, passPhrase string, timeout time.Duration, accountName string) (wallet.KeystoreWalletStatus, *RPCError) {
+	walletName, rpcErr := walletService.keystoreWalletName(walletName)
+	if rpcErr != nil {
+		return wallet.KeystoreWalletStatus{}, rpcErr
+	}
+	err := walletService.Keystore.Unlock(walletName, passPhrase, accountName, timeout)
+	if err != nil {
+		return wallet.KeystoreWalletStatus{}, NewRPCError(WalletKeystoreError, err)
+	}
+	status, err := walletService.Keystore.Status(walletName)
+	if err != nil {
+		return wallet.KeystoreWalletStatus{}, NewRPCError(WalletKeystoreError, err)
+	}
+	return status, nil
+}
+
+// WalletLock locks the wallet and zeroes its keys in memory
+func (walletService WalletService) WalletLock(walletName string) (bool, *RPCError) {
+	walletName, rpcErr := walletService.keystoreWalletName(walletName)
+	if rpcErr != nil {
+		return false, rpcErr
+	}
+	err := walletService.Keystore.Lock(walletName)
+	if err != nil {
+		return false, NewRPCError(WalletKeystoreError, err)
+	}
+	return true, nil
+}
+
+func (walletService WalletService) ListWallets() ([]wallet.KeystoreWalletStatus, *RPCError) {
+	if walletService.Keystore == nil {
+		return nil, NewRPCError(WalletKeystoreError, errors.New("wallet keystore is not enabled"))
+	}
+	return walletService.Keystore.Wallets(), nil
+}
+
+// CreateWallet creates a new wallet in the keystore, it is locked until walletpassphrase is called
+func (walletService WalletService) CreateWallet(walletName string, passPhrase string, numOfAccount uint32) (wallet.KeystoreWalletStatus, *RPCError) {
+	if walletService.Keystore == nil {
+		return wallet.KeystoreWalletStatus{}, NewRPCError(WalletKeystoreError, errors.New("wallet keystore is not enabled"))
+	}
+	_, err := walletService.Keystore.CreateWallet(walletName, passPhrase, numOfAccount)
+	if err != nil {
+		return wallet.KeystoreWalletStatus{}, NewRPCError(WalletKeystoreError, err)
+	}
+	status, err := walletService.Keystore.Status(walletName)
+	if err != nil {
+		return wallet.KeystoreWalletStatus{}, NewRPCError(WalletKeystoreError, err)
+	}
+	return status, nil
+}
+
+// GetConsolidationStatus returns the state of the background consolidation of the output coins of the wallet
+func (walletService WalletService) GetConsolidationStatus() jsonresult.GetConsolidationStatusResult {
+	if walletService.Consolidator == nil {
+		return jsonresult.GetConsolidationStatusResult{Status: []jsonresult.ConsolidationStatus{}}
+	}
+	return walletService.Consolidator.Status()
+}
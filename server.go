@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -42,6 +43,7 @@ import (
 	"github.com/incognitochain/incognito-chain/netsync"
 	"github.com/incognitochain/incognito-chain/peer"
 	"github.com/incognitochain/incognito-chain/rpcserver"
+	"github.com/incognitochain/incognito-chain/rpcserver/rpcservice"
 	"github.com/incognitochain/incognito-chain/wallet"
 	"github.com/incognitochain/incognito-chain/wire"
 	libp2p "github.com/libp2p/go-libp2p-peer"
@@ -76,6 +78,7 @@ type Server struct {
 	miningKeys      string
 	privateKey      string
 	wallet          *wallet.Wallet
+	keystore        *wallet.Keystore
 	consensusEngine *consensus.Engine
 	blockgen        *blockchain.BlockGenerator
 	pusubManager    *pubsub.PubSubManager
@@ -259,11 +262,16 @@ func (serverObj *Server) NewServer(listenAddrs string, db database.DatabaseInter
 		return err
 	}
 	// Init consensus engine
+	chainConsensus, err := parseChainConsensus(cfg.ConsensusEngines)
+	if err != nil {
+		return err
+	}
 	err = serverObj.consensusEngine.Init(&consensus.EngineConfig{
-		Blockchain:    serverObj.blockChain,
-		Node:          serverObj,
-		BlockGen:      serverObj.blockgen,
-		PubSubManager: serverObj.pusubManager,
+		Blockchain:     serverObj.blockChain,
+		Node:           serverObj,
+		BlockGen:       serverObj.blockgen,
+		PubSubManager:  serverObj.pusubManager,
+		ChainConsensus: chainConsensus,
 	})
 	if err != nil {
 		return err
@@ -329,12 +337,14 @@ func (serverObj *Server) NewServer(listenAddrs string, db database.DatabaseInter
 		CrossShardPool:    serverObj.crossShardPool,
 		Server:            serverObj,
 		// UserKeySet:        serverObj.userKeySet,
-		NodeMode:        cfg.NodeMode,
-		FeeEstimator:    make(map[byte]blockchain.FeeEstimator),
-		PubSubManager:   pubsubManager,
-		RandomClient:    randomClient,
-		ConsensusEngine: serverObj.consensusEngine,
-		Highway:         serverObj.highway,
+		NodeMode:         cfg.NodeMode,
+		FeeEstimator:     make(map[byte]blockchain.FeeEstimator),
+		PubSubManager:    pubsubManager,
+		RandomClient:     randomClient,
+		ConsensusEngine:  serverObj.consensusEngine,
+		Highway:          serverObj.highway,
+		PruneBlockEpochs: cfg.PruneBlockEpochs,
+		TxHistoryIndex:   cfg.TxHistoryIndex,
 	})
 	if err != nil {
 		return err
@@ -410,6 +420,7 @@ func (serverObj *Server) NewServer(listenAddrs string, db database.DatabaseInter
 		FeeEstimator:      serverObj.feeEstimator,
 		TxLifeTime:        cfg.TxPoolTTL,
 		MaxTx:             cfg.TxPoolMaxTx,
+		MaxSize:           cfg.TxPoolMaxSize,
 		DataBaseMempool:   dbmp,
 		IsLoadFromMempool: cfg.LoadMempool,
 		PersistMempool:    cfg.PersistMempool,
@@ -524,6 +535,7 @@ func (serverObj *Server) NewServer(listenAddrs string, db database.DatabaseInter
 			TxMemPool:                   serverObj.memPool,
 			Server:                      serverObj,
 			Wallet:                      serverObj.wallet,
+			Keystore:                    serverObj.keystore,
 			ConnMgr:                     serverObj.connManager,
 			AddrMgr:                     serverObj.addrManager,
 			RPCUser:                     cfg.RPCUser,
@@ -540,6 +552,13 @@ func (serverObj *Server) NewServer(listenAddrs string, db database.DatabaseInter
 			PubSubManager:               pubsubManager,
 			ConsensusEngine:             serverObj.consensusEngine,
 			MemCache:                    serverObj.memCache,
+			WalletConsolidation: rpcservice.WalletConsolidationConfig{
+				Threshold:  cfg.WalletConsolidateThreshold,
+				BatchSize:  cfg.WalletConsolidateBatchSize,
+				Interval:   cfg.WalletConsolidateInterval,
+				FeePerKb:   cfg.WalletConsolidateFeePerKb,
+				HasPrivacy: true,
+			},
 		}
 		serverObj.rpcServer = &rpcserver.RpcServer{}
 		serverObj.rpcServer.Init(&rpcConfig)
@@ -622,6 +641,11 @@ func (serverObj *Server) Stop() error {
 		}
 	}
 
+	// Zero the keys of the unlocked wallets
+	if serverObj.keystore != nil {
+		serverObj.keystore.LockAll()
+	}
+
 	err := serverObj.consensusEngine.Stop("all")
 	if err != nil {
 		Logger.log.Error(err)
@@ -740,6 +764,7 @@ func (serverObj Server) Start() {
 		go serverObj.TransactionPoolBroadcastLoop()
 		go serverObj.memPool.Start(serverObj.cQuit)
 		go serverObj.memPool.MonitorPool()
+		go serverObj.memPool.MonitorDatabaseMempool()
 	}
 	go serverObj.pusubManager.Start()
 	// go metrics.StartSystemMetrics()
@@ -1882,6 +1907,24 @@ func (serverObj *Server) GetPrivateKey() string {
 	return serverObj.privateKey
 }
 
+// GetConsensusDataDir - consensus keeps the journal of what this node signed under the data directory
+func (serverObj *Server) GetConsensusDataDir() string {
+	return filepath.Join(cfg.DataDir, "consensus")
+}
+
+// parseChainConsensus - chain key -> consensus implementation from the consensusengine options
+func parseChainConsensus(options []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, option := range options {
+		parts := strings.Split(option, ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid consensusengine %+v, expect <chain key>:<implementation>", option)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
 func (serverObj *Server) PushMessageToChain(msg wire.Message, chain blockchain.ChainInterface) error {
 	chainID := chain.GetShardID()
 	if chainID == -1 {
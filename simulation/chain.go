@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+)
+
+// beaconChain is the beacon chain as seen by the consensus of the nodes:
+// it is always synced and reads the shard to beacon pool directly instead of the state of peers
+type beaconChain struct {
+	blockchain.ChainInterface
+	sim *Simulation
+}
+
+func (chain *beaconChain) IsReady() bool {
+	return true
+}
+
+func (chain *beaconChain) CreateNewBlock(round int) (common.BlockInterface, error) {
+	return chain.sim.blockGen.NewBlockBeacon(round, chain.sim.shardToBeaconPool.GetLatestValidPendingBlockHeight())
+}
+
+func (chain *beaconChain) InsertAndBroadcastBlock(block common.BlockInterface) error {
+	return chain.sim.BlockChain.InsertBeaconBlock(block.(*blockchain.BeaconBlock), true)
+}
+
+// shardChain is a shard chain as seen by the consensus of the nodes,
+// the blocks it commits are handed to the beacon and the other shards through their pools
+type shardChain struct {
+	blockchain.ChainInterface
+	sim     *Simulation
+	shardID byte
+}
+
+func (chain *shardChain) IsReady() bool {
+	return true
+}
+
+func (chain *shardChain) CreateNewBlock(round int) (common.BlockInterface, error) {
+	sim := chain.sim
+	return sim.blockGen.NewBlockShard(chain.shardID, round, sim.crossShardPools[chain.shardID].GetLatestValidBlockHeight(), sim.BlockChain.BestState.Beacon.BeaconHeight, sim.Clock.Now())
+}
+
+// UnmarshalBlock decodes a proposed shard block. json leaves every TotalTxsFee key of a shard header at the zero hash
+// since common.Hash.UnmarshalText is a no-op, so the keys are restored from the token ids written by MarshalText,
+// otherwise validators would not agree with the proposer on the fees and the hash of a block with txs
+func (chain *shardChain) UnmarshalBlock(blockData []byte) (common.BlockInterface, error) {
+	block, err := chain.ChainInterface.UnmarshalBlock(blockData)
+	if err != nil {
+		return nil, err
+	}
+	shardBlock := block.(*blockchain.ShardBlock)
+	rawBlock := struct {
+		Header struct {
+			TotalTxsFee map[string]uint64
+		}
+	}{}
+	if err := json.Unmarshal(blockData, &rawBlock); err != nil {
+		return nil, err
+	}
+	if rawBlock.Header.TotalTxsFee == nil {
+		return shardBlock, nil
+	}
+	shardBlock.Header.TotalTxsFee = make(map[common.Hash]uint64)
+	for tokenIDStr, fee := range rawBlock.Header.TotalTxsFee {
+		tokenID, err := common.Hash{}.NewHashFromStr(tokenIDStr)
+		if err != nil {
+			return nil, err
+		}
+		shardBlock.Header.TotalTxsFee[*tokenID] = fee
+	}
+	return shardBlock, nil
+}
+
+func (chain *shardChain) InsertAndBroadcastBlock(block common.BlockInterface) error {
+	sim := chain.sim
+	shardBlock := block.(*blockchain.ShardBlock)
+	if err := sim.BlockChain.InsertShardBlock(shardBlock, true); err != nil {
+		return err
+	}
+	if _, _, err := sim.shardToBeaconPool.AddShardToBeaconBlock(shardBlock.CreateShardToBeaconBlock(sim.BlockChain)); err != nil {
+		sim.logger.Error(err)
+	}
+	for toShardID, crossShardBlock := range shardBlock.CreateAllCrossShardBlock(sim.BlockChain.BestState.Beacon.ActiveShards) {
+		if _, _, err := sim.crossShardPools[toShardID].AddCrossShardBlock(crossShardBlock); err != nil {
+			sim.logger.Error(err)
+		}
+	}
+	return nil
+}
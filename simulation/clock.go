@@ -0,0 +1,29 @@
+package simulation
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a common.Clock which only moves when told to, every node of a simulation reads time from the same Clock
+type Clock struct {
+	mtx sync.RWMutex
+	now time.Time
+}
+
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+func (clock *Clock) Now() time.Time {
+	clock.mtx.RLock()
+	defer clock.mtx.RUnlock()
+	return clock.now
+}
+
+// Advance moves the clock forward by d
+func (clock *Clock) Advance(d time.Duration) {
+	clock.mtx.Lock()
+	defer clock.mtx.Unlock()
+	clock.now = clock.now.Add(d)
+}
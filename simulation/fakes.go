@@ -0,0 +1,120 @@
+package simulation
+
+import (
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// server stands for the peer layer of blockchain.Config, blocks never go through it:
+// the simulation stores every committed block once in the chain all nodes share
+type server struct{}
+
+func (server) BoardcastNodeState() error { return nil }
+
+func (server) PublishNodeState(userLayer string, shardID int) error { return nil }
+
+func (server) PushMessageGetBlockBeaconByHeight(from uint64, to uint64) error { return nil }
+
+func (server) PushMessageGetBlockBeaconByHash(blksHash []common.Hash, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockBeaconBySpecificHeight(heights []uint64, getFromPool bool) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardByHeight(shardID byte, from uint64, to uint64) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardByHash(shardID byte, blksHash []common.Hash, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardBySpecificHeight(shardID byte, heights []uint64, getFromPool bool) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardToBeaconByHeight(shardID byte, from uint64, to uint64) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardToBeaconByHash(shardID byte, blksHash []common.Hash, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockShardToBeaconBySpecificHeight(shardID byte, blksHeight []uint64, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockCrossShardByHash(fromShard byte, toShard byte, blksHash []common.Hash, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) PushMessageGetBlockCrossShardBySpecificHeight(fromShard byte, toShard byte, blksHeight []uint64, getFromPool bool, peerID libp2p.ID) error {
+	return nil
+}
+
+func (server) UpdateConsensusState(role string, userPbk string, currentShard *byte, beaconCommittee []string, shardCommittee map[byte][]string) {
+}
+
+func (server) PushBlockToAll(block common.BlockInterface, isBeacon bool) error { return nil }
+
+// highway drops the committee updates meant for the highway
+type highway struct{}
+
+func (highway) BroadcastCommittee(uint64, []incognitokey.CommitteePublicKey, map[byte][]incognitokey.CommitteePublicKey, map[byte][]incognitokey.CommitteePublicKey) {
+}
+
+// consensusEngine is the consensus engine of the shared chain, it verifies signatures with the registered consensus
+// and answers the mining key questions for the node the simulation is currently running
+type consensusEngine struct {
+	current *Node
+}
+
+func (engine *consensusEngine) ValidateProducerSig(block common.BlockInterface, consensusType string) error {
+	if _, ok := consensus.AvailableConsensus[consensusType]; !ok {
+		return consensus.NewConsensusError(consensus.ConsensusTypeNotExistError, errors.New(consensusType))
+	}
+	return consensus.AvailableConsensus[consensusType].ValidateProducerSig(block)
+}
+
+func (engine *consensusEngine) ValidateBlockCommitteSig(block common.BlockInterface, committee []incognitokey.CommitteePublicKey, consensusType string) error {
+	if _, ok := consensus.AvailableConsensus[consensusType]; !ok {
+		return consensus.NewConsensusError(consensus.ConsensusTypeNotExistError, errors.New(consensusType))
+	}
+	return consensus.AvailableConsensus[consensusType].ValidateCommitteeSig(block, committee)
+}
+
+func (engine *consensusEngine) GetCurrentMiningPublicKey() (string, string) {
+	if engine.current == nil {
+		return "", ""
+	}
+	return engine.current.MiningKeyBase58(), common.BlsConsensus
+}
+
+func (engine *consensusEngine) GetMiningPublicKeyByConsensus(consensusName string) (string, error) {
+	if engine.current == nil || consensusName != common.BlsConsensus {
+		return "", consensus.NewConsensusError(consensus.ConsensusTypeNotExistError, errors.New(consensusName))
+	}
+	return engine.current.MiningKeyBase58(), nil
+}
+
+// GetUserLayer and GetUserRole are only asked when blocks come from peers, which never happens in a simulation
+func (engine *consensusEngine) GetUserLayer() (string, int) {
+	return "", -2
+}
+
+func (engine *consensusEngine) GetUserRole() (string, string, int) {
+	return "", "", -2
+}
+
+// IsOngoing is false so blocks can always be inserted, the nodes share one chain
+func (engine *consensusEngine) IsOngoing(chainName string) bool {
+	return false
+}
+
+func (engine *consensusEngine) CommitteeChange(chainName string) {}
@@ -0,0 +1,77 @@
+package simulation
+
+import (
+	"github.com/incognitochain/incognito-chain/wire"
+)
+
+type envelope struct {
+	from *Node
+	msg  *wire.MessageBFT
+}
+
+// Network is the in-memory peer layer of a simulation, it carries the consensus messages of the nodes.
+// Messages are delivered in the order they are sent, each node receives its own copy decoded from the wire format.
+// Nodes can be disconnected or split into partitions, and Filter can drop any message to script faulty links.
+type Network struct {
+	queue        []envelope
+	disconnected map[*Node]bool
+	partitions   map[*Node]int
+	// Filter is called for every message and receiver when set, the message is dropped when it returns false
+	Filter func(from *Node, to *Node, msg *wire.MessageBFT) bool
+	// Sent counts the consensus messages sent by type
+	Sent map[string]int
+}
+
+func newNetwork() *Network {
+	return &Network{
+		disconnected: make(map[*Node]bool),
+		partitions:   make(map[*Node]int),
+		Sent:         make(map[string]int),
+	}
+}
+
+// Disconnect stops node from sending or receiving any message until Reconnect is called
+func (network *Network) Disconnect(node *Node) {
+	network.disconnected[node] = true
+}
+
+func (network *Network) Reconnect(node *Node) {
+	delete(network.disconnected, node)
+}
+
+// Partition splits the nodes into groups which cannot reach each other,
+// nodes left out of every group form one more group
+func (network *Network) Partition(groups ...[]*Node) {
+	network.partitions = make(map[*Node]int)
+	for i, group := range groups {
+		for _, node := range group {
+			network.partitions[node] = i + 1
+		}
+	}
+}
+
+// Heal removes the partitions
+func (network *Network) Heal() {
+	network.partitions = make(map[*Node]int)
+}
+
+func (network *Network) canReach(from *Node, to *Node) bool {
+	if network.disconnected[from] || network.disconnected[to] {
+		return false
+	}
+	return network.partitions[from] == network.partitions[to]
+}
+
+func (network *Network) send(from *Node, msg *wire.MessageBFT) {
+	network.Sent[msg.Type]++
+	network.queue = append(network.queue, envelope{from: from, msg: msg})
+}
+
+func (network *Network) pop() (envelope, bool) {
+	if len(network.queue) == 0 {
+		return envelope{}, false
+	}
+	e := network.queue[0]
+	network.queue = network.queue[1:]
+	return e, true
+}
@@ -0,0 +1,152 @@
+package simulation
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/consensus/blsbft"
+	"github.com/incognitochain/incognito-chain/consensus/hotstuff"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/wire"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// Consensus is a consensus the simulation drives by hand, BLSBFT or the implementation a chain is configured with
+type Consensus interface {
+	consensus.ConsensusInterface
+	StartManual() error
+	Tick()
+}
+
+// Node is a validator of the simulation, it runs one consensus per chain with its mining key.
+// A consensus only produces or votes for blocks while the key is in the committee of its chain,
+// so the same node follows the committee changes made by staking and swapping.
+type Node struct {
+	Index           int
+	Account         *Account
+	MiningSeed      string // base58 seed of the bls and bridge mining keys
+	CommitteeKey    incognitokey.CommitteePublicKey
+	CommitteeKeyB58 string
+	Consensus       map[string]Consensus // chain key -> consensus
+	offline         bool
+	sim             *Simulation
+}
+
+func newNode(sim *Simulation, index int, account *Account) (*Node, error) {
+	seed := common.HashB(common.HashB(account.KeySet.PrivateKey))
+	committeeKey, err := incognitokey.NewCommitteeKeyFromSeed(seed, account.KeySet.PaymentAddress.Pk)
+	if err != nil {
+		return nil, err
+	}
+	committeeKeyB58, err := committeeKey.ToBase58()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{
+		Index:           index,
+		Account:         account,
+		MiningSeed:      base58.Base58Check{}.Encode(seed, common.Base58Version),
+		CommitteeKey:    committeeKey,
+		CommitteeKeyB58: committeeKeyB58,
+		Consensus:       make(map[string]Consensus),
+		sim:             sim,
+	}, nil
+}
+
+// startConsensus starts a manually driven consensus for every chain of the simulation
+func (node *Node) startConsensus() error {
+	for _, chainKey := range node.sim.chainKeys {
+		var bft Consensus
+		switch impl := node.sim.config.ChainConsensus[chainKey]; impl {
+		case "":
+			instance := (&blsbft.BLSBFT{}).NewInstance(node.sim.chains[chainKey], chainKey, node, node.sim.logger).(*blsbft.BLSBFT)
+			instance.Clock = node.sim.Clock
+			bft = instance
+		case hotstuff.ConsensusName:
+			instance := (&hotstuff.HotStuff{}).NewInstance(node.sim.chains[chainKey], chainKey, node, node.sim.logger).(*hotstuff.HotStuff)
+			instance.Clock = node.sim.Clock
+			bft = instance
+		default:
+			return errors.Errorf("unknown consensus %+v for chain %+v", impl, chainKey)
+		}
+		if err := bft.LoadUserKey(node.MiningSeed); err != nil {
+			return err
+		}
+		if err := bft.StartManual(); err != nil {
+			return err
+		}
+		node.Consensus[chainKey] = bft
+	}
+	return nil
+}
+
+// RestartConsensus stops the consensus of every chain and starts new ones, as if the node had been restarted:
+// what they knew about the current rounds is lost but for their vote journals
+func (node *Node) RestartConsensus() error {
+	for chainKey, bft := range node.Consensus {
+		bft.Stop()
+		delete(node.Consensus, chainKey)
+	}
+	return node.startConsensus()
+}
+
+// MiningKeyBase58 is the bls public key the chains identify the node with
+func (node *Node) MiningKeyBase58() string {
+	return node.CommitteeKey.GetMiningKeyBase58(common.BlsConsensus)
+}
+
+// SetOffline stops the node as if it had crashed: it neither ticks nor receives messages until brought back online
+func (node *Node) SetOffline(offline bool) {
+	node.offline = offline
+}
+
+func (node *Node) IsOffline() bool {
+	return node.offline
+}
+
+func (node *Node) String() string {
+	return fmt.Sprintf("node-%d", node.Index)
+}
+
+// PushMessageToChain queues msg on the network of the simulation, it is delivered to the other nodes once the sender returns
+func (node *Node) PushMessageToChain(msg wire.Message, chain blockchain.ChainInterface) error {
+	bftMsg, ok := msg.(*wire.MessageBFT)
+	if !ok {
+		return errors.Errorf("unexpected message %+v", msg.MessageType())
+	}
+	node.sim.Network.send(node, bftMsg)
+	return nil
+}
+
+// PushMessageToBeacon queues msg on the network of the simulation like PushMessageToChain, every node runs the beacon consensus
+func (node *Node) PushMessageToBeacon(msg wire.Message, exclusivePeerIDs map[libp2p.ID]bool) error {
+	return node.PushMessageToChain(msg, nil)
+}
+
+func (node *Node) UpdateConsensusState(role string, userPbk string, currentShard *byte, beaconCommittee []string, shardCommittee map[byte][]string) {
+}
+
+func (node *Node) IsEnableMining() bool {
+	return true
+}
+
+func (node *Node) GetMiningKeys() string {
+	return common.BlsConsensus + ":" + node.MiningSeed
+}
+
+func (node *Node) GetPrivateKey() string {
+	return node.Account.PrivateKey
+}
+
+func (node *Node) DropAllConnections() {
+}
+
+// GetConsensusDataDir - every node keeps its vote journals in its own directory of the simulation data directory
+func (node *Node) GetConsensusDataDir() string {
+	return filepath.Join(node.sim.config.DataDir, "consensus", node.String())
+}
@@ -0,0 +1,507 @@
+/*
+Package simulation runs a whole network, the beacon chain, its shards and their committees, inside one process
+so scenarios like cross shard transfers, staking, slashing or PDE trades can be scripted and checked in go test.
+
+Every validator is a Node with its own mining key and one consensus per chain, BLSBFT unless Config.ChainConsensus
+says otherwise. The consensus messages go through
+an in-memory Network and nothing runs in the background: Step moves the Clock forward, lets every node tick
+and delivers the messages they sent, in a fixed order, so a scenario gives the same chain every time it runs.
+
+The blockchain package keeps its best states in process-wide singletons, so all nodes share one BlockChain
+and a block committed by a committee is stored once for everybody. Only one simulation may run at a time in a process.
+*/
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/blockchain"
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus"
+	"github.com/incognitochain/incognito-chain/database"
+	_ "github.com/incognitochain/incognito-chain/database/lvdb"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/mempool"
+	"github.com/incognitochain/incognito-chain/metadata"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/pubsub"
+	"github.com/incognitochain/incognito-chain/transaction"
+	"github.com/incognitochain/incognito-chain/wallet"
+	"github.com/incognitochain/incognito-chain/wire"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultActiveShards        = 2
+	DefaultBeaconCommitteeSize = 4
+	DefaultShardCommitteeSize  = 4
+	DefaultInitialBalance      = 1000000 * 1e9 // 1M PRV
+	DefaultStepInterval        = time.Second
+	// txChannelSize bounds the transactions a block can add or remove from the block generator between two drains
+	txChannelSize = 10000
+)
+
+type Config struct {
+	ActiveShards        int
+	BeaconCommitteeSize int
+	ShardCommitteeSize  int
+	// Candidates is the number of nodes outside every committee at genesis, they can stake during a scenario
+	Candidates int
+	// Accounts is the number of accounts funded at genesis with InitialBalance, spread over the shards
+	Accounts       int
+	InitialBalance uint64
+	// Epoch and RandomTime override the testnet values, short epochs make staking and swapping scenarios fast
+	Epoch        uint64
+	RandomTime   uint64
+	StepInterval time.Duration
+	// DataDir holds the database, a temporary directory removed by Close when empty
+	DataDir string
+	// Logger receives the logs of every package, they are discarded when nil
+	Logger common.Logger
+	// ChainConsensus runs chains with another consensus than BLSBFT, chain key -> implementation e.g. hotstuff
+	ChainConsensus map[string]string
+}
+
+// Account is a key pair of the simulation, every node has one and Config.Accounts more are funded at genesis
+type Account struct {
+	PrivateKey     string
+	PaymentAddress string
+	KeySet         *incognitokey.KeySet
+	ShardID        byte
+}
+
+type Simulation struct {
+	Clock      *Clock
+	Network    *Network
+	BlockChain *blockchain.BlockChain
+	TxPool     *mempool.TxPool
+	Params     *blockchain.Params
+	Nodes      []*Node
+	Accounts   []*Account
+
+	config            Config
+	logger            common.Logger
+	db                database.DatabaseInterface
+	removeDataDir     bool
+	chainKeys         []string
+	chains            map[string]blockchain.ChainInterface
+	engine            *consensusEngine
+	blockGen          *blockchain.BlockGenerator
+	shardToBeaconPool blockchain.ShardToBeaconPool
+	crossShardPools   map[byte]blockchain.CrossShardPool
+	cPendingTxs       chan metadata.Transaction
+	cRemovedTxs       chan metadata.Transaction
+}
+
+// New builds the genesis blocks of a network whose committees are made of new nodes and starts their consensus,
+// the first blocks are produced once Step has moved the clock past the minimum block interval
+func New(config Config) (*Simulation, error) {
+	if config.ActiveShards == 0 {
+		config.ActiveShards = DefaultActiveShards
+	}
+	if config.BeaconCommitteeSize == 0 {
+		config.BeaconCommitteeSize = DefaultBeaconCommitteeSize
+	}
+	if config.ShardCommitteeSize == 0 {
+		config.ShardCommitteeSize = DefaultShardCommitteeSize
+	}
+	if config.InitialBalance == 0 {
+		config.InitialBalance = DefaultInitialBalance
+	}
+	if config.StepInterval == 0 {
+		config.StepInterval = DefaultStepInterval
+	}
+	if config.ActiveShards > common.MaxShardNumber {
+		return nil, errors.Errorf("at most %+v shards, got %+v", common.MaxShardNumber, config.ActiveShards)
+	}
+	sim := &Simulation{
+		Network:         newNetwork(),
+		config:          config,
+		logger:          config.Logger,
+		chains:          make(map[string]blockchain.ChainInterface),
+		engine:          &consensusEngine{},
+		crossShardPools: make(map[byte]blockchain.CrossShardPool),
+		cPendingTxs:     make(chan metadata.Transaction, txChannelSize),
+		cRemovedTxs:     make(chan metadata.Transaction, txChannelSize),
+	}
+	if sim.logger == nil {
+		sim.logger = common.NewBackend(nil).Logger("Simulation log", true)
+	}
+	initLoggers(sim.logger)
+
+	dataDir := config.DataDir
+	if dataDir == "" {
+		var err error
+		dataDir, err = ioutil.TempDir(os.TempDir(), "simulation_")
+		if err != nil {
+			return nil, err
+		}
+		sim.removeDataDir = true
+		sim.config.DataDir = dataDir
+	}
+	db, err := database.Open("leveldb", dataDir)
+	if err != nil {
+		sim.Close()
+		return nil, err
+	}
+	sim.db = db
+	if err := sim.init(); err != nil {
+		sim.Close()
+		return nil, err
+	}
+	return sim, nil
+}
+
+func initLoggers(logger common.Logger) {
+	blockchain.Logger.Init(logger)
+	blockchain.BLogger.Init(logger)
+	consensus.Logger.Init(logger)
+	database.Logger.Init(logger)
+	mempool.Logger.Init(logger)
+	metadata.Logger.Init(logger)
+	privacy.Logger.Init(logger)
+	transaction.Logger.Init(logger)
+	wallet.Logger.Init(logger)
+}
+
+func (sim *Simulation) init() error {
+	config := sim.config
+	// validators live in the shard they validate so their rewards and returned stakes stay in it
+	nodeCount := config.BeaconCommitteeSize + config.ActiveShards*config.ShardCommitteeSize + config.Candidates
+	for i := 0; i < nodeCount; i++ {
+		shardID := byte(i % config.ActiveShards)
+		if i >= config.BeaconCommitteeSize && i < config.BeaconCommitteeSize+config.ActiveShards*config.ShardCommitteeSize {
+			shardID = byte((i - config.BeaconCommitteeSize) / config.ShardCommitteeSize)
+		}
+		node, err := newNode(sim, i, newAccount(fmt.Sprintf("node-%d", i), shardID))
+		if err != nil {
+			return err
+		}
+		sim.Nodes = append(sim.Nodes, node)
+	}
+	for i := 0; i < config.Accounts; i++ {
+		sim.Accounts = append(sim.Accounts, newAccount(fmt.Sprintf("account-%d", i), byte(i%config.ActiveShards)))
+	}
+
+	genesisTime, err := time.Parse("2006-01-02T15:04:05.000Z", blockchain.TestnetGenesisBlockTime)
+	if err != nil {
+		return err
+	}
+	sim.Clock = NewClock(genesisTime)
+	params, err := sim.buildParams()
+	if err != nil {
+		return err
+	}
+	sim.Params = params
+
+	pubSubManager := pubsub.NewPubSubManager()
+	beaconPool := mempool.NewBeaconPool()
+	shardPools := make(map[byte]blockchain.ShardPool)
+	for i := 0; i < common.MaxShardNumber; i++ {
+		shardPools[byte(i)] = mempool.NewShardPool(byte(i))
+	}
+	for i := 0; i < 255; i++ {
+		sim.crossShardPools[byte(i)] = mempool.NewCrossShardPool(byte(i), sim.db)
+	}
+	shardToBeaconPool := mempool.NewShardToBeaconPool()
+	sim.shardToBeaconPool = shardToBeaconPool
+	relayShards := []byte{}
+	for i := 0; i < common.MaxShardNumber; i++ {
+		relayShards = append(relayShards, byte(i))
+	}
+
+	sim.BlockChain = &blockchain.BlockChain{}
+	sim.TxPool = &mempool.TxPool{}
+	sim.blockGen, err = blockchain.NewBlockGenerator(sim.TxPool, sim.BlockChain, shardToBeaconPool, sim.crossShardPools, sim.cPendingTxs, sim.cRemovedTxs)
+	if err != nil {
+		return err
+	}
+	err = sim.BlockChain.Init(&blockchain.Config{
+		ChainParams:       params,
+		DataBase:          sim.db,
+		BlockGen:          sim.blockGen,
+		RelayShards:       relayShards,
+		BeaconPool:        beaconPool,
+		ShardPool:         shardPools,
+		ShardToBeaconPool: shardToBeaconPool,
+		CrossShardPool:    sim.crossShardPools,
+		Server:            server{},
+		NodeMode:          common.NodeModeAuto,
+		FeeEstimator:      make(map[byte]blockchain.FeeEstimator),
+		PubSubManager:     pubSubManager,
+		ConsensusEngine:   sim.engine,
+		Highway:           highway{},
+		Clock:             sim.Clock,
+	})
+	if err != nil {
+		return err
+	}
+	sim.BlockChain.InitChannelBlockchain(sim.cRemovedTxs)
+	// a limit fee of 0 accepts transactions of any fee, Transfer callers pick the fee they want to test
+	feeEstimators := make(map[byte]*mempool.FeeEstimator)
+	for i := 0; i < common.MaxShardNumber; i++ {
+		feeEstimators[byte(i)] = mempool.NewFeeEstimator(mempool.DefaultEstimateFeeMaxRollback, mempool.DefaultEstimateFeeMinRegisteredBlocks, 0)
+		sim.BlockChain.SetFeeEstimator(feeEstimators[byte(i)], byte(i))
+	}
+	beaconPool.SetBeaconState(sim.BlockChain.BestState.Beacon.BeaconHeight)
+	for shardID, bestState := range sim.BlockChain.BestState.Shard {
+		shardPools[shardID].SetShardState(bestState.ShardHeight)
+	}
+	shardToBeaconPool.SetShardState(sim.BlockChain.BestState.Beacon.GetBestShardHeight())
+
+	sim.TxPool.Init(&mempool.Config{
+		BlockChain:    sim.BlockChain,
+		DataBase:      sim.db,
+		ChainParams:   params,
+		FeeEstimator:  feeEstimators,
+		MaxTx:         txChannelSize,
+		RelayShards:   relayShards,
+		PubSubManager: pubSubManager,
+	})
+	sim.TxPool.InitChannelMempool(sim.cPendingTxs, sim.cRemovedTxs)
+	sim.BlockChain.AddTxPool(sim.TxPool)
+	tempTxPool := &mempool.TxPool{}
+	tempTxPool.Init(&mempool.Config{
+		BlockChain:    sim.BlockChain,
+		DataBase:      sim.db,
+		ChainParams:   params,
+		FeeEstimator:  feeEstimators,
+		MaxTx:         txChannelSize,
+		PubSubManager: pubSubManager,
+	})
+	sim.BlockChain.AddTempTxPool(tempTxPool)
+
+	sim.chainKeys = []string{common.BeaconChainKey}
+	sim.chains[common.BeaconChainKey] = &beaconChain{ChainInterface: sim.BlockChain.Chains[common.BeaconChainKey], sim: sim}
+	for i := 0; i < config.ActiveShards; i++ {
+		chainKey := common.GetShardChainKey(byte(i))
+		sim.chainKeys = append(sim.chainKeys, chainKey)
+		sim.chains[chainKey] = &shardChain{ChainInterface: sim.BlockChain.Chains[chainKey], sim: sim, shardID: byte(i)}
+	}
+	for _, node := range sim.Nodes {
+		if err := node.startConsensus(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildParams returns the testnet parameters with the committees, genesis blocks and epochs of the simulation
+func (sim *Simulation) buildParams() (*blockchain.Params, error) {
+	config := sim.config
+	genesisParams := blockchain.GenesisParams{ConsensusAlgorithm: common.BlsConsensus}
+	for i, node := range sim.Nodes {
+		if i >= config.BeaconCommitteeSize+config.ActiveShards*config.ShardCommitteeSize {
+			break
+		}
+		if i < config.BeaconCommitteeSize {
+			genesisParams.PreSelectBeaconNodeSerializedPubkey = append(genesisParams.PreSelectBeaconNodeSerializedPubkey, node.CommitteeKeyB58)
+			genesisParams.PreSelectBeaconNodeSerializedPaymentAddress = append(genesisParams.PreSelectBeaconNodeSerializedPaymentAddress, node.Account.PaymentAddress)
+		} else {
+			genesisParams.PreSelectShardNodeSerializedPubkey = append(genesisParams.PreSelectShardNodeSerializedPubkey, node.CommitteeKeyB58)
+			genesisParams.PreSelectShardNodeSerializedPaymentAddress = append(genesisParams.PreSelectShardNodeSerializedPaymentAddress, node.Account.PaymentAddress)
+		}
+	}
+	for _, account := range sim.Accounts {
+		tx := new(transaction.Tx)
+		if err := tx.InitTxSalary(config.InitialBalance, &account.KeySet.PaymentAddress, &account.KeySet.PrivateKey, sim.db, nil); err != nil {
+			return nil, err
+		}
+		txJSON, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		genesisParams.InitialIncognito = append(genesisParams.InitialIncognito, string(txJSON))
+	}
+
+	params := blockchain.ChainTestParam
+	params.ActiveShards = config.ActiveShards
+	params.MinBeaconCommitteeSize = config.BeaconCommitteeSize
+	params.MaxBeaconCommitteeSize = config.BeaconCommitteeSize
+	params.MinShardCommitteeSize = config.ShardCommitteeSize
+	params.MaxShardCommitteeSize = config.ShardCommitteeSize
+	if config.Epoch != 0 {
+		params.Epoch = config.Epoch
+	}
+	if config.RandomTime != 0 {
+		params.RandomTime = config.RandomTime
+	}
+	if params.RandomTime >= params.Epoch {
+		return nil, errors.Errorf("random time %+v must be lower than epoch %+v", params.RandomTime, params.Epoch)
+	}
+	params.GenesisBeaconBlock = blockchain.CreateBeaconGenesisBlock(1, blockchain.Testnet, blockchain.TestnetGenesisBlockTime, genesisParams)
+	params.GenesisShardBlock = blockchain.CreateShardGenesisBlock(1, blockchain.Testnet, blockchain.TestnetGenesisBlockTime, genesisParams)
+	return &params, nil
+}
+
+// newAccount derives an account of shardID from name, the same name always gives the same keys
+func newAccount(name string, shardID byte) *Account {
+	for i := 0; ; i++ {
+		keySet := new(incognitokey.KeySet).GenerateKey(common.HashB([]byte(fmt.Sprintf("simulation-%s-%d", name, i))))
+		pk := keySet.PaymentAddress.Pk
+		if common.GetShardIDFromLastByte(pk[len(pk)-1]) != shardID {
+			continue
+		}
+		keyWallet := wallet.KeyWallet{
+			ChildNumber: make([]byte, 4),
+			ChainCode:   make([]byte, 32),
+			KeySet:      *keySet,
+		}
+		return &Account{
+			PrivateKey:     keyWallet.Base58CheckSerialize(wallet.PriKeyType),
+			PaymentAddress: keyWallet.Base58CheckSerialize(wallet.PaymentAddressType),
+			KeySet:         keySet,
+			ShardID:        shardID,
+		}
+	}
+}
+
+// Step moves the clock forward by the step interval, then every online node ticks the consensus of each chain,
+// nodes in order and the beacon chain first, and the messages sent by each tick are delivered before the next one
+func (sim *Simulation) Step() {
+	sim.Clock.Advance(sim.config.StepInterval)
+	for _, node := range sim.Nodes {
+		if node.offline {
+			continue
+		}
+		for _, chainKey := range sim.chainKeys {
+			sim.engine.current = node
+			node.Consensus[chainKey].Tick()
+			sim.deliver()
+		}
+	}
+	sim.engine.current = nil
+}
+
+// RunUntil calls Step until done returns true, it fails when done is still false after maxSteps steps
+func (sim *Simulation) RunUntil(maxSteps int, done func() bool) error {
+	for i := 0; i < maxSteps; i++ {
+		if done() {
+			return nil
+		}
+		sim.Step()
+	}
+	if done() {
+		return nil
+	}
+	return errors.Errorf("condition not reached after %+v steps, beacon height %+v", maxSteps, sim.BeaconHeight())
+}
+
+// deliver hands the queued messages to their receivers until no message is left
+func (sim *Simulation) deliver() {
+	for {
+		e, ok := sim.Network.pop()
+		if !ok {
+			break
+		}
+		data, err := e.msg.JsonSerialize()
+		if err != nil {
+			sim.logger.Error(err)
+			continue
+		}
+		for _, node := range sim.Nodes {
+			if node == e.from || node.offline || !sim.Network.canReach(e.from, node) {
+				continue
+			}
+			if sim.Network.Filter != nil && !sim.Network.Filter(e.from, node, e.msg) {
+				continue
+			}
+			msg := new(wire.MessageBFT)
+			if err := msg.JsonDeserialize(string(data)); err != nil {
+				sim.logger.Error(err)
+				continue
+			}
+			bft, ok := node.Consensus[msg.ChainKey]
+			if !ok {
+				continue
+			}
+			sim.engine.current = node
+			bft.ProcessBFTMsg(msg)
+		}
+	}
+	sim.drainTxChannels()
+}
+
+// drainTxChannels does the work of the block generator workers, which a simulation does not start
+func (sim *Simulation) drainTxChannels() {
+	for {
+		select {
+		case tx := <-sim.cPendingTxs:
+			sim.blockGen.AddTransactionV2(tx)
+		case tx := <-sim.cRemovedTxs:
+			sim.blockGen.RemoveTransactionV2(tx)
+		default:
+			return
+		}
+	}
+}
+
+func (sim *Simulation) BeaconHeight() uint64 {
+	return sim.BlockChain.BestState.Beacon.BeaconHeight
+}
+
+func (sim *Simulation) ShardHeight(shardID byte) uint64 {
+	return sim.BlockChain.BestState.Shard[shardID].ShardHeight
+}
+
+// SubmitTx adds tx to the pool the shard block producers pick transactions from
+func (sim *Simulation) SubmitTx(tx metadata.Transaction) error {
+	if _, _, err := sim.TxPool.MaybeAcceptTransaction(tx, int64(sim.BeaconHeight())); err != nil {
+		return err
+	}
+	sim.blockGen.AddTransactionV2(tx)
+	return nil
+}
+
+// Balance returns the PRV of account not spent by a transaction in a block
+func (sim *Simulation) Balance(account *Account) (uint64, error) {
+	coins, err := sim.BlockChain.GetListOutputCoinsByKeyset(account.KeySet, account.ShardID, &common.PRVCoinID)
+	if err != nil {
+		return 0, err
+	}
+	balance := uint64(0)
+	for _, coin := range coins {
+		balance += coin.CoinDetails.GetValue()
+	}
+	return balance, nil
+}
+
+// Transfer submits a transaction without privacy sending amount PRV from one account to another,
+// it spends every coin of the sender so the sender must wait for it to be in a block before the next transfer
+func (sim *Simulation) Transfer(from *Account, to *Account, amount uint64, fee uint64) (metadata.Transaction, error) {
+	coins, err := sim.BlockChain.GetListOutputCoinsByKeyset(from.KeySet, from.ShardID, &common.PRVCoinID)
+	if err != nil {
+		return nil, err
+	}
+	paymentInfos := []*privacy.PaymentInfo{{PaymentAddress: to.KeySet.PaymentAddress, Amount: amount}}
+	tx := new(transaction.Tx)
+	err = tx.Init(transaction.NewTxPrivacyInitParams(&from.KeySet.PrivateKey, paymentInfos, transaction.ConvertOutputCoinToInputCoin(coins), fee, false, sim.db, nil, nil, nil))
+	if err != nil {
+		return nil, err
+	}
+	if err := sim.SubmitTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Close closes the database, and removes it when the simulation made its directory
+func (sim *Simulation) Close() {
+	for _, node := range sim.Nodes {
+		for _, bft := range node.Consensus {
+			bft.Stop()
+		}
+	}
+	if sim.db != nil {
+		if err := sim.db.Close(); err != nil {
+			sim.logger.Error(err)
+		}
+	}
+	if sim.removeDataDir {
+		os.RemoveAll(sim.config.DataDir)
+	}
+}
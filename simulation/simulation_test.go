@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/consensus/hotstuff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulationProducesBlocks(t *testing.T) {
+	sim, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	err = sim.RunUntil(300, func() bool {
+		return sim.BeaconHeight() >= 3 && sim.ShardHeight(0) >= 3 && sim.ShardHeight(1) >= 3
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, sim.Network.Sent["propose"])
+	assert.NotZero(t, sim.Network.Sent["vote"])
+}
+
+func TestSimulationCrossShardTransfer(t *testing.T) {
+	sim, err := New(Config{Accounts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	from, to := sim.Accounts[0], sim.Accounts[1]
+	assert.NotEqual(t, from.ShardID, to.ShardID)
+	_, err = sim.Transfer(from, to, 1000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sim.RunUntil(300, func() bool {
+		balance, err := sim.Balance(to)
+		return err == nil && balance == DefaultInitialBalance+1000
+	})
+	assert.Nil(t, err)
+	balance, err := sim.Balance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(DefaultInitialBalance-1000-100), balance)
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	run := func() []string {
+		sim, err := New(Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sim.Close()
+		if err := sim.RunUntil(300, func() bool { return sim.BeaconHeight() >= 3 }); err != nil {
+			t.Fatal(err)
+		}
+		return []string{
+			sim.BlockChain.BestState.Beacon.BestBlockHash.String(),
+			sim.BlockChain.BestState.Shard[0].BestBlockHash.String(),
+			sim.BlockChain.BestState.Shard[1].BestBlockHash.String(),
+		}
+	}
+	assert.Equal(t, run(), run())
+}
+
+func TestSimulationOfflineProposer(t *testing.T) {
+	sim, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	// one beacon validator down out of four still leaves a quorum, its rounds time out and the next proposer takes over
+	sim.Nodes[0].SetOffline(true)
+	err = sim.RunUntil(600, func() bool { return sim.BeaconHeight() >= 6 })
+	assert.Nil(t, err)
+	assert.NotZero(t, sim.Network.Sent["timeout"])
+}
+
+func TestSimulationRestartedValidators(t *testing.T) {
+	sim, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	err = sim.RunUntil(300, func() bool { return sim.BeaconHeight() >= 3 })
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(sim.Nodes[0].GetConsensusDataDir(), common.BeaconChainKey+".journal"))
+	if assert.Nil(t, err) {
+		assert.NotZero(t, info.Size())
+	}
+	// restarted validators read what they signed from their vote journals and keep producing blocks
+	for _, node := range sim.Nodes {
+		assert.Nil(t, node.RestartConsensus())
+	}
+	height := sim.BeaconHeight()
+	err = sim.RunUntil(300, func() bool { return sim.BeaconHeight() >= height+3 })
+	assert.Nil(t, err)
+}
+
+func hotStuffChains() map[string]string {
+	return map[string]string{
+		common.BeaconChainKey:      hotstuff.ConsensusName,
+		common.GetShardChainKey(0): hotstuff.ConsensusName,
+		common.GetShardChainKey(1): hotstuff.ConsensusName,
+	}
+}
+
+func TestSimulationHotStuffProducesBlocks(t *testing.T) {
+	sim, err := New(Config{ChainConsensus: hotStuffChains()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	err = sim.RunUntil(300, func() bool {
+		return sim.BeaconHeight() >= 4 && sim.ShardHeight(0) >= 4 && sim.ShardHeight(1) >= 4
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, sim.Network.Sent[hotstuff.MSG_PROPOSE])
+	assert.NotZero(t, sim.Network.Sent[hotstuff.MSG_VOTE])
+	assert.Zero(t, sim.Network.Sent["propose"])
+}
+
+func TestSimulationHotStuffOfflineLeader(t *testing.T) {
+	sim, err := New(Config{ChainConsensus: hotStuffChains()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	// the views led by the offline node time out, the validators move to the next view with new view messages
+	sim.Nodes[0].SetOffline(true)
+	err = sim.RunUntil(1000, func() bool { return sim.BeaconHeight() >= 6 })
+	assert.Nil(t, err)
+	assert.NotZero(t, sim.Network.Sent[hotstuff.MSG_NEWVIEW])
+}
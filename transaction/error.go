@@ -63,6 +63,7 @@ const (
 	RejectTxType
 	RejectTxInfoSize
 	RejectTxMedataWithBlockChain
+	InvalidUnsignedTxError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -110,6 +111,7 @@ var ErrCodeMessage = map[int]struct {
 	RejectTxType:                                  {-1037, "Wrong tx type"},
 	RejectTxInfoSize:                              {-1038, "Wrong tx info length"},
 	RejectTxMedataWithBlockChain:                  {-1039, "Reject invalid metadata with blockchain"},
+	InvalidUnsignedTxError:                        {-1040, "Unsigned tx is invalid"},
 
 	// for PRV
 	InvalidSanityDataPRVError:  {-2000, "Invalid sanity data for PRV"},
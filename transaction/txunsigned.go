@@ -0,0 +1,325 @@
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/common/base58"
+	"github.com/incognitochain/incognito-chain/database"
+	"github.com/incognitochain/incognito-chain/incognitokey"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/wallet"
+)
+
+// UnsignedTxPayment is a receiver of an unsigned tx
+type UnsignedTxPayment struct {
+	PaymentAddress string // base58 check serialized payment address
+	Amount         uint64
+	Message        []byte
+}
+
+// UnsignedTx is a PRV transfer prepared by an online node which only knows the viewing key of the sender.
+// It carries all the data the payment proof needs: the input coins with their values and randomness,
+// the random commitments hiding them and the SNDs of the output coins,
+// so a machine which holds the private key proves and signs it with Sign without any database.
+type UnsignedTx struct {
+	SenderPaymentAddress string
+	PaymentInfos         []UnsignedTxPayment // the change back to the sender is not listed, Sign adds it
+	InputCoins           []string            // base58 check encoded input coins
+	Fee                  uint64
+	HasPrivacy           bool
+	Info                 []byte
+	LockTime             int64
+
+	// one-out-of-many proving data, only with privacy
+	CommitmentIndices   []uint64
+	Commitments         []string // base58 check encoded commitments of CommitmentIndices
+	MyCommitmentIndices []uint64
+
+	SNDOutputs []string // base58 check encoded SNDs of the payments then of the change
+}
+
+// NewUnsignedTx chooses the random commitments and the output SNDs of a transfer of PRV from inputCoins,
+// senderPaymentAddress receives the change. The input coins must carry their values and randomness,
+// as the coins decrypted with the viewing key of the sender do
+func NewUnsignedTx(senderPaymentAddress privacy.PaymentAddress,
+	paymentInfo []*privacy.PaymentInfo,
+	inputCoins []*privacy.InputCoin,
+	fee uint64,
+	hasPrivacy bool,
+	db database.DatabaseInterface,
+	info []byte) (*UnsignedTx, error) {
+	if len(inputCoins) > 255 {
+		return nil, NewTransactionErr(InputCoinIsVeryLargeError, nil, strconv.Itoa(len(inputCoins)))
+	}
+	if len(paymentInfo) > 254 {
+		return nil, NewTransactionErr(PaymentInfoIsVeryLargeError, nil, strconv.Itoa(len(paymentInfo)))
+	}
+	if len(info) > MaxSizeInfo {
+		return nil, NewTransactionErr(ExceedSizeInfoTxError, nil)
+	}
+	estimateTxSizeParam := NewEstimateTxSizeParam(len(inputCoins), len(paymentInfo), hasPrivacy, nil, nil, 0)
+	if txSize := EstimateTxSize(estimateTxSizeParam); txSize > common.MaxTxSize {
+		return nil, NewTransactionErr(ExceedSizeTx, nil, strconv.Itoa(int(txSize)))
+	}
+	numOutputs, err := countOutputs(paymentInfo, inputCoins, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	utx := &UnsignedTx{
+		SenderPaymentAddress: base58CheckPaymentAddress(senderPaymentAddress),
+		PaymentInfos:         make([]UnsignedTxPayment, 0, len(paymentInfo)),
+		InputCoins:           make([]string, 0, len(inputCoins)),
+		Fee:                  fee,
+		HasPrivacy:           hasPrivacy,
+		Info:                 info,
+		LockTime:             time.Now().Unix(),
+		SNDOutputs:           make([]string, 0, numOutputs),
+	}
+	for _, p := range paymentInfo {
+		if len(p.Message) > privacy.MaxSizeInfoCoin {
+			return nil, NewTransactionErr(ExceedSizeInfoOutCoinError, nil)
+		}
+		utx.PaymentInfos = append(utx.PaymentInfos, UnsignedTxPayment{
+			PaymentAddress: base58CheckPaymentAddress(p.PaymentAddress),
+			Amount:         p.Amount,
+			Message:        p.Message,
+		})
+	}
+	for _, coin := range inputCoins {
+		utx.InputCoins = append(utx.InputCoins, base58.Base58Check{}.Encode(coin.Bytes(), common.ZeroByte))
+	}
+
+	tokenID := &common.Hash{}
+	err = tokenID.SetBytes(common.PRVCoinID[:])
+	if err != nil {
+		return nil, NewTransactionErr(TokenIDInvalidError, err, tokenID.GetBytes())
+	}
+	if hasPrivacy && len(inputCoins) > 0 {
+		shardID := common.GetShardIDFromLastByte(senderPaymentAddress.Pk[len(senderPaymentAddress.Pk)-1])
+		randomParams := NewRandomCommitmentsProcessParam(inputCoins, privacy.CommitmentRingSize, db, shardID, tokenID)
+		commitmentIndices, myCommitmentIndices, commitments := RandomCommitmentsProcess(randomParams)
+		if len(commitmentIndices) != len(inputCoins)*privacy.CommitmentRingSize {
+			return nil, NewTransactionErr(RandomCommitmentError, nil)
+		}
+		if len(myCommitmentIndices) != len(inputCoins) {
+			return nil, NewTransactionErr(RandomCommitmentError, errors.New("number of list my commitment indices must be equal to number of input coins"))
+		}
+		utx.CommitmentIndices = commitmentIndices
+		utx.MyCommitmentIndices = myCommitmentIndices
+		utx.Commitments = make([]string, 0, len(commitments))
+		for _, commitment := range commitments {
+			utx.Commitments = append(utx.Commitments, base58.Base58Check{}.Encode(commitment, common.ZeroByte))
+		}
+	}
+
+	// SNDs are checked against the database here, the signer can not do it
+	for len(utx.SNDOutputs) < numOutputs {
+		sndOut := privacy.RandomScalar()
+		existed, err := CheckSNDerivatorExistence(tokenID, sndOut, db)
+		if err != nil {
+			Logger.log.Error(err)
+		}
+		sndOutStr := base58.Base58Check{}.Encode(sndOut.ToBytesS(), common.ZeroByte)
+		if existed || common.IndexOfStr(sndOutStr, utx.SNDOutputs) > -1 {
+			continue
+		}
+		utx.SNDOutputs = append(utx.SNDOutputs, sndOutStr)
+	}
+	return utx, nil
+}
+
+// Sign proves and signs utx with the private key of its sender
+// It returns the tx and the serial numbers of the spent input coins keyed by their coin commitments, both base58 check encoded:
+// the online node, which can not derive serial numbers, uses them to leave these coins out of the next unsigned txs
+func (utx *UnsignedTx) Sign(senderSK *privacy.PrivateKey) (*Tx, map[string]string, error) {
+	senderKeySet := incognitokey.KeySet{}
+	err := senderKeySet.InitFromPrivateKey(senderSK)
+	if err != nil {
+		return nil, nil, NewTransactionErr(PrivateKeySenderInvalidError, err)
+	}
+	senderKey, err := wallet.Base58CheckDeserialize(utx.SenderPaymentAddress)
+	if err != nil {
+		return nil, nil, NewTransactionErr(InvalidUnsignedTxError, err)
+	}
+	if !bytes.Equal(senderKey.KeySet.PaymentAddress.Pk, senderKeySet.PaymentAddress.Pk) {
+		return nil, nil, NewTransactionErr(InvalidUnsignedTxError, errors.New("private key is not the key of the sender"))
+	}
+	if len(utx.Info) > MaxSizeInfo {
+		return nil, nil, NewTransactionErr(ExceedSizeInfoTxError, nil)
+	}
+
+	paymentInfo, err := utx.decodePaymentInfos()
+	if err != nil {
+		return nil, nil, err
+	}
+	inputCoins, err := utx.decodeInputCoins()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, coin := range inputCoins {
+		// the online node can not derive the serial number, it needs the private key
+		serialNumber := new(privacy.Point).Derive(privacy.PedCom.G[privacy.PedersenPrivateKeyIndex],
+			new(privacy.Scalar).FromBytesS(*senderSK), coin.CoinDetails.GetSNDerivator())
+		coin.CoinDetails.SetSerialNumber(serialNumber)
+	}
+
+	numOutputs, err := countOutputs(paymentInfo, inputCoins, utx.Fee)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(utx.SNDOutputs) != numOutputs {
+		return nil, nil, NewTransactionErr(InvalidUnsignedTxError, fmt.Errorf("%d output SNDs for %d outputs", len(utx.SNDOutputs), numOutputs))
+	}
+	sndOutputs := make([]*privacy.Scalar, 0, len(utx.SNDOutputs))
+	for _, sndStr := range utx.SNDOutputs {
+		sndBytes, _, err := base58.Base58Check{}.Decode(sndStr)
+		if err != nil {
+			return nil, nil, NewTransactionErr(InvalidUnsignedTxError, err)
+		}
+		sndOutputs = append(sndOutputs, new(privacy.Scalar).FromBytesS(sndBytes))
+	}
+
+	commitments := make([][]byte, 0, len(utx.Commitments))
+	for _, commitmentStr := range utx.Commitments {
+		commitment, _, err := base58.Base58Check{}.Decode(commitmentStr)
+		if err != nil {
+			return nil, nil, NewTransactionErr(InvalidUnsignedTxError, err)
+		}
+		commitments = append(commitments, commitment)
+	}
+	if utx.HasPrivacy && len(inputCoins) > 0 {
+		if len(commitments) != len(utx.CommitmentIndices) || len(utx.MyCommitmentIndices) != len(inputCoins) {
+			return nil, nil, NewTransactionErr(RandomCommitmentError, nil)
+		}
+		// the ring of every input coin must hold the coin itself at the given index
+		for i, coin := range inputCoins {
+			myIndex := utx.MyCommitmentIndices[i]
+			if myIndex >= uint64(len(commitments)) || !bytes.Equal(commitments[myIndex], coin.CoinDetails.GetCoinCommitment().ToBytesS()) {
+				return nil, nil, NewTransactionErr(RandomCommitmentError, fmt.Errorf("commitment of input coin %d is not in its ring", i))
+			}
+		}
+	}
+
+	// proving with privacy hides the input coins in place, collect the serial numbers first
+	serialNumbers := make(map[string]string)
+	for _, coin := range inputCoins {
+		commitment := coin.CoinDetails.GetCoinCommitment().ToBytesS()
+		serialNumber := coin.CoinDetails.GetSerialNumber().ToBytesS()
+		serialNumbers[base58.Base58Check{}.Encode(commitment, common.ZeroByte)] = base58.Base58Check{}.Encode(serialNumber, common.ZeroByte)
+	}
+
+	tx := &Tx{
+		LockTime: utx.LockTime,
+	}
+	err = tx.InitForASM(NewTxPrivacyInitParamsForASM(senderSK, paymentInfo, inputCoins, utx.Fee, utx.HasPrivacy,
+		nil, nil, utx.Info, utx.CommitmentIndices, commitments, utx.MyCommitmentIndices, sndOutputs))
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, serialNumbers, nil
+}
+
+// UnsignedTxDetails is what the signer of an unsigned tx pays, in nano PRV
+type UnsignedTxDetails struct {
+	SenderPaymentAddress string
+	PaymentInfos         []UnsignedTxPayment
+	Change               uint64 // back to the sender
+	Fee                  uint64
+	Info                 []byte
+}
+
+// Details decodes the receivers, the change and the fee of utx, for the signer to check them before Sign.
+// The values of the input coins come from the template but the proof of Sign binds them to the coin commitments,
+// so a tx signed from a template which lies about them is rejected by the chain
+func (utx *UnsignedTx) Details() (*UnsignedTxDetails, error) {
+	paymentInfo, err := utx.decodePaymentInfos()
+	if err != nil {
+		return nil, err
+	}
+	inputCoins, err := utx.decodeInputCoins()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := countOutputs(paymentInfo, inputCoins, utx.Fee); err != nil {
+		return nil, err
+	}
+	change := uint64(0)
+	for _, coin := range inputCoins {
+		change += coin.CoinDetails.GetValue()
+	}
+	change -= utx.Fee
+	for _, p := range paymentInfo {
+		change -= p.Amount
+	}
+	return &UnsignedTxDetails{
+		SenderPaymentAddress: utx.SenderPaymentAddress,
+		PaymentInfos:         utx.PaymentInfos,
+		Change:               change,
+		Fee:                  utx.Fee,
+		Info:                 utx.Info,
+	}, nil
+}
+
+func (utx *UnsignedTx) decodePaymentInfos() ([]*privacy.PaymentInfo, error) {
+	paymentInfo := make([]*privacy.PaymentInfo, 0, len(utx.PaymentInfos))
+	for _, p := range utx.PaymentInfos {
+		receiverKey, err := wallet.Base58CheckDeserialize(p.PaymentAddress)
+		if err != nil || len(receiverKey.KeySet.PaymentAddress.Pk) == 0 {
+			return nil, NewTransactionErr(InvalidUnsignedTxError, fmt.Errorf("payment address %s is invalid", p.PaymentAddress))
+		}
+		paymentInfo = append(paymentInfo, &privacy.PaymentInfo{
+			PaymentAddress: receiverKey.KeySet.PaymentAddress,
+			Amount:         p.Amount,
+			Message:        p.Message,
+		})
+	}
+	return paymentInfo, nil
+}
+
+func (utx *UnsignedTx) decodeInputCoins() ([]*privacy.InputCoin, error) {
+	inputCoins := make([]*privacy.InputCoin, 0, len(utx.InputCoins))
+	for _, coinStr := range utx.InputCoins {
+		coinBytes, _, err := base58.Base58Check{}.Decode(coinStr)
+		if err != nil {
+			return nil, NewTransactionErr(InvalidUnsignedTxError, err)
+		}
+		coin := new(privacy.InputCoin)
+		err = coin.SetBytes(coinBytes)
+		if err != nil || coin.CoinDetails.GetCoinCommitment() == nil || coin.CoinDetails.GetSNDerivator() == nil || coin.CoinDetails.GetRandomness() == nil {
+			return nil, NewTransactionErr(InvalidUnsignedTxError, fmt.Errorf("input coin %s is invalid", coinStr))
+		}
+		inputCoins = append(inputCoins, coin)
+	}
+	return inputCoins, nil
+}
+
+// countOutputs returns the number of output coins of a transfer, the payments and the change if any
+func countOutputs(paymentInfo []*privacy.PaymentInfo, inputCoins []*privacy.InputCoin, fee uint64) (int, error) {
+	sumOutputValue := fee
+	for _, p := range paymentInfo {
+		sumOutputValue += p.Amount
+	}
+	sumInputValue := uint64(0)
+	for _, coin := range inputCoins {
+		sumInputValue += coin.CoinDetails.GetValue()
+	}
+	if sumInputValue < sumOutputValue {
+		return 0, NewTransactionErr(WrongInputError, fmt.Errorf("input value less than output value. sumInputValue=%d sumOutputValue=%d fee=%d", sumInputValue, sumOutputValue-fee, fee))
+	}
+	if sumInputValue > sumOutputValue {
+		return len(paymentInfo) + 1, nil
+	}
+	return len(paymentInfo), nil
+}
+
+func base58CheckPaymentAddress(paymentAddress privacy.PaymentAddress) string {
+	key := wallet.KeyWallet{}
+	key.KeySet.PaymentAddress = paymentAddress
+	return key.Base58CheckSerialize(wallet.PaymentAddressType)
+}
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"github.com/incognitochain/incognito-chain/privacy"
+	"github.com/incognitochain/incognito-chain/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsignedTxSign(t *testing.T) {
+	for _, hasPrivacy := range []bool{false, true} {
+		seed := privacy.RandomScalar().ToBytesS()
+		masterKey, _ := wallet.NewMasterKey(seed)
+		sender, _ := masterKey.NewChildKey(uint32(1))
+		receiver, _ := masterKey.NewChildKey(uint32(2))
+		other, _ := masterKey.NewChildKey(uint32(3))
+		err := sender.KeySet.InitFromPrivateKey(&sender.KeySet.PrivateKey)
+		assert.Equal(t, nil, err)
+		senderPaymentAddress := sender.KeySet.PaymentAddress
+		shardID := common.GetShardIDFromLastByte(senderPaymentAddress.Pk[len(senderPaymentAddress.Pk)-1])
+
+		// mint coins, their commitments are the decoys of the ring too
+		inputCoins := []*privacy.InputCoin{}
+		for i := 0; i < privacy.CommitmentRingSize; i++ {
+			coinBaseTx, err := BuildCoinBaseTxByCoinID(NewBuildCoinBaseTxByCoinIDParams(&senderPaymentAddress, 1000, &sender.KeySet.PrivateKey, db, nil, common.Hash{}, NormalCoinType, "PRV", 0))
+			assert.Equal(t, nil, err)
+			outputCoins := coinBaseTx.(*Tx).Proof.GetOutputCoins()
+			err = db.StoreCommitments(common.PRVCoinID, senderPaymentAddress.Pk, [][]byte{outputCoins[0].CoinDetails.GetCoinCommitment().ToBytesS()}, shardID)
+			assert.Equal(t, nil, err)
+			if i < 2 {
+				// as decrypted with the viewing key: value and randomness but no serial number
+				inputCoins = append(inputCoins, ConvertOutputCoinToInputCoin(outputCoins)...)
+			}
+		}
+
+		paymentInfo := []*privacy.PaymentInfo{{PaymentAddress: receiver.KeySet.PaymentAddress, Amount: 1500}}
+		utx, err := NewUnsignedTx(senderPaymentAddress, paymentInfo, inputCoins, 10, hasPrivacy, db, []byte{})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 2, len(utx.SNDOutputs))
+		if hasPrivacy {
+			assert.Equal(t, 2*privacy.CommitmentRingSize, len(utx.Commitments))
+		}
+
+		// the template goes through a file to the signer
+		utxBytes, err := json.Marshal(utx)
+		assert.Equal(t, nil, err)
+		signer := new(UnsignedTx)
+		err = json.Unmarshal(utxBytes, signer)
+		assert.Equal(t, nil, err)
+
+		details, err := signer.Details()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, utx.SenderPaymentAddress, details.SenderPaymentAddress)
+		assert.Equal(t, utx.PaymentInfos, details.PaymentInfos)
+		assert.Equal(t, uint64(490), details.Change)
+		assert.Equal(t, uint64(10), details.Fee)
+
+		_, _, err = signer.Sign(&other.KeySet.PrivateKey)
+		assert.NotEqual(t, nil, err)
+
+		tx, serialNumbers, err := signer.Sign(&sender.KeySet.PrivateKey)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 2, len(serialNumbers))
+		assert.Equal(t, utx.LockTime, tx.LockTime)
+		assert.Equal(t, uint64(10), tx.GetTxFee())
+		assert.Equal(t, 2, len(tx.Proof.GetOutputCoins()))
+
+		isValidSanity, err := tx.ValidateSanityData(nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, isValidSanity)
+		isValid, err := tx.ValidateTransaction(hasPrivacy, db, shardID, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, isValid)
+	}
+}
+
+func TestUnsignedTxInsufficientInput(t *testing.T) {
+	seed := privacy.RandomScalar().ToBytesS()
+	masterKey, _ := wallet.NewMasterKey(seed)
+	sender, _ := masterKey.NewChildKey(uint32(1))
+	err := sender.KeySet.InitFromPrivateKey(&sender.KeySet.PrivateKey)
+	assert.Equal(t, nil, err)
+	senderPaymentAddress := sender.KeySet.PaymentAddress
+
+	coinBaseTx, err := BuildCoinBaseTxByCoinID(NewBuildCoinBaseTxByCoinIDParams(&senderPaymentAddress, 100, &sender.KeySet.PrivateKey, db, nil, common.Hash{}, NormalCoinType, "PRV", 0))
+	assert.Equal(t, nil, err)
+	inputCoins := ConvertOutputCoinToInputCoin(coinBaseTx.(*Tx).Proof.GetOutputCoins())
+
+	paymentInfo := []*privacy.PaymentInfo{{PaymentAddress: senderPaymentAddress, Amount: 100}}
+	_, err = NewUnsignedTx(senderPaymentAddress, paymentInfo, inputCoins, 10, false, db, nil)
+	assert.Equal(t, ErrCodeMessage[WrongInputError].Code, err.(*TransactionError).Code)
+}
@@ -5,4 +5,5 @@ type KeySerializedData struct {
 	PaymentAddress string `json:"PaymentAddress"`
 	Pubkey         string `json:"Pubkey"` // in hex encode string
 	ReadonlyKey    string `json:"ReadonlyKey"`
+	ViewingKey     string `json:"ViewingKey"` // payment address and readonly key, shares the coins and amounts of the account without the right to spend
 }
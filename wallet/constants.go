@@ -7,17 +7,29 @@ const (
 
 	privateKeySerializedLen = 108 // len string
 
-	privKeySerializedBytesLen     = 75 // bytes
-	paymentAddrSerializedBytesLen = 71 // bytes
-	readOnlyKeySerializedBytesLen = 71 // bytes
+	privKeySerializedBytesLen     = 75  // bytes
+	paymentAddrSerializedBytesLen = 71  // bytes
+	readOnlyKeySerializedBytesLen = 71  // bytes
+	viewingKeySerializedBytesLen  = 104 // bytes
 
 	privKeyBase58CheckSerializedBytesLen     = 107 // len string
 	paymentAddrBase58CheckSerializedBytesLen = 103 // len string
 	readOnlyKeyBase58CheckSerializedBytesLen = 103 // len string
+	viewingKeyBase58CheckSerializedBytesLen  = 148 // len string
 )
 
 const (
 	PriKeyType         = byte(0x0) // Serialize wallet account key into string with only PRIVATE KEY of account keyset
 	PaymentAddressType = byte(0x1) // Serialize wallet account key into string with only PAYMENT ADDRESS of account keyset
 	ReadonlyKeyType    = byte(0x2) // Serialize wallet account key into string with only READONLY KEY of account keyset
+	ViewingKeyType     = byte(0x3) // Serialize wallet account key into string with PAYMENT ADDRESS and READONLY KEY of account keyset, it sees coins and amounts but cannot spend
+)
+
+const (
+	keystoreVersion     = 1
+	keystoreFileExt     = ".json"
+	keystoreKDFName     = "scrypt"
+	keystoreDKLen       = 64  // bytes, an AES key then a MAC key
+	keystoreSaltLen     = 32  // bytes
+	keystoreWholeWallet = "*" // unlock scope of the whole wallet, like the "*" account of getbalance
 )
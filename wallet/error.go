@@ -26,6 +26,12 @@ const (
 	NewMnemonicError
 	MnemonicInvalidError
 	InvalidSeserializedKey
+	InvalidViewingKeyErr
+	WalletLockedErr
+	InvalidKeystoreErr
+	InvalidWalletNameErr
+	ExistedWalletErr
+	NotFoundWalletErr
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -53,6 +59,12 @@ var ErrCodeMessage = map[int]struct {
 	NewMnemonicError:      {-1015, "Can not create mnemonic"},
 	MnemonicInvalidError:  {-1016, "Mnemonic is invalid"},
 	InvalidSeserializedKey:  {-1016, "Serialized key is invalid"},
+	InvalidViewingKeyErr:  {-1017, "Viewing key is invalid"},
+	WalletLockedErr:       {-1018, "Wallet is locked"},
+	InvalidKeystoreErr:    {-1019, "Keystore file is invalid"},
+	InvalidWalletNameErr:  {-1020, "Wallet name is invalid"},
+	ExistedWalletErr:      {-1021, "Existed wallet"},
+	NotFoundWalletErr:     {-1022, "Wallet is not found"},
 }
 
 type WalletError struct {
@@ -88,6 +88,12 @@ func (key *KeyWallet) getIntermediary(childIdx uint32) ([]byte, error) {
 	return hmacObj.Sum(nil), nil
 }
 
+// IsWatchOnly returns true when the key has no private key, like the keys made from a viewing key:
+// it can see the coins of its payment address but cannot spend them
+func (key *KeyWallet) IsWatchOnly() bool {
+	return len(key.KeySet.PrivateKey) == 0
+}
+
 // Serialize receives keyType and serializes key which has keyType to bytes array
 // and append 4-byte checksum into bytes array
 func (key *KeyWallet) Serialize(keyType byte) ([]byte, error) {
@@ -95,6 +101,10 @@ func (key *KeyWallet) Serialize(keyType byte) ([]byte, error) {
 	buffer := new(bytes.Buffer)
 	buffer.WriteByte(keyType)
 	if keyType == PriKeyType {
+		if len(key.KeySet.PrivateKey) == 0 {
+			// a watch-only key has no private key to serialize
+			return []byte{}, NewWalletError(InvalidKeyTypeErr, nil)
+		}
 		buffer.WriteByte(key.Depth)
 		buffer.Write(key.ChildNumber)
 		buffer.Write(key.ChainCode)
@@ -120,6 +130,20 @@ func (key *KeyWallet) Serialize(keyType byte) ([]byte, error) {
 		keyBytes = append(keyBytes, byte(len(key.KeySet.ReadonlyKey.Rk))) // set length Skenc
 		keyBytes = append(keyBytes, key.KeySet.ReadonlyKey.Rk[:]...)      // set Pkenc
 		buffer.Write(keyBytes)
+	} else if keyType == ViewingKeyType {
+		if len(key.KeySet.PaymentAddress.Pk) == 0 || len(key.KeySet.ReadonlyKey.Rk) == 0 {
+			return []byte{}, NewWalletError(InvalidKeyTypeErr, nil)
+		}
+		keyBytes := make([]byte, 0)
+		keyBytes = append(keyBytes, byte(len(key.KeySet.PaymentAddress.Pk))) // set length PaymentAddress
+		keyBytes = append(keyBytes, key.KeySet.PaymentAddress.Pk[:]...)      // set PaymentAddress
+
+		keyBytes = append(keyBytes, byte(len(key.KeySet.PaymentAddress.Tk))) // set length Pkenc
+		keyBytes = append(keyBytes, key.KeySet.PaymentAddress.Tk[:]...)      // set Pkenc
+
+		keyBytes = append(keyBytes, byte(len(key.KeySet.ReadonlyKey.Rk))) // set length Skenc
+		keyBytes = append(keyBytes, key.KeySet.ReadonlyKey.Rk[:]...)      // set Skenc
+		buffer.Write(keyBytes)
 	} else {
 		return []byte{}, NewWalletError(InvalidKeyTypeErr, nil)
 	}
@@ -197,6 +221,31 @@ func deserialize(data []byte) (*KeyWallet, error) {
 		key.KeySet.ReadonlyKey.Rk = make([]byte, skencKeyLength)
 		copy(key.KeySet.ReadonlyKey.Pk[:], data[2:2+apkKeyLength])
 		copy(key.KeySet.ReadonlyKey.Rk[:], data[3+apkKeyLength:3+apkKeyLength+skencKeyLength])
+	} else if keyType == ViewingKeyType {
+		if len(data) != viewingKeySerializedBytesLen {
+			return nil, NewWalletError(InvalidSeserializedKey, nil)
+		}
+
+		apkKeyLength := int(data[1])
+		if len(data) < apkKeyLength+3 {
+			return nil, NewWalletError(InvalidKeyTypeErr, nil)
+		}
+		pkencKeyLength := int(data[apkKeyLength+2])
+		if len(data) < apkKeyLength+pkencKeyLength+4 {
+			return nil, NewWalletError(InvalidKeyTypeErr, nil)
+		}
+		skencKeyLength := int(data[apkKeyLength+pkencKeyLength+3])
+		if len(data) < apkKeyLength+pkencKeyLength+skencKeyLength+4 {
+			return nil, NewWalletError(InvalidKeyTypeErr, nil)
+		}
+		key.KeySet.PaymentAddress.Pk = make([]byte, apkKeyLength)
+		key.KeySet.PaymentAddress.Tk = make([]byte, pkencKeyLength)
+		key.KeySet.ReadonlyKey.Rk = make([]byte, skencKeyLength)
+		copy(key.KeySet.PaymentAddress.Pk[:], data[2:2+apkKeyLength])
+		copy(key.KeySet.PaymentAddress.Tk[:], data[3+apkKeyLength:3+apkKeyLength+pkencKeyLength])
+		copy(key.KeySet.ReadonlyKey.Rk[:], data[4+apkKeyLength+pkencKeyLength:4+apkKeyLength+pkencKeyLength+skencKeyLength])
+		// the read-only key decrypts the coins of its payment address only
+		key.KeySet.ReadonlyKey.Pk = key.KeySet.PaymentAddress.Pk
 	}
 
 	// validate checksum
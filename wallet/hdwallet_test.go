@@ -144,7 +144,7 @@ func TestHDWalletSerializeWithInvalidKeyType(t *testing.T) {
 	data := []struct {
 		keyType byte
 	}{
-		{byte(3)},
+		{byte(4)},
 		{byte(10)},
 		{byte(123)},
 		{byte(234)},
@@ -187,7 +187,7 @@ func TestHDWalletBase58CheckSerializeWithInvalidKeyType(t *testing.T) {
 	data := []struct {
 		keyType byte
 	}{
-		{byte(3)},
+		{byte(4)},
 		{byte(10)},
 		{byte(123)},
 		{byte(234)},
@@ -306,6 +306,45 @@ func TestHDWalletBase58CheckDeserializeWithInvalidData(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 }
 
+/*
+	Unit test for viewing keys
+*/
+
+func TestHDWalletViewingKey(t *testing.T) {
+	seed := []byte{1, 2, 3}
+	masterKey, _ := NewMasterKey(seed)
+
+	viewingKeyBytes, err := masterKey.Serialize(ViewingKeyType)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, viewingKeySerializedBytesLen, len(viewingKeyBytes))
+	viewingKeyStr := masterKey.Base58CheckSerialize(ViewingKeyType)
+	assert.Equal(t, viewingKeyBase58CheckSerializedBytesLen, len(viewingKeyStr))
+
+	keyWallet, err := Base58CheckDeserialize(viewingKeyStr)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, masterKey.KeySet.PaymentAddress.Pk, keyWallet.KeySet.PaymentAddress.Pk)
+	assert.Equal(t, masterKey.KeySet.PaymentAddress.Tk, keyWallet.KeySet.PaymentAddress.Tk)
+	assert.Equal(t, masterKey.KeySet.ReadonlyKey.Pk, keyWallet.KeySet.ReadonlyKey.Pk)
+	assert.Equal(t, masterKey.KeySet.ReadonlyKey.Rk, keyWallet.KeySet.ReadonlyKey.Rk)
+	assert.Equal(t, 0, len(keyWallet.KeySet.PrivateKey))
+	assert.True(t, keyWallet.IsWatchOnly())
+
+	// a viewing key can be shared again, but never gives the private key
+	assert.Equal(t, viewingKeyStr, keyWallet.Base58CheckSerialize(ViewingKeyType))
+	assert.Equal(t, masterKey.Base58CheckSerialize(PaymentAddressType), keyWallet.Base58CheckSerialize(PaymentAddressType))
+	assert.Equal(t, masterKey.Base58CheckSerialize(ReadonlyKeyType), keyWallet.Base58CheckSerialize(ReadonlyKeyType))
+	assert.Equal(t, "", keyWallet.Base58CheckSerialize(PriKeyType))
+
+	// a payment address alone cannot make a viewing key
+	paymentAddress, err := Base58CheckDeserialize(masterKey.Base58CheckSerialize(PaymentAddressType))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", paymentAddress.Base58CheckSerialize(ViewingKeyType))
+
+	viewingKeyBytes[len(viewingKeyBytes)-1] = 0
+	_, err = deserialize(viewingKeyBytes)
+	assert.Equal(t, NewWalletError(InvalidChecksumErr, nil), err)
+}
+
 func TestPrivateKeyToPaymentAddress(t *testing.T){
 	//Todo: need to fill private key
 	privateKeyStr := ""
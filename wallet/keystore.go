@@ -0,0 +1,707 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/incognito-chain/common"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptParams are the cost parameters of the scrypt key derivation,
+// they are stored in every keystore file so a file keeps opening after the defaults change
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+var (
+	// StandardScryptParams is used by a node for the wallets it creates
+	StandardScryptParams = ScryptParams{N: 1 << 15, R: 8, P: 1}
+	// LightScryptParams takes far less memory and time, it is meant for tests
+	LightScryptParams = ScryptParams{N: 1 << 10, R: 8, P: 1}
+)
+
+type keystoreKDF struct {
+	Name  string
+	N     int
+	R     int
+	P     int
+	DKLen int
+	Salt  string // in hex encode string
+}
+
+// keystoreAccount is the part of an account which is kept in plaintext,
+// it lets a locked wallet list its accounts and watch their coins
+type keystoreAccount struct {
+	Name        string
+	ViewingKey  string
+	IsImported  bool
+	ChildNumber string // in hex encode string
+}
+
+// keystoreFile is the json layout of a wallet file in the keystore
+// CipherText is the AES encryption of the whole wallet (seed, mnemonic and every private key),
+// MAC authenticates it and tells a wrong passphrase apart from a corrupted file
+type keystoreFile struct {
+	Version    int
+	Name       string
+	KDF        keystoreKDF
+	CipherText string
+	MAC        string
+	Accounts   []keystoreAccount
+}
+
+// keystoreWallet is a wallet of the keystore
+// wallet is the view handed out to callers, it only holds the keys of the unlocked scopes
+// key is the key derived from the passphrase, it is kept while any scope is unlocked so the wallet can be saved
+// unlocked maps a scope (an account name or keystoreWholeWallet) to the timer which locks it again
+type keystoreWallet struct {
+	path     string
+	file     keystoreFile
+	wallet   *Wallet
+	key      []byte
+	unlocked map[string]*time.Timer
+}
+
+// KeystoreWalletStatus describes a wallet of the keystore and which parts of it are unlocked
+type KeystoreWalletStatus struct {
+	Name             string
+	Accounts         int
+	Unlocked         bool
+	UnlockedAccounts []string
+}
+
+// Keystore keeps many named wallets encrypted on disk, one file per wallet
+// A wallet is locked when it is loaded: its accounts can be watched but not spent
+// until Unlock decrypts the whole wallet or a single account for a while
+type Keystore struct {
+	dir     string
+	params  ScryptParams
+	mtx     sync.Mutex
+	wallets map[string]*keystoreWallet
+}
+
+// NewKeystore opens the keystore in dir, creating the directory if needed, and loads its wallets locked
+// params are used for the wallets created from now on
+func NewKeystore(dir string, params ScryptParams) (*Keystore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, NewWalletError(WriteFileErr, err)
+	}
+	ks := &Keystore{
+		dir:     dir,
+		params:  params,
+		wallets: make(map[string]*keystoreWallet),
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, NewWalletError(ReadFileErr, err)
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != keystoreFileExt {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, NewWalletError(ReadFileErr, err)
+		}
+		entry := &keystoreWallet{
+			path:     path,
+			unlocked: make(map[string]*time.Timer),
+		}
+		err = json.Unmarshal(data, &entry.file)
+		if err != nil {
+			return nil, NewWalletError(JsonUnmarshalErr, err)
+		}
+		if entry.file.Version != keystoreVersion || entry.file.KDF.Name != keystoreKDFName {
+			return nil, NewWalletError(InvalidKeystoreErr, nil)
+		}
+		entry.wallet, err = ks.newLockedWallet(entry)
+		if err != nil {
+			return nil, err
+		}
+		ks.wallets[entry.file.Name] = entry
+	}
+	return ks, nil
+}
+
+// CreateWallet creates a new wallet with numOfAccount accounts, encrypted with passPhrase
+// passPhrase is also used to generate the seed, like Wallet.Init does
+// It returns the wallet which is locked
+func (ks *Keystore) CreateWallet(name string, passPhrase string, numOfAccount uint32) (*Wallet, error) {
+	full := &Wallet{}
+	err := full.Init(passPhrase, numOfAccount, name)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroWallet(full)
+	return ks.addWallet(name, passPhrase, full)
+}
+
+// ImportLegacyWallet moves the single file wallet at legacyPath into the keystore with name
+// The legacy file is left untouched, it returns the wallet which is locked
+func (ks *Keystore) ImportLegacyWallet(name string, legacyPath string, passPhrase string) (*Wallet, error) {
+	full := &Wallet{}
+	full.SetConfig(&WalletConfig{DataPath: legacyPath})
+	err := full.LoadWallet(passPhrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroWallet(full)
+	if full.PassPhrase != passPhrase {
+		return nil, NewWalletError(WrongPassphraseErr, nil)
+	}
+	full.Name = name
+	return ks.addWallet(name, passPhrase, full)
+}
+
+func (ks *Keystore) addWallet(name string, passPhrase string, full *Wallet) (*Wallet, error) {
+	if name == "" {
+		return nil, NewWalletError(EmptyWalletNameErr, nil)
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, NewWalletError(InvalidWalletNameErr, nil)
+	}
+
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if _, ok := ks.wallets[name]; ok {
+		return nil, NewWalletError(ExistedWalletErr, nil)
+	}
+
+	salt := make([]byte, keystoreSaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, NewWalletError(UnexpectedErr, err)
+	}
+	entry := &keystoreWallet{
+		path: filepath.Join(ks.dir, name+keystoreFileExt),
+		file: keystoreFile{
+			Version: keystoreVersion,
+			Name:    name,
+			KDF: keystoreKDF{
+				Name:  keystoreKDFName,
+				N:     ks.params.N,
+				R:     ks.params.R,
+				P:     ks.params.P,
+				DKLen: keystoreDKLen,
+				Salt:  hex.EncodeToString(salt),
+			},
+		},
+		unlocked: make(map[string]*time.Timer),
+	}
+	key, err := deriveKeystoreKey(entry.file.KDF, passPhrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key)
+	err = entry.seal(key, full)
+	if err != nil {
+		return nil, err
+	}
+	entry.wallet, err = ks.newLockedWallet(entry)
+	if err != nil {
+		return nil, err
+	}
+	ks.wallets[name] = entry
+	return entry.wallet, nil
+}
+
+// Wallet returns the wallet named name, or nil when the keystore does not have it
+func (ks *Keystore) Wallet(name string) *Wallet {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[name]
+	if !ok {
+		return nil
+	}
+	return entry.wallet
+}
+
+// Wallets returns the status of every wallet of the keystore sorted by name
+func (ks *Keystore) Wallets() []KeystoreWalletStatus {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	result := make([]KeystoreWalletStatus, 0, len(ks.wallets))
+	for _, entry := range ks.wallets {
+		result = append(result, entry.status())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Status returns the status of the wallet named name
+func (ks *Keystore) Status(name string) (KeystoreWalletStatus, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[name]
+	if !ok {
+		return KeystoreWalletStatus{}, NewWalletError(NotFoundWalletErr, nil)
+	}
+	return entry.status(), nil
+}
+
+// Unlock decrypts the wallet named name with passPhrase and keeps its keys in memory for timeout
+// If accountName is empty, the whole wallet is unlocked: seed, master key and every account,
+// otherwise only the private key of that account is
+// Unlocking a scope again replaces its timeout, a zero timeout keeps it unlocked until Lock is called
+func (ks *Keystore) Unlock(name string, passPhrase string, accountName string, timeout time.Duration) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[name]
+	if !ok {
+		return NewWalletError(NotFoundWalletErr, nil)
+	}
+
+	key, err := deriveKeystoreKey(entry.file.KDF, passPhrase)
+	if err != nil {
+		return err
+	}
+	full, err := entry.open(key)
+	if err != nil {
+		zeroBytes(key)
+		return err
+	}
+	defer zeroWallet(full)
+
+	scope := keystoreWholeWallet
+	view := entry.wallet
+	if accountName == "" {
+		view.Seed = copyBytes(full.Seed)
+		view.Entropy = copyBytes(full.Entropy)
+		view.Mnemonic = full.Mnemonic
+		view.MasterAccount.Key = cloneKeyWallet(full.MasterAccount.Key)
+		for i := range view.MasterAccount.Child {
+			if fullAccount := findAccount(full, &view.MasterAccount.Child[i].Key); fullAccount != nil {
+				view.MasterAccount.Child[i].Key = cloneKeyWallet(fullAccount.Key)
+			}
+		}
+	} else {
+		found := false
+		for i := range view.MasterAccount.Child {
+			if view.MasterAccount.Child[i].Name != accountName {
+				continue
+			}
+			if fullAccount := findAccount(full, &view.MasterAccount.Child[i].Key); fullAccount != nil {
+				view.MasterAccount.Child[i].Key = cloneKeyWallet(fullAccount.Key)
+				found = true
+			}
+			break
+		}
+		if !found {
+			zeroBytes(key)
+			return NewWalletError(NotFoundAccountErr, nil)
+		}
+		scope = accountName
+	}
+
+	zeroBytes(entry.key)
+	entry.key = key
+	if timer := entry.unlocked[scope]; timer != nil {
+		timer.Stop()
+	}
+	entry.unlocked[scope] = nil
+	if timeout > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			ks.mtx.Lock()
+			defer ks.mtx.Unlock()
+			// the scope may have been unlocked again or locked since this timer was set
+			if current, ok := entry.unlocked[scope]; ok && current == timer {
+				entry.lockScope(scope)
+			}
+		})
+		entry.unlocked[scope] = timer
+	}
+	Logger.log.Infof("Wallet %s unlocked, scope %s, timeout %s", name, scope, timeout)
+	return nil
+}
+
+// Lock locks every scope of the wallet named name and zeroes its key material
+func (ks *Keystore) Lock(name string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[name]
+	if !ok {
+		return NewWalletError(NotFoundWalletErr, nil)
+	}
+	entry.lockAll()
+	return nil
+}
+
+// LockAll locks every wallet of the keystore, it is called when the node stops
+func (ks *Keystore) LockAll() {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	for _, entry := range ks.wallets {
+		entry.lockAll()
+	}
+}
+
+// checkPassPhrase returns nil if passPhrase opens the wallet named name
+func (ks *Keystore) checkPassPhrase(name string, passPhrase string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[name]
+	if !ok {
+		return NewWalletError(NotFoundWalletErr, nil)
+	}
+	key, err := deriveKeystoreKey(entry.file.KDF, passPhrase)
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(key)
+	return entry.verify(key)
+}
+
+// save writes the accounts of view into its keystore file
+// The secrets which are locked in view are taken from the file, so saving a partly locked wallet loses nothing
+// passPhrase may be empty while any scope of the wallet is unlocked
+func (ks *Keystore) save(view *Wallet, passPhrase string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	entry, ok := ks.wallets[view.Name]
+	if !ok || entry.wallet != view {
+		return NewWalletError(NotFoundWalletErr, nil)
+	}
+
+	var key []byte
+	if passPhrase != "" {
+		derived, err := deriveKeystoreKey(entry.file.KDF, passPhrase)
+		if err != nil {
+			return err
+		}
+		defer zeroBytes(derived)
+		key = derived
+	} else if entry.key != nil {
+		key = entry.key
+	} else {
+		return NewWalletError(WalletLockedErr, nil)
+	}
+
+	full, err := entry.open(key)
+	if err != nil {
+		return err
+	}
+	defer zeroWallet(full)
+
+	merged := &Wallet{
+		Seed:          copyBytes(full.Seed),
+		Entropy:       copyBytes(full.Entropy),
+		Mnemonic:      full.Mnemonic,
+		Name:          full.Name,
+		MasterAccount: full.MasterAccount,
+	}
+	merged.MasterAccount.Key = cloneKeyWallet(full.MasterAccount.Key)
+	merged.MasterAccount.Child = make([]AccountWallet, 0, len(view.MasterAccount.Child))
+	defer zeroWallet(merged)
+	for _, account := range view.MasterAccount.Child {
+		accountKey := account.Key
+		if accountKey.IsWatchOnly() {
+			if fullAccount := findAccount(full, &account.Key); fullAccount != nil {
+				accountKey = fullAccount.Key
+			}
+		}
+		merged.MasterAccount.Child = append(merged.MasterAccount.Child, AccountWallet{
+			Name:       account.Name,
+			Key:        cloneKeyWallet(accountKey),
+			Child:      make([]AccountWallet, 0),
+			IsImported: account.IsImported,
+		})
+	}
+	err = entry.seal(key, merged)
+	if err != nil {
+		return err
+	}
+
+	// an account imported with the passphrase into a locked wallet stays locked
+	if _, ok := entry.unlocked[keystoreWholeWallet]; !ok {
+		for i := range view.MasterAccount.Child {
+			if _, ok := entry.unlocked[view.MasterAccount.Child[i].Name]; !ok {
+				zeroKeyWallet(&view.MasterAccount.Child[i].Key)
+			}
+		}
+	}
+	return nil
+}
+
+func (ks *Keystore) newLockedWallet(entry *keystoreWallet) (*Wallet, error) {
+	view := &Wallet{
+		Name: entry.file.Name,
+		MasterAccount: AccountWallet{
+			Child: make([]AccountWallet, 0, len(entry.file.Accounts)),
+			Name:  "master",
+		},
+		keystore: ks,
+	}
+	view.SetConfig(&WalletConfig{
+		DataDir:  ks.dir,
+		DataFile: filepath.Base(entry.path),
+		DataPath: entry.path,
+	})
+	for _, account := range entry.file.Accounts {
+		key, err := Base58CheckDeserialize(account.ViewingKey)
+		if err != nil {
+			return nil, NewWalletError(InvalidKeystoreErr, err)
+		}
+		key.ChildNumber, err = hex.DecodeString(account.ChildNumber)
+		if err != nil {
+			return nil, NewWalletError(InvalidKeystoreErr, err)
+		}
+		key.ChainCode = make([]byte, chainCodeLen)
+		view.MasterAccount.Child = append(view.MasterAccount.Child, AccountWallet{
+			Name:       account.Name,
+			Key:        *key,
+			Child:      make([]AccountWallet, 0),
+			IsImported: account.IsImported,
+		})
+	}
+	return view, nil
+}
+
+func (entry *keystoreWallet) status() KeystoreWalletStatus {
+	status := KeystoreWalletStatus{
+		Name:             entry.file.Name,
+		Accounts:         len(entry.file.Accounts),
+		UnlockedAccounts: make([]string, 0),
+	}
+	for scope := range entry.unlocked {
+		if scope == keystoreWholeWallet {
+			status.Unlocked = true
+		} else {
+			status.UnlockedAccounts = append(status.UnlockedAccounts, scope)
+		}
+	}
+	sort.Strings(status.UnlockedAccounts)
+	return status
+}
+
+// lockScope locks one scope, the keys which are still covered by another unlocked scope are kept
+func (entry *keystoreWallet) lockScope(scope string) {
+	if timer := entry.unlocked[scope]; timer != nil {
+		timer.Stop()
+	}
+	delete(entry.unlocked, scope)
+	if len(entry.unlocked) == 0 {
+		entry.lockAll()
+		return
+	}
+
+	view := entry.wallet
+	if scope == keystoreWholeWallet {
+		zeroBytes(view.Seed)
+		zeroBytes(view.Entropy)
+		view.Seed, view.Entropy, view.Mnemonic = nil, nil, ""
+		zeroKeyWallet(&view.MasterAccount.Key)
+		for i := range view.MasterAccount.Child {
+			if _, ok := entry.unlocked[view.MasterAccount.Child[i].Name]; !ok {
+				zeroKeyWallet(&view.MasterAccount.Child[i].Key)
+			}
+		}
+		return
+	}
+	if _, ok := entry.unlocked[keystoreWholeWallet]; ok {
+		return
+	}
+	for i := range view.MasterAccount.Child {
+		if view.MasterAccount.Child[i].Name == scope {
+			zeroKeyWallet(&view.MasterAccount.Child[i].Key)
+		}
+	}
+}
+
+// lockAll zeroes every key of the wallet, the derived key included
+func (entry *keystoreWallet) lockAll() {
+	for scope, timer := range entry.unlocked {
+		if timer != nil {
+			timer.Stop()
+		}
+		delete(entry.unlocked, scope)
+	}
+	zeroBytes(entry.key)
+	entry.key = nil
+	if entry.wallet != nil {
+		zeroWallet(entry.wallet)
+	}
+}
+
+// verify checks the MAC of the encrypted wallet with key
+func (entry *keystoreWallet) verify(key []byte) error {
+	cipherText, err := hex.DecodeString(entry.file.CipherText)
+	if err != nil {
+		return NewWalletError(InvalidKeystoreErr, err)
+	}
+	mac, err := hex.DecodeString(entry.file.MAC)
+	if err != nil {
+		return NewWalletError(InvalidKeystoreErr, err)
+	}
+	if !hmac.Equal(mac, keystoreMAC(key, cipherText)) {
+		return NewWalletError(WrongPassphraseErr, nil)
+	}
+	return nil
+}
+
+// open decrypts the whole wallet with key, the caller zeroes it with zeroWallet when it is done
+func (entry *keystoreWallet) open(key []byte) (*Wallet, error) {
+	err := entry.verify(key)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, _ := hex.DecodeString(entry.file.CipherText)
+	aes := common.AES{
+		Key: key[:common.AESKeySize],
+	}
+	plainText, err := aes.Decrypt(cipherText)
+	if err != nil {
+		return nil, NewWalletError(AESDecryptErr, err)
+	}
+	defer zeroBytes(plainText)
+	full := &Wallet{}
+	err = json.Unmarshal(plainText, full)
+	if err != nil {
+		return nil, NewWalletError(JsonUnmarshalErr, err)
+	}
+	return full, nil
+}
+
+// seal encrypts full with key and writes the keystore file
+func (entry *keystoreWallet) seal(key []byte, full *Wallet) error {
+	payload := *full
+	payload.PassPhrase = ""
+	plainText, err := json.Marshal(payload)
+	if err != nil {
+		return NewWalletError(JsonMarshalErr, err)
+	}
+	defer zeroBytes(plainText)
+	aes := common.AES{
+		Key: key[:common.AESKeySize],
+	}
+	cipherText, err := aes.Encrypt(plainText)
+	if err != nil {
+		return NewWalletError(AESEncryptErr, err)
+	}
+
+	file := entry.file
+	file.CipherText = hex.EncodeToString(cipherText)
+	file.MAC = hex.EncodeToString(keystoreMAC(key, cipherText))
+	file.Accounts = make([]keystoreAccount, 0, len(full.MasterAccount.Child))
+	for _, account := range full.MasterAccount.Child {
+		file.Accounts = append(file.Accounts, keystoreAccount{
+			Name:        account.Name,
+			ViewingKey:  account.Key.Base58CheckSerialize(ViewingKeyType),
+			IsImported:  account.IsImported,
+			ChildNumber: hex.EncodeToString(account.Key.ChildNumber),
+		})
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return NewWalletError(JsonMarshalErr, err)
+	}
+
+	// write a temporary file then rename it, a crash never leaves a half written wallet
+	tmpPath := entry.path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return NewWalletError(WriteFileErr, err)
+	}
+	err = os.Rename(tmpPath, entry.path)
+	if err != nil {
+		return NewWalletError(WriteFileErr, err)
+	}
+	entry.file = file
+	return nil
+}
+
+// deriveKeystoreKey derives the key of a wallet from passPhrase with the scrypt parameters of its file
+// the first AESKeySize bytes are the AES key, the rest is the MAC key
+func deriveKeystoreKey(kdf keystoreKDF, passPhrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(kdf.Salt)
+	if err != nil {
+		return nil, NewWalletError(InvalidKeystoreErr, err)
+	}
+	if kdf.DKLen <= common.AESKeySize {
+		return nil, NewWalletError(InvalidKeystoreErr, nil)
+	}
+	key, err := scrypt.Key([]byte(passPhrase), salt, kdf.N, kdf.R, kdf.P, kdf.DKLen)
+	if err != nil {
+		return nil, NewWalletError(InvalidKeystoreErr, err)
+	}
+	return key, nil
+}
+
+func keystoreMAC(key []byte, cipherText []byte) []byte {
+	mac := hmac.New(sha256.New, key[common.AESKeySize:])
+	mac.Write(cipherText)
+	return mac.Sum(nil)
+}
+
+// findAccount returns the account of full which has the same payment address as key
+func findAccount(full *Wallet, key *KeyWallet) *AccountWallet {
+	for i := range full.MasterAccount.Child {
+		if bytes.Equal(full.MasterAccount.Child[i].Key.KeySet.PaymentAddress.Pk, key.KeySet.PaymentAddress.Pk) {
+			return &full.MasterAccount.Child[i]
+		}
+	}
+	return nil
+}
+
+func cloneKeyWallet(key KeyWallet) KeyWallet {
+	clone := key
+	clone.ChildNumber = copyBytes(key.ChildNumber)
+	clone.ChainCode = copyBytes(key.ChainCode)
+	clone.KeySet.PrivateKey = copyBytes(key.KeySet.PrivateKey)
+	clone.KeySet.PaymentAddress.Pk = copyBytes(key.KeySet.PaymentAddress.Pk)
+	clone.KeySet.PaymentAddress.Tk = copyBytes(key.KeySet.PaymentAddress.Tk)
+	clone.KeySet.ReadonlyKey.Pk = copyBytes(key.KeySet.ReadonlyKey.Pk)
+	clone.KeySet.ReadonlyKey.Rk = copyBytes(key.KeySet.ReadonlyKey.Rk)
+	return clone
+}
+
+// zeroKeyWallet drops the private key and chain code of key, it keeps the payment address and readonly key
+func zeroKeyWallet(key *KeyWallet) {
+	zeroBytes(key.KeySet.PrivateKey)
+	zeroBytes(key.ChainCode)
+	key.KeySet.PrivateKey = nil
+	key.ChainCode = make([]byte, chainCodeLen)
+}
+
+// zeroWallet drops every secret of wallet, what is left is a watch-only wallet
+func zeroWallet(wallet *Wallet) {
+	zeroBytes(wallet.Seed)
+	zeroBytes(wallet.Entropy)
+	wallet.Seed, wallet.Entropy = nil, nil
+	wallet.Mnemonic, wallet.PassPhrase = "", ""
+	zeroKeyWallet(&wallet.MasterAccount.Key)
+	for i := range wallet.MasterAccount.Child {
+		zeroKeyWallet(&wallet.MasterAccount.Child[i].Key)
+	}
+}
+
+func zeroBytes(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte{}, data...)
+}
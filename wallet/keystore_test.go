@@ -0,0 +1,218 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeystore(t *testing.T) (*Keystore, string) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeystore(dir, LightScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks, dir
+}
+
+func TestKeystoreCreateWalletIsLocked(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 2)
+	assert.Nil(t, err)
+	assert.True(t, w.IsLocked())
+	assert.Equal(t, 2, len(w.MasterAccount.Child))
+	for _, account := range w.MasterAccount.Child {
+		assert.True(t, account.Key.IsWatchOnly())
+		assert.NotEmpty(t, account.Key.Base58CheckSerialize(PaymentAddressType))
+	}
+	assert.Empty(t, w.Seed)
+	assert.Empty(t, w.Mnemonic)
+
+	info, err := os.Stat(filepath.Join(dir, "alice.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = ks.CreateWallet("alice", "123", 1)
+	assert.Equal(t, ErrCodeMessage[ExistedWalletErr].code, err.(*WalletError).GetCode())
+	_, err = ks.CreateWallet("../alice", "123", 1)
+	assert.Equal(t, ErrCodeMessage[InvalidWalletNameErr].code, err.(*WalletError).GetCode())
+}
+
+func TestKeystoreUnlockAndLock(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 2)
+	assert.Nil(t, err)
+
+	err = ks.Unlock("alice", "wrong", "", 0)
+	assert.Equal(t, ErrCodeMessage[WrongPassphraseErr].code, err.(*WalletError).GetCode())
+	assert.True(t, w.IsLocked())
+
+	err = ks.Unlock("alice", "123", "", 0)
+	assert.Nil(t, err)
+	assert.False(t, w.IsLocked())
+	assert.NotEmpty(t, w.Seed)
+	privateKey := w.MasterAccount.Child[0].Key.KeySet.PrivateKey
+	assert.NotEmpty(t, w.ExportAccount(0))
+	status, err := ks.Status("alice")
+	assert.Nil(t, err)
+	assert.True(t, status.Unlocked)
+
+	err = ks.Lock("alice")
+	assert.Nil(t, err)
+	assert.True(t, w.IsLocked())
+	assert.Empty(t, w.ExportAccount(0))
+	assert.Empty(t, w.Seed)
+	// the bytes the wallet held are zeroed, not only dropped
+	assert.Equal(t, make([]byte, len(privateKey)), []byte(privateKey))
+	status, err = ks.Status("alice")
+	assert.Nil(t, err)
+	assert.False(t, status.Unlocked)
+}
+
+func TestKeystoreAccountsAreCopies(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 2)
+	assert.Nil(t, err)
+	err = ks.Unlock("alice", "123", "", 0)
+	assert.Nil(t, err)
+	accounts := w.Accounts()
+	listed := w.ListAccounts()
+	privateKey := append([]byte{}, w.MasterAccount.Child[0].Key.KeySet.PrivateKey...)
+
+	// locking zeroes the keys of the wallet, not the ones already handed out
+	done := make(chan struct{})
+	go func() {
+		ks.LockAll()
+		close(done)
+	}()
+	for _, account := range w.Accounts() {
+		_ = account.Key.Base58CheckSerialize(PriKeyType)
+	}
+	<-done
+	assert.True(t, w.IsLocked())
+	assert.Equal(t, 2, len(accounts))
+	assert.Equal(t, privateKey, []byte(accounts[0].Key.KeySet.PrivateKey))
+	assert.Equal(t, privateKey, []byte(listed["AccountWallet 0"].Key.KeySet.PrivateKey))
+	second := listed["AccountWallet 1"]
+	assert.False(t, second.Key.IsWatchOnly())
+}
+
+func TestKeystoreUnlockAccount(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 2)
+	assert.Nil(t, err)
+
+	err = ks.Unlock("alice", "123", "not existed", 0)
+	assert.Equal(t, ErrCodeMessage[NotFoundAccountErr].code, err.(*WalletError).GetCode())
+
+	err = ks.Unlock("alice", "123", "AccountWallet 1", 0)
+	assert.Nil(t, err)
+	assert.True(t, w.IsLocked())
+	assert.True(t, w.MasterAccount.Child[0].Key.IsWatchOnly())
+	assert.False(t, w.MasterAccount.Child[1].Key.IsWatchOnly())
+	status, _ := ks.Status("alice")
+	assert.Equal(t, []string{"AccountWallet 1"}, status.UnlockedAccounts)
+
+	// an account wallet can not derive new accounts
+	_, err = w.CreateNewAccount("new", nil)
+	assert.Equal(t, ErrCodeMessage[WalletLockedErr].code, err.(*WalletError).GetCode())
+}
+
+func TestKeystoreUnlockTimeout(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 2)
+	assert.Nil(t, err)
+
+	err = ks.Unlock("alice", "123", "", 50*time.Millisecond)
+	assert.Nil(t, err)
+	err = ks.Unlock("alice", "123", "AccountWallet 0", time.Hour)
+	assert.Nil(t, err)
+	assert.False(t, w.IsLocked())
+
+	time.Sleep(200 * time.Millisecond)
+	status, _ := ks.Status("alice")
+	assert.False(t, status.Unlocked)
+	assert.Equal(t, []string{"AccountWallet 0"}, status.UnlockedAccounts)
+	assert.True(t, w.IsLocked())
+	assert.False(t, w.MasterAccount.Child[0].Key.IsWatchOnly())
+	assert.True(t, w.MasterAccount.Child[1].Key.IsWatchOnly())
+	ks.LockAll()
+	assert.True(t, w.MasterAccount.Child[0].Key.IsWatchOnly())
+}
+
+func TestKeystoreSaveAndReopen(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	w, err := ks.CreateWallet("alice", "123", 1)
+	assert.Nil(t, err)
+	_, err = w.CreateNewAccount("bob", nil)
+	assert.NotNil(t, err)
+
+	err = ks.Unlock("alice", "123", "", 0)
+	assert.Nil(t, err)
+	_, err = w.CreateNewAccount("bob", nil)
+	assert.Nil(t, err)
+	exported := w.ExportAccount(1)
+	ks.LockAll()
+
+	// the keys of a locked wallet come from the encrypted part of the file
+	other := &Wallet{}
+	assert.Nil(t, other.Init("", 1, "other"))
+	_, err = w.ImportAccount(other.ExportAccount(0), "imported", "wrong")
+	assert.Equal(t, ErrCodeMessage[WrongPassphraseErr].code, err.(*WalletError).GetCode())
+	_, err = w.ImportAccount(other.ExportAccount(0), "imported", "123")
+	assert.Nil(t, err)
+	assert.True(t, w.MasterAccount.Child[2].Key.IsWatchOnly())
+
+	reopened, err := NewKeystore(dir, LightScryptParams)
+	assert.Nil(t, err)
+	w2 := reopened.Wallet("alice")
+	assert.NotNil(t, w2)
+	assert.Equal(t, 3, len(w2.MasterAccount.Child))
+	assert.Equal(t, "bob", w2.MasterAccount.Child[1].Name)
+	assert.Nil(t, w2.CheckPassPhrase("123"))
+
+	err = reopened.Unlock("alice", "123", "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, exported, w2.ExportAccount(1))
+	assert.Equal(t, other.ExportAccount(0), w2.ExportAccount(2))
+}
+
+func TestKeystoreImportLegacyWallet(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+	defer os.RemoveAll(dir)
+
+	legacy := &Wallet{}
+	legacy.SetConfig(&WalletConfig{DataPath: filepath.Join(dir, "legacy")})
+	assert.Nil(t, legacy.Init("123", 2, "legacy"))
+	assert.Nil(t, legacy.Save("123"))
+
+	_, err := ks.ImportLegacyWallet("node", filepath.Join(dir, "legacy"), "wrong")
+	assert.NotNil(t, err)
+
+	w, err := ks.ImportLegacyWallet("node", filepath.Join(dir, "legacy"), "123")
+	assert.Nil(t, err)
+	assert.Equal(t, "node", w.Name)
+	assert.Nil(t, ks.Unlock("node", "123", "", 0))
+	assert.Equal(t, legacy.Mnemonic, w.Mnemonic)
+	assert.Equal(t, legacy.ExportAccount(1), w.ExportAccount(1))
+	assert.Equal(t, 1, len(ks.Wallets()))
+}
@@ -24,6 +24,7 @@ type Wallet struct {
 	MasterAccount AccountWallet
 	Name          string
 	config        *WalletConfig
+	keystore      *Keystore // set when the wallet is kept in a keystore, it then holds only the unlocked keys
 }
 
 type WalletConfig struct {
@@ -93,6 +94,25 @@ func (wallet *Wallet) Init(passPhrase string, numOfAccount uint32, name string)
 // If shardID is nil, new account will belong to any shards
 // Otherwise, new account will belong to specific shard
 func (wallet *Wallet) CreateNewAccount(accountName string, shardID *byte) (*AccountWallet, error) {
+	unlock := wallet.lockKeys()
+	account, err := wallet.addNewAccount(accountName, shardID)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	err = wallet.Save(wallet.PassPhrase)
+	if err != nil {
+		Logger.log.Error(err)
+	}
+	return account, nil
+}
+
+// addNewAccount derives a child key of the master key and adds its account to wallet,
+// it returns a copy of the account which stays valid after the wallet is locked
+func (wallet *Wallet) addNewAccount(accountName string, shardID *byte) (*AccountWallet, error) {
+	if wallet.MasterAccount.Key.IsWatchOnly() {
+		return nil, NewWalletError(WalletLockedErr, nil)
+	}
 	if accountName != "" {
 		for _, acc := range wallet.MasterAccount.Child {
 			if acc.Name == accountName {
@@ -139,11 +159,8 @@ func (wallet *Wallet) CreateNewAccount(accountName string, shardID *byte) (*Acco
 			Name:  accountName,
 		}
 		wallet.MasterAccount.Child = append(wallet.MasterAccount.Child, account)
-		err := wallet.Save(wallet.PassPhrase)
-		if err != nil {
-			Logger.log.Error(err)
-		}
-		return &account, nil
+		clone := cloneAccountWallet(account)
+		return &clone, nil
 
 	} else {
 		newIndex := uint32(len(wallet.MasterAccount.Child))
@@ -157,48 +174,61 @@ func (wallet *Wallet) CreateNewAccount(accountName string, shardID *byte) (*Acco
 			Name:  accountName,
 		}
 		wallet.MasterAccount.Child = append(wallet.MasterAccount.Child, account)
-		err := wallet.Save(wallet.PassPhrase)
-		if err != nil {
-			Logger.log.Error(err)
-		}
-		return &account, nil
+		clone := cloneAccountWallet(account)
+		return &clone, nil
 	}
 }
 
 // ExportAccount returns a private key string of account at childIndex in wallet
 // It is base58 check serialized
 func (wallet *Wallet) ExportAccount(childIndex uint32) string {
+	unlock := wallet.lockKeys()
+	defer unlock()
 	if int(childIndex) >= len(wallet.MasterAccount.Child) {
 		return ""
 	}
 	return wallet.MasterAccount.Child[childIndex].Key.Base58CheckSerialize(PriKeyType)
 }
 
+// RemoveAccount removes the account of privateKeyStr from wallet, a watch-only account is removed with its viewing key
 func (wallet *Wallet) RemoveAccount(privateKeyStr string, passPhrase string) error {
-	if passPhrase != wallet.PassPhrase {
-		return NewWalletError(WrongPassphraseErr, nil)
+	err := wallet.CheckPassPhrase(passPhrase)
+	if err != nil {
+		return err
 	}
+	unlock := wallet.lockKeys()
+	removed := false
 	for i, account := range wallet.MasterAccount.Child {
-		if account.Key.Base58CheckSerialize(PriKeyType) == privateKeyStr {
+		keyType := PriKeyType
+		if account.Key.IsWatchOnly() {
+			keyType = ViewingKeyType
+		}
+		if account.Key.Base58CheckSerialize(keyType) == privateKeyStr {
 			wallet.MasterAccount.Child = append(wallet.MasterAccount.Child[:i], wallet.MasterAccount.Child[i+1:]...)
-			err := wallet.Save(passPhrase)
-			if err != nil {
-				Logger.log.Error(err)
-			}
-			return nil
+			removed = true
+			break
 		}
 	}
-	return NewWalletError(NotFoundAccountErr, nil)
+	unlock()
+	if !removed {
+		return NewWalletError(NotFoundAccountErr, nil)
+	}
+	err = wallet.Save(passPhrase)
+	if err != nil {
+		Logger.log.Error(err)
+	}
+	return nil
 }
 
 // ImportAccount adds account into wallet with privateKeyStr, accountName, and passPhrase which is used to init wallet
 // It returns AccountWallet which is imported and errors (if any)
 func (wallet *Wallet) ImportAccount(privateKeyStr string, accountName string, passPhrase string) (*AccountWallet, error) {
-	if passPhrase != wallet.PassPhrase {
-		return nil, NewWalletError(WrongPassphraseErr, nil)
+	err := wallet.CheckPassPhrase(passPhrase)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, account := range wallet.MasterAccount.Child {
+	for _, account := range wallet.Accounts() {
 		if account.Key.Base58CheckSerialize(PriKeyType) == privateKeyStr {
 			return nil, NewWalletError(ExistedAccountErr, nil)
 		}
@@ -226,8 +256,56 @@ func (wallet *Wallet) ImportAccount(privateKeyStr string, accountName string, pa
 		IsImported: true,
 		Name:       accountName,
 	}
+	unlock := wallet.lockKeys()
 	wallet.MasterAccount.Child = append(wallet.MasterAccount.Child, account)
-	err = wallet.Save(wallet.PassPhrase)
+	account = cloneAccountWallet(account)
+	unlock()
+	err = wallet.Save(passPhrase)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ImportViewingKey adds a watch-only account into wallet with viewingKeyStr, accountName, and passPhrase which is used to init wallet
+// The account sees the coins of its payment address and their amounts but cannot spend them
+// It returns AccountWallet which is imported and errors (if any)
+func (wallet *Wallet) ImportViewingKey(viewingKeyStr string, accountName string, passPhrase string) (*AccountWallet, error) {
+	err := wallet.CheckPassPhrase(passPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := Base58CheckDeserialize(viewingKeyStr)
+	if err != nil {
+		return nil, err
+	}
+	if !keyWallet.IsWatchOnly() || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 || len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, NewWalletError(InvalidViewingKeyErr, nil)
+	}
+
+	for _, account := range wallet.Accounts() {
+		if bytes.Equal(account.Key.KeySet.PaymentAddress.Pk, keyWallet.KeySet.PaymentAddress.Pk) {
+			return nil, NewWalletError(ExistedAccountErr, nil)
+		}
+		if account.Name == accountName {
+			return nil, NewWalletError(ExistedAccountNameErr, nil)
+		}
+	}
+
+	keyWallet.ChildNumber = make([]byte, childNumberLen)
+	keyWallet.ChainCode = make([]byte, chainCodeLen)
+	account := AccountWallet{
+		Key:        *keyWallet,
+		Child:      make([]AccountWallet, 0),
+		IsImported: true,
+		Name:       accountName,
+	}
+	unlock := wallet.lockKeys()
+	wallet.MasterAccount.Child = append(wallet.MasterAccount.Child, account)
+	account = cloneAccountWallet(account)
+	unlock()
+	err = wallet.Save(passPhrase)
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +315,10 @@ func (wallet *Wallet) ImportAccount(privateKeyStr string, accountName string, pa
 // Save saves encrypted wallet (using AES encryption scheme) in config data file of wallet
 // It returns error if any
 func (wallet *Wallet) Save(password string) error {
+	if wallet.keystore != nil {
+		return wallet.keystore.save(wallet, password)
+	}
+
 	if password == "" {
 		password = wallet.PassPhrase
 	}
@@ -268,6 +350,26 @@ func (wallet *Wallet) Save(password string) error {
 	return nil
 }
 
+// CheckPassPhrase returns nil if passPhrase is the pass phrase of wallet
+// A keystore wallet checks it against its encrypted file, so it works while the wallet is locked
+func (wallet *Wallet) CheckPassPhrase(passPhrase string) error {
+	if wallet.keystore != nil {
+		return wallet.keystore.checkPassPhrase(wallet.Name, passPhrase)
+	}
+	if passPhrase != wallet.PassPhrase {
+		return NewWalletError(WrongPassphraseErr, nil)
+	}
+	return nil
+}
+
+// IsLocked returns true when the master key of wallet is not in memory,
+// new accounts can not be derived until its keystore unlocks the whole wallet
+func (wallet *Wallet) IsLocked() bool {
+	unlock := wallet.lockKeys()
+	defer unlock()
+	return wallet.MasterAccount.Key.IsWatchOnly()
+}
+
 // LoadWallet loads encrypted wallet from file and then decrypts it to wallet struct
 // It returns error if any
 func (wallet *Wallet) LoadWallet(password string) error {
@@ -294,6 +396,8 @@ func (wallet *Wallet) LoadWallet(password string) error {
 // which is corresponding to paymentAddrSerialized in all wallet accounts
 // If there is not any wallet account corresponding to paymentAddrSerialized, it returns empty KeySerializedData object
 func (wallet *Wallet) DumpPrivateKey(paymentAddrSerialized string) KeySerializedData {
+	unlock := wallet.lockKeys()
+	defer unlock()
 	for _, account := range wallet.MasterAccount.Child {
 		address := account.Key.Base58CheckSerialize(PaymentAddressType)
 		if address == paymentAddrSerialized {
@@ -306,28 +410,53 @@ func (wallet *Wallet) DumpPrivateKey(paymentAddrSerialized string) KeySerialized
 	return KeySerializedData{}
 }
 
+// DumpViewingKey receives base58 check serialized payment address (paymentAddrSerialized)
+// and returns KeySerializedData object contains the ViewingKey of the wallet account of paymentAddrSerialized,
+// the viewing key lets an auditor see the coins of the account without being able to spend them
+// If there is not any wallet account corresponding to paymentAddrSerialized, it returns empty KeySerializedData object
+func (wallet *Wallet) DumpViewingKey(paymentAddrSerialized string) KeySerializedData {
+	unlock := wallet.lockKeys()
+	defer unlock()
+	for _, account := range wallet.MasterAccount.Child {
+		address := account.Key.Base58CheckSerialize(PaymentAddressType)
+		if address == paymentAddrSerialized {
+			key := KeySerializedData{
+				ViewingKey: account.Key.Base58CheckSerialize(ViewingKeyType),
+			}
+			return key
+		}
+	}
+	return KeySerializedData{}
+}
+
 // GetAddressByAccName receives accountName and shardID
 // and returns corresponding account's KeySerializedData object contains base58 check serialized PaymentAddress,
 // hex encoding Pubkey and base58 check serialized ReadonlyKey
 // If there is not any account corresponding to accountName, we will create new account
 func (wallet *Wallet) GetAddressByAccName(accountName string, shardID *byte) KeySerializedData {
-	for _, account := range wallet.MasterAccount.Child {
+	for _, account := range wallet.Accounts() {
 		if account.Name == accountName {
 			key := KeySerializedData{
 				PaymentAddress: account.Key.Base58CheckSerialize(PaymentAddressType),
 				Pubkey:         hex.EncodeToString(account.Key.KeySet.PaymentAddress.Pk),
 				ReadonlyKey:    account.Key.Base58CheckSerialize(ReadonlyKeyType),
 				PrivateKey:     account.Key.Base58CheckSerialize(PriKeyType),
+				ViewingKey:     account.Key.Base58CheckSerialize(ViewingKeyType),
 			}
 			return key
 		}
 	}
-	newAccount, _ := wallet.CreateNewAccount(accountName, shardID)
+	newAccount, err := wallet.CreateNewAccount(accountName, shardID)
+	if err != nil {
+		Logger.log.Error(err)
+		return KeySerializedData{}
+	}
 	key := KeySerializedData{
 		PaymentAddress: newAccount.Key.Base58CheckSerialize(PaymentAddressType),
 		Pubkey:         hex.EncodeToString(newAccount.Key.KeySet.PaymentAddress.Pk),
 		ReadonlyKey:    newAccount.Key.Base58CheckSerialize(ReadonlyKeyType),
 		PrivateKey:     newAccount.Key.Base58CheckSerialize(PriKeyType),
+		ViewingKey:     newAccount.Key.Base58CheckSerialize(ViewingKeyType),
 	}
 	return key
 }
@@ -335,6 +464,8 @@ func (wallet *Wallet) GetAddressByAccName(accountName string, shardID *byte) Key
 // GetAddressesByAccName receives accountName
 // and returns list of KeySerializedData of accounts which has accountName
 func (wallet *Wallet) GetAddressesByAccName(accountName string) []KeySerializedData {
+	unlock := wallet.lockKeys()
+	defer unlock()
 	result := make([]KeySerializedData, 0)
 	for _, account := range wallet.MasterAccount.Child {
 		if account.Name == accountName {
@@ -342,6 +473,7 @@ func (wallet *Wallet) GetAddressesByAccName(accountName string) []KeySerializedD
 				PaymentAddress: account.Key.Base58CheckSerialize(PaymentAddressType),
 				Pubkey:         hex.EncodeToString(account.Key.KeySet.PaymentAddress.Pk),
 				ReadonlyKey:    account.Key.Base58CheckSerialize(ReadonlyKeyType),
+				ViewingKey:     account.Key.Base58CheckSerialize(ViewingKeyType),
 			}
 			result = append(result, item)
 		}
@@ -350,16 +482,51 @@ func (wallet *Wallet) GetAddressesByAccName(accountName string) []KeySerializedD
 }
 
 // ListAccounts returns a map with key is account name and value is account wallet
+// The accounts are copies, see Accounts
 func (wallet *Wallet) ListAccounts() map[string]AccountWallet {
 	result := make(map[string]AccountWallet)
-	for _, account := range wallet.MasterAccount.Child {
+	for _, account := range wallet.Accounts() {
 		result[account.Name] = account
 	}
 	return result
 }
 
+// Accounts returns copies of the accounts of wallet in order
+// A keystore zeroes the private keys of a wallet in place when it locks them, so the copies are taken under
+// its lock and callers can keep reading or signing with them after the wallet is locked again
+func (wallet *Wallet) Accounts() []AccountWallet {
+	unlock := wallet.lockKeys()
+	defer unlock()
+	result := make([]AccountWallet, 0, len(wallet.MasterAccount.Child))
+	for _, account := range wallet.MasterAccount.Child {
+		result = append(result, cloneAccountWallet(account))
+	}
+	return result
+}
+
+// lockKeys holds the lock of the keystore of wallet while its accounts are read or changed,
+// it does nothing for a wallet which is not kept in a keystore
+func (wallet *Wallet) lockKeys() func() {
+	if wallet.keystore == nil {
+		return func() {}
+	}
+	wallet.keystore.mtx.Lock()
+	return wallet.keystore.mtx.Unlock
+}
+
+func cloneAccountWallet(account AccountWallet) AccountWallet {
+	return AccountWallet{
+		Name:       account.Name,
+		Key:        cloneKeyWallet(account.Key),
+		Child:      make([]AccountWallet, 0),
+		IsImported: account.IsImported,
+	}
+}
+
 // ContainPubKey checks whether the wallet contains any account with pubKey or not
 func (wallet *Wallet) ContainPublicKey(pubKey []byte) bool {
+	unlock := wallet.lockKeys()
+	defer unlock()
 	for _, account := range wallet.MasterAccount.Child {
 		if bytes.Equal(account.Key.KeySet.PaymentAddress.Pk[:], pubKey) {
 			return true
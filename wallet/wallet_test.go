@@ -303,6 +303,60 @@ func TestWalletImportAccountWithUnmatchedPassPhrase(t *testing.T) {
 	Unit test for RemoveAccount function
 */
 
+func TestWalletImportViewingKey(t *testing.T) {
+	config := wallet.GetConfig()
+	defer wallet.SetConfig(config)
+	dir, _ := ioutil.TempDir("", "wallet")
+	defer os.RemoveAll(dir)
+	wallet.SetConfig(&WalletConfig{DataDir: dir, DataFile: "wallet", DataPath: filepath.Join(dir, "wallet")})
+
+	privateKeyStr := "112t8rnY6orkxdArx6fH7xV8C3kiEAJMuDmf7ptrgQ3iqo6VKzSzippYzqT3kPqCXyVmb4iP5AnyTzD1thrhybntuWockJrtYHq6CeSWK5VZ"
+	passPhrase := "123"
+	keyWallet, _ := Base58CheckDeserialize(privateKeyStr)
+	keyWallet.KeySet.InitFromPrivateKey(&keyWallet.KeySet.PrivateKey)
+	viewingKeyStr := keyWallet.Base58CheckSerialize(ViewingKeyType)
+	paymentAddressStr := keyWallet.Base58CheckSerialize(PaymentAddressType)
+
+	wallet.Init(passPhrase, 0, "Wallet")
+	numAccount := len(wallet.MasterAccount.Child)
+
+	_, err := wallet.ImportViewingKey(viewingKeyStr, "Auditor", "1234")
+	assert.Equal(t, NewWalletError(WrongPassphraseErr, nil), err)
+	_, err = wallet.ImportViewingKey(privateKeyStr, "Auditor", passPhrase)
+	assert.Equal(t, NewWalletError(InvalidViewingKeyErr, nil), err)
+	_, err = wallet.ImportViewingKey(paymentAddressStr, "Auditor", passPhrase)
+	assert.Equal(t, NewWalletError(InvalidViewingKeyErr, nil), err)
+
+	newAccount, err := wallet.ImportViewingKey(viewingKeyStr, "Auditor", passPhrase)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, numAccount+1, len(wallet.MasterAccount.Child))
+	assert.Equal(t, true, newAccount.IsImported)
+	assert.Equal(t, true, newAccount.Key.IsWatchOnly())
+	assert.Equal(t, keyWallet.KeySet.PaymentAddress, newAccount.Key.KeySet.PaymentAddress)
+	assert.Equal(t, keyWallet.KeySet.ReadonlyKey, newAccount.Key.KeySet.ReadonlyKey)
+
+	_, err = wallet.ImportViewingKey(viewingKeyStr, "Auditor 2", passPhrase)
+	assert.Equal(t, NewWalletError(ExistedAccountErr, nil), err)
+
+	// the wallet never has a private key to dump for a watch-only account
+	assert.Equal(t, "", wallet.DumpPrivateKey(paymentAddressStr).PrivateKey)
+	assert.Equal(t, viewingKeyStr, wallet.DumpViewingKey(paymentAddressStr).ViewingKey)
+	addresses := wallet.GetAddressesByAccName("Auditor")
+	assert.Equal(t, 1, len(addresses))
+	assert.Equal(t, paymentAddressStr, addresses[0].PaymentAddress)
+	assert.Equal(t, viewingKeyStr, addresses[0].ViewingKey)
+
+	// the wallet file keeps the account watch-only
+	loaded := new(Wallet)
+	loaded.SetConfig(wallet.GetConfig())
+	assert.Equal(t, nil, loaded.LoadWallet(passPhrase))
+	loadedAccount := loaded.ListAccounts()["Auditor"]
+	assert.Equal(t, true, loadedAccount.Key.IsWatchOnly())
+
+	assert.Equal(t, nil, wallet.RemoveAccount(viewingKeyStr, passPhrase))
+	assert.Equal(t, numAccount, len(wallet.MasterAccount.Child))
+}
+
 func TestWalletRemoveAccount(t *testing.T) {
 	data := []struct {
 		privateKeyStr string